@@ -48,6 +48,8 @@ const (
 	TopicStateCreated TopicState = "created"
 	// UserStateCreated describes the status of a KafkaUser as created
 	UserStateCreated UserState = "created"
+	// UserStateRevoked describes the status of a KafkaUser whose certificate is revoked
+	UserStateRevoked UserState = "revoked"
 	// TLSJKSKeyStore is where a JKS keystore is stored in a user secret when requested
 	TLSJKSKeyStore string = "keystore.jks"
 	// TLSJKSTrustStore is where a JKS truststore is stored in a user secret when requested
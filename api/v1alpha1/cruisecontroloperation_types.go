@@ -0,0 +1,146 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CruiseControlOperationType is the type of the Cruise Control task of a CruiseControlOperation
+type CruiseControlOperationType string
+
+// CruiseControlOperationState is the state of a CruiseControlOperation
+type CruiseControlOperationState string
+
+const (
+	// OperationAddBroker moves replicas to the brokers
+	OperationAddBroker CruiseControlOperationType = "add_broker"
+	// OperationRemoveBroker moves all replicas off the brokers
+	OperationRemoveBroker CruiseControlOperationType = "remove_broker"
+	// OperationRebalance rebalances the replicas of the cluster
+	OperationRebalance CruiseControlOperationType = "rebalance"
+	// OperationDemoteBroker moves the partition leaderships off the brokers
+	OperationDemoteBroker CruiseControlOperationType = "demote_broker"
+
+	// OperationStatePending means the task of the operation has not been started in Cruise Control yet, or it is
+	// waiting to be started again after a failed attempt
+	OperationStatePending CruiseControlOperationState = "Pending"
+	// OperationStateActive means Cruise Control accepted the task and computes its proposals
+	OperationStateActive CruiseControlOperationState = "Active"
+	// OperationStateInExecution means Cruise Control executes the proposals of the task
+	OperationStateInExecution CruiseControlOperationState = "InExecution"
+	// OperationStateCompleted means the task completed successfully
+	OperationStateCompleted CruiseControlOperationState = "Completed"
+	// OperationStateCompletedWithError means the task could not be started or it completed with an error
+	OperationStateCompletedWithError CruiseControlOperationState = "CompletedWithError"
+)
+
+// CruiseControlOperationSpec defines the desired state of CruiseControlOperation
+// +k8s:openapi-gen=true
+type CruiseControlOperationSpec struct {
+	// ClusterRef references the KafkaCluster the operation is executed on
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// Operation is the type of the Cruise Control task started for the operation
+	// +kubebuilder:validation:Enum=add_broker;remove_broker;rebalance;demote_broker
+	Operation CruiseControlOperationType `json:"operation"`
+	// +optional
+	Parameters CruiseControlOperationParameters `json:"parameters,omitempty"`
+}
+
+// CruiseControlOperationParameters defines the parameters of the Cruise Control task of a CruiseControlOperation
+type CruiseControlOperationParameters struct {
+	// BrokerIDs are the brokers added, removed or demoted by the operation, they are required for these operations.
+	// The brokers are excluded from the replica movements of a rebalance.
+	// +optional
+	BrokerIDs []string `json:"brokerIDs,omitempty"`
+	// Goals are the goals optimized by the operation, the default goals of Cruise Control are optimized when it is
+	// empty. Demoting brokers does not take goals.
+	// +optional
+	Goals []string `json:"goals,omitempty"`
+	// Topics is the regular expression the names of the topics rebalanced must match, all topics are rebalanced when
+	// it is empty. It only applies to rebalance operations.
+	// +optional
+	Topics string `json:"topics,omitempty"`
+	// ReplicationThrottle is the upper bound of the bandwidth in bytes per second used to move the replicas of the
+	// added and removed brokers, the default throttle of Cruise Control is used when it is 0
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReplicationThrottle int64 `json:"replicationThrottle,omitempty"`
+}
+
+// CruiseControlOperationStatus defines the observed state of CruiseControlOperation
+// +k8s:openapi-gen=true
+type CruiseControlOperationStatus struct {
+	// TaskID is the id of the Cruise Control user task of the operation
+	// +optional
+	TaskID string `json:"taskID,omitempty"`
+	// +optional
+	State CruiseControlOperationState `json:"state,omitempty"`
+	// ErrorMessage is the reason the task could not be started or completed
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// StartedAt is the time Cruise Control accepted the task in RFC3339
+	// +optional
+	StartedAt string `json:"startedAt,omitempty"`
+	// FinishedAt is the time the task was first seen finished in RFC3339
+	// +optional
+	FinishedAt string `json:"finishedAt,omitempty"`
+	// Attempts is the number of times the task of the operation was started
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+	// NextRetryAt is the time the task of the operation is started again after a failed attempt in RFC3339
+	// +optional
+	NextRetryAt string `json:"nextRetryAt,omitempty"`
+	// Paused is true while the start of the task of the operation is held back by the operation-paused annotation
+	// of the operation or of its cluster
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CruiseControlOperation is the Schema for the cruisecontroloperations API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Operation",type="string",JSONPath=".spec.operation"
+// +kubebuilder:printcolumn:name="Task ID",type="string",JSONPath=".status.taskID"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+// +kubebuilder:printcolumn:name="Paused",type="boolean",JSONPath=".status.paused"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type CruiseControlOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CruiseControlOperationSpec   `json:"spec,omitempty"`
+	Status CruiseControlOperationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CruiseControlOperationList contains a list of CruiseControlOperation
+type CruiseControlOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CruiseControlOperation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CruiseControlOperation{}, &CruiseControlOperationList{})
+}
+
+// IsFinished returns true if the task of the operation completed, successfully or not
+func (status *CruiseControlOperationStatus) IsFinished() bool {
+	return status.State == OperationStateCompleted || status.State == OperationStateCompletedWithError
+}
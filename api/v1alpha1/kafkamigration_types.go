@@ -0,0 +1,198 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MigrationPhase is the phase of a KafkaMigration
+type MigrationPhase string
+
+const (
+	// MigrationPhaseReplicating means MirrorMaker 2 copies the topics to the target cluster and the lag is above the
+	// maximum allowed for the cutover
+	MigrationPhaseReplicating MigrationPhase = "Replicating"
+	// MigrationPhaseReadyForCutover means the lag is low enough for the cutover to be started
+	MigrationPhaseReadyForCutover MigrationPhase = "ReadyForCutover"
+	// MigrationPhaseCuttingOver means the producers of the source cluster are paused and the rest of the messages
+	// are being replicated before the offsets of the consumer groups are translated
+	MigrationPhaseCuttingOver MigrationPhase = "CuttingOver"
+	// MigrationPhaseCompleted means the target cluster is authoritative, the clients can be switched over to it
+	MigrationPhaseCompleted MigrationPhase = "Completed"
+)
+
+// KafkaMigrationSpec defines the desired state of KafkaMigration
+// +k8s:openapi-gen=true
+type KafkaMigrationSpec struct {
+	// SourceClusterRef references the KafkaCluster the topics are migrated from
+	SourceClusterRef ClusterReference `json:"sourceClusterRef"`
+	// TargetClusterRef references the KafkaCluster the topics are migrated to
+	TargetClusterRef ClusterReference `json:"targetClusterRef"`
+	// Topics is the regular expression the names of the migrated topics must match, internal topics are never
+	// migrated
+	// +kubebuilder:default=".*"
+	// +optional
+	Topics string `json:"topics,omitempty"`
+	// Groups is the regular expression the names of the consumer groups whose offsets are translated must match
+	// +kubebuilder:default=".*"
+	// +optional
+	Groups string `json:"groups,omitempty"`
+	// MaxCutoverLag is the largest number of messages the target cluster may lag behind the source cluster for the
+	// cutover to be started
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1000
+	// +optional
+	MaxCutoverLag int64 `json:"maxCutoverLag,omitempty"`
+	// Cutover approves the cutover once the migration is ready for it. During the cutover the producers of the source
+	// cluster are paused by clamping the default producer quotas along with the producer quotas of the users and
+	// client ids having quotas of their own.
+	// +optional
+	Cutover bool `json:"cutover,omitempty"`
+	// CutoverTimeoutSeconds is how long the rest of the messages may take to be replicated after the producers are
+	// paused, the producers are resumed and the cutover has to be approved again when it is exceeded
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=600
+	// +optional
+	CutoverTimeoutSeconds int32 `json:"cutoverTimeoutSeconds,omitempty"`
+	// MirrorMaker configures the MirrorMaker 2 deployment replicating the topics
+	// +optional
+	MirrorMaker MirrorMakerConfig `json:"mirrorMaker,omitempty"`
+}
+
+// MirrorMakerConfig defines the MirrorMaker 2 deployment of a KafkaMigration
+type MirrorMakerConfig struct {
+	// Image is the Kafka image MirrorMaker 2 runs from, the image of the target cluster is used when it is not set
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Tasks is the maximum number of tasks the topics are replicated by
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Tasks int32 `json:"tasks,omitempty"`
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// KafkaMigrationStatus defines the observed state of KafkaMigration
+// +k8s:openapi-gen=true
+type KafkaMigrationStatus struct {
+	// +optional
+	Phase MigrationPhase `json:"phase,omitempty"`
+	// Lag is the number of messages the target cluster lags behind the source cluster, estimated from the number of
+	// messages retained in the partitions of the migrated topics. The cutover completes once it drops to zero, which
+	// transactional topics may never reach as their transaction markers are not replicated.
+	// +optional
+	Lag int64 `json:"lag,omitempty"`
+	// Topics is the number of migrated topics
+	// +optional
+	Topics int32 `json:"topics,omitempty"`
+	// CutoverStartedAt is the time the producers of the source cluster were paused
+	// +optional
+	CutoverStartedAt string `json:"cutoverStartedAt,omitempty"`
+	// CompletedAt is the time the target cluster became authoritative
+	// +optional
+	CompletedAt string `json:"completedAt,omitempty"`
+	// TranslatedGroups are the consumer groups whose offsets were translated to the target cluster
+	// +optional
+	TranslatedGroups []string `json:"translatedGroups,omitempty"`
+	// PreviousProducerQuotas holds the default producer quotas of the source cluster by entity type from before the
+	// cutover, they are restored when the cutover times out or the migration is deleted
+	// +optional
+	PreviousProducerQuotas map[string]int64 `json:"previousProducerQuotas,omitempty"`
+	// PreviousEntityProducerQuotas holds the producer quotas of the source cluster set for users, client IDs or
+	// their combinations from before the cutover, they are clamped and restored along the default producer quotas
+	// +optional
+	PreviousEntityProducerQuotas []ProducerQuota `json:"previousEntityProducerQuotas,omitempty"`
+	// FailedCutoverGeneration is the generation of the KafkaMigration whose cutover timed out, the cutover is only
+	// started again once the spec is changed
+	// +optional
+	FailedCutoverGeneration int64 `json:"failedCutoverGeneration,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// SyncedAt is the time the lag was last measured
+	// +optional
+	SyncedAt string `json:"syncedAt,omitempty"`
+}
+
+// ProducerQuota is the producer quota of a quota entity of the source cluster
+type ProducerQuota struct {
+	// Entity holds the components of the quota entity, e.g. a user and a client ID
+	Entity []QuotaEntityComponent `json:"entity"`
+	// ByteRate is the number of bytes per second the producers of the entity may produce
+	ByteRate int64 `json:"byteRate"`
+}
+
+// QuotaEntityComponent is a component of a quota entity
+type QuotaEntityComponent struct {
+	// EntityType is the type of the component, user or client-id
+	EntityType string `json:"entityType"`
+	// Name is the name of the user or the client ID, the default entity of the type has no name
+	// +optional
+	Name *string `json:"name,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KafkaMigration is the Schema for the kafkamigrations API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Lag",type="integer",JSONPath=".status.lag"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type KafkaMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaMigrationSpec   `json:"spec,omitempty"`
+	Status KafkaMigrationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KafkaMigrationList contains a list of KafkaMigration
+type KafkaMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaMigration{}, &KafkaMigrationList{})
+}
+
+// GetTopics returns the regular expression the names of the migrated topics must match
+func (spec *KafkaMigrationSpec) GetTopics() string {
+	if spec.Topics == "" {
+		return ".*"
+	}
+	return spec.Topics
+}
+
+// GetGroups returns the regular expression the names of the consumer groups whose offsets are translated must match
+func (spec *KafkaMigrationSpec) GetGroups() string {
+	if spec.Groups == "" {
+		return ".*"
+	}
+	return spec.Groups
+}
+
+// GetCutoverTimeoutSeconds returns how long the rest of the messages may take to be replicated during the cutover
+func (spec *KafkaMigrationSpec) GetCutoverTimeoutSeconds() int32 {
+	if spec.CutoverTimeoutSeconds == 0 {
+		return 600
+	}
+	return spec.CutoverTimeoutSeconds
+}
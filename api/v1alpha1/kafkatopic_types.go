@@ -26,12 +26,104 @@ type KafkaTopicSpec struct {
 	ReplicationFactor int32             `json:"replicationFactor"`
 	Config            map[string]string `json:"config,omitempty"`
 	ClusterRef        ClusterReference  `json:"clusterRef"`
+	// Placement holds the constraints the replicas of the topic are placed by when the topic is created or its
+	// partitions are increased, Kafka's default placement is used when it is not set
+	// +optional
+	Placement *TopicPlacement `json:"placement,omitempty"`
+}
+
+// TopicPlacement defines the constraints of the replica placement of a topic
+type TopicPlacement struct {
+	// MinRacks is the minimum number of racks the replicas of each partition must span
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinRacks int32 `json:"minRacks,omitempty"`
+	// PreferredBrokerConfigGroup is the broker config group whose brokers are preferred for the replicas,
+	// the rest of the brokers are only used when the group alone can not satisfy the constraints
+	// +optional
+	PreferredBrokerConfigGroup string `json:"preferredBrokerConfigGroup,omitempty"`
+	// ExcludedBrokers is the list of broker IDs no replicas are placed on
+	// +optional
+	ExcludedBrokers []int32 `json:"excludedBrokers,omitempty"`
 }
 
 // KafkaTopicStatus defines the observed state of KafkaTopic
 // +k8s:openapi-gen=true
 type KafkaTopicStatus struct {
 	State TopicState `json:"state"`
+	// Partitions holds the leader, the replicas and the in-sync replicas of the partitions of the topic
+	// +optional
+	Partitions []TopicPartitionStatus `json:"partitions,omitempty"`
+	// UnderReplicatedPartitions is the number of partitions having less in-sync replicas than replicas
+	// +optional
+	UnderReplicatedPartitions int32 `json:"underReplicatedPartitions,omitempty"`
+	// OfflinePartitions is the number of partitions without a leader
+	// +optional
+	OfflinePartitions int32 `json:"offlinePartitions,omitempty"`
+	// SizeBytes is the estimated size of the topic, the sum of the sizes of its partitions
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// SyncedAt is the time the partition details were last refreshed from the Kafka cluster
+	// +optional
+	SyncedAt string `json:"syncedAt,omitempty"`
+	// Usage describes the production to and the consumption of the topic observed at the last sync
+	// +optional
+	Usage *TopicUsageStatus `json:"usage,omitempty"`
+	// Recommendations are the changes of the topic suggested by its usage
+	// +optional
+	Recommendations []TopicRecommendation `json:"recommendations,omitempty"`
+}
+
+// TopicUsageStatus describes the production to and the consumption of a KafkaTopic
+type TopicUsageStatus struct {
+	// EndOffset is the sum of the end offsets of the partitions of the topic
+	EndOffset int64 `json:"endOffset"`
+	// MessagesPerSecond is the rate of the messages produced to the topic since the previous sync
+	// +optional
+	MessagesPerSecond int64 `json:"messagesPerSecond,omitempty"`
+	// ConsumerGroups is the number of consumer groups having committed offsets for the topic
+	// +optional
+	ConsumerGroups int32 `json:"consumerGroups,omitempty"`
+	// MaxConsumers is the largest number of consumers of a consumer group assigned partitions of the topic
+	// +optional
+	MaxConsumers int32 `json:"maxConsumers,omitempty"`
+	// MaxLag is the largest number of messages a consumer group lags behind the end of the partitions
+	// +optional
+	MaxLag int64 `json:"maxLag,omitempty"`
+}
+
+// TopicRecommendationType is the type of a change recommended for a KafkaTopic
+type TopicRecommendationType string
+
+const (
+	// TopicRecommendationIncreasePartitions recommends more partitions to let the consumers scale out
+	TopicRecommendationIncreasePartitions TopicRecommendationType = "IncreasePartitions"
+	// TopicRecommendationReduceRetention recommends a shorter retention as the consumers keep up with the producers
+	TopicRecommendationReduceRetention TopicRecommendationType = "ReduceRetention"
+	// TopicRecommendationEnableCompaction recommends compaction as only the latest value of each key is needed
+	TopicRecommendationEnableCompaction TopicRecommendationType = "EnableCompaction"
+)
+
+// TopicRecommendation is a change recommended for a KafkaTopic along with its reason
+type TopicRecommendation struct {
+	Type   TopicRecommendationType `json:"type"`
+	Reason string                  `json:"reason"`
+}
+
+// TopicPartitionStatus defines the observed state of a partition of a KafkaTopic
+type TopicPartitionStatus struct {
+	Partition int32 `json:"partition"`
+	// Leader is the ID of the broker leading the partition, -1 when the partition is offline
+	Leader int32 `json:"leader"`
+	// +optional
+	Replicas []int32 `json:"replicas,omitempty"`
+	// +optional
+	InSyncReplicas []int32 `json:"inSyncReplicas,omitempty"`
+	// +optional
+	OfflineReplicas []int32 `json:"offlineReplicas,omitempty"`
+	// SizeBytes is the estimated size of the partition, the size of its largest replica
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -33,6 +33,10 @@ type KafkaUserSpec struct {
 	IncludeJKS     bool              `json:"includeJKS,omitempty"`
 	CreateCert     *bool             `json:"createCert,omitempty"`
 	PKIBackendSpec *PKIBackendSpec   `json:"pkiBackendSpec,omitempty"`
+	// Revoked marks the certificate of the user as compromised. The ACLs of a revoked user are replaced with
+	// DENY ACLs so that the brokers reject the certificate without rotating the whole CA. The DENY ACLs are kept
+	// even if the KafkaUser is deleted. Unsetting it removes the DENY ACLs and recreates the ACLs of the topic grants.
+	Revoked bool `json:"revoked,omitempty"`
 }
 
 type PKIBackendSpec struct {
@@ -59,6 +63,8 @@ type KafkaUserStatus struct {
 	ACLs  []string  `json:"acls,omitempty"`
 }
 
+// +kubebuilder:webhook:failurePolicy="fail",sideEffects="None",name="kafkausers.kafka.banzaicloud.io",path="/validate",mutating=false,resources={"kafkausers"},verbs={"create","update"},groups={"kafka.banzaicloud.io"},versions={"v1alpha1"},admissionReviewVersions={"v1"}
+
 //KafkaUser is the Schema for the kafka users API
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
@@ -21,6 +21,7 @@ package v1alpha1
 
 import (
 	"github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -40,7 +41,7 @@ func (in *ClusterReference) DeepCopy() *ClusterReference {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaTopic) DeepCopyInto(out *KafkaTopic) {
+func (in *CruiseControlOperation) DeepCopyInto(out *CruiseControlOperation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -48,6 +49,233 @@ func (in *KafkaTopic) DeepCopyInto(out *KafkaTopic) {
 	out.Status = in.Status
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperation.
+func (in *CruiseControlOperation) DeepCopy() *CruiseControlOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CruiseControlOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationList) DeepCopyInto(out *CruiseControlOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CruiseControlOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationList.
+func (in *CruiseControlOperationList) DeepCopy() *CruiseControlOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CruiseControlOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationParameters) DeepCopyInto(out *CruiseControlOperationParameters) {
+	*out = *in
+	if in.BrokerIDs != nil {
+		in, out := &in.BrokerIDs, &out.BrokerIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationParameters.
+func (in *CruiseControlOperationParameters) DeepCopy() *CruiseControlOperationParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationSpec) DeepCopyInto(out *CruiseControlOperationSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	in.Parameters.DeepCopyInto(&out.Parameters)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationSpec.
+func (in *CruiseControlOperationSpec) DeepCopy() *CruiseControlOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationStatus) DeepCopyInto(out *CruiseControlOperationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationStatus.
+func (in *CruiseControlOperationStatus) DeepCopy() *CruiseControlOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMigration) DeepCopyInto(out *KafkaMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMigration.
+func (in *KafkaMigration) DeepCopy() *KafkaMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMigrationList) DeepCopyInto(out *KafkaMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMigrationList.
+func (in *KafkaMigrationList) DeepCopy() *KafkaMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMigrationSpec) DeepCopyInto(out *KafkaMigrationSpec) {
+	*out = *in
+	out.SourceClusterRef = in.SourceClusterRef
+	out.TargetClusterRef = in.TargetClusterRef
+	in.MirrorMaker.DeepCopyInto(&out.MirrorMaker)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMigrationSpec.
+func (in *KafkaMigrationSpec) DeepCopy() *KafkaMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaMigrationStatus) DeepCopyInto(out *KafkaMigrationStatus) {
+	*out = *in
+	if in.TranslatedGroups != nil {
+		in, out := &in.TranslatedGroups, &out.TranslatedGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreviousProducerQuotas != nil {
+		in, out := &in.PreviousProducerQuotas, &out.PreviousProducerQuotas
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PreviousEntityProducerQuotas != nil {
+		in, out := &in.PreviousEntityProducerQuotas, &out.PreviousEntityProducerQuotas
+		*out = make([]ProducerQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaMigrationStatus.
+func (in *KafkaMigrationStatus) DeepCopy() *KafkaMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTopic) DeepCopyInto(out *KafkaTopic) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopic.
 func (in *KafkaTopic) DeepCopy() *KafkaTopic {
 	if in == nil {
@@ -109,6 +337,11 @@ func (in *KafkaTopicSpec) DeepCopyInto(out *KafkaTopicSpec) {
 		}
 	}
 	out.ClusterRef = in.ClusterRef
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(TopicPlacement)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicSpec.
@@ -124,6 +357,23 @@ func (in *KafkaTopicSpec) DeepCopy() *KafkaTopicSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaTopicStatus) DeepCopyInto(out *KafkaTopicStatus) {
 	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]TopicPartitionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(TopicUsageStatus)
+		**out = **in
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = make([]TopicRecommendation, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicStatus.
@@ -258,6 +508,26 @@ func (in *KafkaUserStatus) DeepCopy() *KafkaUserStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorMakerConfig) DeepCopyInto(out *MirrorMakerConfig) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorMakerConfig.
+func (in *MirrorMakerConfig) DeepCopy() *MirrorMakerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorMakerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PKIBackendSpec) DeepCopyInto(out *PKIBackendSpec) {
 	*out = *in
@@ -278,6 +548,128 @@ func (in *PKIBackendSpec) DeepCopy() *PKIBackendSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProducerQuota) DeepCopyInto(out *ProducerQuota) {
+	*out = *in
+	if in.Entity != nil {
+		in, out := &in.Entity, &out.Entity
+		*out = make([]QuotaEntityComponent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducerQuota.
+func (in *ProducerQuota) DeepCopy() *ProducerQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ProducerQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaEntityComponent) DeepCopyInto(out *QuotaEntityComponent) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaEntityComponent.
+func (in *QuotaEntityComponent) DeepCopy() *QuotaEntityComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaEntityComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicPartitionStatus) DeepCopyInto(out *TopicPartitionStatus) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.InSyncReplicas != nil {
+		in, out := &in.InSyncReplicas, &out.InSyncReplicas
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.OfflineReplicas != nil {
+		in, out := &in.OfflineReplicas, &out.OfflineReplicas
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicPartitionStatus.
+func (in *TopicPartitionStatus) DeepCopy() *TopicPartitionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicPartitionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicRecommendation) DeepCopyInto(out *TopicRecommendation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicRecommendation.
+func (in *TopicRecommendation) DeepCopy() *TopicRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicPlacement) DeepCopyInto(out *TopicPlacement) {
+	*out = *in
+	if in.ExcludedBrokers != nil {
+		in, out := &in.ExcludedBrokers, &out.ExcludedBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicPlacement.
+func (in *TopicPlacement) DeepCopy() *TopicPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicUsageStatus) DeepCopyInto(out *TopicUsageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicUsageStatus.
+func (in *TopicUsageStatus) DeepCopy() *TopicUsageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicUsageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserTopicGrant) DeepCopyInto(out *UserTopicGrant) {
 	*out = *in
@@ -14,7 +14,10 @@
 
 package v1beta1
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // RackAwarenessState stores info about rack awareness status
 type RackAwarenessState string
@@ -48,6 +51,18 @@ type PerBrokerConfigurationState string
 // ExternalListenerConfigNames type describes a collection of external listener names
 type ExternalListenerConfigNames []string
 
+// EnvoyTLSMode is the way Envoy handles the TLS of the external client connections
+type EnvoyTLSMode string
+
+const (
+	// EnvoyTLSModePassthrough proxies the client connections to the brokers as they are
+	EnvoyTLSModePassthrough EnvoyTLSMode = "Passthrough"
+	// EnvoyTLSModeTerminate terminates the TLS of the client connections and forwards the plaintext streams
+	EnvoyTLSModeTerminate EnvoyTLSMode = "Terminate"
+	// EnvoyTLSModeReencrypt terminates the TLS of the client connections and re-encrypts the streams towards the brokers
+	EnvoyTLSModeReencrypt EnvoyTLSMode = "Reencrypt"
+)
+
 // KafkaVersion type describes the kafka version and docker version
 type KafkaVersion struct {
 	// Version holds the current version of the broker in semver format
@@ -59,6 +74,68 @@ type KafkaVersion struct {
 // PKIBackend represents an interface implementing the PKIManager
 type PKIBackend string
 
+// OperationUrgency describes whether a disruptive operation may wait for the next maintenance window
+type OperationUrgency string
+
+// PreflightOperation is the disruptive operation the pre-flight checks are run for
+type PreflightOperation string
+
+// SmokeTestState holds info about the state of the post-change verification
+type SmokeTestState string
+
+// OperationType is the kind of a significant operation recorded in the operation history of a cluster
+type OperationType string
+
+// OperationOutcome is the outcome of an operation recorded in the operation history of a cluster
+type OperationOutcome string
+
+// BrokerLatencyState describes whether the request latencies of a broker breach the thresholds
+type BrokerLatencyState string
+
+// ControllerHealthState describes whether the controller of a cluster is healthy
+type ControllerHealthState string
+
+// RebalancerType is the component reassigning the partition replicas when brokers are added or removed
+type RebalancerType string
+
+// BrokerConfigValidationPolicy defines how invalid broker configurations are handled
+type BrokerConfigValidationPolicy string
+
+// ConfigIssueSeverity tells whether a broker config issue would prevent the brokers from starting or applying it
+type ConfigIssueSeverity string
+
+// VersionUpgradePhase is the phase of a Kafka version upgrade staged by the operator
+type VersionUpgradePhase string
+
+// NodeArchitecture is a CPU architecture of the Kubernetes nodes, as reported by the kubernetes.io/arch node label
+// +kubebuilder:validation:Enum=amd64;arm64;ppc64le;s390x
+type NodeArchitecture string
+
+// MaintenanceWindowDay is a day of the week a maintenance window recurs on
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type MaintenanceWindowDay string
+
+// AntiAffinityType is the strength of the anti-affinity spreading the brokers across the topology domains
+// +kubebuilder:validation:Enum=Required;Preferred;None
+type AntiAffinityType string
+
+// AffinityMergeStrategy defines how a user supplied affinity is combined with the broker anti-affinity generated by
+// the operator
+// +kubebuilder:validation:Enum=Override;Append
+type AffinityMergeStrategy string
+
+// ProcessRole is a role of a Kafka node in KRaft mode
+// +kubebuilder:validation:Enum=broker;controller
+type ProcessRole string
+
+// RollingUpgradeConcurrencyPolicy tells which brokers can be restarted at once during rolling upgrades
+// +kubebuilder:validation:Enum=Any;Rack
+type RollingUpgradeConcurrencyPolicy string
+
+// RollingUpgradeOrder tells in which order the brokers are restarted during rolling upgrades
+// +kubebuilder:validation:Enum=ControllerLast;LowestLoadFirst;Explicit
+type RollingUpgradeOrder string
+
 // CruiseControlVolumeState holds information about the state of volume rebalance
 type CruiseControlVolumeState string
 
@@ -74,6 +151,15 @@ func (s CruiseControlVolumeState) IsActive() bool {
 	return s == GracefulDiskRebalanceRunning || s == GracefulDiskRebalanceRequired
 }
 
+// CruiseControlMaintenanceState holds information about the state of the demotion of a broker in maintenance
+type CruiseControlMaintenanceState string
+
+// IsActive returns true if CruiseControlMaintenanceState is in active state (GracefulDemotion*Running or
+// GracefulDemotion*Required) the controller needs to take care of.
+func (s CruiseControlMaintenanceState) IsActive() bool {
+	return s == GracefulDemotionRunning || s == GracefulDemotionRequired
+}
+
 // IsUpscale returns true if CruiseControlState in GracefulUpscale* state.
 func (r CruiseControlState) IsUpscale() bool {
 	return r == GracefulUpscaleRequired || r == GracefulUpscaleSucceeded || r == GracefulUpscaleRunning
@@ -115,6 +201,11 @@ func (r CruiseControlState) Complete() CruiseControlState {
 	}
 }
 
+// IsBlocking returns true if the smoke test has not passed and therefore further disruptive operations must wait
+func (s SmokeTestState) IsBlocking() bool {
+	return s == SmokeTestRunning || s == SmokeTestFailed
+}
+
 // IsSSL determines if the receiver is using SSL
 func (r SecurityProtocol) IsSSL() bool {
 	return r.Equal(SecurityProtocolSaslSSL) || r.Equal(SecurityProtocolSSL)
@@ -140,6 +231,28 @@ func (r SecurityProtocol) Equal(s SecurityProtocol) bool {
 	return r.ToUpperString() == s.ToUpperString()
 }
 
+const (
+	// AntiAffinityRequired places each broker to a different topology domain, brokers stay pending when there are
+	// not enough domains
+	AntiAffinityRequired AntiAffinityType = "Required"
+	// AntiAffinityPreferred spreads the brokers across the topology domains when possible
+	AntiAffinityPreferred AntiAffinityType = "Preferred"
+	// AntiAffinityNone generates no broker anti-affinity
+	AntiAffinityNone AntiAffinityType = "None"
+
+	// AffinityMergeOverride uses the user supplied affinity instead of the generated broker anti-affinity
+	AffinityMergeOverride AffinityMergeStrategy = "Override"
+	// AffinityMergeAppend appends the generated broker anti-affinity terms to the user supplied affinity
+	AffinityMergeAppend AffinityMergeStrategy = "Append"
+)
+
+const (
+	// ProcessRoleBroker makes the node store the partitions and serve the clients
+	ProcessRoleBroker ProcessRole = "broker"
+	// ProcessRoleController makes the node a voter of the KRaft metadata quorum
+	ProcessRoleController ProcessRole = "controller"
+)
+
 const (
 	// PKIBackendCertManager invokes cert-manager for user certificate management
 	PKIBackendCertManager PKIBackend = "cert-manager"
@@ -154,6 +267,25 @@ const (
 type IstioControlPlaneReference struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
+	// Revision is the Istio revision the mesh gateways of the cluster are bound to through the istio.io/rev label.
+	// It defaults to the revision of the referenced IstioControlPlane i.e. its name. During a canary upgrade of the
+	// mesh, pointing the reference to the IstioControlPlane of the new revision rolls the mesh gateways over to it
+	// by starting the new gateway pods before stopping the old ones, so external access is kept.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+}
+
+// GetRevision returns the Istio revision of the referenced IstioControlPlane
+func (r *IstioControlPlaneReference) GetRevision() string {
+	if r.Revision != "" {
+		return r.Revision
+	}
+	return strings.ReplaceAll(r.Name, ".", "-")
+}
+
+// GetNamespacedRevision returns the value of the istio.io/rev label selecting the referenced IstioControlPlane
+func (r *IstioControlPlaneReference) GetNamespacedRevision() string {
+	return fmt.Sprintf("%s.%s", r.GetRevision(), r.Namespace)
 }
 
 // GracefulActionState holds information about GracefulAction State
@@ -164,10 +296,40 @@ type GracefulActionState struct {
 	CruiseControlTaskId string `json:"cruiseControlTaskId,omitempty"`
 	// TaskStarted hold the time when the execution started
 	TaskStarted string `json:"TaskStarted,omitempty"`
+	// TaskFinished holds the time when the task was first seen finished in RFC3339
+	TaskFinished string `json:"taskFinished,omitempty"`
+	// TaskProgress holds the progress of the replica movements of the task while it is executed by CC
+	TaskProgress *CruiseControlTaskProgress `json:"taskProgress,omitempty"`
 	// CruiseControlState holds the information about CC state
 	CruiseControlState CruiseControlState `json:"cruiseControlState"`
 	// VolumeStates holds the information about the CC disk rebalance states and tasks
 	VolumeStates map[string]VolumeState `json:"volumeStates,omitempty"`
+	// MaintenanceState holds the information about the CC demotion of the broker while it is in maintenance
+	MaintenanceState *MaintenanceState `json:"maintenanceState,omitempty"`
+}
+
+// CruiseControlTaskProgress describes the progress of the replica movements executed by CC for a task
+type CruiseControlTaskProgress struct {
+	// ReplicaMovementsCompletedPercent is the percentage of the replica movements of the task which are finished
+	ReplicaMovementsCompletedPercent int32 `json:"replicaMovementsCompletedPercent"`
+	// DataMovedMB is the amount of data moved so far in MB
+	DataMovedMB int64 `json:"dataMovedMB"`
+	// DataToMoveMB is the total amount of data moved by the task in MB
+	DataToMoveMB int64 `json:"dataToMoveMB"`
+	// EstimatedCompletion is the estimated time of the completion of the task
+	// +optional
+	EstimatedCompletion string `json:"estimatedCompletion,omitempty"`
+}
+
+type MaintenanceState struct {
+	// ErrorMessage holds the information what happened with CC demotion
+	ErrorMessage string `json:"errorMessage"`
+	// CruiseControlTaskId holds info about the task id ran by CC
+	CruiseControlTaskId string `json:"cruiseControlTaskId,omitempty"`
+	// TaskStarted hold the time when the execution started
+	TaskStarted string `json:"TaskStarted,omitempty"`
+	// CruiseControlMaintenanceState holds the information about the CC demotion state
+	CruiseControlMaintenanceState CruiseControlMaintenanceState `json:"cruiseControlMaintenanceState"`
 }
 
 type VolumeState struct {
@@ -230,6 +392,14 @@ const (
 	// GracefulDiskRebalanceSucceeded states that the for the broker volume rebalance has succeeded
 	GracefulDiskRebalanceSucceeded CruiseControlVolumeState = "GracefulDiskRebalanceSucceeded"
 
+	// Maintenance cruise control states
+	// GracefulDemotionRequired states that the broker in maintenance needs a CC demotion
+	GracefulDemotionRequired CruiseControlMaintenanceState = "GracefulDemotionRequired"
+	// GracefulDemotionRunning states that the CC demotion of the broker in maintenance is in progress
+	GracefulDemotionRunning CruiseControlMaintenanceState = "GracefulDemotionRunning"
+	// GracefulDemotionSucceeded states that the broker in maintenance has been demoted
+	GracefulDemotionSucceeded CruiseControlMaintenanceState = "GracefulDemotionSucceeded"
+
 	// CruiseControlTopicNotReady states the CC required topic is not yet created
 	CruiseControlTopicNotReady CruiseControlTopicStatus = "CruiseControlTopicNotReady"
 	// CruiseControlTopicReady states the CC required topic is created
@@ -242,6 +412,8 @@ const (
 	CruiseControlTaskCompleted CruiseControlUserTaskState = "Completed"
 	// CruiseControlTaskCompletedWithError states the CC task completed with error
 	CruiseControlTaskCompletedWithError CruiseControlUserTaskState = "CompletedWithError"
+	// CruiseControlTaskUnavailable states the CC task could not be started as Cruise Control is unavailable
+	CruiseControlTaskUnavailable CruiseControlUserTaskState = "CruiseControlUnavailable"
 	// KafkaClusterReconciling states that the cluster is still in reconciling stage
 	KafkaClusterReconciling ClusterState = "ClusterReconciling"
 	// KafkaClusterRollingUpgrading states that the cluster is rolling upgrading
@@ -271,4 +443,166 @@ const (
 
 	// SSLClientAuthRequired states that the client authentication is required when SSL is enabled
 	SSLClientAuthRequired SSLClientAuthentication = "required"
+
+	// OperationUrgencyNormal states that the operation has to wait for an open maintenance window
+	OperationUrgencyNormal OperationUrgency = "Normal"
+	// OperationUrgencyUrgent states that the operation can be performed outside of maintenance windows
+	OperationUrgencyUrgent OperationUrgency = "Urgent"
+
+	// PreflightOperationVersionUpgrade states that the pre-flight checks were run before a Kafka version upgrade
+	PreflightOperationVersionUpgrade PreflightOperation = "VersionUpgrade"
+	// PreflightOperationScaleDown states that the pre-flight checks were run before removing brokers
+	PreflightOperationScaleDown PreflightOperation = "ScaleDown"
+
+	// SmokeTestRunning states that the smoke test Job is running
+	SmokeTestRunning SmokeTestState = "Running"
+	// SmokeTestPassed states that the smoke test Job succeeded
+	SmokeTestPassed SmokeTestState = "Passed"
+	// SmokeTestFailed states that the smoke test Job failed
+	SmokeTestFailed SmokeTestState = "Failed"
+
+	// OperationTypeRollingUpgrade is the restart of the broker pods one by one
+	OperationTypeRollingUpgrade OperationType = "RollingUpgrade"
+	// OperationTypeAddBroker is the Cruise Control task moving partitions to new brokers
+	OperationTypeAddBroker OperationType = "AddBroker"
+	// OperationTypeRemoveBroker is the Cruise Control task moving partitions off the removed brokers
+	OperationTypeRemoveBroker OperationType = "RemoveBroker"
+	// OperationTypeRebalanceDisks is the Cruise Control task moving partitions between the disks of the brokers
+	OperationTypeRebalanceDisks OperationType = "RebalanceDisks"
+	// OperationTypeDemoteBroker is the Cruise Control task moving the leadership off the brokers in maintenance
+	OperationTypeDemoteBroker OperationType = "DemoteBroker"
+	// OperationTypeRebalance is the Cruise Control task rebalancing the replicas of the cluster
+	OperationTypeRebalance OperationType = "Rebalance"
+
+	// OperationPending states that the task of the operation has not been started yet
+	OperationPending OperationOutcome = "Pending"
+	// OperationPaused states that the start of the task of the operation is held back
+	OperationPaused OperationOutcome = "Paused"
+	// OperationRunning states that the operation is in progress
+	OperationRunning OperationOutcome = "Running"
+	// OperationSucceeded states that the operation finished successfully
+	OperationSucceeded OperationOutcome = "Succeeded"
+	// OperationFailed states that the operation finished with an error
+	OperationFailed OperationOutcome = "Failed"
+
+	// BrokerLatencyHealthy states that the request latencies of the broker are within the thresholds
+	BrokerLatencyHealthy BrokerLatencyState = "Healthy"
+	// BrokerLatencySlow states that the produce or the fetch request latency of the broker breaches its threshold,
+	// like the slow broker anomaly of Cruise Control
+	BrokerLatencySlow BrokerLatencyState = "Slow"
+
+	// ControllerHealthy states that a single broker acts as the controller and it is stable
+	ControllerHealthy ControllerHealthState = "Healthy"
+	// ControllerDegraded states that there is no active controller, the brokers disagree on it, it changes too often
+	// or the KRaft quorum has no leader or lagging voters
+	ControllerDegraded ControllerHealthState = "Degraded"
+
+	// RebalancerCruiseControl reassigns the partition replicas via Cruise Control deployed by the operator or
+	// running at spec.cruiseControlConfig.cruiseControlEndpoint
+	RebalancerCruiseControl RebalancerType = "CruiseControl"
+	// RebalancerBuiltIn reassigns the partition replicas via the admin API of Kafka, Cruise Control is not deployed
+	RebalancerBuiltIn RebalancerType = "BuiltIn"
+
+	// BrokerConfigValidationPolicyDisabled turns off the validation of the broker configurations
+	BrokerConfigValidationPolicyDisabled BrokerConfigValidationPolicy = "Disabled"
+	// BrokerConfigValidationPolicyWarn reports the broker configuration issues in the status only
+	BrokerConfigValidationPolicyWarn BrokerConfigValidationPolicy = "Warn"
+	// BrokerConfigValidationPolicyEnforce holds back broker configuration changes containing errors
+	BrokerConfigValidationPolicyEnforce BrokerConfigValidationPolicy = "Enforce"
+
+	// VersionUpgradeRollingOutBinaries states that the brokers are rolled out to the new Kafka version with the
+	// inter-broker protocol pinned to the old one
+	VersionUpgradeRollingOutBinaries VersionUpgradePhase = "RollingOutBinaries"
+	// VersionUpgradeBumpingProtocol states that the pinned protocol versions are lifted and the brokers are rolled
+	// again to talk the protocol of the new Kafka version
+	VersionUpgradeBumpingProtocol VersionUpgradePhase = "BumpingProtocol"
+	// VersionUpgradeCompleted states that all brokers run the new Kafka version and talk its protocol
+	VersionUpgradeCompleted VersionUpgradePhase = "Completed"
+	// VersionUpgradeAborted states that the brokers are rolled back to the old Kafka version
+	VersionUpgradeAborted VersionUpgradePhase = "Aborted"
+
+	// RollingUpgradeConcurrencyAny lets any brokers not holding replicas of the same partition be restarted at once
+	RollingUpgradeConcurrencyAny RollingUpgradeConcurrencyPolicy = "Any"
+	// RollingUpgradeConcurrencyRack lets only the brokers of the same rack be restarted at once, so that the partitions
+	// with rack aware replica placement lose at most one replica at a time
+	RollingUpgradeConcurrencyRack RollingUpgradeConcurrencyPolicy = "Rack"
+
+	// RollingUpgradeOrderControllerLast restarts the active controller after the other brokers, so that the controller
+	// role moves only once
+	RollingUpgradeOrderControllerLast RollingUpgradeOrder = "ControllerLast"
+	// RollingUpgradeOrderLowestLoadFirst restarts the brokers leading the fewest partitions first
+	RollingUpgradeOrderLowestLoadFirst RollingUpgradeOrder = "LowestLoadFirst"
+	// RollingUpgradeOrderExplicit restarts the brokers in the order of their IDs listed in the rolling upgrade config
+	RollingUpgradeOrderExplicit RollingUpgradeOrder = "Explicit"
+
+	// ConfigIssueSeverityWarning states that the config may be ignored by the brokers, e.g. it is unknown or deprecated
+	ConfigIssueSeverityWarning ConfigIssueSeverity = "Warning"
+	// ConfigIssueSeverityError states that the brokers would refuse the config
+	ConfigIssueSeverityError ConfigIssueSeverity = "Error"
+
+	// EmergencyOverrideAnnotation on a KafkaCluster allows every disruptive operation to bypass maintenance windows.
+	// Its value should describe the reason of the override which is recorded in the emitted audit Events.
+	EmergencyOverrideAnnotation = "kafka.banzaicloud.io/emergency-override"
+
+	// RestartUrgencyAnnotation on a broker pod sets the OperationUrgency of its pending restart, Urgent lets the restart
+	// bypass the maintenance windows while Normal makes it wait for one even if the pod has failed. The annotation is
+	// gone once the pod is restarted.
+	RestartUrgencyAnnotation = "kafka.banzaicloud.io/restart-urgency"
+
+	// DeletionProtectionAnnotation set to DeletionProtectionEnabled on a KafkaCluster, KafkaTopic or KafkaUser makes
+	// the webhook reject the deletion of the resource until the annotation is removed
+	DeletionProtectionAnnotation = "kafka.banzaicloud.io/deletion-protection"
+	// DeletionProtectionEnabled is the value of DeletionProtectionAnnotation enabling the deletion protection
+	DeletionProtectionEnabled = "enabled"
+
+	// CancelCruiseControlTasksAnnotation set to "true" on a KafkaCluster stops the ongoing execution of Cruise Control
+	// and holds back its tasks until the annotation is removed
+	CancelCruiseControlTasksAnnotation = "kafka.banzaicloud.io/cancel-cruise-control-tasks"
+
+	// OperationPausedAnnotation set to "true" on a CruiseControlOperation, or on its KafkaCluster for all of them,
+	// holds back the start of the task of the pending operations until the annotation is removed. The tasks already
+	// started are tracked until they finish.
+	OperationPausedAnnotation = "kafka.banzaicloud.io/operation-paused"
+
+	// RestartGenerationAnnotation on a broker pod holds the restart generations requested for the broker, the pod is
+	// restarted when they change
+	RestartGenerationAnnotation = "kafka.banzaicloud.io/restart-generation"
+
+	// AdoptedAnnotation marks the KafkaTopics created for the topics of an adopted cluster, the webhook lets them
+	// reference the already existing topics
+	AdoptedAnnotation = "kafka.banzaicloud.io/adopted"
+
+	// IncomingNetworkThroughputAnnotation on a node sets the NW_IN Cruise Control capacity, in KB/s, of the brokers
+	// running on the node when spec.cruiseControlConfig.nodeNetworkCapacity is set
+	IncomingNetworkThroughputAnnotation = "kafka.banzaicloud.io/incoming-network-throughput"
+	// OutgoingNetworkThroughputAnnotation on a node sets the NW_OUT Cruise Control capacity, in KB/s, of the brokers
+	// running on the node when spec.cruiseControlConfig.nodeNetworkCapacity is set
+	OutgoingNetworkThroughputAnnotation = "kafka.banzaicloud.io/outgoing-network-throughput"
+
+	// AbortVersionUpgradeAnnotation set to "true" on a KafkaCluster rolls the brokers back to the old Kafka version
+	// while the version upgrade staged by the operator has not bumped the inter-broker protocol yet
+	AbortVersionUpgradeAnnotation = "kafka.banzaicloud.io/abort-version-upgrade"
 )
+
+// IsCruiseControlTaskCancellationRequested returns whether the Cruise Control tasks of the cluster with the given
+// annotations are to be cancelled
+func IsCruiseControlTaskCancellationRequested(annotations map[string]string) bool {
+	return annotations[CancelCruiseControlTasksAnnotation] == "true"
+}
+
+// IsOperationPaused returns whether the start of the pending CruiseControlOperations with the given annotations, or
+// the ones of the cluster with the given annotations, is held back
+func IsOperationPaused(annotations map[string]string) bool {
+	return annotations[OperationPausedAnnotation] == "true"
+}
+
+// IsDeletionProtected returns whether the deletion of the resource with the given annotations is blocked by the webhook
+func IsDeletionProtected(annotations map[string]string) bool {
+	return annotations[DeletionProtectionAnnotation] == DeletionProtectionEnabled
+}
+
+// IsVersionUpgradeAbortRequested returns whether the version upgrade of the cluster with the given annotations is to
+// be aborted
+func IsVersionUpgradeAbortRequested(annotations map[string]string) bool {
+	return annotations[AbortVersionUpgradeAnnotation] == "true"
+}
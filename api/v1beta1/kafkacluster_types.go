@@ -16,7 +16,10 @@ package v1beta1
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 
@@ -45,8 +48,42 @@ const (
 	DefaultEnvoyHealthCheckPort = 8080
 	// DefaultEnvoyAdminPort envoy admin port
 	DefaultEnvoyAdminPort = 8081
+	// DefaultEnvoyTargetCPUUtilizationPercentage envoy autoscaling CPU utilization target
+	DefaultEnvoyTargetCPUUtilizationPercentage int32 = 80
+	// DefaultEnvoyConnectionsMetricName custom pod metric of the active envoy downstream connections used for autoscaling
+	DefaultEnvoyConnectionsMetricName = "envoy_server_total_connections"
 	// DefaultBrokerTerminationGracePeriod default kafka pod termination grace period
 	DefaultBrokerTerminationGracePeriod = 120
+	// DefaultGoalViolationRemediationCooldown default minimum time between two remediations of the violations of a goal
+	DefaultGoalViolationRemediationCooldown = time.Hour
+	// DefaultOperationHistoryLimit default number of operations kept in the operation history of the cluster
+	DefaultOperationHistoryLimit = 20
+	// DefaultOperationRetryMaxAttempts default number of times the task of a failing CruiseControlOperation is started
+	DefaultOperationRetryMaxAttempts = 3
+	// DefaultOperationRetryBackoff default delay before the first retry of a failed CruiseControlOperation
+	DefaultOperationRetryBackoff = time.Minute
+	// MaxOperationRetryBackoff upper bound of the delay between two retries of a failed CruiseControlOperation
+	MaxOperationRetryBackoff = time.Hour
+	// DefaultDiskSkewRebalanceCooldown default minimum time between two rebalances started by the disk usage skew
+	DefaultDiskSkewRebalanceCooldown = time.Hour
+	// DefaultBrokerAutoscalingCooldown default minimum time between two adjustments of the broker count by the autoscaler
+	DefaultBrokerAutoscalingCooldown = 30 * time.Minute
+	// DefaultResourceRecommendationMinChangePercent default difference from the applied resource requests in percent
+	// below which the recommendations of a VerticalPodAutoscaler are not applied to the brokers
+	DefaultResourceRecommendationMinChangePercent = 10
+)
+
+// CruiseControlErrorPolicy defines how the failed CruiseControlOperations of a cluster are handled
+type CruiseControlErrorPolicy string
+
+const (
+	// CruiseControlErrorPolicyRetry starts the task of the failed operation again until it runs out of attempts
+	CruiseControlErrorPolicyRetry CruiseControlErrorPolicy = "retry"
+	// CruiseControlErrorPolicyIgnore leaves the failed operation failed, the other operations proceed
+	CruiseControlErrorPolicyIgnore CruiseControlErrorPolicy = "ignore"
+	// CruiseControlErrorPolicyBlock leaves the failed operation failed and starts no other operation of the cluster
+	// until the failed one is deleted
+	CruiseControlErrorPolicyBlock CruiseControlErrorPolicy = "block"
 )
 
 // KafkaClusterSpec defines the desired state of KafkaCluster
@@ -55,10 +92,17 @@ type KafkaClusterSpec struct {
 	ListenersConfig        ListenersConfig `json:"listenersConfig"`
 	// ZKAddresses specifies the ZooKeeper connection string
 	// in the form hostname:port where host and port are the host and port of a ZooKeeper server.
-	ZKAddresses []string `json:"zkAddresses"`
+	// It is required unless the cluster runs in KRaft mode.
+	// +optional
+	ZKAddresses []string `json:"zkAddresses,omitempty"`
 	// ZKPath specifies the ZooKeeper chroot path as part
 	// of its ZooKeeper connection string which puts its data under some path in the global ZooKeeper namespace.
-	ZKPath                      string                  `json:"zkPath,omitempty"`
+	ZKPath string `json:"zkPath,omitempty"`
+	// KRaftMode runs the cluster without ZooKeeper on Kafka 3.x, the metadata is managed by the KRaft quorum of the
+	// brokers with the controller process role. The internal listener usedForControllerCommunication becomes the
+	// controller listener of the quorum. It can not be changed once the cluster is created.
+	// +optional
+	KRaftMode                   bool                    `json:"kRaft,omitempty"`
 	RackAwareness               *RackAwareness          `json:"rackAwareness,omitempty"`
 	ClusterImage                string                  `json:"clusterImage,omitempty"`
 	ClusterMetricsReporterImage string                  `json:"clusterMetricsReporterImage,omitempty"`
@@ -68,20 +112,56 @@ type KafkaClusterSpec struct {
 	Brokers                     []Broker                `json:"brokers"`
 	DisruptionBudget            DisruptionBudget        `json:"disruptionBudget,omitempty"`
 	RollingUpgradeConfig        RollingUpgradeConfig    `json:"rollingUpgradeConfig"`
+	// BrokerGroupReplicas sets the number of brokers of the given broker config groups. The operator adds brokers of
+	// a group to spec.brokers or removes the ones with the highest IDs until the group has the set number of brokers,
+	// the brokers of the groups not listed are left as they are.
+	// +optional
+	BrokerGroupReplicas map[string]int32 `json:"brokerGroupReplicas,omitempty"`
+	// MaintenanceWindows restricts disruptive operations, like restarting broker pods, to the given recurring time windows.
+	// Disruptive operations are not restricted when no window is specified. Urgent operations (e.g. replacing a failed broker)
+	// and clusters annotated with kafka.banzaicloud.io/emergency-override bypass the windows. The urgency of restarting a
+	// broker pod can be set explicitly with the kafka.banzaicloud.io/restart-urgency annotation of the pod.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// PreflightChecks defines the checks which have to pass before a Kafka version upgrade or a broker scale down is started
+	// +optional
+	PreflightChecks PreflightChecksConfig `json:"preflightChecks,omitempty"`
+	// SmokeTest defines the produce/consume verification Job run after rolling upgrades and scaling events
+	// +optional
+	SmokeTest SmokeTestConfig `json:"smokeTest,omitempty"`
+	// BrokerConfigValidation defines how the broker configurations are validated against the config schema of the
+	// Kafka version the brokers run
+	// +optional
+	BrokerConfigValidation BrokerConfigValidationConfig `json:"brokerConfigValidation,omitempty"`
+	// ConnectionInfo defines the publishing of the client connection details of the listeners
+	// +optional
+	ConnectionInfo ConnectionInfoConfig `json:"connectionInfo,omitempty"`
+	// DevProfile runs a minimal-footprint cluster for local development, e.g. on kind or minikube
+	// +optional
+	DevProfile DevProfileConfig `json:"devProfile,omitempty"`
 	// +kubebuilder:validation:Enum=envoy;istioingress
 	// IngressController specifies the type of the ingress controller to be used for external listeners. The `istioingress` ingress controller type requires the `spec.istioControlPlane` field to be populated as well.
 	IngressController string `json:"ingressController,omitempty"`
 	// IstioControlPlane is a reference to the IstioControlPlane resource for envoy configuration. It must be specified if istio ingress is used.
 	IstioControlPlane *IstioControlPlaneReference `json:"istioControlPlane,omitempty"`
+	// Architectures restricts the pods of every component (brokers, Cruise Control, Envoy) to nodes with one of the
+	// given CPU architectures, unless the component overrides it. The images in use must be published for them.
+	// Pods can be scheduled to nodes of any architecture when it is empty.
+	// +optional
+	Architectures []NodeArchitecture `json:"architectures,omitempty"`
 	// If true OneBrokerPerNode ensures that each kafka broker will be placed on a different node unless a custom
 	// Affinity definition overrides this behavior
-	OneBrokerPerNode    bool                `json:"oneBrokerPerNode"`
-	PropagateLabels     bool                `json:"propagateLabels,omitempty"`
-	CruiseControlConfig CruiseControlConfig `json:"cruiseControlConfig"`
-	EnvoyConfig         EnvoyConfig         `json:"envoyConfig,omitempty"`
-	MonitoringConfig    MonitoringConfig    `json:"monitoringConfig,omitempty"`
-	AlertManagerConfig  *AlertManagerConfig `json:"alertManagerConfig,omitempty"`
-	IstioIngressConfig  IstioIngressConfig  `json:"istioIngressConfig,omitempty"`
+	OneBrokerPerNode bool `json:"oneBrokerPerNode"`
+	// BrokerAntiAffinity configures the anti-affinity generated for the broker pods, it takes precedence over
+	// OneBrokerPerNode
+	// +optional
+	BrokerAntiAffinity  *BrokerAntiAffinityConfig `json:"brokerAntiAffinity,omitempty"`
+	PropagateLabels     bool                      `json:"propagateLabels,omitempty"`
+	CruiseControlConfig CruiseControlConfig       `json:"cruiseControlConfig"`
+	EnvoyConfig         EnvoyConfig               `json:"envoyConfig,omitempty"`
+	MonitoringConfig    MonitoringConfig          `json:"monitoringConfig,omitempty"`
+	AlertManagerConfig  *AlertManagerConfig       `json:"alertManagerConfig,omitempty"`
+	IstioIngressConfig  IstioIngressConfig        `json:"istioIngressConfig,omitempty"`
 	// Envs defines environment variables for Kafka broker Pods.
 	// Adding the "+" prefix to the name prepends the value to that environment variable instead of overwriting it.
 	// Add the "+" suffix to append.
@@ -94,6 +174,197 @@ type KafkaClusterSpec struct {
 	// The secret must contains the keystore, truststore jks files and the password for them in base64 encoded format
 	// under the keystore.jks, truststore.jks, password data fields.
 	ClientSSLCertSecret *corev1.LocalObjectReference `json:"clientSSLCertSecret,omitempty"`
+	// Tenancy restricts the namespaces other than the namespace of the cluster in which KafkaTopics and KafkaUsers
+	// referencing the cluster can be created, and limits their usage of the cluster. KafkaTopics and KafkaUsers of any
+	// namespace can reference the cluster when it is not set.
+	// +optional
+	Tenancy *TenancyConfig `json:"tenancy,omitempty"`
+	// Adoption makes the operator take over the management of a Kafka cluster deployed outside of it. The broker
+	// pods, services and PVCs labeled like the ones of the operator are adopted in place, the changes which would
+	// restart or remove brokers or override the cluster-wide configs are held back until the adoption is approved.
+	// +optional
+	Adoption *AdoptionConfig `json:"adoption,omitempty"`
+	// OperationHistoryLimit is the number of operations kept in status.operationHistory, 20 when not set
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	OperationHistoryLimit *int32 `json:"operationHistoryLimit,omitempty"`
+	// OperationRetention limits how many and how long the finished operations of the cluster are kept, the finished
+	// CruiseControlOperations of the cluster and the records of status.operationHistory are removed beyond it
+	// +optional
+	OperationRetention *OperationRetentionConfig `json:"operationRetention,omitempty"`
+	// CapacityHeadroom defines the capacity kept free on the brokers. Topic creations and partition count increases
+	// are rejected while it is breached and the number of brokers to add is recommended in status.capacityHeadroom.
+	// +optional
+	CapacityHeadroom *CapacityHeadroomConfig `json:"capacityHeadroom,omitempty"`
+	// InternalTopics enables the periodic health checks of the __consumer_offsets and __transaction_state internal
+	// topics, the replication factor, the in-sync replicas and the leadership of their partitions are reported in
+	// status.internalTopics
+	// +optional
+	InternalTopics *InternalTopicsConfig `json:"internalTopics,omitempty"`
+	// RequestLatency enables the produce and fetch request latency SLI of the brokers, the p99 latencies reported by
+	// the JMX exporters of the brokers are compared to the thresholds and the brokers breaching them are marked Slow
+	// in status.requestLatency
+	// +optional
+	RequestLatency *RequestLatencyConfig `json:"requestLatency,omitempty"`
+	// ControllerHealth enables the periodic health checks of the controller and, for KRaft clusters, of the metadata
+	// quorum, the active controller, the controller changes and the quorum lag are reported in status.controllerHealth
+	// +optional
+	ControllerHealth *ControllerHealthConfig `json:"controllerHealth,omitempty"`
+	// BrokerDecommission defines how the data of the brokers removed from spec.brokers is handled, the PVCs of the
+	// removed brokers can be kept for a grace period so that the removal can be reverted by re-adding the broker
+	// +optional
+	BrokerDecommission *BrokerDecommissionConfig `json:"brokerDecommission,omitempty"`
+	// VersionUpgrade makes the operator stage the upgrades of spec.clusterImage to a newer minor Kafka version. The
+	// brokers are rolled out to the new version with inter.broker.protocol.version, and log.message.format.version
+	// below Kafka 3.0, pinned to the old version, then the pins are lifted and the brokers are rolled again. The phase
+	// is reported in status.versionUpgrade. Ignored in KRaft mode and when readOnlyConfig sets the protocol versions.
+	// +optional
+	VersionUpgrade *VersionUpgradeConfig `json:"versionUpgrade,omitempty"`
+	// Rebalancer selects the component reassigning the partition replicas when brokers are added or removed.
+	// BuiltIn computes simple rack-aware reassignments in the operator for small clusters where running Cruise
+	// Control is too heavy, Cruise Control is not deployed then and the features relying on it are not available.
+	// Defaults to CruiseControl.
+	// +kubebuilder:validation:Enum=CruiseControl;BuiltIn
+	// +optional
+	Rebalancer RebalancerType `json:"rebalancer,omitempty"`
+}
+
+// VersionUpgradeConfig defines the staging of the Kafka version upgrades
+type VersionUpgradeConfig struct {
+	// ProtocolBumpDelay is the time to wait for after all brokers run the new Kafka version before the inter-broker
+	// protocol is bumped, e.g. 1h. The upgrade can be aborted with the kafka.banzaicloud.io/abort-version-upgrade
+	// annotation until then. The protocol is bumped right away when not set.
+	// +optional
+	ProtocolBumpDelay *metav1.Duration `json:"protocolBumpDelay,omitempty"`
+}
+
+// GetProtocolBumpDelay returns the time to wait for before the inter-broker protocol is bumped
+func (c *VersionUpgradeConfig) GetProtocolBumpDelay() time.Duration {
+	if c.ProtocolBumpDelay == nil || c.ProtocolBumpDelay.Duration < 0 {
+		return 0
+	}
+	return c.ProtocolBumpDelay.Duration
+}
+
+// BrokerDecommissionConfig defines the retention of the data of the removed brokers
+type BrokerDecommissionConfig struct {
+	// DataRetentionPeriod is the time the PVCs of a removed broker are kept for, e.g. 24h. The PVCs are reused when
+	// the broker is re-added with the same ID within the period and deleted after it. The PVCs are deleted together
+	// with the broker when not set.
+	// +optional
+	DataRetentionPeriod *metav1.Duration `json:"dataRetentionPeriod,omitempty"`
+}
+
+// RequestLatencyConfig defines the request latency thresholds the brokers are marked Slow by
+type RequestLatencyConfig struct {
+	// ProduceP99ThresholdMs is the p99 produce request latency in milliseconds above which a broker is Slow,
+	// 1000 when not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProduceP99ThresholdMs int32 `json:"produceP99ThresholdMs,omitempty"`
+	// FetchP99ThresholdMs is the p99 consumer fetch request latency in milliseconds above which a broker is Slow,
+	// 2000 when not set. It includes the fetch.max.wait.ms the consumers wait for data.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FetchP99ThresholdMs int32 `json:"fetchP99ThresholdMs,omitempty"`
+	// AutoDemote makes the operator demote the Slow brokers via Cruise Control moving the leadership of their
+	// partitions to the other brokers. A single broker is demoted at a time and none while half or more of the
+	// brokers are Slow, as it points to a cluster-wide issue rather than a degraded broker.
+	// +optional
+	AutoDemote bool `json:"autoDemote,omitempty"`
+}
+
+// ControllerHealthConfig defines the thresholds the controller health is Degraded by
+type ControllerHealthConfig struct {
+	// MaxControllerChangesPerHour is the number of controller changes within an hour above which the controller is
+	// considered unstable, 3 when not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxControllerChangesPerHour int32 `json:"maxControllerChangesPerHour,omitempty"`
+	// MaxVoterLag is the number of metadata log records a voter of a KRaft quorum may lag behind the quorum leader,
+	// 10000 when not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxVoterLag int64 `json:"maxVoterLag,omitempty"`
+}
+
+// InternalTopicsConfig defines the health checks of the internal topics of Kafka
+type InternalTopicsConfig struct {
+	// RepairReplicationFactor makes the operator raise the replication factor of the internal topics having less
+	// replicas than configured by offsets.topic.replication.factor and transaction.state.log.replication.factor
+	// via Cruise Control
+	// +optional
+	RepairReplicationFactor bool `json:"repairReplicationFactor,omitempty"`
+}
+
+// CapacityHeadroomConfig defines the capacity kept free on the brokers
+type CapacityHeadroomConfig struct {
+	// MaxDiskUsagePercent is the disk utilization, as reported by Cruise Control, the brokers are kept below
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MaxDiskUsagePercent int32 `json:"maxDiskUsagePercent"`
+}
+
+// OperationRetentionConfig defines the retention of the finished operations of the cluster
+type OperationRetentionConfig struct {
+	// MaxCount is the number of finished CruiseControlOperations of the cluster kept, the oldest ones are deleted
+	// beyond it. The number of records of status.operationHistory is limited by operationHistoryLimit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+	// MaxAge is the time the finished operations are kept for, the finished CruiseControlOperations created and the
+	// records of status.operationHistory finished longer ago are removed
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// AdoptionConfig defines how the operator takes over the management of a pre-existing Kafka cluster
+type AdoptionConfig struct {
+	// ImportTopics creates KafkaTopics for the topics of the cluster having none, the internal topics are skipped
+	// +optional
+	ImportTopics bool `json:"importTopics,omitempty"`
+	// Approved lets the operator converge the adopted cluster to the KafkaCluster, the held back changes listed in
+	// status.adoption are applied through the regular rolling upgrade and downscale flows
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+}
+
+// TenancyConfig defines the application namespaces sharing the cluster
+type TenancyConfig struct {
+	// Namespaces are the namespaces allowed to create KafkaTopics and KafkaUsers referencing the cluster besides the
+	// namespace of the cluster, which is only limited if it is listed as well
+	Namespaces []TenantNamespace `json:"namespaces,omitempty"`
+}
+
+// TenantNamespace defines the budget of a namespace sharing the cluster
+type TenantNamespace struct {
+	Name string `json:"name"`
+	// MaxTopics is the maximum number of KafkaTopics of the namespace, unlimited when not set
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxTopics *int32 `json:"maxTopics,omitempty"`
+	// MaxPartitions is the maximum total number of partitions of the KafkaTopics of the namespace, unlimited when
+	// not set
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPartitions *int32 `json:"maxPartitions,omitempty"`
+	// TopicNamePrefix is the prefix the names of the topics of the namespace, and of the topics its KafkaUsers are
+	// granted access to, have to start with
+	// +optional
+	TopicNamePrefix string `json:"topicNamePrefix,omitempty"`
+}
+
+// GetNamespace returns the budget of the namespace, nil if the namespace is not listed
+func (t *TenancyConfig) GetNamespace(namespace string) *TenantNamespace {
+	if t == nil {
+		return nil
+	}
+	for i := range t.Namespaces {
+		if t.Namespaces[i].Name == namespace {
+			return &t.Namespaces[i]
+		}
+	}
+	return nil
 }
 
 // KafkaClusterStatus defines the observed state of KafkaCluster
@@ -104,6 +375,564 @@ type KafkaClusterStatus struct {
 	RollingUpgrade           RollingUpgradeStatus     `json:"rollingUpgradeStatus,omitempty"`
 	AlertCount               int                      `json:"alertCount"`
 	ListenerStatuses         ListenerStatuses         `json:"listenerStatuses,omitempty"`
+	// PreflightChecks holds the report of the last pre-flight checks
+	PreflightChecks *PreflightChecksStatus `json:"preflightChecks,omitempty"`
+	// SmokeTest holds the outcome of the last post-change verification
+	SmokeTest *SmokeTestStatus `json:"smokeTest,omitempty"`
+	// BrokerConfigValidation holds the issues found by the last validation of the broker configurations
+	BrokerConfigValidation *BrokerConfigValidationStatus `json:"brokerConfigValidation,omitempty"`
+	// CruiseControlLoad holds the last snapshot of the broker loads reported by Cruise Control
+	CruiseControlLoad *CruiseControlLoadStatus `json:"cruiseControlLoad,omitempty"`
+	// GoalViolationRemediation holds the last remediations of the goal violations detected by Cruise Control
+	GoalViolationRemediation *GoalViolationRemediationStatus `json:"goalViolationRemediation,omitempty"`
+	// TenantUsage holds the usage of the cluster by the KafkaTopics of each namespace listed in spec.tenancy
+	TenantUsage map[string]TenantUsage `json:"tenantUsage,omitempty"`
+	// Adoption describes the takeover of the cluster when spec.adoption is set
+	Adoption *AdoptionStatus `json:"adoption,omitempty"`
+	// OperationHistory holds the last rolling upgrades and Cruise Control tasks of the cluster, oldest first
+	OperationHistory []OperationRecord `json:"operationHistory,omitempty"`
+	// CapacityHeadroom describes the breach of spec.capacityHeadroom by the last broker loads of Cruise Control
+	CapacityHeadroom *CapacityHeadroomStatus `json:"capacityHeadroom,omitempty"`
+	// InternalTopics holds the last health checks of the internal topics when spec.internalTopics is set
+	InternalTopics *InternalTopicsStatus `json:"internalTopics,omitempty"`
+	// ListenerMetrics holds the last snapshot of the connections and the byte rates of the listeners reported by the
+	// JMX exporters of the brokers
+	ListenerMetrics *ListenerMetricsStatus `json:"listenerMetrics,omitempty"`
+	// RequestLatency holds the last snapshot of the request latencies of the brokers when spec.requestLatency is set
+	RequestLatency *RequestLatencyStatus `json:"requestLatency,omitempty"`
+	// ControllerHealth holds the last health check of the controller when spec.controllerHealth is set
+	ControllerHealth *ControllerHealthStatus `json:"controllerHealth,omitempty"`
+	// DecommissionedBrokers holds the tombstones of the removed brokers the PVCs of which are kept when
+	// spec.brokerDecommission.dataRetentionPeriod is set
+	DecommissionedBrokers []DecommissionedBroker `json:"decommissionedBrokers,omitempty"`
+	// CruiseControlRightsize describes the last request submitted to the provisioner of Cruise Control when
+	// spec.cruiseControlConfig.rightsize is set
+	CruiseControlRightsize *CruiseControlRightsizeStatus `json:"cruiseControlRightsize,omitempty"`
+	// TopicRebalance describes the last rebalance started when spec.cruiseControlConfig.topicRebalance is set
+	TopicRebalance *TopicRebalanceStatus `json:"topicRebalance,omitempty"`
+	// ScheduledRebalance describes the last and the next rebalance when spec.cruiseControlConfig.rebalanceSchedule
+	// is set
+	ScheduledRebalance *ScheduledRebalanceStatus `json:"scheduledRebalance,omitempty"`
+	// DiskSkewRebalance describes the last disk usage skew and the last rebalance it started when
+	// spec.cruiseControlConfig.diskSkewRebalance is set
+	DiskSkewRebalance *DiskSkewRebalanceStatus `json:"diskSkewRebalance,omitempty"`
+	// CruiseControlOperations summarizes the in-flight and the recently finished Cruise Control operations of the
+	// cluster, both the tasks of the brokers started by the operator and the CruiseControlOperations
+	CruiseControlOperations *CruiseControlOperationsStatus `json:"cruiseControlOperations,omitempty"`
+	// KRaft holds the bootstrap state of the metadata quorum when spec.kRaft is set
+	KRaft *KRaftStatus `json:"kRaft,omitempty"`
+	// VersionUpgrade describes the last Kafka version upgrade staged by the operator when spec.versionUpgrade is set
+	VersionUpgrade *VersionUpgradeStatus `json:"versionUpgrade,omitempty"`
+	// BrokerAutoscaling describes the last decision of the broker autoscaler when
+	// spec.cruiseControlConfig.autoscaling is set
+	BrokerAutoscaling *BrokerAutoscalingStatus `json:"brokerAutoscaling,omitempty"`
+	// ResourceRecommendations are the resource requests applied to the brokers from the recommendations of the
+	// VerticalPodAutoscalers of their resourceRecommendation, keyed by the name of the VerticalPodAutoscaler
+	ResourceRecommendations map[string]ResourceRecommendationStatus `json:"resourceRecommendations,omitempty"`
+}
+
+// ResourceRecommendationStatus describes the recommendation of a VerticalPodAutoscaler applied to the brokers
+type ResourceRecommendationStatus struct {
+	// Requests are the resource requests of the kafka container applied to the brokers
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+	// AppliedAt is the time the requests were applied at
+	AppliedAt string `json:"appliedAt,omitempty"`
+}
+
+// BrokerAutoscalingStatus describes the broker count called for by the last broker loads of Cruise Control
+type BrokerAutoscalingStatus struct {
+	// DesiredBrokers is the number of brokers the last broker loads call for within the bounds
+	DesiredBrokers int32 `json:"desiredBrokers"`
+	// Reason describes the load the desired number of brokers is driven by
+	Reason string `json:"reason,omitempty"`
+	// LastScaledAt is the time the broker count was last adjusted at
+	LastScaledAt string `json:"lastScaledAt,omitempty"`
+	// AddedBrokers are the IDs of the brokers added by the last adjustment
+	AddedBrokers []string `json:"addedBrokers,omitempty"`
+	// RemovedBrokers are the IDs of the brokers removed by the last adjustment
+	RemovedBrokers []string `json:"removedBrokers,omitempty"`
+}
+
+// VersionUpgradeStatus describes a Kafka version upgrade staged by the operator
+type VersionUpgradeStatus struct {
+	Phase       VersionUpgradePhase `json:"phase"`
+	FromImage   string              `json:"fromImage"`
+	ToImage     string              `json:"toImage"`
+	FromVersion string              `json:"fromVersion"`
+	ToVersion   string              `json:"toVersion"`
+	// ProtocolVersion is the version inter.broker.protocol.version is pinned to while the brokers are rolled out to
+	// the new Kafka version or rolled back to the old one. The pin is kept after an abort.
+	ProtocolVersion string `json:"protocolVersion"`
+	StartedAt       string `json:"startedAt"`
+	// BinariesRolledOutAt is the time all brokers were found running the new Kafka version at
+	BinariesRolledOutAt string `json:"binariesRolledOutAt,omitempty"`
+	// ProtocolBumpedAt is the time the pinned protocol versions were lifted at
+	ProtocolBumpedAt string `json:"protocolBumpedAt,omitempty"`
+	// FinishedAt is set once the upgrade is completed or the brokers of an aborted upgrade are no longer rolled back
+	FinishedAt string `json:"finishedAt,omitempty"`
+	// Conditions describe the phase transitions of the upgrade, oldest first
+	Conditions []VersionUpgradeCondition `json:"conditions,omitempty"`
+}
+
+// VersionUpgradeCondition describes a phase transition of a Kafka version upgrade
+type VersionUpgradeCondition struct {
+	Phase          VersionUpgradePhase `json:"phase"`
+	Message        string              `json:"message"`
+	TransitionTime string              `json:"transitionTime"`
+}
+
+// IsFinished returns whether the upgrade is completed or its aborted brokers are no longer rolled back
+func (s *VersionUpgradeStatus) IsFinished() bool {
+	return s.FinishedAt != ""
+}
+
+// GetPinnedProtocolVersion returns the version inter.broker.protocol.version is pinned to, empty when the brokers
+// talk the protocol of the Kafka version they run
+func (s *VersionUpgradeStatus) GetPinnedProtocolVersion() string {
+	if s == nil || (s.Phase != VersionUpgradeRollingOutBinaries && s.Phase != VersionUpgradeAborted) {
+		return ""
+	}
+	return s.ProtocolVersion
+}
+
+// SetPhase moves the upgrade to the given phase and records the transition in its conditions
+func (s *VersionUpgradeStatus) SetPhase(phase VersionUpgradePhase, message, transitionTime string) {
+	s.Phase = phase
+	s.Conditions = append(s.Conditions, VersionUpgradeCondition{
+		Phase:          phase,
+		Message:        message,
+		TransitionTime: transitionTime,
+	})
+}
+
+// KRaftStatus is the bootstrap state of the KRaft metadata quorum
+type KRaftStatus struct {
+	// ClusterID is the ID the storage of the nodes is formatted with, it is generated once when the cluster is
+	// created
+	ClusterID string `json:"clusterId"`
+}
+
+// DecommissionedBroker is the tombstone of a removed broker the PVCs of which are kept
+type DecommissionedBroker struct {
+	BrokerID         string `json:"brokerId"`
+	DecommissionedAt string `json:"decommissionedAt"`
+	// RetainUntil is the time after which the PVCs of the broker are deleted
+	RetainUntil string `json:"retainUntil"`
+	// PersistentVolumeClaims are the names of the kept PVCs holding the data of the broker
+	PersistentVolumeClaims []string `json:"persistentVolumeClaims,omitempty"`
+}
+
+// RequestLatencyStatus is a snapshot of the produce and fetch request latencies of the brokers
+type RequestLatencyStatus struct {
+	UpdatedAt string                 `json:"updatedAt"`
+	Brokers   []BrokerRequestLatency `json:"brokers,omitempty"`
+}
+
+// BrokerRequestLatency describes the request latencies of a broker, the latencies have two decimals
+type BrokerRequestLatency struct {
+	BrokerID string `json:"brokerId"`
+	// ProduceP99Ms is the p99 produce request latency of the broker in milliseconds
+	ProduceP99Ms string `json:"produceP99Ms"`
+	// FetchP99Ms is the p99 consumer fetch request latency of the broker in milliseconds
+	FetchP99Ms string             `json:"fetchP99Ms"`
+	State      BrokerLatencyState `json:"state"`
+	// DemoteTaskID is the ID of the Cruise Control task demoting the broker since it is Slow
+	DemoteTaskID string `json:"demoteTaskId,omitempty"`
+}
+
+// GetBroker returns the request latencies of the broker, nil if they were not reported
+func (s *RequestLatencyStatus) GetBroker(brokerID string) *BrokerRequestLatency {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Brokers {
+		if s.Brokers[i].BrokerID == brokerID {
+			return &s.Brokers[i]
+		}
+	}
+	return nil
+}
+
+// ListenerMetricsStatus is a snapshot of the connections and the byte rates of the listeners of the cluster
+type ListenerMetricsStatus struct {
+	UpdatedAt string            `json:"updatedAt"`
+	Listeners []ListenerMetrics `json:"listeners,omitempty"`
+}
+
+// ListenerMetrics describes the connections and the byte rates of a listener summed over the brokers, the rates
+// have two decimals
+type ListenerMetrics struct {
+	Name        string `json:"name"`
+	Connections int64  `json:"connections"`
+	// IncomingKBps is the incoming byte rate of the listener in KB/s
+	IncomingKBps string `json:"incomingKBps"`
+	// OutgoingKBps is the outgoing byte rate of the listener in KB/s
+	OutgoingKBps string `json:"outgoingKBps"`
+	// BusiestBrokerID is the ID of the broker with the highest incoming and outgoing byte rate on the listener
+	BusiestBrokerID string `json:"busiestBrokerId,omitempty"`
+}
+
+// ControllerHealthStatus describes the health of the controller and, for KRaft clusters, of the metadata quorum
+type ControllerHealthStatus struct {
+	// CheckedAt is the time of the last health check
+	CheckedAt string                `json:"checkedAt,omitempty"`
+	State     ControllerHealthState `json:"state"`
+	// ActiveControllerID is the ID of the broker acting as the controller, -1 when there is no active controller
+	ActiveControllerID int32 `json:"activeControllerId"`
+	// ActiveControllers is the number of brokers reporting to be the active controller, more than one means the
+	// brokers disagree on the controller
+	ActiveControllers int32 `json:"activeControllers"`
+	// ControllerEpoch is the epoch of the KRaft quorum leader, it is not reported by the brokers in ZooKeeper mode
+	ControllerEpoch int64 `json:"controllerEpoch,omitempty"`
+	// ControllerChanges is the number of consecutive controller changes observed less than an hour apart, it is
+	// reset after an hour without controller changes
+	ControllerChanges int32 `json:"controllerChanges,omitempty"`
+	// LastControllerChangeAt is the time the last controller change was observed at
+	LastControllerChangeAt string `json:"lastControllerChangeAt,omitempty"`
+	// Quorum describes the KRaft metadata quorum, empty in ZooKeeper mode
+	Quorum *QuorumStatus `json:"quorum,omitempty"`
+	// Warnings are the health issues found
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// QuorumStatus describes the KRaft metadata quorum
+type QuorumStatus struct {
+	// LeaderID is the ID of the quorum leader, -1 when the quorum has no leader
+	LeaderID int32 `json:"leaderId"`
+	// HighWatermark is the offset of the metadata log committed by the quorum
+	HighWatermark int64 `json:"highWatermark"`
+	// Voters are the voters reporting their metadata log
+	Voters []QuorumVoterStatus `json:"voters,omitempty"`
+}
+
+// QuorumVoterStatus describes a voter of the KRaft metadata quorum
+type QuorumVoterStatus struct {
+	ID string `json:"id"`
+	// LogEndOffset is the end offset of the metadata log of the voter
+	LogEndOffset int64 `json:"logEndOffset"`
+	// Lag is the number of metadata log records the voter lags behind the quorum leader
+	Lag int64 `json:"lag"`
+}
+
+// InternalTopicsStatus describes the health of the internal topics of Kafka
+type InternalTopicsStatus struct {
+	// CheckedAt is the time of the last health check
+	CheckedAt string                `json:"checkedAt,omitempty"`
+	Topics    []InternalTopicHealth `json:"topics,omitempty"`
+}
+
+// InternalTopicHealth describes the health of an internal topic of Kafka
+type InternalTopicHealth struct {
+	Name string `json:"name"`
+	// ReplicationFactor is the smallest number of replicas of the partitions of the topic
+	ReplicationFactor int32 `json:"replicationFactor"`
+	// ExpectedReplicationFactor is the replication factor configured for the topic, capped by the number of brokers
+	ExpectedReplicationFactor int32 `json:"expectedReplicationFactor"`
+	UnderReplicatedPartitions int32 `json:"underReplicatedPartitions,omitempty"`
+	// OfflinePartitions is the number of partitions without a leader
+	OfflinePartitions int32 `json:"offlinePartitions,omitempty"`
+	// NonPreferredLeaders is the number of partitions not led by their preferred replica
+	NonPreferredLeaders int32 `json:"nonPreferredLeaders,omitempty"`
+	// MaxLeadersPerBroker is the largest number of partitions of the topic led by a single broker
+	MaxLeadersPerBroker int32 `json:"maxLeadersPerBroker,omitempty"`
+	// Warnings are the health issues found
+	Warnings []string `json:"warnings,omitempty"`
+	// RepairTaskID is the ID of the last Cruise Control task raising the replication factor of the topic
+	RepairTaskID string `json:"repairTaskId,omitempty"`
+}
+
+// IsHealthy returns true if no health issues were found for the topic
+func (h InternalTopicHealth) IsHealthy() bool {
+	return len(h.Warnings) == 0
+}
+
+// GetTopic returns the health of the internal topic, nil if it was not checked
+func (s *InternalTopicsStatus) GetTopic(name string) *InternalTopicHealth {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Topics {
+		if s.Topics[i].Name == name {
+			return &s.Topics[i]
+		}
+	}
+	return nil
+}
+
+// CapacityHeadroomStatus describes the breach of the capacity headroom of the cluster
+type CapacityHeadroomStatus struct {
+	// BrokersAboveThreshold are the IDs of the brokers whose disk utilization is not below maxDiskUsagePercent
+	BrokersAboveThreshold []string `json:"brokersAboveThreshold,omitempty"`
+	// RecommendedAdditionalBrokers is the number of brokers to add to bring the average disk utilization below
+	// maxDiskUsagePercent, a rebalance is enough when it is zero while brokers are above the threshold
+	RecommendedAdditionalBrokers int32 `json:"recommendedAdditionalBrokers,omitempty"`
+}
+
+// CruiseControlRightsizeStatus describes the last request submitted to the provisioner of Cruise Control
+type CruiseControlRightsizeStatus struct {
+	BrokersToAdd   int32  `json:"brokersToAdd,omitempty"`
+	PartitionCount int32  `json:"partitionCount,omitempty"`
+	Topic          string `json:"topic,omitempty"`
+	RequestedAt    string `json:"requestedAt"`
+	// RecommendedBrokersToAdd is the number of brokers to add recommended by the provisioner
+	RecommendedBrokersToAdd int32 `json:"recommendedBrokersToAdd,omitempty"`
+	// RecommendedPartitionCount is the partition count of the topics recommended by the provisioner
+	RecommendedPartitionCount int32 `json:"recommendedPartitionCount,omitempty"`
+	// ProvisionerState is the state of the provisioner reported for the request, e.g. COMPLETED or IN_PROGRESS
+	ProvisionerState string `json:"provisionerState,omitempty"`
+	// Error is the reason why the request failed, it is submitted again later
+	Error string `json:"error,omitempty"`
+}
+
+// TopicRebalanceStatus describes the last rebalance of the replicas of the matching topics
+type TopicRebalanceStatus struct {
+	Topics      string   `json:"topics"`
+	Goals       []string `json:"goals,omitempty"`
+	RequestedAt string   `json:"requestedAt"`
+	// TaskID is the ID of the Cruise Control rebalance task
+	TaskID string `json:"taskId,omitempty"`
+	// Error is the reason why the rebalance could not be started, it is started again later
+	Error string `json:"error,omitempty"`
+}
+
+// ScheduledRebalanceStatus describes the last and the next rebalance of the cluster started by its schedule
+type ScheduledRebalanceStatus struct {
+	// Schedule is the cron expression the next rebalance was computed from
+	Schedule        string `json:"schedule"`
+	NextRebalanceAt string `json:"nextRebalanceAt,omitempty"`
+	LastRebalanceAt string `json:"lastRebalanceAt,omitempty"`
+	// TaskID is the ID of the Cruise Control task of the last rebalance
+	TaskID string `json:"taskId,omitempty"`
+	// SkippedReason is the reason why the last scheduled rebalance was skipped or could not be started
+	SkippedReason string `json:"skippedReason,omitempty"`
+}
+
+// DiskSkewRebalanceStatus describes the disk usage skew of the brokers and the last rebalance it started
+type DiskSkewRebalanceStatus struct {
+	// SkewPercent is the difference between the highest and the lowest disk utilization of the brokers
+	SkewPercent string `json:"skewPercent"`
+	// LastOperation is the name of the last CruiseControlOperation created to rebalance the cluster
+	LastOperation   string `json:"lastOperation,omitempty"`
+	LastTriggeredAt string `json:"lastTriggeredAt,omitempty"`
+}
+
+// CruiseControlOperationsStatus summarizes the Cruise Control operations of the cluster
+type CruiseControlOperationsStatus struct {
+	// InFlight are the operations waiting for their task to be started or executed by Cruise Control, the started
+	// ones first
+	InFlight []CruiseControlOperationSummary `json:"inFlight,omitempty"`
+	// RecentlyFinished are the last finished operations, the most recent first
+	RecentlyFinished []CruiseControlOperationSummary `json:"recentlyFinished,omitempty"`
+}
+
+// CruiseControlOperationSummary describes a Cruise Control operation of the cluster
+type CruiseControlOperationSummary struct {
+	Type OperationType `json:"type"`
+	// Source is the namespaced name of the CruiseControlOperation of the operation, operator for the tasks of the
+	// brokers started by the operator
+	Source string `json:"source"`
+	// Brokers are the IDs of the brokers the operation is performed on
+	Brokers []string `json:"brokers,omitempty"`
+	// TaskID is the ID of the Cruise Control task of the operation once it is started
+	TaskID string           `json:"taskId,omitempty"`
+	State  OperationOutcome `json:"state"`
+	// StartedAt and FinishedAt are the times of the operation in RFC3339
+	StartedAt  string `json:"startedAt,omitempty"`
+	FinishedAt string `json:"finishedAt,omitempty"`
+	// Duration is the time the finished operation took, or the time the started operation has been running for
+	Duration string `json:"duration,omitempty"`
+	// Error is the reason of the failure of the operation
+	Error string `json:"error,omitempty"`
+}
+
+// OperationRecord describes a significant operation of the cluster
+type OperationRecord struct {
+	Type OperationType `json:"type"`
+	// TriggeredBy describes the change which triggered the operation
+	TriggeredBy string `json:"triggeredBy,omitempty"`
+	// Brokers are the IDs of the brokers the operation was performed on
+	Brokers []string `json:"brokers,omitempty"`
+	// TaskID is the ID of the Cruise Control task of the operation
+	TaskID     string           `json:"taskId,omitempty"`
+	StartedAt  string           `json:"startedAt"`
+	FinishedAt string           `json:"finishedAt,omitempty"`
+	Outcome    OperationOutcome `json:"outcome"`
+	// Error is the reason of the failure of the operation
+	Error string `json:"error,omitempty"`
+}
+
+// RecordOperation adds the operation to the operation history, or updates it if it is still running, keeping the
+// last limit operations
+func (s *KafkaClusterStatus) RecordOperation(record OperationRecord, limit int) {
+	for i := len(s.OperationHistory) - 1; i >= 0; i-- {
+		current := &s.OperationHistory[i]
+		if current.Type != record.Type || current.TaskID != record.TaskID || current.Outcome != OperationRunning {
+			continue
+		}
+		if record.TriggeredBy == "" {
+			record.TriggeredBy = current.TriggeredBy
+		}
+		if len(record.Brokers) == 0 {
+			record.Brokers = current.Brokers
+		}
+		if record.StartedAt == "" {
+			record.StartedAt = current.StartedAt
+		}
+		*current = record
+		return
+	}
+	// the end of an operation not recorded when it started is only recorded for Cruise Control tasks, which may
+	// finish between two reconciliations
+	if record.Outcome != OperationRunning && record.TaskID == "" {
+		return
+	}
+	s.OperationHistory = append(s.OperationHistory, record)
+	if len(s.OperationHistory) > limit {
+		s.OperationHistory = s.OperationHistory[len(s.OperationHistory)-limit:]
+	}
+}
+
+// GetOperation returns the last recorded operation of the type with the given Cruise Control task ID, nil if there
+// is none
+func (s *KafkaClusterStatus) GetOperation(operationType OperationType, taskID string) *OperationRecord {
+	for i := len(s.OperationHistory) - 1; i >= 0; i-- {
+		if s.OperationHistory[i].Type == operationType && s.OperationHistory[i].TaskID == taskID {
+			return &s.OperationHistory[i]
+		}
+	}
+	return nil
+}
+
+// AdoptionStatus describes the takeover of an adopted cluster
+type AdoptionStatus struct {
+	// ImportedTopics is the number of KafkaTopics created for the topics of the adopted cluster
+	ImportedTopics int32 `json:"importedTopics,omitempty"`
+	// HeldBackChanges are the changes of the cluster not applied until the adoption is approved
+	HeldBackChanges []string `json:"heldBackChanges,omitempty"`
+}
+
+// TenantUsage describes the usage of the cluster by the KafkaTopics of a namespace
+type TenantUsage struct {
+	Topics     int32 `json:"topics"`
+	Partitions int32 `json:"partitions"`
+}
+
+// GoalViolationRemediationStatus describes the last remediation of the violations of each remediated goal
+type GoalViolationRemediationStatus struct {
+	Goals []RemediatedGoalStatus `json:"goals,omitempty"`
+}
+
+// RemediatedGoalStatus describes the last remediation of the violations of a goal
+type RemediatedGoalStatus struct {
+	Name string `json:"name"`
+	// AnomalyID is the ID of the goal violation anomaly of Cruise Control which was remediated
+	AnomalyID    string `json:"anomalyId"`
+	RemediatedAt string `json:"remediatedAt"`
+	// TaskID is the ID of the Cruise Control rebalance task started to remediate the violation
+	TaskID string `json:"taskId,omitempty"`
+	// Error is the reason why the rebalance could not be started
+	Error string `json:"error,omitempty"`
+}
+
+// GetGoal returns the status of the last remediation of the violations of the goal, nil if there was none
+func (s *GoalViolationRemediationStatus) GetGoal(name string) *RemediatedGoalStatus {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Goals {
+		if s.Goals[i].Name == name {
+			return &s.Goals[i]
+		}
+	}
+	return nil
+}
+
+// CruiseControlLoadStatus is a snapshot of the resource utilization of the brokers reported by Cruise Control
+type CruiseControlLoadStatus struct {
+	UpdatedAt string             `json:"updatedAt"`
+	Brokers   []BrokerLoadStatus `json:"brokers,omitempty"`
+}
+
+// BrokerLoadStatus describes the resource utilization of a broker, the percentages and rates have two decimals
+type BrokerLoadStatus struct {
+	BrokerID    string `json:"brokerId"`
+	CPUPercent  string `json:"cpuPercent"`
+	DiskPercent string `json:"diskPercent"`
+	Leaders     int32  `json:"leaders"`
+	Replicas    int32  `json:"replicas"`
+	// NetworkInKBps is the incoming network rate of the broker, as leader and follower, in KB/s
+	NetworkInKBps string `json:"networkInKBps"`
+	// NetworkOutKBps is the outgoing network rate of the broker in KB/s
+	NetworkOutKBps string `json:"networkOutKBps"`
+}
+
+// GetBrokersAboveDiskUsage returns the IDs of the brokers whose disk utilization is not below the given percentage
+func (s *CruiseControlLoadStatus) GetBrokersAboveDiskUsage(percent int32) []string {
+	if s == nil {
+		return nil
+	}
+	var brokerIDs []string
+	for _, broker := range s.Brokers {
+		if diskPercent, err := strconv.ParseFloat(broker.DiskPercent, 64); err == nil && diskPercent >= float64(percent) {
+			brokerIDs = append(brokerIDs, broker.BrokerID)
+		}
+	}
+	return brokerIDs
+}
+
+// BrokerConfigValidationStatus describes the outcome of the validation of the broker configurations
+type BrokerConfigValidationStatus struct {
+	// KafkaVersion is the version the configurations were validated against, version specific checks are
+	// skipped when it is empty
+	KafkaVersion string                        `json:"kafkaVersion,omitempty"`
+	Issues       []BrokerConfigValidationIssue `json:"issues,omitempty"`
+}
+
+// BrokerConfigValidationIssue describes a problem found in a broker configuration
+type BrokerConfigValidationIssue struct {
+	// Source is the field of the KafkaCluster the config comes from, e.g. clusterWideConfig or brokers[0].readOnlyConfig
+	Source   string              `json:"source"`
+	Key      string              `json:"key"`
+	Severity ConfigIssueSeverity `json:"severity"`
+	Message  string              `json:"message"`
+}
+
+// HasErrors returns true if any of the issues has Error severity
+func (s *BrokerConfigValidationStatus) HasErrors() bool {
+	if s == nil {
+		return false
+	}
+	for _, issue := range s.Issues {
+		if issue.Severity == ConfigIssueSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// SmokeTestStatus describes the outcome of a post-change verification Job
+type SmokeTestStatus struct {
+	State SmokeTestState `json:"state"`
+	// Fingerprint identifies the set of brokers and images the smoke test was run against
+	Fingerprint    string `json:"fingerprint"`
+	JobName        string `json:"jobName"`
+	StartTime      string `json:"startTime,omitempty"`
+	CompletionTime string `json:"completionTime,omitempty"`
+}
+
+// PreflightChecksStatus describes the outcome of the pre-flight checks run before a disruptive operation
+type PreflightChecksStatus struct {
+	Operation PreflightOperation `json:"operation"`
+	Passed    bool               `json:"passed"`
+	CheckedAt string             `json:"checkedAt"`
+	Checks    []PreflightCheck   `json:"checks,omitempty"`
+}
+
+// PreflightCheck describes the outcome of a single pre-flight check
+type PreflightCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
 }
 
 // RollingUpgradeStatus defines status of rolling upgrade
@@ -121,6 +950,175 @@ type RollingUpgradeConfig struct {
 	// distinct broker replicas with either offline replicas or out of sync replicas and the number of alerts triggered by
 	// alerts with 'rollingupgrade'
 	FailureThreshold int `json:"failureThreshold"`
+	// RestartGeneration triggers a rolling restart of every broker whenever it is changed, e.g. after an out-of-band
+	// change of a mounted Secret. The brokers are restarted like on configuration changes.
+	// +optional
+	RestartGeneration int32 `json:"restartGeneration,omitempty"`
+	// Concurrency is the number of brokers restarted at once, defaults to 1. The brokers being restarted are not
+	// counted as failures against the failure threshold.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency int32 `json:"concurrency,omitempty"`
+	// ConcurrencyPolicy tells which brokers can be restarted at once. With Any the brokers holding replicas of the same
+	// partition are not restarted together. With Rack only the brokers of the same rack are restarted together, which
+	// requires rack awareness. Defaults to Any
+	// +optional
+	ConcurrencyPolicy RollingUpgradeConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// Order tells in which order the brokers are restarted. ControllerLast restarts the active controller after the
+	// other brokers, LowestLoadFirst restarts the brokers leading the fewest partitions first and Explicit follows
+	// brokerOrder. Defaults to ControllerLast
+	// +optional
+	Order RollingUpgradeOrder `json:"order,omitempty"`
+	// BrokerOrder lists the IDs of the brokers in the order they are restarted with the Explicit order, the brokers
+	// not listed are restarted after them
+	// +optional
+	BrokerOrder []int32 `json:"brokerOrder,omitempty"`
+}
+
+// GetConcurrency returns the number of brokers restarted at once during rolling upgrades
+func (c RollingUpgradeConfig) GetConcurrency() int {
+	if c.Concurrency < 1 {
+		return 1
+	}
+	return int(c.Concurrency)
+}
+
+// GetConcurrencyPolicy returns which brokers can be restarted at once during rolling upgrades, defaults to Any
+func (c RollingUpgradeConfig) GetConcurrencyPolicy() RollingUpgradeConcurrencyPolicy {
+	if c.ConcurrencyPolicy == "" {
+		return RollingUpgradeConcurrencyAny
+	}
+	return c.ConcurrencyPolicy
+}
+
+// GetOrder returns the order the brokers are restarted in during rolling upgrades, defaults to ControllerLast
+func (c RollingUpgradeConfig) GetOrder() RollingUpgradeOrder {
+	if c.Order == "" {
+		return RollingUpgradeOrderControllerLast
+	}
+	return c.Order
+}
+
+// PreflightChecksConfig defines the configuration of the pre-flight checks
+type PreflightChecksConfig struct {
+	// Enabled makes the operator refuse to start a Kafka version upgrade or a broker scale down when there are
+	// under-replicated partitions, no active controller, in-flight partition reassignments, Cruise Control is not ready
+	// or the remaining brokers would not have enough disk headroom
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxDiskUsagePercentage is the disk usage of the remaining brokers after a scale down that is not allowed to be
+	// exceeded. Defaults to 80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxDiskUsagePercentage int `json:"maxDiskUsagePercentage,omitempty"`
+}
+
+// SmokeTestConfig defines the configuration of the post-change verification Job
+type SmokeTestConfig struct {
+	// Enabled makes the operator run a Job producing to and consuming from every broker after each rolling upgrade
+	// and scaling event. Further rolling upgrades and scale downs are held back until the smoke test passes.
+	// A failed smoke test is rerun when its Job is deleted.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Image is the container image of the smoke test Job, it must contain the Kafka command line tools.
+	// Defaults to the cluster image
+	// +optional
+	Image string `json:"image,omitempty"`
+	// NumRecords is the number of records produced and consumed by the smoke test. Defaults to 1000
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumRecords int32 `json:"numRecords,omitempty"`
+	// TimeoutSeconds is the duration after which the smoke test is considered failed. Defaults to 300
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// ConnectionInfoConfig defines the publishing of the client connection details
+type ConnectionInfoConfig struct {
+	// Enabled makes the operator publish the bootstrap servers, the security protocol and the CA certificate of each
+	// listener in the <cluster>-connection-info ConfigMap and JKS truststores of the CA certificates in the
+	// <cluster>-connection-info Secret so that clients do not have to hardcode service names
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// TopologyChangeHints bumps topology.generation in the <cluster>-connection-info ConfigMap and records a
+	// TopologyChanged event on the cluster when brokers are added or removed or the advertised addresses of the
+	// listeners change, so that client tooling watching them can refresh its bootstrap lists
+	// +optional
+	TopologyChangeHints bool `json:"topologyChangeHints,omitempty"`
+}
+
+// DevProfileConfig defines the minimal-footprint development profile of the cluster
+type DevProfileConfig struct {
+	// Enabled runs the cluster with exactly one broker, without Cruise Control and Envoy. The replication factor of
+	// the internal topics defaults to 1 and Cruise Control is not waited for, KafkaTopics and KafkaUsers are still
+	// reconciled. It must not be used in production.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// BrokerAntiAffinityConfig defines the anti-affinity the operator generates for the broker pods
+type BrokerAntiAffinityConfig struct {
+	// Type is the strength of the anti-affinity. With None no anti-affinity is generated, which allows running
+	// several brokers on a single node e.g. in development clusters. Defaults to Required when OneBrokerPerNode is
+	// true, Preferred otherwise
+	// +optional
+	Type AntiAffinityType `json:"type,omitempty"`
+	// TopologyKey is the node label the brokers are spread by. Defaults to kubernetes.io/hostname
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// MergeStrategy defines how the affinity given in the broker config is combined with the generated anti-affinity.
+	// With Override the given affinity is used on its own, with Append the generated anti-affinity terms are added
+	// to it. Defaults to Override
+	// +optional
+	MergeStrategy AffinityMergeStrategy `json:"mergeStrategy,omitempty"`
+}
+
+// GetTopologyKey returns the topology key of the broker anti-affinity, defaults to kubernetes.io/hostname
+func (c *BrokerAntiAffinityConfig) GetTopologyKey() string {
+	if c == nil || c.TopologyKey == "" {
+		return corev1.LabelHostname
+	}
+	return c.TopologyKey
+}
+
+// GetMergeStrategy returns how the user supplied affinity is combined with the broker anti-affinity, defaults to
+// Override
+func (c *BrokerAntiAffinityConfig) GetMergeStrategy() AffinityMergeStrategy {
+	if c == nil || c.MergeStrategy == "" {
+		return AffinityMergeOverride
+	}
+	return c.MergeStrategy
+}
+
+// BrokerConfigValidationConfig defines how the broker configurations are validated
+type BrokerConfigValidationConfig struct {
+	// Policy defines what happens when invalid configuration is found. With Warn the issues are only reported in the
+	// status, with Enforce the broker configurations are not updated until the errors are fixed. Defaults to Warn
+	// +kubebuilder:validation:Enum=Disabled;Warn;Enforce
+	// +optional
+	Policy BrokerConfigValidationPolicy `json:"policy,omitempty"`
+}
+
+// GetPolicy returns the broker config validation policy, defaults to Warn
+func (c BrokerConfigValidationConfig) GetPolicy() BrokerConfigValidationPolicy {
+	if c.Policy == "" {
+		return BrokerConfigValidationPolicyWarn
+	}
+	return c.Policy
+}
+
+// MaintenanceWindow defines a recurring time window (in UTC) in which disruptive operations are allowed
+type MaintenanceWindow struct {
+	// Days the window recurs on. The window recurs every day if left empty
+	// +optional
+	Days []MaintenanceWindowDay `json:"days,omitempty"`
+	// Start of the window in HH:MM format (UTC)
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// Duration of the window, e.g. 2h30m
+	Duration metav1.Duration `json:"duration"`
 }
 
 // DisruptionBudget defines the configuration for PodDisruptionBudget where the workload is managed by the kafka-operator
@@ -148,6 +1146,10 @@ type Broker struct {
 	BrokerConfigGroup string        `json:"brokerConfigGroup,omitempty"`
 	ReadOnlyConfig    string        `json:"readOnlyConfig,omitempty"`
 	BrokerConfig      *BrokerConfig `json:"brokerConfig,omitempty"`
+	// Maintenance marks the broker as being in planned maintenance: its leadership is moved to the other brokers,
+	// no data is moved to it by Cruise Control and the operator does not remediate its failures until it is unset
+	// +optional
+	Maintenance bool `json:"maintenance,omitempty"`
 }
 
 // BrokerConfig defines the broker configuration
@@ -183,7 +1185,8 @@ type BrokerConfig struct {
 	// it will override the broker's external listener advertise address according to the description of the "hostnameOverride" field.
 	NodePortExternalIP map[string]string `json:"nodePortExternalIP,omitempty"`
 	// Any definition received through this field will override the default behaviour of OneBrokerPerNode flag
-	// and the operator supposes that the user is aware of how scheduling is done by kubernetes
+	// and the operator supposes that the user is aware of how scheduling is done by kubernetes, unless
+	// brokerAntiAffinity.mergeStrategy is Append
 	// Affinity could be set through brokerConfigGroups definitions and can be set for individual brokers as well
 	// where letter setting will override the group setting
 	Affinity           *corev1.Affinity           `json:"affinity,omitempty"`
@@ -206,49 +1209,576 @@ type BrokerConfig struct {
 	// Adding the "+" prefix to the name prepends the value to that environment variable instead of overwriting it.
 	// Add the "+" suffix to append.
 	Envs []corev1.EnvVar `json:"envs,omitempty"`
+	// Architectures restricts the broker pods to nodes with one of the given CPU architectures,
+	// it overrides spec.architectures
+	// +optional
+	Architectures []NodeArchitecture `json:"architectures,omitempty"`
 	// TerminationGracePeriod defines the pod termination grace period
 	// +kubebuilder:default=120
 	// +optional
-	TerminationGracePeriod *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	TerminationGracePeriod *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// EphemeralStorage makes the brokers keep their storageConfigs on emptyDir volumes instead of
+	// PersistentVolumeClaims, e.g. for development and CI clusters without a storage class.
+	// The data of a broker is lost whenever its pod is replaced, the operator re-replicates the partitions
+	// to the broker afterwards.
+	// +optional
+	EphemeralStorage *EphemeralStorageConfig `json:"ephemeralStorage,omitempty"`
+	// RestartGeneration triggers a rolling restart of the brokers of the group, or of the broker when set in the
+	// brokerConfig of a broker, whenever it is changed
+	// +optional
+	RestartGeneration int32 `json:"restartGeneration,omitempty"`
+	// Lifecycle tunes the termination and the startup of the broker pods, e.g. for environments with slow storage
+	// attach and detach or custom agents. When set in the brokerConfig of a broker it overrides the one of the group.
+	// +optional
+	Lifecycle *BrokerLifecycleConfig `json:"lifecycle,omitempty"`
+	// ProcessRoles are the KRaft process roles of the broker, the brokers with the controller role are the voters of
+	// the metadata quorum. It defaults to broker and it is ignored in ZooKeeper mode. When set in the brokerConfig of
+	// a broker it overrides the one of the group.
+	// +optional
+	ProcessRoles []ProcessRole `json:"processRoles,omitempty"`
+	// ResourceRecommendation makes the resource requests of the kafka container follow the recommendation of a
+	// VerticalPodAutoscaler, the changed requests are rolled out by the rolling update of the broker pods. The
+	// VerticalPodAutoscaler should use the Off update mode, so that it does not evict the broker pods itself.
+	// +optional
+	ResourceRecommendation *ResourceRecommendationConfig `json:"resourceRecommendation,omitempty"`
+	// RollingUpgrade overrides the rolling upgrade policy of spec.rollingUpgradeConfig for the brokers of the group,
+	// or for the broker when set in the brokerConfig of a broker
+	// +optional
+	RollingUpgrade *BrokerRollingUpgradeConfig `json:"rollingUpgrade,omitempty"`
+}
+
+// BrokerRollingUpgradeConfig defines the rolling upgrade policy of a group of brokers
+type BrokerRollingUpgradeConfig struct {
+	// FailureThreshold overrides spec.rollingUpgradeConfig.failureThreshold for the restarts of the brokers
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailureThreshold *int `json:"failureThreshold,omitempty"`
+	// Paused holds back the rolling upgrades of the brokers until it is unset, the failed broker pods are replaced
+	// regardless
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// ResourceRecommendationConfig selects the VerticalPodAutoscaler the resource requests of the brokers follow and
+// bounds the requests applied from its recommendation
+type ResourceRecommendationConfig struct {
+	// VerticalPodAutoscaler is the name of the VerticalPodAutoscaler in the namespace of the cluster, the target
+	// recommendation of which for the kafka container is applied
+	VerticalPodAutoscaler string `json:"verticalPodAutoscaler"`
+	// MinAllowed is the lower bound of the applied requests per resource
+	// +optional
+	MinAllowed corev1.ResourceList `json:"minAllowed,omitempty"`
+	// MaxAllowed is the upper bound of the applied requests per resource
+	// +optional
+	MaxAllowed corev1.ResourceList `json:"maxAllowed,omitempty"`
+	// MinChangePercent is the difference from the applied requests in percent below which a recommendation is not
+	// applied, to spare the brokers of rolling restarts for minor changes, defaults to 10
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MinChangePercent *int32 `json:"minChangePercent,omitempty"`
+}
+
+// GetMinChangePercent returns the difference from the applied requests below which a recommendation is not applied
+func (c *ResourceRecommendationConfig) GetMinChangePercent() int32 {
+	if c.MinChangePercent != nil {
+		return *c.MinChangePercent
+	}
+	return DefaultResourceRecommendationMinChangePercent
+}
+
+// BrokerLifecycleConfig defines the lifecycle hooks and the startup probe of the kafka container of the broker pods
+type BrokerLifecycleConfig struct {
+	// PreStopCommand overrides the command of the preStop hook stopping the broker gracefully,
+	// it has terminationGracePeriodSeconds to complete
+	// +optional
+	PreStopCommand []string `json:"preStopCommand,omitempty"`
+	// PostStartCommand is run by the postStart hook of the kafka container, the broker pod is replaced when it fails
+	// +optional
+	PostStartCommand []string `json:"postStartCommand,omitempty"`
+	// StartupProbe makes the broker pods ready only once the broker accepts connections on the listener used for
+	// the inter-broker communication, the broker pod is replaced when it does not start in time
+	// +optional
+	StartupProbe *StartupProbeConfig `json:"startupProbe,omitempty"`
+}
+
+// StartupProbeConfig tunes the startup probe of the broker pods, the broker has
+// initialDelaySeconds + periodSeconds * failureThreshold seconds to start
+type StartupProbeConfig struct {
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	// PeriodSeconds is the period of the probes, 10 when not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// TimeoutSeconds is the timeout of a probe, 5 when not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// FailureThreshold is the number of failed probes the broker pod is replaced after, 30 when not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// EphemeralStorageConfig defines the emptyDir volumes used as broker storage. The storage request of the pvcSpec of
+// a storage config, if set, is used as the size limit of its volume.
+type EphemeralStorageConfig struct {
+	Enabled bool `json:"enabled"`
+	// Medium of the emptyDir volumes, "Memory" makes them tmpfs backed
+	// +kubebuilder:validation:Enum="";Memory
+	// +optional
+	Medium corev1.StorageMedium `json:"medium,omitempty"`
+}
+
+type NetworkConfig struct {
+	IncomingNetworkThroughPut string `json:"incomingNetworkThroughPut,omitempty"`
+	OutgoingNetworkThroughPut string `json:"outgoingNetworkThroughPut,omitempty"`
+}
+
+// RackAwareness defines the required fields to enable kafka's rack aware feature
+type RackAwareness struct {
+	Labels []string `json:"labels"`
+}
+
+// CruiseControlConfig defines the config for Cruise Control
+type CruiseControlConfig struct {
+	CruiseControlTaskSpec CruiseControlTaskSpec `json:"cruiseControlTaskSpec,omitempty"`
+	// CruiseControlEndpoint is the endpoint of an already running Cruise Control, the operator does not deploy one
+	// if it is set. It is either a host with an optional port and path prefix, e.g. "cruisecontrol:8090", or a full
+	// URL, e.g. "https://gw.example.com/kafka/cc" for Cruise Control behind a path-prefixed reverse proxy.
+	CruiseControlEndpoint string                        `json:"cruiseControlEndpoint,omitempty"`
+	Resources             *corev1.ResourceRequirements  `json:"resourceRequirements,omitempty"`
+	ServiceAccountName    string                        `json:"serviceAccountName,omitempty"`
+	ImagePullSecrets      []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	NodeSelector          map[string]string             `json:"nodeSelector,omitempty"`
+	Tolerations           []corev1.Toleration           `json:"tolerations,omitempty"`
+	Config                string                        `json:"config,omitempty"`
+	CapacityConfig        string                        `json:"capacityConfig,omitempty"`
+	ClusterConfig         string                        `json:"clusterConfig,omitempty"`
+	Log4jConfig           string                        `json:"log4jConfig,omitempty"`
+	Image                 string                        `json:"image,omitempty"`
+	TopicConfig           *TopicConfig                  `json:"topicConfig,omitempty"`
+	//  Annotations to be applied to CruiseControl pod
+	// +optional
+	CruiseControlAnnotations map[string]string `json:"cruiseControlAnnotations,omitempty"`
+	// InitContainers add extra initContainers to CruiseControl pod
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// Volumes define some extra Kubernetes Volumes for the CruiseControl Pods.
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// VolumeMounts define some extra Kubernetes Volume mounts for the CruiseControl Pods.
+	VolumeMounts       []corev1.VolumeMount       `json:"volumeMounts,omitempty"`
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// SecurityContext allows to set security context for the CruiseControl container
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// Architectures restricts the Cruise Control pod to nodes with one of the given CPU architectures,
+	// it overrides spec.architectures
+	// +optional
+	Architectures []NodeArchitecture `json:"architectures,omitempty"`
+	// Authentication configures the credentials the operator sends with its requests to Cruise Control, e.g. when
+	// it is behind an authenticating proxy
+	// +optional
+	Authentication *CruiseControlAuthentication `json:"authentication,omitempty"`
+	// ClientTLS makes the operator connect to Cruise Control over TLS, the endpoint of Cruise Control defaults to
+	// https when it is set
+	// +optional
+	ClientTLS *CruiseControlClientTLS `json:"clientTLS,omitempty"`
+	// Proxy makes the operator connect to Cruise Control through an HTTP(S) proxy, e.g. a corporate egress proxy
+	// +optional
+	Proxy *CruiseControlClientProxy `json:"proxy,omitempty"`
+	// NodeNetworkCapacity derives the NW_IN and NW_OUT capacities of the brokers from the nodes their pods run on,
+	// for the brokers not setting them in their networkConfig
+	// +optional
+	NodeNetworkCapacity *NodeNetworkCapacityConfig `json:"nodeNetworkCapacity,omitempty"`
+	// GoalViolationRemediation lets the operator rebalance the cluster when Cruise Control detects the violation of
+	// one of the given goals. The self-healing of goal violations should be disabled in Cruise Control when it is used.
+	// +optional
+	GoalViolationRemediation *GoalViolationRemediation `json:"goalViolationRemediation,omitempty"`
+	// OperationGoals overrides the goals Cruise Control optimizes when the operator adds or removes brokers and
+	// rebalances disks, the ready default goals of Cruise Control are used for the operations without goals
+	// +optional
+	OperationGoals *CruiseControlOperationGoals `json:"operationGoals,omitempty"`
+	// ReplicationThrottle is the upper bound of the bandwidth in bytes per second used to move partition replicas
+	// when the operator adds or removes brokers and rebalances disks, the default throttle of Cruise Control is used
+	// if it is not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ReplicationThrottle *int64 `json:"replicationThrottle,omitempty"`
+	// ExecutorConcurrency limits the concurrent movements of the operations the operator starts, it is applied to
+	// the ongoing execution of Cruise Control too once it is changed
+	// +optional
+	ExecutorConcurrency *CruiseControlExecutorConcurrency `json:"executorConcurrency,omitempty"`
+	// Rightsize requests the provisioner of Cruise Control to add brokers to the cluster or to increase the partition
+	// count of topics, the request is submitted again whenever it changes
+	// +optional
+	Rightsize *CruiseControlRightsize `json:"rightsize,omitempty"`
+	// TopicRebalance lets the operator rebalance only the replicas of the matching topics instead of the whole
+	// cluster, e.g. of a topic created with poor placement. The rebalance is started again whenever it changes.
+	// +optional
+	TopicRebalance *CruiseControlTopicRebalance `json:"topicRebalance,omitempty"`
+	// OperationRetryPolicy defines how the CruiseControlOperations of the cluster whose task completed with error
+	// are handled, they are left failed when it is not set
+	// +optional
+	OperationRetryPolicy *CruiseControlOperationRetryPolicy `json:"operationRetryPolicy,omitempty"`
+	// RebalanceSchedule is a cron expression in UTC, e.g. "0 2 * * *", at which the operator rebalances the whole
+	// cluster. The rebalance is skipped when Cruise Control is executing a task or the cluster is not healthy.
+	// +optional
+	RebalanceSchedule string `json:"rebalanceSchedule,omitempty"`
+	// DiskSkewRebalance lets the operator create a rebalance CruiseControlOperation when the disk utilization of the
+	// brokers reported by Cruise Control is skewed beyond the given threshold
+	// +optional
+	DiskSkewRebalance *DiskSkewRebalanceConfig `json:"diskSkewRebalance,omitempty"`
+	// AnomalyNotifier defines which anomalies notified by Cruise Control to the operator are remediated by
+	// CruiseControlOperations, Kubernetes events are recorded on the cluster for all of them. The anomalies of the
+	// clusters without it are rejected. The notifier has to be allowed by RBAC to create kafkaclusters/anomalies.
+	// +optional
+	AnomalyNotifier *CruiseControlAnomalyNotifier `json:"anomalyNotifier,omitempty"`
+	// SelfHealing enables or disables the self-healing of Cruise Control for each anomaly type through its admin
+	// endpoint, the anomaly types not set are left as configured in Cruise Control
+	// +optional
+	SelfHealing *CruiseControlSelfHealing `json:"selfHealing,omitempty"`
+	// Autoscaling lets the operator adjust the number of brokers within the given bounds to keep the average load of
+	// the brokers reported by Cruise Control at the given targets. The brokers are added to and removed from
+	// spec.brokers, which starts their add and remove broker operations in Cruise Control.
+	// +optional
+	Autoscaling *BrokerAutoscalingConfig `json:"autoscaling,omitempty"`
+}
+
+// BrokerAutoscalingConfig defines the bounds of the broker count and the load targets it is adjusted by, at least
+// one target has to be set
+type BrokerAutoscalingConfig struct {
+	// MinBrokers is the number of brokers the cluster is not scaled down below
+	// +kubebuilder:validation:Minimum=1
+	MinBrokers int32 `json:"minBrokers"`
+	// MaxBrokers is the number of brokers the cluster is not scaled up above
+	// +kubebuilder:validation:Minimum=1
+	MaxBrokers int32 `json:"maxBrokers"`
+	// BrokerConfigGroup is the broker config group of the brokers added, only the brokers of the group are removed
+	BrokerConfigGroup string `json:"brokerConfigGroup"`
+	// TargetDiskPercent is the average disk utilization of the brokers to keep
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	TargetDiskPercent int32 `json:"targetDiskPercent,omitempty"`
+	// TargetCPUPercent is the average CPU utilization of the brokers to keep
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	TargetCPUPercent int32 `json:"targetCpuPercent,omitempty"`
+	// TargetNetworkInKBps is the average incoming network rate of the brokers to keep in KB/s
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetNetworkInKBps int32 `json:"targetNetworkInKBps,omitempty"`
+	// TargetNetworkOutKBps is the average outgoing network rate of the brokers to keep in KB/s
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetNetworkOutKBps int32 `json:"targetNetworkOutKBps,omitempty"`
+	// Cooldown is the minimum time between two adjustments of the broker count, defaults to 30m
+	// +optional
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
+}
+
+// GetCooldown returns the minimum time between two adjustments of the broker count
+func (c *BrokerAutoscalingConfig) GetCooldown() time.Duration {
+	if c.Cooldown != nil {
+		return c.Cooldown.Duration
+	}
+	return DefaultBrokerAutoscalingCooldown
+}
+
+// CruiseControlSelfHealing enables or disables the self-healing of Cruise Control per anomaly type
+type CruiseControlSelfHealing struct {
+	// +optional
+	BrokerFailure *bool `json:"brokerFailure,omitempty"`
+	// +optional
+	GoalViolation *bool `json:"goalViolation,omitempty"`
+	// +optional
+	DiskFailure *bool `json:"diskFailure,omitempty"`
+	// +optional
+	TopicAnomaly *bool `json:"topicAnomaly,omitempty"`
+}
+
+// AnomalyTypes returns the anomaly types of Cruise Control, e.g. BROKER_FAILURE, the self-healing of which is enabled
+// and the ones the self-healing of which is disabled
+func (s *CruiseControlSelfHealing) AnomalyTypes() (enabled, disabled []string) {
+	for _, setting := range []struct {
+		anomalyType string
+		enabled     *bool
+	}{
+		{anomalyType: "BROKER_FAILURE", enabled: s.BrokerFailure},
+		{anomalyType: "GOAL_VIOLATION", enabled: s.GoalViolation},
+		{anomalyType: "DISK_FAILURE", enabled: s.DiskFailure},
+		{anomalyType: "TOPIC_ANOMALY", enabled: s.TopicAnomaly},
+	} {
+		switch {
+		case setting.enabled == nil:
+		case *setting.enabled:
+			enabled = append(enabled, setting.anomalyType)
+		default:
+			disabled = append(disabled, setting.anomalyType)
+		}
+	}
+	return enabled, disabled
+}
+
+// CruiseControlAnomalyNotifier defines the remediation of the anomalies notified by Cruise Control
+type CruiseControlAnomalyNotifier struct {
+	// RemediateGoalViolations creates a rebalance CruiseControlOperation optimizing the fixable violated goals
+	// +optional
+	RemediateGoalViolations bool `json:"remediateGoalViolations,omitempty"`
+	// RemediateBrokerFailures creates a remove_broker CruiseControlOperation moving the replicas off the failed
+	// brokers, the brokers holding the last in-sync replicas of a partition are not removed
+	// +optional
+	RemediateBrokerFailures bool `json:"remediateBrokerFailures,omitempty"`
+}
+
+// DiskSkewRebalanceConfig defines when the disk usage skew of the brokers triggers a rebalance
+type DiskSkewRebalanceConfig struct {
+	// MaxSkewPercent is the difference between the highest and the lowest disk utilization of the brokers, in
+	// percentage points, above which a rebalance is started
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MaxSkewPercent int32 `json:"maxSkewPercent"`
+	// Cooldown is the minimum time between two rebalances started by the skew, defaults to 1h
+	// +optional
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
+	// Goals are the goals optimized by the rebalance, the default goals of Cruise Control are optimized when it is
+	// empty
+	// +optional
+	Goals []string `json:"goals,omitempty"`
+}
+
+// GetCooldown returns the minimum time between two rebalances started by the disk usage skew
+func (c *DiskSkewRebalanceConfig) GetCooldown() time.Duration {
+	if c.Cooldown != nil {
+		return c.Cooldown.Duration
+	}
+	return DefaultDiskSkewRebalanceCooldown
+}
+
+// CruiseControlOperationRetryPolicy defines how the failed CruiseControlOperations of a cluster are handled
+type CruiseControlOperationRetryPolicy struct {
+	// ErrorPolicy is one of retry, ignore and block. The task of a failed operation is started again with the retry
+	// policy until it runs out of attempts. The ignore and block policies leave the failed operation failed, the
+	// block policy does not start any other operation of the cluster until the failed one is deleted.
+	// +kubebuilder:validation:Enum=retry;ignore;block
+	// +kubebuilder:default=retry
+	// +optional
+	ErrorPolicy CruiseControlErrorPolicy `json:"errorPolicy,omitempty"`
+	// MaxAttempts is the number of times the task of an operation is started at most with the retry policy,
+	// including the first one, defaults to 3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+	// Backoff is the delay before the first retry of a failed operation, it is doubled for each further retry up to
+	// an hour, defaults to 1m
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+}
+
+// GetErrorPolicy returns how the failed operations are handled, they are ignored without a retry policy
+func (p *CruiseControlOperationRetryPolicy) GetErrorPolicy() CruiseControlErrorPolicy {
+	if p == nil {
+		return CruiseControlErrorPolicyIgnore
+	}
+	if p.ErrorPolicy == "" {
+		return CruiseControlErrorPolicyRetry
+	}
+	return p.ErrorPolicy
+}
+
+// GetMaxAttempts returns the number of times the task of an operation is started at most, the task is started only
+// once unless the failed operations are retried
+func (p *CruiseControlOperationRetryPolicy) GetMaxAttempts() int32 {
+	if p.GetErrorPolicy() != CruiseControlErrorPolicyRetry {
+		return 1
+	}
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return DefaultOperationRetryMaxAttempts
+}
+
+// GetBackoff returns the delay before starting the task of an operation again after the given number of failed
+// attempts
+func (p *CruiseControlOperationRetryPolicy) GetBackoff(attempts int32) time.Duration {
+	backoff := DefaultOperationRetryBackoff
+	if p != nil && p.Backoff != nil {
+		backoff = p.Backoff.Duration
+	}
+	for i := int32(1); i < attempts && backoff < MaxOperationRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > MaxOperationRetryBackoff {
+		return MaxOperationRetryBackoff
+	}
+	return backoff
+}
+
+// CruiseControlTopicRebalance defines a rebalance moving only the replicas of the matching topics
+type CruiseControlTopicRebalance struct {
+	// Topics is the regular expression of the topics whose replicas are rebalanced, e.g. events|clicks-.*
+	// +kubebuilder:validation:MinLength=1
+	Topics string `json:"topics"`
+	// Goals are the goals optimized by the rebalance, the default goals of Cruise Control are optimized if it is empty
+	// +optional
+	Goals []string `json:"goals,omitempty"`
+}
+
+// CruiseControlRightsize defines a request to the provisioner of Cruise Control, at least one of brokersToAdd,
+// partitionCount and fromCapacityHeadroom has to be set
+type CruiseControlRightsize struct {
+	// BrokersToAdd is the number of brokers the provisioner is requested to add to the cluster
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BrokersToAdd int32 `json:"brokersToAdd,omitempty"`
+	// PartitionCount is the number of partitions the partitions of the topics matching topic are increased to
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PartitionCount int32 `json:"partitionCount,omitempty"`
+	// Topic is the regular expression of the topics whose partition count is rightsized
+	// +optional
+	Topic string `json:"topic,omitempty"`
+	// FromCapacityHeadroom requests the provisioner to add the brokers recommended by status.capacityHeadroom
+	// instead of brokersToAdd whenever spec.capacityHeadroom is breached
+	// +optional
+	FromCapacityHeadroom bool `json:"fromCapacityHeadroom,omitempty"`
+}
+
+// CruiseControlExecutorConcurrency defines the upper bounds of the concurrent movements executed by Cruise Control,
+// the defaults of Cruise Control are used for the ones not set
+type CruiseControlExecutorConcurrency struct {
+	// PartitionMovementsPerBroker is the upper bound of the ongoing replica movements going into or out of each broker
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PartitionMovementsPerBroker int32 `json:"partitionMovementsPerBroker,omitempty"`
+	// LeaderMovements is the upper bound of the ongoing leadership movements
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	LeaderMovements int32 `json:"leaderMovements,omitempty"`
+	// IntraBrokerPartitionMovements is the upper bound of the ongoing replica movements between the disks of each
+	// broker
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IntraBrokerPartitionMovements int32 `json:"intraBrokerPartitionMovements,omitempty"`
 }
 
-type NetworkConfig struct {
-	IncomingNetworkThroughPut string `json:"incomingNetworkThroughPut,omitempty"`
-	OutgoingNetworkThroughPut string `json:"outgoingNetworkThroughPut,omitempty"`
+// CruiseControlOperationGoals defines the Cruise Control goals of the operations started by the operator. The hard
+// goal check of Cruise Control is skipped for the operations with goals, so the hard goals to keep satisfied, e.g.
+// RackAwareGoal, have to be listed too.
+type CruiseControlOperationGoals struct {
+	// AddBrokers are the goals of moving partition replicas to the new brokers
+	// +optional
+	AddBrokers []string `json:"addBrokers,omitempty"`
+	// RemoveBrokers are the goals of moving partition replicas off from the removed brokers
+	// +optional
+	RemoveBrokers []string `json:"removeBrokers,omitempty"`
+	// RebalanceDisks are the goals of moving partition replicas to the new disks of the brokers
+	// +optional
+	RebalanceDisks []string `json:"rebalanceDisks,omitempty"`
 }
 
-// RackAwareness defines the required fields to enable kafka's rack aware feature
-type RackAwareness struct {
-	Labels []string `json:"labels"`
+// GoalViolationRemediation defines which goal violations detected by Cruise Control are remediated by the operator
+type GoalViolationRemediation struct {
+	// Goals are the goals whose violations are remediated by a rebalance optimizing only the violated goals
+	// +kubebuilder:validation:MinItems=1
+	Goals []RemediatedGoal `json:"goals"`
 }
 
-// CruiseControlConfig defines the config for Cruise Control
-type CruiseControlConfig struct {
-	CruiseControlTaskSpec CruiseControlTaskSpec         `json:"cruiseControlTaskSpec,omitempty"`
-	CruiseControlEndpoint string                        `json:"cruiseControlEndpoint,omitempty"`
-	Resources             *corev1.ResourceRequirements  `json:"resourceRequirements,omitempty"`
-	ServiceAccountName    string                        `json:"serviceAccountName,omitempty"`
-	ImagePullSecrets      []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
-	NodeSelector          map[string]string             `json:"nodeSelector,omitempty"`
-	Tolerations           []corev1.Toleration           `json:"tolerations,omitempty"`
-	Config                string                        `json:"config,omitempty"`
-	CapacityConfig        string                        `json:"capacityConfig,omitempty"`
-	ClusterConfig         string                        `json:"clusterConfig,omitempty"`
-	Log4jConfig           string                        `json:"log4jConfig,omitempty"`
-	Image                 string                        `json:"image,omitempty"`
-	TopicConfig           *TopicConfig                  `json:"topicConfig,omitempty"`
-	//  Annotations to be applied to CruiseControl pod
+// RemediatedGoal defines the remediation of the violations of a Cruise Control goal
+type RemediatedGoal struct {
+	// Name is the name of the Cruise Control goal, e.g. DiskUsageDistributionGoal
+	Name string `json:"name"`
+	// Cooldown is the minimum time between two remediations of the violations of the goal, defaults to 1h
 	// +optional
-	CruiseControlAnnotations map[string]string `json:"cruiseControlAnnotations,omitempty"`
-	// InitContainers add extra initContainers to CruiseControl pod
-	InitContainers []corev1.Container `json:"initContainers,omitempty"`
-	// Volumes define some extra Kubernetes Volumes for the CruiseControl Pods.
-	Volumes []corev1.Volume `json:"volumes,omitempty"`
-	// VolumeMounts define some extra Kubernetes Volume mounts for the CruiseControl Pods.
-	VolumeMounts       []corev1.VolumeMount       `json:"volumeMounts,omitempty"`
-	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
-	// SecurityContext allows to set security context for the CruiseControl container
-	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
+}
+
+// GetCooldown returns the minimum time between two remediations of the violations of the goal
+func (g RemediatedGoal) GetCooldown() time.Duration {
+	if g.Cooldown != nil {
+		return g.Cooldown.Duration
+	}
+	return DefaultGoalViolationRemediationCooldown
+}
+
+// NodeNetworkCapacityConfig defines how the network capacities of the brokers are derived from their nodes.
+// The kafka.banzaicloud.io/incoming-network-throughput and kafka.banzaicloud.io/outgoing-network-throughput
+// annotations of a node override the capacity of its instance type.
+type NodeNetworkCapacityConfig struct {
+	// InstanceTypes maps the instance types of the nodes, as reported by the node.kubernetes.io/instance-type
+	// node label, to the network throughput of the instance type in KB/s used both as the NW_IN and NW_OUT capacity
+	// +optional
+	InstanceTypes map[string]string `json:"instanceTypes,omitempty"`
+}
+
+// CruiseControlAuthentication defines how the operator authenticates its requests to Cruise Control.
+// Either a static token, a token exchange or basic authentication has to be set.
+type CruiseControlAuthentication struct {
+	// BearerTokenSecretRef references the key of a Secret in the namespace of the KafkaCluster holding a static token.
+	// The Secret is read again when Cruise Control rejects the token, so it can be rotated.
+	// +optional
+	BearerTokenSecretRef *corev1.SecretKeySelector `json:"bearerTokenSecretRef,omitempty"`
+	// TokenExchange obtains the token from an OAuth2 token endpoint with the client credentials grant
+	// +optional
+	TokenExchange *CruiseControlTokenExchange `json:"tokenExchange,omitempty"`
+	// BasicAuth sends the credentials of a Cruise Control user with HTTP basic authentication
+	// +optional
+	BasicAuth *CruiseControlBasicAuth `json:"basicAuth,omitempty"`
+}
+
+// CruiseControlBasicAuth defines the credentials of the Cruise Control user the operator authenticates as
+type CruiseControlBasicAuth struct {
+	Username string `json:"username"`
+	// PasswordSecretRef references the key of a Secret in the namespace of the KafkaCluster holding the password
+	PasswordSecretRef corev1.SecretKeySelector `json:"passwordSecretRef"`
+}
+
+// CruiseControlClientTLS defines the TLS config of the connections of the operator to Cruise Control
+type CruiseControlClientTLS struct {
+	// CASecretRef references the key of a Secret in the namespace of the KafkaCluster holding the PEM encoded CA
+	// bundle the certificate of Cruise Control is verified with, the system CAs are used when it is not set
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+	// ClientCertSecretName is the name of a kubernetes.io/tls Secret in the namespace of the KafkaCluster holding the
+	// client certificate and key presented to Cruise Control
+	// +optional
+	ClientCertSecretName string `json:"clientCertSecretName,omitempty"`
+	// IncludeSystemCAs verifies the certificates of Cruise Control and the proxy with the system CAs as well as with
+	// the CA bundle of CASecretRef, e.g. when only one of them is issued by a private PKI
+	// +optional
+	IncludeSystemCAs bool `json:"includeSystemCAs,omitempty"`
+}
+
+// CruiseControlClientProxy defines the HTTP(S) proxy the operator connects to Cruise Control through
+type CruiseControlClientProxy struct {
+	// URL is the URL of the proxy, e.g. http://proxy.example.com:3128
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// CredentialsSecretName is the name of a kubernetes.io/basic-auth Secret in the namespace of the KafkaCluster
+	// holding the username and password the operator authenticates to the proxy with
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// CruiseControlTokenExchange defines the OAuth2 client credentials used to obtain the bearer token of the Cruise
+// Control requests. A new token is requested when the current one expires or is rejected by Cruise Control.
+// Either the token endpoint or the OIDC issuer has to be set.
+type CruiseControlTokenExchange struct {
+	// TokenURL is the URL of the token endpoint
+	// +optional
+	TokenURL string `json:"tokenURL,omitempty"`
+	// IssuerURL is the URL of the OpenID Connect issuer the token endpoint is discovered from when TokenURL is not set
+	// +optional
+	IssuerURL string `json:"issuerURL,omitempty"`
+	// ClientID is the OAuth2 client ID of the operator
+	ClientID string `json:"clientID"`
+	// ClientSecretRef references the key of a Secret in the namespace of the KafkaCluster holding the client secret
+	ClientSecretRef corev1.SecretKeySelector `json:"clientSecretRef"`
+	// Scopes are the scopes requested for the token
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // CruiseControlTaskSpec specifies the configuration of the CC Tasks
@@ -294,6 +1824,64 @@ type EnvoyConfig struct {
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	CommandLineArgs *EnvoyCommandLineArgs `json:"envoyCommandLineArgs,omitempty"`
+	// Architectures restricts the Envoy pods to nodes with one of the given CPU architectures,
+	// it overrides spec.architectures
+	// +optional
+	Architectures []NodeArchitecture `json:"architectures,omitempty"`
+	// Autoscaling creates a HorizontalPodAutoscaler for the Envoy Deployment(s), replicas is only used as the
+	// default of the minimum replicas then
+	// +optional
+	Autoscaling *EnvoyAutoscaling `json:"autoscaling,omitempty"`
+	// TLS configures how Envoy handles the TLS of the external client connections, by default the connections are
+	// proxied to the brokers as they are
+	// +optional
+	TLS *EnvoyTLSConfig `json:"tls,omitempty"`
+}
+
+// EnvoyTLSConfig defines the TLS handling of the external client connections by Envoy
+type EnvoyTLSConfig struct {
+	// Mode is the way Envoy handles the TLS of the client connections, Terminate forwards the decrypted streams to
+	// plaintext broker listeners while Reencrypt opens new TLS connections to ssl broker listeners validated by the
+	// internal CA, so that the clients can use a certificate issued by a public CA
+	// +kubebuilder:validation:Enum=Passthrough;Terminate;Reencrypt
+	Mode EnvoyTLSMode `json:"mode"`
+	// SecretName is the name of the kubernetes.io/tls Secret holding the certificate and the key presented to the
+	// clients, required unless the mode is Passthrough
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// ServerNames are the SNI server names, wildcards like *.kafka.example.com are allowed, the client connections
+	// are accepted for, connections for other names are rejected. All the connections are accepted when empty
+	// +optional
+	ServerNames []string `json:"serverNames,omitempty"`
+	// UpstreamCASecretName is the name of the Secret holding the ca.crt the certificates of the brokers are
+	// validated by in Reencrypt mode, defaults to the server certificate Secret of the listener
+	// +optional
+	UpstreamCASecretName string `json:"upstreamCASecretName,omitempty"`
+}
+
+// EnvoyAutoscaling defines the HorizontalPodAutoscaler of the Envoy Deployment(s)
+type EnvoyAutoscaling struct {
+	// MinReplicas is the lower limit of the Envoy replicas, defaults to replicas
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit of the Envoy replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetCPUUtilizationPercentage is the average CPU utilization of the Envoy pods, relative to their
+	// requested CPU, to keep. Defaults to 80 when targetConnectionsPerPod is not set either
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	// TargetConnectionsPerPod is the average number of active downstream connections of the Envoy pods to keep.
+	// The connection metric of Envoy has to be served through the custom metrics API, e.g. by prometheus-adapter
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetConnectionsPerPod *int32 `json:"targetConnectionsPerPod,omitempty"`
+	// ConnectionsMetricName is the name of the custom pod metric holding the active downstream connections of Envoy,
+	// defaults to envoy_server_total_connections
+	// +optional
+	ConnectionsMetricName string `json:"connectionsMetricName,omitempty"`
 }
 
 // EnvoyCommandLineArgs defines envoy command line arguments
@@ -349,6 +1937,56 @@ type ListenersConfig struct {
 	InternalListeners  []InternalListenerConfig `json:"internalListeners"`
 	SSLSecrets         *SSLSecrets              `json:"sslSecrets,omitempty"`
 	ServiceAnnotations map[string]string        `json:"serviceAnnotations,omitempty"`
+	// CertificateRevocation configures the revocation checking of the client certificates presented to the brokers
+	// on listeners using mTLS
+	// +optional
+	CertificateRevocation *CertificateRevocationConfig `json:"certificateRevocation,omitempty"`
+	// PrincipalBuilder configures a custom principal builder class used by the brokers to build the principal
+	// of the authenticated clients e.g. from a SPIFFE URI found in the SANs of the client certificates
+	// +optional
+	PrincipalBuilder *PrincipalBuilderConfig `json:"principalBuilder,omitempty"`
+}
+
+// PrincipalBuilderConfig defines a custom principal builder class and the image containing its jar
+type PrincipalBuilderConfig struct {
+	// ClassName is the fully qualified name of the class implementing
+	// org.apache.kafka.common.security.auth.KafkaPrincipalBuilder
+	// +kubebuilder:validation:Pattern=`^([a-zA-Z_$][a-zA-Z0-9_$]*\.)*[a-zA-Z_$][a-zA-Z0-9_$]*$`
+	ClassName string `json:"className"`
+	// Image is the image containing the jar of the principal builder, the jar is copied onto the classpath
+	// of the brokers by an init container. When empty the class must be on the classpath of the Kafka image already.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// JarPath is the path of the jar inside the image, defaults to /opt/principal-builder/principal-builder.jar
+	// +kubebuilder:validation:Pattern=`^/[a-zA-Z0-9_./-]+\.jar$`
+	// +optional
+	JarPath string `json:"jarPath,omitempty"`
+}
+
+// GetJarPath returns the path of the principal builder jar inside the image
+func (c *PrincipalBuilderConfig) GetJarPath() string {
+	if c.JarPath == "" {
+		return "/opt/principal-builder/principal-builder.jar"
+	}
+	return c.JarPath
+}
+
+// CertificateRevocationConfig defines how the brokers check whether the client certificates were revoked
+type CertificateRevocationConfig struct {
+	// EnableCRLDistributionPoints makes the brokers download the CRLs from the distribution points
+	// listed in the client certificates
+	EnableCRLDistributionPoints bool `json:"enableCRLDistributionPoints,omitempty"`
+	// EnableOCSP makes the brokers check the revocation status of the client certificates using OCSP
+	EnableOCSP bool `json:"enableOCSP,omitempty"`
+	// OCSPResponderURL overrides the OCSP responder location found in the authority information access
+	// extension of the client certificates
+	// +optional
+	OCSPResponderURL string `json:"ocspResponderURL,omitempty"`
+}
+
+// IsEnabled returns true if any kind of certificate revocation checking is enabled
+func (c *CertificateRevocationConfig) IsEnabled() bool {
+	return c != nil && (c.EnableCRLDistributionPoints || c.EnableOCSP)
 }
 
 // GetServiceAnnotations returns a copy of the ServiceAnnotations field.
@@ -363,6 +2001,15 @@ func (c ExternalListenerConfig) GetAccessMethod() corev1.ServiceType {
 	return c.AccessMethod
 }
 
+// GetIngressController returns the ingress controller of the external listener or the given cluster wide default
+// if not specified otherwise
+func (c ExternalListenerConfig) GetIngressController(defaultIngressController string) string {
+	if c.IngressController == "" {
+		return defaultIngressController
+	}
+	return c.IngressController
+}
+
 func (c ExternalListenerConfig) GetAnyCastPort() int32 {
 	if c.AnyCastPort == nil {
 		return DefaultAnyCastPort
@@ -448,6 +2095,12 @@ type ExternalListenerConfig struct {
 	// NodePort should be used in Kubernetes environments with no support for provisioning Load Balancers.
 	// +optional
 	AccessMethod corev1.ServiceType `json:"accessMethod,omitempty"`
+	// IngressController specifies the type of the ingress controller to be used for this external listener
+	// if set overrides the default `KafkaClusterSpec.IngressController`. This allows exposing the cluster through
+	// several external listeners at the same time, each of them using a different ingress controller.
+	// +kubebuilder:validation:Enum=envoy;istioingress
+	// +optional
+	IngressController string `json:"ingressController,omitempty"`
 	// Config allows to specify ingress controller configuration per external listener
 	// if set overrides the the default `KafkaClusterSpec.IstioIngressConfig` or `KafkaClusterSpec.EnvoyConfig` for this external listener.
 	// +optional
@@ -486,6 +2139,13 @@ type CommonListenerSpec struct {
 	// This field defaults to "required" if it is omitted
 	// +kubebuilder:validation:Enum=required;requested;none
 	SSLClientAuth SSLClientAuthentication `json:"sslClientAuth,omitempty"`
+	// SASLPlainCredentialsSecret is a reference to the Kubernetes secret that contains the credentials of the clients
+	// authenticating with SASL/PLAIN on the listener. Each key of the secret is a username and its value is the password.
+	// The rendered JAAS configuration is stored in the <cluster>-sasl-plain secret mounted into the broker pods.
+	// The credentials are reloaded by the brokers without restart when the secret changes.
+	// Can only be set for listeners of sasl_ssl or sasl_plaintext type.
+	// +optional
+	SASLPlainCredentialsSecret *corev1.LocalObjectReference `json:"saslPlainCredentialsSecret,omitempty"`
 	// +kubebuilder:validation:Pattern=^[a-z0-9\-]+
 	Name          string `json:"name"`
 	ContainerPort int32  `json:"containerPort"`
@@ -498,6 +2158,14 @@ func (c *CommonListenerSpec) GetServerSSLCertSecretName() string {
 	return c.ServerSSLCertSecret.Name
 }
 
+// GetSASLPlainCredentialsSecretName returns the name of the secret holding the SASL/PLAIN credentials of the listener
+func (c *CommonListenerSpec) GetSASLPlainCredentialsSecretName() string {
+	if c.SASLPlainCredentialsSecret == nil {
+		return ""
+	}
+	return c.SASLPlainCredentialsSecret.Name
+}
+
 // ListenerStatuses holds information about the statuses of the configured listeners.
 // The internal and external listeners are stored in separate maps, and each listener can be looked up by name.
 type ListenerStatuses struct {
@@ -522,10 +2190,48 @@ func (l ListenerStatusList) Swap(i, j int) {
 
 // ListenerStatus holds information about the address of the listener
 type ListenerStatus struct {
-	Name    string `json:"name"`
+	// Name is "any-broker" (or "any-broker-<ingressConfig>") for the all brokers service, "headless" for the headless
+	// service, and "broker-<id>" for the address advertised by the given broker
+	Name string `json:"name"`
+	// Address is the host:port clients can use to connect
 	Address string `json:"address"`
 }
 
+// IsBootstrap returns true if the status holds the address of the all brokers or headless service
+func (l ListenerStatus) IsBootstrap() bool {
+	return strings.HasPrefix(l.Name, "any-broker") || l.Name == "headless"
+}
+
+// BootstrapAddresses returns the addresses clients can bootstrap from: those of the all brokers or headless services,
+// or the addresses of every broker if there is no such service (e.g. for node port listeners)
+func (l ListenerStatusList) BootstrapAddresses() []string {
+	var bootstrap, brokers []string
+	for _, status := range l {
+		switch {
+		case status.IsBootstrap():
+			bootstrap = append(bootstrap, status.Address)
+		case strings.HasPrefix(status.Name, "broker-"):
+			brokers = append(brokers, status.Address)
+		}
+	}
+	if len(bootstrap) == 0 {
+		bootstrap = brokers
+	}
+	sort.Strings(bootstrap)
+	return bootstrap
+}
+
+// BrokerAddress returns the address advertised by the given broker on the listener
+func (l ListenerStatusList) BrokerAddress(brokerID int32) (string, bool) {
+	name := fmt.Sprintf("broker-%d", brokerID)
+	for _, status := range l {
+		if status.Name == name {
+			return status.Address, true
+		}
+	}
+	return "", false
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:JSONPath=".status.state",name="Cluster state",type="string"
@@ -533,6 +2239,8 @@ type ListenerStatus struct {
 // +kubebuilder:printcolumn:JSONPath=".status.rollingUpgradeStatus.lastSuccess",name="Last successful upgrade",type="string"
 // +kubebuilder:printcolumn:JSONPath=".status.rollingUpgradeStatus.errorCount",name="Upgrade error count",type="string"
 // +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
+// +kubebuilder:webhook:failurePolicy="fail",sideEffects="None",name="kafkaclusters.kafka.banzaicloud.io",path="/validate",mutating=false,resources={"kafkaclusters"},verbs={"create","update"},groups={"kafka.banzaicloud.io"},versions={"v1beta1"},admissionReviewVersions={"v1"}
+// +kubebuilder:webhook:failurePolicy="fail",sideEffects="None",name="deletion-protection.kafka.banzaicloud.io",path="/validate",mutating=false,resources={"kafkaclusters","kafkatopics","kafkausers"},verbs={"delete"},groups={"kafka.banzaicloud.io"},versions={"v1alpha1","v1beta1"},admissionReviewVersions={"v1"}
 
 // KafkaCluster is the Schema for the kafkaclusters API
 type KafkaCluster struct {
@@ -602,6 +2310,76 @@ func (k *KafkaClusterSpec) IsClientSSLSecretPresent() bool {
 	return k.ListenersConfig.SSLSecrets != nil || k.GetClientSSLCertSecretName() != ""
 }
 
+// IsDevProfileEnabled returns true if the cluster runs with the minimal-footprint development profile
+func (kSpec *KafkaClusterSpec) IsDevProfileEnabled() bool {
+	return kSpec.DevProfile.Enabled
+}
+
+// GetBrokerAntiAffinityType returns the strength of the broker anti-affinity, it falls back to OneBrokerPerNode
+func (kSpec *KafkaClusterSpec) GetBrokerAntiAffinityType() AntiAffinityType {
+	if kSpec.BrokerAntiAffinity != nil && kSpec.BrokerAntiAffinity.Type != "" {
+		return kSpec.BrokerAntiAffinity.Type
+	}
+	if kSpec.OneBrokerPerNode {
+		return AntiAffinityRequired
+	}
+	return AntiAffinityPreferred
+}
+
+// GetBrokerIDsInMaintenance returns the IDs of the brokers which are in maintenance
+func (kSpec *KafkaClusterSpec) GetBrokerIDsInMaintenance() []string {
+	var brokerIDs []string
+	for _, broker := range kSpec.Brokers {
+		if broker.Maintenance {
+			brokerIDs = append(brokerIDs, strconv.Itoa(int(broker.Id)))
+		}
+	}
+	return brokerIDs
+}
+
+// IsBrokerInMaintenance returns true if the broker with the given ID is in maintenance
+func (kSpec *KafkaClusterSpec) IsBrokerInMaintenance(brokerID string) bool {
+	for _, id := range kSpec.GetBrokerIDsInMaintenance() {
+		if id == brokerID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdoptionPending returns true if the cluster is being adopted and the adoption is not approved yet
+func (kSpec *KafkaClusterSpec) IsAdoptionPending() bool {
+	return kSpec.Adoption != nil && !kSpec.Adoption.Approved
+}
+
+// GetOperationHistoryLimit returns the number of operations kept in the operation history of the cluster
+func (kSpec *KafkaClusterSpec) GetOperationHistoryLimit() int {
+	if kSpec.OperationHistoryLimit == nil {
+		return DefaultOperationHistoryLimit
+	}
+	return int(*kSpec.OperationHistoryLimit)
+}
+
+// UsesBuiltInRebalancer returns true if the partition replicas are reassigned by the operator instead of Cruise Control
+func (kSpec *KafkaClusterSpec) UsesBuiltInRebalancer() bool {
+	return kSpec.Rebalancer == RebalancerBuiltIn
+}
+
+// IsCruiseControlDisabled returns true if the cluster runs without Cruise Control
+func (kSpec *KafkaClusterSpec) IsCruiseControlDisabled() bool {
+	return kSpec.IsDevProfileEnabled() || kSpec.UsesBuiltInRebalancer()
+}
+
+// GetBrokerDataRetentionPeriod returns the time the PVCs of the removed brokers are kept for, 0 when they are deleted
+// together with the brokers
+func (kSpec *KafkaClusterSpec) GetBrokerDataRetentionPeriod() time.Duration {
+	if kSpec.BrokerDecommission == nil || kSpec.BrokerDecommission.DataRetentionPeriod == nil ||
+		kSpec.BrokerDecommission.DataRetentionPeriod.Duration < 0 {
+		return 0
+	}
+	return kSpec.BrokerDecommission.DataRetentionPeriod.Duration
+}
+
 // GetIngressController returns the default Envoy ingress controller if not specified otherwise
 func (kSpec *KafkaClusterSpec) GetIngressController() string {
 	if kSpec.IngressController == "" {
@@ -618,6 +2396,24 @@ func (kSpec *KafkaClusterSpec) GetKubernetesClusterDomain() string {
 	return kSpec.KubernetesClusterDomain
 }
 
+// GetKRaftControllerIDs returns the IDs of the brokers with the controller process role in ascending order, the voters
+// of the metadata quorum in KRaft mode
+func (kSpec *KafkaClusterSpec) GetKRaftControllerIDs() ([]int32, error) {
+	var ids []int32
+	for _, broker := range kSpec.Brokers {
+		broker := broker
+		brokerConfig, err := broker.GetBrokerConfig(*kSpec)
+		if err != nil {
+			return nil, err
+		}
+		if brokerConfig != nil && brokerConfig.IsControllerNode() {
+			ids = append(ids, broker.Id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
 // GetZkPath returns the default "/" ZkPath if not specified otherwise
 func (kSpec *KafkaClusterSpec) GetZkPath() string {
 	const prefix = "/"
@@ -648,6 +2444,117 @@ func (kSpec *KafkaClusterSpec) GetClusterMetricsReporterImage() string {
 	return kSpec.CruiseControlConfig.GetCCImage()
 }
 
+// GetMaxDiskUsagePercentage returns the max disk usage of the remaining brokers allowed after a scale down
+func (pConfig *PreflightChecksConfig) GetMaxDiskUsagePercentage() int {
+	if pConfig.MaxDiskUsagePercentage == 0 {
+		return 80
+	}
+	return pConfig.MaxDiskUsagePercentage
+}
+
+// GetProduceP99ThresholdMs returns the p99 produce request latency above which a broker is Slow
+func (lConfig *RequestLatencyConfig) GetProduceP99ThresholdMs() int32 {
+	if lConfig.ProduceP99ThresholdMs == 0 {
+		return 1000
+	}
+	return lConfig.ProduceP99ThresholdMs
+}
+
+// GetFetchP99ThresholdMs returns the p99 consumer fetch request latency above which a broker is Slow
+func (lConfig *RequestLatencyConfig) GetFetchP99ThresholdMs() int32 {
+	if lConfig.FetchP99ThresholdMs == 0 {
+		return 2000
+	}
+	return lConfig.FetchP99ThresholdMs
+}
+
+// GetMaxControllerChangesPerHour returns the number of controller changes within an hour the controller is
+// considered unstable above
+func (cConfig *ControllerHealthConfig) GetMaxControllerChangesPerHour() int32 {
+	if cConfig.MaxControllerChangesPerHour == 0 {
+		return 3
+	}
+	return cConfig.MaxControllerChangesPerHour
+}
+
+// GetMaxVoterLag returns the number of metadata log records a KRaft voter may lag behind the quorum leader
+func (cConfig *ControllerHealthConfig) GetMaxVoterLag() int64 {
+	if cConfig.MaxVoterLag == 0 {
+		return 10000
+	}
+	return cConfig.MaxVoterLag
+}
+
+// GetImage returns the image of the smoke test Job
+func (sConfig *SmokeTestConfig) GetImage(clusterImage string) string {
+	if sConfig.Image != "" {
+		return sConfig.Image
+	}
+	return clusterImage
+}
+
+// GetNumRecords returns the number of records produced and consumed by the smoke test
+func (sConfig *SmokeTestConfig) GetNumRecords() int32 {
+	if sConfig.NumRecords == 0 {
+		return 1000
+	}
+	return sConfig.NumRecords
+}
+
+// GetTimeoutSeconds returns the duration after which the smoke test is considered failed
+func (sConfig *SmokeTestConfig) GetTimeoutSeconds() int64 {
+	if sConfig.TimeoutSeconds == 0 {
+		return 300
+	}
+	return sConfig.TimeoutSeconds
+}
+
+// InMaintenanceWindow returns true if no maintenance windows are specified or t falls into any of them
+func (kSpec *KafkaClusterSpec) InMaintenanceWindow(t time.Time) bool {
+	if len(kSpec.MaintenanceWindows) == 0 {
+		return true
+	}
+	for _, w := range kSpec.MaintenanceWindows {
+		if w.IsOpen(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen returns true if t falls into an occurrence of the maintenance window. Occurrences started on previous days
+// are taken into account as well in case the window spans over midnight.
+func (w MaintenanceWindow) IsOpen(t time.Time) bool {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil || w.Duration.Duration <= 0 {
+		return false
+	}
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+	for lookBack := 0; lookBack <= int(w.Duration.Duration/(24*time.Hour))+1; lookBack++ {
+		occurrence := day.AddDate(0, 0, -lookBack)
+		if !w.recursOn(occurrence.Weekday()) {
+			continue
+		}
+		if !t.Before(occurrence) && t.Before(occurrence.Add(w.Duration.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w MaintenanceWindow) recursOn(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if string(d) == weekday.String() {
+			return true
+		}
+	}
+	return false
+}
+
 func (cTaskSpec *CruiseControlTaskSpec) GetDurationMinutes() float64 {
 	if cTaskSpec.RetryDurationMinutes == 0 {
 		return 5
@@ -681,6 +2588,58 @@ func (eConfig *EnvoyConfig) GetReplicas() int32 {
 	return eConfig.Replicas
 }
 
+// GetTLSMode returns the way Envoy handles the TLS of the external client connections
+func (eConfig *EnvoyConfig) GetTLSMode() EnvoyTLSMode {
+	if eConfig.TLS == nil || eConfig.TLS.Mode == "" {
+		return EnvoyTLSModePassthrough
+	}
+	return eConfig.TLS.Mode
+}
+
+// TerminatesTLS returns true if Envoy terminates the TLS of the external client connections
+func (eConfig *EnvoyConfig) TerminatesTLS() bool {
+	mode := eConfig.GetTLSMode()
+	return mode == EnvoyTLSModeTerminate || mode == EnvoyTLSModeReencrypt
+}
+
+// IsAutoscalingEnabled returns true if the Envoy Deployment(s) are scaled by a HorizontalPodAutoscaler
+func (eConfig *EnvoyConfig) IsAutoscalingEnabled() bool {
+	return eConfig.Autoscaling != nil
+}
+
+// GetMinReplicas returns the lower limit of the Envoy replicas, which defaults to the replicas
+// of the Envoy deployment capped by the upper limit
+func (eConfig *EnvoyConfig) GetMinReplicas() int32 {
+	if eConfig.Autoscaling == nil {
+		return eConfig.GetReplicas()
+	}
+	if eConfig.Autoscaling.MinReplicas != nil {
+		return *eConfig.Autoscaling.MinReplicas
+	}
+	if replicas := eConfig.GetReplicas(); replicas < eConfig.Autoscaling.MaxReplicas {
+		return replicas
+	}
+	return eConfig.Autoscaling.MaxReplicas
+}
+
+// GetTargetCPUUtilizationPercentage returns the average CPU utilization the Envoy pods are scaled at,
+// nil means no CPU based scaling
+func (eAutoscaling *EnvoyAutoscaling) GetTargetCPUUtilizationPercentage() *int32 {
+	if eAutoscaling.TargetCPUUtilizationPercentage == nil && eAutoscaling.TargetConnectionsPerPod == nil {
+		target := DefaultEnvoyTargetCPUUtilizationPercentage
+		return &target
+	}
+	return eAutoscaling.TargetCPUUtilizationPercentage
+}
+
+// GetConnectionsMetricName returns the name of the custom pod metric holding the active connections of Envoy
+func (eAutoscaling *EnvoyAutoscaling) GetConnectionsMetricName() string {
+	if eAutoscaling.ConnectionsMetricName != "" {
+		return eAutoscaling.ConnectionsMetricName
+	}
+	return DefaultEnvoyConnectionsMetricName
+}
+
 // GetServiceAccount returns the Kubernetes Service Account to use for Kafka Cluster
 func (bConfig *BrokerConfig) GetServiceAccount() string {
 	if bConfig.ServiceAccountName != "" {
@@ -728,6 +2687,59 @@ func (bConfig *BrokerConfig) GetTerminationGracePeriod() int64 {
 	return *bConfig.TerminationGracePeriod
 }
 
+// GetPeriodSeconds returns the period of the startup probes of the broker pods
+func (pConfig *StartupProbeConfig) GetPeriodSeconds() int32 {
+	if pConfig.PeriodSeconds == 0 {
+		return 10
+	}
+	return pConfig.PeriodSeconds
+}
+
+// GetTimeoutSeconds returns the timeout of the startup probes of the broker pods
+func (pConfig *StartupProbeConfig) GetTimeoutSeconds() int32 {
+	if pConfig.TimeoutSeconds == 0 {
+		return 5
+	}
+	return pConfig.TimeoutSeconds
+}
+
+// GetFailureThreshold returns the number of failed startup probes the broker pods are replaced after
+func (pConfig *StartupProbeConfig) GetFailureThreshold() int32 {
+	if pConfig.FailureThreshold == 0 {
+		return 30
+	}
+	return pConfig.FailureThreshold
+}
+
+// IsEphemeralStorageEnabled returns true if the brokers keep their data on emptyDir volumes
+func (bConfig *BrokerConfig) IsEphemeralStorageEnabled() bool {
+	return bConfig.EphemeralStorage != nil && bConfig.EphemeralStorage.Enabled
+}
+
+// GetArchitectures returns the CPU architectures the Cruise Control pod can be scheduled to
+func (cConfig *CruiseControlConfig) GetArchitectures(defaults []NodeArchitecture) []NodeArchitecture {
+	if len(cConfig.Architectures) > 0 {
+		return cConfig.Architectures
+	}
+	return defaults
+}
+
+// GetArchitectures returns the CPU architectures the Envoy pods can be scheduled to
+func (eConfig *EnvoyConfig) GetArchitectures(defaults []NodeArchitecture) []NodeArchitecture {
+	if len(eConfig.Architectures) > 0 {
+		return eConfig.Architectures
+	}
+	return defaults
+}
+
+// GetArchitectures returns the CPU architectures the broker pods can be scheduled to
+func (bConfig *BrokerConfig) GetArchitectures(defaults []NodeArchitecture) []NodeArchitecture {
+	if len(bConfig.Architectures) > 0 {
+		return bConfig.Architectures
+	}
+	return defaults
+}
+
 // GetNodeSelector returns the node selector for cruise control
 func (cConfig *CruiseControlConfig) GetNodeSelector() map[string]string {
 	return cConfig.NodeSelector
@@ -763,6 +2775,49 @@ func (bConfig *BrokerConfig) GetBrokerAnnotations() map[string]string {
 	return util.CloneMap(bConfig.BrokerAnnotations)
 }
 
+// GetProcessRoles returns the KRaft process roles of the broker, the broker role unless specified otherwise
+func (bConfig *BrokerConfig) GetProcessRoles() []ProcessRole {
+	if len(bConfig.ProcessRoles) == 0 {
+		return []ProcessRole{ProcessRoleBroker}
+	}
+	return bConfig.ProcessRoles
+}
+
+// IsControllerNode returns true if the broker is a voter of the KRaft metadata quorum
+func (bConfig *BrokerConfig) IsControllerNode() bool {
+	for _, role := range bConfig.GetProcessRoles() {
+		if role == ProcessRoleController {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRollingUpgradeFailureThreshold returns the number of failures the cluster can tolerate during the restart of
+// the broker, the one of the given cluster-wide config unless it is overridden for the broker
+func (bConfig *BrokerConfig) GetRollingUpgradeFailureThreshold(rollingUpgradeConfig RollingUpgradeConfig) int {
+	if bConfig != nil && bConfig.RollingUpgrade != nil && bConfig.RollingUpgrade.FailureThreshold != nil {
+		return *bConfig.RollingUpgrade.FailureThreshold
+	}
+	return rollingUpgradeConfig.FailureThreshold
+}
+
+// IsRollingUpgradePaused returns true if the rolling upgrades of the broker are held back
+func (bConfig *BrokerConfig) IsRollingUpgradePaused() bool {
+	return bConfig != nil && bConfig.RollingUpgrade != nil && bConfig.RollingUpgrade.Paused
+}
+
+// IsBrokerNode returns true if the broker stores partitions and serves clients in KRaft mode, controller-only nodes
+// do not
+func (bConfig *BrokerConfig) IsBrokerNode() bool {
+	for _, role := range bConfig.GetProcessRoles() {
+		if role == ProcessRoleBroker {
+			return true
+		}
+	}
+	return false
+}
+
 // GetBrokerLabels returns the labels that are applied to broker pods
 func (bConfig *BrokerConfig) GetBrokerLabels(kafkaClusterName string, brokerId int32) map[string]string {
 	return util.MergeLabels(
@@ -900,6 +2955,32 @@ func (cConfig *CruiseControlConfig) GetCCImage() string {
 	return "ghcr.io/banzaicloud/cruise-control:2.5.86"
 }
 
+// GetOperationGoals returns the Cruise Control goals of the operations started by the operator
+func (cConfig *CruiseControlConfig) GetOperationGoals() CruiseControlOperationGoals {
+	if cConfig.OperationGoals != nil {
+		return *cConfig.OperationGoals
+	}
+	return CruiseControlOperationGoals{}
+}
+
+// GetReplicationThrottle returns the upper bound of the bandwidth in bytes per second used to move partition replicas
+// by the operations started by the operator, 0 means the default throttle of Cruise Control
+func (cConfig *CruiseControlConfig) GetReplicationThrottle() int64 {
+	if cConfig.ReplicationThrottle != nil {
+		return *cConfig.ReplicationThrottle
+	}
+	return 0
+}
+
+// GetExecutorConcurrency returns the upper bounds of the concurrent movements of the operations started by the
+// operator, the zero values mean the defaults of Cruise Control
+func (cConfig *CruiseControlConfig) GetExecutorConcurrency() CruiseControlExecutorConcurrency {
+	if cConfig.ExecutorConcurrency != nil {
+		return *cConfig.ExecutorConcurrency
+	}
+	return CruiseControlExecutorConcurrency{}
+}
+
 // GetCCLog4jConfig returns the used Cruise Control log4j configuration
 func (cConfig *CruiseControlConfig) GetCCLog4jConfig() string {
 	if cConfig.Log4jConfig != "" {
@@ -962,6 +3043,10 @@ func (b *Broker) GetBrokerConfig(kafkaClusterSpec KafkaClusterSpec) (*BrokerConf
 		return nil, errors.WrapIf(err, "could not merge brokerConfig.Affinity with ConfigGroup.Affinity")
 	}
 	envs := mergeEnvs(kafkaClusterSpec, &groupConfig, bConfig)
+	// the architectures and the lifecycle of the broker override the ones of the group instead of extending them
+	architectures := bConfig.Architectures
+	lifecycle := bConfig.Lifecycle.DeepCopy()
+	processRoles := bConfig.ProcessRoles
 
 	err = mergo.Merge(bConfig, groupConfig, mergo.WithAppendSlice)
 	if err != nil {
@@ -973,6 +3058,15 @@ func (b *Broker) GetBrokerConfig(kafkaClusterSpec KafkaClusterSpec) (*BrokerConf
 		bConfig.Affinity = dstAffinity
 	}
 	bConfig.Envs = envs
+	if len(architectures) > 0 {
+		bConfig.Architectures = architectures
+	}
+	if lifecycle != nil {
+		bConfig.Lifecycle = lifecycle
+	}
+	if len(processRoles) > 0 {
+		bConfig.ProcessRoles = processRoles
+	}
 
 	return bConfig, nil
 }
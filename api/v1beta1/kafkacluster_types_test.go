@@ -18,6 +18,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
@@ -427,6 +428,38 @@ func TestGetBrokerConfigEnvs(t *testing.T) {
 	}
 }
 
+func TestGetBrokerConfigLifecycle(t *testing.T) {
+	spec := KafkaClusterSpec{
+		BrokerConfigGroups: map[string]BrokerConfig{
+			"default": {
+				Lifecycle: &BrokerLifecycleConfig{
+					PreStopCommand: []string{"/bin/group-pre-stop"},
+					StartupProbe:   &StartupProbeConfig{FailureThreshold: 60},
+				},
+			},
+		},
+	}
+
+	broker := Broker{Id: 0, BrokerConfigGroup: "default"}
+	result, err := broker.GetBrokerConfig(spec)
+	if err != nil {
+		t.Fatal("Error GetBrokerConfig throw an unexpected error")
+	}
+	if !reflect.DeepEqual(result.Lifecycle, spec.BrokerConfigGroups["default"].Lifecycle) {
+		t.Error("Expected the lifecycle of the group, got:", result.Lifecycle)
+	}
+
+	brokerLifecycle := &BrokerLifecycleConfig{PostStartCommand: []string{"/bin/wait-for-agent"}}
+	broker.BrokerConfig = &BrokerConfig{Lifecycle: brokerLifecycle}
+	result, err = broker.GetBrokerConfig(spec)
+	if err != nil {
+		t.Fatal("Error GetBrokerConfig throw an unexpected error")
+	}
+	if !reflect.DeepEqual(result.Lifecycle, brokerLifecycle) {
+		t.Error("Expected:", brokerLifecycle, "Got:", result.Lifecycle)
+	}
+}
+
 // TestGetBrokerLabels makes sure the reserved labels "app", "brokerId", and "kafka_cr" are not overridden by the BrokerConfig
 func TestGetBrokerLabels(t *testing.T) {
 	const (
@@ -458,3 +491,320 @@ func TestGetBrokerLabels(t *testing.T) {
 		t.Error("Expected:", expected, "Got:", result)
 	}
 }
+
+func TestMaintenanceWindowIsOpen(t *testing.T) {
+	testCases := []struct {
+		testName string
+		window   MaintenanceWindow
+		time     time.Time
+		expected bool
+	}{
+		{
+			testName: "daily window open",
+			window:   MaintenanceWindow{Start: "02:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			time:     time.Date(2022, time.March, 30, 3, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			testName: "daily window closed",
+			window:   MaintenanceWindow{Start: "02:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			time:     time.Date(2022, time.March, 30, 4, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			testName: "window spanning over midnight",
+			window:   MaintenanceWindow{Days: []MaintenanceWindowDay{"Tuesday"}, Start: "23:00", Duration: metav1.Duration{Duration: 3 * time.Hour}},
+			time:     time.Date(2022, time.March, 30, 1, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			testName: "window on other day",
+			window:   MaintenanceWindow{Days: []MaintenanceWindowDay{"Saturday", "Sunday"}, Start: "00:00", Duration: metav1.Duration{Duration: 24 * time.Hour}},
+			time:     time.Date(2022, time.March, 30, 1, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			testName: "time in other timezone",
+			window:   MaintenanceWindow{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}},
+			time:     time.Date(2022, time.March, 30, 4, 30, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			expected: true,
+		},
+		{
+			testName: "invalid start",
+			window:   MaintenanceWindow{Start: "2am", Duration: metav1.Duration{Duration: time.Hour}},
+			time:     time.Date(2022, time.March, 30, 2, 30, 0, 0, time.UTC),
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		if got := test.window.IsOpen(test.time); got != test.expected {
+			t.Errorf("%s: expected: %v, got: %v", test.testName, test.expected, got)
+		}
+	}
+
+	spec := KafkaClusterSpec{}
+	if !spec.InMaintenanceWindow(time.Now()) {
+		t.Error("Expected maintenance window to be open when no windows are specified")
+	}
+}
+
+func TestListenerStatusListAddresses(t *testing.T) {
+	testCases := []struct {
+		testName          string
+		statusList        ListenerStatusList
+		expectedBootstrap []string
+	}{
+		{
+			testName: "all broker service",
+			statusList: ListenerStatusList{
+				{Name: "any-broker", Address: "kafka-all-broker.kafka.svc.cluster.local:29092"},
+				{Name: "broker-0", Address: "kafka-0.kafka.svc.cluster.local:29092"},
+			},
+			expectedBootstrap: []string{"kafka-all-broker.kafka.svc.cluster.local:29092"},
+		},
+		{
+			testName: "node port listener",
+			statusList: ListenerStatusList{
+				{Name: "broker-1", Address: "10.0.0.2:30001"},
+				{Name: "broker-0", Address: "10.0.0.1:30000"},
+			},
+			expectedBootstrap: []string{"10.0.0.1:30000", "10.0.0.2:30001"},
+		},
+	}
+
+	for _, test := range testCases {
+		if bootstrap := test.statusList.BootstrapAddresses(); !reflect.DeepEqual(bootstrap, test.expectedBootstrap) {
+			t.Errorf("%s: expected bootstrap addresses: %v, got: %v", test.testName, test.expectedBootstrap, bootstrap)
+		}
+		if address, ok := test.statusList.BrokerAddress(0); !ok || address != test.statusList[len(test.statusList)-1].Address {
+			t.Errorf("%s: unexpected address of broker 0: %q", test.testName, address)
+		}
+		if _, ok := test.statusList.BrokerAddress(2); ok {
+			t.Errorf("%s: expected no address for broker 2", test.testName)
+		}
+	}
+}
+
+func TestIstioControlPlaneReferenceGetNamespacedRevision(t *testing.T) {
+	testCases := []struct {
+		testName         string
+		reference        IstioControlPlaneReference
+		expectedRevision string
+	}{
+		{
+			testName:         "revision of the control plane name",
+			reference:        IstioControlPlaneReference{Name: "icp-v1.13", Namespace: "istio-system"},
+			expectedRevision: "icp-v1-13.istio-system",
+		},
+		{
+			testName:         "explicit revision",
+			reference:        IstioControlPlaneReference{Name: "icp-v1.13", Namespace: "istio-system", Revision: "canary"},
+			expectedRevision: "canary.istio-system",
+		},
+	}
+
+	for _, test := range testCases {
+		if revision := test.reference.GetNamespacedRevision(); revision != test.expectedRevision {
+			t.Errorf("%s: expected revision: %q, got: %q", test.testName, test.expectedRevision, revision)
+		}
+	}
+}
+
+func TestEnvoyConfigAutoscaling(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	testCases := []struct {
+		testName            string
+		envoyConfig         EnvoyConfig
+		expectedMinReplicas int32
+		expectedCPUTarget   *int32
+	}{
+		{
+			testName:            "defaults",
+			envoyConfig:         EnvoyConfig{Replicas: 2, Autoscaling: &EnvoyAutoscaling{MaxReplicas: 5}},
+			expectedMinReplicas: 2,
+			expectedCPUTarget:   int32Ptr(DefaultEnvoyTargetCPUUtilizationPercentage),
+		},
+		{
+			testName:            "replicas above the maximum",
+			envoyConfig:         EnvoyConfig{Replicas: 6, Autoscaling: &EnvoyAutoscaling{MaxReplicas: 5}},
+			expectedMinReplicas: 5,
+			expectedCPUTarget:   int32Ptr(DefaultEnvoyTargetCPUUtilizationPercentage),
+		},
+		{
+			testName: "connection based scaling only",
+			envoyConfig: EnvoyConfig{Autoscaling: &EnvoyAutoscaling{
+				MinReplicas:             int32Ptr(3),
+				MaxReplicas:             5,
+				TargetConnectionsPerPod: int32Ptr(1000),
+			}},
+			expectedMinReplicas: 3,
+		},
+	}
+
+	for _, test := range testCases {
+		if !test.envoyConfig.IsAutoscalingEnabled() {
+			t.Errorf("%s: expected autoscaling to be enabled", test.testName)
+		}
+		if minReplicas := test.envoyConfig.GetMinReplicas(); minReplicas != test.expectedMinReplicas {
+			t.Errorf("%s: expected min replicas: %d, got: %d", test.testName, test.expectedMinReplicas, minReplicas)
+		}
+		target := test.envoyConfig.Autoscaling.GetTargetCPUUtilizationPercentage()
+		if (target == nil) != (test.expectedCPUTarget == nil) || (target != nil && *target != *test.expectedCPUTarget) {
+			t.Errorf("%s: expected CPU utilization target: %v, got: %v", test.testName, test.expectedCPUTarget, target)
+		}
+	}
+}
+
+func TestGoalViolationRemediation(t *testing.T) {
+	if cooldown := (RemediatedGoal{Name: "CpuCapacityGoal"}).GetCooldown(); cooldown != DefaultGoalViolationRemediationCooldown {
+		t.Errorf("expected default cooldown %s, got: %s", DefaultGoalViolationRemediationCooldown, cooldown)
+	}
+	goal := RemediatedGoal{Name: "CpuCapacityGoal", Cooldown: &metav1.Duration{Duration: 10 * time.Minute}}
+	if cooldown := goal.GetCooldown(); cooldown != 10*time.Minute {
+		t.Errorf("expected cooldown 10m, got: %s", cooldown)
+	}
+
+	var status *GoalViolationRemediationStatus
+	if status.GetGoal("CpuCapacityGoal") != nil {
+		t.Error("expected no remediation in nil status")
+	}
+	status = &GoalViolationRemediationStatus{Goals: []RemediatedGoalStatus{{Name: "CpuCapacityGoal", AnomalyID: "a1"}}}
+	if last := status.GetGoal("CpuCapacityGoal"); last == nil || last.AnomalyID != "a1" {
+		t.Errorf("expected remediation of anomaly a1, got: %+v", last)
+	}
+	if status.GetGoal("DiskUsageDistributionGoal") != nil {
+		t.Error("expected no remediation of DiskUsageDistributionGoal")
+	}
+}
+
+func TestCruiseControlOperationRetryPolicy(t *testing.T) {
+	var policy *CruiseControlOperationRetryPolicy
+	if policy.GetErrorPolicy() != CruiseControlErrorPolicyIgnore || policy.GetMaxAttempts() != 1 {
+		t.Error("expected the failed operations to be ignored without a retry policy")
+	}
+	if backoff := policy.GetBackoff(1); backoff != DefaultOperationRetryBackoff {
+		t.Errorf("expected default backoff %s, got: %s", DefaultOperationRetryBackoff, backoff)
+	}
+
+	policy = &CruiseControlOperationRetryPolicy{}
+	if policy.GetErrorPolicy() != CruiseControlErrorPolicyRetry || policy.GetMaxAttempts() != DefaultOperationRetryMaxAttempts {
+		t.Errorf("expected the failed operations to be retried %d times by default", DefaultOperationRetryMaxAttempts)
+	}
+	policy = &CruiseControlOperationRetryPolicy{ErrorPolicy: CruiseControlErrorPolicyBlock, MaxAttempts: 5}
+	if policy.GetMaxAttempts() != 1 {
+		t.Errorf("expected a single attempt with the block policy, got: %d", policy.GetMaxAttempts())
+	}
+
+	policy = &CruiseControlOperationRetryPolicy{Backoff: &metav1.Duration{Duration: 20 * time.Minute}}
+	for attempts, expected := range map[int32]time.Duration{1: 20 * time.Minute, 2: 40 * time.Minute, 3: time.Hour, 10: time.Hour} {
+		if backoff := policy.GetBackoff(attempts); backoff != expected {
+			t.Errorf("expected backoff %s after %d attempts, got: %s", expected, attempts, backoff)
+		}
+	}
+}
+
+func TestBrokersInMaintenance(t *testing.T) {
+	spec := KafkaClusterSpec{
+		Brokers: []Broker{{Id: 0}, {Id: 1, Maintenance: true}, {Id: 2}, {Id: 3, Maintenance: true}},
+	}
+	if brokerIDs := spec.GetBrokerIDsInMaintenance(); !reflect.DeepEqual(brokerIDs, []string{"1", "3"}) {
+		t.Errorf("expected brokers 1 and 3 in maintenance, got: %v", brokerIDs)
+	}
+	if !spec.IsBrokerInMaintenance("3") || spec.IsBrokerInMaintenance("0") || spec.IsBrokerInMaintenance("4") {
+		t.Error("expected only brokers 1 and 3 to be in maintenance")
+	}
+}
+
+func TestBrokerRollingUpgradeConfig(t *testing.T) {
+	threshold := 3
+	spec := KafkaClusterSpec{
+		RollingUpgradeConfig: RollingUpgradeConfig{FailureThreshold: 1},
+		BrokerConfigGroups: map[string]BrokerConfig{
+			"hdd": {},
+			"ssd": {RollingUpgrade: &BrokerRollingUpgradeConfig{FailureThreshold: &threshold, Paused: true}},
+		},
+	}
+
+	hdd, err := (&Broker{Id: 0, BrokerConfigGroup: "hdd"}).GetBrokerConfig(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdd.IsRollingUpgradePaused() || hdd.GetRollingUpgradeFailureThreshold(spec.RollingUpgradeConfig) != 1 {
+		t.Error("expected the cluster-wide rolling upgrade policy for the hdd group")
+	}
+	ssd, err := (&Broker{Id: 1, BrokerConfigGroup: "ssd"}).GetBrokerConfig(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ssd.IsRollingUpgradePaused() || ssd.GetRollingUpgradeFailureThreshold(spec.RollingUpgradeConfig) != 3 {
+		t.Error("expected the rolling upgrade policy of the ssd group")
+	}
+	var noConfig *BrokerConfig
+	if noConfig.IsRollingUpgradePaused() || noConfig.GetRollingUpgradeFailureThreshold(spec.RollingUpgradeConfig) != 1 {
+		t.Error("expected the cluster-wide rolling upgrade policy without broker config")
+	}
+}
+
+func TestTenancyConfigGetNamespace(t *testing.T) {
+	var tenancy *TenancyConfig
+	if tenancy.GetNamespace("team-a") != nil {
+		t.Error("expected no tenant namespace in nil tenancy")
+	}
+	tenancy = &TenancyConfig{Namespaces: []TenantNamespace{{Name: "team-a", TopicNamePrefix: "team-a."}, {Name: "team-b"}}}
+	if tenant := tenancy.GetNamespace("team-a"); tenant == nil || tenant.TopicNamePrefix != "team-a." {
+		t.Errorf("expected tenant namespace team-a, got: %+v", tenant)
+	}
+	if tenancy.GetNamespace("team-c") != nil {
+		t.Error("expected no tenant namespace team-c")
+	}
+}
+
+func TestCruiseControlSelfHealingAnomalyTypes(t *testing.T) {
+	enabled, disabled := (&CruiseControlSelfHealing{}).AnomalyTypes()
+	if len(enabled) != 0 || len(disabled) != 0 {
+		t.Errorf("expected no anomaly types, got enabled: %v, disabled: %v", enabled, disabled)
+	}
+
+	on, off := true, false
+	enabled, disabled = (&CruiseControlSelfHealing{BrokerFailure: &on, DiskFailure: &off, TopicAnomaly: &on}).AnomalyTypes()
+	if !reflect.DeepEqual(enabled, []string{"BROKER_FAILURE", "TOPIC_ANOMALY"}) {
+		t.Errorf("unexpected enabled anomaly types: %v", enabled)
+	}
+	if !reflect.DeepEqual(disabled, []string{"DISK_FAILURE"}) {
+		t.Errorf("unexpected disabled anomaly types: %v", disabled)
+	}
+}
+
+func TestRecordOperation(t *testing.T) {
+	status := &KafkaClusterStatus{}
+	status.RecordOperation(OperationRecord{Type: OperationTypeRollingUpgrade, TriggeredBy: "image change of broker 0",
+		StartedAt: "2022-04-01 10:00:00", Outcome: OperationRunning}, 2)
+	status.RecordOperation(OperationRecord{Type: OperationTypeRollingUpgrade, FinishedAt: "2022-04-01 10:30:00",
+		Outcome: OperationSucceeded}, 2)
+
+	expected := []OperationRecord{{Type: OperationTypeRollingUpgrade, TriggeredBy: "image change of broker 0",
+		StartedAt: "2022-04-01 10:00:00", FinishedAt: "2022-04-01 10:30:00", Outcome: OperationSucceeded}}
+	if !reflect.DeepEqual(status.OperationHistory, expected) {
+		t.Fatalf("expected finished rolling upgrade, got: %+v", status.OperationHistory)
+	}
+
+	// the end of a rolling upgrade whose start was not recorded is skipped
+	status.RecordOperation(OperationRecord{Type: OperationTypeRollingUpgrade, Outcome: OperationSucceeded}, 2)
+	if len(status.OperationHistory) != 1 {
+		t.Fatalf("expected a single operation, got: %+v", status.OperationHistory)
+	}
+
+	status.RecordOperation(OperationRecord{Type: OperationTypeAddBroker, TaskID: "t1", Outcome: OperationSucceeded}, 2)
+	status.RecordOperation(OperationRecord{Type: OperationTypeRemoveBroker, TaskID: "t2", Outcome: OperationRunning}, 2)
+	if len(status.OperationHistory) != 2 || status.OperationHistory[0].TaskID != "t1" {
+		t.Fatalf("expected the oldest operation to be dropped, got: %+v", status.OperationHistory)
+	}
+	if record := status.GetOperation(OperationTypeRemoveBroker, "t2"); record == nil || record.Outcome != OperationRunning {
+		t.Errorf("expected running remove broker task t2, got: %+v", record)
+	}
+	if status.GetOperation(OperationTypeAddBroker, "t2") != nil {
+		t.Error("expected no add broker task t2")
+	}
+}
@@ -23,9 +23,45 @@ import (
 	networkingv1beta1 "github.com/banzaicloud/istio-client-go/pkg/networking/v1beta1"
 	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"k8s.io/api/core/v1"
+	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptionConfig) DeepCopyInto(out *AdoptionConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptionConfig.
+func (in *AdoptionConfig) DeepCopy() *AdoptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptionStatus) DeepCopyInto(out *AdoptionStatus) {
+	*out = *in
+	if in.HeldBackChanges != nil {
+		in, out := &in.HeldBackChanges, &out.HeldBackChanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptionStatus.
+func (in *AdoptionStatus) DeepCopy() *AdoptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AlertManagerConfig) DeepCopyInto(out *AlertManagerConfig) {
 	*out = *in
@@ -61,6 +97,66 @@ func (in *Broker) DeepCopy() *Broker {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerAntiAffinityConfig) DeepCopyInto(out *BrokerAntiAffinityConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerAntiAffinityConfig.
+func (in *BrokerAntiAffinityConfig) DeepCopy() *BrokerAntiAffinityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerAntiAffinityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerAutoscalingConfig) DeepCopyInto(out *BrokerAutoscalingConfig) {
+	*out = *in
+	if in.Cooldown != nil {
+		in, out := &in.Cooldown, &out.Cooldown
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerAutoscalingConfig.
+func (in *BrokerAutoscalingConfig) DeepCopy() *BrokerAutoscalingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerAutoscalingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerAutoscalingStatus) DeepCopyInto(out *BrokerAutoscalingStatus) {
+	*out = *in
+	if in.AddedBrokers != nil {
+		in, out := &in.AddedBrokers, &out.AddedBrokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemovedBrokers != nil {
+		in, out := &in.RemovedBrokers, &out.RemovedBrokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerAutoscalingStatus.
+func (in *BrokerAutoscalingStatus) DeepCopy() *BrokerAutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerAutoscalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokerConfig) DeepCopyInto(out *BrokerConfig) {
 	*out = *in
@@ -176,11 +272,41 @@ func (in *BrokerConfig) DeepCopyInto(out *BrokerConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]NodeArchitecture, len(*in))
+		copy(*out, *in)
+	}
 	if in.TerminationGracePeriod != nil {
 		in, out := &in.TerminationGracePeriod, &out.TerminationGracePeriod
 		*out = new(int64)
 		**out = **in
 	}
+	if in.EphemeralStorage != nil {
+		in, out := &in.EphemeralStorage, &out.EphemeralStorage
+		*out = new(EphemeralStorageConfig)
+		**out = **in
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(BrokerLifecycleConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProcessRoles != nil {
+		in, out := &in.ProcessRoles, &out.ProcessRoles
+		*out = make([]ProcessRole, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceRecommendation != nil {
+		in, out := &in.ResourceRecommendation, &out.ResourceRecommendation
+		*out = new(ResourceRecommendationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RollingUpgrade != nil {
+		in, out := &in.RollingUpgrade, &out.RollingUpgrade
+		*out = new(BrokerRollingUpgradeConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerConfig.
@@ -194,236 +320,437 @@ func (in *BrokerConfig) DeepCopy() *BrokerConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BrokerState) DeepCopyInto(out *BrokerState) {
+func (in *BrokerConfigValidationConfig) DeepCopyInto(out *BrokerConfigValidationConfig) {
 	*out = *in
-	in.GracefulActionState.DeepCopyInto(&out.GracefulActionState)
-	if in.ExternalListenerConfigNames != nil {
-		in, out := &in.ExternalListenerConfigNames, &out.ExternalListenerConfigNames
-		*out = make(ExternalListenerConfigNames, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerConfigValidationConfig.
+func (in *BrokerConfigValidationConfig) DeepCopy() *BrokerConfigValidationConfig {
+	if in == nil {
+		return nil
 	}
+	out := new(BrokerConfigValidationConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerState.
-func (in *BrokerState) DeepCopy() *BrokerState {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerConfigValidationIssue) DeepCopyInto(out *BrokerConfigValidationIssue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerConfigValidationIssue.
+func (in *BrokerConfigValidationIssue) DeepCopy() *BrokerConfigValidationIssue {
 	if in == nil {
 		return nil
 	}
-	out := new(BrokerState)
+	out := new(BrokerConfigValidationIssue)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CommonListenerSpec) DeepCopyInto(out *CommonListenerSpec) {
+func (in *BrokerConfigValidationStatus) DeepCopyInto(out *BrokerConfigValidationStatus) {
 	*out = *in
-	if in.ServerSSLCertSecret != nil {
-		in, out := &in.ServerSSLCertSecret, &out.ServerSSLCertSecret
-		*out = new(v1.LocalObjectReference)
-		**out = **in
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]BrokerConfigValidationIssue, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonListenerSpec.
-func (in *CommonListenerSpec) DeepCopy() *CommonListenerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerConfigValidationStatus.
+func (in *BrokerConfigValidationStatus) DeepCopy() *BrokerConfigValidationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CommonListenerSpec)
+	out := new(BrokerConfigValidationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Config) DeepCopyInto(out *Config) {
+func (in *BrokerDecommissionConfig) DeepCopyInto(out *BrokerDecommissionConfig) {
 	*out = *in
-	if in.IngressConfig != nil {
-		in, out := &in.IngressConfig, &out.IngressConfig
-		*out = make(map[string]IngressConfig, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.DataRetentionPeriod != nil {
+		in, out := &in.DataRetentionPeriod, &out.DataRetentionPeriod
+		*out = new(apismetav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Config.
-func (in *Config) DeepCopy() *Config {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerDecommissionConfig.
+func (in *BrokerDecommissionConfig) DeepCopy() *BrokerDecommissionConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(Config)
+	out := new(BrokerDecommissionConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CruiseControlConfig) DeepCopyInto(out *CruiseControlConfig) {
+func (in *BrokerLifecycleConfig) DeepCopyInto(out *BrokerLifecycleConfig) {
 	*out = *in
-	out.CruiseControlTaskSpec = in.CruiseControlTaskSpec
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+	if in.PreStopCommand != nil {
+		in, out := &in.PreStopCommand, &out.PreStopCommand
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.PostStartCommand != nil {
+		in, out := &in.PostStartCommand, &out.PostStartCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.TopicConfig != nil {
-		in, out := &in.TopicConfig, &out.TopicConfig
-		*out = new(TopicConfig)
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(StartupProbeConfig)
 		**out = **in
 	}
-	if in.CruiseControlAnnotations != nil {
-		in, out := &in.CruiseControlAnnotations, &out.CruiseControlAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.InitContainers != nil {
-		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]v1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerLifecycleConfig.
+func (in *BrokerLifecycleConfig) DeepCopy() *BrokerLifecycleConfig {
+	if in == nil {
+		return nil
 	}
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out := new(BrokerLifecycleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerLoadStatus) DeepCopyInto(out *BrokerLoadStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerLoadStatus.
+func (in *BrokerLoadStatus) DeepCopy() *BrokerLoadStatus {
+	if in == nil {
+		return nil
 	}
-	if in.PodSecurityContext != nil {
-		in, out := &in.PodSecurityContext, &out.PodSecurityContext
-		*out = new(v1.PodSecurityContext)
-		(*in).DeepCopyInto(*out)
+	out := new(BrokerLoadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerRequestLatency) DeepCopyInto(out *BrokerRequestLatency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerRequestLatency.
+func (in *BrokerRequestLatency) DeepCopy() *BrokerRequestLatency {
+	if in == nil {
+		return nil
 	}
-	if in.SecurityContext != nil {
-		in, out := &in.SecurityContext, &out.SecurityContext
-		*out = new(v1.SecurityContext)
-		(*in).DeepCopyInto(*out)
+	out := new(BrokerRequestLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerRollingUpgradeConfig) DeepCopyInto(out *BrokerRollingUpgradeConfig) {
+	*out = *in
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlConfig.
-func (in *CruiseControlConfig) DeepCopy() *CruiseControlConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerRollingUpgradeConfig.
+func (in *BrokerRollingUpgradeConfig) DeepCopy() *BrokerRollingUpgradeConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(CruiseControlConfig)
+	out := new(BrokerRollingUpgradeConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CruiseControlTaskSpec) DeepCopyInto(out *CruiseControlTaskSpec) {
+func (in *BrokerState) DeepCopyInto(out *BrokerState) {
 	*out = *in
+	in.GracefulActionState.DeepCopyInto(&out.GracefulActionState)
+	if in.ExternalListenerConfigNames != nil {
+		in, out := &in.ExternalListenerConfigNames, &out.ExternalListenerConfigNames
+		*out = make(ExternalListenerConfigNames, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskSpec.
-func (in *CruiseControlTaskSpec) DeepCopy() *CruiseControlTaskSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerState.
+func (in *BrokerState) DeepCopy() *BrokerState {
 	if in == nil {
 		return nil
 	}
-	out := new(CruiseControlTaskSpec)
+	out := new(BrokerState)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DisruptionBudget) DeepCopyInto(out *DisruptionBudget) {
+func (in *CapacityHeadroomConfig) DeepCopyInto(out *CapacityHeadroomConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudget.
-func (in *DisruptionBudget) DeepCopy() *DisruptionBudget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityHeadroomConfig.
+func (in *CapacityHeadroomConfig) DeepCopy() *CapacityHeadroomConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(DisruptionBudget)
+	out := new(CapacityHeadroomConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DisruptionBudgetWithStrategy) DeepCopyInto(out *DisruptionBudgetWithStrategy) {
+func (in *CapacityHeadroomStatus) DeepCopyInto(out *CapacityHeadroomStatus) {
 	*out = *in
-	out.DisruptionBudget = in.DisruptionBudget
+	if in.BrokersAboveThreshold != nil {
+		in, out := &in.BrokersAboveThreshold, &out.BrokersAboveThreshold
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudgetWithStrategy.
-func (in *DisruptionBudgetWithStrategy) DeepCopy() *DisruptionBudgetWithStrategy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityHeadroomStatus.
+func (in *CapacityHeadroomStatus) DeepCopy() *CapacityHeadroomStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DisruptionBudgetWithStrategy)
+	out := new(CapacityHeadroomStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnvoyCommandLineArgs) DeepCopyInto(out *EnvoyCommandLineArgs) {
+func (in *CertificateRevocationConfig) DeepCopyInto(out *CertificateRevocationConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyCommandLineArgs.
-func (in *EnvoyCommandLineArgs) DeepCopy() *EnvoyCommandLineArgs {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRevocationConfig.
+func (in *CertificateRevocationConfig) DeepCopy() *CertificateRevocationConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(EnvoyCommandLineArgs)
+	out := new(CertificateRevocationConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
+func (in *CommonListenerSpec) DeepCopyInto(out *CommonListenerSpec) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
+	if in.ServerSSLCertSecret != nil {
+		in, out := &in.ServerSSLCertSecret, &out.ServerSSLCertSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
 	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+	if in.SASLPlainCredentialsSecret != nil {
+		in, out := &in.SASLPlainCredentialsSecret, &out.SASLPlainCredentialsSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonListenerSpec.
+func (in *CommonListenerSpec) DeepCopy() *CommonListenerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CommonListenerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Config) DeepCopyInto(out *Config) {
+	*out = *in
+	if in.IngressConfig != nil {
+		in, out := &in.IngressConfig, &out.IngressConfig
+		*out = make(map[string]IngressConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Config.
+func (in *Config) DeepCopy() *Config {
+	if in == nil {
+		return nil
+	}
+	out := new(Config)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionInfoConfig) DeepCopyInto(out *ConnectionInfoConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionInfoConfig.
+func (in *ConnectionInfoConfig) DeepCopy() *ConnectionInfoConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionInfoConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerHealthConfig) DeepCopyInto(out *ControllerHealthConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerHealthConfig.
+func (in *ControllerHealthConfig) DeepCopy() *ControllerHealthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerHealthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerHealthStatus) DeepCopyInto(out *ControllerHealthStatus) {
+	*out = *in
+	if in.Quorum != nil {
+		in, out := &in.Quorum, &out.Quorum
+		*out = new(QuorumStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerHealthStatus.
+func (in *ControllerHealthStatus) DeepCopy() *ControllerHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAnomalyNotifier) DeepCopyInto(out *CruiseControlAnomalyNotifier) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAnomalyNotifier.
+func (in *CruiseControlAnomalyNotifier) DeepCopy() *CruiseControlAnomalyNotifier {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAnomalyNotifier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAuthentication) DeepCopyInto(out *CruiseControlAuthentication) {
+	*out = *in
+	if in.BearerTokenSecretRef != nil {
+		in, out := &in.BearerTokenSecretRef, &out.BearerTokenSecretRef
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.TopologySpreadConstraints != nil {
-		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.TokenExchange != nil {
+		in, out := &in.TokenExchange, &out.TokenExchange
+		*out = new(CruiseControlTokenExchange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(CruiseControlBasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAuthentication.
+func (in *CruiseControlAuthentication) DeepCopy() *CruiseControlAuthentication {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAuthentication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlBasicAuth) DeepCopyInto(out *CruiseControlBasicAuth) {
+	*out = *in
+	in.PasswordSecretRef.DeepCopyInto(&out.PasswordSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlBasicAuth.
+func (in *CruiseControlBasicAuth) DeepCopy() *CruiseControlBasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlBasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlClientProxy) DeepCopyInto(out *CruiseControlClientProxy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlClientProxy.
+func (in *CruiseControlClientProxy) DeepCopy() *CruiseControlClientProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlClientProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlClientTLS) DeepCopyInto(out *CruiseControlClientTLS) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlClientTLS.
+func (in *CruiseControlClientTLS) DeepCopy() *CruiseControlClientTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlClientTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlConfig) DeepCopyInto(out *CruiseControlConfig) {
+	*out = *in
+	out.CruiseControlTaskSpec = in.CruiseControlTaskSpec
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
 	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
@@ -439,149 +766,1630 @@ func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
+	if in.TopicConfig != nil {
+		in, out := &in.TopicConfig, &out.TopicConfig
+		*out = new(TopicConfig)
+		**out = **in
+	}
+	if in.CruiseControlAnnotations != nil {
+		in, out := &in.CruiseControlAnnotations, &out.CruiseControlAnnotations
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.LoadBalancerSourceRanges != nil {
-		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
-		*out = make([]string, len(*in))
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]NodeArchitecture, len(*in))
 		copy(*out, *in)
 	}
-	if in.AdminPort != nil {
-		in, out := &in.AdminPort, &out.AdminPort
-		*out = new(int32)
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(CruiseControlAuthentication)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientTLS != nil {
+		in, out := &in.ClientTLS, &out.ClientTLS
+		*out = new(CruiseControlClientTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(CruiseControlClientProxy)
 		**out = **in
 	}
-	if in.HealthCheckPort != nil {
-		in, out := &in.HealthCheckPort, &out.HealthCheckPort
-		*out = new(int32)
+	if in.NodeNetworkCapacity != nil {
+		in, out := &in.NodeNetworkCapacity, &out.NodeNetworkCapacity
+		*out = new(NodeNetworkCapacityConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GoalViolationRemediation != nil {
+		in, out := &in.GoalViolationRemediation, &out.GoalViolationRemediation
+		*out = new(GoalViolationRemediation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OperationGoals != nil {
+		in, out := &in.OperationGoals, &out.OperationGoals
+		*out = new(CruiseControlOperationGoals)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicationThrottle != nil {
+		in, out := &in.ReplicationThrottle, &out.ReplicationThrottle
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ExecutorConcurrency != nil {
+		in, out := &in.ExecutorConcurrency, &out.ExecutorConcurrency
+		*out = new(CruiseControlExecutorConcurrency)
+		**out = **in
+	}
+	if in.Rightsize != nil {
+		in, out := &in.Rightsize, &out.Rightsize
+		*out = new(CruiseControlRightsize)
+		**out = **in
+	}
+	if in.TopicRebalance != nil {
+		in, out := &in.TopicRebalance, &out.TopicRebalance
+		*out = new(CruiseControlTopicRebalance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OperationRetryPolicy != nil {
+		in, out := &in.OperationRetryPolicy, &out.OperationRetryPolicy
+		*out = new(CruiseControlOperationRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskSkewRebalance != nil {
+		in, out := &in.DiskSkewRebalance, &out.DiskSkewRebalance
+		*out = new(DiskSkewRebalanceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AnomalyNotifier != nil {
+		in, out := &in.AnomalyNotifier, &out.AnomalyNotifier
+		*out = new(CruiseControlAnomalyNotifier)
 		**out = **in
 	}
-	if in.DisruptionBudget != nil {
-		in, out := &in.DisruptionBudget, &out.DisruptionBudget
-		*out = new(DisruptionBudgetWithStrategy)
+	if in.SelfHealing != nil {
+		in, out := &in.SelfHealing, &out.SelfHealing
+		*out = new(CruiseControlSelfHealing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(BrokerAutoscalingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlConfig.
+func (in *CruiseControlConfig) DeepCopy() *CruiseControlConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlExecutorConcurrency) DeepCopyInto(out *CruiseControlExecutorConcurrency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlExecutorConcurrency.
+func (in *CruiseControlExecutorConcurrency) DeepCopy() *CruiseControlExecutorConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlExecutorConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlLoadStatus) DeepCopyInto(out *CruiseControlLoadStatus) {
+	*out = *in
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]BrokerLoadStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlLoadStatus.
+func (in *CruiseControlLoadStatus) DeepCopy() *CruiseControlLoadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlLoadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationGoals) DeepCopyInto(out *CruiseControlOperationGoals) {
+	*out = *in
+	if in.AddBrokers != nil {
+		in, out := &in.AddBrokers, &out.AddBrokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoveBrokers != nil {
+		in, out := &in.RemoveBrokers, &out.RemoveBrokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RebalanceDisks != nil {
+		in, out := &in.RebalanceDisks, &out.RebalanceDisks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationGoals.
+func (in *CruiseControlOperationGoals) DeepCopy() *CruiseControlOperationGoals {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationGoals)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationRetryPolicy) DeepCopyInto(out *CruiseControlOperationRetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationRetryPolicy.
+func (in *CruiseControlOperationRetryPolicy) DeepCopy() *CruiseControlOperationRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationSummary) DeepCopyInto(out *CruiseControlOperationSummary) {
+	*out = *in
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationSummary.
+func (in *CruiseControlOperationSummary) DeepCopy() *CruiseControlOperationSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationsStatus) DeepCopyInto(out *CruiseControlOperationsStatus) {
+	*out = *in
+	if in.InFlight != nil {
+		in, out := &in.InFlight, &out.InFlight
+		*out = make([]CruiseControlOperationSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RecentlyFinished != nil {
+		in, out := &in.RecentlyFinished, &out.RecentlyFinished
+		*out = make([]CruiseControlOperationSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationsStatus.
+func (in *CruiseControlOperationsStatus) DeepCopy() *CruiseControlOperationsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlRightsize) DeepCopyInto(out *CruiseControlRightsize) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlRightsize.
+func (in *CruiseControlRightsize) DeepCopy() *CruiseControlRightsize {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlRightsize)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlRightsizeStatus) DeepCopyInto(out *CruiseControlRightsizeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlRightsizeStatus.
+func (in *CruiseControlRightsizeStatus) DeepCopy() *CruiseControlRightsizeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlRightsizeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlSelfHealing) DeepCopyInto(out *CruiseControlSelfHealing) {
+	*out = *in
+	if in.BrokerFailure != nil {
+		in, out := &in.BrokerFailure, &out.BrokerFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GoalViolation != nil {
+		in, out := &in.GoalViolation, &out.GoalViolation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DiskFailure != nil {
+		in, out := &in.DiskFailure, &out.DiskFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TopicAnomaly != nil {
+		in, out := &in.TopicAnomaly, &out.TopicAnomaly
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlSelfHealing.
+func (in *CruiseControlSelfHealing) DeepCopy() *CruiseControlSelfHealing {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlSelfHealing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlTaskProgress) DeepCopyInto(out *CruiseControlTaskProgress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskProgress.
+func (in *CruiseControlTaskProgress) DeepCopy() *CruiseControlTaskProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTaskProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlTaskSpec) DeepCopyInto(out *CruiseControlTaskSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskSpec.
+func (in *CruiseControlTaskSpec) DeepCopy() *CruiseControlTaskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTaskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlTokenExchange) DeepCopyInto(out *CruiseControlTokenExchange) {
+	*out = *in
+	in.ClientSecretRef.DeepCopyInto(&out.ClientSecretRef)
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTokenExchange.
+func (in *CruiseControlTokenExchange) DeepCopy() *CruiseControlTokenExchange {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTokenExchange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlTopicRebalance) DeepCopyInto(out *CruiseControlTopicRebalance) {
+	*out = *in
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTopicRebalance.
+func (in *CruiseControlTopicRebalance) DeepCopy() *CruiseControlTopicRebalance {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTopicRebalance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecommissionedBroker) DeepCopyInto(out *DecommissionedBroker) {
+	*out = *in
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecommissionedBroker.
+func (in *DecommissionedBroker) DeepCopy() *DecommissionedBroker {
+	if in == nil {
+		return nil
+	}
+	out := new(DecommissionedBroker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevProfileConfig) DeepCopyInto(out *DevProfileConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevProfileConfig.
+func (in *DevProfileConfig) DeepCopy() *DevProfileConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DevProfileConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskSkewRebalanceConfig) DeepCopyInto(out *DiskSkewRebalanceConfig) {
+	*out = *in
+	if in.Cooldown != nil {
+		in, out := &in.Cooldown, &out.Cooldown
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskSkewRebalanceConfig.
+func (in *DiskSkewRebalanceConfig) DeepCopy() *DiskSkewRebalanceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskSkewRebalanceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskSkewRebalanceStatus) DeepCopyInto(out *DiskSkewRebalanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskSkewRebalanceStatus.
+func (in *DiskSkewRebalanceStatus) DeepCopy() *DiskSkewRebalanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskSkewRebalanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionBudget) DeepCopyInto(out *DisruptionBudget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudget.
+func (in *DisruptionBudget) DeepCopy() *DisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionBudgetWithStrategy) DeepCopyInto(out *DisruptionBudgetWithStrategy) {
+	*out = *in
+	out.DisruptionBudget = in.DisruptionBudget
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudgetWithStrategy.
+func (in *DisruptionBudgetWithStrategy) DeepCopy() *DisruptionBudgetWithStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionBudgetWithStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyAutoscaling) DeepCopyInto(out *EnvoyAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetConnectionsPerPod != nil {
+		in, out := &in.TargetConnectionsPerPod, &out.TargetConnectionsPerPod
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyAutoscaling.
+func (in *EnvoyAutoscaling) DeepCopy() *EnvoyAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyCommandLineArgs) DeepCopyInto(out *EnvoyCommandLineArgs) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyCommandLineArgs.
+func (in *EnvoyCommandLineArgs) DeepCopy() *EnvoyCommandLineArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyCommandLineArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdminPort != nil {
+		in, out := &in.AdminPort, &out.AdminPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HealthCheckPort != nil {
+		in, out := &in.HealthCheckPort, &out.HealthCheckPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(DisruptionBudgetWithStrategy)
+		**out = **in
+	}
+	if in.CommandLineArgs != nil {
+		in, out := &in.CommandLineArgs, &out.CommandLineArgs
+		*out = new(EnvoyCommandLineArgs)
+		**out = **in
+	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]NodeArchitecture, len(*in))
+		copy(*out, *in)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(EnvoyAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(EnvoyTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyConfig.
+func (in *EnvoyConfig) DeepCopy() *EnvoyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyTLSConfig) DeepCopyInto(out *EnvoyTLSConfig) {
+	*out = *in
+	if in.ServerNames != nil {
+		in, out := &in.ServerNames, &out.ServerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyTLSConfig.
+func (in *EnvoyTLSConfig) DeepCopy() *EnvoyTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralStorageConfig) DeepCopyInto(out *EphemeralStorageConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralStorageConfig.
+func (in *EphemeralStorageConfig) DeepCopy() *EphemeralStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalListenerConfig) DeepCopyInto(out *ExternalListenerConfig) {
+	*out = *in
+	in.CommonListenerSpec.DeepCopyInto(&out.CommonListenerSpec)
+	in.IngressServiceSettings.DeepCopyInto(&out.IngressServiceSettings)
+	if in.AnyCastPort != nil {
+		in, out := &in.AnyCastPort, &out.AnyCastPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(Config)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListenerConfig.
+func (in *ExternalListenerConfig) DeepCopy() *ExternalListenerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalListenerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ExternalListenerConfigNames) DeepCopyInto(out *ExternalListenerConfigNames) {
+	{
+		in := &in
+		*out = make(ExternalListenerConfigNames, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListenerConfigNames.
+func (in ExternalListenerConfigNames) DeepCopy() ExternalListenerConfigNames {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalListenerConfigNames)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoalViolationRemediation) DeepCopyInto(out *GoalViolationRemediation) {
+	*out = *in
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]RemediatedGoal, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoalViolationRemediation.
+func (in *GoalViolationRemediation) DeepCopy() *GoalViolationRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(GoalViolationRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoalViolationRemediationStatus) DeepCopyInto(out *GoalViolationRemediationStatus) {
+	*out = *in
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]RemediatedGoalStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoalViolationRemediationStatus.
+func (in *GoalViolationRemediationStatus) DeepCopy() *GoalViolationRemediationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GoalViolationRemediationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GracefulActionState) DeepCopyInto(out *GracefulActionState) {
+	*out = *in
+	if in.TaskProgress != nil {
+		in, out := &in.TaskProgress, &out.TaskProgress
+		*out = new(CruiseControlTaskProgress)
+		**out = **in
+	}
+	if in.VolumeStates != nil {
+		in, out := &in.VolumeStates, &out.VolumeStates
+		*out = make(map[string]VolumeState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaintenanceState != nil {
+		in, out := &in.MaintenanceState, &out.MaintenanceState
+		*out = new(MaintenanceState)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulActionState.
+func (in *GracefulActionState) DeepCopy() *GracefulActionState {
+	if in == nil {
+		return nil
+	}
+	out := new(GracefulActionState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
+	*out = *in
+	in.IngressServiceSettings.DeepCopyInto(&out.IngressServiceSettings)
+	if in.IstioIngressConfig != nil {
+		in, out := &in.IstioIngressConfig, &out.IstioIngressConfig
+		*out = new(IstioIngressConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnvoyConfig != nil {
+		in, out := &in.EnvoyConfig, &out.EnvoyConfig
+		*out = new(EnvoyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressConfig.
+func (in *IngressConfig) DeepCopy() *IngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressServiceSettings) DeepCopyInto(out *IngressServiceSettings) {
+	*out = *in
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressServiceSettings.
+func (in *IngressServiceSettings) DeepCopy() *IngressServiceSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressServiceSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalListenerConfig) DeepCopyInto(out *InternalListenerConfig) {
+	*out = *in
+	in.CommonListenerSpec.DeepCopyInto(&out.CommonListenerSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalListenerConfig.
+func (in *InternalListenerConfig) DeepCopy() *InternalListenerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalListenerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalTopicHealth) DeepCopyInto(out *InternalTopicHealth) {
+	*out = *in
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalTopicHealth.
+func (in *InternalTopicHealth) DeepCopy() *InternalTopicHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalTopicHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalTopicsConfig) DeepCopyInto(out *InternalTopicsConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalTopicsConfig.
+func (in *InternalTopicsConfig) DeepCopy() *InternalTopicsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalTopicsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalTopicsStatus) DeepCopyInto(out *InternalTopicsStatus) {
+	*out = *in
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]InternalTopicHealth, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalTopicsStatus.
+func (in *InternalTopicsStatus) DeepCopy() *InternalTopicsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalTopicsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioControlPlaneReference) DeepCopyInto(out *IstioControlPlaneReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioControlPlaneReference.
+func (in *IstioControlPlaneReference) DeepCopy() *IstioControlPlaneReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioControlPlaneReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioIngressConfig) DeepCopyInto(out *IstioIngressConfig) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLSOptions != nil {
+		in, out := &in.TLSOptions, &out.TLSOptions
+		*out = new(networkingv1beta1.TLSOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VirtualServiceAnnotations != nil {
+		in, out := &in.VirtualServiceAnnotations, &out.VirtualServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioIngressConfig.
+func (in *IstioIngressConfig) DeepCopy() *IstioIngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioIngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KRaftStatus) DeepCopyInto(out *KRaftStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KRaftStatus.
+func (in *KRaftStatus) DeepCopy() *KRaftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KRaftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaCluster) DeepCopyInto(out *KafkaCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCluster.
+func (in *KafkaCluster) DeepCopy() *KafkaCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClusterList) DeepCopyInto(out *KafkaClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterList.
+func (in *KafkaClusterList) DeepCopy() *KafkaClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClusterSpec) DeepCopyInto(out *KafkaClusterSpec) {
+	*out = *in
+	in.ListenersConfig.DeepCopyInto(&out.ListenersConfig)
+	if in.ZKAddresses != nil {
+		in, out := &in.ZKAddresses, &out.ZKAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RackAwareness != nil {
+		in, out := &in.RackAwareness, &out.RackAwareness
+		*out = new(RackAwareness)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BrokerConfigGroups != nil {
+		in, out := &in.BrokerConfigGroups, &out.BrokerConfigGroups
+		*out = make(map[string]BrokerConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]Broker, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.DisruptionBudget = in.DisruptionBudget
+	in.RollingUpgradeConfig.DeepCopyInto(&out.RollingUpgradeConfig)
+	if in.BrokerGroupReplicas != nil {
+		in, out := &in.BrokerGroupReplicas, &out.BrokerGroupReplicas
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.PreflightChecks = in.PreflightChecks
+	out.SmokeTest = in.SmokeTest
+	out.BrokerConfigValidation = in.BrokerConfigValidation
+	out.ConnectionInfo = in.ConnectionInfo
+	out.DevProfile = in.DevProfile
+	if in.IstioControlPlane != nil {
+		in, out := &in.IstioControlPlane, &out.IstioControlPlane
+		*out = new(IstioControlPlaneReference)
+		**out = **in
+	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]NodeArchitecture, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokerAntiAffinity != nil {
+		in, out := &in.BrokerAntiAffinity, &out.BrokerAntiAffinity
+		*out = new(BrokerAntiAffinityConfig)
+		**out = **in
+	}
+	in.CruiseControlConfig.DeepCopyInto(&out.CruiseControlConfig)
+	in.EnvoyConfig.DeepCopyInto(&out.EnvoyConfig)
+	out.MonitoringConfig = in.MonitoringConfig
+	if in.AlertManagerConfig != nil {
+		in, out := &in.AlertManagerConfig, &out.AlertManagerConfig
+		*out = new(AlertManagerConfig)
+		**out = **in
+	}
+	in.IstioIngressConfig.DeepCopyInto(&out.IstioIngressConfig)
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClientSSLCertSecret != nil {
+		in, out := &in.ClientSSLCertSecret, &out.ClientSSLCertSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Tenancy != nil {
+		in, out := &in.Tenancy, &out.Tenancy
+		*out = new(TenancyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(AdoptionConfig)
+		**out = **in
+	}
+	if in.OperationHistoryLimit != nil {
+		in, out := &in.OperationHistoryLimit, &out.OperationHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OperationRetention != nil {
+		in, out := &in.OperationRetention, &out.OperationRetention
+		*out = new(OperationRetentionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CapacityHeadroom != nil {
+		in, out := &in.CapacityHeadroom, &out.CapacityHeadroom
+		*out = new(CapacityHeadroomConfig)
+		**out = **in
+	}
+	if in.InternalTopics != nil {
+		in, out := &in.InternalTopics, &out.InternalTopics
+		*out = new(InternalTopicsConfig)
+		**out = **in
+	}
+	if in.RequestLatency != nil {
+		in, out := &in.RequestLatency, &out.RequestLatency
+		*out = new(RequestLatencyConfig)
+		**out = **in
+	}
+	if in.ControllerHealth != nil {
+		in, out := &in.ControllerHealth, &out.ControllerHealth
+		*out = new(ControllerHealthConfig)
+		**out = **in
+	}
+	if in.BrokerDecommission != nil {
+		in, out := &in.BrokerDecommission, &out.BrokerDecommission
+		*out = new(BrokerDecommissionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VersionUpgrade != nil {
+		in, out := &in.VersionUpgrade, &out.VersionUpgrade
+		*out = new(VersionUpgradeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterSpec.
+func (in *KafkaClusterSpec) DeepCopy() *KafkaClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClusterStatus) DeepCopyInto(out *KafkaClusterStatus) {
+	*out = *in
+	if in.BrokersState != nil {
+		in, out := &in.BrokersState, &out.BrokersState
+		*out = make(map[string]BrokerState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	out.RollingUpgrade = in.RollingUpgrade
+	in.ListenerStatuses.DeepCopyInto(&out.ListenerStatuses)
+	if in.PreflightChecks != nil {
+		in, out := &in.PreflightChecks, &out.PreflightChecks
+		*out = new(PreflightChecksStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SmokeTest != nil {
+		in, out := &in.SmokeTest, &out.SmokeTest
+		*out = new(SmokeTestStatus)
+		**out = **in
+	}
+	if in.BrokerConfigValidation != nil {
+		in, out := &in.BrokerConfigValidation, &out.BrokerConfigValidation
+		*out = new(BrokerConfigValidationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CruiseControlLoad != nil {
+		in, out := &in.CruiseControlLoad, &out.CruiseControlLoad
+		*out = new(CruiseControlLoadStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GoalViolationRemediation != nil {
+		in, out := &in.GoalViolationRemediation, &out.GoalViolationRemediation
+		*out = new(GoalViolationRemediationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TenantUsage != nil {
+		in, out := &in.TenantUsage, &out.TenantUsage
+		*out = make(map[string]TenantUsage, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(AdoptionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OperationHistory != nil {
+		in, out := &in.OperationHistory, &out.OperationHistory
+		*out = make([]OperationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CapacityHeadroom != nil {
+		in, out := &in.CapacityHeadroom, &out.CapacityHeadroom
+		*out = new(CapacityHeadroomStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InternalTopics != nil {
+		in, out := &in.InternalTopics, &out.InternalTopics
+		*out = new(InternalTopicsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ListenerMetrics != nil {
+		in, out := &in.ListenerMetrics, &out.ListenerMetrics
+		*out = new(ListenerMetricsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestLatency != nil {
+		in, out := &in.RequestLatency, &out.RequestLatency
+		*out = new(RequestLatencyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControllerHealth != nil {
+		in, out := &in.ControllerHealth, &out.ControllerHealth
+		*out = new(ControllerHealthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DecommissionedBrokers != nil {
+		in, out := &in.DecommissionedBrokers, &out.DecommissionedBrokers
+		*out = make([]DecommissionedBroker, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CruiseControlRightsize != nil {
+		in, out := &in.CruiseControlRightsize, &out.CruiseControlRightsize
+		*out = new(CruiseControlRightsizeStatus)
+		**out = **in
+	}
+	if in.TopicRebalance != nil {
+		in, out := &in.TopicRebalance, &out.TopicRebalance
+		*out = new(TopicRebalanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduledRebalance != nil {
+		in, out := &in.ScheduledRebalance, &out.ScheduledRebalance
+		*out = new(ScheduledRebalanceStatus)
+		**out = **in
+	}
+	if in.DiskSkewRebalance != nil {
+		in, out := &in.DiskSkewRebalance, &out.DiskSkewRebalance
+		*out = new(DiskSkewRebalanceStatus)
+		**out = **in
+	}
+	if in.CruiseControlOperations != nil {
+		in, out := &in.CruiseControlOperations, &out.CruiseControlOperations
+		*out = new(CruiseControlOperationsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KRaft != nil {
+		in, out := &in.KRaft, &out.KRaft
+		*out = new(KRaftStatus)
+		**out = **in
+	}
+	if in.VersionUpgrade != nil {
+		in, out := &in.VersionUpgrade, &out.VersionUpgrade
+		*out = new(VersionUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BrokerAutoscaling != nil {
+		in, out := &in.BrokerAutoscaling, &out.BrokerAutoscaling
+		*out = new(BrokerAutoscalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceRecommendations != nil {
+		in, out := &in.ResourceRecommendations, &out.ResourceRecommendations
+		*out = make(map[string]ResourceRecommendationStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterStatus.
+func (in *KafkaClusterStatus) DeepCopy() *KafkaClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaVersion) DeepCopyInto(out *KafkaVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaVersion.
+func (in *KafkaVersion) DeepCopy() *KafkaVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerMetrics) DeepCopyInto(out *ListenerMetrics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerMetrics.
+func (in *ListenerMetrics) DeepCopy() *ListenerMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerMetricsStatus) DeepCopyInto(out *ListenerMetricsStatus) {
+	*out = *in
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]ListenerMetrics, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerMetricsStatus.
+func (in *ListenerMetricsStatus) DeepCopy() *ListenerMetricsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerMetricsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerStatus) DeepCopyInto(out *ListenerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatus.
+func (in *ListenerStatus) DeepCopy() *ListenerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ListenerStatusList) DeepCopyInto(out *ListenerStatusList) {
+	{
+		in := &in
+		*out = make(ListenerStatusList, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatusList.
+func (in ListenerStatusList) DeepCopy() ListenerStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerStatusList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerStatuses) DeepCopyInto(out *ListenerStatuses) {
+	*out = *in
+	if in.InternalListeners != nil {
+		in, out := &in.InternalListeners, &out.InternalListeners
+		*out = make(map[string]ListenerStatusList, len(*in))
+		for key, val := range *in {
+			var outVal []ListenerStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(ListenerStatusList, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ExternalListeners != nil {
+		in, out := &in.ExternalListeners, &out.ExternalListeners
+		*out = make(map[string]ListenerStatusList, len(*in))
+		for key, val := range *in {
+			var outVal []ListenerStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(ListenerStatusList, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatuses.
+func (in *ListenerStatuses) DeepCopy() *ListenerStatuses {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerStatuses)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenersConfig) DeepCopyInto(out *ListenersConfig) {
+	*out = *in
+	if in.ExternalListeners != nil {
+		in, out := &in.ExternalListeners, &out.ExternalListeners
+		*out = make([]ExternalListenerConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InternalListeners != nil {
+		in, out := &in.InternalListeners, &out.InternalListeners
+		*out = make([]InternalListenerConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SSLSecrets != nil {
+		in, out := &in.SSLSecrets, &out.SSLSecrets
+		*out = new(SSLSecrets)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CertificateRevocation != nil {
+		in, out := &in.CertificateRevocation, &out.CertificateRevocation
+		*out = new(CertificateRevocationConfig)
 		**out = **in
 	}
-	if in.CommandLineArgs != nil {
-		in, out := &in.CommandLineArgs, &out.CommandLineArgs
-		*out = new(EnvoyCommandLineArgs)
+	if in.PrincipalBuilder != nil {
+		in, out := &in.PrincipalBuilder, &out.PrincipalBuilder
+		*out = new(PrincipalBuilderConfig)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyConfig.
-func (in *EnvoyConfig) DeepCopy() *EnvoyConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenersConfig.
+func (in *ListenersConfig) DeepCopy() *ListenersConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(EnvoyConfig)
+	out := new(ListenersConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExternalListenerConfig) DeepCopyInto(out *ExternalListenerConfig) {
+func (in *MaintenanceState) DeepCopyInto(out *MaintenanceState) {
 	*out = *in
-	in.CommonListenerSpec.DeepCopyInto(&out.CommonListenerSpec)
-	in.IngressServiceSettings.DeepCopyInto(&out.IngressServiceSettings)
-	if in.AnyCastPort != nil {
-		in, out := &in.AnyCastPort, &out.AnyCastPort
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Config != nil {
-		in, out := &in.Config, &out.Config
-		*out = new(Config)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListenerConfig.
-func (in *ExternalListenerConfig) DeepCopy() *ExternalListenerConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceState.
+func (in *MaintenanceState) DeepCopy() *MaintenanceState {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalListenerConfig)
+	out := new(MaintenanceState)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ExternalListenerConfigNames) DeepCopyInto(out *ExternalListenerConfigNames) {
-	{
-		in := &in
-		*out = make(ExternalListenerConfigNames, len(*in))
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]MaintenanceWindowDay, len(*in))
 		copy(*out, *in)
 	}
+	out.Duration = in.Duration
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListenerConfigNames.
-func (in ExternalListenerConfigNames) DeepCopy() ExternalListenerConfigNames {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalListenerConfigNames)
+	out := new(MaintenanceWindow)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GracefulActionState) DeepCopyInto(out *GracefulActionState) {
+func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
 	*out = *in
-	if in.VolumeStates != nil {
-		in, out := &in.VolumeStates, &out.VolumeStates
-		*out = make(map[string]VolumeState, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulActionState.
-func (in *GracefulActionState) DeepCopy() *GracefulActionState {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConfig.
+func (in *MonitoringConfig) DeepCopy() *MonitoringConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(GracefulActionState)
+	out := new(MonitoringConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
+func (in *NetworkConfig) DeepCopyInto(out *NetworkConfig) {
 	*out = *in
-	in.IngressServiceSettings.DeepCopyInto(&out.IngressServiceSettings)
-	if in.IstioIngressConfig != nil {
-		in, out := &in.IstioIngressConfig, &out.IstioIngressConfig
-		*out = new(IstioIngressConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.EnvoyConfig != nil {
-		in, out := &in.EnvoyConfig, &out.EnvoyConfig
-		*out = new(EnvoyConfig)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressConfig.
-func (in *IngressConfig) DeepCopy() *IngressConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkConfig.
+func (in *NetworkConfig) DeepCopy() *NetworkConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(IngressConfig)
+	out := new(NetworkConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngressServiceSettings) DeepCopyInto(out *IngressServiceSettings) {
+func (in *NodeNetworkCapacityConfig) DeepCopyInto(out *NodeNetworkCapacityConfig) {
 	*out = *in
-	if in.ServiceAnnotations != nil {
-		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+	if in.InstanceTypes != nil {
+		in, out := &in.InstanceTypes, &out.InstanceTypes
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
@@ -589,489 +2397,418 @@ func (in *IngressServiceSettings) DeepCopyInto(out *IngressServiceSettings) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressServiceSettings.
-func (in *IngressServiceSettings) DeepCopy() *IngressServiceSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkCapacityConfig.
+func (in *NodeNetworkCapacityConfig) DeepCopy() *NodeNetworkCapacityConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(IngressServiceSettings)
+	out := new(NodeNetworkCapacityConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalListenerConfig) DeepCopyInto(out *InternalListenerConfig) {
+func (in *OperationRecord) DeepCopyInto(out *OperationRecord) {
 	*out = *in
-	in.CommonListenerSpec.DeepCopyInto(&out.CommonListenerSpec)
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalListenerConfig.
-func (in *InternalListenerConfig) DeepCopy() *InternalListenerConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationRecord.
+func (in *OperationRecord) DeepCopy() *OperationRecord {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalListenerConfig)
+	out := new(OperationRecord)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IstioControlPlaneReference) DeepCopyInto(out *IstioControlPlaneReference) {
+func (in *OperationRetentionConfig) DeepCopyInto(out *OperationRetentionConfig) {
 	*out = *in
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioControlPlaneReference.
-func (in *IstioControlPlaneReference) DeepCopy() *IstioControlPlaneReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationRetentionConfig.
+func (in *OperationRetentionConfig) DeepCopy() *OperationRetentionConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(IstioControlPlaneReference)
+	out := new(OperationRetentionConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IstioIngressConfig) DeepCopyInto(out *IstioIngressConfig) {
+func (in *PreflightCheck) DeepCopyInto(out *PreflightCheck) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.TLSOptions != nil {
-		in, out := &in.TLSOptions, &out.TLSOptions
-		*out = new(networkingv1beta1.TLSOptions)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.VirtualServiceAnnotations != nil {
-		in, out := &in.VirtualServiceAnnotations, &out.VirtualServiceAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Envs != nil {
-		in, out := &in.Envs, &out.Envs
-		*out = make([]v1.EnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioIngressConfig.
-func (in *IstioIngressConfig) DeepCopy() *IstioIngressConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightCheck.
+func (in *PreflightCheck) DeepCopy() *PreflightCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(IstioIngressConfig)
+	out := new(PreflightCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaCluster) DeepCopyInto(out *KafkaCluster) {
+func (in *PreflightChecksConfig) DeepCopyInto(out *PreflightChecksConfig) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCluster.
-func (in *KafkaCluster) DeepCopy() *KafkaCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightChecksConfig.
+func (in *PreflightChecksConfig) DeepCopy() *PreflightChecksConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaCluster)
+	out := new(PreflightChecksConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KafkaCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaClusterList) DeepCopyInto(out *KafkaClusterList) {
+func (in *PreflightChecksStatus) DeepCopyInto(out *PreflightChecksStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KafkaCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]PreflightCheck, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterList.
-func (in *KafkaClusterList) DeepCopy() *KafkaClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightChecksStatus.
+func (in *PreflightChecksStatus) DeepCopy() *PreflightChecksStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaClusterList)
+	out := new(PreflightChecksStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KafkaClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrincipalBuilderConfig) DeepCopyInto(out *PrincipalBuilderConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrincipalBuilderConfig.
+func (in *PrincipalBuilderConfig) DeepCopy() *PrincipalBuilderConfig {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(PrincipalBuilderConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaClusterSpec) DeepCopyInto(out *KafkaClusterSpec) {
+func (in *QuorumStatus) DeepCopyInto(out *QuorumStatus) {
 	*out = *in
-	in.ListenersConfig.DeepCopyInto(&out.ListenersConfig)
-	if in.ZKAddresses != nil {
-		in, out := &in.ZKAddresses, &out.ZKAddresses
-		*out = make([]string, len(*in))
+	if in.Voters != nil {
+		in, out := &in.Voters, &out.Voters
+		*out = make([]QuorumVoterStatus, len(*in))
 		copy(*out, *in)
 	}
-	if in.RackAwareness != nil {
-		in, out := &in.RackAwareness, &out.RackAwareness
-		*out = new(RackAwareness)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.BrokerConfigGroups != nil {
-		in, out := &in.BrokerConfigGroups, &out.BrokerConfigGroups
-		*out = make(map[string]BrokerConfig, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
-	}
-	if in.Brokers != nil {
-		in, out := &in.Brokers, &out.Brokers
-		*out = make([]Broker, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	out.DisruptionBudget = in.DisruptionBudget
-	out.RollingUpgradeConfig = in.RollingUpgradeConfig
-	if in.IstioControlPlane != nil {
-		in, out := &in.IstioControlPlane, &out.IstioControlPlane
-		*out = new(IstioControlPlaneReference)
-		**out = **in
-	}
-	in.CruiseControlConfig.DeepCopyInto(&out.CruiseControlConfig)
-	in.EnvoyConfig.DeepCopyInto(&out.EnvoyConfig)
-	out.MonitoringConfig = in.MonitoringConfig
-	if in.AlertManagerConfig != nil {
-		in, out := &in.AlertManagerConfig, &out.AlertManagerConfig
-		*out = new(AlertManagerConfig)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuorumStatus.
+func (in *QuorumStatus) DeepCopy() *QuorumStatus {
+	if in == nil {
+		return nil
 	}
-	in.IstioIngressConfig.DeepCopyInto(&out.IstioIngressConfig)
-	if in.Envs != nil {
-		in, out := &in.Envs, &out.Envs
-		*out = make([]v1.EnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out := new(QuorumStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuorumVoterStatus) DeepCopyInto(out *QuorumVoterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuorumVoterStatus.
+func (in *QuorumVoterStatus) DeepCopy() *QuorumVoterStatus {
+	if in == nil {
+		return nil
 	}
-	if in.ClientSSLCertSecret != nil {
-		in, out := &in.ClientSSLCertSecret, &out.ClientSSLCertSecret
-		*out = new(v1.LocalObjectReference)
-		**out = **in
+	out := new(QuorumVoterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RackAwareness) DeepCopyInto(out *RackAwareness) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterSpec.
-func (in *KafkaClusterSpec) DeepCopy() *KafkaClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RackAwareness.
+func (in *RackAwareness) DeepCopy() *RackAwareness {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaClusterSpec)
+	out := new(RackAwareness)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaClusterStatus) DeepCopyInto(out *KafkaClusterStatus) {
+func (in *RemediatedGoal) DeepCopyInto(out *RemediatedGoal) {
 	*out = *in
-	if in.BrokersState != nil {
-		in, out := &in.BrokersState, &out.BrokersState
-		*out = make(map[string]BrokerState, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.Cooldown != nil {
+		in, out := &in.Cooldown, &out.Cooldown
+		*out = new(apismetav1.Duration)
+		**out = **in
 	}
-	out.RollingUpgrade = in.RollingUpgrade
-	in.ListenerStatuses.DeepCopyInto(&out.ListenerStatuses)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterStatus.
-func (in *KafkaClusterStatus) DeepCopy() *KafkaClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediatedGoal.
+func (in *RemediatedGoal) DeepCopy() *RemediatedGoal {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaClusterStatus)
+	out := new(RemediatedGoal)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaVersion) DeepCopyInto(out *KafkaVersion) {
+func (in *RemediatedGoalStatus) DeepCopyInto(out *RemediatedGoalStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaVersion.
-func (in *KafkaVersion) DeepCopy() *KafkaVersion {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediatedGoalStatus.
+func (in *RemediatedGoalStatus) DeepCopy() *RemediatedGoalStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaVersion)
+	out := new(RemediatedGoalStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListenerStatus) DeepCopyInto(out *ListenerStatus) {
+func (in *RequestLatencyConfig) DeepCopyInto(out *RequestLatencyConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatus.
-func (in *ListenerStatus) DeepCopy() *ListenerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestLatencyConfig.
+func (in *RequestLatencyConfig) DeepCopy() *RequestLatencyConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ListenerStatus)
+	out := new(RequestLatencyConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ListenerStatusList) DeepCopyInto(out *ListenerStatusList) {
-	{
-		in := &in
-		*out = make(ListenerStatusList, len(*in))
+func (in *RequestLatencyStatus) DeepCopyInto(out *RequestLatencyStatus) {
+	*out = *in
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]BrokerRequestLatency, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatusList.
-func (in ListenerStatusList) DeepCopy() ListenerStatusList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestLatencyStatus.
+func (in *RequestLatencyStatus) DeepCopy() *RequestLatencyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ListenerStatusList)
+	out := new(RequestLatencyStatus)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListenerStatuses) DeepCopyInto(out *ListenerStatuses) {
+func (in *ResourceRecommendationConfig) DeepCopyInto(out *ResourceRecommendationConfig) {
 	*out = *in
-	if in.InternalListeners != nil {
-		in, out := &in.InternalListeners, &out.InternalListeners
-		*out = make(map[string]ListenerStatusList, len(*in))
+	if in.MinAllowed != nil {
+		in, out := &in.MinAllowed, &out.MinAllowed
+		*out = make(v1.ResourceList, len(*in))
 		for key, val := range *in {
-			var outVal []ListenerStatus
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make(ListenerStatusList, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
+			(*out)[key] = val.DeepCopy()
 		}
 	}
-	if in.ExternalListeners != nil {
-		in, out := &in.ExternalListeners, &out.ExternalListeners
-		*out = make(map[string]ListenerStatusList, len(*in))
+	if in.MaxAllowed != nil {
+		in, out := &in.MaxAllowed, &out.MaxAllowed
+		*out = make(v1.ResourceList, len(*in))
 		for key, val := range *in {
-			var outVal []ListenerStatus
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make(ListenerStatusList, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
+			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.MinChangePercent != nil {
+		in, out := &in.MinChangePercent, &out.MinChangePercent
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatuses.
-func (in *ListenerStatuses) DeepCopy() *ListenerStatuses {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRecommendationConfig.
+func (in *ResourceRecommendationConfig) DeepCopy() *ResourceRecommendationConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ListenerStatuses)
+	out := new(ResourceRecommendationConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListenersConfig) DeepCopyInto(out *ListenersConfig) {
+func (in *ResourceRecommendationStatus) DeepCopyInto(out *ResourceRecommendationStatus) {
 	*out = *in
-	if in.ExternalListeners != nil {
-		in, out := &in.ExternalListeners, &out.ExternalListeners
-		*out = make([]ExternalListenerConfig, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.InternalListeners != nil {
-		in, out := &in.InternalListeners, &out.InternalListeners
-		*out = make([]InternalListenerConfig, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.SSLSecrets != nil {
-		in, out := &in.SSLSecrets, &out.SSLSecrets
-		*out = new(SSLSecrets)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ServiceAnnotations != nil {
-		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
-		*out = make(map[string]string, len(*in))
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = val.DeepCopy()
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenersConfig.
-func (in *ListenersConfig) DeepCopy() *ListenersConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRecommendationStatus.
+func (in *ResourceRecommendationStatus) DeepCopy() *ResourceRecommendationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ListenersConfig)
+	out := new(ResourceRecommendationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
+func (in *RollingUpgradeConfig) DeepCopyInto(out *RollingUpgradeConfig) {
 	*out = *in
+	if in.BrokerOrder != nil {
+		in, out := &in.BrokerOrder, &out.BrokerOrder
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConfig.
-func (in *MonitoringConfig) DeepCopy() *MonitoringConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpgradeConfig.
+func (in *RollingUpgradeConfig) DeepCopy() *RollingUpgradeConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringConfig)
+	out := new(RollingUpgradeConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkConfig) DeepCopyInto(out *NetworkConfig) {
+func (in *RollingUpgradeStatus) DeepCopyInto(out *RollingUpgradeStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkConfig.
-func (in *NetworkConfig) DeepCopy() *NetworkConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpgradeStatus.
+func (in *RollingUpgradeStatus) DeepCopy() *RollingUpgradeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkConfig)
+	out := new(RollingUpgradeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RackAwareness) DeepCopyInto(out *RackAwareness) {
+func (in *SSLSecrets) DeepCopyInto(out *SSLSecrets) {
 	*out = *in
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(metav1.ObjectReference)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RackAwareness.
-func (in *RackAwareness) DeepCopy() *RackAwareness {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSLSecrets.
+func (in *SSLSecrets) DeepCopy() *SSLSecrets {
 	if in == nil {
 		return nil
 	}
-	out := new(RackAwareness)
+	out := new(SSLSecrets)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RollingUpgradeConfig) DeepCopyInto(out *RollingUpgradeConfig) {
+func (in *ScheduledRebalanceStatus) DeepCopyInto(out *ScheduledRebalanceStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpgradeConfig.
-func (in *RollingUpgradeConfig) DeepCopy() *RollingUpgradeConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledRebalanceStatus.
+func (in *ScheduledRebalanceStatus) DeepCopy() *ScheduledRebalanceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RollingUpgradeConfig)
+	out := new(ScheduledRebalanceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RollingUpgradeStatus) DeepCopyInto(out *RollingUpgradeStatus) {
+func (in *SmokeTestConfig) DeepCopyInto(out *SmokeTestConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpgradeStatus.
-func (in *RollingUpgradeStatus) DeepCopy() *RollingUpgradeStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmokeTestConfig.
+func (in *SmokeTestConfig) DeepCopy() *SmokeTestConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(RollingUpgradeStatus)
+	out := new(SmokeTestConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SSLSecrets) DeepCopyInto(out *SSLSecrets) {
+func (in *SmokeTestStatus) DeepCopyInto(out *SmokeTestStatus) {
 	*out = *in
-	if in.IssuerRef != nil {
-		in, out := &in.IssuerRef, &out.IssuerRef
-		*out = new(metav1.ObjectReference)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmokeTestStatus.
+func (in *SmokeTestStatus) DeepCopy() *SmokeTestStatus {
+	if in == nil {
+		return nil
 	}
+	out := new(SmokeTestStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSLSecrets.
-func (in *SSLSecrets) DeepCopy() *SSLSecrets {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProbeConfig) DeepCopyInto(out *StartupProbeConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProbeConfig.
+func (in *StartupProbeConfig) DeepCopy() *StartupProbeConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(SSLSecrets)
+	out := new(StartupProbeConfig)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1096,6 +2833,68 @@ func (in *StorageConfig) DeepCopy() *StorageConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenancyConfig) DeepCopyInto(out *TenancyConfig) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]TenantNamespace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenancyConfig.
+func (in *TenancyConfig) DeepCopy() *TenancyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TenancyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantNamespace) DeepCopyInto(out *TenantNamespace) {
+	*out = *in
+	if in.MaxTopics != nil {
+		in, out := &in.MaxTopics, &out.MaxTopics
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPartitions != nil {
+		in, out := &in.MaxPartitions, &out.MaxPartitions
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantNamespace.
+func (in *TenantNamespace) DeepCopy() *TenantNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantUsage) DeepCopyInto(out *TenantUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantUsage.
+func (in *TenantUsage) DeepCopy() *TenantUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TopicConfig) DeepCopyInto(out *TopicConfig) {
 	*out = *in
@@ -1111,6 +2910,81 @@ func (in *TopicConfig) DeepCopy() *TopicConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicRebalanceStatus) DeepCopyInto(out *TopicRebalanceStatus) {
+	*out = *in
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicRebalanceStatus.
+func (in *TopicRebalanceStatus) DeepCopy() *TopicRebalanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicRebalanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionUpgradeCondition) DeepCopyInto(out *VersionUpgradeCondition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionUpgradeCondition.
+func (in *VersionUpgradeCondition) DeepCopy() *VersionUpgradeCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionUpgradeCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionUpgradeConfig) DeepCopyInto(out *VersionUpgradeConfig) {
+	*out = *in
+	if in.ProtocolBumpDelay != nil {
+		in, out := &in.ProtocolBumpDelay, &out.ProtocolBumpDelay
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionUpgradeConfig.
+func (in *VersionUpgradeConfig) DeepCopy() *VersionUpgradeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionUpgradeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionUpgradeStatus) DeepCopyInto(out *VersionUpgradeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]VersionUpgradeCondition, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionUpgradeStatus.
+func (in *VersionUpgradeStatus) DeepCopy() *VersionUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeState) DeepCopyInto(out *VolumeState) {
 	*out = *in
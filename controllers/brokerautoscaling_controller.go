@@ -0,0 +1,336 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka"
+)
+
+const (
+	// DefaultBrokerAutoscalingIntervalInSec is the period of checking the broker count called for by the broker loads
+	DefaultBrokerAutoscalingIntervalInSec = 60
+
+	brokerAutoscalingTimeFormat = "2006-01-02 15:04:05"
+)
+
+// BrokerAutoscalingReconciler adjusts the number of brokers of the kafka clusters within the bounds of
+// spec.cruiseControlConfig.autoscaling to keep the average load of the brokers at its targets. The load is taken from
+// the last broker loads of Cruise Control recorded in status.cruiseControlLoad. The brokers are added to and removed
+// from spec.brokers, the KafkaCluster controller starts their add and remove broker operations in Cruise Control.
+type BrokerAutoscalingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *BrokerAutoscalingReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	config := instance.Spec.CruiseControlConfig.Autoscaling
+	if config == nil || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	if !brokerCountSettled(instance) {
+		log.V(1).Info("requeue event as the cluster is not running or brokers are being added or removed")
+		return requeueAfter(DefaultBrokerAutoscalingIntervalInSec)
+	}
+
+	brokerIDs := brokerNodeIDs(instance)
+	load := instance.Status.CruiseControlLoad
+	if !isLoadReportedForBrokers(load, brokerIDs) {
+		log.V(1).Info("requeue event as the load of the brokers is not known (yet)")
+		return requeueAfter(DefaultBrokerAutoscalingIntervalInSec)
+	}
+
+	current := int32(len(brokerIDs))
+	desired, reason := desiredBrokerCount(config, load, brokerIDs)
+	status := &kafkav1beta1.BrokerAutoscalingStatus{DesiredBrokers: desired, Reason: reason}
+	if last := instance.Status.BrokerAutoscaling; last != nil {
+		status.LastScaledAt = last.LastScaledAt
+		status.AddedBrokers = last.AddedBrokers
+		status.RemovedBrokers = last.RemovedBrokers
+	}
+
+	now := time.Now()
+	if desired != current && brokerAutoscalingCooldownElapsed(config, status, now) {
+		var added, removed []string
+		if desired > current {
+			added = addGroupBrokers(instance, config.BrokerConfigGroup, desired-current)
+		} else {
+			// the brokers are removed one by one, so that the load is reported again before the next removal
+			removed = removeAutoscaledBroker(instance, config, load)
+		}
+		if len(added) > 0 || len(removed) > 0 {
+			log.Info("adjusting the number of brokers to the load of the brokers",
+				"current", current, "desired", desired, "reason", reason, "added", added, "removed", removed)
+			if err := k8sutil.UpdateCr(instance, r.Client); err != nil {
+				return requeueWithError(log, "failed to update the brokers of the Kafka Cluster", err)
+			}
+			status.LastScaledAt = now.Format(brokerAutoscalingTimeFormat)
+			status.AddedBrokers = added
+			status.RemovedBrokers = removed
+		}
+	}
+
+	if !reflect.DeepEqual(instance.Status.BrokerAutoscaling, status) {
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, status, log); err != nil {
+			return requeueWithError(log, "failed to update the broker autoscaling in the Kafka Cluster status", err)
+		}
+	}
+	return requeueAfter(DefaultBrokerAutoscalingIntervalInSec)
+}
+
+// brokerCountSettled returns true if the cluster is running and no brokers are being added or removed, the broker
+// count is only adjusted once the previous adjustment is carried out
+func brokerCountSettled(instance *kafkav1beta1.KafkaCluster) bool {
+	if instance.Status.State != kafkav1beta1.KafkaClusterRunning {
+		return false
+	}
+	if len(kafka.GetBrokersWithPendingOrRunningCCTask(instance)) > 0 {
+		return false
+	}
+	if len(instance.Status.BrokersState) != len(instance.Spec.Brokers) {
+		return false
+	}
+	for _, broker := range instance.Spec.Brokers {
+		if _, ok := instance.Status.BrokersState[strconv.Itoa(int(broker.Id))]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// brokerNodeIDs returns the IDs of the brokers of the cluster, the controller-only nodes of a KRaft cluster are left
+// out as they do not serve any load
+func brokerNodeIDs(instance *kafkav1beta1.KafkaCluster) []string {
+	ids := make([]string, 0, len(instance.Spec.Brokers))
+	for i := range instance.Spec.Brokers {
+		brokerConfig, err := instance.Spec.Brokers[i].GetBrokerConfig(instance.Spec)
+		if err == nil && brokerConfig != nil && !brokerConfig.IsBrokerNode() {
+			continue
+		}
+		ids = append(ids, strconv.Itoa(int(instance.Spec.Brokers[i].Id)))
+	}
+	return ids
+}
+
+// isLoadReportedForBrokers returns true if the load of all the given brokers is reported
+func isLoadReportedForBrokers(load *kafkav1beta1.CruiseControlLoadStatus, brokerIDs []string) bool {
+	if load == nil || len(brokerIDs) == 0 {
+		return false
+	}
+	reported := make(map[string]bool, len(load.Brokers))
+	for _, broker := range load.Brokers {
+		reported[broker.BrokerID] = true
+	}
+	for _, id := range brokerIDs {
+		if !reported[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// brokerLoadTarget is a load target of the autoscaler with the load it is compared to
+type brokerLoadTarget struct {
+	name   string
+	unit   string
+	target int32
+	load   func(kafkav1beta1.BrokerLoadStatus) string
+}
+
+func brokerLoadTargets(config *kafkav1beta1.BrokerAutoscalingConfig) []brokerLoadTarget {
+	return []brokerLoadTarget{
+		{name: "disk utilization", unit: "%", target: config.TargetDiskPercent,
+			load: func(b kafkav1beta1.BrokerLoadStatus) string { return b.DiskPercent }},
+		{name: "CPU utilization", unit: "%", target: config.TargetCPUPercent,
+			load: func(b kafkav1beta1.BrokerLoadStatus) string { return b.CPUPercent }},
+		{name: "incoming network rate", unit: "KB/s", target: config.TargetNetworkInKBps,
+			load: func(b kafkav1beta1.BrokerLoadStatus) string { return b.NetworkInKBps }},
+		{name: "outgoing network rate", unit: "KB/s", target: config.TargetNetworkOutKBps,
+			load: func(b kafkav1beta1.BrokerLoadStatus) string { return b.NetworkOutKBps }},
+	}
+}
+
+// desiredBrokerCount returns the number of brokers the total load of the given brokers calls for to keep their average
+// load at the targets within the bounds, along with the description of the load it is driven by
+func desiredBrokerCount(config *kafkav1beta1.BrokerAutoscalingConfig, load *kafkav1beta1.CruiseControlLoadStatus,
+	brokerIDs []string) (int32, string) {
+	counted := make(map[string]bool, len(brokerIDs))
+	for _, id := range brokerIDs {
+		counted[id] = true
+	}
+
+	var desired int32
+	reason := ""
+	for _, target := range brokerLoadTargets(config) {
+		if target.target <= 0 {
+			continue
+		}
+		var total float64
+		for _, broker := range load.Brokers {
+			if !counted[broker.BrokerID] {
+				continue
+			}
+			if value, err := strconv.ParseFloat(target.load(broker), 64); err == nil {
+				total += value
+			}
+		}
+		required := int32(math.Ceil(total / float64(target.target)))
+		if reason == "" || required > desired {
+			desired = required
+			reason = fmt.Sprintf("average %s %s%s of %d brokers against the target %d%s", target.name,
+				formatLoad(total/float64(len(brokerIDs))), target.unit, len(brokerIDs), target.target, target.unit)
+		}
+	}
+
+	if desired < config.MinBrokers {
+		desired = config.MinBrokers
+	}
+	if desired > config.MaxBrokers {
+		desired = config.MaxBrokers
+	}
+	return desired, reason
+}
+
+// brokerAutoscalingCooldownElapsed returns true if the cooldown since the last adjustment of the broker count elapsed
+func brokerAutoscalingCooldownElapsed(config *kafkav1beta1.BrokerAutoscalingConfig,
+	status *kafkav1beta1.BrokerAutoscalingStatus, now time.Time) bool {
+	if status.LastScaledAt == "" {
+		return true
+	}
+	lastScaledAt, err := time.ParseInLocation(brokerAutoscalingTimeFormat, status.LastScaledAt, time.Local)
+	return err != nil || now.Sub(lastScaledAt) >= config.GetCooldown()
+}
+
+// addGroupBrokers adds the given number of brokers of the broker config group to the cluster with IDs following the
+// highest one in use, and returns their IDs
+func addGroupBrokers(instance *kafkav1beta1.KafkaCluster, group string, count int32) []string {
+	var nextID int32
+	for _, broker := range instance.Spec.Brokers {
+		if broker.Id >= nextID {
+			nextID = broker.Id + 1
+		}
+	}
+	// the IDs of the brokers being removed are not reused
+	for id := range instance.Status.BrokersState {
+		if brokerID, err := strconv.Atoi(id); err == nil && int32(brokerID) >= nextID {
+			nextID = int32(brokerID) + 1
+		}
+	}
+
+	added := make([]string, 0, count)
+	for i := int32(0); i < count; i++ {
+		instance.Spec.Brokers = append(instance.Spec.Brokers, kafkav1beta1.Broker{
+			Id:                nextID,
+			BrokerConfigGroup: group,
+		})
+		added = append(added, strconv.Itoa(int(nextID)))
+		nextID++
+	}
+	return added
+}
+
+// removeAutoscaledBroker removes the broker of the autoscaling broker config group holding the fewest replicas from the
+// cluster and returns its ID, the brokers in maintenance are kept
+func removeAutoscaledBroker(instance *kafkav1beta1.KafkaCluster, config *kafkav1beta1.BrokerAutoscalingConfig,
+	load *kafkav1beta1.CruiseControlLoadStatus) []string {
+	replicas := make(map[string]int32, len(load.Brokers))
+	for _, broker := range load.Brokers {
+		replicas[broker.BrokerID] = broker.Replicas
+	}
+
+	candidate := -1
+	var candidateReplicas int32
+	for i, broker := range instance.Spec.Brokers {
+		id := strconv.Itoa(int(broker.Id))
+		if broker.BrokerConfigGroup != config.BrokerConfigGroup || instance.Spec.IsBrokerInMaintenance(id) {
+			continue
+		}
+		brokerReplicas, ok := replicas[id]
+		if !ok {
+			continue
+		}
+		// the latest broker is preferred among the ones holding as few replicas
+		if candidate < 0 || brokerReplicas < candidateReplicas ||
+			(brokerReplicas == candidateReplicas && broker.Id > instance.Spec.Brokers[candidate].Id) {
+			candidate = i
+			candidateReplicas = brokerReplicas
+		}
+	}
+	if candidate < 0 {
+		return nil
+	}
+
+	removed := instance.Spec.Brokers[candidate].Id
+	removeBroker(instance, removed)
+	return []string{strconv.Itoa(int(removed))}
+}
+
+// SetupBrokerAutoscalingWithManager registers the broker autoscaling controller to the manager
+func SetupBrokerAutoscalingWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("BrokerAutoscaling")
+
+	// the load is checked periodically, only the creation, the deletion and the spec changes of the clusters trigger
+	// the checks in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,184 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestDesiredBrokerCount(t *testing.T) {
+	load := &v1beta1.CruiseControlLoadStatus{Brokers: []v1beta1.BrokerLoadStatus{
+		{BrokerID: "0", DiskPercent: "80.00", CPUPercent: "30.00", NetworkInKBps: "1000.00"},
+		{BrokerID: "1", DiskPercent: "90.00", CPUPercent: "50.00", NetworkInKBps: "3000.00"},
+		{BrokerID: "2", DiskPercent: "70.00", CPUPercent: "40.00", NetworkInKBps: "unknown"},
+	}}
+	testCases := []struct {
+		testName string
+		config   v1beta1.BrokerAutoscalingConfig
+		desired  int32
+		reason   string
+	}{
+		{
+			testName: "scale up by disk utilization",
+			config:   v1beta1.BrokerAutoscalingConfig{MinBrokers: 1, MaxBrokers: 10, TargetDiskPercent: 60},
+			desired:  4,
+			reason:   "average disk utilization 80.00% of 3 brokers against the target 60%",
+		},
+		{
+			testName: "scale down by CPU utilization",
+			config:   v1beta1.BrokerAutoscalingConfig{MinBrokers: 1, MaxBrokers: 10, TargetCPUPercent: 70},
+			desired:  2,
+			reason:   "average CPU utilization 40.00% of 3 brokers against the target 70%",
+		},
+		{
+			testName: "highest requirement of the targets",
+			config: v1beta1.BrokerAutoscalingConfig{MinBrokers: 1, MaxBrokers: 10, TargetCPUPercent: 70,
+				TargetNetworkInKBps: 500},
+			desired: 8,
+			reason:  "average incoming network rate 1333.33KB/s of 3 brokers against the target 500KB/s",
+		},
+		{
+			testName: "up to the maximum",
+			config:   v1beta1.BrokerAutoscalingConfig{MinBrokers: 1, MaxBrokers: 3, TargetDiskPercent: 10},
+			desired:  3,
+			reason:   "average disk utilization 80.00% of 3 brokers against the target 10%",
+		},
+		{
+			testName: "down to the minimum",
+			config:   v1beta1.BrokerAutoscalingConfig{MinBrokers: 3, MaxBrokers: 6, TargetCPUPercent: 100},
+			desired:  3,
+			reason:   "average CPU utilization 40.00% of 3 brokers against the target 100%",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			desired, reason := desiredBrokerCount(&test.config, load, []string{"0", "1", "2"})
+			if desired != test.desired || reason != test.reason {
+				t.Errorf("expected %d brokers (%s), got %d (%s)", test.desired, test.reason, desired, reason)
+			}
+		})
+	}
+}
+
+func TestBrokerCountSettled(t *testing.T) {
+	newCluster := func() *v1beta1.KafkaCluster {
+		return &v1beta1.KafkaCluster{
+			Spec: v1beta1.KafkaClusterSpec{Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}}},
+			Status: v1beta1.KafkaClusterStatus{
+				State: v1beta1.KafkaClusterRunning,
+				BrokersState: map[string]v1beta1.BrokerState{
+					"0": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}},
+					"1": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}},
+				},
+			},
+		}
+	}
+
+	cluster := newCluster()
+	if !brokerCountSettled(cluster) {
+		t.Error("expected the broker count of the running cluster to be settled")
+	}
+
+	cluster = newCluster()
+	cluster.Status.State = v1beta1.KafkaClusterRollingUpgrading
+	if brokerCountSettled(cluster) {
+		t.Error("expected the broker count of the upgrading cluster not to be settled")
+	}
+
+	cluster = newCluster()
+	cluster.Spec.Brokers = append(cluster.Spec.Brokers, v1beta1.Broker{Id: 2})
+	if brokerCountSettled(cluster) {
+		t.Error("expected the broker count not to be settled while a broker is being added")
+	}
+
+	cluster = newCluster()
+	cluster.Spec.Brokers = cluster.Spec.Brokers[:1]
+	if brokerCountSettled(cluster) {
+		t.Error("expected the broker count not to be settled while a broker is being removed")
+	}
+
+	cluster = newCluster()
+	cluster.Status.BrokersState["1"] = v1beta1.BrokerState{
+		GracefulActionState: v1beta1.GracefulActionState{
+			CruiseControlState:  v1beta1.GracefulUpscaleRunning,
+			CruiseControlTaskId: "task",
+		},
+	}
+	if brokerCountSettled(cluster) {
+		t.Error("expected the broker count not to be settled while Cruise Control adds a broker")
+	}
+}
+
+func TestBrokerAutoscalingCooldownElapsed(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 0, 0, 0, time.Local)
+	config := &v1beta1.BrokerAutoscalingConfig{Cooldown: &metav1.Duration{Duration: time.Hour}}
+
+	if !brokerAutoscalingCooldownElapsed(config, &v1beta1.BrokerAutoscalingStatus{}, now) {
+		t.Error("expected the cooldown to be elapsed without earlier adjustments")
+	}
+	recent := &v1beta1.BrokerAutoscalingStatus{LastScaledAt: now.Add(-30 * time.Minute).Format(brokerAutoscalingTimeFormat)}
+	if brokerAutoscalingCooldownElapsed(config, recent, now) {
+		t.Error("expected the cooldown not to be elapsed after a recent adjustment")
+	}
+	earlier := &v1beta1.BrokerAutoscalingStatus{LastScaledAt: now.Add(-2 * time.Hour).Format(brokerAutoscalingTimeFormat)}
+	if !brokerAutoscalingCooldownElapsed(config, earlier, now) {
+		t.Error("expected the cooldown to be elapsed after an earlier adjustment")
+	}
+}
+
+func TestAddAndRemoveAutoscaledBrokers(t *testing.T) {
+	config := &v1beta1.BrokerAutoscalingConfig{BrokerConfigGroup: "autoscaled"}
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfigGroup: "default"},
+				{Id: 1, BrokerConfigGroup: "autoscaled"},
+				{Id: 2, BrokerConfigGroup: "autoscaled"},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{"0": {}, "1": {}, "2": {}, "5": {}},
+		},
+	}
+
+	added := addGroupBrokers(cluster, config.BrokerConfigGroup, 2)
+	if !reflect.DeepEqual(added, []string{"6", "7"}) {
+		t.Errorf("expected brokers 6 and 7 to be added, got %v", added)
+	}
+	if len(cluster.Spec.Brokers) != 5 || cluster.Spec.Brokers[4].BrokerConfigGroup != "autoscaled" {
+		t.Errorf("expected the added brokers in the autoscaled group, got %v", cluster.Spec.Brokers)
+	}
+
+	load := &v1beta1.CruiseControlLoadStatus{Brokers: []v1beta1.BrokerLoadStatus{
+		{BrokerID: "0", Replicas: 1},
+		{BrokerID: "1", Replicas: 10},
+		{BrokerID: "2", Replicas: 5},
+		{BrokerID: "6", Replicas: 5},
+	}}
+	removed := removeAutoscaledBroker(cluster, config, load)
+	if !reflect.DeepEqual(removed, []string{"6"}) {
+		t.Errorf("expected broker 6 to be removed, got %v", removed)
+	}
+	if len(cluster.Spec.Brokers) != 4 {
+		t.Errorf("expected 4 brokers left, got %v", cluster.Spec.Brokers)
+	}
+}
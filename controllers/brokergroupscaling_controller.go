@@ -0,0 +1,151 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// DefaultBrokerGroupScalingIntervalInSec is the period of checking whether the broker groups can be scaled while
+// brokers are being added or removed
+const DefaultBrokerGroupScalingIntervalInSec = 30
+
+// BrokerGroupScalingReconciler adds brokers of the broker config groups of spec.brokerGroupReplicas to spec.brokers or
+// removes them until each group has the set number of brokers. The groups are scaled once the brokers added or removed
+// earlier are settled, the KafkaCluster controller starts their add and remove broker operations in Cruise Control.
+type BrokerGroupScalingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters,verbs=get;list;watch;update;patch
+
+func (r *BrokerGroupScalingReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if len(instance.Spec.BrokerGroupReplicas) == 0 || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	settled := brokerCountSettled(instance)
+	added, removed := scaleBrokerGroups(instance)
+	if len(added) == 0 && len(removed) == 0 {
+		return reconciled()
+	}
+	if !settled {
+		log.V(1).Info("requeue event as the cluster is not running or brokers are being added or removed")
+		return requeueAfter(DefaultBrokerGroupScalingIntervalInSec)
+	}
+
+	log.Info("scaling the broker groups to their number of brokers", "added", added, "removed", removed)
+	if err := k8sutil.UpdateCr(instance, r.Client); err != nil {
+		return requeueWithError(log, "failed to update the brokers of the Kafka Cluster", err)
+	}
+	return reconciled()
+}
+
+// scaleBrokerGroups adds brokers to and removes brokers with the highest IDs from the broker groups of
+// spec.brokerGroupReplicas until they have the set number of brokers, and returns the IDs of the added and the
+// removed brokers
+func scaleBrokerGroups(instance *kafkav1beta1.KafkaCluster) ([]string, []string) {
+	groups := make([]string, 0, len(instance.Spec.BrokerGroupReplicas))
+	for group := range instance.Spec.BrokerGroupReplicas {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var added, removed []string
+	for _, group := range groups {
+		replicas := instance.Spec.BrokerGroupReplicas[group]
+		var groupBrokerIDs []int32
+		for _, broker := range instance.Spec.Brokers {
+			if broker.BrokerConfigGroup == group {
+				groupBrokerIDs = append(groupBrokerIDs, broker.Id)
+			}
+		}
+
+		current := int32(len(groupBrokerIDs))
+		switch {
+		case current < replicas:
+			added = append(added, addGroupBrokers(instance, group, replicas-current)...)
+		case current > replicas:
+			sort.Slice(groupBrokerIDs, func(i, j int) bool { return groupBrokerIDs[i] > groupBrokerIDs[j] })
+			for _, id := range groupBrokerIDs[:current-replicas] {
+				removeBroker(instance, id)
+				removed = append(removed, strconv.Itoa(int(id)))
+			}
+		}
+	}
+	return added, removed
+}
+
+// removeBroker removes the broker with the given ID from spec.brokers
+func removeBroker(instance *kafkav1beta1.KafkaCluster, id int32) {
+	for i, broker := range instance.Spec.Brokers {
+		if broker.Id == id {
+			instance.Spec.Brokers = append(instance.Spec.Brokers[:i], instance.Spec.Brokers[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetupBrokerGroupScalingWithManager registers the broker group scaling controller to the manager
+func SetupBrokerGroupScalingWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("BrokerGroupScaling")
+
+	// the broker groups are scaled on the creation, the deletion and the spec changes of the clusters, the status
+	// changes of the brokers being added or removed are polled
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
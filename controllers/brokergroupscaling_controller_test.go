@@ -0,0 +1,60 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestScaleBrokerGroups(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfigGroup: "hdd"},
+				{Id: 1, BrokerConfigGroup: "ssd"},
+				{Id: 2, BrokerConfigGroup: "hdd"},
+				{Id: 3, BrokerConfigGroup: "hdd"},
+				{Id: 4, BrokerConfigGroup: "other"},
+			},
+			BrokerGroupReplicas: map[string]int32{"hdd": 1, "ssd": 3},
+		},
+	}
+
+	added, removed := scaleBrokerGroups(cluster)
+	if !reflect.DeepEqual(added, []string{"5", "6"}) {
+		t.Errorf("expected brokers 5 and 6 to be added, got %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"3", "2"}) {
+		t.Errorf("expected brokers 3 and 2 to be removed, got %v", removed)
+	}
+	expected := []v1beta1.Broker{
+		{Id: 0, BrokerConfigGroup: "hdd"},
+		{Id: 1, BrokerConfigGroup: "ssd"},
+		{Id: 4, BrokerConfigGroup: "other"},
+		{Id: 5, BrokerConfigGroup: "ssd"},
+		{Id: 6, BrokerConfigGroup: "ssd"},
+	}
+	if !reflect.DeepEqual(cluster.Spec.Brokers, expected) {
+		t.Errorf("expected brokers %v, got %v", expected, cluster.Spec.Brokers)
+	}
+
+	added, removed = scaleBrokerGroups(cluster)
+	if len(added) > 0 || len(removed) > 0 {
+		t.Errorf("expected the scaled groups to be left as they are, got added %v and removed %v", added, removed)
+	}
+}
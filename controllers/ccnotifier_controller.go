@@ -0,0 +1,88 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/banzaicloud/koperator/internal/ccnotifier"
+	"github.com/banzaicloud/koperator/internal/statusapi"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// CruiseControlNotifierController implements Runnable receiving the anomalies the notifier of Cruise Control posts
+// to the operator. The notifier authenticates with a bearer token allowed by RBAC to create the
+// kafkaclusters/anomalies subresource of the cluster.
+type CruiseControlNotifierController struct {
+	Client              client.Client
+	Recorder            record.EventRecorder
+	KafkaClientProvider kafkaclient.Provider
+	Addr                string
+	CertDir             string
+}
+
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// SetCruiseControlNotifierWithManager adds the receiver of the Cruise Control anomalies serving HTTPS at addr with the
+// tls.crt and tls.key of certDir to the Manager
+func SetCruiseControlNotifierWithManager(mgr manager.Manager, addr, certDir string) error {
+	return mgr.Add(CruiseControlNotifierController{
+		Client:              mgr.GetClient(),
+		Recorder:            mgr.GetEventRecorderFor("cruisecontrol-notifier"),
+		KafkaClientProvider: kafkaclient.NewDefaultProvider(),
+		Addr:                addr,
+		CertDir:             certDir,
+	})
+}
+
+// NeedLeaderElection returns false as the anomalies are received by every replica of the operator, the
+// remediation operations are named after the anomalies so they are created only once
+func (c CruiseControlNotifierController) NeedLeaderElection() bool {
+	return false
+}
+
+// Start receives the anomalies until the context is done
+func (c CruiseControlNotifierController) Start(ctx context.Context) error {
+	log := logf.Log.WithName("ccnotifier")
+
+	httpServer := &http.Server{
+		Addr:    c.Addr,
+		Handler: ccnotifier.NewApp(log, c.Client, c.Recorder, statusapi.NewReviewAuthorizer(c.Client), c.KafkaClientProvider),
+	}
+	go func() {
+		<-ctx.Done()
+		if err := httpServer.Shutdown(context.Background()); err != nil {
+			log.Error(err, "shutting down Cruise Control notifier receiver failed")
+		}
+	}()
+
+	log.Info("receiving Cruise Control anomalies", "addr", c.Addr)
+	err := httpServer.ListenAndServeTLS(filepath.Join(c.CertDir, "tls.crt"), filepath.Join(c.CertDir, "tls.key"))
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -37,6 +37,10 @@ import (
 // to a KafkaCluster
 var clusterRefLabel = "kafkaCluster"
 
+// fieldOwner is the field manager the metadata the operator sets on the custom resources is attributed to, so
+// that server-side diffs, e.g. of kubectl diff or GitOps tools, tell it apart from the fields set by the users
+const fieldOwner = "koperator"
+
 // newKafkaFromCluster points to the function for retrieving kafka clients,
 // use as var so it can be overwritten from unit tests
 var newKafkaFromCluster = kafkaclient.NewFromCluster
@@ -103,11 +107,13 @@ func checkBrokerConnectionError(logger logr.Logger, err error) (ctrl.Result, err
 	}
 }
 
-// applyClusterRefLabel ensures a map of labels contains a reference to a parent kafka cluster
-func applyClusterRefLabel(cluster *v1beta1.KafkaCluster, labels map[string]string) map[string]string {
+// applyClusterRefLabel returns a copy of a map of labels containing a reference to a parent kafka cluster, the
+// given map is left as it is so that it can be compared with the result
+func applyClusterRefLabel(cluster *v1beta1.KafkaCluster, current map[string]string) map[string]string {
 	labelValue := clusterLabelString(cluster)
-	if labels == nil {
-		labels = make(map[string]string)
+	labels := make(map[string]string, len(current)+1)
+	for k, v := range current {
+		labels[k] = v
 	}
 	if label, ok := labels[clusterRefLabel]; ok {
 		if label != labelValue {
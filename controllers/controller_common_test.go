@@ -197,6 +197,9 @@ func TestApplyClusterRefLabel(t *testing.T) {
 	if !reflect.DeepEqual(newLabels, expected) {
 		t.Error("Expected:", expected, "Got:", newLabels)
 	}
+	if _, ok := labels[clusterRefLabel]; ok {
+		t.Error("Expected the input labels to be left as they are, got:", labels)
+	}
 
 	// existing label with wrong value
 	labels = map[string]string{
@@ -0,0 +1,333 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/jmxextractor"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	// DefaultControllerHealthCheckIntervalInSec is the period of checking the health of the controller
+	DefaultControllerHealthCheckIntervalInSec = 60
+
+	// quorumVotersConfig is the broker config listing the voters of the KRaft metadata quorum as id@host:port
+	quorumVotersConfig = "controller.quorum.voters"
+	// controllerChangeWindow is the period the controller changes are counted within
+	controllerChangeWindow = time.Hour
+)
+
+// ControllerHealthReconciler periodically checks the active controller of the kafka clusters and, for KRaft clusters,
+// the leadership and the replication of the metadata quorum
+type ControllerHealthReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *ControllerHealthReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	if instance.Spec.ControllerHealth == nil {
+		if instance.Status.ControllerHealth != nil {
+			var status *kafkav1beta1.ControllerHealthStatus
+			if err := k8sutil.UpdateCRStatus(r.Client, instance, status, log); err != nil {
+				return requeueWithError(log, "failed to remove the controller health from the Kafka Cluster status", err)
+			}
+		}
+		return reconciled()
+	}
+
+	if instance.Status.State != kafkav1beta1.KafkaClusterRunning {
+		log.V(1).Info("requeue event as the Kafka cluster is not running")
+		return requeueAfter(DefaultControllerHealthCheckIntervalInSec)
+	}
+
+	voters := quorumVoters(instance.Spec)
+
+	// in KRaft mode the controller reported by the brokers is a random broker, the quorum leader is used instead
+	controllerID := int32(-1)
+	if len(voters) == 0 {
+		broker, closeClient, err := newKafkaFromCluster(r.Client, instance)
+		if err != nil {
+			log.Info("requeue event as connecting to the Kafka cluster failed", "error", err.Error())
+			return requeueAfter(DefaultControllerHealthCheckIntervalInSec)
+		}
+		_, controllerID, err = broker.DescribeCluster()
+		closeClient()
+		if err != nil {
+			log.Info("requeue event as describing the Kafka cluster failed", "error", err.Error())
+			return requeueAfter(DefaultControllerHealthCheckIntervalInSec)
+		}
+	}
+
+	jmxExp := jmxextractor.NewJMXExtractor(instance.GetNamespace(),
+		instance.Spec.GetKubernetesClusterDomain(), instance.GetName(), log)
+
+	metrics := make(map[string]*jmxextractor.ControllerMetrics, len(instance.Spec.Brokers))
+	for _, broker := range instance.Spec.Brokers {
+		brokerMetrics, err := jmxExp.ExtractControllerMetrics(broker.Id, instance.Spec.HeadlessServiceEnabled)
+		if err != nil {
+			// the brokers not reachable are left out of the health check
+			log.V(1).Info("getting the controller metrics of broker failed", "brokerId", broker.Id, "error", err.Error())
+			continue
+		}
+		metrics[strconv.Itoa(int(broker.Id))] = brokerMetrics
+	}
+	if len(metrics) == 0 {
+		log.Info("requeue event as getting the controller metrics of the brokers failed")
+		return requeueAfter(DefaultControllerHealthCheckIntervalInSec)
+	}
+
+	status := newControllerHealthStatus(instance.Spec.ControllerHealth, controllerID, metrics, voters,
+		instance.Status.ControllerHealth, time.Now())
+
+	if !controllerHealthEqual(instance.Status.ControllerHealth, status) {
+		if status.State == kafkav1beta1.ControllerDegraded {
+			log.Info("controller is degraded", "activeControllerId", status.ActiveControllerID, "warnings", status.Warnings)
+		}
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, status, log); err != nil {
+			return requeueWithError(log, "failed to update the controller health in the Kafka Cluster status", err)
+		}
+	}
+	return requeueAfter(DefaultControllerHealthCheckIntervalInSec)
+}
+
+// quorumVoters returns the IDs of the voters of the KRaft metadata quorum, the brokers with the controller role when
+// spec.kRaft is set, otherwise the ones set by controller.quorum.voters in the read-only config of the cluster, none in
+// ZooKeeper mode
+func quorumVoters(spec kafkav1beta1.KafkaClusterSpec) []string {
+	if spec.KRaftMode {
+		controllerIDs, err := spec.GetKRaftControllerIDs()
+		if err != nil {
+			return nil
+		}
+		voters := make([]string, 0, len(controllerIDs))
+		for _, id := range controllerIDs {
+			voters = append(voters, strconv.Itoa(int(id)))
+		}
+		return voters
+	}
+	config, err := properties.NewFromString(spec.ReadOnlyConfig)
+	if err != nil {
+		return nil
+	}
+	property, ok := config.Get(quorumVotersConfig)
+	if !ok {
+		return nil
+	}
+	var voters []string
+	for _, voter := range strings.Split(property.Value(), ",") {
+		if id := strings.TrimSpace(strings.SplitN(voter, "@", 2)[0]); id != "" {
+			voters = append(voters, id)
+		}
+	}
+	return voters
+}
+
+// newControllerHealthStatus returns the health of the controller from the controller reported by the cluster in
+// ZooKeeper mode and the controller metrics of the brokers, and from the raft metrics of the voters in KRaft mode.
+// The controller changes are counted on top of the last health check.
+func newControllerHealthStatus(config *kafkav1beta1.ControllerHealthConfig, controllerID int32,
+	metrics map[string]*jmxextractor.ControllerMetrics, voters []string, last *kafkav1beta1.ControllerHealthStatus,
+	now time.Time) *kafkav1beta1.ControllerHealthStatus {
+	status := &kafkav1beta1.ControllerHealthStatus{
+		CheckedAt:          now.Format("2006-01-02 15:04:05"),
+		State:              kafkav1beta1.ControllerHealthy,
+		ActiveControllerID: controllerID,
+	}
+	for _, brokerMetrics := range metrics {
+		if brokerMetrics.ActiveController {
+			status.ActiveControllers++
+		}
+	}
+
+	if len(voters) > 0 {
+		status.Quorum = newQuorumStatus(config, metrics, voters, status)
+		status.ActiveControllerID = status.Quorum.LeaderID
+		for _, id := range voters {
+			if voterMetrics := metrics[id]; voterMetrics != nil && voterMetrics.Raft != nil &&
+				voterMetrics.Raft.CurrentEpoch > status.ControllerEpoch {
+				status.ControllerEpoch = voterMetrics.Raft.CurrentEpoch
+			}
+		}
+	}
+
+	if status.ActiveControllerID < 0 {
+		status.Warnings = append(status.Warnings, "there is no active controller")
+	}
+	if status.ActiveControllers > 1 {
+		status.Warnings = append(status.Warnings,
+			fmt.Sprintf("%d brokers report to be the active controller", status.ActiveControllers))
+	}
+
+	if last != nil {
+		status.ControllerChanges = last.ControllerChanges
+		status.LastControllerChangeAt = last.LastControllerChangeAt
+		lastChangeAt, err := time.ParseInLocation("2006-01-02 15:04:05", last.LastControllerChangeAt, now.Location())
+		withinWindow := err == nil && now.Sub(lastChangeAt) < controllerChangeWindow
+		switch {
+		case last.ActiveControllerID != status.ActiveControllerID && status.ActiveControllerID >= 0:
+			if withinWindow {
+				status.ControllerChanges++
+			} else {
+				status.ControllerChanges = 1
+			}
+			status.LastControllerChangeAt = status.CheckedAt
+		case !withinWindow:
+			status.ControllerChanges = 0
+		}
+	}
+	if status.ControllerChanges > config.GetMaxControllerChangesPerHour() {
+		status.Warnings = append(status.Warnings,
+			fmt.Sprintf("the controller changed %d times within an hour", status.ControllerChanges))
+	}
+
+	if len(status.Warnings) > 0 {
+		status.State = kafkav1beta1.ControllerDegraded
+	}
+	return status
+}
+
+// newQuorumStatus returns the leader and the replication of the metadata quorum reported by the voters, the
+// warnings found are added to the controller health
+func newQuorumStatus(config *kafkav1beta1.ControllerHealthConfig, metrics map[string]*jmxextractor.ControllerMetrics,
+	voters []string, health *kafkav1beta1.ControllerHealthStatus) *kafkav1beta1.QuorumStatus {
+	quorum := &kafkav1beta1.QuorumStatus{LeaderID: -1}
+
+	leaders := make(map[int32]bool)
+	var leaderEpoch int64
+	var unreachable []string
+	for _, id := range voters {
+		voterMetrics := metrics[id]
+		if voterMetrics == nil || voterMetrics.Raft == nil {
+			unreachable = append(unreachable, id)
+			continue
+		}
+		if voterMetrics.Raft.CurrentLeader >= 0 {
+			leaders[voterMetrics.Raft.CurrentLeader] = true
+			// the voters lagging behind may still follow the leader of an earlier epoch
+			if quorum.LeaderID < 0 || voterMetrics.Raft.CurrentEpoch > leaderEpoch {
+				quorum.LeaderID = voterMetrics.Raft.CurrentLeader
+				leaderEpoch = voterMetrics.Raft.CurrentEpoch
+			}
+		}
+		if voterMetrics.Raft.HighWatermark > quorum.HighWatermark {
+			quorum.HighWatermark = voterMetrics.Raft.HighWatermark
+		}
+	}
+
+	if len(leaders) > 1 {
+		ids := make([]string, 0, len(leaders))
+		for id := range leaders {
+			ids = append(ids, strconv.Itoa(int(id)))
+		}
+		sort.Strings(ids)
+		health.Warnings = append(health.Warnings,
+			fmt.Sprintf("the voters disagree on the quorum leader: %s", strings.Join(ids, ", ")))
+	} else if quorum.LeaderID < 0 {
+		health.Warnings = append(health.Warnings, "the metadata quorum has no leader")
+	}
+
+	for _, id := range voters {
+		voterMetrics := metrics[id]
+		if voterMetrics == nil || voterMetrics.Raft == nil {
+			continue
+		}
+		voter := kafkav1beta1.QuorumVoterStatus{ID: id, LogEndOffset: voterMetrics.Raft.LogEndOffset}
+		if lag := quorum.HighWatermark - voter.LogEndOffset; lag > 0 {
+			voter.Lag = lag
+		}
+		if voter.Lag > config.GetMaxVoterLag() {
+			health.Warnings = append(health.Warnings,
+				fmt.Sprintf("voter %s lags %d records behind the quorum leader", id, voter.Lag))
+		}
+		quorum.Voters = append(quorum.Voters, voter)
+	}
+	if len(unreachable) > 0 {
+		health.Warnings = append(health.Warnings,
+			fmt.Sprintf("the metadata of voters %s could not be checked", strings.Join(unreachable, ", ")))
+	}
+	return quorum
+}
+
+// controllerHealthEqual returns true if the controller health checks differ only in the time they were done at
+func controllerHealthEqual(last, current *kafkav1beta1.ControllerHealthStatus) bool {
+	if last == nil || current == nil {
+		return last == current
+	}
+	lastCopy, currentCopy := last.DeepCopy(), current.DeepCopy()
+	lastCopy.CheckedAt, currentCopy.CheckedAt = "", ""
+	return reflect.DeepEqual(lastCopy, currentCopy)
+}
+
+// SetupControllerHealthWithManager registers the controller health controller to the manager
+func SetupControllerHealthWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("ControllerHealth")
+
+	// the health checks are done periodically, only the creation, the deletion and the spec changes of the clusters
+	// trigger them in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
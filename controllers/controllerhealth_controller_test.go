@@ -0,0 +1,162 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/jmxextractor"
+)
+
+func TestQuorumVoters(t *testing.T) {
+	if voters := quorumVoters(v1beta1.KafkaClusterSpec{ReadOnlyConfig: "broker.rack=a"}); voters != nil {
+		t.Errorf("expected no voters in ZooKeeper mode, got: %v", voters)
+	}
+	voters := quorumVoters(v1beta1.KafkaClusterSpec{
+		ReadOnlyConfig: "controller.quorum.voters=0@kafka-0:29093, 1@kafka-1:29093,2@kafka-2:29093",
+	})
+	if expected := []string{"0", "1", "2"}; !reflect.DeepEqual(voters, expected) {
+		t.Errorf("expected voters %v, got: %v", expected, voters)
+	}
+
+	controller := &v1beta1.BrokerConfig{ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleController}}
+	voters = quorumVoters(v1beta1.KafkaClusterSpec{
+		KRaftMode: true,
+		Brokers: []v1beta1.Broker{
+			{Id: 3, BrokerConfig: controller},
+			{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{}},
+			{Id: 1, BrokerConfig: controller},
+		},
+	})
+	if expected := []string{"1", "3"}; !reflect.DeepEqual(voters, expected) {
+		t.Errorf("expected voters %v, got: %v", expected, voters)
+	}
+}
+
+func TestNewControllerHealthStatus(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 30, 0, 0, time.UTC)
+	config := &v1beta1.ControllerHealthConfig{MaxControllerChangesPerHour: 2, MaxVoterLag: 100}
+	raft := func(active bool, leader int32, epoch, highWatermark, logEndOffset int64) *jmxextractor.ControllerMetrics {
+		return &jmxextractor.ControllerMetrics{
+			ActiveController: active,
+			Raft: &jmxextractor.RaftMetrics{CurrentLeader: leader, CurrentEpoch: epoch,
+				HighWatermark: highWatermark, LogEndOffset: logEndOffset},
+		}
+	}
+
+	testCases := []struct {
+		testName     string
+		controllerID int32
+		metrics      map[string]*jmxextractor.ControllerMetrics
+		voters       []string
+		last         *v1beta1.ControllerHealthStatus
+		expected     *v1beta1.ControllerHealthStatus
+	}{
+		{
+			testName:     "healthy ZooKeeper controller",
+			controllerID: 1,
+			metrics:      map[string]*jmxextractor.ControllerMetrics{"0": {}, "1": {ActiveController: true}},
+			last:         &v1beta1.ControllerHealthStatus{ActiveControllerID: 1, ControllerChanges: 1, LastControllerChangeAt: "2022-05-04 12:00:00"},
+			expected: &v1beta1.ControllerHealthStatus{CheckedAt: "2022-05-04 12:30:00", State: v1beta1.ControllerHealthy,
+				ActiveControllerID: 1, ActiveControllers: 1, ControllerChanges: 1, LastControllerChangeAt: "2022-05-04 12:00:00"},
+		},
+		{
+			testName:     "controller changes reset after an hour",
+			controllerID: 1,
+			metrics:      map[string]*jmxextractor.ControllerMetrics{"1": {ActiveController: true}},
+			last:         &v1beta1.ControllerHealthStatus{ActiveControllerID: 1, ControllerChanges: 3, LastControllerChangeAt: "2022-05-04 11:00:00"},
+			expected: &v1beta1.ControllerHealthStatus{CheckedAt: "2022-05-04 12:30:00", State: v1beta1.ControllerHealthy,
+				ActiveControllerID: 1, ActiveControllers: 1, LastControllerChangeAt: "2022-05-04 11:00:00"},
+		},
+		{
+			testName:     "unstable ZooKeeper controller",
+			controllerID: 0,
+			metrics:      map[string]*jmxextractor.ControllerMetrics{"0": {ActiveController: true}, "1": {ActiveController: true}},
+			last:         &v1beta1.ControllerHealthStatus{ActiveControllerID: 1, ControllerChanges: 2, LastControllerChangeAt: "2022-05-04 12:10:00"},
+			expected: &v1beta1.ControllerHealthStatus{CheckedAt: "2022-05-04 12:30:00", State: v1beta1.ControllerDegraded,
+				ActiveControllerID: 0, ActiveControllers: 2, ControllerChanges: 3, LastControllerChangeAt: "2022-05-04 12:30:00",
+				Warnings: []string{
+					"2 brokers report to be the active controller",
+					"the controller changed 3 times within an hour",
+				}},
+		},
+		{
+			testName:     "healthy KRaft quorum",
+			controllerID: -1,
+			metrics:      map[string]*jmxextractor.ControllerMetrics{"0": raft(false, 2, 5, 1000, 1000), "1": raft(false, 2, 5, 1000, 950), "2": raft(true, 2, 5, 1000, 1000)},
+			voters:       []string{"0", "1", "2"},
+			expected: &v1beta1.ControllerHealthStatus{CheckedAt: "2022-05-04 12:30:00", State: v1beta1.ControllerHealthy,
+				ActiveControllerID: 2, ActiveControllers: 1, ControllerEpoch: 5,
+				Quorum: &v1beta1.QuorumStatus{LeaderID: 2, HighWatermark: 1000, Voters: []v1beta1.QuorumVoterStatus{
+					{ID: "0", LogEndOffset: 1000}, {ID: "1", LogEndOffset: 950, Lag: 50}, {ID: "2", LogEndOffset: 1000},
+				}}},
+		},
+		{
+			testName:     "unhealthy KRaft quorum",
+			controllerID: -1,
+			metrics:      map[string]*jmxextractor.ControllerMetrics{"0": raft(false, 1, 4, 800, 800), "1": raft(false, 2, 5, 1000, 1000)},
+			voters:       []string{"0", "1", "2"},
+			expected: &v1beta1.ControllerHealthStatus{CheckedAt: "2022-05-04 12:30:00", State: v1beta1.ControllerDegraded,
+				ActiveControllerID: 2, ControllerEpoch: 5,
+				Quorum: &v1beta1.QuorumStatus{LeaderID: 2, HighWatermark: 1000, Voters: []v1beta1.QuorumVoterStatus{
+					{ID: "0", LogEndOffset: 800, Lag: 200}, {ID: "1", LogEndOffset: 1000},
+				}},
+				Warnings: []string{
+					"the voters disagree on the quorum leader: 1, 2",
+					"voter 0 lags 200 records behind the quorum leader",
+					"the metadata of voters 2 could not be checked",
+				}},
+		},
+		{
+			testName:     "KRaft quorum without leader",
+			controllerID: -1,
+			metrics:      map[string]*jmxextractor.ControllerMetrics{"0": raft(false, -1, 5, 1000, 1000)},
+			voters:       []string{"0"},
+			expected: &v1beta1.ControllerHealthStatus{CheckedAt: "2022-05-04 12:30:00", State: v1beta1.ControllerDegraded,
+				ActiveControllerID: -1, ControllerEpoch: 5,
+				Quorum: &v1beta1.QuorumStatus{LeaderID: -1, HighWatermark: 1000, Voters: []v1beta1.QuorumVoterStatus{
+					{ID: "0", LogEndOffset: 1000},
+				}},
+				Warnings: []string{
+					"the metadata quorum has no leader",
+					"there is no active controller",
+				}},
+		},
+	}
+	for _, test := range testCases {
+		status := newControllerHealthStatus(config, test.controllerID, test.metrics, test.voters, test.last, now)
+		if !reflect.DeepEqual(status, test.expected) {
+			t.Errorf("%s: expected controller health %+v, got: %+v", test.testName, test.expected, status)
+		}
+	}
+}
+
+func TestControllerHealthEqual(t *testing.T) {
+	last := &v1beta1.ControllerHealthStatus{CheckedAt: "2022-05-04 12:29:00", State: v1beta1.ControllerHealthy, ActiveControllerID: 1}
+	current := &v1beta1.ControllerHealthStatus{CheckedAt: "2022-05-04 12:30:00", State: v1beta1.ControllerHealthy, ActiveControllerID: 1}
+	if !controllerHealthEqual(last, current) {
+		t.Error("expected the health checks differing only in their time to be equal")
+	}
+	current.ActiveControllerID = 2
+	if controllerHealthEqual(last, current) {
+		t.Error("expected the health checks with different controllers to differ")
+	}
+	if controllerHealthEqual(nil, current) {
+		t.Error("expected the first health check to differ")
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const (
+	// DefaultLoadRefreshIntervalInSec is the period of taking snapshots of the broker loads from Cruise Control
+	DefaultLoadRefreshIntervalInSec = 60
+)
+
+var (
+	brokerLoadMetricLabels = []string{"namespace", "kafka_cr", "broker_id"}
+
+	brokerCPUPercentGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_cpu_percent",
+		Help: "CPU utilization of the broker reported by Cruise Control",
+	}, brokerLoadMetricLabels)
+	brokerDiskPercentGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_disk_percent",
+		Help: "Disk utilization of the broker reported by Cruise Control",
+	}, brokerLoadMetricLabels)
+	brokerLeadersGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_leader_replicas",
+		Help: "Number of leader replicas on the broker reported by Cruise Control",
+	}, brokerLoadMetricLabels)
+	brokerReplicasGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_replicas",
+		Help: "Number of replicas on the broker reported by Cruise Control",
+	}, brokerLoadMetricLabels)
+	brokerNetworkInGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_network_in_kilobytes_per_second",
+		Help: "Incoming network rate of the broker in KB/s reported by Cruise Control",
+	}, brokerLoadMetricLabels)
+	brokerNetworkOutGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_network_out_kilobytes_per_second",
+		Help: "Outgoing network rate of the broker in KB/s reported by Cruise Control",
+	}, brokerLoadMetricLabels)
+
+	brokerLoadGauges = []*prometheus.GaugeVec{
+		brokerCPUPercentGauge,
+		brokerDiskPercentGauge,
+		brokerLeadersGauge,
+		brokerReplicasGauge,
+		brokerNetworkInGauge,
+		brokerNetworkOutGauge,
+	}
+)
+
+func init() {
+	for _, gauge := range brokerLoadGauges {
+		metrics.Registry.MustRegister(gauge)
+	}
+}
+
+// CruiseControlLoadReconciler periodically records the broker loads reported by Cruise Control in the status of the
+// kafka cluster object and in metrics
+type CruiseControlLoadReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *CruiseControlLoadReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		updateBrokerLoadMetrics(instance, &kafkav1beta1.CruiseControlLoadStatus{})
+		return reconciled()
+	}
+
+	if instance.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		instance.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		log.V(1).Info("requeue event as Cruise Control is not deployed (yet)")
+		return requeueAfter(DefaultLoadRefreshIntervalInSec)
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, instance)
+	if err != nil {
+		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+
+	loads, err := scaler.BrokerLoads(ctx)
+	if err != nil {
+		log.Info("requeue event as getting the broker loads from Cruise Control failed", "error", err.Error())
+		return requeueAfter(DefaultLoadRefreshIntervalInSec)
+	}
+
+	loadStatus := newCruiseControlLoadStatus(loads, time.Now())
+	updateBrokerLoadMetrics(instance, loadStatus)
+
+	if instance.Status.CruiseControlLoad == nil ||
+		!reflect.DeepEqual(instance.Status.CruiseControlLoad.Brokers, loadStatus.Brokers) {
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, loadStatus, log); err != nil {
+			return requeueWithError(log, "failed to update the broker loads in the Kafka Cluster status", err)
+		}
+	}
+
+	headroomStatus := newCapacityHeadroomStatus(instance.Spec.CapacityHeadroom, loadStatus)
+	if !reflect.DeepEqual(instance.Status.CapacityHeadroom, headroomStatus) {
+		if headroomStatus != nil {
+			log.Info("capacity headroom of the cluster is breached", "brokers", headroomStatus.BrokersAboveThreshold,
+				"recommendedAdditionalBrokers", headroomStatus.RecommendedAdditionalBrokers)
+		}
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, headroomStatus, log); err != nil {
+			return requeueWithError(log, "failed to update the capacity headroom in the Kafka Cluster status", err)
+		}
+	}
+	return requeueAfter(DefaultLoadRefreshIntervalInSec)
+}
+
+// newCapacityHeadroomStatus returns the breach of the capacity headroom by the broker loads, nil if it is not breached.
+// The number of brokers to add is computed assuming that the brokers have the same disk capacity and that the data is
+// spread evenly after adding them.
+func newCapacityHeadroomStatus(headroom *kafkav1beta1.CapacityHeadroomConfig, loadStatus *kafkav1beta1.CruiseControlLoadStatus) *kafkav1beta1.CapacityHeadroomStatus {
+	if headroom == nil {
+		return nil
+	}
+	brokerIDs := loadStatus.GetBrokersAboveDiskUsage(headroom.MaxDiskUsagePercent)
+	if len(brokerIDs) == 0 {
+		return nil
+	}
+
+	var totalDiskPercent float64
+	for _, broker := range loadStatus.Brokers {
+		if diskPercent, err := strconv.ParseFloat(broker.DiskPercent, 64); err == nil {
+			totalDiskPercent += diskPercent
+		}
+	}
+	status := &kafkav1beta1.CapacityHeadroomStatus{BrokersAboveThreshold: brokerIDs}
+	// the brokers are kept strictly below the threshold
+	if required := int32(math.Floor(totalDiskPercent/float64(headroom.MaxDiskUsagePercent))) + 1; required > int32(len(loadStatus.Brokers)) {
+		status.RecommendedAdditionalBrokers = required - int32(len(loadStatus.Brokers))
+	}
+	return status
+}
+
+// newCruiseControlLoadStatus returns the status snapshot of the broker loads sorted by broker ID
+func newCruiseControlLoadStatus(loads map[string]scale.BrokerLoad, now time.Time) *kafkav1beta1.CruiseControlLoadStatus {
+	brokerIDs := make([]string, 0, len(loads))
+	for brokerID := range loads {
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+	sort.Slice(brokerIDs, func(i, j int) bool {
+		a, _ := strconv.Atoi(brokerIDs[i])
+		b, _ := strconv.Atoi(brokerIDs[j])
+		return a < b
+	})
+
+	status := &kafkav1beta1.CruiseControlLoadStatus{
+		UpdatedAt: now.Format("2006-01-02 15:04:05"),
+		Brokers:   make([]kafkav1beta1.BrokerLoadStatus, 0, len(loads)),
+	}
+	for _, brokerID := range brokerIDs {
+		load := loads[brokerID]
+		status.Brokers = append(status.Brokers, kafkav1beta1.BrokerLoadStatus{
+			BrokerID:       brokerID,
+			CPUPercent:     formatLoad(load.CPUPct),
+			DiskPercent:    formatLoad(load.DiskPct),
+			Leaders:        load.Leaders,
+			Replicas:       load.Replicas,
+			NetworkInKBps:  formatLoad(load.NetworkInRateKB),
+			NetworkOutKBps: formatLoad(load.NetworkOutRateKB),
+		})
+	}
+	return status
+}
+
+func formatLoad(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// updateBrokerLoadMetrics sets the broker load metrics of the cluster and removes the ones of the brokers which are
+// no longer reported, all the metrics of the cluster are removed with an empty status
+func updateBrokerLoadMetrics(instance *kafkav1beta1.KafkaCluster, loadStatus *kafkav1beta1.CruiseControlLoadStatus) {
+	reported := make(map[string]bool, len(loadStatus.Brokers))
+	for _, broker := range loadStatus.Brokers {
+		reported[broker.BrokerID] = true
+		labels := prometheus.Labels{"namespace": instance.Namespace, "kafka_cr": instance.Name, "broker_id": broker.BrokerID}
+		setBrokerLoadGauge(brokerCPUPercentGauge, labels, broker.CPUPercent)
+		setBrokerLoadGauge(brokerDiskPercentGauge, labels, broker.DiskPercent)
+		brokerLeadersGauge.With(labels).Set(float64(broker.Leaders))
+		brokerReplicasGauge.With(labels).Set(float64(broker.Replicas))
+		setBrokerLoadGauge(brokerNetworkInGauge, labels, broker.NetworkInKBps)
+		setBrokerLoadGauge(brokerNetworkOutGauge, labels, broker.NetworkOutKBps)
+	}
+
+	if instance.Status.CruiseControlLoad == nil {
+		return
+	}
+	for _, broker := range instance.Status.CruiseControlLoad.Brokers {
+		if reported[broker.BrokerID] {
+			continue
+		}
+		labels := prometheus.Labels{"namespace": instance.Namespace, "kafka_cr": instance.Name, "broker_id": broker.BrokerID}
+		for _, gauge := range brokerLoadGauges {
+			gauge.Delete(labels)
+		}
+	}
+}
+
+func setBrokerLoadGauge(gauge *prometheus.GaugeVec, labels prometheus.Labels, value string) {
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		gauge.With(labels).Set(v)
+	}
+}
+
+// SetupCruiseControlLoadWithManager registers the cruise control load controller to the manager
+func SetupCruiseControlLoadWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("CruiseControlLoad")
+
+	// the snapshots are taken periodically, only the creation, the deletion and the spec changes of the clusters
+	// trigger them in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,114 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestNewCruiseControlLoadStatus(t *testing.T) {
+	loads := map[string]scale.BrokerLoad{
+		"10": {CPUPct: 5, DiskPct: 10.126, Leaders: 1, Replicas: 3, NetworkInRateKB: 1.5, NetworkOutRateKB: 2},
+		"2":  {CPUPct: 12.345, DiskPct: 25, Leaders: 2, Replicas: 4, NetworkInRateKB: 100, NetworkOutRateKB: 300.004},
+	}
+	now := time.Date(2022, 5, 4, 10, 30, 0, 0, time.UTC)
+
+	expected := &v1beta1.CruiseControlLoadStatus{
+		UpdatedAt: "2022-05-04 10:30:00",
+		Brokers: []v1beta1.BrokerLoadStatus{
+			{BrokerID: "2", CPUPercent: "12.35", DiskPercent: "25.00", Leaders: 2, Replicas: 4,
+				NetworkInKBps: "100.00", NetworkOutKBps: "300.00"},
+			{BrokerID: "10", CPUPercent: "5.00", DiskPercent: "10.13", Leaders: 1, Replicas: 3,
+				NetworkInKBps: "1.50", NetworkOutKBps: "2.00"},
+		},
+	}
+	if status := newCruiseControlLoadStatus(loads, now); !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected load status: %+v, got: %+v", expected, status)
+	}
+}
+
+func TestUpdateBrokerLoadMetrics(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "metrics-test"}}
+	now := time.Now()
+
+	loadStatus := newCruiseControlLoadStatus(map[string]scale.BrokerLoad{
+		"0": {CPUPct: 10, Leaders: 3},
+		"1": {CPUPct: 20, Leaders: 4},
+	}, now)
+	updateBrokerLoadMetrics(cluster, loadStatus)
+	if value := testutil.ToFloat64(brokerCPUPercentGauge.WithLabelValues("metrics-test", "kafka", "1")); value != 20 {
+		t.Errorf("expected CPU utilization 20 of broker 1, got: %v", value)
+	}
+	if count := testutil.CollectAndCount(brokerLeadersGauge); count < 2 {
+		t.Errorf("expected leader metrics of both brokers, got %d metrics", count)
+	}
+
+	// broker 1 is removed
+	cluster.Status.CruiseControlLoad = loadStatus
+	loadStatus = newCruiseControlLoadStatus(map[string]scale.BrokerLoad{"0": {CPUPct: 15}}, now)
+	before := testutil.CollectAndCount(brokerCPUPercentGauge)
+	updateBrokerLoadMetrics(cluster, loadStatus)
+	if after := testutil.CollectAndCount(brokerCPUPercentGauge); after != before-1 {
+		t.Errorf("expected the metrics of broker 1 to be removed, got %d metrics instead of %d", after, before-1)
+	}
+
+	// cluster is deleted
+	cluster.Status.CruiseControlLoad = loadStatus
+	before = testutil.CollectAndCount(brokerCPUPercentGauge)
+	updateBrokerLoadMetrics(cluster, &v1beta1.CruiseControlLoadStatus{})
+	if after := testutil.CollectAndCount(brokerCPUPercentGauge); after != before-1 {
+		t.Errorf("expected the metrics of the cluster to be removed, got %d metrics instead of %d", after, before-1)
+	}
+}
+
+func TestNewCapacityHeadroomStatus(t *testing.T) {
+	loadStatus := &v1beta1.CruiseControlLoadStatus{
+		Brokers: []v1beta1.BrokerLoadStatus{
+			{BrokerID: "0", DiskPercent: "80.00"},
+			{BrokerID: "1", DiskPercent: "75.00"},
+			{BrokerID: "2", DiskPercent: "40.00"},
+		},
+	}
+
+	testCases := []struct {
+		maxDiskUsagePercent int32
+		expected            *v1beta1.CapacityHeadroomStatus
+	}{
+		{maxDiskUsagePercent: 90},
+		// the average is below the threshold, rebalancing is enough
+		{maxDiskUsagePercent: 76, expected: &v1beta1.CapacityHeadroomStatus{BrokersAboveThreshold: []string{"0"}}},
+		// the 195% of disk usage averages exactly the threshold on 3 brokers, which have to stay below it
+		{maxDiskUsagePercent: 65, expected: &v1beta1.CapacityHeadroomStatus{BrokersAboveThreshold: []string{"0", "1"}, RecommendedAdditionalBrokers: 1}},
+		{maxDiskUsagePercent: 30, expected: &v1beta1.CapacityHeadroomStatus{BrokersAboveThreshold: []string{"0", "1", "2"}, RecommendedAdditionalBrokers: 4}},
+	}
+
+	if status := newCapacityHeadroomStatus(nil, loadStatus); status != nil {
+		t.Errorf("expected no status without capacity headroom, got: %+v", status)
+	}
+	for _, test := range testCases {
+		headroom := &v1beta1.CapacityHeadroomConfig{MaxDiskUsagePercent: test.maxDiskUsagePercent}
+		if status := newCapacityHeadroomStatus(headroom, loadStatus); !reflect.DeepEqual(status, test.expected) {
+			t.Errorf("max disk usage %d%%: expected %+v, got: %+v", test.maxDiskUsagePercent, test.expected, status)
+		}
+	}
+}
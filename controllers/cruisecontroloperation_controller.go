@@ -0,0 +1,275 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// SetupCruiseControlOperationWithManager registers the cruise control operation controller to the manager
+func SetupCruiseControlOperationWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.CruiseControlOperation{}).
+		Named("CruiseControlOperation")
+}
+
+// blank assignment to verify that CruiseControlOperationReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &CruiseControlOperationReconciler{}
+
+// CruiseControlOperationReconciler starts the Cruise Control task of the CruiseControlOperations and tracks it until
+// it finishes. The task id is kept in the status of the operation, so the task is tracked across restarts of the
+// operator. The failed operations are handled according to the operation retry policy of the cluster.
+type CruiseControlOperationReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations/status,verbs=get;update;patch
+
+// Reconcile reconciles the cruise control operation
+func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	instance := &v1alpha1.CruiseControlOperation{}
+	if err := r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if instance.Status.IsFinished() || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, r.Client, instance.Spec.ClusterRef.Name,
+		getClusterRefNamespace(instance.Namespace, instance.Spec.ClusterRef))
+	if err != nil {
+		return requeueWithError(log, "failed to lookup the referenced kafka cluster", err)
+	}
+
+	requester := "CruiseControlOperation/" + request.NamespacedName.String()
+	paused := instance.Status.TaskID == "" &&
+		(kafkav1beta1.IsOperationPaused(instance.Annotations) || kafkav1beta1.IsOperationPaused(cluster.Annotations))
+	if paused != instance.Status.Paused {
+		instance.Status.Paused = paused
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return requeueWithError(log, "failed to update cruisecontroloperation status", err)
+		}
+	}
+	if paused {
+		// the operation is queued again once it is resumed
+		scale.DefaultOperationQueue.Remove(cluster.Namespace, cluster.Name, requester)
+		log.V(1).Info("requeue event as the operation is paused")
+		return requeueAfter(DefaultRequeueAfterTimeInSec)
+	}
+
+	if cluster.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		cluster.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		log.V(1).Info("requeue event as Cruise Control is not deployed (yet)")
+		return requeueAfter(DefaultRequeueAfterTimeInSec)
+	}
+
+	retryPolicy := cluster.Spec.CruiseControlConfig.OperationRetryPolicy
+	if instance.Status.TaskID == "" && retryPolicy.GetErrorPolicy() == kafkav1beta1.CruiseControlErrorPolicyBlock {
+		operations := &v1alpha1.CruiseControlOperationList{}
+		if err := r.Client.List(ctx, operations); err != nil {
+			return requeueWithError(log, "failed to list the cruise control operations", err)
+		}
+		if blocking := blockingCruiseControlOperation(operations.Items, cluster); blocking != nil {
+			log.Info("requeue event as a failed operation of the cluster blocks the new operations",
+				"blockingOperation", blocking.Namespace+"/"+blocking.Name)
+			return requeueAfter(DefaultRequeueAfterTimeInSec)
+		}
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.Scaler(ctx, r.Client, cluster)
+	if err != nil {
+		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+	}
+	scaler = faultinjection.CruiseControlScaler(log, cluster, scaler)
+	scaler = scale.QueuedCruiseControlScaler(scale.DefaultOperationQueue, cluster, requester, scale.TriggerUser, scaler)
+
+	if !scaler.IsUp(ctx) {
+		log.Info("requeue event as Cruise Control is not up (yet)")
+		return requeueAfter(DefaultRequeueAfterTimeInSec)
+	}
+
+	status, err := syncCruiseControlOperation(ctx, scaler, instance, retryPolicy, time.Now())
+	if err != nil {
+		log.Error(err, "requeue event as the state of the task could not be retrieved from Cruise Control",
+			"taskID", instance.Status.TaskID)
+		return requeueAfter(DefaultRequeueAfterTimeInSec)
+	}
+	if !reflect.DeepEqual(status, instance.Status) {
+		instance.Status = status
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return requeueWithError(log, "failed to update cruisecontroloperation status", err)
+		}
+	}
+
+	if status.IsFinished() {
+		log.Info("Cruise Control operation finished", "operation", instance.Spec.Operation,
+			"taskID", status.TaskID, "state", status.State)
+		return reconciled()
+	}
+	return requeueAfter(DefaultRequeueAfterTimeInSec)
+}
+
+// syncCruiseControlOperation starts the task of the operation if it has not been started yet, otherwise it updates
+// the state of the task from Cruise Control. The operation stays pending while Cruise Control is unavailable, and
+// the failed attempts are retried according to the retry policy.
+func syncCruiseControlOperation(ctx context.Context, scaler scale.CruiseControlScaler,
+	instance *v1alpha1.CruiseControlOperation, retryPolicy *kafkav1beta1.CruiseControlOperationRetryPolicy,
+	now time.Time) (v1alpha1.CruiseControlOperationStatus, error) {
+	status := *instance.Status.DeepCopy()
+	if status.IsFinished() {
+		return status, nil
+	}
+
+	if status.TaskID == "" {
+		if retryAt, err := time.Parse(operationTimeFormat, status.NextRetryAt); err == nil && now.Before(retryAt) {
+			return status, nil
+		}
+		result, err := startCruiseControlOperation(ctx, scaler, instance.Spec)
+		switch {
+		case errors.Is(err, scale.ErrCruiseControlUnavailable):
+			status.State = v1alpha1.OperationStatePending
+			status.ErrorMessage = err.Error()
+		case err != nil:
+			status.Attempts++
+			failCruiseControlOperation(&status, retryPolicy, err.Error(), now)
+		default:
+			status.Attempts++
+			status.NextRetryAt = ""
+			applyCruiseControlOperationResult(&status, result, now)
+		}
+		return status, nil
+	}
+
+	results, err := scaler.GetUserTasks(ctx, status.TaskID)
+	if err != nil {
+		return status, err
+	}
+	for _, result := range results {
+		if result.TaskID == status.TaskID {
+			applyCruiseControlOperationResult(&status, result, now)
+		}
+	}
+	if status.State == v1alpha1.OperationStateCompletedWithError {
+		failCruiseControlOperation(&status, retryPolicy, status.ErrorMessage, now)
+	}
+	return status, nil
+}
+
+// failCruiseControlOperation records the failure of the last attempt of the operation, the operation is left failed
+// unless the retry policy lets its task be started again after the backoff
+func failCruiseControlOperation(status *v1alpha1.CruiseControlOperationStatus,
+	retryPolicy *kafkav1beta1.CruiseControlOperationRetryPolicy, errorMessage string, now time.Time) {
+	if status.Attempts < retryPolicy.GetMaxAttempts() {
+		status.State = v1alpha1.OperationStatePending
+		status.ErrorMessage = fmt.Sprintf("attempt %d of %d failed: %s", status.Attempts, retryPolicy.GetMaxAttempts(), errorMessage)
+		status.TaskID = ""
+		status.StartedAt = ""
+		status.FinishedAt = ""
+		status.NextRetryAt = now.Add(retryPolicy.GetBackoff(status.Attempts)).UTC().Format(operationTimeFormat)
+		return
+	}
+	status.State = v1alpha1.OperationStateCompletedWithError
+	status.ErrorMessage = errorMessage
+	status.NextRetryAt = ""
+	if status.FinishedAt == "" {
+		status.FinishedAt = now.UTC().Format(operationTimeFormat)
+	}
+}
+
+// blockingCruiseControlOperation returns the failed operation of the cluster blocking the new operations with the block
+// error policy, or nil if there is none
+func blockingCruiseControlOperation(operations []v1alpha1.CruiseControlOperation,
+	cluster *kafkav1beta1.KafkaCluster) *v1alpha1.CruiseControlOperation {
+	for i := range operations {
+		operation := &operations[i]
+		if operation.Spec.ClusterRef.Name != cluster.Name ||
+			getClusterRefNamespace(operation.Namespace, operation.Spec.ClusterRef) != cluster.Namespace {
+			continue
+		}
+		if operation.Status.State == v1alpha1.OperationStateCompletedWithError &&
+			!k8sutil.IsMarkedForDeletion(operation.ObjectMeta) {
+			return operation
+		}
+	}
+	return nil
+}
+
+// startCruiseControlOperation starts the Cruise Control task of the operation
+func startCruiseControlOperation(ctx context.Context, scaler scale.CruiseControlScaler,
+	spec v1alpha1.CruiseControlOperationSpec) (*scale.Result, error) {
+	params := spec.Parameters
+	opts := scale.OperationOptions{
+		Goals:               params.Goals,
+		ReplicationThrottle: params.ReplicationThrottle,
+	}
+	switch spec.Operation {
+	case v1alpha1.OperationAddBroker:
+		return scaler.AddBrokers(ctx, opts, params.BrokerIDs...)
+	case v1alpha1.OperationRemoveBroker:
+		return scaler.RemoveBrokers(ctx, opts, params.BrokerIDs...)
+	case v1alpha1.OperationRebalance:
+		return scaler.RebalanceWithGoals(ctx, params.BrokerIDs, params.Topics, params.Goals...)
+	case v1alpha1.OperationDemoteBroker:
+		return scaler.DemoteBrokers(ctx, params.BrokerIDs...)
+	default:
+		return nil, errors.Errorf("unsupported Cruise Control operation %q", spec.Operation)
+	}
+}
+
+// applyCruiseControlOperationResult records the state of the task reported by Cruise Control in the status
+func applyCruiseControlOperationResult(status *v1alpha1.CruiseControlOperationStatus, result *scale.Result, now time.Time) {
+	if result == nil {
+		return
+	}
+	status.TaskID = result.TaskID
+	status.StartedAt = result.StartedAt
+	status.State = v1alpha1.CruiseControlOperationState(result.State)
+	status.ErrorMessage = result.Err
+	// Cruise Control does not report when its tasks finish, so the time the task is first seen finished is kept
+	switch {
+	case !status.IsFinished():
+		status.FinishedAt = ""
+	case status.FinishedAt == "":
+		status.FinishedAt = result.FinishedAt
+		if status.FinishedAt == "" {
+			status.FinishedAt = now.UTC().Format(operationTimeFormat)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	fakescale "github.com/banzaicloud/koperator/pkg/scale/fake"
+)
+
+func TestSyncCruiseControlOperation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	scaler := fakescale.NewScaler(fakescale.Broker{ID: "0", State: scale.KafkaBrokerAlive}, fakescale.Broker{ID: "1", State: scale.KafkaBrokerAlive})
+	scaler.TaskProgression = []v1beta1.CruiseControlUserTaskState{v1beta1.CruiseControlTaskInExecution}
+	instance := &v1alpha1.CruiseControlOperation{
+		Spec: v1alpha1.CruiseControlOperationSpec{
+			Operation:  v1alpha1.OperationDemoteBroker,
+			Parameters: v1alpha1.CruiseControlOperationParameters{BrokerIDs: []string{"1"}},
+		},
+	}
+
+	// the task is started once
+	status, err := syncCruiseControlOperation(ctx, scaler, instance, nil, now)
+	if err != nil {
+		t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+	}
+	if status.TaskID == "" || status.State != v1alpha1.OperationStateActive || status.StartedAt == "" {
+		t.Error("Expected the task to be started, got:", status)
+	}
+	tasks := scaler.Tasks()
+	if len(tasks) != 1 || tasks[0].Method != fakescale.MethodDemoteBrokers || tasks[0].BrokerIDs[0] != "1" {
+		t.Fatal("Expected the brokers to be demoted, got:", tasks)
+	}
+
+	// the task is tracked by its id until it finishes
+	instance.Status = status
+	if status, err = syncCruiseControlOperation(ctx, scaler, instance, nil, now); err != nil {
+		t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+	}
+	if status.State != v1alpha1.OperationStateInExecution || status.IsFinished() {
+		t.Error("Expected the task to be in execution, got:", status)
+	}
+	instance.Status = status
+	if status, err = syncCruiseControlOperation(ctx, scaler, instance, nil, now); err != nil {
+		t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+	}
+	if status.State != v1alpha1.OperationStateCompleted || status.FinishedAt == "" {
+		t.Error("Expected the task to be completed, got:", status)
+	}
+
+	// a finished operation is left alone
+	instance.Status = status
+	calls := len(scaler.Calls())
+	if status, err = syncCruiseControlOperation(ctx, scaler, instance, nil, now); err != nil || status != instance.Status {
+		t.Error("Expected the finished operation to be left unchanged, got:", status, err)
+	}
+	if len(scaler.Calls()) != calls || len(scaler.Tasks()) != 1 {
+		t.Error("Expected Cruise Control not to be called for the finished operation")
+	}
+}
+
+func TestSyncCruiseControlOperationFailures(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testName      string
+		spec          v1alpha1.CruiseControlOperationSpec
+		failWith      error
+		expectedState v1alpha1.CruiseControlOperationState
+	}{
+		{
+			testName:      "unknown operation",
+			spec:          v1alpha1.CruiseControlOperationSpec{Operation: "fix_offline_replicas"},
+			expectedState: v1alpha1.OperationStateCompletedWithError,
+		},
+		{
+			testName:      "rebalance rejected",
+			spec:          v1alpha1.CruiseControlOperationSpec{Operation: v1alpha1.OperationRebalance},
+			failWith:      errors.New("goal not supported"),
+			expectedState: v1alpha1.OperationStateCompletedWithError,
+		},
+		{
+			testName:      "cruise control unavailable",
+			spec:          v1alpha1.CruiseControlOperationSpec{Operation: v1alpha1.OperationRebalance},
+			failWith:      errors.WrapIf(scale.ErrCruiseControlUnavailable, "rebalance"),
+			expectedState: v1alpha1.OperationStatePending,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			scaler := fakescale.NewScaler()
+			if test.failWith != nil {
+				scaler.FailNext(fakescale.MethodRebalanceWithGoals, test.failWith)
+			}
+			status, err := syncCruiseControlOperation(ctx, scaler, &v1alpha1.CruiseControlOperation{Spec: test.spec}, nil, now)
+			if err != nil {
+				t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+			}
+			if status.State != test.expectedState || status.ErrorMessage == "" {
+				t.Error("Expected state", test.expectedState, "with the error, got:", status)
+			}
+			if finished := status.FinishedAt != ""; finished != status.IsFinished() {
+				t.Error("Expected the finish time to be set for the finished operations only, got:", status)
+			}
+		})
+	}
+}
+
+func TestApplyCruiseControlOperationResultFinishedAt(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	status := v1alpha1.CruiseControlOperationStatus{}
+
+	applyCruiseControlOperationResult(&status, &scale.Result{TaskID: "task-1", State: v1beta1.CruiseControlTaskInExecution}, now)
+	if status.FinishedAt != "" {
+		t.Errorf("expected no finish time of the task in execution, got: %s", status.FinishedAt)
+	}
+
+	// the task is polled again after it finished, the time it was first seen finished is kept
+	result := &scale.Result{TaskID: "task-1", State: v1beta1.CruiseControlTaskCompleted}
+	applyCruiseControlOperationResult(&status, result, now)
+	applyCruiseControlOperationResult(&status, result, now.Add(time.Minute))
+	if status.FinishedAt != "2022-06-01T12:00:00Z" {
+		t.Errorf("expected the time the task was first seen finished, got: %s", status.FinishedAt)
+	}
+
+	// the finish time known by the scaler is preferred
+	status = v1alpha1.CruiseControlOperationStatus{}
+	result.FinishedAt = "2022-06-01T11:58:00Z"
+	applyCruiseControlOperationResult(&status, result, now)
+	if status.FinishedAt != result.FinishedAt {
+		t.Errorf("expected the finish time reported by the scaler, got: %s", status.FinishedAt)
+	}
+}
+
+func TestSyncCruiseControlOperationRetry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	retryPolicy := &v1beta1.CruiseControlOperationRetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     &metav1.Duration{Duration: time.Minute},
+	}
+
+	scaler := fakescale.NewScaler(fakescale.Broker{ID: "0", State: scale.KafkaBrokerAlive})
+	scaler.TaskProgression = []v1beta1.CruiseControlUserTaskState{v1beta1.CruiseControlTaskCompletedWithError}
+	instance := &v1alpha1.CruiseControlOperation{
+		Spec: v1alpha1.CruiseControlOperationSpec{Operation: v1alpha1.OperationRebalance},
+	}
+
+	// the failed task is started again after the backoff
+	status, err := syncCruiseControlOperation(ctx, scaler, instance, retryPolicy, now)
+	if err != nil {
+		t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+	}
+	instance.Status = status
+	if status, err = syncCruiseControlOperation(ctx, scaler, instance, retryPolicy, now); err != nil {
+		t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+	}
+	if status.State != v1alpha1.OperationStatePending || status.TaskID != "" || status.Attempts != 1 ||
+		status.NextRetryAt != "2022-06-01T12:01:00Z" {
+		t.Error("Expected the failed task to be retried after the backoff, got:", status)
+	}
+	instance.Status = status
+	if status, err = syncCruiseControlOperation(ctx, scaler, instance, retryPolicy, now.Add(30*time.Second)); err != nil {
+		t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+	}
+	if status.TaskID != "" || len(scaler.Tasks()) != 1 {
+		t.Error("Expected the task not to be started again before the backoff, got:", status)
+	}
+
+	// the operation fails once it runs out of attempts
+	instance.Status = status
+	if status, err = syncCruiseControlOperation(ctx, scaler, instance, retryPolicy, now.Add(time.Minute)); err != nil {
+		t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+	}
+	if status.TaskID == "" || status.Attempts != 2 || len(scaler.Tasks()) != 2 {
+		t.Fatal("Expected the task to be started again, got:", status)
+	}
+	instance.Status = status
+	if status, err = syncCruiseControlOperation(ctx, scaler, instance, retryPolicy, now.Add(time.Minute)); err != nil {
+		t.Fatal("Expected no error on syncCruiseControlOperation, got:", err)
+	}
+	if status.State != v1alpha1.OperationStateCompletedWithError || status.NextRetryAt != "" {
+		t.Error("Expected the operation to fail after the last attempt, got:", status)
+	}
+}
+
+func TestBlockingCruiseControlOperation(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	operation := func(name, namespace string, ref v1alpha1.ClusterReference, state v1alpha1.CruiseControlOperationState) v1alpha1.CruiseControlOperation {
+		return v1alpha1.CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1alpha1.CruiseControlOperationSpec{ClusterRef: ref},
+			Status:     v1alpha1.CruiseControlOperationStatus{State: state},
+		}
+	}
+
+	operations := []v1alpha1.CruiseControlOperation{
+		operation("completed", "kafka", v1alpha1.ClusterReference{Name: "kafka"}, v1alpha1.OperationStateCompleted),
+		operation("other-cluster", "kafka", v1alpha1.ClusterReference{Name: "kafka-green"}, v1alpha1.OperationStateCompletedWithError),
+		operation("other-namespace", "default", v1alpha1.ClusterReference{Name: "kafka"}, v1alpha1.OperationStateCompletedWithError),
+	}
+	if blocking := blockingCruiseControlOperation(operations, cluster); blocking != nil {
+		t.Error("Expected no blocking operation, got:", blocking.Name)
+	}
+
+	operations = append(operations,
+		operation("failed", "default", v1alpha1.ClusterReference{Name: "kafka", Namespace: "kafka"}, v1alpha1.OperationStateCompletedWithError))
+	if blocking := blockingCruiseControlOperation(operations, cluster); blocking == nil || blocking.Name != "failed" {
+		t.Error("Expected the failed operation of the cluster to block, got:", blocking)
+	}
+}
+
+func TestReconcilePausedCruiseControlOperation(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka",
+		Annotations: map[string]string{v1beta1.OperationPausedAnnotation: "true"}}}
+	operation := &v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "rebalance", Namespace: "kafka"},
+		Spec: v1alpha1.CruiseControlOperationSpec{ClusterRef: v1alpha1.ClusterReference{Name: "kafka"},
+			Operation: v1alpha1.OperationRebalance},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, operation).Build()
+	r := &CruiseControlOperationReconciler{Client: c, Scheme: scheme}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "kafka", Name: "rebalance"}}
+
+	if _, err := r.Reconcile(ctx, request); err != nil {
+		t.Fatal("Expected no error on Reconcile, got:", err)
+	}
+	if err := c.Get(ctx, request.NamespacedName, operation); err != nil {
+		t.Fatal(err)
+	}
+	if !operation.Status.Paused || operation.Status.State != "" {
+		t.Error("Expected the operation to be paused by the annotation of the cluster, got:", operation.Status)
+	}
+
+	// the operation is resumed once the annotation is removed, it stays pending as Cruise Control is not deployed
+	cluster.Annotations = nil
+	if err := c.Update(ctx, cluster); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(ctx, request); err != nil {
+		t.Fatal("Expected no error on Reconcile, got:", err)
+	}
+	if err := c.Get(ctx, request.NamespacedName, operation); err != nil {
+		t.Fatal(err)
+	}
+	if operation.Status.Paused {
+		t.Error("Expected the operation to be resumed, got:", operation.Status)
+	}
+}
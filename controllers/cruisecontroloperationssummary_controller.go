@@ -0,0 +1,295 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+const (
+	// DefaultOperationsSummaryIntervalInSec is the period of summarizing the Cruise Control operations of the clusters
+	DefaultOperationsSummaryIntervalInSec = 30
+
+	// recentlyFinishedOperationsLimit is the number of finished operations kept in the summary
+	recentlyFinishedOperationsLimit = 10
+	// operatorOperationSource is the source of the tasks of the brokers started by the operator
+	operatorOperationSource = "operator"
+)
+
+// CruiseControlOperationsSummaryReconciler summarizes the in-flight and the recently finished Cruise Control
+// operations of the kafka clusters in status.cruiseControlOperations. The tasks of the brokers are taken from the
+// broker states and the operation history of the cluster, the rest from the CruiseControlOperations of the cluster.
+type CruiseControlOperationsSummaryReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations,verbs=get;list;watch
+
+func (r *CruiseControlOperationsSummaryReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	operations := &v1alpha1.CruiseControlOperationList{}
+	if err := r.List(ctx, operations); err != nil {
+		return requeueWithError(log, "failed to list the cruise control operations", err)
+	}
+
+	summary := summarizeCruiseControlOperations(instance, operations.Items, time.Now())
+	if !reflect.DeepEqual(summary, instance.Status.CruiseControlOperations) {
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, summary, log); err != nil {
+			return requeueWithError(log, "failed to update the cruise control operations in the Kafka Cluster status", err)
+		}
+	}
+	return requeueAfter(DefaultOperationsSummaryIntervalInSec)
+}
+
+// summarizeCruiseControlOperations returns the in-flight and the recently finished Cruise Control operations of the
+// cluster, nil if there are none
+func summarizeCruiseControlOperations(instance *kafkav1beta1.KafkaCluster, operations []v1alpha1.CruiseControlOperation,
+	now time.Time) *kafkav1beta1.CruiseControlOperationsStatus {
+	var started, pending, finished []kafkav1beta1.CruiseControlOperationSummary
+
+	for _, record := range instance.Status.OperationHistory {
+		if record.Type == kafkav1beta1.OperationTypeRollingUpgrade {
+			continue
+		}
+		summary := kafkav1beta1.CruiseControlOperationSummary{
+			Type:       record.Type,
+			Source:     operatorOperationSource,
+			Brokers:    record.Brokers,
+			TaskID:     record.TaskID,
+			State:      record.Outcome,
+			StartedAt:  record.StartedAt,
+			FinishedAt: record.FinishedAt,
+			Duration:   operationDuration(record.StartedAt, record.FinishedAt, now),
+			Error:      record.Error,
+		}
+		if record.Outcome == kafkav1beta1.OperationRunning {
+			started = append(started, summary)
+		} else {
+			finished = append(finished, summary)
+		}
+	}
+	pending = append(pending, pendingBrokerTasks(instance)...)
+
+	for i := range operations {
+		operation := &operations[i]
+		if operation.Spec.ClusterRef.Name != instance.Name ||
+			getClusterRefNamespace(operation.Namespace, operation.Spec.ClusterRef) != instance.Namespace {
+			continue
+		}
+		summary := kafkav1beta1.CruiseControlOperationSummary{
+			Type:       cruiseControlOperationType(operation.Spec.Operation),
+			Source:     operation.Namespace + "/" + operation.Name,
+			Brokers:    operation.Spec.Parameters.BrokerIDs,
+			TaskID:     operation.Status.TaskID,
+			StartedAt:  operation.Status.StartedAt,
+			FinishedAt: operation.Status.FinishedAt,
+			Error:      operation.Status.ErrorMessage,
+		}
+		switch {
+		case operation.Status.Paused:
+			summary.State = kafkav1beta1.OperationPaused
+			pending = append(pending, summary)
+		case operation.Status.State == v1alpha1.OperationStateCompleted:
+			summary.State = kafkav1beta1.OperationSucceeded
+			summary.Duration = operationDuration(summary.StartedAt, summary.FinishedAt, now)
+			finished = append(finished, summary)
+		case operation.Status.State == v1alpha1.OperationStateCompletedWithError && operation.Status.NextRetryAt == "":
+			summary.State = kafkav1beta1.OperationFailed
+			summary.Duration = operationDuration(summary.StartedAt, summary.FinishedAt, now)
+			finished = append(finished, summary)
+		case operation.Status.TaskID == "" || operation.Status.NextRetryAt != "":
+			summary.State = kafkav1beta1.OperationPending
+			summary.TaskID, summary.StartedAt, summary.FinishedAt = "", "", ""
+			pending = append(pending, summary)
+		default:
+			summary.State = kafkav1beta1.OperationRunning
+			summary.Duration = operationDuration(summary.StartedAt, "", now)
+			started = append(started, summary)
+		}
+	}
+
+	sortOperationSummaries(started, func(s kafkav1beta1.CruiseControlOperationSummary) string { return s.StartedAt }, false)
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].Type != pending[j].Type {
+			return pending[i].Type < pending[j].Type
+		}
+		return pending[i].Source < pending[j].Source
+	})
+	sortOperationSummaries(finished, func(s kafkav1beta1.CruiseControlOperationSummary) string { return s.FinishedAt }, true)
+	if len(finished) > recentlyFinishedOperationsLimit {
+		finished = finished[:recentlyFinishedOperationsLimit]
+	}
+
+	if len(started) == 0 && len(pending) == 0 && len(finished) == 0 {
+		return nil
+	}
+	return &kafkav1beta1.CruiseControlOperationsStatus{
+		InFlight:         append(started, pending...),
+		RecentlyFinished: finished,
+	}
+}
+
+// pendingBrokerTasks returns the tasks of the brokers the operator is to start, one for each type of task
+func pendingBrokerTasks(instance *kafkav1beta1.KafkaCluster) []kafkav1beta1.CruiseControlOperationSummary {
+	brokersByType := make(map[kafkav1beta1.OperationType][]string)
+	for brokerID, brokerState := range instance.Status.BrokersState {
+		state := brokerState.GracefulActionState
+		switch {
+		case state.CruiseControlState.IsRequiredState() && state.CruiseControlState.IsUpscale():
+			brokersByType[kafkav1beta1.OperationTypeAddBroker] = append(brokersByType[kafkav1beta1.OperationTypeAddBroker], brokerID)
+		case state.CruiseControlState.IsRequiredState() && state.CruiseControlState.IsDownscale():
+			brokersByType[kafkav1beta1.OperationTypeRemoveBroker] = append(brokersByType[kafkav1beta1.OperationTypeRemoveBroker], brokerID)
+		}
+		for _, volumeState := range state.VolumeStates {
+			if volumeState.CruiseControlVolumeState == kafkav1beta1.GracefulDiskRebalanceRequired &&
+				!util.StringSliceContains(brokersByType[kafkav1beta1.OperationTypeRebalanceDisks], brokerID) {
+				brokersByType[kafkav1beta1.OperationTypeRebalanceDisks] = append(brokersByType[kafkav1beta1.OperationTypeRebalanceDisks], brokerID)
+			}
+		}
+		if state.MaintenanceState != nil &&
+			state.MaintenanceState.CruiseControlMaintenanceState == kafkav1beta1.GracefulDemotionRequired {
+			brokersByType[kafkav1beta1.OperationTypeDemoteBroker] = append(brokersByType[kafkav1beta1.OperationTypeDemoteBroker], brokerID)
+		}
+	}
+
+	pending := make([]kafkav1beta1.CruiseControlOperationSummary, 0, len(brokersByType))
+	for operationType, brokerIDs := range brokersByType {
+		sort.Strings(brokerIDs)
+		pending = append(pending, kafkav1beta1.CruiseControlOperationSummary{
+			Type:    operationType,
+			Source:  operatorOperationSource,
+			Brokers: brokerIDs,
+			State:   kafkav1beta1.OperationPending,
+		})
+	}
+	return pending
+}
+
+// cruiseControlOperationType returns the operation type of the CruiseControlOperation
+func cruiseControlOperationType(operation v1alpha1.CruiseControlOperationType) kafkav1beta1.OperationType {
+	switch operation {
+	case v1alpha1.OperationAddBroker:
+		return kafkav1beta1.OperationTypeAddBroker
+	case v1alpha1.OperationRemoveBroker:
+		return kafkav1beta1.OperationTypeRemoveBroker
+	case v1alpha1.OperationDemoteBroker:
+		return kafkav1beta1.OperationTypeDemoteBroker
+	default:
+		return kafkav1beta1.OperationTypeRebalance
+	}
+}
+
+// parseOperationTime parses the time of an operation recorded in operationTimeFormat
+func parseOperationTime(value string) (time.Time, bool) {
+	t, err := time.Parse(operationTimeFormat, value)
+	return t, err == nil
+}
+
+// operationDuration returns the time the operation took, or the time it has been running for rounded to minutes
+// when it has not finished yet, so the summary does not change on every reconcile
+func operationDuration(startedAt, finishedAt string, now time.Time) string {
+	started, ok := parseOperationTime(startedAt)
+	if !ok {
+		return ""
+	}
+	if finishedAt == "" {
+		if elapsed := now.Sub(started).Round(time.Minute); elapsed >= 0 {
+			return elapsed.String()
+		}
+		return ""
+	}
+	finished, ok := parseOperationTime(finishedAt)
+	if !ok || finished.Before(started) {
+		return ""
+	}
+	return finished.Sub(started).Round(time.Second).String()
+}
+
+// sortOperationSummaries sorts the operations by the time returned by timeOf, oldest or newest first, the ones the
+// time of which is not known last
+func sortOperationSummaries(summaries []kafkav1beta1.CruiseControlOperationSummary,
+	timeOf func(kafkav1beta1.CruiseControlOperationSummary) string, newestFirst bool) {
+	sort.SliceStable(summaries, func(i, j int) bool {
+		ti, iok := parseOperationTime(timeOf(summaries[i]))
+		tj, jok := parseOperationTime(timeOf(summaries[j]))
+		if iok != jok {
+			return iok
+		}
+		if newestFirst {
+			return iok && ti.After(tj)
+		}
+		return iok && ti.Before(tj)
+	})
+}
+
+// SetupCruiseControlOperationsSummaryWithManager registers the Cruise Control operations summary controller to the
+// manager
+func SetupCruiseControlOperationsSummaryWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("CruiseControlOperationsSummary")
+
+	// the operations are summarized periodically, only the creation, the deletion and the spec changes of the
+	// clusters trigger the summary in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,98 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestSummarizeCruiseControlOperations(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"3": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleRequired}},
+				"4": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleRequired}},
+				"0": {GracefulActionState: v1beta1.GracefulActionState{VolumeStates: map[string]v1beta1.VolumeState{
+					"/kafka-logs":   {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired},
+					"/kafka-logs-2": {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired},
+				}}},
+			},
+			OperationHistory: []v1beta1.OperationRecord{
+				{Type: v1beta1.OperationTypeRollingUpgrade, StartedAt: "2022-06-01T09:00:00Z", Outcome: v1beta1.OperationRunning},
+				{Type: v1beta1.OperationTypeRemoveBroker, Brokers: []string{"2"}, TaskID: "task-1",
+					StartedAt: "2022-06-01T10:00:00Z", FinishedAt: "2022-06-01T10:30:15Z", Outcome: v1beta1.OperationSucceeded},
+				{Type: v1beta1.OperationTypeAddBroker, Brokers: []string{"1"}, TaskID: "task-2",
+					StartedAt: "2022-06-01T11:20:10Z", Outcome: v1beta1.OperationRunning},
+			},
+		},
+	}
+	operations := []v1alpha1.CruiseControlOperation{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "rebalance", Namespace: "kafka"},
+			Spec:       v1alpha1.CruiseControlOperationSpec{ClusterRef: v1alpha1.ClusterReference{Name: "kafka"}, Operation: v1alpha1.OperationRebalance},
+			Status: v1alpha1.CruiseControlOperationStatus{TaskID: "task-0", State: v1alpha1.OperationStateCompletedWithError,
+				StartedAt: "2022-05-31T09:00:00Z", FinishedAt: "2022-05-31T09:10:00Z", ErrorMessage: "no proposals"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "paused", Namespace: "kafka"},
+			Spec:       v1alpha1.CruiseControlOperationSpec{ClusterRef: v1alpha1.ClusterReference{Name: "kafka"}, Operation: v1alpha1.OperationDemoteBroker},
+			Status:     v1alpha1.CruiseControlOperationStatus{Paused: true},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-cluster", Namespace: "kafka"},
+			Spec:       v1alpha1.CruiseControlOperationSpec{ClusterRef: v1alpha1.ClusterReference{Name: "other"}, Operation: v1alpha1.OperationRebalance},
+		},
+	}
+
+	summary := summarizeCruiseControlOperations(cluster, operations, now)
+	if summary == nil {
+		t.Fatal("expected a summary of the operations")
+	}
+
+	inFlight := []v1beta1.CruiseControlOperationSummary{
+		{Type: v1beta1.OperationTypeAddBroker, Source: "operator", Brokers: []string{"1"}, TaskID: "task-2",
+			State: v1beta1.OperationRunning, StartedAt: "2022-06-01T11:20:10Z", Duration: "40m0s"},
+		{Type: v1beta1.OperationTypeAddBroker, Source: "operator", Brokers: []string{"3", "4"}, State: v1beta1.OperationPending},
+		{Type: v1beta1.OperationTypeDemoteBroker, Source: "kafka/paused", State: v1beta1.OperationPaused},
+		{Type: v1beta1.OperationTypeRebalanceDisks, Source: "operator", Brokers: []string{"0"}, State: v1beta1.OperationPending},
+	}
+	if !reflect.DeepEqual(summary.InFlight, inFlight) {
+		t.Errorf("expected in-flight operations:\n%+v\ngot:\n%+v", inFlight, summary.InFlight)
+	}
+
+	finished := []v1beta1.CruiseControlOperationSummary{
+		{Type: v1beta1.OperationTypeRemoveBroker, Source: "operator", Brokers: []string{"2"}, TaskID: "task-1",
+			State: v1beta1.OperationSucceeded, StartedAt: "2022-06-01T10:00:00Z", FinishedAt: "2022-06-01T10:30:15Z", Duration: "30m15s"},
+		{Type: v1beta1.OperationTypeRebalance, Source: "kafka/rebalance", TaskID: "task-0", State: v1beta1.OperationFailed,
+			StartedAt: "2022-05-31T09:00:00Z", FinishedAt: "2022-05-31T09:10:00Z", Duration: "10m0s",
+			Error: "no proposals"},
+	}
+	if !reflect.DeepEqual(summary.RecentlyFinished, finished) {
+		t.Errorf("expected recently finished operations:\n%+v\ngot:\n%+v", finished, summary.RecentlyFinished)
+	}
+
+	if summary := summarizeCruiseControlOperations(&v1beta1.KafkaCluster{}, nil, now); summary != nil {
+		t.Errorf("expected no summary without operations, got: %+v", summary)
+	}
+}
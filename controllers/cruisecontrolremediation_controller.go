@@ -0,0 +1,229 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const (
+	// DefaultRemediationIntervalInSec is the period of checking the goal violations detected by Cruise Control
+	DefaultRemediationIntervalInSec = 60
+
+	remediatedAtTimeFormat = "2006-01-02 15:04:05"
+)
+
+// CruiseControlRemediationReconciler periodically rebalances the kafka clusters when Cruise Control detects the
+// violation of the goals listed in the goal violation remediation policy of the cluster
+type CruiseControlRemediationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *CruiseControlRemediationReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	policy := instance.Spec.CruiseControlConfig.GoalViolationRemediation
+	if policy == nil || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	if instance.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		instance.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		log.V(1).Info("requeue event as Cruise Control is not deployed (yet)")
+		return requeueAfter(DefaultRemediationIntervalInSec)
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, instance)
+	if err != nil {
+		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+	scaler = scale.QueuedCruiseControlScaler(scale.DefaultOperationQueue, instance, "CruiseControlRemediation", scale.TriggerPeriodic, scaler)
+
+	if !scaler.IsUp(ctx) {
+		log.Info("requeue event as Cruise Control is not up (yet)")
+		return requeueAfter(DefaultRemediationIntervalInSec)
+	}
+
+	if scaler.Status(ctx).InExecution() {
+		log.V(1).Info("requeue event as Cruise Control is executing a task")
+		return requeueAfter(DefaultRemediationIntervalInSec)
+	}
+
+	violations, err := scaler.GoalViolations(ctx)
+	if err != nil {
+		log.Info("requeue event as getting the goal violations from Cruise Control failed", "error", err.Error())
+		return requeueAfter(DefaultRemediationIntervalInSec)
+	}
+
+	now := time.Now()
+	anomalies := selectRemediatedGoals(policy, instance.Status.GoalViolationRemediation, violations, now)
+	if len(anomalies) == 0 {
+		return requeueAfter(DefaultRemediationIntervalInSec)
+	}
+
+	goals := make([]string, 0, len(anomalies))
+	for _, goal := range policy.Goals {
+		if _, ok := anomalies[goal.Name]; ok {
+			goals = append(goals, goal.Name)
+		}
+	}
+
+	log.Info("remediating goal violations detected by Cruise Control", "goals", goals)
+	result, err := scaler.RebalanceWithGoals(ctx, instance.Spec.GetBrokerIDsInMaintenance(), "", goals...)
+	if errors.Is(err, scale.ErrOperationQueued) {
+		// the violations are remediated once the other operations of the cluster are started
+		log.V(1).Info("requeue event as the remediation is queued", "reason", err.Error())
+		return requeueAfter(DefaultRemediationIntervalInSec)
+	}
+	if err != nil {
+		log.Error(err, "rebalance remediating goal violations could not be started", "goals", goals)
+	}
+
+	remediationStatus := newGoalViolationRemediationStatus(policy, instance.Status.GoalViolationRemediation, anomalies, result, err, now)
+	if err := k8sutil.UpdateCRStatus(r.Client, instance, remediationStatus, log); err != nil {
+		return requeueWithError(log, "failed to update the goal violation remediations in the Kafka Cluster status", err)
+	}
+	return requeueAfter(DefaultRemediationIntervalInSec)
+}
+
+// selectRemediatedGoals returns the goals of the policy to be remediated mapped to the ID of their latest violation.
+// A goal is remediated when it has a fixable violation detected after its last remediation and the cooldown of the
+// goal has elapsed since then.
+func selectRemediatedGoals(policy *kafkav1beta1.GoalViolationRemediation, status *kafkav1beta1.GoalViolationRemediationStatus,
+	violations []scale.GoalViolation, now time.Time) map[string]string {
+	anomalies := make(map[string]string)
+	for _, goal := range policy.Goals {
+		var lastAnomalyID string
+		var lastRemediatedAt time.Time
+		if last := status.GetGoal(goal.Name); last != nil {
+			lastAnomalyID = last.AnomalyID
+			if remediatedAt, err := time.ParseInLocation(remediatedAtTimeFormat, last.RemediatedAt, time.Local); err == nil {
+				lastRemediatedAt = remediatedAt
+			}
+			if now.Sub(lastRemediatedAt) < goal.GetCooldown() {
+				continue
+			}
+		}
+
+		var latest *scale.GoalViolation
+		for i := range violations {
+			violation := &violations[i]
+			if violation.AnomalyID == lastAnomalyID || !violation.DetectedAt.After(lastRemediatedAt) ||
+				!containsGoal(violation.FixableGoals, goal.Name) {
+				continue
+			}
+			if latest == nil || violation.DetectedAt.After(latest.DetectedAt) {
+				latest = violation
+			}
+		}
+		if latest != nil {
+			anomalies[goal.Name] = latest.AnomalyID
+		}
+	}
+	return anomalies
+}
+
+// newGoalViolationRemediationStatus returns the remediation status of the goals of the policy with the given
+// remediated anomalies recorded, a failed rebalance is recorded as well so that it is retried only after the cooldown
+func newGoalViolationRemediationStatus(policy *kafkav1beta1.GoalViolationRemediation, current *kafkav1beta1.GoalViolationRemediationStatus,
+	anomalies map[string]string, result *scale.Result, err error, now time.Time) *kafkav1beta1.GoalViolationRemediationStatus {
+	status := &kafkav1beta1.GoalViolationRemediationStatus{}
+	for _, goal := range policy.Goals {
+		anomalyID, ok := anomalies[goal.Name]
+		if !ok {
+			if last := current.GetGoal(goal.Name); last != nil {
+				status.Goals = append(status.Goals, *last)
+			}
+			continue
+		}
+
+		goalStatus := kafkav1beta1.RemediatedGoalStatus{
+			Name:         goal.Name,
+			AnomalyID:    anomalyID,
+			RemediatedAt: now.Format(remediatedAtTimeFormat),
+		}
+		if err != nil {
+			goalStatus.Error = err.Error()
+		} else if result != nil {
+			goalStatus.TaskID = result.TaskID
+		}
+		status.Goals = append(status.Goals, goalStatus)
+	}
+	return status
+}
+
+func containsGoal(goals []string, goal string) bool {
+	for _, g := range goals {
+		if g == goal {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupCruiseControlRemediationWithManager registers the cruise control remediation controller to the manager
+func SetupCruiseControlRemediationWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("CruiseControlRemediation")
+
+	// the goal violations are checked periodically, only the creation, the deletion and the spec changes of the
+	// clusters trigger the checks in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,123 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestSelectRemediatedGoals(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 0, 0, 0, time.Local)
+	policy := &v1beta1.GoalViolationRemediation{
+		Goals: []v1beta1.RemediatedGoal{
+			{Name: "DiskUsageDistributionGoal"},
+			{Name: "CpuCapacityGoal", Cooldown: &metav1.Duration{Duration: 10 * time.Minute}},
+		},
+	}
+
+	testCases := []struct {
+		testName   string
+		status     *v1beta1.GoalViolationRemediationStatus
+		violations []scale.GoalViolation
+		expected   map[string]string
+	}{
+		{
+			testName: "no violations",
+			expected: map[string]string{},
+		},
+		{
+			testName: "latest violation of configured goals",
+			violations: []scale.GoalViolation{
+				{AnomalyID: "a1", DetectedAt: now.Add(-30 * time.Minute), FixableGoals: []string{"DiskUsageDistributionGoal"}},
+				{AnomalyID: "a2", DetectedAt: now.Add(-5 * time.Minute), FixableGoals: []string{"DiskUsageDistributionGoal", "CpuCapacityGoal"}},
+				{AnomalyID: "a3", DetectedAt: now.Add(-time.Minute), FixableGoals: []string{"RackAwareGoal"}},
+			},
+			expected: map[string]string{"DiskUsageDistributionGoal": "a2", "CpuCapacityGoal": "a2"},
+		},
+		{
+			testName: "cooldown not elapsed",
+			status: &v1beta1.GoalViolationRemediationStatus{
+				Goals: []v1beta1.RemediatedGoalStatus{
+					{Name: "DiskUsageDistributionGoal", AnomalyID: "a0", RemediatedAt: now.Add(-30 * time.Minute).Format("2006-01-02 15:04:05")},
+					{Name: "CpuCapacityGoal", AnomalyID: "a0", RemediatedAt: now.Add(-30 * time.Minute).Format("2006-01-02 15:04:05")},
+				},
+			},
+			violations: []scale.GoalViolation{
+				{AnomalyID: "a1", DetectedAt: now.Add(-time.Minute), FixableGoals: []string{"DiskUsageDistributionGoal", "CpuCapacityGoal"}},
+			},
+			expected: map[string]string{"CpuCapacityGoal": "a1"},
+		},
+		{
+			testName: "violations already remediated",
+			status: &v1beta1.GoalViolationRemediationStatus{
+				Goals: []v1beta1.RemediatedGoalStatus{
+					{Name: "CpuCapacityGoal", AnomalyID: "a1", RemediatedAt: now.Add(-30 * time.Minute).Format("2006-01-02 15:04:05")},
+				},
+			},
+			violations: []scale.GoalViolation{
+				{AnomalyID: "a0", DetectedAt: now.Add(-40 * time.Minute), FixableGoals: []string{"CpuCapacityGoal"}},
+				{AnomalyID: "a1", DetectedAt: now.Add(-31 * time.Minute), FixableGoals: []string{"CpuCapacityGoal"}},
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			anomalies := selectRemediatedGoals(policy, test.status, test.violations, now)
+			if !reflect.DeepEqual(anomalies, test.expected) {
+				t.Errorf("expected remediated goals: %v, got: %v", test.expected, anomalies)
+			}
+		})
+	}
+}
+
+func TestNewGoalViolationRemediationStatus(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 0, 0, 0, time.Local)
+	policy := &v1beta1.GoalViolationRemediation{
+		Goals: []v1beta1.RemediatedGoal{{Name: "DiskUsageDistributionGoal"}, {Name: "CpuCapacityGoal"}},
+	}
+	current := &v1beta1.GoalViolationRemediationStatus{
+		Goals: []v1beta1.RemediatedGoalStatus{
+			{Name: "CpuCapacityGoal", AnomalyID: "a0", RemediatedAt: "2022-05-04 10:00:00", TaskID: "t0"},
+			{Name: "RackAwareGoal", AnomalyID: "a0", RemediatedAt: "2022-05-04 10:00:00", TaskID: "t0"},
+		},
+	}
+	anomalies := map[string]string{"DiskUsageDistributionGoal": "a1"}
+
+	expected := &v1beta1.GoalViolationRemediationStatus{
+		Goals: []v1beta1.RemediatedGoalStatus{
+			{Name: "DiskUsageDistributionGoal", AnomalyID: "a1", RemediatedAt: "2022-05-04 12:00:00", TaskID: "t1"},
+			{Name: "CpuCapacityGoal", AnomalyID: "a0", RemediatedAt: "2022-05-04 10:00:00", TaskID: "t0"},
+		},
+	}
+	status := newGoalViolationRemediationStatus(policy, current, anomalies, &scale.Result{TaskID: "t1"}, nil, now)
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected remediation status: %+v, got: %+v", expected, status)
+	}
+
+	status = newGoalViolationRemediationStatus(policy, current, anomalies, nil, errors.New("rebalance failed"), now)
+	if goal := status.GetGoal("DiskUsageDistributionGoal"); goal == nil || goal.Error != "rebalance failed" || goal.TaskID != "" {
+		t.Errorf("expected failed remediation to be recorded, got: %+v", goal)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const (
+	// DefaultRightsizeIntervalInSec is the period of checking whether the rightsize request of a cluster has to be
+	// submitted to Cruise Control
+	DefaultRightsizeIntervalInSec = 60
+
+	rightsizedAtTimeFormat = "2006-01-02 15:04:05"
+)
+
+// CruiseControlRightsizeReconciler submits the rightsize request of the kafka clusters to the provisioner of Cruise
+// Control whenever it changes, and records the recommendation of the provisioner in the status of the cluster
+type CruiseControlRightsizeReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *CruiseControlRightsizeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	rightsize := instance.Spec.CruiseControlConfig.Rightsize
+	if rightsize == nil || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	opts := rightsizeOptions(rightsize, instance.Status.CapacityHeadroom)
+	if !rightsizeRequired(opts, instance.Status.CruiseControlRightsize) {
+		return requeueAfter(DefaultRightsizeIntervalInSec)
+	}
+
+	if instance.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		instance.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		log.V(1).Info("requeue event as Cruise Control is not deployed (yet)")
+		return requeueAfter(DefaultRightsizeIntervalInSec)
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, instance)
+	if err != nil {
+		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+
+	if !scaler.IsUp(ctx) {
+		log.Info("requeue event as Cruise Control is not up (yet)")
+		return requeueAfter(DefaultRightsizeIntervalInSec)
+	}
+
+	log.Info("submitting rightsize request to Cruise Control", "brokersToAdd", opts.BrokersToAdd,
+		"partitionCount", opts.PartitionCount, "topic", opts.Topic)
+	result, err := scaler.Rightsize(ctx, opts)
+	if err != nil {
+		log.Error(err, "rightsize request could not be submitted to Cruise Control")
+	}
+
+	rightsizeStatus := newCruiseControlRightsizeStatus(opts, result, err, time.Now())
+	if err := k8sutil.UpdateCRStatus(r.Client, instance, rightsizeStatus, log); err != nil {
+		return requeueWithError(log, "failed to update the rightsize request in the Kafka Cluster status", err)
+	}
+	return requeueAfter(DefaultRightsizeIntervalInSec)
+}
+
+// rightsizeOptions returns the rightsize request of the cluster, the brokers recommended by the capacity headroom
+// status are requested when the request is derived from it
+func rightsizeOptions(rightsize *kafkav1beta1.CruiseControlRightsize, headroom *kafkav1beta1.CapacityHeadroomStatus) scale.RightsizeOptions {
+	opts := scale.RightsizeOptions{
+		BrokersToAdd:   rightsize.BrokersToAdd,
+		PartitionCount: rightsize.PartitionCount,
+		Topic:          rightsize.Topic,
+	}
+	if rightsize.FromCapacityHeadroom {
+		opts.BrokersToAdd = 0
+		if headroom != nil {
+			opts.BrokersToAdd = headroom.RecommendedAdditionalBrokers
+		}
+	}
+	return opts
+}
+
+// rightsizeRequired returns true if the rightsize request has something to request and it differs from the last
+// request submitted, or the last request failed
+func rightsizeRequired(opts scale.RightsizeOptions, last *kafkav1beta1.CruiseControlRightsizeStatus) bool {
+	if opts.BrokersToAdd == 0 && opts.PartitionCount == 0 {
+		return false
+	}
+	return last == nil || last.Error != "" || last.BrokersToAdd != opts.BrokersToAdd ||
+		last.PartitionCount != opts.PartitionCount || last.Topic != opts.Topic
+}
+
+// newCruiseControlRightsizeStatus returns the status of the submitted rightsize request with the recommendation of
+// the provisioner, or with the error if the request failed
+func newCruiseControlRightsizeStatus(opts scale.RightsizeOptions, result *scale.RightsizeResult, err error,
+	now time.Time) *kafkav1beta1.CruiseControlRightsizeStatus {
+	status := &kafkav1beta1.CruiseControlRightsizeStatus{
+		BrokersToAdd:   opts.BrokersToAdd,
+		PartitionCount: opts.PartitionCount,
+		Topic:          opts.Topic,
+		RequestedAt:    now.Format(rightsizedAtTimeFormat),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	} else if result != nil {
+		status.RecommendedBrokersToAdd = result.BrokersToAdd
+		status.RecommendedPartitionCount = result.PartitionCount
+		status.ProvisionerState = result.ProvisionerState
+	}
+	return status
+}
+
+// SetupCruiseControlRightsizeWithManager registers the cruise control rightsize controller to the manager
+func SetupCruiseControlRightsizeWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("CruiseControlRightsize")
+
+	// the rightsize requests are checked periodically, only the creation, the deletion and the spec changes of the
+	// clusters trigger the checks in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,116 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestRightsizeRequired(t *testing.T) {
+	headroom := &v1beta1.CapacityHeadroomStatus{BrokersAboveThreshold: []string{"0"}, RecommendedAdditionalBrokers: 2}
+
+	testCases := []struct {
+		testName  string
+		rightsize *v1beta1.CruiseControlRightsize
+		headroom  *v1beta1.CapacityHeadroomStatus
+		last      *v1beta1.CruiseControlRightsizeStatus
+		expected  bool
+	}{
+		{
+			testName:  "nothing to request",
+			rightsize: &v1beta1.CruiseControlRightsize{Topic: "events"},
+		},
+		{
+			testName:  "first request",
+			rightsize: &v1beta1.CruiseControlRightsize{PartitionCount: 12, Topic: "events"},
+			expected:  true,
+		},
+		{
+			testName:  "request already submitted",
+			rightsize: &v1beta1.CruiseControlRightsize{PartitionCount: 12, Topic: "events"},
+			last:      &v1beta1.CruiseControlRightsizeStatus{PartitionCount: 12, Topic: "events", ProvisionerState: "COMPLETED"},
+		},
+		{
+			testName:  "request changed",
+			rightsize: &v1beta1.CruiseControlRightsize{PartitionCount: 24, Topic: "events"},
+			last:      &v1beta1.CruiseControlRightsizeStatus{PartitionCount: 12, Topic: "events"},
+			expected:  true,
+		},
+		{
+			testName:  "last request failed",
+			rightsize: &v1beta1.CruiseControlRightsize{BrokersToAdd: 1},
+			last:      &v1beta1.CruiseControlRightsizeStatus{BrokersToAdd: 1, Error: "cruise control is unavailable"},
+			expected:  true,
+		},
+		{
+			testName:  "capacity headroom not breached",
+			rightsize: &v1beta1.CruiseControlRightsize{BrokersToAdd: 1, FromCapacityHeadroom: true},
+		},
+		{
+			testName:  "capacity headroom breached",
+			rightsize: &v1beta1.CruiseControlRightsize{BrokersToAdd: 1, FromCapacityHeadroom: true},
+			headroom:  headroom,
+			last:      &v1beta1.CruiseControlRightsizeStatus{BrokersToAdd: 1},
+			expected:  true,
+		},
+		{
+			testName:  "capacity headroom recommendation already submitted",
+			rightsize: &v1beta1.CruiseControlRightsize{FromCapacityHeadroom: true},
+			headroom:  headroom,
+			last:      &v1beta1.CruiseControlRightsizeStatus{BrokersToAdd: 2},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			opts := rightsizeOptions(test.rightsize, test.headroom)
+			if required := rightsizeRequired(opts, test.last); required != test.expected {
+				t.Errorf("expected rightsize required: %v, got: %v", test.expected, required)
+			}
+		})
+	}
+}
+
+func TestNewCruiseControlRightsizeStatus(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 0, 0, 0, time.Local)
+	opts := scale.RightsizeOptions{BrokersToAdd: 2}
+
+	expected := &v1beta1.CruiseControlRightsizeStatus{
+		BrokersToAdd:            2,
+		RequestedAt:             "2022-05-04 12:00:00",
+		RecommendedBrokersToAdd: 3,
+		ProvisionerState:        "IN_PROGRESS",
+	}
+	status := newCruiseControlRightsizeStatus(opts, &scale.RightsizeResult{BrokersToAdd: 3, ProvisionerState: "IN_PROGRESS"}, nil, now)
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected status: %+v, got: %+v", expected, status)
+	}
+
+	expected = &v1beta1.CruiseControlRightsizeStatus{
+		BrokersToAdd: 2,
+		RequestedAt:  "2022-05-04 12:00:00",
+		Error:        "rightsize failed",
+	}
+	status = newCruiseControlRightsizeStatus(opts, nil, errors.New("rightsize failed"), now)
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected status: %+v, got: %+v", expected, status)
+	}
+}
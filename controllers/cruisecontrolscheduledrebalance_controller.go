@@ -0,0 +1,217 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	"github.com/banzaicloud/koperator/pkg/util/cron"
+)
+
+const (
+	// DefaultScheduledRebalanceIntervalInSec is the period of checking whether the scheduled rebalance of a cluster is
+	// due
+	DefaultScheduledRebalanceIntervalInSec = 60
+	// DefaultScheduledRebalanceStartingDeadline is the delay after which a scheduled rebalance which was missed, e.g.
+	// while the operator was down, is skipped instead of being started late
+	DefaultScheduledRebalanceStartingDeadline = time.Hour
+
+	scheduledRebalanceTimeFormat = "2006-01-02 15:04:05"
+)
+
+// CruiseControlScheduledRebalanceReconciler rebalances the whole kafka clusters at the times of their rebalance
+// schedule
+type CruiseControlScheduledRebalanceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *CruiseControlScheduledRebalanceReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	spec := instance.Spec.CruiseControlConfig.RebalanceSchedule
+	if spec == "" || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		log.Error(err, "invalid rebalance schedule", "schedule", spec)
+		return reconciled()
+	}
+
+	now := time.Now().UTC()
+	status := instance.Status.ScheduledRebalance
+	next := nextScheduledRebalanceAt(schedule, spec, status, now)
+	if next.IsZero() {
+		log.Info("the rebalance schedule never matches", "schedule", spec)
+		return reconciled()
+	}
+	if now.Before(next) {
+		if status == nil || status.Schedule != spec || status.NextRebalanceAt != next.Format(scheduledRebalanceTimeFormat) {
+			pending := &kafkav1beta1.ScheduledRebalanceStatus{Schedule: spec}
+			if status != nil {
+				*pending = *status
+				pending.Schedule = spec
+			}
+			pending.NextRebalanceAt = next.Format(scheduledRebalanceTimeFormat)
+			if err := k8sutil.UpdateCRStatus(r.Client, instance, pending, log); err != nil {
+				return requeueWithError(log, "failed to update the scheduled rebalance in the Kafka Cluster status", err)
+			}
+		}
+		return requeueAfter(DefaultScheduledRebalanceIntervalInSec)
+	}
+
+	rebalanceStatus := &kafkav1beta1.ScheduledRebalanceStatus{
+		Schedule:        spec,
+		NextRebalanceAt: schedule.Next(now).Format(scheduledRebalanceTimeFormat),
+		LastRebalanceAt: now.Format(scheduledRebalanceTimeFormat),
+	}
+	if now.Sub(next) > DefaultScheduledRebalanceStartingDeadline {
+		rebalanceStatus.SkippedReason = fmt.Sprintf("the rebalance scheduled at %s was missed",
+			next.Format(scheduledRebalanceTimeFormat))
+	} else {
+		result, reason, err := r.rebalance(ctx, log, instance)
+		if err != nil {
+			return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+		}
+		rebalanceStatus.SkippedReason = reason
+		if result != nil {
+			rebalanceStatus.TaskID = result.TaskID
+		}
+	}
+	if rebalanceStatus.SkippedReason != "" {
+		log.Info("scheduled rebalance skipped", "reason", rebalanceStatus.SkippedReason)
+	}
+
+	if err := k8sutil.UpdateCRStatus(r.Client, instance, rebalanceStatus, log); err != nil {
+		return requeueWithError(log, "failed to update the scheduled rebalance in the Kafka Cluster status", err)
+	}
+	return requeueAfter(DefaultScheduledRebalanceIntervalInSec)
+}
+
+// rebalance starts the rebalance of the whole cluster if Cruise Control is idle and the cluster is
+// healthy, otherwise it returns the reason why the rebalance is skipped
+func (r *CruiseControlScheduledRebalanceReconciler) rebalance(ctx context.Context, log logr.Logger,
+	instance *kafkav1beta1.KafkaCluster) (*scale.Result, string, error) {
+	if instance.Status.State != kafkav1beta1.KafkaClusterRunning {
+		return nil, fmt.Sprintf("the cluster is in %s state", instance.Status.State), nil
+	}
+	if instance.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		instance.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		return nil, "Cruise Control is not deployed", nil
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, instance)
+	if err != nil {
+		return nil, "", err
+	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+	scaler = scale.QueuedCruiseControlScaler(scale.DefaultOperationQueue, instance, "CruiseControlScheduledRebalance", scale.TriggerPeriodic, scaler)
+
+	result, reason := startScheduledRebalance(ctx, log, instance, scaler)
+	return result, reason, nil
+}
+
+// startScheduledRebalance checks that Cruise Control is idle and that none of the brokers are dead or have bad disks
+// before starting the rebalance of the whole cluster
+func startScheduledRebalance(ctx context.Context, log logr.Logger, instance *kafkav1beta1.KafkaCluster,
+	scaler scale.CruiseControlScaler) (*scale.Result, string) {
+	if !scaler.IsUp(ctx) {
+		return nil, "Cruise Control is not up"
+	}
+	if scaler.Status(ctx).InExecution() {
+		return nil, "Cruise Control is executing a task"
+	}
+	unhealthyBrokers, err := scaler.BrokersWithState(ctx, scale.KafkaBrokerDead, scale.KafkaBrokerBadDisks)
+	if err != nil {
+		return nil, fmt.Sprintf("the state of the brokers could not be checked: %v", err)
+	}
+	if len(unhealthyBrokers) > 0 {
+		return nil, fmt.Sprintf("brokers %s are dead or have bad disks", strings.Join(unhealthyBrokers, ","))
+	}
+
+	log.Info("starting the scheduled rebalance of the cluster")
+	result, err := scaler.RebalanceWithGoals(ctx, instance.Spec.GetBrokerIDsInMaintenance(), "")
+	if err != nil {
+		return nil, fmt.Sprintf("the rebalance could not be started: %v", err)
+	}
+	return result, ""
+}
+
+// nextScheduledRebalanceAt returns the time of the next scheduled rebalance recorded in the status, or computes it
+// from the schedule if it changed
+func nextScheduledRebalanceAt(schedule *cron.Schedule, spec string, status *kafkav1beta1.ScheduledRebalanceStatus,
+	now time.Time) time.Time {
+	if status != nil && status.Schedule == spec {
+		if next, err := time.Parse(scheduledRebalanceTimeFormat, status.NextRebalanceAt); err == nil {
+			return next
+		}
+	}
+	return schedule.Next(now)
+}
+
+// SetupCruiseControlScheduledRebalanceWithManager registers the cruise control scheduled rebalance controller to the
+// manager
+func SetupCruiseControlScheduledRebalanceWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("CruiseControlScheduledRebalance")
+
+	// only the creation, the deletion and the spec changes of the clusters trigger the checks, the schedule is
+	// checked periodically afterwards
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,116 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	fakescale "github.com/banzaicloud/koperator/pkg/scale/fake"
+	"github.com/banzaicloud/koperator/pkg/util/cron"
+)
+
+func TestNextScheduledRebalanceAt(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 0, 0, 0, time.UTC)
+	schedule, err := cron.Parse("0 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Date(2022, 5, 5, 2, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testName string
+		status   *v1beta1.ScheduledRebalanceStatus
+		expected time.Time
+	}{
+		{
+			testName: "first schedule",
+			expected: tomorrow,
+		},
+		{
+			testName: "next rebalance already computed",
+			status:   &v1beta1.ScheduledRebalanceStatus{Schedule: "0 2 * * *", NextRebalanceAt: "2022-05-04 02:00:00"},
+			expected: time.Date(2022, 5, 4, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			testName: "schedule changed",
+			status:   &v1beta1.ScheduledRebalanceStatus{Schedule: "0 3 * * *", NextRebalanceAt: "2022-05-04 03:00:00"},
+			expected: tomorrow,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			if next := nextScheduledRebalanceAt(schedule, "0 2 * * *", test.status, now); !next.Equal(test.expected) {
+				t.Errorf("expected next rebalance at %s, got %s", test.expected, next)
+			}
+		})
+	}
+}
+
+func TestStartScheduledRebalance(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{}
+
+	testCases := []struct {
+		testName string
+		scaler   *fakescale.Scaler
+		skipped  bool
+	}{
+		{
+			testName: "healthy cluster",
+			scaler:   fakescale.NewScaler(fakescale.Broker{ID: "0", State: scale.KafkaBrokerAlive}),
+		},
+		{
+			testName: "cruise control is down",
+			scaler:   &fakescale.Scaler{Down: true},
+			skipped:  true,
+		},
+		{
+			testName: "cruise control is executing a task",
+			scaler:   &fakescale.Scaler{StatusResult: &scale.CruiseControlStatus{ExecutorReady: false}},
+			skipped:  true,
+		},
+		{
+			testName: "dead broker",
+			scaler: fakescale.NewScaler(fakescale.Broker{ID: "0", State: scale.KafkaBrokerAlive},
+				fakescale.Broker{ID: "1", State: scale.KafkaBrokerDead}),
+			skipped: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			result, reason := startScheduledRebalance(context.TODO(), logr.Discard(), cluster, test.scaler)
+			if test.skipped {
+				if reason == "" || result != nil {
+					t.Errorf("expected the rebalance to be skipped, got result: %v", result)
+				}
+				return
+			}
+			if reason != "" || result == nil || result.TaskID == "" {
+				t.Errorf("expected the rebalance to be started, got reason: %s", reason)
+			}
+			tasks := test.scaler.Tasks()
+			if len(tasks) != 1 || tasks[0].Method != fakescale.MethodRebalanceWithGoals {
+				t.Errorf("expected a rebalance task, got: %v", tasks)
+			}
+		})
+	}
+}
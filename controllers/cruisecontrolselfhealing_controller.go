@@ -0,0 +1,139 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// DefaultSelfHealingSyncIntervalInSec is the period of checking the self-healing settings of Cruise Control
+const DefaultSelfHealingSyncIntervalInSec = 60
+
+// CruiseControlSelfHealingReconciler keeps the self-healing settings of Cruise Control in sync with
+// spec.cruiseControlConfig.selfHealing. The settings are checked periodically as Cruise Control falls back to its
+// configuration when it is restarted.
+type CruiseControlSelfHealingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func (r *CruiseControlSelfHealingReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	selfHealing := instance.Spec.CruiseControlConfig.SelfHealing
+	if selfHealing == nil || instance.Spec.UsesBuiltInRebalancer() || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	if instance.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		instance.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		log.V(1).Info("requeue event as Cruise Control is not deployed (yet)")
+		return requeueAfter(DefaultSelfHealingSyncIntervalInSec)
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, instance)
+	if err != nil {
+		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+
+	if err := syncSelfHealing(ctx, log, selfHealing, scaler); err != nil {
+		return requeueWithError(log, "failed to update the self-healing settings of Cruise Control", err)
+	}
+	return requeueAfter(DefaultSelfHealingSyncIntervalInSec)
+}
+
+// syncSelfHealing enables and disables the self-healing of the anomaly types the settings of which differ from the
+// ones reported by Cruise Control, nothing is changed while the settings of Cruise Control are not known
+func syncSelfHealing(ctx context.Context, log logr.Logger, selfHealing *kafkav1beta1.CruiseControlSelfHealing,
+	scaler scale.CruiseControlScaler) error {
+	if !scaler.IsUp(ctx) {
+		log.V(1).Info("Cruise Control is not up, the self-healing settings are not checked")
+		return nil
+	}
+	current := scaler.Status(ctx).AnomalyDetector
+	if len(current.SelfHealingEnabled) == 0 && len(current.SelfHealingDisabled) == 0 {
+		log.V(1).Info("the self-healing settings of Cruise Control are not known (yet)")
+		return nil
+	}
+
+	enabled, disabled := selfHealing.AnomalyTypes()
+	var enable, disable []string
+	for _, anomalyType := range enabled {
+		if !current.SelfHealingEnabledFor(anomalyType) {
+			enable = append(enable, anomalyType)
+		}
+	}
+	for _, anomalyType := range disabled {
+		if current.SelfHealingEnabledFor(anomalyType) {
+			disable = append(disable, anomalyType)
+		}
+	}
+	if len(enable) == 0 && len(disable) == 0 {
+		return nil
+	}
+
+	log.Info("updating the self-healing settings of Cruise Control", "enable", enable, "disable", disable)
+	return scaler.UpdateSelfHealing(ctx, enable, disable)
+}
+
+// SetupCruiseControlSelfHealingWithManager registers the Cruise Control self-healing controller to the manager
+func SetupCruiseControlSelfHealingWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("CruiseControlSelfHealing")
+
+	// the settings are checked periodically, only the creation, the deletion and the spec changes of the clusters
+	// trigger the checks in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,79 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	fakescale "github.com/banzaicloud/koperator/pkg/scale/fake"
+)
+
+func TestSyncSelfHealing(t *testing.T) {
+	on, off := true, false
+	selfHealing := &v1beta1.CruiseControlSelfHealing{BrokerFailure: &on, GoalViolation: &off}
+
+	testCases := []struct {
+		testName string
+		scaler   *fakescale.Scaler
+		calls    []fakescale.Method
+		expected map[string]bool
+	}{
+		{
+			testName: "settings differ",
+			scaler:   &fakescale.Scaler{SelfHealing: map[string]bool{"BROKER_FAILURE": false, "GOAL_VIOLATION": true, "DISK_FAILURE": true}},
+			calls:    []fakescale.Method{fakescale.MethodUpdateSelfHealing},
+			expected: map[string]bool{"BROKER_FAILURE": true, "GOAL_VIOLATION": false, "DISK_FAILURE": true},
+		},
+		{
+			testName: "settings in sync",
+			scaler:   &fakescale.Scaler{SelfHealing: map[string]bool{"BROKER_FAILURE": true, "GOAL_VIOLATION": false}},
+			expected: map[string]bool{"BROKER_FAILURE": true, "GOAL_VIOLATION": false},
+		},
+		{
+			testName: "settings not known",
+			scaler:   &fakescale.Scaler{},
+		},
+		{
+			testName: "cruise control is down",
+			scaler:   &fakescale.Scaler{Down: true, SelfHealing: map[string]bool{"BROKER_FAILURE": false}},
+			expected: map[string]bool{"BROKER_FAILURE": false},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			if err := syncSelfHealing(context.TODO(), logr.Discard(), selfHealing, test.scaler); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var calls []fakescale.Method
+			for _, call := range test.scaler.Calls() {
+				if call == fakescale.MethodUpdateSelfHealing {
+					calls = append(calls, call)
+				}
+			}
+			if !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("expected calls: %v, got: %v", test.calls, calls)
+			}
+			if !reflect.DeepEqual(test.scaler.SelfHealing, test.expected) {
+				t.Errorf("expected self-healing settings: %v, got: %v", test.expected, test.scaler.SelfHealing)
+			}
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
 	"github.com/banzaicloud/koperator/pkg/scale"
 )
 
@@ -70,33 +71,65 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 		return reconciled()
 	}
 
-	scaler, err := scale.NewCruiseControlScaler(ctx, scale.CruiseControlURLFromKafkaCluster(instance))
+	scaler, err := scale.DefaultScalerRegistry.Scaler(ctx, r.Client, instance)
 	if err != nil {
 		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
 	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+	scaler = scale.QueuedCruiseControlScaler(scale.DefaultOperationQueue, instance, "CruiseControl", scale.TriggerUser, scaler)
 
-	if !scaler.IsUp() {
+	if !scaler.IsUp(ctx) {
 		log.Info("requeue event as Cruise Control is not available (yet)")
 		return requeueAfter(DefaultRequeueAfterTimeInSec)
 	}
 
 	// Update task states with information from Cruise Control
-	err = updateActiveTasks(scaler, tasksAndStates)
+	err = updateActiveTasks(ctx, scaler, tasksAndStates)
 	if err != nil {
 		log.Error(err, "requeue event as updating state of active tasks failed")
 		return requeueAfter(DefaultRequeueAfterTimeInSec)
 	}
 
+	// Cancel the tasks the user asked for before anything else, so no new task is started for them
+	if tasksToCancel := tasksAndStates.GetTasksToCancel(instance); len(tasksToCancel) > 0 {
+		return r.cancelTasks(ctx, scaler, instance, tasksAndStates, tasksToCancel)
+	}
+
 	// Check if CruiseControl is ready as we cannot perform any operation until it is in ready state
-	if status := scaler.Status(); status.InExecution() {
+	if status := scaler.Status(ctx); status.InExecution() {
 		log.Info("updating status of Kafka Cluster and requeue event as Cruise Control is in execution")
+		// the executor concurrency may have been changed since the execution was started
+		concurrency := executorConcurrency(instance.Spec.CruiseControlConfig.GetExecutorConcurrency())
+		if !concurrency.IsZero() && !instance.Spec.UsesBuiltInRebalancer() {
+			if err := scaler.UpdateExecutorConcurrency(ctx, concurrency); err != nil {
+				log.Error(err, "failed to update the executor concurrency of the ongoing execution of Cruise Control")
+			}
+		}
 		if err := r.UpdateStatus(ctx, instance, tasksAndStates); err != nil {
 			log.Error(err, "failed to update Kafka Cluster status")
 		}
 		return requeueAfter(DefaultRequeueAfterTimeInSec)
 	}
 
+	goals := instance.Spec.CruiseControlConfig.GetOperationGoals()
 	switch {
+	case tasksAndStates.NumActiveTasksByOp(OperationDemoteBroker) > 0:
+		demoteBrokerTasks := tasksAndStates.GetActiveTasksByOp(OperationDemoteBroker)
+		brokerIDs := make([]string, 0, len(demoteBrokerTasks))
+		for _, task := range demoteBrokerTasks {
+			brokerIDs = append(brokerIDs, task.BrokerID)
+		}
+		details := []interface{}{"operation", "demote broker", "brokers", brokerIDs}
+
+		result, err := scaler.DemoteBrokers(ctx, brokerIDs...)
+		if err != nil {
+			log.Error(err, "demoting broker(s) in maintenance via Cruise Control failed", details...)
+		}
+
+		for _, task := range demoteBrokerTasks {
+			task.FromResult(result)
+		}
+
 	case tasksAndStates.NumActiveTasksByOp(OperationAddBroker) > 0:
 		addBrokerTasks := make([]*CruiseControlTask, 0)
 		brokerIDs := make([]string, 0)
@@ -106,7 +139,7 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 		}
 		details := []interface{}{"operation", "add broker", "brokers", brokerIDs}
 
-		result, err := scaler.AddBrokers(brokerIDs...)
+		result, err := scaler.AddBrokers(ctx, operationOptions(instance, goals.AddBrokers), brokerIDs...)
 		if err != nil {
 			log.Error(err, "adding broker(s) to Kafka cluster via Cruise Control failed", details...)
 		}
@@ -127,14 +160,14 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 
 		details := []interface{}{"operation", "remove broker", "brokers", removeTask.BrokerID}
 
-		result, err := scaler.RemoveBrokers(removeTask.BrokerID)
+		result, err := scaler.RemoveBrokers(ctx, operationOptions(instance, goals.RemoveBrokers), removeTask.BrokerID)
 		if err != nil {
 			log.Error(err, "removing broker(s) from Kafka cluster via Cruise Control failed", details...)
 		}
 		removeTask.FromResult(result)
 
 	case tasksAndStates.NumActiveTasksByOp(OperationRebalanceDisks) > 0:
-		logDirsByBroker, err := scaler.LogDirsByBroker()
+		logDirsByBroker, err := scaler.LogDirsByBroker(ctx)
 		if err != nil {
 			log.Error(err, "failed to get list of volumes per broker from Cruise Control")
 			return requeueAfter(DefaultRequeueAfterTimeInSec)
@@ -148,7 +181,7 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 			if onlineDirs, ok := logDirsByBroker[task.BrokerID][scale.LogDirStateOnline]; ok {
 				found := true
 				for _, dir := range onlineDirs {
-					if !strings.HasPrefix(strings.TrimSpace(dir), strings.TrimSpace(task.Volume)) {
+					if !strings.HasPrefix(strings.TrimSpace(dir.Path), strings.TrimSpace(task.Volume)) {
 						found = false
 					}
 				}
@@ -162,7 +195,7 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 
 		details := []interface{}{"operation", "rebalance disks", "brokers", brokerIDs}
 
-		result, err := scaler.RebalanceDisks(brokerIDs...)
+		result, err := scaler.RebalanceDisks(ctx, operationOptions(instance, goals.RebalanceDisks), brokerIDs...)
 		if err != nil {
 			log.Error(err, "re-balancing disk(s) in Kafka cluster via Cruise Control failed", details...)
 		}
@@ -181,6 +214,55 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 	return requeueAfter(DefaultRequeueAfterTimeInSec)
 }
 
+// operationOptions returns the options of a Cruise Control operation of the cluster optimizing the given goals
+func operationOptions(instance *kafkav1beta1.KafkaCluster, goals []string) scale.OperationOptions {
+	return scale.OperationOptions{
+		Goals:               goals,
+		ReplicationThrottle: instance.Spec.CruiseControlConfig.GetReplicationThrottle(),
+		Concurrency:         executorConcurrency(instance.Spec.CruiseControlConfig.GetExecutorConcurrency()),
+	}
+}
+
+// executorConcurrency converts the executor concurrency of the cluster to the one of the scaler
+func executorConcurrency(concurrency kafkav1beta1.CruiseControlExecutorConcurrency) scale.ExecutorConcurrency {
+	return scale.ExecutorConcurrency{
+		PartitionMovementsPerBroker:   concurrency.PartitionMovementsPerBroker,
+		LeaderMovements:               concurrency.LeaderMovements,
+		IntraBrokerPartitionMovements: concurrency.IntraBrokerPartitionMovements,
+	}
+}
+
+// cancelTasks stops the ongoing execution of Cruise Control if any of the provided tasks is running and marks the
+// tasks as cancelled. The remove broker tasks of the brokers added back to the kafkav1beta1.KafkaCluster are
+// reverted, the other tasks are held back until the cancellation is withdrawn.
+func (r *CruiseControlTaskReconciler) cancelTasks(ctx context.Context, scaler scale.CruiseControlScaler,
+	instance *kafkav1beta1.KafkaCluster, tasksAndStates *CruiseControlTasksAndStates, tasks []*CruiseControlTask) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	for _, task := range tasks {
+		if task.IsRunning() {
+			if err := scaler.StopExecution(ctx); err != nil {
+				return requeueWithError(log, "failed to stop the execution of Cruise Control", err)
+			}
+			log.Info("execution of Cruise Control stopped", "taskId", task.TaskID)
+			break
+		}
+	}
+
+	for _, task := range tasks {
+		if task.Operation == OperationRemoveBroker && isBrokerInSpec(instance, task.BrokerID) {
+			task.Revert()
+		} else {
+			task.Cancel()
+		}
+	}
+
+	if err := r.UpdateStatus(ctx, instance, tasksAndStates); err != nil {
+		log.Error(err, "failed to update Kafka Cluster status")
+	}
+	return requeueAfter(DefaultRequeueAfterTimeInSec)
+}
+
 // UpdateStatus updates the Status of the provided kafkav1beta1.KafkaCluster instance with the status of the tasks
 // from a CruiseControlTasksAndStates and sends the updates to the Kubernetes API if any changes in the Status field is
 // detected. Otherwise, this step is skipped.
@@ -228,6 +310,8 @@ func SetupCruiseControlWithManager(mgr ctrl.Manager) *ctrl.Builder {
 					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
 					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
 					if !reflect.DeepEqual(oldObj.Status.BrokersState, newObj.Status.BrokersState) ||
+						kafkav1beta1.IsCruiseControlTaskCancellationRequested(oldObj.GetAnnotations()) !=
+							kafkav1beta1.IsCruiseControlTaskCancellationRequested(newObj.GetAnnotations()) ||
 						oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
 						oldObj.GetGeneration() != newObj.GetGeneration() {
 						return true
@@ -247,10 +331,27 @@ func getActiveTasksFromCluster(instance *kafkav1beta1.KafkaCluster) *CruiseContr
 	tasksAndStates := newCruiseControlTasksAndStates()
 
 	for brokerId, brokerStatus := range instance.Status.BrokersState {
+		// no data is moved to the brokers in maintenance, their upscale and disk rebalance tasks are held back
+		// until they are back from maintenance
+		inMaintenance := instance.Spec.IsBrokerInMaintenance(brokerId)
+
+		if maintenanceState := brokerStatus.GracefulActionState.MaintenanceState; inMaintenance &&
+			maintenanceState != nil && maintenanceState.CruiseControlMaintenanceState.IsActive() {
+			t := &CruiseControlTask{
+				TaskID:           maintenanceState.CruiseControlTaskId,
+				BrokerID:         brokerId,
+				StartedAt:        maintenanceState.TaskStarted,
+				MaintenanceState: maintenanceState.CruiseControlMaintenanceState,
+				Operation:        OperationDemoteBroker,
+				Err:              maintenanceState.ErrorMessage,
+			}
+			tasksAndStates.Add(t)
+		}
+
 		if brokerStatus.GracefulActionState.CruiseControlState.IsActive() {
 			state := brokerStatus.GracefulActionState
 			switch {
-			case state.CruiseControlState.IsUpscale():
+			case state.CruiseControlState.IsUpscale() && !inMaintenance:
 				t := &CruiseControlTask{
 					TaskID:      state.CruiseControlTaskId,
 					BrokerID:    brokerId,
@@ -274,7 +375,7 @@ func getActiveTasksFromCluster(instance *kafkav1beta1.KafkaCluster) *CruiseContr
 		}
 
 		for mountPath, volumeState := range brokerStatus.GracefulActionState.VolumeStates {
-			if volumeState.CruiseControlVolumeState.IsActive() {
+			if volumeState.CruiseControlVolumeState.IsActive() && !inMaintenance {
 				t := &CruiseControlTask{
 					TaskID:      volumeState.CruiseControlTaskId,
 					BrokerID:    brokerId,
@@ -293,8 +394,15 @@ func getActiveTasksFromCluster(instance *kafkav1beta1.KafkaCluster) *CruiseContr
 
 // updateActiveTasks updates the state of the tasks from the CruiseControlTasksAndStates instance by getting their
 // status from CruiseControl using the provided scale.CruiseControlScaler.
-func updateActiveTasks(scaler scale.CruiseControlScaler, tasksAndStates *CruiseControlTasksAndStates) error {
-	tasks, err := scaler.GetUserTasks()
+func updateActiveTasks(ctx context.Context, scaler scale.CruiseControlScaler, tasksAndStates *CruiseControlTasksAndStates) error {
+	taskIDs := make([]string, 0, len(tasksAndStates.tasks))
+	for _, task := range tasksAndStates.tasks {
+		if task != nil && task.TaskID != "" {
+			taskIDs = append(taskIDs, task.TaskID)
+		}
+	}
+
+	tasks, err := scaler.GetUserTasks(ctx, taskIDs...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,196 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestGetActiveTasksFromClusterWithBrokersInMaintenance(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1, Maintenance: true}},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {GracefulActionState: v1beta1.GracefulActionState{
+					CruiseControlState: v1beta1.GracefulUpscaleRequired,
+					VolumeStates: map[string]v1beta1.VolumeState{
+						"/kafka-logs": {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired},
+					},
+				}},
+				"1": {GracefulActionState: v1beta1.GracefulActionState{
+					CruiseControlState: v1beta1.GracefulUpscaleRequired,
+					VolumeStates: map[string]v1beta1.VolumeState{
+						"/kafka-logs": {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired},
+					},
+					MaintenanceState: &v1beta1.MaintenanceState{CruiseControlMaintenanceState: v1beta1.GracefulDemotionRequired},
+				}},
+			},
+		},
+	}
+
+	tasksAndStates := getActiveTasksFromCluster(cluster)
+	for _, op := range []CruiseControlOperation{OperationAddBroker, OperationRebalanceDisks} {
+		tasks := tasksAndStates.GetActiveTasksByOp(op)
+		if len(tasks) != 1 || tasks[0].BrokerID != "0" {
+			t.Errorf("expected task of operation %d only for broker 0, got: %+v", op, tasks)
+		}
+	}
+
+	demoteTasks := tasksAndStates.GetActiveTasksByOp(OperationDemoteBroker)
+	if len(demoteTasks) != 1 || demoteTasks[0].BrokerID != "1" {
+		t.Fatalf("expected demote task for broker 1, got: %+v", demoteTasks)
+	}
+
+	demoteTasks[0].FromResult(&scale.Result{TaskID: "demote-task", State: v1beta1.CruiseControlTaskActive})
+	tasksAndStates.SyncState(cluster)
+	state := cluster.Status.BrokersState["1"].GracefulActionState.MaintenanceState
+	if state.CruiseControlMaintenanceState != v1beta1.GracefulDemotionRunning || state.CruiseControlTaskId != "demote-task" {
+		t.Errorf("expected running demotion of broker 1, got: %+v", state)
+	}
+
+	demoteTasks[0].FromResult(&scale.Result{TaskID: "demote-task", State: v1beta1.CruiseControlTaskCompleted})
+	tasksAndStates.SyncState(cluster)
+	if state.CruiseControlMaintenanceState != v1beta1.GracefulDemotionSucceeded {
+		t.Errorf("expected succeeded demotion of broker 1, got: %+v", state)
+	}
+	if tasksAndStates.NumActiveTasksByOp(OperationDemoteBroker) != 0 {
+		t.Error("expected no active demote task after the demotion succeeded")
+	}
+
+	// the held back tasks of the broker are picked up once it is back from maintenance
+	cluster.Spec.Brokers[1].Maintenance = false
+	tasksAndStates = getActiveTasksFromCluster(cluster)
+	if n := tasksAndStates.NumActiveTasksByOp(OperationAddBroker); n != 2 {
+		t.Errorf("expected add broker tasks for both brokers, got: %d", n)
+	}
+}
+
+func TestRecordOperations(t *testing.T) {
+	now := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	instance := &v1beta1.KafkaCluster{}
+
+	tasksAndStates := newCruiseControlTasksAndStates()
+	tasksAndStates.Add(&CruiseControlTask{TaskID: "t1", StartedAt: "s1", BrokerID: "2",
+		BrokerState: v1beta1.GracefulUpscaleRunning, Operation: OperationAddBroker})
+	tasksAndStates.Add(&CruiseControlTask{TaskID: "t1", StartedAt: "s1", BrokerID: "1",
+		BrokerState: v1beta1.GracefulUpscaleRunning, Operation: OperationAddBroker})
+	tasksAndStates.Add(&CruiseControlTask{BrokerID: "3", BrokerState: v1beta1.GracefulDownscaleRequired,
+		Operation: OperationRemoveBroker})
+	tasksAndStates.recordOperations(instance, now)
+
+	expected := []v1beta1.OperationRecord{{Type: v1beta1.OperationTypeAddBroker, TriggeredBy: "operator",
+		Brokers: []string{"1", "2"}, TaskID: "t1", StartedAt: "s1", Outcome: v1beta1.OperationRunning}}
+	if !reflect.DeepEqual(instance.Status.OperationHistory, expected) {
+		t.Fatalf("expected running add broker task, got: %+v", instance.Status.OperationHistory)
+	}
+
+	for _, task := range tasksAndStates.tasks {
+		task.BrokerState = v1beta1.GracefulUpscaleSucceeded
+		task.Err = "completed with error"
+	}
+	tasksAndStates.recordOperations(instance, now)
+	// a finished task is recorded only once
+	tasksAndStates.recordOperations(instance, now.Add(time.Minute))
+
+	expected[0].FinishedAt = "2022-04-01T10:00:00Z"
+	expected[0].Outcome = v1beta1.OperationFailed
+	expected[0].Error = "completed with error"
+	if !reflect.DeepEqual(instance.Status.OperationHistory, expected) {
+		t.Errorf("expected failed add broker task, got: %+v", instance.Status.OperationHistory)
+	}
+}
+
+func TestCancelTasks(t *testing.T) {
+	ccClient := scale.NewFakeCruiseControlClient(scale.FakeBroker{ID: 1, Replicas: 10}, scale.FakeBroker{ID: 2})
+	removeResp, _ := ccClient.RemoveBroker(context.TODO(), &api.RemoveBrokerRequest{BrokerIDs: []int32{1}})
+	scaler := scale.NewCruiseControlScalerWithClient(logr.Discard(), ccClient)
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			// broker 1 is added back to the cluster while it is being removed
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {GracefulActionState: v1beta1.GracefulActionState{
+					CruiseControlState: v1beta1.GracefulUpscaleRequired,
+				}},
+				"1": {GracefulActionState: v1beta1.GracefulActionState{
+					CruiseControlState:  v1beta1.GracefulDownscaleRunning,
+					CruiseControlTaskId: removeResp.TaskID,
+					TaskStarted:         "s1",
+				}},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	r := &CruiseControlTaskReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()}
+
+	tasksAndStates := getActiveTasksFromCluster(cluster)
+	tasksToCancel := tasksAndStates.GetTasksToCancel(cluster)
+	if len(tasksToCancel) != 1 || tasksToCancel[0].BrokerID != "1" {
+		t.Fatalf("expected the remove broker task of broker 1 to be cancelled, got: %+v", tasksToCancel)
+	}
+	if _, err := r.cancelTasks(context.Background(), scaler, cluster, tasksAndStates, tasksToCancel); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if tasks := ccClient.Tasks(); tasks[0].Status != types.UserTaskStatusCompletedWithError {
+		t.Errorf("expected the execution of the remove broker task to be stopped, got: %+v", tasks[0])
+	}
+	state := cluster.Status.BrokersState["1"].GracefulActionState
+	if state.CruiseControlState != v1beta1.GracefulUpscaleSucceeded || state.CruiseControlTaskId != "" ||
+		state.ErrorMessage != cruiseControlTaskCancelledMsg {
+		t.Errorf("expected broker 1 to be kept, got: %+v", state)
+	}
+	record := cluster.Status.GetOperation(v1beta1.OperationTypeRemoveBroker, removeResp.TaskID)
+	if record == nil || record.Outcome != v1beta1.OperationFailed || record.Error != cruiseControlTaskCancelledMsg {
+		t.Errorf("expected cancelled remove broker operation, got: %+v", record)
+	}
+
+	// every task is held back while the cancellation is requested
+	cluster.Annotations = map[string]string{v1beta1.CancelCruiseControlTasksAnnotation: "true"}
+	tasksAndStates = getActiveTasksFromCluster(cluster)
+	tasksToCancel = tasksAndStates.GetTasksToCancel(cluster)
+	if len(tasksToCancel) != 1 || tasksToCancel[0].BrokerID != "0" {
+		t.Fatalf("expected the add broker task of broker 0 to be cancelled, got: %+v", tasksToCancel)
+	}
+	if _, err := r.cancelTasks(context.Background(), scaler, cluster, tasksAndStates, tasksToCancel); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	state = cluster.Status.BrokersState["0"].GracefulActionState
+	if state.CruiseControlState != v1beta1.GracefulUpscaleRequired || state.ErrorMessage != cruiseControlTaskCancelledMsg {
+		t.Errorf("expected the add broker task of broker 0 to be held back, got: %+v", state)
+	}
+}
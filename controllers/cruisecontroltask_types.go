@@ -15,8 +15,13 @@
 package controllers
 
 import (
+	"sort"
+	"strconv"
+	"time"
+
 	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/scale"
+	"github.com/banzaicloud/koperator/pkg/util"
 )
 
 type CruiseControlOperation int8
@@ -25,12 +30,37 @@ const (
 	OperationAddBroker CruiseControlOperation = iota
 	OperationRemoveBroker
 	OperationRebalanceDisks
+	OperationDemoteBroker
+)
+
+const (
+	operationTimeFormat = time.RFC3339
+
+	cruiseControlTaskCancelledMsg = "cancelled"
 )
 
+// operationType returns the type of the CruiseControlOperation in the operation history of the cluster
+func (o CruiseControlOperation) operationType() kafkav1beta1.OperationType {
+	switch o {
+	case OperationAddBroker:
+		return kafkav1beta1.OperationTypeAddBroker
+	case OperationRemoveBroker:
+		return kafkav1beta1.OperationTypeRemoveBroker
+	case OperationRebalanceDisks:
+		return kafkav1beta1.OperationTypeRebalanceDisks
+	case OperationDemoteBroker:
+		return kafkav1beta1.OperationTypeDemoteBroker
+	}
+	return ""
+}
+
 // CruiseControlTask defines a task to be performed via Cruise Control.
 type CruiseControlTask struct {
-	TaskID    string
-	StartedAt string
+	TaskID     string
+	StartedAt  string
+	FinishedAt string
+	// Progress is the progress of the replica movements of the task while it is executed by Cruise Control
+	Progress *scale.TaskProgress
 
 	BrokerID    string
 	BrokerState kafkav1beta1.CruiseControlState
@@ -38,8 +68,14 @@ type CruiseControlTask struct {
 	Volume      string
 	VolumeState kafkav1beta1.CruiseControlVolumeState
 
+	MaintenanceState kafkav1beta1.CruiseControlMaintenanceState
+
 	Err       string
 	Operation CruiseControlOperation
+
+	// cancelled is set when the Cruise Control task of the task is cancelled, its ID is kept only to record the
+	// cancellation in the operation history
+	cancelled bool
 }
 
 // IsDone returns true if the task is considered finished.
@@ -53,22 +89,85 @@ func (t *CruiseControlTask) IsDone() bool {
 		return !t.BrokerState.IsActive()
 	case OperationRebalanceDisks:
 		return !t.VolumeState.IsActive()
+	case OperationDemoteBroker:
+		return !t.MaintenanceState.IsActive()
+	}
+	return false
+}
+
+// IsRunning returns true if the task is being executed by Cruise Control.
+func (t *CruiseControlTask) IsRunning() bool {
+	if t == nil {
+		return false
+	}
+
+	switch t.Operation {
+	case OperationAddBroker, OperationRemoveBroker:
+		return t.BrokerState.IsRunningState()
+	case OperationRebalanceDisks:
+		return t.VolumeState.IsRunningState()
+	case OperationDemoteBroker:
+		return t.MaintenanceState == kafkav1beta1.GracefulDemotionRunning
 	}
 	return false
 }
 
+// Cancel resets the task to be required again, so it is performed once the cancellation is withdrawn.
+func (t *CruiseControlTask) Cancel() {
+	if t == nil {
+		return
+	}
+
+	switch t.Operation {
+	case OperationAddBroker:
+		t.BrokerState = kafkav1beta1.GracefulUpscaleRequired
+	case OperationRemoveBroker:
+		t.BrokerState = kafkav1beta1.GracefulDownscaleRequired
+	case OperationRebalanceDisks:
+		t.VolumeState = kafkav1beta1.GracefulDiskRebalanceRequired
+	case OperationDemoteBroker:
+		t.MaintenanceState = kafkav1beta1.GracefulDemotionRequired
+	}
+	t.cancelled = true
+	t.Err = cruiseControlTaskCancelledMsg
+}
+
+// Revert cancels the remove broker task of a broker added back to the cluster, the broker is kept as if it was
+// never removed.
+func (t *CruiseControlTask) Revert() {
+	if t == nil || t.Operation != OperationRemoveBroker {
+		return
+	}
+
+	t.BrokerState = kafkav1beta1.GracefulUpscaleSucceeded
+	t.cancelled = true
+	t.Err = cruiseControlTaskCancelledMsg
+}
+
+// taskIDAndStart returns the ID and the start time of the task to be stored in the status, which are empty once the
+// task is cancelled.
+func (t *CruiseControlTask) taskIDAndStart() (string, string) {
+	if t.cancelled {
+		return "", ""
+	}
+	return t.TaskID, t.StartedAt
+}
+
 // Apply takes a kafkav1beta1.KafkaCluster instance and updates its Status field to reflect the state of the task.
 func (t *CruiseControlTask) Apply(instance *kafkav1beta1.KafkaCluster) {
 	if t == nil || instance == nil {
 		return
 	}
 
+	taskID, startedAt := t.taskIDAndStart()
 	switch t.Operation {
 	case OperationAddBroker, OperationRemoveBroker:
 		if state, ok := instance.Status.BrokersState[t.BrokerID]; ok {
 			state.GracefulActionState.CruiseControlState = t.BrokerState
-			state.GracefulActionState.CruiseControlTaskId = t.TaskID
-			state.GracefulActionState.TaskStarted = t.StartedAt
+			state.GracefulActionState.CruiseControlTaskId = taskID
+			state.GracefulActionState.TaskStarted = startedAt
+			state.GracefulActionState.TaskFinished = t.FinishedAt
+			state.GracefulActionState.TaskProgress = taskProgressStatus(t.Progress)
 			state.GracefulActionState.ErrorMessage = t.Err
 			instance.Status.BrokersState[t.BrokerID] = state
 		}
@@ -76,12 +175,20 @@ func (t *CruiseControlTask) Apply(instance *kafkav1beta1.KafkaCluster) {
 		if state, ok := instance.Status.BrokersState[t.BrokerID]; ok {
 			if volState, ok := state.GracefulActionState.VolumeStates[t.Volume]; ok {
 				volState.CruiseControlVolumeState = t.VolumeState
-				volState.CruiseControlTaskId = t.TaskID
-				volState.TaskStarted = t.StartedAt
+				volState.CruiseControlTaskId = taskID
+				volState.TaskStarted = startedAt
 				volState.ErrorMessage = t.Err
 				instance.Status.BrokersState[t.BrokerID].GracefulActionState.VolumeStates[t.Volume] = volState
 			}
 		}
+	case OperationDemoteBroker:
+		if state, ok := instance.Status.BrokersState[t.BrokerID]; ok && state.GracefulActionState.MaintenanceState != nil {
+			maintenanceState := state.GracefulActionState.MaintenanceState
+			maintenanceState.CruiseControlMaintenanceState = t.MaintenanceState
+			maintenanceState.CruiseControlTaskId = taskID
+			maintenanceState.TaskStarted = startedAt
+			maintenanceState.ErrorMessage = t.Err
+		}
 	}
 }
 
@@ -91,6 +198,13 @@ func (t *CruiseControlTask) FromResult(result *scale.Result) {
 		return
 	}
 
+	if result.State == kafkav1beta1.CruiseControlTaskUnavailable {
+		// the operation was not started, so the task is kept in its current state to be retried once Cruise Control
+		// is available again
+		t.Err = result.Err
+		return
+	}
+
 	switch t.Operation {
 	case OperationAddBroker:
 		switch result.State {
@@ -113,13 +227,45 @@ func (t *CruiseControlTask) FromResult(result *scale.Result) {
 		case kafkav1beta1.CruiseControlTaskCompleted, kafkav1beta1.CruiseControlTaskCompletedWithError:
 			t.VolumeState = kafkav1beta1.GracefulDiskRebalanceSucceeded
 		}
+	case OperationDemoteBroker:
+		switch result.State {
+		case kafkav1beta1.CruiseControlTaskActive, kafkav1beta1.CruiseControlTaskInExecution:
+			t.MaintenanceState = kafkav1beta1.GracefulDemotionRunning
+		case kafkav1beta1.CruiseControlTaskCompleted, kafkav1beta1.CruiseControlTaskCompletedWithError:
+			t.MaintenanceState = kafkav1beta1.GracefulDemotionSucceeded
+		}
 	}
 
 	t.TaskID = result.TaskID
 	t.StartedAt = result.StartedAt
+	// Cruise Control does not report when its tasks finish, so the time the task is first seen finished is kept
+	if t.FinishedAt == "" && (result.State == kafkav1beta1.CruiseControlTaskCompleted ||
+		result.State == kafkav1beta1.CruiseControlTaskCompletedWithError) {
+		t.FinishedAt = result.FinishedAt
+		if t.FinishedAt == "" {
+			t.FinishedAt = time.Now().UTC().Format(operationTimeFormat)
+		}
+	}
+	t.Progress = result.Progress
 	t.Err = result.Err
 }
 
+// taskProgressStatus returns the progress of a task reported by the scale.CruiseControlScaler as stored in the status
+func taskProgressStatus(progress *scale.TaskProgress) *kafkav1beta1.CruiseControlTaskProgress {
+	if progress == nil {
+		return nil
+	}
+	status := &kafkav1beta1.CruiseControlTaskProgress{
+		ReplicaMovementsCompletedPercent: int32(progress.ReplicaMovementsCompletedPct),
+		DataMovedMB:                      progress.DataMovedMB,
+		DataToMoveMB:                     progress.DataToMoveMB,
+	}
+	if !progress.EstimatedCompletion.IsZero() {
+		status.EstimatedCompletion = progress.EstimatedCompletion.UTC().Format(operationTimeFormat)
+	}
+	return status
+}
+
 // CruiseControlTasksAndStates is a container for CruiseControlTask objects.
 type CruiseControlTasksAndStates struct {
 	tasks     []*CruiseControlTask
@@ -156,12 +302,89 @@ func (s *CruiseControlTasksAndStates) NumActiveTasksByOp(o CruiseControlOperatio
 	return len(s.GetActiveTasksByOp(o))
 }
 
+// GetTasksToCancel returns the active tasks to be cancelled: every active task if the cancellation of the tasks is
+// requested on the kafkav1beta1.KafkaCluster, the remove broker tasks of the brokers added back to it otherwise.
+func (s *CruiseControlTasksAndStates) GetTasksToCancel(instance *kafkav1beta1.KafkaCluster) []*CruiseControlTask {
+	cancelAll := kafkav1beta1.IsCruiseControlTaskCancellationRequested(instance.GetAnnotations())
+	tasks := make([]*CruiseControlTask, 0)
+	for _, task := range s.tasks {
+		if task == nil || task.IsDone() {
+			continue
+		}
+		if cancelAll || (task.Operation == OperationRemoveBroker && isBrokerInSpec(instance, task.BrokerID)) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
 // SyncState makes sure that the status of the provided kafkav1beta1.KafkaCluster reflects the state of the
 // CruiseControlTask instances.
 func (s *CruiseControlTasksAndStates) SyncState(instance *kafkav1beta1.KafkaCluster) {
 	for _, task := range s.tasks {
 		task.Apply(instance)
 	}
+	s.recordOperations(instance, time.Now())
+}
+
+// recordOperations records the started and finished Cruise Control tasks in the operation history of the
+// kafkav1beta1.KafkaCluster, the tasks of the brokers sharing the same Cruise Control task are recorded together.
+func (s *CruiseControlTasksAndStates) recordOperations(instance *kafkav1beta1.KafkaCluster, now time.Time) {
+	records := make(map[string]*kafkav1beta1.OperationRecord)
+	var recordKeys []string
+	for _, task := range s.tasks {
+		if task == nil || task.TaskID == "" {
+			continue
+		}
+		operationType := task.Operation.operationType()
+		key := string(operationType) + "/" + task.TaskID
+		record, ok := records[key]
+		if !ok {
+			record = &kafkav1beta1.OperationRecord{
+				Type:        operationType,
+				TriggeredBy: "operator",
+				TaskID:      task.TaskID,
+				StartedAt:   task.StartedAt,
+				Outcome:     kafkav1beta1.OperationRunning,
+			}
+			if task.IsDone() || task.cancelled {
+				record.FinishedAt = now.Format(operationTimeFormat)
+				record.Outcome = kafkav1beta1.OperationSucceeded
+			}
+			records[key] = record
+			recordKeys = append(recordKeys, key)
+		}
+		if !util.StringSliceContains(record.Brokers, task.BrokerID) {
+			record.Brokers = append(record.Brokers, task.BrokerID)
+		}
+		if task.Err != "" {
+			record.Error = task.Err
+			if record.Outcome == kafkav1beta1.OperationSucceeded {
+				record.Outcome = kafkav1beta1.OperationFailed
+			}
+		}
+	}
+
+	sort.Strings(recordKeys)
+	for _, key := range recordKeys {
+		record := records[key]
+		sort.Strings(record.Brokers)
+		current := instance.Status.GetOperation(record.Type, record.TaskID)
+		if current != nil && current.Outcome != kafkav1beta1.OperationRunning {
+			continue
+		}
+		instance.Status.RecordOperation(*record, instance.Spec.GetOperationHistoryLimit())
+	}
+}
+
+// isBrokerInSpec returns true if the broker with the given ID is part of the spec of the kafkav1beta1.KafkaCluster.
+func isBrokerInSpec(instance *kafkav1beta1.KafkaCluster, brokerID string) bool {
+	for _, broker := range instance.Spec.Brokers {
+		if strconv.Itoa(int(broker.Id)) == brokerID {
+			return true
+		}
+	}
+	return false
 }
 
 // newCruiseControlTasksAndStates returns an initialized CruiseControlTasksAndStates instance.
@@ -0,0 +1,162 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const (
+	// DefaultTopicRebalanceIntervalInSec is the period of checking whether the topic rebalance of a cluster has to be
+	// started
+	DefaultTopicRebalanceIntervalInSec = 60
+
+	topicRebalanceRequestedAtTimeFormat = "2006-01-02 15:04:05"
+)
+
+// CruiseControlTopicRebalanceReconciler starts a rebalance of the replicas of the topics selected by the topic
+// rebalance of the kafka clusters whenever it changes
+type CruiseControlTopicRebalanceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *CruiseControlTopicRebalanceReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	rebalance := instance.Spec.CruiseControlConfig.TopicRebalance
+	if rebalance == nil || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+	if !topicRebalanceRequired(rebalance, instance.Status.TopicRebalance) {
+		return reconciled()
+	}
+
+	if instance.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		instance.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		log.V(1).Info("requeue event as Cruise Control is not deployed (yet)")
+		return requeueAfter(DefaultTopicRebalanceIntervalInSec)
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, instance)
+	if err != nil {
+		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+	scaler = scale.QueuedCruiseControlScaler(scale.DefaultOperationQueue, instance, "CruiseControlTopicRebalance", scale.TriggerUser, scaler)
+
+	if !scaler.IsUp(ctx) {
+		log.Info("requeue event as Cruise Control is not up (yet)")
+		return requeueAfter(DefaultTopicRebalanceIntervalInSec)
+	}
+
+	if scaler.Status(ctx).InExecution() {
+		log.V(1).Info("requeue event as Cruise Control is executing a task")
+		return requeueAfter(DefaultTopicRebalanceIntervalInSec)
+	}
+
+	log.Info("rebalancing the replicas of the topics", "topics", rebalance.Topics, "goals", rebalance.Goals)
+	result, err := scaler.RebalanceWithGoals(ctx, instance.Spec.GetBrokerIDsInMaintenance(), rebalance.Topics, rebalance.Goals...)
+	if err != nil {
+		log.Error(err, "rebalance of the topics could not be started", "topics", rebalance.Topics)
+	}
+
+	rebalanceStatus := newTopicRebalanceStatus(rebalance, result, err, time.Now())
+	if err := k8sutil.UpdateCRStatus(r.Client, instance, rebalanceStatus, log); err != nil {
+		return requeueWithError(log, "failed to update the topic rebalance in the Kafka Cluster status", err)
+	}
+	if rebalanceStatus.Error != "" {
+		return requeueAfter(DefaultTopicRebalanceIntervalInSec)
+	}
+	return reconciled()
+}
+
+// topicRebalanceRequired returns true if the topic rebalance differs from the last one started, or the last one could
+// not be started
+func topicRebalanceRequired(rebalance *kafkav1beta1.CruiseControlTopicRebalance, last *kafkav1beta1.TopicRebalanceStatus) bool {
+	if last == nil || last.Error != "" || last.Topics != rebalance.Topics {
+		return true
+	}
+	return !reflect.DeepEqual(last.Goals, rebalance.Goals) && (len(last.Goals) > 0 || len(rebalance.Goals) > 0)
+}
+
+// newTopicRebalanceStatus returns the status of the started topic rebalance, or of the error if it could not be started
+func newTopicRebalanceStatus(rebalance *kafkav1beta1.CruiseControlTopicRebalance, result *scale.Result, err error,
+	now time.Time) *kafkav1beta1.TopicRebalanceStatus {
+	status := &kafkav1beta1.TopicRebalanceStatus{
+		Topics:      rebalance.Topics,
+		Goals:       rebalance.Goals,
+		RequestedAt: now.Format(topicRebalanceRequestedAtTimeFormat),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	} else if result != nil {
+		status.TaskID = result.TaskID
+	}
+	return status
+}
+
+// SetupCruiseControlTopicRebalanceWithManager registers the cruise control topic rebalance controller to the manager
+func SetupCruiseControlTopicRebalanceWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("CruiseControlTopicRebalance")
+
+	// only the creation, the deletion and the spec changes of the clusters trigger the checks, the rebalances which
+	// could not be started are retried periodically
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
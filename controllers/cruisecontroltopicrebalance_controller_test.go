@@ -0,0 +1,96 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestTopicRebalanceRequired(t *testing.T) {
+	rebalance := &v1beta1.CruiseControlTopicRebalance{Topics: "events|clicks-.*"}
+
+	testCases := []struct {
+		testName  string
+		rebalance *v1beta1.CruiseControlTopicRebalance
+		last      *v1beta1.TopicRebalanceStatus
+		expected  bool
+	}{
+		{
+			testName:  "first rebalance",
+			rebalance: rebalance,
+			expected:  true,
+		},
+		{
+			testName:  "rebalance already started",
+			rebalance: rebalance,
+			last:      &v1beta1.TopicRebalanceStatus{Topics: "events|clicks-.*", Goals: []string{}, TaskID: "t1"},
+		},
+		{
+			testName:  "topics changed",
+			rebalance: rebalance,
+			last:      &v1beta1.TopicRebalanceStatus{Topics: "events", TaskID: "t1"},
+			expected:  true,
+		},
+		{
+			testName:  "goals changed",
+			rebalance: &v1beta1.CruiseControlTopicRebalance{Topics: "events|clicks-.*", Goals: []string{"RackAwareGoal"}},
+			last:      &v1beta1.TopicRebalanceStatus{Topics: "events|clicks-.*", TaskID: "t1"},
+			expected:  true,
+		},
+		{
+			testName:  "last rebalance could not be started",
+			rebalance: rebalance,
+			last:      &v1beta1.TopicRebalanceStatus{Topics: "events|clicks-.*", Error: "cruise control is unavailable"},
+			expected:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			if required := topicRebalanceRequired(test.rebalance, test.last); required != test.expected {
+				t.Errorf("expected topic rebalance required: %v, got: %v", test.expected, required)
+			}
+		})
+	}
+}
+
+func TestNewTopicRebalanceStatus(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 0, 0, 0, time.Local)
+	rebalance := &v1beta1.CruiseControlTopicRebalance{Topics: "events", Goals: []string{"ReplicaDistributionGoal"}}
+
+	expected := &v1beta1.TopicRebalanceStatus{
+		Topics:      "events",
+		Goals:       []string{"ReplicaDistributionGoal"},
+		RequestedAt: "2022-05-04 12:00:00",
+		TaskID:      "t1",
+	}
+	status := newTopicRebalanceStatus(rebalance, &scale.Result{TaskID: "t1"}, nil, now)
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected status: %+v, got: %+v", expected, status)
+	}
+
+	expected.TaskID = ""
+	expected.Error = "rebalance failed"
+	status = newTopicRebalanceStatus(rebalance, nil, errors.New("rebalance failed"), now)
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected status: %+v, got: %+v", expected, status)
+	}
+}
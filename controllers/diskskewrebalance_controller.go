@@ -0,0 +1,204 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+)
+
+const (
+	// DefaultDiskSkewCheckIntervalInSec is the period of checking the disk usage skew of the brokers
+	DefaultDiskSkewCheckIntervalInSec = 60
+
+	diskSkewRebalanceTimeFormat = "2006-01-02 15:04:05"
+)
+
+// DiskSkewRebalanceReconciler creates a rebalance CruiseControlOperation for the kafka clusters the disk utilization
+// of the brokers of which is skewed beyond the threshold of spec.cruiseControlConfig.diskSkewRebalance. The disk
+// utilization is taken from the last broker loads of Cruise Control recorded in status.cruiseControlLoad.
+type DiskSkewRebalanceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations,verbs=get;list;watch;create;update;patch;delete
+
+func (r *DiskSkewRebalanceReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	config := instance.Spec.CruiseControlConfig.DiskSkewRebalance
+	if config == nil || k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	skew, ok := diskUsageSkew(instance.Status.CruiseControlLoad)
+	if !ok {
+		log.V(1).Info("requeue event as the disk utilization of the brokers is not known (yet)")
+		return requeueAfter(DefaultDiskSkewCheckIntervalInSec)
+	}
+
+	now := time.Now()
+	status := &kafkav1beta1.DiskSkewRebalanceStatus{SkewPercent: formatLoad(skew)}
+	if last := instance.Status.DiskSkewRebalance; last != nil {
+		status.LastOperation = last.LastOperation
+		status.LastTriggeredAt = last.LastTriggeredAt
+	}
+
+	if skew > float64(config.MaxSkewPercent) {
+		rebalance, err := r.diskSkewRebalanceAllowed(ctx, instance, config, status, now)
+		if err != nil {
+			return requeueWithError(log, "failed to get the last disk skew rebalance operation", err)
+		}
+		if rebalance {
+			operation := newDiskSkewRebalanceOperation(instance, config, now)
+			log.Info("creating rebalance operation as the disk utilization of the brokers is skewed",
+				"skewPercent", status.SkewPercent, "operation", operation.Name)
+			if err := r.Create(ctx, operation); err != nil {
+				return requeueWithError(log, "failed to create the disk skew rebalance operation", err)
+			}
+			status.LastOperation = operation.Name
+			status.LastTriggeredAt = now.Format(diskSkewRebalanceTimeFormat)
+		}
+	}
+
+	if instance.Status.DiskSkewRebalance == nil || *instance.Status.DiskSkewRebalance != *status {
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, status, log); err != nil {
+			return requeueWithError(log, "failed to update the disk skew rebalance in the Kafka Cluster status", err)
+		}
+	}
+	return requeueAfter(DefaultDiskSkewCheckIntervalInSec)
+}
+
+// diskSkewRebalanceAllowed returns true if the cooldown since the last rebalance elapsed and its operation finished
+func (r *DiskSkewRebalanceReconciler) diskSkewRebalanceAllowed(ctx context.Context, instance *kafkav1beta1.KafkaCluster,
+	config *kafkav1beta1.DiskSkewRebalanceConfig, status *kafkav1beta1.DiskSkewRebalanceStatus, now time.Time) (bool, error) {
+	if status.LastTriggeredAt != "" {
+		lastTriggeredAt, err := time.ParseInLocation(diskSkewRebalanceTimeFormat, status.LastTriggeredAt, time.Local)
+		if err == nil && now.Sub(lastTriggeredAt) < config.GetCooldown() {
+			return false, nil
+		}
+	}
+	if status.LastOperation == "" {
+		return true, nil
+	}
+
+	operation := &v1alpha1.CruiseControlOperation{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: status.LastOperation}, operation)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return operation.Status.IsFinished(), nil
+}
+
+// diskUsageSkew returns the difference between the highest and the lowest disk utilization of the brokers in
+// percentage points, it is not known until the load of at least two brokers is reported
+func diskUsageSkew(load *kafkav1beta1.CruiseControlLoadStatus) (float64, bool) {
+	if load == nil {
+		return 0, false
+	}
+	var lowest, highest float64
+	reported := 0
+	for _, broker := range load.Brokers {
+		diskPercent, err := strconv.ParseFloat(broker.DiskPercent, 64)
+		if err != nil {
+			continue
+		}
+		if reported == 0 || diskPercent < lowest {
+			lowest = diskPercent
+		}
+		if reported == 0 || diskPercent > highest {
+			highest = diskPercent
+		}
+		reported++
+	}
+	if reported < 2 {
+		return 0, false
+	}
+	return highest - lowest, true
+}
+
+// newDiskSkewRebalanceOperation returns the CruiseControlOperation rebalancing the cluster, owned by the cluster
+func newDiskSkewRebalanceOperation(instance *kafkav1beta1.KafkaCluster, config *kafkav1beta1.DiskSkewRebalanceConfig,
+	now time.Time) *v1alpha1.CruiseControlOperation {
+	name := fmt.Sprintf("%s-disk-skew-rebalance-%d", instance.Name, now.Unix())
+	return &v1alpha1.CruiseControlOperation{
+		ObjectMeta: templates.ObjectMeta(name, map[string]string{"app": "disk-skew-rebalance"}, instance),
+		Spec: v1alpha1.CruiseControlOperationSpec{
+			ClusterRef: v1alpha1.ClusterReference{Name: instance.Name, Namespace: instance.Namespace},
+			Operation:  v1alpha1.OperationRebalance,
+			Parameters: v1alpha1.CruiseControlOperationParameters{
+				BrokerIDs: instance.Spec.GetBrokerIDsInMaintenance(),
+				Goals:     config.Goals,
+			},
+		},
+	}
+}
+
+// SetupDiskSkewRebalanceWithManager registers the disk skew rebalance controller to the manager
+func SetupDiskSkewRebalanceWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("DiskSkewRebalance")
+
+	// the skew is checked periodically, only the creation, the deletion and the spec changes of the clusters trigger
+	// the checks in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
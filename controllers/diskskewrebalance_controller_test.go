@@ -0,0 +1,144 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestDiskUsageSkew(t *testing.T) {
+	testCases := []struct {
+		testName string
+		load     *v1beta1.CruiseControlLoadStatus
+		skew     float64
+		known    bool
+	}{
+		{
+			testName: "no load",
+		},
+		{
+			testName: "single broker",
+			load:     &v1beta1.CruiseControlLoadStatus{Brokers: []v1beta1.BrokerLoadStatus{{BrokerID: "0", DiskPercent: "40.00"}}},
+		},
+		{
+			testName: "skewed brokers",
+			load: &v1beta1.CruiseControlLoadStatus{Brokers: []v1beta1.BrokerLoadStatus{
+				{BrokerID: "0", DiskPercent: "40.00"},
+				{BrokerID: "1", DiskPercent: "75.50"},
+				{BrokerID: "2", DiskPercent: "52.25"},
+				{BrokerID: "3", DiskPercent: "unknown"},
+			}},
+			skew:  35.5,
+			known: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			skew, known := diskUsageSkew(test.load)
+			if skew != test.skew || known != test.known {
+				t.Errorf("expected skew %v (known: %v), got %v (known: %v)", test.skew, test.known, skew, known)
+			}
+		})
+	}
+}
+
+func TestDiskSkewRebalanceAllowed(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 0, 0, 0, time.Local)
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	config := &v1beta1.DiskSkewRebalanceConfig{MaxSkewPercent: 20, Cooldown: &metav1.Duration{Duration: time.Hour}}
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	running := &v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "kafka"},
+		Status:     v1alpha1.CruiseControlOperationStatus{State: v1alpha1.OperationStateInExecution},
+	}
+	completed := &v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "completed", Namespace: "kafka"},
+		Status:     v1alpha1.CruiseControlOperationStatus{State: v1alpha1.OperationStateCompleted},
+	}
+	r := &DiskSkewRebalanceReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(running, completed).Build()}
+
+	testCases := []struct {
+		testName string
+		status   *v1beta1.DiskSkewRebalanceStatus
+		expected bool
+	}{
+		{
+			testName: "first rebalance",
+			status:   &v1beta1.DiskSkewRebalanceStatus{},
+			expected: true,
+		},
+		{
+			testName: "cooldown not elapsed",
+			status:   &v1beta1.DiskSkewRebalanceStatus{LastOperation: "completed", LastTriggeredAt: "2022-05-04 11:30:00"},
+		},
+		{
+			testName: "last operation still running",
+			status:   &v1beta1.DiskSkewRebalanceStatus{LastOperation: "running", LastTriggeredAt: "2022-05-04 10:00:00"},
+		},
+		{
+			testName: "last operation completed",
+			status:   &v1beta1.DiskSkewRebalanceStatus{LastOperation: "completed", LastTriggeredAt: "2022-05-04 10:00:00"},
+			expected: true,
+		},
+		{
+			testName: "last operation deleted",
+			status:   &v1beta1.DiskSkewRebalanceStatus{LastOperation: "deleted", LastTriggeredAt: "2022-05-04 10:00:00"},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			allowed, err := r.diskSkewRebalanceAllowed(context.TODO(), cluster, config, test.status, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if allowed != test.expected {
+				t.Errorf("expected rebalance allowed: %v, got: %v", test.expected, allowed)
+			}
+		})
+	}
+}
+
+func TestNewDiskSkewRebalanceOperation(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 0, 0, 0, time.UTC)
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	config := &v1beta1.DiskSkewRebalanceConfig{MaxSkewPercent: 20, Goals: []string{"DiskUsageDistributionGoal"}}
+
+	operation := newDiskSkewRebalanceOperation(cluster, config, now)
+	if operation.Name != "kafka-disk-skew-rebalance-1651665600" || operation.Namespace != "kafka" {
+		t.Errorf("unexpected operation name: %s/%s", operation.Namespace, operation.Name)
+	}
+	if operation.Spec.Operation != v1alpha1.OperationRebalance || operation.Spec.ClusterRef.Name != "kafka" {
+		t.Errorf("unexpected operation spec: %+v", operation.Spec)
+	}
+	if len(operation.Spec.Parameters.Goals) != 1 || len(operation.OwnerReferences) != 1 {
+		t.Errorf("expected the goals and the owner reference of the operation to be set, got: %+v", operation)
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	// DefaultInternalTopicHealthCheckIntervalInSec is the period of checking the health of the internal topics
+	DefaultInternalTopicHealthCheckIntervalInSec = 300
+
+	// defaultInternalTopicReplicationFactor is the replication factor of the internal topics when it is not configured
+	defaultInternalTopicReplicationFactor = 3
+)
+
+// internalTopics are the internal topics of Kafka checked mapped to the broker config setting their replication factor
+var internalTopics = []struct {
+	name                    string
+	replicationFactorConfig string
+}{
+	{name: "__consumer_offsets", replicationFactorConfig: "offsets.topic.replication.factor"},
+	{name: "__transaction_state", replicationFactorConfig: "transaction.state.log.replication.factor"},
+}
+
+// InternalTopicHealthReconciler periodically checks the health of the internal topics of the kafka clusters and
+// raises their replication factor via Cruise Control when requested
+type InternalTopicHealthReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *InternalTopicHealthReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	if instance.Spec.InternalTopics == nil {
+		if instance.Status.InternalTopics != nil {
+			var status *kafkav1beta1.InternalTopicsStatus
+			if err := k8sutil.UpdateCRStatus(r.Client, instance, status, log); err != nil {
+				return requeueWithError(log, "failed to remove the internal topics from the Kafka Cluster status", err)
+			}
+		}
+		return reconciled()
+	}
+
+	if instance.Status.State != kafkav1beta1.KafkaClusterRunning {
+		log.V(1).Info("requeue event as the Kafka cluster is not running")
+		return requeueAfter(DefaultInternalTopicHealthCheckIntervalInSec)
+	}
+
+	broker, closeClient, err := newKafkaFromCluster(r.Client, instance)
+	if err != nil {
+		log.Info("requeue event as connecting to the Kafka cluster failed", "error", err.Error())
+		return requeueAfter(DefaultInternalTopicHealthCheckIntervalInSec)
+	}
+	defer closeClient()
+
+	topics, err := checkInternalTopics(broker, instance)
+	if err != nil {
+		log.Info("requeue event as checking the internal topics failed", "error", err.Error())
+		return requeueAfter(DefaultInternalTopicHealthCheckIntervalInSec)
+	}
+
+	if instance.Spec.InternalTopics.RepairReplicationFactor {
+		r.repairReplicationFactor(ctx, log, instance, topics)
+	}
+
+	if instance.Status.InternalTopics == nil || !reflect.DeepEqual(instance.Status.InternalTopics.Topics, topics) {
+		for _, topic := range topics {
+			if !topic.IsHealthy() {
+				log.Info("internal topic is unhealthy", "topic", topic.Name, "warnings", topic.Warnings)
+			}
+		}
+		status := &kafkav1beta1.InternalTopicsStatus{
+			CheckedAt: time.Now().Format("2006-01-02 15:04:05"),
+			Topics:    topics,
+		}
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, status, log); err != nil {
+			return requeueWithError(log, "failed to update the internal topics in the Kafka Cluster status", err)
+		}
+	}
+	return requeueAfter(DefaultInternalTopicHealthCheckIntervalInSec)
+}
+
+// checkInternalTopics returns the health of the internal topics of the cluster, the topics not created yet, e.g.
+// __transaction_state before the first transaction, are skipped
+func checkInternalTopics(broker kafkaclient.KafkaClient, instance *kafkav1beta1.KafkaCluster) ([]kafkav1beta1.InternalTopicHealth, error) {
+	readOnlyConfig, err := properties.NewFromString(instance.Spec.ReadOnlyConfig)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse broker config")
+	}
+
+	topics := make([]kafkav1beta1.InternalTopicHealth, 0, len(internalTopics))
+	for _, internalTopic := range internalTopics {
+		health, err := broker.TopicHealth(internalTopic.name)
+		if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		expected := expectedReplicationFactor(readOnlyConfig, internalTopic.replicationFactorConfig, len(instance.Spec.Brokers))
+		topic := newInternalTopicHealth(internalTopic.name, health, expected, len(instance.Spec.Brokers))
+		if last := instance.Status.InternalTopics.GetTopic(internalTopic.name); last != nil {
+			topic.RepairTaskID = last.RepairTaskID
+		}
+		topics = append(topics, topic)
+	}
+	return topics, nil
+}
+
+// repairReplicationFactor raises the replication factor of the internal topics having less replicas than expected
+// via Cruise Control, nothing is done while Cruise Control is executing a task, e.g. the previous repair
+func (r *InternalTopicHealthReconciler) repairReplicationFactor(ctx context.Context, log logr.Logger,
+	instance *kafkav1beta1.KafkaCluster, topics []kafkav1beta1.InternalTopicHealth) {
+	var repaired []int
+	for i, topic := range topics {
+		if topic.ReplicationFactor < topic.ExpectedReplicationFactor {
+			repaired = append(repaired, i)
+		}
+	}
+	if len(repaired) == 0 {
+		return
+	}
+
+	if instance.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		instance.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		log.V(1).Info("skipping the repair of the internal topics as Cruise Control is not deployed (yet)")
+		return
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, instance)
+	if err != nil {
+		log.Error(err, "failed to create Cruise Control Scaler instance")
+		return
+	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+	scaler = scale.QueuedCruiseControlScaler(scale.DefaultOperationQueue, instance, "InternalTopicHealth", scale.TriggerPeriodic, scaler)
+
+	if !scaler.IsUp(ctx) || scaler.Status(ctx).InExecution() {
+		log.V(1).Info("skipping the repair of the internal topics as Cruise Control is not up or is executing a task")
+		return
+	}
+
+	for _, i := range repaired {
+		topic := &topics[i]
+		log.Info("raising the replication factor of internal topic", "topic", topic.Name,
+			"replicationFactor", topic.ReplicationFactor, "expectedReplicationFactor", topic.ExpectedReplicationFactor)
+		result, err := scaler.UpdateTopicReplicationFactor(ctx, topic.Name, topic.ExpectedReplicationFactor)
+		if err != nil {
+			log.Error(err, "raising the replication factor of internal topic could not be started", "topic", topic.Name)
+			continue
+		}
+		topic.RepairTaskID = result.TaskID
+	}
+}
+
+// expectedReplicationFactor returns the replication factor set by the given broker config, capped by the number of
+// brokers as Kafka does when creating the internal topics
+func expectedReplicationFactor(readOnlyConfig *properties.Properties, config string, brokers int) int32 {
+	replicationFactor := int64(defaultInternalTopicReplicationFactor)
+	if property, ok := readOnlyConfig.Get(config); ok {
+		if value, err := property.Int(); err == nil && value > 0 {
+			replicationFactor = value
+		}
+	}
+	if brokers > 0 && replicationFactor > int64(brokers) {
+		replicationFactor = int64(brokers)
+	}
+	return int32(replicationFactor)
+}
+
+// newInternalTopicHealth returns the health status of the internal topic with the issues found listed as warnings
+func newInternalTopicHealth(name string, health *kafkaclient.TopicHealth, expectedReplicationFactor int32, brokers int) kafkav1beta1.InternalTopicHealth {
+	topic := kafkav1beta1.InternalTopicHealth{
+		Name:                      name,
+		ReplicationFactor:         health.ReplicationFactor,
+		ExpectedReplicationFactor: expectedReplicationFactor,
+		UnderReplicatedPartitions: health.UnderReplicatedPartitions,
+		OfflinePartitions:         health.OfflinePartitions,
+		NonPreferredLeaders:       health.NonPreferredLeaders,
+	}
+
+	for _, leaders := range health.LeadersByBroker {
+		if leaders > topic.MaxLeadersPerBroker {
+			topic.MaxLeadersPerBroker = leaders
+		}
+	}
+
+	if topic.ReplicationFactor < expectedReplicationFactor {
+		topic.Warnings = append(topic.Warnings, fmt.Sprintf("replication factor %d is below the expected %d",
+			topic.ReplicationFactor, expectedReplicationFactor))
+	}
+	if topic.OfflinePartitions > 0 {
+		topic.Warnings = append(topic.Warnings, fmt.Sprintf("%d partitions are offline", topic.OfflinePartitions))
+	}
+	if topic.UnderReplicatedPartitions > 0 {
+		topic.Warnings = append(topic.Warnings, fmt.Sprintf("%d partitions are under-replicated", topic.UnderReplicatedPartitions))
+	}
+	if topic.NonPreferredLeaders > 0 {
+		topic.Warnings = append(topic.Warnings, fmt.Sprintf("%d partitions are not led by their preferred replica", topic.NonPreferredLeaders))
+	}
+	// the leadership is skewed when a broker leads more than twice its fair share of the partitions
+	if brokers > 1 && topic.MaxLeadersPerBroker > 2*((health.Partitions+int32(brokers)-1)/int32(brokers)) {
+		topic.Warnings = append(topic.Warnings, fmt.Sprintf("leadership is skewed, a broker leads %d of %d partitions",
+			topic.MaxLeadersPerBroker, health.Partitions))
+	}
+	return topic
+}
+
+// SetupInternalTopicHealthWithManager registers the internal topic health controller to the manager
+func SetupInternalTopicHealthWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("InternalTopicHealth")
+
+	// the health checks are periodic, only the creation, the deletion and the spec changes of the clusters
+	// trigger them in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
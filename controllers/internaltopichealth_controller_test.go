@@ -0,0 +1,98 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+func TestExpectedReplicationFactor(t *testing.T) {
+	readOnlyConfig, err := properties.NewFromString("offsets.topic.replication.factor=2\ntransaction.state.log.replication.factor=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		config   string
+		brokers  int
+		expected int32
+	}{
+		{config: "offsets.topic.replication.factor", brokers: 6, expected: 2},
+		{config: "transaction.state.log.replication.factor", brokers: 6, expected: 5},
+		{config: "transaction.state.log.replication.factor", brokers: 4, expected: 4},
+		{config: "not.configured.replication.factor", brokers: 6, expected: 3},
+		{config: "not.configured.replication.factor", brokers: 1, expected: 1},
+	}
+	for _, test := range testCases {
+		if replicationFactor := expectedReplicationFactor(readOnlyConfig, test.config, test.brokers); replicationFactor != test.expected {
+			t.Errorf("expected replication factor %d of %s with %d brokers, got: %d", test.expected, test.config, test.brokers, replicationFactor)
+		}
+	}
+}
+
+func TestNewInternalTopicHealth(t *testing.T) {
+	testCases := []struct {
+		testName string
+		health   kafkaclient.TopicHealth
+		expected v1beta1.InternalTopicHealth
+	}{
+		{
+			testName: "healthy topic",
+			health: kafkaclient.TopicHealth{Partitions: 6, ReplicationFactor: 3,
+				LeadersByBroker: map[int32]int32{0: 2, 1: 2, 2: 2}},
+			expected: v1beta1.InternalTopicHealth{Name: "__consumer_offsets", ReplicationFactor: 3,
+				ExpectedReplicationFactor: 3, MaxLeadersPerBroker: 2},
+		},
+		{
+			testName: "mis-replicated topic",
+			health: kafkaclient.TopicHealth{Partitions: 6, ReplicationFactor: 1, UnderReplicatedPartitions: 2,
+				OfflinePartitions: 1, NonPreferredLeaders: 3, LeadersByBroker: map[int32]int32{0: 1, 1: 4}},
+			expected: v1beta1.InternalTopicHealth{Name: "__consumer_offsets", ReplicationFactor: 1,
+				ExpectedReplicationFactor: 3, UnderReplicatedPartitions: 2, OfflinePartitions: 1, NonPreferredLeaders: 3,
+				MaxLeadersPerBroker: 4, Warnings: []string{
+					"replication factor 1 is below the expected 3",
+					"1 partitions are offline",
+					"2 partitions are under-replicated",
+					"3 partitions are not led by their preferred replica",
+				}},
+		},
+		{
+			testName: "skewed leadership",
+			health: kafkaclient.TopicHealth{Partitions: 8, ReplicationFactor: 3,
+				LeadersByBroker: map[int32]int32{0: 7, 1: 1}},
+			expected: v1beta1.InternalTopicHealth{Name: "__consumer_offsets", ReplicationFactor: 3,
+				ExpectedReplicationFactor: 3, MaxLeadersPerBroker: 7, Warnings: []string{
+					"leadership is skewed, a broker leads 7 of 8 partitions",
+				}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			topic := newInternalTopicHealth("__consumer_offsets", &test.health, 3, 3)
+			if !reflect.DeepEqual(topic, test.expected) {
+				t.Errorf("expected internal topic health: %+v, got: %+v", test.expected, topic)
+			}
+			if topic.IsHealthy() != (len(test.expected.Warnings) == 0) {
+				t.Errorf("unexpected health of internal topic: %+v", topic)
+			}
+		})
+	}
+}
@@ -23,14 +23,20 @@ import (
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 
@@ -41,6 +47,7 @@ import (
 	"github.com/banzaicloud/koperator/pkg/kafkaclient"
 	"github.com/banzaicloud/koperator/pkg/pki"
 	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/resources/connectioninfo"
 	"github.com/banzaicloud/koperator/pkg/resources/cruisecontrol"
 	"github.com/banzaicloud/koperator/pkg/resources/cruisecontrolmonitoring"
 	"github.com/banzaicloud/koperator/pkg/resources/envoy"
@@ -48,6 +55,8 @@ import (
 	"github.com/banzaicloud/koperator/pkg/resources/kafka"
 	"github.com/banzaicloud/koperator/pkg/resources/kafkamonitoring"
 	"github.com/banzaicloud/koperator/pkg/resources/nodeportexternalaccess"
+	"github.com/banzaicloud/koperator/pkg/resources/smoketest"
+	"github.com/banzaicloud/koperator/pkg/scale"
 	"github.com/banzaicloud/koperator/pkg/util"
 )
 
@@ -61,6 +70,7 @@ type KafkaClusterReconciler struct {
 	DirectClient        client.Reader
 	Namespaces          []string
 	KafkaClientProvider kafkaclient.Provider
+	Recorder            record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a KafkaCluster object and makes changes based on the state read
@@ -68,12 +78,16 @@ type KafkaClusterReconciler struct {
 // Automatically generate RBAC rules to allow the Controller to read and write Deployments
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete;patch
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
@@ -117,8 +131,10 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 		nodeportexternalaccess.New(r.Client, instance),
 		kafkamonitoring.New(r.Client, instance),
 		cruisecontrolmonitoring.New(r.Client, instance),
-		kafka.New(r.Client, r.DirectClient, instance, r.KafkaClientProvider),
+		kafka.New(r.Client, r.DirectClient, instance, r.KafkaClientProvider, r.Recorder),
+		connectioninfo.New(r.Client, instance, r.Recorder),
 		cruisecontrol.New(r.Client, instance),
+		smoketest.New(r.Client, instance),
 	}
 
 	for _, rec := range reconcilers {
@@ -165,6 +181,31 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 				return ctrl.Result{
 					RequeueAfter: time.Duration(30) * time.Second,
 				}, nil
+			case errorfactory.MaintenanceWindowClosed:
+				log.Info("Disruptive operation postponed until the next maintenance window", "error", err.Error())
+				return ctrl.Result{
+					RequeueAfter: time.Duration(1) * time.Minute,
+				}, nil
+			case errorfactory.PreflightChecksFailed:
+				log.Info("Pre-flight checks failed, see the status of the KafkaCluster for details", "error", err.Error())
+				return ctrl.Result{
+					RequeueAfter: time.Duration(30) * time.Second,
+				}, nil
+			case errorfactory.SmokeTestNotPassed:
+				log.Info("Smoke test of the previous change has not passed yet", "error", err.Error())
+				return ctrl.Result{
+					RequeueAfter: time.Duration(30) * time.Second,
+				}, nil
+			case errorfactory.InvalidBrokerConfig:
+				log.Info("Broker configuration is invalid, see the status of the KafkaCluster for details", "error", err.Error())
+				return ctrl.Result{
+					RequeueAfter: time.Duration(1) * time.Minute,
+				}, nil
+			case errorfactory.CanaryBrokersUnhealthy:
+				log.Info("Rollout of the Kafka version is held back as the canary brokers are unhealthy", "error", err.Error())
+				return ctrl.Result{
+					RequeueAfter: time.Duration(30) * time.Second,
+				}, nil
 			default:
 				return requeueWithError(log, err.Error(), err)
 			}
@@ -181,6 +222,18 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 		if err := k8sutil.UpdateRollingUpgradeState(r.Client, instance, time.Now(), log); err != nil {
 			return requeueWithError(log, err.Error(), err)
 		}
+		record := v1beta1.OperationRecord{
+			Type:       v1beta1.OperationTypeRollingUpgrade,
+			FinishedAt: instance.Status.RollingUpgrade.LastSuccess,
+			Outcome:    v1beta1.OperationSucceeded,
+		}
+		if err := k8sutil.RecordOperation(r.Client, instance, record, log); err != nil {
+			return requeueWithError(log, err.Error(), err)
+		}
+		if err := kafka.ResumeSamplingAfterRollingUpgrade(ctx, log, r.Client, instance); err != nil {
+			// the sampling is resumed after the next rolling upgrade, or by the users, it does not fail the reconcile
+			log.Error(err, "failed to resume the metrics sampling of Cruise Control after the rolling upgrade")
+		}
 	}
 
 	if err := k8sutil.UpdateCRStatus(r.Client, instance, v1beta1.KafkaClusterRunning, log); err != nil {
@@ -301,6 +354,8 @@ func (r *KafkaClusterReconciler) checkFinalizers(ctx context.Context, cluster *v
 	}
 
 	log.Info("Finalizing deletion of kafkacluster instance")
+	scale.DefaultScalerRegistry.Forget(cluster.Namespace, cluster.Name)
+	scale.DefaultOperationQueue.Forget(cluster.Namespace, cluster.Name)
 	if _, err = r.removeFinalizer(ctx, cluster, clusterFinalizer); err != nil {
 		if client.IgnoreNotFound(err) == nil {
 			// We may have been a requeue from earlier with all conditions met - but with
@@ -339,7 +394,7 @@ func (r *KafkaClusterReconciler) removeFinalizer(ctx context.Context, cluster *v
 
 func (r *KafkaClusterReconciler) updateAndFetchLatest(ctx context.Context, cluster *v1beta1.KafkaCluster) (*v1beta1.KafkaCluster, error) {
 	typeMeta := cluster.TypeMeta
-	err := r.Client.Update(ctx, cluster)
+	err := r.Client.Update(ctx, cluster, client.FieldOwner(fieldOwner))
 	if err != nil {
 		return nil, err
 	}
@@ -358,6 +413,8 @@ func SetupKafkaClusterWithManager(mgr ctrl.Manager) *ctrl.Builder {
 	kafkaWatches(builder)
 	envoyWatches(builder)
 	cruiseControlWatches(builder)
+	smokeTestWatches(builder)
+	saslPlainCredentialsWatches(builder, mgr.GetClient(), log)
 
 	builder.WithEventFilter(
 		predicate.Funcs{
@@ -406,6 +463,7 @@ func envoyWatches(builder *ctrl.Builder) *ctrl.Builder {
 	return builder.
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&autoscalingv2beta2.HorizontalPodAutoscaler{}).
 		Owns(&corev1.ConfigMap{})
 }
 
@@ -415,3 +473,45 @@ func cruiseControlWatches(builder *ctrl.Builder) *ctrl.Builder {
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.ConfigMap{})
 }
+
+func smokeTestWatches(builder *ctrl.Builder) *ctrl.Builder {
+	return builder.
+		Owns(&batchv1.Job{})
+}
+
+// saslPlainCredentialsWatches triggers the reconciliation of the KafkaClusters using the SASL/PLAIN credentials secret
+// which has changed so that the brokers can reload the credentials
+func saslPlainCredentialsWatches(builder *ctrl.Builder, c client.Reader, log logr.Logger) *ctrl.Builder {
+	return builder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []ctrl.Request {
+			var clusters v1beta1.KafkaClusterList
+			if err := c.List(context.Background(), &clusters, client.InNamespace(obj.GetNamespace())); err != nil {
+				log.Error(err, "couldn't list KafkaClusters", "namespace", obj.GetNamespace())
+				return []ctrl.Request{}
+			}
+			var requests []ctrl.Request
+			for _, cluster := range clusters.Items {
+				if usesSASLPlainCredentialsSecret(cluster.Spec.ListenersConfig, obj.GetName()) {
+					requests = append(requests, ctrl.Request{
+						NamespacedName: types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name},
+					})
+				}
+			}
+			return requests
+		}))
+}
+
+func usesSASLPlainCredentialsSecret(listenersConfig v1beta1.ListenersConfig, secretName string) bool {
+	for _, iListener := range listenersConfig.InternalListeners {
+		if iListener.GetSASLPlainCredentialsSecretName() == secretName {
+			return true
+		}
+	}
+	for _, eListener := range listenersConfig.ExternalListeners {
+		if eListener.GetSASLPlainCredentialsSecretName() == secretName {
+			return true
+		}
+	}
+	return false
+}
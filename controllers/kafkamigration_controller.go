@@ -0,0 +1,351 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/migration"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+var migrationFinalizer = "finalizer.kafkamigrations.kafka.banzaicloud.io"
+
+const (
+	// migrationSyncIntervalInSec is the period of measuring the lag while the topics are replicated
+	migrationSyncIntervalInSec = 30
+	// migrationCutoverSyncIntervalInSec is the period of measuring the lag while the producers are paused
+	migrationCutoverSyncIntervalInSec = 5
+
+	migrationStatusTimeFormat = "2006-01-02 15:04:05"
+)
+
+// clampedProducerByteRate is the producer quota of the source cluster during the cutover, Kafka does not accept a
+// zero quota
+var clampedProducerByteRate int64 = 1
+
+// SetupKafkaMigrationWithManager registers kafka migration controller with manager
+func SetupKafkaMigrationWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.KafkaMigration{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.ConfigMap{}).
+		Named("KafkaMigration")
+}
+
+// blank assignment to verify that KafkaMigrationReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &KafkaMigrationReconciler{}
+
+// KafkaMigrationReconciler replicates the topics of a KafkaCluster to another one with MirrorMaker 2 and cuts the
+// clients over once the target cluster caught up
+type KafkaMigrationReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkamigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkamigrations/status,verbs=get;update;patch
+
+// Reconcile reconciles the kafka migration
+func (r *KafkaMigrationReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := logr.FromContextOrDiscard(ctx)
+	reqLogger.Info("Reconciling KafkaMigration")
+	var err error
+
+	instance := &v1alpha1.KafkaMigration{}
+	if err = r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(reqLogger, err.Error(), err)
+	}
+
+	source, err := k8sutil.LookupKafkaCluster(ctx, r.Client, instance.Spec.SourceClusterRef.Name,
+		getClusterRefNamespace(instance.Namespace, instance.Spec.SourceClusterRef))
+	if err != nil {
+		if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+			reqLogger.Info("Source cluster is already gone, there is nothing to clean up")
+			return r.removeFinalizer(ctx, instance)
+		}
+		return requeueWithError(reqLogger, "failed to lookup the source cluster", err)
+	}
+	target, err := k8sutil.LookupKafkaCluster(ctx, r.Client, instance.Spec.TargetClusterRef.Name,
+		getClusterRefNamespace(instance.Namespace, instance.Spec.TargetClusterRef))
+	if err != nil && !k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return requeueWithError(reqLogger, "failed to lookup the target cluster", err)
+	}
+
+	sourceBroker, closeSource, err := newKafkaFromCluster(r.Client, source)
+	if err != nil {
+		return checkBrokerConnectionError(reqLogger, err)
+	}
+	defer closeSource()
+
+	// the producers of the source cluster must not stay paused once the migration is gone
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		if util.StringSliceContains(instance.GetFinalizers(), migrationFinalizer) {
+			if err = releaseProducerQuotas(sourceBroker, instance.Status); err != nil {
+				return requeueWithError(reqLogger, "failed to restore the producer quotas of the source cluster", err)
+			}
+		}
+		return r.removeFinalizer(ctx, instance)
+	}
+
+	if !util.StringSliceContains(instance.GetFinalizers(), migrationFinalizer) {
+		reqLogger.Info("Adding Finalizer for the KafkaMigration")
+		instance.SetFinalizers(append(instance.GetFinalizers(), migrationFinalizer))
+		if err = r.Client.Update(ctx, instance, client.FieldOwner(fieldOwner)); err != nil {
+			return requeueWithError(reqLogger, "failed to add Finalizer to KafkaMigration", err)
+		}
+	}
+
+	if instance.Status.Phase == v1alpha1.MigrationPhaseCompleted {
+		if err = r.deleteMirrorMaker(ctx, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to delete MirrorMaker 2", err)
+		}
+		return reconciled()
+	}
+
+	if err = r.ensureMirrorMaker(reqLogger, instance, source, target); err != nil {
+		return requeueWithError(reqLogger, "failed to ensure MirrorMaker 2", err)
+	}
+
+	targetBroker, closeTarget, err := newKafkaFromCluster(r.Client, target)
+	if err != nil {
+		return checkBrokerConnectionError(reqLogger, err)
+	}
+	defer closeTarget()
+
+	status, err := syncMigration(reqLogger, sourceBroker, targetBroker, instance, time.Now())
+	if err != nil {
+		return requeueWithError(reqLogger, "failed to sync the migration", err)
+	}
+	if !reflect.DeepEqual(status, instance.Status) {
+		instance.Status = status
+		if err = r.Client.Status().Update(ctx, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to update kafkamigration status", err)
+		}
+	}
+
+	switch status.Phase {
+	case v1alpha1.MigrationPhaseCompleted:
+		reqLogger.Info("Migration completed, the target cluster is authoritative")
+		if err = r.deleteMirrorMaker(ctx, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to delete MirrorMaker 2", err)
+		}
+		return reconciled()
+	case v1alpha1.MigrationPhaseCuttingOver:
+		return requeueAfter(migrationCutoverSyncIntervalInSec)
+	default:
+		return requeueAfter(migrationSyncIntervalInSec)
+	}
+}
+
+// syncMigration measures the lag of the target cluster and moves the migration through its phases
+func syncMigration(log logr.Logger, sourceBroker, targetBroker kafkaclient.KafkaClient, instance *v1alpha1.KafkaMigration, now time.Time) (v1alpha1.KafkaMigrationStatus, error) {
+	status := *instance.Status.DeepCopy()
+
+	topicPattern, err := migration.CompilePattern(instance.Spec.GetTopics())
+	if err != nil {
+		return status, err
+	}
+	topics, err := sourceBroker.ListTopics()
+	if err != nil {
+		return status, err
+	}
+	migratedTopics := migration.MigratedTopics(topics, topicPattern)
+	sourceOffsets, err := sourceBroker.TopicOffsets(migratedTopics)
+	if err != nil {
+		return status, err
+	}
+	// the topics MirrorMaker 2 did not create yet have no messages in the target cluster
+	targetTopics, err := targetBroker.ListTopics()
+	if err != nil {
+		return status, err
+	}
+	existingTopics := make([]string, 0, len(migratedTopics))
+	for _, topic := range migratedTopics {
+		if _, ok := targetTopics[topic]; ok {
+			existingTopics = append(existingTopics, topic)
+		}
+	}
+	targetOffsets, err := targetBroker.TopicOffsets(existingTopics)
+	if err != nil {
+		return status, err
+	}
+
+	lag := migration.Lag(sourceOffsets, targetOffsets)
+	status.Lag = lag
+	status.Topics = int32(len(migratedTopics))
+	status.SyncedAt = now.UTC().Format(migrationStatusTimeFormat)
+
+	switch status.Phase {
+	case v1alpha1.MigrationPhaseCuttingOver:
+		// MirrorMaker 2 is deleted once the cutover completes, so every message must have been replicated by then
+		if lag == 0 {
+			groups, err := translateConsumerGroupOffsets(log, sourceBroker, targetBroker, instance, sourceOffsets, targetOffsets)
+			if err != nil {
+				return status, err
+			}
+			status.TranslatedGroups = groups
+			status.Phase = v1alpha1.MigrationPhaseCompleted
+			status.CompletedAt = now.UTC().Format(migrationStatusTimeFormat)
+			status.Message = "the target cluster is authoritative, the producers of the source cluster stay paused until the migration is deleted"
+			return status, nil
+		}
+		startedAt, err := time.Parse(migrationStatusTimeFormat, status.CutoverStartedAt)
+		if err != nil || now.UTC().Sub(startedAt) < time.Duration(instance.Spec.GetCutoverTimeoutSeconds())*time.Second {
+			return status, nil
+		}
+		log.Info("Cutover timed out, resuming the producers of the source cluster", "lag", lag)
+		if err = releaseProducerQuotas(sourceBroker, status); err != nil {
+			return status, err
+		}
+		status.PreviousProducerQuotas = nil
+		status.PreviousEntityProducerQuotas = nil
+		status.CutoverStartedAt = ""
+		status.FailedCutoverGeneration = instance.Generation
+		status.Phase = v1alpha1.MigrationPhaseReplicating
+		status.Message = fmt.Sprintf("the cutover timed out with a lag of %d messages, it has to be approved again", lag)
+		return status, nil
+	default:
+		status.Phase = v1alpha1.MigrationPhaseReplicating
+		if lag > instance.Spec.MaxCutoverLag {
+			return status, nil
+		}
+		status.Phase = v1alpha1.MigrationPhaseReadyForCutover
+		if !instance.Spec.Cutover || status.FailedCutoverGeneration == instance.Generation {
+			return status, nil
+		}
+		log.Info("Starting the cutover, pausing the producers of the source cluster", "lag", lag)
+		if status.PreviousProducerQuotas, err = sourceBroker.DefaultProducerByteRates(); err != nil {
+			return status, err
+		}
+		// the users and client ids having producer quotas of their own are not limited by the default ones
+		if status.PreviousEntityProducerQuotas, err = sourceBroker.EntityProducerByteRates(); err != nil {
+			return status, err
+		}
+		for _, entityType := range kafkaclient.ProducerQuotaEntityTypes {
+			if err = sourceBroker.SetDefaultProducerByteRate(entityType, &clampedProducerByteRate); err != nil {
+				return status, err
+			}
+		}
+		for _, quota := range status.PreviousEntityProducerQuotas {
+			if err = sourceBroker.SetEntityProducerByteRate(quota.Entity, clampedProducerByteRate); err != nil {
+				return status, err
+			}
+		}
+		status.Phase = v1alpha1.MigrationPhaseCuttingOver
+		status.CutoverStartedAt = now.UTC().Format(migrationStatusTimeFormat)
+		status.Message = ""
+		return status, nil
+	}
+}
+
+// translateConsumerGroupOffsets commits the offsets of the consumer groups of the source cluster translated to the
+// target cluster and returns the names of the groups
+func translateConsumerGroupOffsets(log logr.Logger, sourceBroker, targetBroker kafkaclient.KafkaClient, instance *v1alpha1.KafkaMigration,
+	sourceOffsets, targetOffsets map[string]map[int32]kafkaclient.PartitionOffsets) ([]string, error) {
+	groupPattern, err := migration.CompilePattern(instance.Spec.GetGroups())
+	if err != nil {
+		return nil, err
+	}
+	committed, err := sourceBroker.ConsumerGroupOffsets(migration.TopicPartitions(sourceOffsets))
+	if err != nil {
+		return nil, err
+	}
+	groups := migration.MatchingGroups(committed, groupPattern)
+	for _, group := range groups {
+		offsets := migration.TranslateOffsets(committed[group], sourceOffsets, targetOffsets)
+		if err = targetBroker.CommitConsumerGroupOffsets(group, offsets); err != nil {
+			return nil, err
+		}
+		log.Info("Translated the offsets of the consumer group", "group", group)
+	}
+	return groups, nil
+}
+
+// releaseProducerQuotas restores the default producer quotas and the ones of the users and client ids the source
+// cluster had before the cutover
+func releaseProducerQuotas(sourceBroker kafkaclient.KafkaClient, status v1alpha1.KafkaMigrationStatus) error {
+	if status.CutoverStartedAt == "" {
+		return nil
+	}
+	for _, entityType := range kafkaclient.ProducerQuotaEntityTypes {
+		var rate *int64
+		if previous, ok := status.PreviousProducerQuotas[entityType]; ok {
+			rate = &previous
+		}
+		if err := sourceBroker.SetDefaultProducerByteRate(entityType, rate); err != nil {
+			return err
+		}
+	}
+	for _, quota := range status.PreviousEntityProducerQuotas {
+		if err := sourceBroker.SetEntityProducerByteRate(quota.Entity, quota.ByteRate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *KafkaMigrationReconciler) ensureMirrorMaker(log logr.Logger, instance *v1alpha1.KafkaMigration, source, target *v1beta1.KafkaCluster) error {
+	configMap, err := migration.ConfigMap(instance, source, target)
+	if err != nil {
+		return err
+	}
+	if err = k8sutil.Reconcile(log, r.Client, configMap, nil); err != nil {
+		return err
+	}
+	return k8sutil.Reconcile(log, r.Client, migration.Deployment(instance, source, target, configMap), nil)
+}
+
+func (r *KafkaMigrationReconciler) deleteMirrorMaker(ctx context.Context, instance *v1alpha1.KafkaMigration) error {
+	for _, o := range []client.Object{&appsv1.Deployment{}, &corev1.ConfigMap{}} {
+		o.SetName(migration.Name(instance))
+		o.SetNamespace(instance.Namespace)
+		if err := r.Client.Delete(ctx, o); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *KafkaMigrationReconciler) removeFinalizer(ctx context.Context, instance *v1alpha1.KafkaMigration) (reconcile.Result, error) {
+	reqLogger := logr.FromContextOrDiscard(ctx)
+	if !util.StringSliceContains(instance.GetFinalizers(), migrationFinalizer) {
+		return reconciled()
+	}
+	instance.SetFinalizers(util.StringSliceRemove(instance.GetFinalizers(), migrationFinalizer))
+	if err := r.Client.Update(ctx, instance, client.FieldOwner(fieldOwner)); err != nil {
+		return requeueWithError(reqLogger, "failed to remove finalizer from kafkamigration", err)
+	}
+	return reconciled()
+}
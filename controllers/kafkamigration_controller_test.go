@@ -0,0 +1,178 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+func TestSyncMigration(t *testing.T) {
+	sourceBroker, closeSource, err := kafkaclient.NewMockFromCluster(nil, &v1beta1.KafkaCluster{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeSource()
+	targetBroker, closeTarget, err := kafkaclient.NewMockFromCluster(nil, &v1beta1.KafkaCluster{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTarget()
+	if err := sourceBroker.CreateTopic(&kafkaclient.CreateTopicOptions{Name: "orders", Partitions: 1, ReplicationFactor: 1}); err != nil {
+		t.Fatal(err)
+	}
+	previousRate := int64(1048576)
+	if err := sourceBroker.SetDefaultProducerByteRate("user", &previousRate); err != nil {
+		t.Fatal(err)
+	}
+	// a user with a producer quota of its own is not limited by the default producer quotas
+	alice := "alice"
+	aliceQuota := []v1alpha1.QuotaEntityComponent{{EntityType: "user", Name: &alice}}
+	if err := sourceBroker.SetEntityProducerByteRate(aliceQuota, 2*previousRate); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	instance := &v1alpha1.KafkaMigration{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       v1alpha1.KafkaMigrationSpec{MaxCutoverLag: 1000},
+	}
+	status, err := syncMigration(logr.Discard(), sourceBroker, targetBroker, instance, now)
+	if err != nil {
+		t.Fatal("Expected no error on syncMigration, got:", err)
+	}
+	if status.Phase != v1alpha1.MigrationPhaseReadyForCutover || status.Topics != 1 {
+		t.Error("Expected the migration of the topic to be ready for the cutover, got:", status)
+	}
+
+	// the cutover pauses the producers of the source cluster
+	instance.Spec.Cutover = true
+	instance.Status = status
+	if status, err = syncMigration(logr.Discard(), sourceBroker, targetBroker, instance, now); err != nil {
+		t.Fatal("Expected no error on syncMigration, got:", err)
+	}
+	if status.Phase != v1alpha1.MigrationPhaseCuttingOver {
+		t.Error("Expected the cutover to be started, got:", status.Phase)
+	}
+	if !reflect.DeepEqual(status.PreviousProducerQuotas, map[string]int64{"user": previousRate}) {
+		t.Error("Expected the previous producer quotas to be recorded, got:", status.PreviousProducerQuotas)
+	}
+	if rates, _ := sourceBroker.DefaultProducerByteRates(); !reflect.DeepEqual(rates, map[string]int64{"user": 1, "client-id": 1}) {
+		t.Error("Expected the default producer quotas to be clamped, got:", rates)
+	}
+	expectedPrevious := []v1alpha1.ProducerQuota{{Entity: aliceQuota, ByteRate: 2 * previousRate}}
+	if !reflect.DeepEqual(status.PreviousEntityProducerQuotas, expectedPrevious) {
+		t.Error("Expected the previous producer quota of the user to be recorded, got:", status.PreviousEntityProducerQuotas)
+	}
+	expectedClamped := []v1alpha1.ProducerQuota{{Entity: aliceQuota, ByteRate: clampedProducerByteRate}}
+	if quotas, _ := sourceBroker.EntityProducerByteRates(); !reflect.DeepEqual(quotas, expectedClamped) {
+		t.Error("Expected the producer quota of the user to be clamped, got:", quotas)
+	}
+
+	// the target cluster becomes authoritative once it caught up
+	instance.Status = status
+	if status, err = syncMigration(logr.Discard(), sourceBroker, targetBroker, instance, now.Add(time.Minute)); err != nil {
+		t.Fatal("Expected no error on syncMigration, got:", err)
+	}
+	if status.Phase != v1alpha1.MigrationPhaseCompleted || status.CompletedAt == "" {
+		t.Error("Expected the migration to be completed, got:", status)
+	}
+
+	if err := releaseProducerQuotas(sourceBroker, status); err != nil {
+		t.Fatal("Expected no error on releaseProducerQuotas, got:", err)
+	}
+	if rates, _ := sourceBroker.DefaultProducerByteRates(); !reflect.DeepEqual(rates, map[string]int64{"user": previousRate}) {
+		t.Error("Expected the previous producer quotas to be restored, got:", rates)
+	}
+	if quotas, _ := sourceBroker.EntityProducerByteRates(); !reflect.DeepEqual(quotas, expectedPrevious) {
+		t.Error("Expected the previous producer quota of the user to be restored, got:", quotas)
+	}
+}
+
+func TestSyncMigrationCutoverTimeout(t *testing.T) {
+	sourceBroker, closeSource, err := kafkaclient.NewMockFromCluster(nil, &v1beta1.KafkaCluster{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeSource()
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	instance := &v1alpha1.KafkaMigration{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       v1alpha1.KafkaMigrationSpec{Cutover: true, CutoverTimeoutSeconds: 60, MaxCutoverLag: 1000},
+		Status: v1alpha1.KafkaMigrationStatus{
+			Phase:                        v1alpha1.MigrationPhaseCuttingOver,
+			CutoverStartedAt:             "2022-05-10 11:58:00",
+			PreviousEntityProducerQuotas: []v1alpha1.ProducerQuota{{Entity: []v1alpha1.QuotaEntityComponent{{EntityType: "client-id"}, {EntityType: "user"}}, ByteRate: 1024}},
+		},
+	}
+	for _, entityType := range kafkaclient.ProducerQuotaEntityTypes {
+		if err := sourceBroker.SetDefaultProducerByteRate(entityType, &clampedProducerByteRate); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the target cluster lags behind as the rest of the messages are not replicated yet
+	if err := sourceBroker.CreateTopic(&kafkaclient.CreateTopicOptions{Name: "orders", Partitions: 1, ReplicationFactor: 1}); err != nil {
+		t.Fatal(err)
+	}
+	laggingSource := &offsetsKafkaClient{
+		KafkaClient: sourceBroker,
+		offsets:     map[string]map[int32]kafkaclient.PartitionOffsets{"orders": {0: {End: 100}}},
+	}
+	status, err := syncMigration(logr.Discard(), laggingSource, sourceBroker, instance, now)
+	if err != nil {
+		t.Fatal("Expected no error on syncMigration, got:", err)
+	}
+	if status.Phase != v1alpha1.MigrationPhaseReplicating || status.FailedCutoverGeneration != 2 {
+		t.Error("Expected the timed out cutover to be abandoned, got:", status)
+	}
+	if rates, _ := sourceBroker.DefaultProducerByteRates(); len(rates) != 0 {
+		t.Error("Expected the clamped producer quotas to be removed, got:", rates)
+	}
+	if quotas, _ := sourceBroker.EntityProducerByteRates(); len(quotas) != 1 || quotas[0].ByteRate != 1024 {
+		t.Error("Expected the previous producer quota of the default users and client ids to be restored, got:", quotas)
+	}
+	if status.PreviousEntityProducerQuotas != nil {
+		t.Error("Expected the previous producer quotas to be forgotten, got:", status.PreviousEntityProducerQuotas)
+	}
+
+	// the cutover is not started again until the spec changes
+	instance.Status = status
+	if status, err = syncMigration(logr.Discard(), laggingSource, sourceBroker, instance, now); err != nil {
+		t.Fatal("Expected no error on syncMigration, got:", err)
+	}
+	if status.Phase != v1alpha1.MigrationPhaseReadyForCutover {
+		t.Error("Expected the migration to wait for a new approval of the cutover, got:", status.Phase)
+	}
+}
+
+// offsetsKafkaClient reports the given offsets for the partitions of the topics
+type offsetsKafkaClient struct {
+	kafkaclient.KafkaClient
+	offsets map[string]map[int32]kafkaclient.PartitionOffsets
+}
+
+func (c *offsetsKafkaClient) TopicOffsets(topics []string) (map[string]map[int32]kafkaclient.PartitionOffsets, error) {
+	return c.offsets, nil
+}
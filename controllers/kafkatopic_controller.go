@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"time"
 
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -36,6 +37,13 @@ import (
 
 var topicFinalizer = "finalizer.kafkatopics.kafka.banzaicloud.io"
 
+const (
+	// DefaultKafkaTopicStatusSyncIntervalInSec is the period of refreshing the partition details in the status of the topics
+	DefaultKafkaTopicStatusSyncIntervalInSec = 300
+
+	topicStatusTimeFormat = "2006-01-02 15:04:05"
+)
+
 // SetupKafkaTopicWithManager registers kafka topic controller with manager
 func SetupKafkaTopicWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) *ctrl.Builder {
 	builder := ctrl.NewControllerManagedBy(mgr).
@@ -123,7 +131,7 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 	if existing != nil {
 		reqLogger.Info("Topic already exists, verifying configuration")
 		// Ensure partition count
-		if changed, err := broker.EnsurePartitionCount(instance.Spec.Name, instance.Spec.Partitions); err != nil {
+		if changed, err := broker.EnsurePartitionCount(instance.Spec.Name, instance.Spec.Partitions, topicPlacement(instance, cluster)); err != nil {
 			return requeueWithError(reqLogger, "failed to ensure topic partition count", err)
 		} else if changed {
 			reqLogger.Info("Increased partition count for topic")
@@ -139,6 +147,7 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 		Partitions:        instance.Spec.Partitions,
 		ReplicationFactor: int16(instance.Spec.ReplicationFactor),
 		Config:            util.MapStringStringPointer(instance.Spec.Config),
+		Placement:         topicPlacement(instance, cluster),
 	}); err != nil {
 		return requeueWithError(reqLogger, "failed to create kafka topic", err)
 	}
@@ -157,17 +166,87 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 		}
 	}
 
-	// set topic status as created
-	if instance.Status.State != v1alpha1.TopicStateCreated {
-		instance.Status = v1alpha1.KafkaTopicStatus{State: v1alpha1.TopicStateCreated}
-		if err := r.Client.Status().Update(ctx, instance); err != nil {
-			return requeueWithError(reqLogger, "failed to update kafkatopic status", err)
+	// set topic status as created and refresh the partition details when they are due
+	now := time.Now()
+	if instance.Status.State != v1alpha1.TopicStateCreated || topicStatusSyncDue(instance.Status, now) {
+		status := syncTopicStatus(reqLogger, broker, instance, now)
+		if !reflect.DeepEqual(status, instance.Status) {
+			instance.Status = status
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return requeueWithError(reqLogger, "failed to update kafkatopic status", err)
+			}
 		}
 	}
 
 	reqLogger.Info("Ensured topic")
 
-	return reconciled()
+	return requeueAfter(DefaultKafkaTopicStatusSyncIntervalInSec)
+}
+
+// topicStatusSyncDue returns true if the partition details in the status were never synced or were synced longer
+// than the sync interval ago
+func topicStatusSyncDue(status v1alpha1.KafkaTopicStatus, now time.Time) bool {
+	syncedAt, err := time.Parse(topicStatusTimeFormat, status.SyncedAt)
+	if err != nil {
+		return true
+	}
+	return now.UTC().Sub(syncedAt) >= DefaultKafkaTopicStatusSyncIntervalInSec*time.Second
+}
+
+// syncTopicStatus returns the status of the topic with the partition details refreshed from the Kafka cluster, the
+// details of the previous sync are kept when the topic can not be described, e.g. right after its creation
+func syncTopicStatus(log logr.Logger, broker kafkaclient.KafkaClient, topic *v1alpha1.KafkaTopic, now time.Time) v1alpha1.KafkaTopicStatus {
+	status := topic.Status.DeepCopy()
+	status.State = v1alpha1.TopicStateCreated
+
+	meta, err := broker.DescribeTopic(topic.Spec.Name)
+	if err != nil {
+		log.Info("could not describe topic, keeping the partition details of the previous sync", "error", err.Error())
+		return *status
+	}
+	status = broker.TopicMetaToStatus(meta)
+	status.State = v1alpha1.TopicStateCreated
+
+	// the sizes are best effort, e.g. describing the log dirs may not be allowed for the operator
+	sizes, err := broker.TopicPartitionSizes(topic.Spec.Name)
+	if err != nil {
+		log.V(1).Info("could not estimate the size of the topic partitions", "error", err.Error())
+	}
+	for i := range status.Partitions {
+		status.Partitions[i].SizeBytes = sizes[status.Partitions[i].Partition]
+		status.SizeBytes += status.Partitions[i].SizeBytes
+	}
+
+	usage, err := broker.TopicUsage(topic.Spec.Name)
+	if err != nil {
+		log.V(1).Info("could not collect the usage of the topic", "error", err.Error())
+	} else {
+		status.Usage = topicUsageStatus(usage, topic.Status, now)
+	}
+	status.Recommendations = topicRecommendations(topic, *status, usage)
+
+	status.SyncedAt = now.UTC().Format(topicStatusTimeFormat)
+	return *status
+}
+
+// topicPlacement returns the replica placement constraints of the topic with the preferred broker config group
+// resolved to the brokers of the cluster
+func topicPlacement(topic *v1alpha1.KafkaTopic, cluster *v1beta1.KafkaCluster) *kafkaclient.TopicPlacement {
+	if topic.Spec.Placement == nil {
+		return nil
+	}
+	placement := &kafkaclient.TopicPlacement{
+		MinRacks:        int(topic.Spec.Placement.MinRacks),
+		ExcludedBrokers: topic.Spec.Placement.ExcludedBrokers,
+	}
+	if group := topic.Spec.Placement.PreferredBrokerConfigGroup; group != "" {
+		for _, broker := range cluster.Spec.Brokers {
+			if broker.BrokerConfigGroup == group {
+				placement.PreferredBrokers = append(placement.PreferredBrokers, broker.Id)
+			}
+		}
+	}
+	return placement
 }
 
 func (r *KafkaTopicReconciler) ensureClusterLabel(ctx context.Context, cluster *v1beta1.KafkaCluster, topic *v1alpha1.KafkaTopic) (*v1alpha1.KafkaTopic, error) {
@@ -181,7 +260,7 @@ func (r *KafkaTopicReconciler) ensureClusterLabel(ctx context.Context, cluster *
 
 func (r *KafkaTopicReconciler) updateAndFetchLatest(ctx context.Context, topic *v1alpha1.KafkaTopic) (*v1alpha1.KafkaTopic, error) {
 	typeMeta := topic.TypeMeta
-	err := r.Client.Update(ctx, topic)
+	err := r.Client.Update(ctx, topic, client.FieldOwner(fieldOwner))
 	if err != nil {
 		return nil, err
 	}
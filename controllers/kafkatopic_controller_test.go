@@ -0,0 +1,78 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+func TestTopicStatusSyncDue(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testName string
+		syncedAt string
+		expected bool
+	}{
+		{testName: "never synced", syncedAt: "", expected: true},
+		{testName: "unparsable", syncedAt: "yesterday", expected: true},
+		{testName: "recently synced", syncedAt: "2022-05-10 11:58:00", expected: false},
+		{testName: "synced an interval ago", syncedAt: "2022-05-10 11:55:00", expected: true},
+	}
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			if due := topicStatusSyncDue(v1alpha1.KafkaTopicStatus{SyncedAt: test.syncedAt}, now); due != test.expected {
+				t.Errorf("expected sync due %t, got: %t", test.expected, due)
+			}
+		})
+	}
+}
+
+func TestSyncTopicStatus(t *testing.T) {
+	broker, closeClient, err := kafkaclient.NewMockFromCluster(nil, &v1beta1.KafkaCluster{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeClient()
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	topic := &v1alpha1.KafkaTopic{Spec: v1alpha1.KafkaTopicSpec{Name: "test-topic"}}
+	status := syncTopicStatus(logr.Discard(), broker, topic, now)
+	if status.State != v1alpha1.TopicStateCreated {
+		t.Error("expected the topic to be created, got:", status.State)
+	}
+	if len(status.Partitions) != 1 {
+		t.Error("expected the details of a single partition, got:", status.Partitions)
+	}
+	if status.SyncedAt != "2022-05-10 12:00:00" {
+		t.Error("expected the sync time to be set, got:", status.SyncedAt)
+	}
+
+	// the details of the previous sync are kept when the topic can not be described
+	topic = &v1alpha1.KafkaTopic{
+		Spec:   v1alpha1.KafkaTopicSpec{Name: "not-exists"},
+		Status: status,
+	}
+	if kept := syncTopicStatus(logr.Discard(), broker, topic, now.Add(time.Hour)); kept.SyncedAt != status.SyncedAt || len(kept.Partitions) != 1 {
+		t.Error("expected the previous partition details to be kept, got:", kept)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+const (
+	// saturatedLagInSec is the production time a consumer group running a consumer per partition may lag behind
+	// before more partitions are recommended
+	saturatedLagInSec = 300
+	// caughtUpLagInSec is the production time all the consumer groups lag behind at most when the retention is
+	// considered longer than needed
+	caughtUpLagInSec = 3600
+	// minRetentionRecommendationBytes is the size the topic must exceed for a shorter retention to be recommended
+	minRetentionRecommendationBytes = 1 << 30
+	// minRecommendedRetention is the retention shorter retentions are never recommended below
+	minRecommendedRetention = 24 * time.Hour
+	// defaultTopicRetention is the retention of the topics Kafka uses when retention.ms is not set
+	defaultTopicRetention = 7 * 24 * time.Hour
+
+	topicRetentionConfig     = "retention.ms"
+	topicCleanupPolicyConfig = "cleanup.policy"
+	// changelogTopicSuffix is the suffix of the changelog topics of the Kafka Streams state stores
+	changelogTopicSuffix = "-changelog"
+)
+
+// topicUsageStatus returns the usage of the topic with the production rate measured since the previous sync
+func topicUsageStatus(usage *kafkaclient.TopicUsage, previous v1alpha1.KafkaTopicStatus, now time.Time) *v1alpha1.TopicUsageStatus {
+	status := &v1alpha1.TopicUsageStatus{
+		EndOffset:      usage.EndOffset,
+		ConsumerGroups: int32(len(usage.ConsumerGroups)),
+	}
+	for _, group := range usage.ConsumerGroups {
+		if group.Consumers > status.MaxConsumers {
+			status.MaxConsumers = group.Consumers
+		}
+		if group.Lag > status.MaxLag {
+			status.MaxLag = group.Lag
+		}
+	}
+	if previous.Usage == nil || previous.Usage.EndOffset > usage.EndOffset {
+		return status
+	}
+	if syncedAt, err := time.Parse(topicStatusTimeFormat, previous.SyncedAt); err == nil {
+		if elapsed := int64(now.UTC().Sub(syncedAt).Seconds()); elapsed > 0 {
+			status.MessagesPerSecond = (usage.EndOffset - previous.Usage.EndOffset) / elapsed
+		}
+	}
+	return status
+}
+
+// topicRecommendations returns the changes of the topic suggested by the number of its partitions, its
+// configuration, its size and its usage, the recommendations based on the production rate are skipped until it
+// is measured
+func topicRecommendations(topic *v1alpha1.KafkaTopic, status v1alpha1.KafkaTopicStatus,
+	usage *kafkaclient.TopicUsage) []v1alpha1.TopicRecommendation {
+	var recommendations []v1alpha1.TopicRecommendation
+	var rate int64
+	if status.Usage != nil {
+		rate = status.Usage.MessagesPerSecond
+	}
+	partitions := int32(len(status.Partitions))
+
+	if usage != nil {
+		for _, group := range usage.ConsumerGroups {
+			var reason string
+			switch {
+			case group.Consumers > partitions:
+				reason = fmt.Sprintf("consumer group %s runs %d consumers, %d of them are idle as the topic has %d partitions",
+					group.Name, group.Consumers, group.Consumers-partitions, partitions)
+			case rate > 0 && group.Consumers == partitions && group.Lag > rate*saturatedLagInSec:
+				reason = fmt.Sprintf("consumer group %s lags %d messages behind, more than %s of production, while running a consumer per partition",
+					group.Name, group.Lag, saturatedLagInSec*time.Second)
+			default:
+				continue
+			}
+			recommendations = append(recommendations, v1alpha1.TopicRecommendation{
+				Type:   v1alpha1.TopicRecommendationIncreasePartitions,
+				Reason: reason,
+			})
+			break
+		}
+
+		retention := topicRetention(topic.Spec.Config)
+		if rate > 0 && len(usage.ConsumerGroups) > 0 && status.SizeBytes > minRetentionRecommendationBytes &&
+			(retention < 0 || retention > minRecommendedRetention) && consumersCaughtUp(usage, rate) {
+			retained := "forever"
+			if retention > 0 {
+				retained = "for " + retention.String()
+			}
+			recommendations = append(recommendations, v1alpha1.TopicRecommendation{
+				Type: v1alpha1.TopicRecommendationReduceRetention,
+				Reason: fmt.Sprintf("the consumer groups lag less than %s of production behind while the %d bytes of the topic are retained %s",
+					caughtUpLagInSec*time.Second, status.SizeBytes, retained),
+			})
+		}
+	}
+
+	if strings.HasSuffix(topic.Spec.Name, changelogTopicSuffix) &&
+		!strings.Contains(topic.Spec.Config[topicCleanupPolicyConfig], "compact") {
+		recommendations = append(recommendations, v1alpha1.TopicRecommendation{
+			Type:   v1alpha1.TopicRecommendationEnableCompaction,
+			Reason: "the topic is named as the changelog of a state store which only needs the latest value of each key",
+		})
+	}
+	return recommendations
+}
+
+// topicRetention returns the retention of the topic, it is negative if the messages are retained forever
+func topicRetention(config map[string]string) time.Duration {
+	if value, ok := config[topicRetentionConfig]; ok {
+		if retentionMs, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if retentionMs < 0 {
+				return -1
+			}
+			return time.Duration(retentionMs) * time.Millisecond
+		}
+	}
+	return defaultTopicRetention
+}
+
+// consumersCaughtUp returns true if every consumer group lags less than caughtUpLagInSec of production behind
+func consumersCaughtUp(usage *kafkaclient.TopicUsage, rate int64) bool {
+	for _, group := range usage.ConsumerGroups {
+		if group.Lag > rate*caughtUpLagInSec {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+func TestTopicUsageStatus(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	usage := &kafkaclient.TopicUsage{
+		EndOffset: 40000,
+		ConsumerGroups: []kafkaclient.ConsumerGroupUsage{
+			{Name: "group-a", Consumers: 2, Lag: 100},
+			{Name: "group-b", Consumers: 4, Lag: 30},
+		},
+	}
+
+	testCases := []struct {
+		testName string
+		previous v1alpha1.KafkaTopicStatus
+		expected int64
+	}{
+		{testName: "first sync", previous: v1alpha1.KafkaTopicStatus{}, expected: 0},
+		{
+			testName: "rate since the previous sync",
+			previous: v1alpha1.KafkaTopicStatus{SyncedAt: "2022-05-10 11:55:00", Usage: &v1alpha1.TopicUsageStatus{EndOffset: 10000}},
+			expected: 100,
+		},
+		{
+			testName: "topic recreated since the previous sync",
+			previous: v1alpha1.KafkaTopicStatus{SyncedAt: "2022-05-10 11:55:00", Usage: &v1alpha1.TopicUsageStatus{EndOffset: 50000}},
+			expected: 0,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			status := topicUsageStatus(usage, test.previous, now)
+			expected := &v1alpha1.TopicUsageStatus{EndOffset: 40000, MessagesPerSecond: test.expected,
+				ConsumerGroups: 2, MaxConsumers: 4, MaxLag: 100}
+			if !reflect.DeepEqual(status, expected) {
+				t.Errorf("expected usage %+v, got: %+v", expected, status)
+			}
+		})
+	}
+}
+
+func TestTopicRecommendations(t *testing.T) {
+	partitions := make([]v1alpha1.TopicPartitionStatus, 4)
+	status := func(rate int64, size int64) v1alpha1.KafkaTopicStatus {
+		return v1alpha1.KafkaTopicStatus{
+			Partitions: partitions,
+			SizeBytes:  size,
+			Usage:      &v1alpha1.TopicUsageStatus{MessagesPerSecond: rate},
+		}
+	}
+	topic := func(name string, config map[string]string) *v1alpha1.KafkaTopic {
+		return &v1alpha1.KafkaTopic{Spec: v1alpha1.KafkaTopicSpec{Name: name, Config: config}}
+	}
+	usage := func(groups ...kafkaclient.ConsumerGroupUsage) *kafkaclient.TopicUsage {
+		return &kafkaclient.TopicUsage{ConsumerGroups: groups}
+	}
+
+	testCases := []struct {
+		testName string
+		topic    *v1alpha1.KafkaTopic
+		status   v1alpha1.KafkaTopicStatus
+		usage    *kafkaclient.TopicUsage
+		expected []v1alpha1.TopicRecommendationType
+	}{
+		{
+			testName: "right-sized topic",
+			topic:    topic("orders", nil),
+			status:   status(10, 1<<20),
+			usage:    usage(kafkaclient.ConsumerGroupUsage{Name: "group-a", Consumers: 4, Lag: 100}),
+		},
+		{
+			testName: "idle consumers",
+			topic:    topic("orders", nil),
+			status:   status(0, 1<<20),
+			usage:    usage(kafkaclient.ConsumerGroupUsage{Name: "group-a", Consumers: 6}),
+			expected: []v1alpha1.TopicRecommendationType{v1alpha1.TopicRecommendationIncreasePartitions},
+		},
+		{
+			testName: "lagging consumer per partition",
+			topic:    topic("orders", nil),
+			status:   status(10, 1<<20),
+			usage:    usage(kafkaclient.ConsumerGroupUsage{Name: "group-a", Consumers: 4, Lag: 5000}),
+			expected: []v1alpha1.TopicRecommendationType{v1alpha1.TopicRecommendationIncreasePartitions},
+		},
+		{
+			testName: "lagging with less consumers than partitions",
+			topic:    topic("orders", nil),
+			status:   status(10, 1<<20),
+			usage:    usage(kafkaclient.ConsumerGroupUsage{Name: "group-a", Consumers: 2, Lag: 5000}),
+		},
+		{
+			testName: "large topic read by caught up consumers",
+			topic:    topic("orders", nil),
+			status:   status(10, 2<<30),
+			usage:    usage(kafkaclient.ConsumerGroupUsage{Name: "group-a", Consumers: 4, Lag: 100}),
+			expected: []v1alpha1.TopicRecommendationType{v1alpha1.TopicRecommendationReduceRetention},
+		},
+		{
+			testName: "large topic with short retention",
+			topic:    topic("orders", map[string]string{"retention.ms": "3600000"}),
+			status:   status(10, 2<<30),
+			usage:    usage(kafkaclient.ConsumerGroupUsage{Name: "group-a", Consumers: 4, Lag: 100}),
+		},
+		{
+			testName: "large topic with a consumer group far behind",
+			topic:    topic("orders", map[string]string{"retention.ms": "-1"}),
+			status:   status(10, 2<<30),
+			usage: usage(kafkaclient.ConsumerGroupUsage{Name: "group-a", Consumers: 4, Lag: 100},
+				kafkaclient.ConsumerGroupUsage{Name: "group-b", Consumers: 1, Lag: 1000000}),
+		},
+		{
+			testName: "uncompacted changelog",
+			topic:    topic("app-store-changelog", map[string]string{"cleanup.policy": "delete"}),
+			status:   status(0, 1<<20),
+			expected: []v1alpha1.TopicRecommendationType{v1alpha1.TopicRecommendationEnableCompaction},
+		},
+		{
+			testName: "compacted changelog",
+			topic:    topic("app-store-changelog", map[string]string{"cleanup.policy": "compact,delete"}),
+			status:   status(0, 1<<20),
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			var types []v1alpha1.TopicRecommendationType
+			for _, recommendation := range topicRecommendations(test.topic, test.status, test.usage) {
+				if recommendation.Reason == "" {
+					t.Errorf("expected a reason for the %s recommendation", recommendation.Type)
+				}
+				types = append(types, recommendation.Type)
+			}
+			if !reflect.DeepEqual(types, test.expected) {
+				t.Errorf("expected recommendations %v, got: %v", test.expected, types)
+			}
+		})
+	}
+}
@@ -260,6 +260,18 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		return requeueWithError(reqLogger, "failed to ensure kafkacluster label on user", err)
 	}
 
+	if instance.Spec.Revoked {
+		return r.revokeUser(ctx, cluster, instance, kafkaUser)
+	}
+
+	// the DENY ACLs of a user revoked before would override the ACLs of its topic grants
+	if instance.Status.State == v1alpha1.UserStateRevoked {
+		if err := r.restoreUser(cluster, kafkaUser); err != nil {
+			return requeueWithError(reqLogger, "failed to remove the DENY ACLs of the no longer revoked kafkauser", err)
+		}
+		reqLogger.Info(fmt.Sprintf("Removed the DENY ACLs of the no longer revoked User: %s", kafkaUser))
+	}
+
 	// If topic grants supplied, grab a broker connection and set ACLs
 	if len(instance.Spec.TopicGrants) > 0 {
 		broker, close, err := newKafkaFromCluster(r.Client, cluster)
@@ -311,7 +323,7 @@ func (r *KafkaUserReconciler) ensureClusterLabel(ctx context.Context, cluster *v
 
 func (r *KafkaUserReconciler) updateAndFetchLatest(ctx context.Context, user *v1alpha1.KafkaUser) (*v1alpha1.KafkaUser, error) {
 	typeMeta := user.TypeMeta
-	err := r.Client.Update(ctx, user)
+	err := r.Client.Update(ctx, user, client.FieldOwner(fieldOwner))
 	if err != nil {
 		return nil, err
 	}
@@ -324,7 +336,8 @@ func (r *KafkaUserReconciler) checkFinalizers(ctx context.Context, cluster *v1be
 	// run finalizers
 	var err error
 	if util.StringSliceContains(instance.GetFinalizers(), userFinalizer) {
-		if len(instance.Spec.TopicGrants) > 0 {
+		// the DENY ACLs of a revoked user are kept as its certificate may still be trusted by the brokers
+		if len(instance.Spec.TopicGrants) > 0 && !instance.Spec.Revoked {
 			if err = r.finalizeKafkaUserACLs(reqLogger, cluster, user); err != nil {
 				return requeueWithError(reqLogger, "failed to finalize kafkauser", err)
 			}
@@ -337,6 +350,43 @@ func (r *KafkaUserReconciler) checkFinalizers(ctx context.Context, cluster *v1be
 	return reconciled()
 }
 
+// revokeUser replaces the ACLs of the user with DENY ACLs so that its compromised certificate is rejected by the brokers
+func (r *KafkaUserReconciler) revokeUser(ctx context.Context, cluster *v1beta1.KafkaCluster, instance *v1alpha1.KafkaUser, user string) (reconcile.Result, error) {
+	reqLogger := logr.FromContextOrDiscard(ctx)
+
+	broker, close, err := newKafkaFromCluster(r.Client, cluster)
+	if err != nil {
+		return checkBrokerConnectionError(reqLogger, err)
+	}
+	defer close()
+
+	reqLogger.Info(fmt.Sprintf("Revoking ACLs for User: %s", user))
+	if err = broker.RevokeUserACLs(user); err != nil {
+		return requeueWithError(reqLogger, "failed to revoke ACLs of kafkauser", err)
+	}
+
+	if instance.Status.State != v1alpha1.UserStateRevoked {
+		instance.Status = v1alpha1.KafkaUserStatus{
+			State: v1alpha1.UserStateRevoked,
+		}
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to update kafkauser status", err)
+		}
+	}
+
+	return reconciled()
+}
+
+// restoreUser removes the DENY ACLs created on the revocation of the user
+func (r *KafkaUserReconciler) restoreUser(cluster *v1beta1.KafkaCluster, user string) error {
+	broker, close, err := newKafkaFromCluster(r.Client, cluster)
+	if err != nil {
+		return err
+	}
+	defer close()
+	return broker.RestoreUserACLs(user)
+}
+
 func (r *KafkaUserReconciler) removeFinalizer(ctx context.Context, user *v1alpha1.KafkaUser) error {
 	user.SetFinalizers(util.StringSliceRemove(user.GetFinalizers(), userFinalizer))
 	_, err := r.updateAndFetchLatest(ctx, user)
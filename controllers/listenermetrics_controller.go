@@ -0,0 +1,238 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/jmxextractor"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+const (
+	// DefaultListenerMetricsRefreshIntervalInSec is the period of taking snapshots of the listener metrics of the brokers
+	DefaultListenerMetricsRefreshIntervalInSec = 60
+)
+
+var (
+	listenerMetricLabels = []string{"namespace", "kafka_cr", "listener"}
+
+	listenerConnectionsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_listener_connections",
+		Help: "Number of connections of the listener summed over the brokers",
+	}, listenerMetricLabels)
+	listenerIncomingGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_listener_incoming_kilobytes_per_second",
+		Help: "Incoming byte rate of the listener in KB/s summed over the brokers",
+	}, listenerMetricLabels)
+	listenerOutgoingGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_listener_outgoing_kilobytes_per_second",
+		Help: "Outgoing byte rate of the listener in KB/s summed over the brokers",
+	}, listenerMetricLabels)
+
+	listenerGauges = []*prometheus.GaugeVec{
+		listenerConnectionsGauge,
+		listenerIncomingGauge,
+		listenerOutgoingGauge,
+	}
+)
+
+func init() {
+	for _, gauge := range listenerGauges {
+		metrics.Registry.MustRegister(gauge)
+	}
+}
+
+// ListenerMetricsReconciler periodically aggregates the connections and the byte rates of the listeners reported by
+// the JMX exporters of the brokers in the status of the kafka cluster object and in metrics
+type ListenerMetricsReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *ListenerMetricsReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		updateListenerMetrics(instance, &kafkav1beta1.ListenerMetricsStatus{})
+		return reconciled()
+	}
+
+	if instance.Status.State != kafkav1beta1.KafkaClusterRunning {
+		log.V(1).Info("requeue event as the Kafka cluster is not running")
+		return requeueAfter(DefaultListenerMetricsRefreshIntervalInSec)
+	}
+
+	jmxExp := jmxextractor.NewJMXExtractor(instance.GetNamespace(),
+		instance.Spec.GetKubernetesClusterDomain(), instance.GetName(), log)
+
+	brokerMetrics := make(map[string]map[string]jmxextractor.ListenerMetrics, len(instance.Spec.Brokers))
+	for _, broker := range instance.Spec.Brokers {
+		listeners, err := jmxExp.ExtractListenerMetrics(broker.Id, instance.Spec.HeadlessServiceEnabled)
+		if err != nil {
+			// the listeners of the brokers not reachable are left out of the snapshot
+			log.V(1).Info("getting the listener metrics of broker failed", "brokerId", broker.Id, "error", err.Error())
+			continue
+		}
+		brokerMetrics[strconv.Itoa(int(broker.Id))] = listeners
+	}
+	if len(brokerMetrics) == 0 {
+		log.Info("requeue event as getting the listener metrics of the brokers failed")
+		return requeueAfter(DefaultListenerMetricsRefreshIntervalInSec)
+	}
+
+	metricsStatus := newListenerMetricsStatus(brokerMetrics, time.Now())
+	updateListenerMetrics(instance, metricsStatus)
+
+	if instance.Status.ListenerMetrics == nil ||
+		!reflect.DeepEqual(instance.Status.ListenerMetrics.Listeners, metricsStatus.Listeners) {
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, metricsStatus, log); err != nil {
+			return requeueWithError(log, "failed to update the listener metrics in the Kafka Cluster status", err)
+		}
+	}
+	return requeueAfter(DefaultListenerMetricsRefreshIntervalInSec)
+}
+
+// newListenerMetricsStatus returns the status snapshot of the listener metrics of the brokers, given by broker ID,
+// summed per listener and sorted by listener name
+func newListenerMetricsStatus(brokerMetrics map[string]map[string]jmxextractor.ListenerMetrics, now time.Time) *kafkav1beta1.ListenerMetricsStatus {
+	type listenerTotal struct {
+		jmxextractor.ListenerMetrics
+		busiestBrokerID string
+		busiestByteRate float64
+	}
+
+	brokerIDs := make([]string, 0, len(brokerMetrics))
+	for brokerID := range brokerMetrics {
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+	sort.Strings(brokerIDs)
+
+	totals := make(map[string]*listenerTotal)
+	var names []string
+	for _, brokerID := range brokerIDs {
+		for name, listener := range brokerMetrics[brokerID] {
+			total, ok := totals[name]
+			if !ok {
+				total = &listenerTotal{}
+				totals[name] = total
+				names = append(names, name)
+			}
+			total.Connections += listener.Connections
+			total.IncomingByteRate += listener.IncomingByteRate
+			total.OutgoingByteRate += listener.OutgoingByteRate
+			if byteRate := listener.IncomingByteRate + listener.OutgoingByteRate; byteRate > total.busiestByteRate {
+				total.busiestByteRate = byteRate
+				total.busiestBrokerID = brokerID
+			}
+		}
+	}
+	sort.Strings(names)
+
+	status := &kafkav1beta1.ListenerMetricsStatus{
+		UpdatedAt: now.Format("2006-01-02 15:04:05"),
+		Listeners: make([]kafkav1beta1.ListenerMetrics, 0, len(names)),
+	}
+	for _, name := range names {
+		total := totals[name]
+		status.Listeners = append(status.Listeners, kafkav1beta1.ListenerMetrics{
+			Name:            name,
+			Connections:     int64(total.Connections),
+			IncomingKBps:    formatLoad(total.IncomingByteRate / 1024),
+			OutgoingKBps:    formatLoad(total.OutgoingByteRate / 1024),
+			BusiestBrokerID: total.busiestBrokerID,
+		})
+	}
+	return status
+}
+
+// updateListenerMetrics sets the listener metrics of the cluster and removes the ones of the listeners which are no
+// longer reported, all the metrics of the cluster are removed with an empty status
+func updateListenerMetrics(instance *kafkav1beta1.KafkaCluster, metricsStatus *kafkav1beta1.ListenerMetricsStatus) {
+	reported := make(map[string]bool, len(metricsStatus.Listeners))
+	for _, listener := range metricsStatus.Listeners {
+		reported[listener.Name] = true
+		labels := prometheus.Labels{"namespace": instance.Namespace, "kafka_cr": instance.Name, "listener": listener.Name}
+		listenerConnectionsGauge.With(labels).Set(float64(listener.Connections))
+		setBrokerLoadGauge(listenerIncomingGauge, labels, listener.IncomingKBps)
+		setBrokerLoadGauge(listenerOutgoingGauge, labels, listener.OutgoingKBps)
+	}
+
+	if instance.Status.ListenerMetrics == nil {
+		return
+	}
+	for _, listener := range instance.Status.ListenerMetrics.Listeners {
+		if reported[listener.Name] {
+			continue
+		}
+		labels := prometheus.Labels{"namespace": instance.Namespace, "kafka_cr": instance.Name, "listener": listener.Name}
+		for _, gauge := range listenerGauges {
+			gauge.Delete(labels)
+		}
+	}
+}
+
+// SetupListenerMetricsWithManager registers the listener metrics controller to the manager
+func SetupListenerMetricsWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("ListenerMetrics")
+
+	// the snapshots are taken periodically, only the creation, the deletion and the spec changes of the clusters
+	// trigger them in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,71 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/jmxextractor"
+)
+
+func TestNewListenerMetricsStatus(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testName      string
+		brokerMetrics map[string]map[string]jmxextractor.ListenerMetrics
+		expected      *v1beta1.ListenerMetricsStatus
+	}{
+		{
+			testName:      "no listeners reported",
+			brokerMetrics: map[string]map[string]jmxextractor.ListenerMetrics{"0": {}},
+			expected: &v1beta1.ListenerMetricsStatus{
+				UpdatedAt: "2022-05-04 12:30:00",
+				Listeners: []v1beta1.ListenerMetrics{},
+			},
+		},
+		{
+			testName: "listeners summed over the brokers",
+			brokerMetrics: map[string]map[string]jmxextractor.ListenerMetrics{
+				"0": {
+					"internal": {Connections: 10, IncomingByteRate: 2048, OutgoingByteRate: 1024},
+					"external": {Connections: 100, IncomingByteRate: 10240, OutgoingByteRate: 20480},
+				},
+				"1": {
+					"internal": {Connections: 12, IncomingByteRate: 1024, OutgoingByteRate: 4096},
+					"external": {Connections: 50, IncomingByteRate: 512, OutgoingByteRate: 0},
+				},
+				"2": {
+					"internal": {Connections: 8, IncomingByteRate: 0, OutgoingByteRate: 0},
+				},
+			},
+			expected: &v1beta1.ListenerMetricsStatus{
+				UpdatedAt: "2022-05-04 12:30:00",
+				Listeners: []v1beta1.ListenerMetrics{
+					{Name: "external", Connections: 150, IncomingKBps: "10.50", OutgoingKBps: "20.00", BusiestBrokerID: "0"},
+					{Name: "internal", Connections: 30, IncomingKBps: "3.00", OutgoingKBps: "5.00", BusiestBrokerID: "1"},
+				},
+			},
+		},
+	}
+	for _, test := range testCases {
+		if status := newListenerMetricsStatus(test.brokerMetrics, now); !reflect.DeepEqual(status, test.expected) {
+			t.Errorf("%s: expected listener metrics %+v, got: %+v", test.testName, test.expected, status)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// DefaultOperationRetentionIntervalInSec is the period of removing the finished operations of the clusters
+const DefaultOperationRetentionIntervalInSec = 300
+
+// OperationRetentionReconciler removes the finished operations of the kafka clusters beyond the retention of
+// spec.operationRetention, both the CruiseControlOperations and the records of status.operationHistory. The disk
+// rebalance states of the volumes removed from the brokers are pruned from the status as well.
+type OperationRetentionReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations,verbs=get;list;watch;create;update;patch;delete
+
+func (r *OperationRetentionReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	now := time.Now()
+	retention := instance.Spec.OperationRetention
+	if retention != nil {
+		operations := &v1alpha1.CruiseControlOperationList{}
+		if err := r.List(ctx, operations); err != nil {
+			return requeueWithError(log, "failed to list the cruise control operations", err)
+		}
+		for _, operation := range expiredCruiseControlOperations(operations.Items, instance, retention, now) {
+			log.Info("deleting finished cruise control operation beyond the retention", "operation", operation.Name,
+				"namespace", operation.Namespace)
+			if err := r.Delete(ctx, operation); client.IgnoreNotFound(err) != nil {
+				return requeueWithError(log, "failed to delete the finished cruise control operation", err)
+			}
+		}
+	}
+
+	prune := func(status *kafkav1beta1.KafkaClusterStatus) bool {
+		pruned := pruneStaleVolumeStates(status, instance.Spec)
+		if retention != nil && retention.MaxAge != nil {
+			pruned = pruneOperationHistory(status, now.Add(-retention.MaxAge.Duration)) || pruned
+		}
+		return pruned
+	}
+	if err := k8sutil.PruneStatus(r.Client, instance, prune, log); err != nil {
+		return requeueWithError(log, "failed to prune the finished operations from the Kafka Cluster status", err)
+	}
+	return requeueAfter(DefaultOperationRetentionIntervalInSec)
+}
+
+// expiredCruiseControlOperations returns the finished operations of the cluster beyond the retention, newest first.
+// The operations to be retried and the failed operations blocking new ones with the block error policy are kept.
+func expiredCruiseControlOperations(operations []v1alpha1.CruiseControlOperation, cluster *kafkav1beta1.KafkaCluster,
+	retention *kafkav1beta1.OperationRetentionConfig, now time.Time) []*v1alpha1.CruiseControlOperation {
+	blocking := cluster.Spec.CruiseControlConfig.OperationRetryPolicy.GetErrorPolicy() == kafkav1beta1.CruiseControlErrorPolicyBlock
+
+	var finished []*v1alpha1.CruiseControlOperation
+	for i := range operations {
+		operation := &operations[i]
+		if operation.Spec.ClusterRef.Name != cluster.Name ||
+			getClusterRefNamespace(operation.Namespace, operation.Spec.ClusterRef) != cluster.Namespace {
+			continue
+		}
+		if !operation.Status.IsFinished() || operation.Status.NextRetryAt != "" ||
+			k8sutil.IsMarkedForDeletion(operation.ObjectMeta) {
+			continue
+		}
+		if blocking && operation.Status.State == v1alpha1.OperationStateCompletedWithError {
+			continue
+		}
+		finished = append(finished, operation)
+	}
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[j].CreationTimestamp.Before(&finished[i].CreationTimestamp)
+	})
+
+	var expired []*v1alpha1.CruiseControlOperation
+	for i, operation := range finished {
+		if retention.MaxCount != nil && i >= int(*retention.MaxCount) {
+			expired = append(expired, operation)
+			continue
+		}
+		if retention.MaxAge != nil && operation.CreationTimestamp.Time.Before(now.Add(-retention.MaxAge.Duration)) {
+			expired = append(expired, operation)
+		}
+	}
+	return expired
+}
+
+// pruneOperationHistory removes the records of the operations finished before the given time from the operation
+// history, it returns true if any of them is removed
+func pruneOperationHistory(status *kafkav1beta1.KafkaClusterStatus, finishedBefore time.Time) bool {
+	history := make([]kafkav1beta1.OperationRecord, 0, len(status.OperationHistory))
+	for _, record := range status.OperationHistory {
+		if record.Outcome != kafkav1beta1.OperationRunning && record.FinishedAt != "" {
+			finishedAt, err := time.Parse(operationTimeFormat, record.FinishedAt)
+			if err == nil && finishedAt.Before(finishedBefore) {
+				continue
+			}
+		}
+		history = append(history, record)
+	}
+	if len(history) == len(status.OperationHistory) {
+		return false
+	}
+	status.OperationHistory = history
+	return true
+}
+
+// pruneStaleVolumeStates removes the disk rebalance states of the volumes which are no longer mounted by the brokers,
+// unless their rebalance is still required or running. It returns true if any of them is removed.
+func pruneStaleVolumeStates(status *kafkav1beta1.KafkaClusterStatus, spec kafkav1beta1.KafkaClusterSpec) bool {
+	pruned := false
+	for i := range spec.Brokers {
+		broker := &spec.Brokers[i]
+		brokerState, ok := status.BrokersState[strconv.Itoa(int(broker.Id))]
+		if !ok || len(brokerState.GracefulActionState.VolumeStates) == 0 {
+			continue
+		}
+		brokerConfig, err := broker.GetBrokerConfig(spec)
+		if err != nil {
+			continue
+		}
+		mountPaths := make(map[string]bool, len(brokerConfig.StorageConfigs))
+		for _, storageConfig := range brokerConfig.StorageConfigs {
+			mountPaths[storageConfig.MountPath] = true
+		}
+		for mountPath, volumeState := range brokerState.GracefulActionState.VolumeStates {
+			if mountPaths[mountPath] || volumeState.CruiseControlVolumeState.IsActive() {
+				continue
+			}
+			delete(brokerState.GracefulActionState.VolumeStates, mountPath)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// SetupOperationRetentionWithManager registers the operation retention controller to the manager
+func SetupOperationRetentionWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("OperationRetention")
+
+	// the finished operations are removed periodically, only the creation, the deletion and the spec changes of the
+	// clusters trigger the removal in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
@@ -0,0 +1,144 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestExpiredCruiseControlOperations(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	operation := func(name string, age time.Duration, status v1alpha1.CruiseControlOperationStatus) v1alpha1.CruiseControlOperation {
+		return v1alpha1.CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kafka", CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Spec:       v1alpha1.CruiseControlOperationSpec{ClusterRef: v1alpha1.ClusterReference{Name: "kafka"}},
+			Status:     status,
+		}
+	}
+	completed := v1alpha1.CruiseControlOperationStatus{State: v1alpha1.OperationStateCompleted}
+	failed := v1alpha1.CruiseControlOperationStatus{State: v1alpha1.OperationStateCompletedWithError}
+	operations := []v1alpha1.CruiseControlOperation{
+		operation("oldest", 72*time.Hour, completed),
+		operation("old-failed", 48*time.Hour, failed),
+		operation("retried", 47*time.Hour, v1alpha1.CruiseControlOperationStatus{
+			State: v1alpha1.OperationStateCompletedWithError, NextRetryAt: "2022-05-10T12:05:00Z"}),
+		operation("running", 46*time.Hour, v1alpha1.CruiseControlOperationStatus{State: v1alpha1.OperationStateInExecution}),
+		operation("recent", time.Hour, completed),
+		operation("newest", time.Minute, completed),
+	}
+	other := operation("other-cluster", 96*time.Hour, completed)
+	other.Spec.ClusterRef.Name = "other"
+	operations = append(operations, other)
+
+	names := func(operations []*v1alpha1.CruiseControlOperation) []string {
+		var names []string
+		for _, operation := range operations {
+			names = append(names, operation.Name)
+		}
+		return names
+	}
+	maxCount := int32(2)
+
+	testCases := []struct {
+		testName    string
+		retention   *v1beta1.OperationRetentionConfig
+		retryPolicy *v1beta1.CruiseControlOperationRetryPolicy
+		expected    []string
+	}{
+		{
+			testName:  "max count",
+			retention: &v1beta1.OperationRetentionConfig{MaxCount: &maxCount},
+			expected:  []string{"old-failed", "oldest"},
+		},
+		{
+			testName:  "max age",
+			retention: &v1beta1.OperationRetentionConfig{MaxAge: &metav1.Duration{Duration: 60 * time.Hour}},
+			expected:  []string{"oldest"},
+		},
+		{
+			testName:    "failed operations blocking new ones are kept",
+			retention:   &v1beta1.OperationRetentionConfig{MaxCount: &maxCount},
+			retryPolicy: &v1beta1.CruiseControlOperationRetryPolicy{ErrorPolicy: v1beta1.CruiseControlErrorPolicyBlock},
+			expected:    []string{"oldest"},
+		},
+		{
+			testName:  "no limits",
+			retention: &v1beta1.OperationRetentionConfig{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			cluster.Spec.CruiseControlConfig.OperationRetryPolicy = test.retryPolicy
+			expired := names(expiredCruiseControlOperations(operations, cluster, test.retention, now))
+			if !reflect.DeepEqual(expired, test.expected) {
+				t.Errorf("expected expired operations: %v, got: %v", test.expected, expired)
+			}
+		})
+	}
+}
+
+func TestPruneOperationHistory(t *testing.T) {
+	status := &v1beta1.KafkaClusterStatus{OperationHistory: []v1beta1.OperationRecord{
+		{Type: v1beta1.OperationTypeRollingUpgrade, StartedAt: "2022-05-01T10:00:00Z", FinishedAt: "2022-05-01T10:30:00Z",
+			Outcome: v1beta1.OperationSucceeded},
+		{Type: v1beta1.OperationTypeRollingUpgrade, StartedAt: "2022-05-01T11:00:00Z", Outcome: v1beta1.OperationRunning},
+		{Type: v1beta1.OperationTypeRollingUpgrade, StartedAt: "2022-05-09T10:00:00Z", FinishedAt: "2022-05-09T10:30:00Z",
+			Outcome: v1beta1.OperationSucceeded},
+	}}
+	finishedBefore := time.Date(2022, 5, 5, 0, 0, 0, 0, time.UTC)
+
+	if !pruneOperationHistory(status, finishedBefore) {
+		t.Fatal("expected the operation history to be pruned")
+	}
+	if len(status.OperationHistory) != 2 || status.OperationHistory[0].Outcome != v1beta1.OperationRunning {
+		t.Errorf("expected the running and the recent operations to be kept, got: %+v", status.OperationHistory)
+	}
+	if pruneOperationHistory(status, finishedBefore) {
+		t.Error("expected nothing to be pruned")
+	}
+}
+
+func TestPruneStaleVolumeStates(t *testing.T) {
+	spec := v1beta1.KafkaClusterSpec{Brokers: []v1beta1.Broker{{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{
+		StorageConfigs: []v1beta1.StorageConfig{{MountPath: "/kafka-logs"}},
+	}}}}
+	status := &v1beta1.KafkaClusterStatus{BrokersState: map[string]v1beta1.BrokerState{
+		"0": {GracefulActionState: v1beta1.GracefulActionState{VolumeStates: map[string]v1beta1.VolumeState{
+			"/kafka-logs":   {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceSucceeded},
+			"/kafka-logs-2": {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceSucceeded},
+			"/kafka-logs-3": {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRunning},
+		}}},
+	}}
+
+	if !pruneStaleVolumeStates(status, spec) {
+		t.Fatal("expected the volume states to be pruned")
+	}
+	volumeStates := status.BrokersState["0"].GracefulActionState.VolumeStates
+	if _, ok := volumeStates["/kafka-logs-2"]; ok || len(volumeStates) != 2 {
+		t.Errorf("expected the state of the removed volume to be pruned, got: %v", volumeStates)
+	}
+	if pruneStaleVolumeStates(status, spec) {
+		t.Error("expected nothing to be pruned")
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/jmxextractor"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const (
+	// DefaultRequestLatencyRefreshIntervalInSec is the period of taking snapshots of the request latencies of the brokers
+	DefaultRequestLatencyRefreshIntervalInSec = 60
+)
+
+var (
+	brokerProduceLatencyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_produce_p99_latency_milliseconds",
+		Help: "p99 produce request latency of the broker in milliseconds",
+	}, brokerLoadMetricLabels)
+	brokerFetchLatencyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_fetch_p99_latency_milliseconds",
+		Help: "p99 consumer fetch request latency of the broker in milliseconds",
+	}, brokerLoadMetricLabels)
+	brokerSlowGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_broker_slow",
+		Help: "Whether the request latencies of the broker breach the thresholds (1) or not (0)",
+	}, brokerLoadMetricLabels)
+
+	brokerLatencyGauges = []*prometheus.GaugeVec{
+		brokerProduceLatencyGauge,
+		brokerFetchLatencyGauge,
+		brokerSlowGauge,
+	}
+)
+
+func init() {
+	for _, gauge := range brokerLatencyGauges {
+		metrics.Registry.MustRegister(gauge)
+	}
+}
+
+// RequestLatencyReconciler periodically records the produce and fetch request latencies of the brokers reported by
+// their JMX exporters in the status of the kafka cluster object and in metrics, and demotes the Slow brokers when
+// requested
+type RequestLatencyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *RequestLatencyReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		updateRequestLatencyMetrics(instance, &kafkav1beta1.RequestLatencyStatus{})
+		return reconciled()
+	}
+
+	if instance.Spec.RequestLatency == nil {
+		if instance.Status.RequestLatency != nil {
+			updateRequestLatencyMetrics(instance, &kafkav1beta1.RequestLatencyStatus{})
+			var status *kafkav1beta1.RequestLatencyStatus
+			if err := k8sutil.UpdateCRStatus(r.Client, instance, status, log); err != nil {
+				return requeueWithError(log, "failed to remove the request latencies from the Kafka Cluster status", err)
+			}
+		}
+		return reconciled()
+	}
+
+	if instance.Status.State != kafkav1beta1.KafkaClusterRunning {
+		log.V(1).Info("requeue event as the Kafka cluster is not running")
+		return requeueAfter(DefaultRequestLatencyRefreshIntervalInSec)
+	}
+
+	jmxExp := jmxextractor.NewJMXExtractor(instance.GetNamespace(),
+		instance.Spec.GetKubernetesClusterDomain(), instance.GetName(), log)
+
+	latencies := make(map[string]*jmxextractor.RequestLatencies, len(instance.Spec.Brokers))
+	for _, broker := range instance.Spec.Brokers {
+		brokerLatencies, err := jmxExp.ExtractRequestLatencies(broker.Id, instance.Spec.HeadlessServiceEnabled)
+		if err != nil {
+			// the brokers not reachable are left out of the snapshot
+			log.V(1).Info("getting the request latencies of broker failed", "brokerId", broker.Id, "error", err.Error())
+			continue
+		}
+		latencies[strconv.Itoa(int(broker.Id))] = brokerLatencies
+	}
+	if len(latencies) == 0 {
+		log.Info("requeue event as getting the request latencies of the brokers failed")
+		return requeueAfter(DefaultRequestLatencyRefreshIntervalInSec)
+	}
+
+	latencyStatus := newRequestLatencyStatus(instance.Spec.RequestLatency, latencies, instance.Status.RequestLatency, time.Now())
+	if instance.Spec.RequestLatency.AutoDemote {
+		r.demoteSlowBroker(ctx, log, instance, latencyStatus)
+	}
+	updateRequestLatencyMetrics(instance, latencyStatus)
+
+	if instance.Status.RequestLatency == nil ||
+		!reflect.DeepEqual(instance.Status.RequestLatency.Brokers, latencyStatus.Brokers) {
+		for _, broker := range latencyStatus.Brokers {
+			if broker.State == kafkav1beta1.BrokerLatencySlow {
+				log.Info("broker is slow", "brokerId", broker.BrokerID,
+					"produceP99Ms", broker.ProduceP99Ms, "fetchP99Ms", broker.FetchP99Ms)
+			}
+		}
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, latencyStatus, log); err != nil {
+			return requeueWithError(log, "failed to update the request latencies in the Kafka Cluster status", err)
+		}
+	}
+	return requeueAfter(DefaultRequestLatencyRefreshIntervalInSec)
+}
+
+// newRequestLatencyStatus returns the status snapshot of the request latencies of the brokers sorted by broker ID,
+// the Cruise Control demotion of the brokers still Slow is carried over from the last snapshot
+func newRequestLatencyStatus(config *kafkav1beta1.RequestLatencyConfig, latencies map[string]*jmxextractor.RequestLatencies,
+	last *kafkav1beta1.RequestLatencyStatus, now time.Time) *kafkav1beta1.RequestLatencyStatus {
+	brokerIDs := make([]string, 0, len(latencies))
+	for brokerID := range latencies {
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+	sort.Slice(brokerIDs, func(i, j int) bool {
+		a, _ := strconv.Atoi(brokerIDs[i])
+		b, _ := strconv.Atoi(brokerIDs[j])
+		return a < b
+	})
+
+	status := &kafkav1beta1.RequestLatencyStatus{
+		UpdatedAt: now.Format("2006-01-02 15:04:05"),
+		Brokers:   make([]kafkav1beta1.BrokerRequestLatency, 0, len(latencies)),
+	}
+	for _, brokerID := range brokerIDs {
+		latency := latencies[brokerID]
+		broker := kafkav1beta1.BrokerRequestLatency{
+			BrokerID:     brokerID,
+			ProduceP99Ms: formatLoad(latency.ProduceP99Ms),
+			FetchP99Ms:   formatLoad(latency.FetchP99Ms),
+			State:        kafkav1beta1.BrokerLatencyHealthy,
+		}
+		if latency.ProduceP99Ms > float64(config.GetProduceP99ThresholdMs()) ||
+			latency.FetchP99Ms > float64(config.GetFetchP99ThresholdMs()) {
+			broker.State = kafkav1beta1.BrokerLatencySlow
+			if lastBroker := last.GetBroker(brokerID); lastBroker != nil && lastBroker.State == kafkav1beta1.BrokerLatencySlow {
+				broker.DemoteTaskID = lastBroker.DemoteTaskID
+			}
+		}
+		status.Brokers = append(status.Brokers, broker)
+	}
+	return status
+}
+
+// brokerToDemote returns the index of the Slow broker to demote, -1 if there is none. The brokers already demoted
+// or in maintenance are skipped and none is demoted while half or more of the brokers are Slow.
+func brokerToDemote(spec *kafkav1beta1.KafkaClusterSpec, latencyStatus *kafkav1beta1.RequestLatencyStatus) int {
+	candidate := -1
+	var slowBrokers int
+	for i, broker := range latencyStatus.Brokers {
+		if broker.State != kafkav1beta1.BrokerLatencySlow {
+			continue
+		}
+		slowBrokers++
+		if candidate < 0 && broker.DemoteTaskID == "" && !spec.IsBrokerInMaintenance(broker.BrokerID) {
+			candidate = i
+		}
+	}
+	if 2*slowBrokers >= len(latencyStatus.Brokers) {
+		return -1
+	}
+	return candidate
+}
+
+// demoteSlowBroker demotes a Slow broker via Cruise Control, nothing is done while Cruise Control is executing
+// a task, e.g. the previous demotion
+func (r *RequestLatencyReconciler) demoteSlowBroker(ctx context.Context, log logr.Logger,
+	instance *kafkav1beta1.KafkaCluster, latencyStatus *kafkav1beta1.RequestLatencyStatus) {
+	i := brokerToDemote(&instance.Spec, latencyStatus)
+	if i < 0 {
+		return
+	}
+
+	if instance.Spec.CruiseControlConfig.CruiseControlEndpoint == "" &&
+		instance.Status.CruiseControlTopicStatus != kafkav1beta1.CruiseControlTopicReady {
+		log.V(1).Info("skipping the demotion of the slow broker as Cruise Control is not deployed (yet)")
+		return
+	}
+
+	scaler, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, instance)
+	if err != nil {
+		log.Error(err, "failed to create Cruise Control Scaler instance")
+		return
+	}
+	scaler = faultinjection.CruiseControlScaler(log, instance, scaler)
+	scaler = scale.QueuedCruiseControlScaler(scale.DefaultOperationQueue, instance, "RequestLatency", scale.TriggerPeriodic, scaler)
+
+	if !scaler.IsUp(ctx) || scaler.Status(ctx).InExecution() {
+		log.V(1).Info("skipping the demotion of the slow broker as Cruise Control is not up or is executing a task")
+		return
+	}
+
+	broker := &latencyStatus.Brokers[i]
+	log.Info("demoting slow broker", "brokerId", broker.BrokerID)
+	result, err := scaler.DemoteBrokers(ctx, broker.BrokerID)
+	if err != nil {
+		log.Error(err, "demotion of slow broker could not be started", "brokerId", broker.BrokerID)
+		return
+	}
+	broker.DemoteTaskID = result.TaskID
+}
+
+// updateRequestLatencyMetrics sets the request latency metrics of the cluster and removes the ones of the brokers
+// which are no longer reported, all the metrics of the cluster are removed with an empty status
+func updateRequestLatencyMetrics(instance *kafkav1beta1.KafkaCluster, latencyStatus *kafkav1beta1.RequestLatencyStatus) {
+	reported := make(map[string]bool, len(latencyStatus.Brokers))
+	for _, broker := range latencyStatus.Brokers {
+		reported[broker.BrokerID] = true
+		labels := prometheus.Labels{"namespace": instance.Namespace, "kafka_cr": instance.Name, "broker_id": broker.BrokerID}
+		setBrokerLoadGauge(brokerProduceLatencyGauge, labels, broker.ProduceP99Ms)
+		setBrokerLoadGauge(brokerFetchLatencyGauge, labels, broker.FetchP99Ms)
+		if broker.State == kafkav1beta1.BrokerLatencySlow {
+			brokerSlowGauge.With(labels).Set(1)
+		} else {
+			brokerSlowGauge.With(labels).Set(0)
+		}
+	}
+
+	if instance.Status.RequestLatency == nil {
+		return
+	}
+	for _, broker := range instance.Status.RequestLatency.Brokers {
+		if reported[broker.BrokerID] {
+			continue
+		}
+		labels := prometheus.Labels{"namespace": instance.Namespace, "kafka_cr": instance.Name, "broker_id": broker.BrokerID}
+		for _, gauge := range brokerLatencyGauges {
+			gauge.Delete(labels)
+		}
+	}
+}
+
+// SetupRequestLatencyWithManager registers the request latency controller to the manager
+func SetupRequestLatencyWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("RequestLatency")
+
+	// the snapshots are taken periodically, only the creation, the deletion and the spec changes of the clusters
+	// trigger them in between
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
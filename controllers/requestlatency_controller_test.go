@@ -0,0 +1,102 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/jmxextractor"
+)
+
+func TestNewRequestLatencyStatus(t *testing.T) {
+	now := time.Date(2022, 5, 4, 12, 30, 0, 0, time.UTC)
+	config := &v1beta1.RequestLatencyConfig{ProduceP99ThresholdMs: 100}
+	latencies := map[string]*jmxextractor.RequestLatencies{
+		"10": {ProduceP99Ms: 12.5, FetchP99Ms: 500},
+		"2":  {ProduceP99Ms: 250, FetchP99Ms: 501},
+		"1":  {ProduceP99Ms: 20, FetchP99Ms: 2500.25},
+	}
+	last := &v1beta1.RequestLatencyStatus{
+		Brokers: []v1beta1.BrokerRequestLatency{
+			{BrokerID: "1", State: v1beta1.BrokerLatencySlow, DemoteTaskID: "task-1"},
+			{BrokerID: "10", State: v1beta1.BrokerLatencySlow, DemoteTaskID: "task-10"},
+		},
+	}
+
+	expected := &v1beta1.RequestLatencyStatus{
+		UpdatedAt: "2022-05-04 12:30:00",
+		Brokers: []v1beta1.BrokerRequestLatency{
+			{BrokerID: "1", ProduceP99Ms: "20.00", FetchP99Ms: "2500.25", State: v1beta1.BrokerLatencySlow, DemoteTaskID: "task-1"},
+			{BrokerID: "2", ProduceP99Ms: "250.00", FetchP99Ms: "501.00", State: v1beta1.BrokerLatencySlow},
+			{BrokerID: "10", ProduceP99Ms: "12.50", FetchP99Ms: "500.00", State: v1beta1.BrokerLatencyHealthy},
+		},
+	}
+	if status := newRequestLatencyStatus(config, latencies, last, now); !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected request latencies %+v, got: %+v", expected, status)
+	}
+}
+
+func TestBrokerToDemote(t *testing.T) {
+	spec := &v1beta1.KafkaClusterSpec{
+		Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1, Maintenance: true}, {Id: 2}, {Id: 3}, {Id: 4}},
+	}
+	healthy := func(id string) v1beta1.BrokerRequestLatency {
+		return v1beta1.BrokerRequestLatency{BrokerID: id, State: v1beta1.BrokerLatencyHealthy}
+	}
+	slow := func(id, taskID string) v1beta1.BrokerRequestLatency {
+		return v1beta1.BrokerRequestLatency{BrokerID: id, State: v1beta1.BrokerLatencySlow, DemoteTaskID: taskID}
+	}
+
+	testCases := []struct {
+		testName string
+		brokers  []v1beta1.BrokerRequestLatency
+		expected int
+	}{
+		{
+			testName: "no slow broker",
+			brokers:  []v1beta1.BrokerRequestLatency{healthy("0"), healthy("1"), healthy("2")},
+			expected: -1,
+		},
+		{
+			testName: "slow broker",
+			brokers:  []v1beta1.BrokerRequestLatency{healthy("0"), healthy("1"), slow("2", ""), healthy("3")},
+			expected: 2,
+		},
+		{
+			testName: "slow broker already demoted",
+			brokers:  []v1beta1.BrokerRequestLatency{slow("0", "task"), healthy("1"), healthy("2"), healthy("3"), slow("4", "")},
+			expected: 4,
+		},
+		{
+			testName: "slow broker in maintenance",
+			brokers:  []v1beta1.BrokerRequestLatency{healthy("0"), slow("1", ""), healthy("2"), healthy("3")},
+			expected: -1,
+		},
+		{
+			testName: "half of the brokers are slow",
+			brokers:  []v1beta1.BrokerRequestLatency{slow("0", ""), healthy("2"), slow("3", ""), healthy("4")},
+			expected: -1,
+		},
+	}
+	for _, test := range testCases {
+		status := &v1beta1.RequestLatencyStatus{Brokers: test.brokers}
+		if i := brokerToDemote(spec, status); i != test.expected {
+			t.Errorf("%s: expected broker index %d to demote, got: %d", test.testName, test.expected, i)
+		}
+	}
+}
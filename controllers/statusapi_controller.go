@@ -0,0 +1,73 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/banzaicloud/koperator/internal/statusapi"
+)
+
+// StatusAPIController implements Runnable serving the read-only status summaries of the Kafka resources to the
+// callers allowed to read them by RBAC
+type StatusAPIController struct {
+	Client  client.Client
+	Addr    string
+	CertDir string
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// SetStatusAPIWithManager adds the status API serving HTTPS at addr with the tls.crt and tls.key of certDir to the
+// Manager
+func SetStatusAPIWithManager(mgr manager.Manager, addr, certDir string) error {
+	return mgr.Add(StatusAPIController{Client: mgr.GetClient(), Addr: addr, CertDir: certDir})
+}
+
+// NeedLeaderElection returns false as the status API is served by every replica of the operator
+func (c StatusAPIController) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the status API until the context is done
+func (c StatusAPIController) Start(ctx context.Context) error {
+	log := logf.Log.WithName("statusapi")
+
+	httpServer := &http.Server{
+		Addr:    c.Addr,
+		Handler: statusapi.NewApp(log, c.Client, statusapi.NewReviewAuthorizer(c.Client)),
+	}
+	go func() {
+		<-ctx.Done()
+		if err := httpServer.Shutdown(context.Background()); err != nil {
+			log.Error(err, "shutting down status API failed")
+		}
+	}()
+
+	log.Info("serving status API", "addr", c.Addr)
+	err := httpServer.ListenAndServeTLS(filepath.Join(c.CertDir, "tls.crt"), filepath.Join(c.CertDir, "tls.key"))
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
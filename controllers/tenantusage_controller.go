@@ -0,0 +1,133 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	kafkav1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	kafkav1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// TenantUsageReconciler records the usage of the kafka clusters by the KafkaTopics of the namespaces
+// sharing them in the status of the kafka cluster objects
+type TenantUsageReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkatopics,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+
+func (r *TenantUsageReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	// Fetch the KafkaCluster instance
+	instance := &kafkav1beta1.KafkaCluster{}
+	err := r.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return reconciled()
+	}
+
+	var usage map[string]kafkav1beta1.TenantUsage
+	if instance.Spec.Tenancy != nil {
+		var topics kafkav1alpha1.KafkaTopicList
+		if err := r.List(ctx, &topics); err != nil {
+			return requeueWithError(log, "failed to list KafkaTopics", err)
+		}
+		usage = newTenantUsage(instance, topics.Items)
+	}
+
+	if !reflect.DeepEqual(instance.Status.TenantUsage, usage) {
+		if err := k8sutil.UpdateCRStatus(r.Client, instance, usage, log); err != nil {
+			return requeueWithError(log, "failed to update the tenant usage in the Kafka Cluster status", err)
+		}
+	}
+	return reconciled()
+}
+
+// newTenantUsage returns the usage of the cluster by the KafkaTopics of each namespace listed in the tenancy
+// of the cluster, the namespaces without KafkaTopics are listed with zero usage
+func newTenantUsage(cluster *kafkav1beta1.KafkaCluster, topics []kafkav1alpha1.KafkaTopic) map[string]kafkav1beta1.TenantUsage {
+	if len(cluster.Spec.Tenancy.Namespaces) == 0 {
+		return nil
+	}
+	usageOfTopics := k8sutil.TenantUsageOfKafkaTopics(topics, cluster)
+	usage := make(map[string]kafkav1beta1.TenantUsage, len(cluster.Spec.Tenancy.Namespaces))
+	for _, namespace := range cluster.Spec.Tenancy.Namespaces {
+		usage[namespace.Name] = usageOfTopics[namespace.Name]
+	}
+	return usage
+}
+
+// SetupTenantUsageWithManager registers the tenant usage controller to the manager
+func SetupTenantUsageWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&kafkav1beta1.KafkaCluster{}).
+		Watches(
+			&source.Kind{Type: &kafkav1alpha1.KafkaTopic{}},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []ctrl.Request {
+				topic, ok := obj.(*kafkav1alpha1.KafkaTopic)
+				if !ok {
+					return []ctrl.Request{}
+				}
+				namespace := topic.Spec.ClusterRef.Namespace
+				if namespace == "" {
+					namespace = topic.GetNamespace()
+				}
+				return []ctrl.Request{{
+					NamespacedName: types.NamespacedName{Namespace: namespace, Name: topic.Spec.ClusterRef.Name},
+				}}
+			})).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		Named("TenantUsage")
+
+	// only the spec changes of the clusters can change the usage besides the KafkaTopics
+	builder.WithEventFilter(
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if _, ok := e.ObjectNew.(*kafkav1beta1.KafkaCluster); ok {
+					oldObj := e.ObjectOld.(*kafkav1beta1.KafkaCluster)
+					newObj := e.ObjectNew.(*kafkav1beta1.KafkaCluster)
+					return oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+						oldObj.GetGeneration() != newObj.GetGeneration()
+				}
+				return true
+			},
+		})
+
+	return builder
+}
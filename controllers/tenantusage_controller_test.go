@@ -0,0 +1,71 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestNewTenantUsage(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Tenancy: &v1beta1.TenancyConfig{
+				Namespaces: []v1beta1.TenantNamespace{{Name: "team-a"}, {Name: "team-b"}},
+			},
+		},
+	}
+	newTopic := func(name, namespace string, partitions int32, ref v1alpha1.ClusterReference) v1alpha1.KafkaTopic {
+		return v1alpha1.KafkaTopic{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1alpha1.KafkaTopicSpec{Name: name, Partitions: partitions, ClusterRef: ref},
+		}
+	}
+	ref := v1alpha1.ClusterReference{Name: "kafka", Namespace: "kafka"}
+	deleted := newTopic("deleted", "team-a", 10, ref)
+	now := metav1.Now()
+	deleted.SetDeletionTimestamp(&now)
+
+	topics := []v1alpha1.KafkaTopic{
+		newTopic("orders", "team-a", 3, ref),
+		newTopic("payments", "team-a", 6, ref),
+		deleted,
+		// references another cluster
+		newTopic("invoices", "team-a", 12, v1alpha1.ClusterReference{Name: "other", Namespace: "kafka"}),
+		// the namespace of the reference defaults to the namespace of the topic
+		newTopic("local", "team-a", 1, v1alpha1.ClusterReference{Name: "kafka"}),
+		// the namespace of the cluster is not listed
+		newTopic("internal", "kafka", 1, v1alpha1.ClusterReference{Name: "kafka"}),
+	}
+
+	expected := map[string]v1beta1.TenantUsage{
+		"team-a": {Topics: 2, Partitions: 9},
+		"team-b": {},
+	}
+	if usage := newTenantUsage(cluster, topics); !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected usage %+v, got: %+v", expected, usage)
+	}
+
+	cluster.Spec.Tenancy.Namespaces = nil
+	if usage := newTenantUsage(cluster, topics); usage != nil {
+		t.Errorf("expected no usage without tenant namespaces, got: %+v", usage)
+	}
+}
@@ -147,6 +147,7 @@ var _ = BeforeSuite(func() {
 		Client:              mgr.GetClient(),
 		DirectClient:        mgr.GetAPIReader(),
 		KafkaClientProvider: kafkaclient.NewMockProvider(),
+		Recorder:            mgr.GetEventRecorderFor("kafkacluster-controller"),
 	}
 
 	err = controllers.SetupKafkaClusterWithManager(mgr).Complete(&kafkaClusterReconciler)
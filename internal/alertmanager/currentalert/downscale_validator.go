@@ -16,6 +16,8 @@ package currentalert
 
 import (
 	emperror "emperror.dev/errors"
+
+	"github.com/banzaicloud/koperator/pkg/scale"
 )
 
 type downScaleValidator struct {
@@ -35,6 +37,11 @@ func (a downScaleValidator) validateAlert() error {
 	if a.Alert.Annotations["command"] != DownScaleCommand {
 		return emperror.NewWithDetails("unsupported command", "comand", a.Alert.Annotations["command"])
 	}
+	switch selection := scale.BrokerSelectionMode(a.Alert.Annotations["brokerSelection"]); selection {
+	case "", scale.BrokerSelectionByReplicas, scale.BrokerSelectionByUtilization:
+	default:
+		return emperror.NewWithDetails("unsupported broker selection", "brokerSelection", selection)
+	}
 
 	return nil
 }
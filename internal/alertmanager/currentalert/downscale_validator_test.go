@@ -42,6 +42,35 @@ func TestDownScaleValidator_validateAlert(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "downScale validate success with broker selection",
+			fields: fields{
+				Alert: &currentAlertStruct{
+					Labels: model.LabelSet{
+						"kafka_cr": "kafka",
+					},
+					Annotations: model.LabelSet{
+						"command":         DownScaleCommand,
+						"brokerSelection": "utilization",
+					},
+				},
+			},
+		},
+		{
+			name: "downScale validate failed due to unsupported broker selection",
+			fields: fields{
+				Alert: &currentAlertStruct{
+					Labels: model.LabelSet{
+						"kafka_cr": "kafka",
+					},
+					Annotations: model.LabelSet{
+						"command":         DownScaleCommand,
+						"brokerSelection": "random",
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "downScale validate failed due to missing label",
 			fields: fields{
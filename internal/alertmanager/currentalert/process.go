@@ -151,7 +151,7 @@ func (e *examiner) processAlert(ds disableScaling) (bool, error) {
 			e.Log.Info("downscale is skipped due to downscale limit")
 			return false, nil
 		}
-		err := downScale(e.Log, e.Alert.Labels, e.Client)
+		err := downScale(e.Log, e.Alert.Labels, e.Alert.Annotations, e.Client)
 		if err != nil {
 			return false, err
 		}
@@ -191,6 +191,16 @@ func addPvc(log logr.Logger, alertLabels model.LabelSet, alertAnnotations model.
 		return err
 	}
 
+	// Check for skipping in case the broker is in maintenance
+	inMaintenance, err := brokerInMaintenance(pvc.Labels["kafka_cr"], string(alertLabels["namespace"]), pvc.Labels["brokerId"], client)
+	if err != nil {
+		return err
+	}
+	if inMaintenance {
+		log.Info("addPvc is skipped as the broker is in maintenance", "broker id", pvc.Labels["brokerId"])
+		return nil
+	}
+
 	// Check for skipping in case of pending or running CC task
 	ccTaskExists, err := pendingOrRunningCCTaskExists(pvc.Labels, string(alertLabels["namespace"]), client, log)
 	if err != nil {
@@ -248,6 +258,10 @@ func resizePvc(log logr.Logger, labels model.LabelSet, annotiations model.LabelS
 	if err != nil {
 		return err
 	}
+	if cr.Spec.IsBrokerInMaintenance(pvc.Labels["brokerId"]) {
+		log.Info("resizePvc is skipped as the broker is in maintenance", "broker id", pvc.Labels["brokerId"])
+		return nil
+	}
 	incrementBy, err := resource.ParseQuantity(string(annotiations["incrementBy"]))
 	if err != nil {
 		return err
@@ -293,7 +307,7 @@ func resizePvc(log logr.Logger, labels model.LabelSet, annotiations model.LabelS
 	return nil
 }
 
-func downScale(log logr.Logger, labels model.LabelSet, client client.Client) error {
+func downScale(log logr.Logger, labels model.LabelSet, annotations model.LabelSet, client client.Client) error {
 	cr, err := k8sutil.GetCr(string(labels["kafka_cr"]), string(labels["namespace"]), client)
 	if err != nil {
 		return err
@@ -316,17 +330,24 @@ func downScale(log logr.Logger, labels model.LabelSet, client client.Client) err
 	} else {
 		cruiseControlURL := scale.CruiseControlURLFromKafkaCluster(cr)
 		// FIXME: we should reuse the context of passed to AController.Start() here
-		cc, err := scale.NewCruiseControlScaler(context.TODO(), cruiseControlURL)
+		cc, err := scale.DefaultScalerRegistry.CruiseControlScaler(context.TODO(), client, cr)
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "failed to initialize Cruise Control Scaler",
 				"cruise control url", cruiseControlURL)
 		}
-		brokerID, err = cc.BrokerWithLeastPartitionReplicas()
+		// the brokerSelection annotation of the alert selects how the broker to remove is picked
+		brokerSelection := scale.BrokerSelectionMode(annotations["brokerSelection"])
+		brokerID, err = cc.BrokerWithLeastPartitionReplicas(context.TODO(), brokerSelection)
 		if err != nil {
 			return err
 		}
 	}
 
+	if cr.Spec.IsBrokerInMaintenance(brokerID) {
+		log.Info("downscale is skipped as the broker is in maintenance", "broker id", brokerID)
+		return nil
+	}
+
 	err = k8sutil.RemoveBrokerFromCr(brokerID, string(labels["kafka_cr"]), string(labels["namespace"]), client)
 	if err != nil {
 		return err
@@ -425,6 +446,14 @@ func getPvc(name, namespace string, client client.Client) (*corev1.PersistentVol
 	return pvc, nil
 }
 
+func brokerInMaintenance(kafkaCrName, namespace, brokerID string, client client.Client) (bool, error) {
+	kafkaCluster, err := k8sutil.GetCr(kafkaCrName, namespace, client)
+	if err != nil {
+		return false, err
+	}
+	return kafkaCluster.Spec.IsBrokerInMaintenance(brokerID), nil
+}
+
 func pendingOrRunningCCTaskExists(pvcLabels map[string]string, namespace string, client client.Client, log logr.Logger) (bool, error) {
 	kafkaCluster, err := k8sutil.GetCr(pvcLabels["kafka_cr"], namespace, client)
 	if err != nil {
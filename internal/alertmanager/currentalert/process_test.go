@@ -650,6 +650,35 @@ func Test_downScale(t *testing.T) {
 			},
 			expectedBrokers: []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}},
 		},
+		{
+			testName: "downscale skipped for broker in maintenance",
+			kafkaCluster: v1beta1.KafkaCluster{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "test-namespace",
+				},
+				Spec: v1beta1.KafkaClusterSpec{
+					BrokerConfigGroups: map[string]v1beta1.BrokerConfig{
+						"default": {},
+					},
+					Brokers: []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}, {Id: 1, BrokerConfigGroup: "default", Maintenance: true}},
+				},
+			},
+			alert: model.Alert{
+				Labels: model.LabelSet{
+					"kafka_cr":   "test-cluster",
+					"namespace":  "test-namespace",
+					"severity":   "critical",
+					"alertGroup": "test",
+					"brokerId":   "1",
+				},
+				Annotations: map[model.LabelName]model.LabelValue{
+					"command":           "downscale",
+					"brokerConfigGroup": "default",
+				},
+			},
+			expectedBrokers: []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}, {Id: 1, BrokerConfigGroup: "default", Maintenance: true}},
+		},
 	}
 
 	for _, test := range testCases {
@@ -665,7 +694,7 @@ func Test_downScale(t *testing.T) {
 				}
 			}()
 
-			if err := downScale(logr.Discard(), test.alert.Labels, testClient); err != nil {
+			if err := downScale(logr.Discard(), test.alert.Labels, test.alert.Annotations, testClient); err != nil {
 				t.Error(err)
 				return
 			}
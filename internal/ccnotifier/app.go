@@ -0,0 +1,349 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ccnotifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/internal/statusapi"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+)
+
+const (
+	// APIPathPrefix is the path prefix of the endpoint, the anomalies of a cluster are posted to
+	// /api/v1/namespaces/<namespace>/kafkaclusters/<name>/anomalies
+	APIPathPrefix = "/api/v1/namespaces/"
+
+	kafkaClustersResource = "kafkaclusters"
+	anomaliesSubresource  = "anomalies"
+
+	// maxAnomalyBytes is the size limit of the anomalies posted
+	maxAnomalyBytes = 1 << 20
+	// maxOperationNameSuffixLength limits the part of the names of the CruiseControlOperations derived from the
+	// anomaly IDs
+	maxOperationNameSuffixLength = 40
+)
+
+// AnomalyType is the type of an anomaly detected by Cruise Control
+type AnomalyType string
+
+const (
+	AnomalyGoalViolation AnomalyType = "GOAL_VIOLATION"
+	AnomalyBrokerFailure AnomalyType = "BROKER_FAILURE"
+	AnomalyDiskFailure   AnomalyType = "DISK_FAILURE"
+)
+
+// Anomaly is an anomaly detected by Cruise Control as posted by its notifier
+type Anomaly struct {
+	AnomalyID   string      `json:"anomalyId"`
+	AnomalyType AnomalyType `json:"anomalyType"`
+	Description string      `json:"description,omitempty"`
+	// BrokerIDs are the failed brokers, or the brokers of the failed disks
+	BrokerIDs []string `json:"brokerIds,omitempty"`
+	// ViolatedGoals are the violated goals which can be fixed by a rebalance
+	ViolatedGoals []string `json:"violatedGoals,omitempty"`
+	// LogDirs are the failed log dirs of a disk failure
+	LogDirs []string `json:"logDirs,omitempty"`
+}
+
+// handler receives the anomalies notified by Cruise Control
+type handler struct {
+	log                 logr.Logger
+	client              client.Client
+	recorder            record.EventRecorder
+	authorizer          statusapi.Authorizer
+	kafkaClientProvider kafkaclient.Provider
+}
+
+// NewApp returns the HTTP handler receiving the anomalies notified by Cruise Control, each of them is recorded as a
+// Kubernetes event of the cluster and remediated by a CruiseControlOperation if the cluster enables it. The callers
+// have to be allowed by the authorizer to create the anomalies subresource of the cluster.
+func NewApp(log logr.Logger, client client.Client, recorder record.EventRecorder, authorizer statusapi.Authorizer,
+	kafkaClientProvider kafkaclient.Provider) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(APIPathPrefix, &handler{
+		log:                 log,
+		client:              client,
+		recorder:            recorder,
+		authorizer:          authorizer,
+		kafkaClientProvider: kafkaClientProvider,
+	})
+	return mux
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// <namespace>/kafkaclusters/<name>/anomalies
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, APIPathPrefix), "/"), "/")
+	if len(parts) != 4 || parts[0] == "" || parts[1] != kafkaClustersResource || parts[2] == "" ||
+		parts[3] != anomaliesSubresource {
+		http.NotFound(w, r)
+		return
+	}
+	key := client.ObjectKey{Namespace: parts[0], Name: parts[2]}
+
+	authorization := r.Header.Get("Authorization")
+	token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if !strings.HasPrefix(authorization, "Bearer ") || token == "" {
+		http.Error(w, "bearer token required", http.StatusUnauthorized)
+		return
+	}
+	allowed, err := h.authorizer.Authorize(r.Context(), token, authorizationv1.ResourceAttributes{
+		Namespace:   key.Namespace,
+		Verb:        "create",
+		Group:       v1beta1.GroupVersion.Group,
+		Resource:    kafkaClustersResource,
+		Subresource: anomaliesSubresource,
+		Name:        key.Name,
+	})
+	if err != nil {
+		h.log.Error(err, "authorizing request failed", "path", r.URL.Path)
+		http.Error(w, "authorizing request failed", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	anomaly := Anomaly{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAnomalyBytes)).Decode(&anomaly); err != nil {
+		http.Error(w, "invalid anomaly", http.StatusBadRequest)
+		return
+	}
+	if anomaly.AnomalyID == "" || anomaly.AnomalyType == "" {
+		http.Error(w, "anomalyId and anomalyType are required", http.StatusBadRequest)
+		return
+	}
+
+	cluster := &v1beta1.KafkaCluster{}
+	if err := h.client.Get(r.Context(), key, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+		h.log.Error(err, "reading cluster failed", "cluster", key)
+		http.Error(w, "reading cluster failed", http.StatusInternalServerError)
+		return
+	}
+	notifier := cluster.Spec.CruiseControlConfig.AnomalyNotifier
+	if notifier == nil {
+		http.Error(w, "the cluster does not receive anomalies", http.StatusForbidden)
+		return
+	}
+
+	log := h.log.WithValues("cluster", key, "anomalyId", anomaly.AnomalyID, "anomalyType", anomaly.AnomalyType)
+	log.Info("anomaly notified by Cruise Control", "brokers", anomaly.BrokerIDs, "goals", anomaly.ViolatedGoals)
+	h.recorder.Event(cluster, corev1.EventTypeWarning, eventReason(anomaly.AnomalyType), eventMessage(anomaly))
+
+	if anomaly.AnomalyType == AnomalyBrokerFailure && notifier.RemediateBrokerFailures {
+		brokerIDs, err := h.removableBrokerIDs(cluster, anomaly.BrokerIDs)
+		if err != nil {
+			log.Error(err, "checking the replicas of the failed brokers failed")
+			http.Error(w, "checking the replicas of the failed brokers failed", http.StatusServiceUnavailable)
+			return
+		}
+		if len(brokerIDs) < len(anomaly.BrokerIDs) {
+			log.Info("brokers left out of the remediation", "brokers", anomaly.BrokerIDs, "removableBrokers", brokerIDs)
+		}
+		anomaly.BrokerIDs = brokerIDs
+	}
+
+	if operation := remediationOperation(cluster, anomaly); operation != nil {
+		err := h.client.Create(r.Context(), operation)
+		switch {
+		case err == nil:
+			log.Info("remediation operation created", "operation", operation.Name)
+		case apierrors.IsAlreadyExists(err):
+			// Cruise Control notifies the anomalies again until they are fixed
+		default:
+			log.Error(err, "creating remediation operation failed", "operation", operation.Name)
+			http.Error(w, "creating remediation operation failed", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func eventReason(anomalyType AnomalyType) string {
+	switch anomalyType {
+	case AnomalyGoalViolation:
+		return "CruiseControlGoalViolation"
+	case AnomalyBrokerFailure:
+		return "CruiseControlBrokerFailure"
+	case AnomalyDiskFailure:
+		return "CruiseControlDiskFailure"
+	default:
+		return "CruiseControlAnomaly"
+	}
+}
+
+func eventMessage(anomaly Anomaly) string {
+	message := fmt.Sprintf("Cruise Control detected %s anomaly %s", anomaly.AnomalyType, anomaly.AnomalyID)
+	if len(anomaly.BrokerIDs) > 0 {
+		message += fmt.Sprintf(" on brokers %s", strings.Join(anomaly.BrokerIDs, ","))
+	}
+	if len(anomaly.LogDirs) > 0 {
+		message += fmt.Sprintf(", log dirs %s", strings.Join(anomaly.LogDirs, ","))
+	}
+	if len(anomaly.ViolatedGoals) > 0 {
+		message += fmt.Sprintf(", violated goals %s", strings.Join(anomaly.ViolatedGoals, ","))
+	}
+	if anomaly.Description != "" {
+		message += ": " + anomaly.Description
+	}
+	return message
+}
+
+// removableBrokerIDs returns the failed brokers which can be removed from the cluster: the brokers of the cluster
+// whose removal would not take away the last in-sync replicas of any partition
+func (h *handler) removableBrokerIDs(cluster *v1beta1.KafkaCluster, brokerIDs []string) ([]string, error) {
+	brokerIDs = clusterBrokerIDs(cluster, brokerIDs)
+	if len(brokerIDs) == 0 {
+		return nil, nil
+	}
+
+	kClient, closeClient, err := h.kafkaClientProvider.NewFromCluster(h.client, cluster)
+	if err != nil {
+		return nil, err
+	}
+	defer closeClient()
+
+	partitions, err := kClient.PartitionReplicas()
+	if err != nil {
+		return nil, err
+	}
+	return keepInSyncReplicas(partitions, brokerIDs), nil
+}
+
+// clusterBrokerIDs returns the given broker IDs which are brokers of the cluster
+func clusterBrokerIDs(cluster *v1beta1.KafkaCluster, brokerIDs []string) []string {
+	var ids []string
+	for _, brokerID := range brokerIDs {
+		id, err := strconv.Atoi(brokerID)
+		if err != nil {
+			continue
+		}
+		for _, broker := range cluster.Spec.Brokers {
+			if broker.Id == int32(id) {
+				ids = append(ids, brokerID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// keepInSyncReplicas leaves out the brokers holding the last in-sync replicas of a partition from the brokers to be
+// removed
+func keepInSyncReplicas(partitions []kafkaclient.PartitionReplicas, brokerIDs []string) []string {
+	removed := make(map[int32]bool, len(brokerIDs))
+	for _, brokerID := range brokerIDs {
+		id, _ := strconv.Atoi(brokerID)
+		removed[int32(id)] = true
+	}
+	for _, partition := range partitions {
+		lastInSync := len(partition.Isr) > 0
+		for _, id := range partition.Isr {
+			if !removed[id] {
+				lastInSync = false
+				break
+			}
+		}
+		if lastInSync {
+			for _, id := range partition.Isr {
+				removed[id] = false
+			}
+		}
+	}
+
+	var ids []string
+	for _, brokerID := range brokerIDs {
+		id, _ := strconv.Atoi(brokerID)
+		if removed[int32(id)] {
+			ids = append(ids, brokerID)
+		}
+	}
+	return ids
+}
+
+// remediationOperation returns the CruiseControlOperation remediating the anomaly if the cluster enables it, nil
+// otherwise. Its name is derived from the anomaly ID, so each anomaly is remediated once.
+func remediationOperation(cluster *v1beta1.KafkaCluster, anomaly Anomaly) *v1alpha1.CruiseControlOperation {
+	notifier := cluster.Spec.CruiseControlConfig.AnomalyNotifier
+	if notifier == nil {
+		return nil
+	}
+
+	var spec v1alpha1.CruiseControlOperationSpec
+	switch {
+	case anomaly.AnomalyType == AnomalyGoalViolation && notifier.RemediateGoalViolations && len(anomaly.ViolatedGoals) > 0:
+		spec = v1alpha1.CruiseControlOperationSpec{
+			Operation: v1alpha1.OperationRebalance,
+			Parameters: v1alpha1.CruiseControlOperationParameters{
+				BrokerIDs: cluster.Spec.GetBrokerIDsInMaintenance(),
+				Goals:     anomaly.ViolatedGoals,
+			},
+		}
+	case anomaly.AnomalyType == AnomalyBrokerFailure && notifier.RemediateBrokerFailures && len(anomaly.BrokerIDs) > 0:
+		spec = v1alpha1.CruiseControlOperationSpec{
+			Operation:  v1alpha1.OperationRemoveBroker,
+			Parameters: v1alpha1.CruiseControlOperationParameters{BrokerIDs: anomaly.BrokerIDs},
+		}
+	default:
+		return nil
+	}
+	spec.ClusterRef = v1alpha1.ClusterReference{Name: cluster.Name, Namespace: cluster.Namespace}
+
+	name := fmt.Sprintf("%s-anomaly-%s", cluster.Name, operationNameSuffix(anomaly.AnomalyID))
+	return &v1alpha1.CruiseControlOperation{
+		ObjectMeta: templates.ObjectMeta(name, map[string]string{"app": "cruisecontrol-anomaly"}, cluster),
+		Spec:       spec,
+	}
+}
+
+// operationNameSuffix turns the anomaly ID into a valid part of an object name
+func operationNameSuffix(anomalyID string) string {
+	suffix := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(anomalyID))
+	if len(suffix) > maxOperationNameSuffixLength {
+		suffix = suffix[:maxOperationNameSuffixLength]
+	}
+	return strings.Trim(suffix, "-")
+}
@@ -0,0 +1,257 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ccnotifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+type fakeAuthorizer struct {
+	allowed bool
+}
+
+func (a fakeAuthorizer) Authorize(context.Context, string, authorizationv1.ResourceAttributes) (bool, error) {
+	return a.allowed, nil
+}
+
+// partitionsKafkaClient reports the given partitions, the other methods of the client are not implemented
+type partitionsKafkaClient struct {
+	kafkaclient.KafkaClient
+	partitions []kafkaclient.PartitionReplicas
+}
+
+func (c partitionsKafkaClient) PartitionReplicas() ([]kafkaclient.PartitionReplicas, error) {
+	return c.partitions, nil
+}
+
+type partitionsKafkaProvider struct {
+	partitions []kafkaclient.PartitionReplicas
+}
+
+func (p partitionsKafkaProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return partitionsKafkaClient{partitions: p.partitions}, func() {}, nil
+}
+
+func newTestApp(t *testing.T) (http.Handler, client.Client, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				AnomalyNotifier: &v1beta1.CruiseControlAnomalyNotifier{RemediateBrokerFailures: true},
+			},
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}},
+		},
+	}
+	disabled := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "disabled", Namespace: "kafka"}}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, disabled).Build()
+	recorder := record.NewFakeRecorder(10)
+	provider := partitionsKafkaProvider{partitions: []kafkaclient.PartitionReplicas{
+		{Topic: "orders", Partition: 0, Replicas: []int32{0, 1, 2}, Isr: []int32{0, 1}},
+	}}
+	return NewApp(logr.Discard(), client, recorder, fakeAuthorizer{allowed: true}, provider), client, recorder
+}
+
+func postAnomaly(app http.Handler, path, body string) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer cruise-control")
+	app.ServeHTTP(response, request)
+	return response
+}
+
+func TestReceiveAnomaly(t *testing.T) {
+	app, client, recorder := newTestApp(t)
+	path := "/api/v1/namespaces/kafka/kafkaclusters/kafka/anomalies"
+
+	testCases := []struct {
+		testName string
+		path     string
+		body     string
+		status   int
+	}{
+		{
+			testName: "unknown path",
+			path:     "/api/v1/namespaces/kafka/kafkaclusters/kafka",
+			body:     `{"anomalyId":"a1","anomalyType":"GOAL_VIOLATION"}`,
+			status:   http.StatusNotFound,
+		},
+		{
+			testName: "unknown cluster",
+			path:     "/api/v1/namespaces/kafka/kafkaclusters/other/anomalies",
+			body:     `{"anomalyId":"a1","anomalyType":"GOAL_VIOLATION"}`,
+			status:   http.StatusNotFound,
+		},
+		{
+			testName: "cluster without anomaly notifier",
+			path:     "/api/v1/namespaces/kafka/kafkaclusters/disabled/anomalies",
+			body:     `{"anomalyId":"a1","anomalyType":"GOAL_VIOLATION"}`,
+			status:   http.StatusForbidden,
+		},
+		{
+			testName: "invalid anomaly",
+			path:     path,
+			body:     `{"anomalyType":"GOAL_VIOLATION"}`,
+			status:   http.StatusBadRequest,
+		},
+		{
+			testName: "goal violation",
+			path:     path,
+			body:     `{"anomalyId":"a1","anomalyType":"GOAL_VIOLATION","violatedGoals":["RackAwareGoal"]}`,
+			status:   http.StatusAccepted,
+		},
+		{
+			testName: "broker failure",
+			path:     path,
+			body:     `{"anomalyId":"A2_b","anomalyType":"BROKER_FAILURE","brokerIds":["1"]}`,
+			status:   http.StatusAccepted,
+		},
+		{
+			testName: "broker failure notified again",
+			path:     path,
+			body:     `{"anomalyId":"A2_b","anomalyType":"BROKER_FAILURE","brokerIds":["1"]}`,
+			status:   http.StatusAccepted,
+		},
+		{
+			testName: "broker failure of unknown brokers and of the last in-sync replicas",
+			path:     path,
+			body:     `{"anomalyId":"a3","anomalyType":"BROKER_FAILURE","brokerIds":["0","1","7"]}`,
+			status:   http.StatusAccepted,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			if response := postAnomaly(app, test.path, test.body); response.Code != test.status {
+				t.Errorf("expected status %d, got %d: %s", test.status, response.Code, response.Body.String())
+			}
+		})
+	}
+
+	if len(recorder.Events) != 4 {
+		t.Errorf("expected 4 events, got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning CruiseControlGoalViolation") {
+		t.Errorf("unexpected event: %s", event)
+	}
+
+	operations := &v1alpha1.CruiseControlOperationList{}
+	if err := client.List(context.TODO(), operations); err != nil {
+		t.Fatal(err)
+	}
+	// the goal violations are not remediated by the cluster
+	if len(operations.Items) != 1 {
+		t.Fatalf("expected 1 operation, got %d", len(operations.Items))
+	}
+	operation := operations.Items[0]
+	if operation.Name != "kafka-anomaly-a2-b" || operation.Spec.Operation != v1alpha1.OperationRemoveBroker ||
+		len(operation.Spec.Parameters.BrokerIDs) != 1 || operation.Spec.ClusterRef.Name != "kafka" {
+		t.Errorf("unexpected operation: %+v", operation)
+	}
+}
+
+func TestReceiveAnomalyMethod(t *testing.T) {
+	app, _, _ := newTestApp(t)
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/kafka/kafkaclusters/kafka/anomalies", nil))
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, response.Code)
+	}
+}
+
+func TestReceiveAnomalyAuthorization(t *testing.T) {
+	_, client, recorder := newTestApp(t)
+	path := "/api/v1/namespaces/kafka/kafkaclusters/kafka/anomalies"
+	body := `{"anomalyId":"a1","anomalyType":"BROKER_FAILURE","brokerIds":["1"]}`
+
+	response := httptest.NewRecorder()
+	NewApp(logr.Discard(), client, recorder, fakeAuthorizer{allowed: true}, partitionsKafkaProvider{}).
+		ServeHTTP(response, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if response.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d without token, got %d", http.StatusUnauthorized, response.Code)
+	}
+
+	forbidden := NewApp(logr.Discard(), client, recorder, fakeAuthorizer{}, partitionsKafkaProvider{})
+	if response := postAnomaly(forbidden, path, body); response.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, response.Code)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("expected no events, got %d", len(recorder.Events))
+	}
+}
+
+func TestKeepInSyncReplicas(t *testing.T) {
+	partitions := []kafkaclient.PartitionReplicas{
+		{Topic: "orders", Partition: 0, Replicas: []int32{0, 1, 2}, Isr: []int32{0, 1}},
+		{Topic: "orders", Partition: 1, Replicas: []int32{1, 2, 3}, Isr: []int32{3}},
+		{Topic: "offline", Partition: 0, Replicas: []int32{4}},
+	}
+	testCases := []struct {
+		brokerIDs []string
+		expected  []string
+	}{
+		{brokerIDs: []string{"1"}, expected: []string{"1"}},
+		{brokerIDs: []string{"2", "4"}, expected: []string{"2", "4"}},
+		{brokerIDs: []string{"0", "1", "2"}, expected: []string{"2"}},
+		{brokerIDs: []string{"3", "2"}, expected: []string{"2"}},
+	}
+	for _, test := range testCases {
+		if ids := keepInSyncReplicas(partitions, test.brokerIDs); !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("%v: expected removable brokers %v, got: %v", test.brokerIDs, test.expected, ids)
+		}
+	}
+}
+
+func TestRemediationOperation(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	goalViolation := Anomaly{AnomalyID: "a1", AnomalyType: AnomalyGoalViolation, ViolatedGoals: []string{"RackAwareGoal"}}
+
+	if operation := remediationOperation(cluster, goalViolation); operation != nil {
+		t.Errorf("expected no operation without anomaly notifier, got: %+v", operation)
+	}
+
+	cluster.Spec.CruiseControlConfig.AnomalyNotifier = &v1beta1.CruiseControlAnomalyNotifier{RemediateGoalViolations: true}
+	operation := remediationOperation(cluster, goalViolation)
+	if operation == nil || operation.Spec.Operation != v1alpha1.OperationRebalance ||
+		len(operation.Spec.Parameters.Goals) != 1 {
+		t.Errorf("expected rebalance operation, got: %+v", operation)
+	}
+	if operation := remediationOperation(cluster, Anomaly{AnomalyID: "a2", AnomalyType: AnomalyDiskFailure}); operation != nil {
+		t.Errorf("expected no operation for disk failure, got: %+v", operation)
+	}
+}
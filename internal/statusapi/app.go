@@ -0,0 +1,239 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const (
+	// APIPathPrefix is the path prefix of the endpoints, they are served at
+	// /api/v1/namespaces/<namespace>/<kafkaclusters|kafkatopics|kafkausers>[/<name>]
+	APIPathPrefix = "/api/v1/namespaces/"
+
+	// SummarySubresource is the subresource of the KafkaClusters, KafkaTopics and KafkaUsers the callers have to be
+	// allowed to get or list by RBAC to read their summaries, e.g. kafkaclusters/summary
+	SummarySubresource = "summary"
+
+	kafkaClustersResource = "kafkaclusters"
+	kafkaTopicsResource   = "kafkatopics"
+	kafkaUsersResource    = "kafkausers"
+)
+
+// Authorizer decides whether the caller presenting the bearer token is allowed to access the resource
+type Authorizer interface {
+	Authorize(ctx context.Context, token string, attributes authorizationv1.ResourceAttributes) (bool, error)
+}
+
+type reviewAuthorizer struct {
+	client client.Client
+}
+
+// NewReviewAuthorizer returns an Authorizer authenticating the callers with TokenReviews and authorizing them with
+// SubjectAccessReviews, so the access is granted by the RBAC rules of the Kubernetes cluster
+func NewReviewAuthorizer(client client.Client) Authorizer {
+	return &reviewAuthorizer{client: client}
+}
+
+func (a *reviewAuthorizer) Authorize(ctx context.Context, token string, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	tokenReview := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := a.client.Create(ctx, tokenReview); err != nil {
+		return false, err
+	}
+	if !tokenReview.Status.Authenticated {
+		return false, nil
+	}
+
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	accessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+			User:               user.Username,
+			Groups:             user.Groups,
+			UID:                user.UID,
+			Extra:              extra,
+		},
+	}
+	if err := a.client.Create(ctx, accessReview); err != nil {
+		return false, err
+	}
+	return accessReview.Status.Allowed, nil
+}
+
+// handler serves the read-only summaries of the KafkaClusters, KafkaTopics and KafkaUsers
+type handler struct {
+	log        logr.Logger
+	client     client.Reader
+	authorizer Authorizer
+}
+
+// NewApp returns the HTTP handler of the status API serving the summaries of the resources to the callers allowed to
+// read them by the authorizer
+func NewApp(log logr.Logger, client client.Reader, authorizer Authorizer) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(APIPathPrefix, &handler{log: log, client: client, authorizer: authorizer})
+	return mux
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// <namespace>/<resource>[/<name>]
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, APIPathPrefix), "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	namespace, resource := parts[0], parts[1]
+	var name string
+	if len(parts) == 3 {
+		name = parts[2]
+	}
+	switch resource {
+	case kafkaClustersResource, kafkaTopicsResource, kafkaUsersResource:
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	authorization := r.Header.Get("Authorization")
+	token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if !strings.HasPrefix(authorization, "Bearer ") || token == "" {
+		http.Error(w, "bearer token required", http.StatusUnauthorized)
+		return
+	}
+	verb := "list"
+	if name != "" {
+		verb = "get"
+	}
+	allowed, err := h.authorizer.Authorize(r.Context(), token, authorizationv1.ResourceAttributes{
+		Namespace:   namespace,
+		Verb:        verb,
+		Group:       v1beta1.GroupVersion.Group,
+		Resource:    resource,
+		Subresource: SummarySubresource,
+		Name:        name,
+	})
+	if err != nil {
+		h.log.Error(err, "authorizing request failed", "path", r.URL.Path)
+		http.Error(w, "authorizing request failed", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	var response interface{}
+	if name != "" {
+		response, err = h.get(r.Context(), namespace, resource, name)
+	} else {
+		response, err = h.list(r.Context(), namespace, resource)
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+		h.log.Error(err, "reading resource failed", "path", r.URL.Path)
+		http.Error(w, "reading resource failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.log.Error(err, "writing response failed", "path", r.URL.Path)
+	}
+}
+
+// get returns the summary of the resource
+func (h *handler) get(ctx context.Context, namespace, resource, name string) (interface{}, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+	switch resource {
+	case kafkaClustersResource:
+		cluster := &v1beta1.KafkaCluster{}
+		if err := h.client.Get(ctx, key, cluster); err != nil {
+			return nil, err
+		}
+		return newClusterSummary(cluster), nil
+	case kafkaTopicsResource:
+		topic := &v1alpha1.KafkaTopic{}
+		if err := h.client.Get(ctx, key, topic); err != nil {
+			return nil, err
+		}
+		return newTopicSummary(topic), nil
+	default:
+		user := &v1alpha1.KafkaUser{}
+		if err := h.client.Get(ctx, key, user); err != nil {
+			return nil, err
+		}
+		return newUserSummary(user), nil
+	}
+}
+
+// list returns the summaries of the resources of the namespace as {"items": [...]}
+func (h *handler) list(ctx context.Context, namespace, resource string) (interface{}, error) {
+	switch resource {
+	case kafkaClustersResource:
+		clusters := &v1beta1.KafkaClusterList{}
+		if err := h.client.List(ctx, clusters, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		items := make([]ClusterSummary, 0, len(clusters.Items))
+		for i := range clusters.Items {
+			items = append(items, newClusterSummary(&clusters.Items[i]))
+		}
+		return map[string]interface{}{"items": items}, nil
+	case kafkaTopicsResource:
+		topics := &v1alpha1.KafkaTopicList{}
+		if err := h.client.List(ctx, topics, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		items := make([]TopicSummary, 0, len(topics.Items))
+		for i := range topics.Items {
+			items = append(items, newTopicSummary(&topics.Items[i]))
+		}
+		return map[string]interface{}{"items": items}, nil
+	default:
+		users := &v1alpha1.KafkaUserList{}
+		if err := h.client.List(ctx, users, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		items := make([]UserSummary, 0, len(users.Items))
+		for i := range users.Items {
+			items = append(items, newUserSummary(&users.Items[i]))
+		}
+		return map[string]interface{}{"items": items}, nil
+	}
+}
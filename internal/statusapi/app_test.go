@@ -0,0 +1,166 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+type fakeAuthorizer struct {
+	allowed    bool
+	attributes []authorizationv1.ResourceAttributes
+}
+
+func (a *fakeAuthorizer) Authorize(_ context.Context, _ string, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	a.attributes = append(a.attributes, attributes)
+	return a.allowed, nil
+}
+
+func newTestApp(t *testing.T, authorizer Authorizer) http.Handler {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec:       v1beta1.KafkaClusterSpec{ReadOnlyConfig: "sasl.jaas.config=secret"},
+		Status: v1beta1.KafkaClusterStatus{
+			State: v1beta1.KafkaClusterRunning,
+			BrokersState: map[string]v1beta1.BrokerState{
+				"10": {ConfigurationState: v1beta1.ConfigInSync, Version: "2.8.1"},
+				"2":  {ConfigurationState: v1beta1.ConfigOutOfSync, Version: "2.8.1"},
+			},
+			ListenerStatuses: v1beta1.ListenerStatuses{
+				InternalListeners: map[string]v1beta1.ListenerStatusList{
+					"plaintext": {{Name: "any-broker", Address: "kafka-all-broker.kafka.svc.cluster.local:29092"}},
+				},
+			},
+		},
+	}
+	topic := &v1alpha1.KafkaTopic{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "kafka"},
+		Spec: v1alpha1.KafkaTopicSpec{Name: "orders", Partitions: 6, ReplicationFactor: 3,
+			ClusterRef: v1alpha1.ClusterReference{Name: "kafka"}},
+		Status: v1alpha1.KafkaTopicStatus{State: v1alpha1.TopicStateCreated, UnderReplicatedPartitions: 1},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, topic).Build()
+	return NewApp(logr.Discard(), client, authorizer)
+}
+
+func TestStatusAPIAuthorization(t *testing.T) {
+	authorizer := &fakeAuthorizer{}
+	app := newTestApp(t, authorizer)
+
+	testCases := []struct {
+		testName string
+		method   string
+		path     string
+		token    string
+		expected int
+	}{
+		{testName: "missing token", method: http.MethodGet, path: "/api/v1/namespaces/kafka/kafkatopics/orders", expected: http.StatusUnauthorized},
+		{testName: "forbidden", method: http.MethodGet, path: "/api/v1/namespaces/kafka/kafkatopics/orders", token: "dev", expected: http.StatusForbidden},
+		{testName: "unknown resource", method: http.MethodGet, path: "/api/v1/namespaces/kafka/secrets/orders", token: "dev", expected: http.StatusNotFound},
+		{testName: "write request", method: http.MethodPost, path: "/api/v1/namespaces/kafka/kafkatopics", token: "dev", expected: http.StatusMethodNotAllowed},
+	}
+	for _, test := range testCases {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if test.token != "" {
+			req.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		if rec.Code != test.expected {
+			t.Errorf("%s: expected status code %d, got: %d", test.testName, test.expected, rec.Code)
+		}
+	}
+
+	expected := []authorizationv1.ResourceAttributes{{Namespace: "kafka", Verb: "get", Group: "kafka.banzaicloud.io",
+		Resource: "kafkatopics", Subresource: "summary", Name: "orders"}}
+	if !reflect.DeepEqual(authorizer.attributes, expected) {
+		t.Errorf("expected authorized attributes %+v, got: %+v", expected, authorizer.attributes)
+	}
+}
+
+func TestStatusAPISummaries(t *testing.T) {
+	app := newTestApp(t, &fakeAuthorizer{allowed: true})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer dev")
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/api/v1/namespaces/kafka/kafkatopics/orders")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got: %d", http.StatusOK, rec.Code)
+	}
+	topic := TopicSummary{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &topic); err != nil {
+		t.Fatal(err)
+	}
+	expectedTopic := TopicSummary{Name: "orders", Namespace: "kafka", TopicName: "orders", Cluster: "kafka",
+		Partitions: 6, ReplicationFactor: 3, State: v1alpha1.TopicStateCreated, UnderReplicatedPartitions: 1}
+	if !reflect.DeepEqual(topic, expectedTopic) {
+		t.Errorf("expected topic summary %+v, got: %+v", expectedTopic, topic)
+	}
+
+	rec = get("/api/v1/namespaces/kafka/kafkaclusters")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got: %d", http.StatusOK, rec.Code)
+	}
+	clusters := struct {
+		Items []ClusterSummary `json:"items"`
+	}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &clusters); err != nil {
+		t.Fatal(err)
+	}
+	expectedClusters := []ClusterSummary{{
+		Name:      "kafka",
+		Namespace: "kafka",
+		State:     v1beta1.KafkaClusterRunning,
+		Brokers: []BrokerSummary{
+			{ID: "2", Version: "2.8.1", ConfigurationState: v1beta1.ConfigOutOfSync},
+			{ID: "10", Version: "2.8.1", ConfigurationState: v1beta1.ConfigInSync},
+		},
+		Listeners: map[string][]string{"plaintext": {"kafka-all-broker.kafka.svc.cluster.local:29092"}},
+	}}
+	if !reflect.DeepEqual(clusters.Items, expectedClusters) {
+		t.Errorf("expected cluster summaries %+v, got: %+v", expectedClusters, clusters.Items)
+	}
+
+	if rec := get("/api/v1/namespaces/kafka/kafkausers/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d for missing user, got: %d", http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusapi
+
+import (
+	"sort"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// ClusterSummary is the status of a KafkaCluster without its configuration
+type ClusterSummary struct {
+	Name       string               `json:"name"`
+	Namespace  string               `json:"namespace"`
+	State      v1beta1.ClusterState `json:"state"`
+	AlertCount int                  `json:"alertCount"`
+	Brokers    []BrokerSummary      `json:"brokers,omitempty"`
+	// Listeners are the bootstrap addresses of the listeners by listener name
+	Listeners        map[string][]string           `json:"listeners,omitempty"`
+	ControllerHealth v1beta1.ControllerHealthState `json:"controllerHealth,omitempty"`
+}
+
+// BrokerSummary is the status of a broker of a KafkaCluster
+type BrokerSummary struct {
+	ID                 string                     `json:"id"`
+	Version            string                     `json:"version,omitempty"`
+	ConfigurationState v1beta1.ConfigurationState `json:"configurationState"`
+	CruiseControlState v1beta1.CruiseControlState `json:"cruiseControlState"`
+}
+
+// TopicSummary is the status of a KafkaTopic
+type TopicSummary struct {
+	Name                      string              `json:"name"`
+	Namespace                 string              `json:"namespace"`
+	TopicName                 string              `json:"topicName"`
+	Cluster                   string              `json:"cluster"`
+	Partitions                int32               `json:"partitions"`
+	ReplicationFactor         int32               `json:"replicationFactor"`
+	State                     v1alpha1.TopicState `json:"state"`
+	UnderReplicatedPartitions int32               `json:"underReplicatedPartitions,omitempty"`
+	OfflinePartitions         int32               `json:"offlinePartitions,omitempty"`
+	SizeBytes                 int64               `json:"sizeBytes,omitempty"`
+}
+
+// UserSummary is the status of a KafkaUser
+type UserSummary struct {
+	Name      string             `json:"name"`
+	Namespace string             `json:"namespace"`
+	Cluster   string             `json:"cluster"`
+	State     v1alpha1.UserState `json:"state"`
+	ACLs      []string           `json:"acls,omitempty"`
+}
+
+func newClusterSummary(cluster *v1beta1.KafkaCluster) ClusterSummary {
+	summary := ClusterSummary{
+		Name:       cluster.Name,
+		Namespace:  cluster.Namespace,
+		State:      cluster.Status.State,
+		AlertCount: cluster.Status.AlertCount,
+	}
+	for id, state := range cluster.Status.BrokersState {
+		summary.Brokers = append(summary.Brokers, BrokerSummary{
+			ID:                 id,
+			Version:            state.Version,
+			ConfigurationState: state.ConfigurationState,
+			CruiseControlState: state.GracefulActionState.CruiseControlState,
+		})
+	}
+	sort.Slice(summary.Brokers, func(i, j int) bool {
+		if len(summary.Brokers[i].ID) != len(summary.Brokers[j].ID) {
+			return len(summary.Brokers[i].ID) < len(summary.Brokers[j].ID)
+		}
+		return summary.Brokers[i].ID < summary.Brokers[j].ID
+	})
+
+	statuses := cluster.Status.ListenerStatuses
+	for _, listeners := range []map[string]v1beta1.ListenerStatusList{statuses.InternalListeners, statuses.ExternalListeners} {
+		for name, statusList := range listeners {
+			if summary.Listeners == nil {
+				summary.Listeners = make(map[string][]string)
+			}
+			summary.Listeners[name] = statusList.BootstrapAddresses()
+		}
+	}
+	if cluster.Status.ControllerHealth != nil {
+		summary.ControllerHealth = cluster.Status.ControllerHealth.State
+	}
+	return summary
+}
+
+func newTopicSummary(topic *v1alpha1.KafkaTopic) TopicSummary {
+	return TopicSummary{
+		Name:                      topic.Name,
+		Namespace:                 topic.Namespace,
+		TopicName:                 topic.Spec.Name,
+		Cluster:                   topic.Spec.ClusterRef.Name,
+		Partitions:                topic.Spec.Partitions,
+		ReplicationFactor:         topic.Spec.ReplicationFactor,
+		State:                     topic.Status.State,
+		UnderReplicatedPartitions: topic.Status.UnderReplicatedPartitions,
+		OfflinePartitions:         topic.Status.OfflinePartitions,
+		SizeBytes:                 topic.Status.SizeBytes,
+	}
+}
+
+func newUserSummary(user *v1alpha1.KafkaUser) UserSummary {
+	return UserSummary{
+		Name:      user.Name,
+		Namespace: user.Namespace,
+		Cluster:   user.Spec.ClusterRef.Name,
+		State:     user.Status.State,
+		ACLs:      user.Status.ACLs,
+	}
+}
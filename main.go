@@ -50,6 +50,7 @@ import (
 	banzaicloudv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
 	banzaicloudv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/controllers"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
 	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	"github.com/banzaicloud/koperator/pkg/kafkaclient"
 	"github.com/banzaicloud/koperator/pkg/util"
@@ -88,6 +89,9 @@ func main() {
 		certSigningDisabled               bool
 		certManagerEnabled                bool
 		maxKafkaTopicConcurrentReconciles int
+		faultInjectionEnabled             bool
+		statusAPIAddr                     string
+		ccNotifierAddr                    string
 	)
 
 	flag.StringVar(&namespaces, "namespaces", "", "Comma separated list of namespaces where operator listens for resources")
@@ -102,10 +106,18 @@ func main() {
 	flag.BoolVar(&certManagerEnabled, "cert-manager-enabled", false, "Enable cert-manager integration")
 	flag.BoolVar(&certSigningDisabled, "disable-cert-signing-support", false, "Disable native certificate signing integration")
 	flag.IntVar(&maxKafkaTopicConcurrentReconciles, "max-kafka-topic-concurrent-reconciles", 10, "Define max amount of concurrent KafkaTopic reconciles")
+	flag.BoolVar(&faultInjectionEnabled, "enable-fault-injection", false, "Enable the injection of the faults selected by annotations on KafkaClusters, for end-to-end testing only")
+	flag.StringVar(&statusAPIAddr, "status-api-addr", "", "The address the read-only status API binds to, serving HTTPS with the certificate of tls-cert-dir. The API is disabled if it is empty.")
+	flag.StringVar(&ccNotifierAddr, "cc-notifier-addr", "", "The address the receiver of the anomalies notified by Cruise Control binds to, serving HTTPS with the certificate of tls-cert-dir. The receiver is disabled if it is empty.")
 	flag.Parse()
 
 	ctrl.SetLogger(util.CreateLogger(verboseLogging, developmentLogging))
 
+	if faultInjectionEnabled {
+		setupLog.Info("fault injection is enabled, it must not be used in production")
+		faultinjection.Enable()
+	}
+
 	// adding indexers to KafkaTopics so that the KafkaTopic admission webhooks could work
 	ctx := context.Background()
 	var managerWatchCacheBuilder cache.NewCacheFunc
@@ -148,11 +160,26 @@ func main() {
 		os.Exit(1)
 	}
 
+	if statusAPIAddr != "" {
+		if err = controllers.SetStatusAPIWithManager(mgr, statusAPIAddr, webhookCertDir); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "StatusAPI")
+			os.Exit(1)
+		}
+	}
+
+	if ccNotifierAddr != "" {
+		if err = controllers.SetCruiseControlNotifierWithManager(mgr, ccNotifierAddr, webhookCertDir); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CruiseControlNotifier")
+			os.Exit(1)
+		}
+	}
+
 	kafkaClusterReconciler := &controllers.KafkaClusterReconciler{
 		Client:              mgr.GetClient(),
 		DirectClient:        mgr.GetAPIReader(),
 		Namespaces:          namespaceList,
 		KafkaClientProvider: kafkaclient.NewDefaultProvider(),
+		Recorder:            mgr.GetEventRecorderFor("kafkacluster-controller"),
 	}
 
 	if err = controllers.SetupKafkaClusterWithManager(mgr).Complete(kafkaClusterReconciler); err != nil {
@@ -170,6 +197,26 @@ func main() {
 		os.Exit(1)
 	}
 
+	kafkaMigrationReconciler := &controllers.KafkaMigrationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupKafkaMigrationWithManager(mgr).Complete(kafkaMigrationReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaMigration")
+		os.Exit(1)
+	}
+
+	cruiseControlOperationReconciler := &controllers.CruiseControlOperationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupCruiseControlOperationWithManager(mgr).Complete(cruiseControlOperationReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlOperation")
+		os.Exit(1)
+	}
+
 	// Create a new  kafka user reconciler
 	kafkaUserReconciler := &controllers.KafkaUserReconciler{
 		Client: mgr.GetClient(),
@@ -191,6 +238,166 @@ func main() {
 		os.Exit(1)
 	}
 
+	kafkaClusterCCLoadReconciler := &controllers.CruiseControlLoadReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupCruiseControlLoadWithManager(mgr).Complete(kafkaClusterCCLoadReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlLoad")
+		os.Exit(1)
+	}
+
+	kafkaClusterCCRemediationReconciler := &controllers.CruiseControlRemediationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupCruiseControlRemediationWithManager(mgr).Complete(kafkaClusterCCRemediationReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlRemediation")
+		os.Exit(1)
+	}
+
+	kafkaClusterCCRightsizeReconciler := &controllers.CruiseControlRightsizeReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupCruiseControlRightsizeWithManager(mgr).Complete(kafkaClusterCCRightsizeReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlRightsize")
+		os.Exit(1)
+	}
+
+	kafkaClusterCCTopicRebalanceReconciler := &controllers.CruiseControlTopicRebalanceReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupCruiseControlTopicRebalanceWithManager(mgr).Complete(kafkaClusterCCTopicRebalanceReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlTopicRebalance")
+		os.Exit(1)
+	}
+
+	kafkaClusterCCScheduledRebalanceReconciler := &controllers.CruiseControlScheduledRebalanceReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupCruiseControlScheduledRebalanceWithManager(mgr).Complete(kafkaClusterCCScheduledRebalanceReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlScheduledRebalance")
+		os.Exit(1)
+	}
+
+	kafkaClusterDiskSkewRebalanceReconciler := &controllers.DiskSkewRebalanceReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupDiskSkewRebalanceWithManager(mgr).Complete(kafkaClusterDiskSkewRebalanceReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DiskSkewRebalance")
+		os.Exit(1)
+	}
+
+	kafkaClusterCCSelfHealingReconciler := &controllers.CruiseControlSelfHealingReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupCruiseControlSelfHealingWithManager(mgr).Complete(kafkaClusterCCSelfHealingReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlSelfHealing")
+		os.Exit(1)
+	}
+
+	kafkaClusterOperationRetentionReconciler := &controllers.OperationRetentionReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupOperationRetentionWithManager(mgr).Complete(kafkaClusterOperationRetentionReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OperationRetention")
+		os.Exit(1)
+	}
+
+	kafkaClusterCCOperationsSummaryReconciler := &controllers.CruiseControlOperationsSummaryReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupCruiseControlOperationsSummaryWithManager(mgr).Complete(kafkaClusterCCOperationsSummaryReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlOperationsSummary")
+		os.Exit(1)
+	}
+
+	kafkaClusterInternalTopicHealthReconciler := &controllers.InternalTopicHealthReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupInternalTopicHealthWithManager(mgr).Complete(kafkaClusterInternalTopicHealthReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "InternalTopicHealth")
+		os.Exit(1)
+	}
+
+	kafkaClusterListenerMetricsReconciler := &controllers.ListenerMetricsReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupListenerMetricsWithManager(mgr).Complete(kafkaClusterListenerMetricsReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ListenerMetrics")
+		os.Exit(1)
+	}
+
+	kafkaClusterRequestLatencyReconciler := &controllers.RequestLatencyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupRequestLatencyWithManager(mgr).Complete(kafkaClusterRequestLatencyReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RequestLatency")
+		os.Exit(1)
+	}
+
+	kafkaClusterControllerHealthReconciler := &controllers.ControllerHealthReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupControllerHealthWithManager(mgr).Complete(kafkaClusterControllerHealthReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ControllerHealth")
+		os.Exit(1)
+	}
+
+	kafkaClusterTenantUsageReconciler := &controllers.TenantUsageReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupTenantUsageWithManager(mgr).Complete(kafkaClusterTenantUsageReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TenantUsage")
+		os.Exit(1)
+	}
+
+	kafkaClusterBrokerAutoscalingReconciler := &controllers.BrokerAutoscalingReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupBrokerAutoscalingWithManager(mgr).Complete(kafkaClusterBrokerAutoscalingReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BrokerAutoscaling")
+		os.Exit(1)
+	}
+
+	kafkaClusterBrokerGroupScalingReconciler := &controllers.BrokerGroupScalingReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupBrokerGroupScalingWithManager(mgr).Complete(kafkaClusterBrokerGroupScalingReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BrokerGroupScaling")
+		os.Exit(1)
+	}
+
 	if !webhookDisabled {
 		webhook.SetupServerHandlers(mgr, webhookCertDir)
 	}
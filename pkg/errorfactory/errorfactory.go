@@ -73,6 +73,21 @@ type PerBrokerConfigNotReady struct{ error }
 // LoadBalancerIPNotReady states that the LoadBalancer IP is not yet created
 type LoadBalancerIPNotReady struct{ error }
 
+// MaintenanceWindowClosed states that a disruptive operation is postponed until the next maintenance window
+type MaintenanceWindowClosed struct{ error }
+
+// PreflightChecksFailed states that a disruptive operation was refused as the pre-flight checks failed
+type PreflightChecksFailed struct{ error }
+
+// SmokeTestNotPassed states that a disruptive operation was refused as the smoke test of the previous change did not pass
+type SmokeTestNotPassed struct{ error }
+
+// InvalidBrokerConfig states that the broker configurations were not updated as they contain invalid configs
+type InvalidBrokerConfig struct{ error }
+
+// CanaryBrokersUnhealthy states that the rollout of a Kafka version was held back as the brokers already running it are unhealthy
+type CanaryBrokersUnhealthy struct{ error }
+
 // New creates a new error factory error
 func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 	wrapped := errors.WrapIfWithDetails(err, msg, wrapArgs...)
@@ -115,6 +130,16 @@ func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 		return PerBrokerConfigNotReady{wrapped}
 	case LoadBalancerIPNotReady:
 		return LoadBalancerIPNotReady{wrapped}
+	case MaintenanceWindowClosed:
+		return MaintenanceWindowClosed{wrapped}
+	case PreflightChecksFailed:
+		return PreflightChecksFailed{wrapped}
+	case SmokeTestNotPassed:
+		return SmokeTestNotPassed{wrapped}
+	case InvalidBrokerConfig:
+		return InvalidBrokerConfig{wrapped}
+	case CanaryBrokersUnhealthy:
+		return CanaryBrokersUnhealthy{wrapped}
 	}
 	return wrapped
 }
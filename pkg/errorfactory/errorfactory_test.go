@@ -37,6 +37,11 @@ var errorTypes = []error{
 	FatalReconcileError{},
 	CruiseControlNotReady{},
 	CruiseControlTaskRunning{},
+	MaintenanceWindowClosed{},
+	PreflightChecksFailed{},
+	SmokeTestNotPassed{},
+	InvalidBrokerConfig{},
+	CanaryBrokersUnhealthy{},
 }
 
 func TestNew(t *testing.T) {
@@ -0,0 +1,164 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package faultinjection allows the end-to-end tests to inject faults into the orchestration logic of the operator.
+// Faults are selected with annotations on the KafkaCluster and are only injected when the operator runs with the
+// --enable-fault-injection flag, so they can not be triggered on production deployments by accident.
+package faultinjection
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// Fault is a kind of fault the operator can inject
+type Fault string
+
+const (
+	// KillBrokerDuringRollingUpgrade deletes the pod of the broker given as the value of the annotation right after
+	// another broker pod was restarted by a rolling upgrade. The fault is injected once, the annotation is removed
+	// afterwards.
+	KillBrokerDuringRollingUpgrade Fault = "faultinjection.kafka.banzaicloud.io/kill-broker-during-rolling-upgrade"
+	// DelayCruiseControl delays every request sent to Cruise Control with the duration given as the value of the
+	// annotation, e.g. "30s"
+	DelayCruiseControl Fault = "faultinjection.kafka.banzaicloud.io/delay-cruise-control"
+	// FailPVCProvisioning makes the creation of the PersistentVolumeClaims of the brokers fail if the value of the
+	// annotation is "true"
+	FailPVCProvisioning Fault = "faultinjection.kafka.banzaicloud.io/fail-pvc-provisioning"
+)
+
+// ErrFaultInjected is the error returned by the operations failed by an injected fault
+var ErrFaultInjected = errors.Sentinel("fault injected")
+
+// Hook is called every time a fault is injected, the end-to-end tests can use it to synchronize with the operator
+type Hook func(fault Fault, cluster *v1beta1.KafkaCluster)
+
+var (
+	mu      sync.RWMutex
+	enabled bool
+	hooks   []Hook
+)
+
+// Enable turns on the injection of the faults selected on the KafkaClusters
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+}
+
+// Enabled returns true if faults can be injected
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return enabled
+}
+
+// RegisterHook registers a hook called when a fault is injected
+func RegisterHook(hook Hook) {
+	mu.Lock()
+	defer mu.Unlock()
+	hooks = append(hooks, hook)
+}
+
+// Reset disables the fault injection and removes the registered hooks
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = false
+	hooks = nil
+}
+
+// selected returns the value of the annotation selecting the fault on the cluster
+func selected(cluster *v1beta1.KafkaCluster, fault Fault) (string, bool) {
+	if !Enabled() || cluster == nil {
+		return "", false
+	}
+	value, ok := cluster.GetAnnotations()[string(fault)]
+	return value, ok && value != ""
+}
+
+func injected(log logr.Logger, fault Fault, cluster *v1beta1.KafkaCluster, keysAndValues ...interface{}) {
+	log.Info("injecting fault", append([]interface{}{"fault", fault}, keysAndValues...)...)
+
+	mu.RLock()
+	registered := make([]Hook, len(hooks))
+	copy(registered, hooks)
+	mu.RUnlock()
+
+	for _, hook := range registered {
+		hook(fault, cluster)
+	}
+}
+
+// PVCProvisioningError returns an error if the provisioning of the PersistentVolumeClaims has to fail
+func PVCProvisioningError(log logr.Logger, cluster *v1beta1.KafkaCluster) error {
+	value, ok := selected(cluster, FailPVCProvisioning)
+	if !ok {
+		return nil
+	}
+	if fail, err := strconv.ParseBool(value); err != nil || !fail {
+		return nil
+	}
+	injected(log, FailPVCProvisioning, cluster)
+	return errors.WithDetails(ErrFaultInjected, "fault", FailPVCProvisioning)
+}
+
+// BrokerToKill returns the ID of the broker which has to be killed during the rolling upgrade, except if it is the
+// broker just restarted
+func BrokerToKill(log logr.Logger, cluster *v1beta1.KafkaCluster, restartedBrokerID string) (string, bool) {
+	value, ok := selected(cluster, KillBrokerDuringRollingUpgrade)
+	if !ok || value == restartedBrokerID {
+		return "", false
+	}
+	if _, err := strconv.Atoi(value); err != nil {
+		log.Info("ignoring fault with invalid broker ID", "fault", KillBrokerDuringRollingUpgrade, "brokerId", value)
+		return "", false
+	}
+	injected(log, KillBrokerDuringRollingUpgrade, cluster, "brokerId", value, "restartedBrokerId", restartedBrokerID)
+	return value, true
+}
+
+// CruiseControlScaler returns the given scaler delaying each request if the DelayCruiseControl fault is selected, the
+// delay is cut short when the context of the request is done
+func CruiseControlScaler(log logr.Logger, cluster *v1beta1.KafkaCluster, scaler scale.CruiseControlScaler) scale.CruiseControlScaler {
+	value, ok := selected(cluster, DelayCruiseControl)
+	if !ok {
+		return scaler
+	}
+	delay, err := time.ParseDuration(value)
+	if err != nil || delay <= 0 {
+		log.Info("ignoring fault with invalid delay", "fault", DelayCruiseControl, "delay", value)
+		return scaler
+	}
+	return &delayedCruiseControlScaler{
+		CruiseControlScaler: scaler,
+		delay: func(ctx context.Context) {
+			injected(log, DelayCruiseControl, cluster, "delay", delay)
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+			}
+		},
+	}
+}
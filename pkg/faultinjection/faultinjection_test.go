@@ -0,0 +1,124 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faultinjection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+type fakeScaler struct {
+	scale.CruiseControlScaler
+}
+
+func (f *fakeScaler) IsUp(ctx context.Context) bool {
+	return true
+}
+
+func testCluster(annotations map[string]string) *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", Annotations: annotations}}
+}
+
+func TestFaultsNotInjectedUnlessEnabled(t *testing.T) {
+	Reset()
+	cluster := testCluster(map[string]string{
+		string(FailPVCProvisioning):            "true",
+		string(KillBrokerDuringRollingUpgrade): "1",
+		string(DelayCruiseControl):             "1ms",
+	})
+
+	if err := PVCProvisioningError(logr.Discard(), cluster); err != nil {
+		t.Errorf("expected no error, got: %s", err)
+	}
+	if _, ok := BrokerToKill(logr.Discard(), cluster, "0"); ok {
+		t.Error("expected no broker to kill")
+	}
+	scaler := &fakeScaler{}
+	if CruiseControlScaler(logr.Discard(), cluster, scaler) != scaler {
+		t.Error("expected the scaler to be returned as is")
+	}
+}
+
+func TestCruiseControlDelayCanceled(t *testing.T) {
+	Reset()
+	defer Reset()
+	Enable()
+
+	cluster := testCluster(map[string]string{string(DelayCruiseControl): "1h"})
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	done := make(chan bool)
+	go func() {
+		done <- CruiseControlScaler(logr.Discard(), cluster, &fakeScaler{}).IsUp(ctx)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Error("expected the delay to be cut short by the canceled context")
+	}
+}
+
+func TestFaultsInjected(t *testing.T) {
+	Reset()
+	defer Reset()
+	Enable()
+
+	var injectedFaults []Fault
+	RegisterHook(func(fault Fault, _ *v1beta1.KafkaCluster) {
+		injectedFaults = append(injectedFaults, fault)
+	})
+
+	cluster := testCluster(map[string]string{
+		string(FailPVCProvisioning):            "true",
+		string(KillBrokerDuringRollingUpgrade): "1",
+		string(DelayCruiseControl):             "1ms",
+	})
+
+	if err := PVCProvisioningError(logr.Discard(), cluster); !errors.Is(err, ErrFaultInjected) {
+		t.Errorf("expected injected fault, got: %v", err)
+	}
+	if _, ok := BrokerToKill(logr.Discard(), cluster, "1"); ok {
+		t.Error("expected the restarted broker not to be killed")
+	}
+	if brokerID, ok := BrokerToKill(logr.Discard(), cluster, "0"); !ok || brokerID != "1" {
+		t.Errorf("expected broker 1 to be killed, got: %q", brokerID)
+	}
+	if !CruiseControlScaler(logr.Discard(), cluster, &fakeScaler{}).IsUp(context.TODO()) {
+		t.Error("expected the delayed scaler to pass the request to the wrapped one")
+	}
+
+	expected := []Fault{FailPVCProvisioning, KillBrokerDuringRollingUpgrade, DelayCruiseControl}
+	if len(injectedFaults) != len(expected) {
+		t.Fatalf("expected injected faults: %v, got: %v", expected, injectedFaults)
+	}
+	for i := range expected {
+		if injectedFaults[i] != expected[i] {
+			t.Errorf("expected injected faults: %v, got: %v", expected, injectedFaults)
+		}
+	}
+
+	if err := PVCProvisioningError(logr.Discard(), testCluster(map[string]string{string(FailPVCProvisioning): "false"})); err != nil {
+		t.Errorf("expected no error, got: %s", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faultinjection
+
+import (
+	"context"
+
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// delayedCruiseControlScaler delays every request before passing it to the wrapped scaler
+type delayedCruiseControlScaler struct {
+	scale.CruiseControlScaler
+
+	delay func(ctx context.Context)
+}
+
+func (d *delayedCruiseControlScaler) IsReady(ctx context.Context) bool {
+	d.delay(ctx)
+	return d.CruiseControlScaler.IsReady(ctx)
+}
+
+func (d *delayedCruiseControlScaler) Status(ctx context.Context) scale.CruiseControlStatus {
+	d.delay(ctx)
+	return d.CruiseControlScaler.Status(ctx)
+}
+
+func (d *delayedCruiseControlScaler) GetUserTasks(ctx context.Context, taskIDs ...string) ([]*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.GetUserTasks(ctx, taskIDs...)
+}
+
+func (d *delayedCruiseControlScaler) ListUserTasks(ctx context.Context, filter scale.UserTaskFilter) ([]*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.ListUserTasks(ctx, filter)
+}
+
+func (d *delayedCruiseControlScaler) IsUp(ctx context.Context) bool {
+	d.delay(ctx)
+	return d.CruiseControlScaler.IsUp(ctx)
+}
+
+func (d *delayedCruiseControlScaler) AddBrokers(ctx context.Context, opts scale.OperationOptions, brokerIDs ...string) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.AddBrokers(ctx, opts, brokerIDs...)
+}
+
+func (d *delayedCruiseControlScaler) RemoveBrokers(ctx context.Context, opts scale.OperationOptions, brokerIDs ...string) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.RemoveBrokers(ctx, opts, brokerIDs...)
+}
+
+func (d *delayedCruiseControlScaler) RebalanceDisks(ctx context.Context, opts scale.OperationOptions, brokerIDs ...string) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.RebalanceDisks(ctx, opts, brokerIDs...)
+}
+
+func (d *delayedCruiseControlScaler) IntraBrokerRebalance(ctx context.Context, opts scale.OperationOptions, brokerIDs ...string) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.IntraBrokerRebalance(ctx, opts, brokerIDs...)
+}
+
+func (d *delayedCruiseControlScaler) BrokersWithState(ctx context.Context, states ...scale.KafkaBrokerState) ([]string, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.BrokersWithState(ctx, states...)
+}
+
+func (d *delayedCruiseControlScaler) PartitionReplicasByBroker(ctx context.Context) (map[string]int32, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.PartitionReplicasByBroker(ctx)
+}
+
+func (d *delayedCruiseControlScaler) BrokerWithLeastPartitionReplicas(ctx context.Context, mode scale.BrokerSelectionMode) (string, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.BrokerWithLeastPartitionReplicas(ctx, mode)
+}
+
+func (d *delayedCruiseControlScaler) LogDirsByBroker(ctx context.Context) (map[string]map[scale.LogDirState][]scale.LogDir, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.LogDirsByBroker(ctx)
+}
+
+func (d *delayedCruiseControlScaler) DiskUsageByBroker(ctx context.Context) (map[string]scale.DiskUsage, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.DiskUsageByBroker(ctx)
+}
+
+func (d *delayedCruiseControlScaler) BrokerLoads(ctx context.Context) (map[string]scale.BrokerLoad, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.BrokerLoads(ctx)
+}
+
+func (d *delayedCruiseControlScaler) GoalViolations(ctx context.Context) ([]scale.GoalViolation, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.GoalViolations(ctx)
+}
+
+func (d *delayedCruiseControlScaler) RebalanceWithGoals(ctx context.Context, excludedBrokerIDs []string, topics string, goals ...string) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.RebalanceWithGoals(ctx, excludedBrokerIDs, topics, goals...)
+}
+
+func (d *delayedCruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.DemoteBrokers(ctx, brokerIDs...)
+}
+
+func (d *delayedCruiseControlScaler) UpdateTopicReplicationFactor(ctx context.Context, topic string, replicationFactor int32) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.UpdateTopicReplicationFactor(ctx, topic, replicationFactor)
+}
+
+func (d *delayedCruiseControlScaler) StopExecution(ctx context.Context) error {
+	d.delay(ctx)
+	return d.CruiseControlScaler.StopExecution(ctx)
+}
+
+func (d *delayedCruiseControlScaler) FixOfflineReplicas(ctx context.Context) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.FixOfflineReplicas(ctx)
+}
+
+func (d *delayedCruiseControlScaler) RemoveDisks(ctx context.Context, brokerID string, logDirs []string) (*scale.Result, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.RemoveDisks(ctx, brokerID, logDirs)
+}
+
+func (d *delayedCruiseControlScaler) GetProposals(ctx context.Context, goals ...string) (*scale.ProposalSummary, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.GetProposals(ctx, goals...)
+}
+
+func (d *delayedCruiseControlScaler) PauseSampling(ctx context.Context, reason string) error {
+	d.delay(ctx)
+	return d.CruiseControlScaler.PauseSampling(ctx, reason)
+}
+
+func (d *delayedCruiseControlScaler) ResumeSampling(ctx context.Context, reason string) error {
+	d.delay(ctx)
+	return d.CruiseControlScaler.ResumeSampling(ctx, reason)
+}
+
+func (d *delayedCruiseControlScaler) UpdateExecutorConcurrency(ctx context.Context, concurrency scale.ExecutorConcurrency) error {
+	d.delay(ctx)
+	return d.CruiseControlScaler.UpdateExecutorConcurrency(ctx, concurrency)
+}
+
+func (d *delayedCruiseControlScaler) UpdateSelfHealing(ctx context.Context, enabled, disabled []string) error {
+	d.delay(ctx)
+	return d.CruiseControlScaler.UpdateSelfHealing(ctx, enabled, disabled)
+}
+
+func (d *delayedCruiseControlScaler) Rightsize(ctx context.Context, opts scale.RightsizeOptions) (*scale.RightsizeResult, error) {
+	d.delay(ctx)
+	return d.CruiseControlScaler.Rightsize(ctx, opts)
+}
+
+// WatchTask polls the task through the delayed scaler, so that each poll is delayed
+func (d *delayedCruiseControlScaler) WatchTask(ctx context.Context, taskID string) <-chan scale.TaskUpdate {
+	return scale.PollTask(ctx, d, taskID, scale.DefaultPollConfig)
+}
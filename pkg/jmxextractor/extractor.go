@@ -15,10 +15,16 @@
 package jmxextractor
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"regexp"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/prometheus/common/expfmt"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
@@ -32,6 +38,21 @@ const (
 	headlessServiceJMXTemplate = "http://%s-%d." + kafka.HeadlessServiceTemplate + ".%s.svc.%s:%d"
 	serviceJMXTemplate         = "http://%s-%d.%s.svc.%s:%d"
 	versionRegexGroup          = "version"
+
+	socketServerConnectionCountMetric  = "kafka_server_socket_server_metrics_connection_count"
+	socketServerIncomingByteRateMetric = "kafka_server_socket_server_metrics_incoming_byte_rate"
+	socketServerOutgoingByteRateMetric = "kafka_server_socket_server_metrics_outgoing_byte_rate"
+
+	requestTotalTimeMetric = "kafka_network_requestmetrics_totaltimems"
+	produceRequest         = "Produce"
+	fetchConsumerRequest   = "FetchConsumer"
+	p99Quantile            = "0.99"
+
+	activeControllerCountMetric = "kafka_controller_kafkacontroller_activecontrollercount"
+	raftCurrentLeaderMetric     = "kafka_server_raft_metrics_current_leader"
+	raftCurrentEpochMetric      = "kafka_server_raft_metrics_current_epoch"
+	raftHighWatermarkMetric     = "kafka_server_raft_metrics_high_watermark"
+	raftLogEndOffsetMetric      = "kafka_server_raft_metrics_log_end_offset"
 )
 
 var newJMXExtractor = createNewJMXExtractor
@@ -41,6 +62,39 @@ var jmxMetricRegex = regexp.MustCompile(
 type JMXExtractor interface {
 	ExtractDockerImageAndVersion(brokerId int32, brokerConfig *v1beta1.BrokerConfig,
 		clusterImage string, headlessServiceEnabled bool) (*v1beta1.KafkaVersion, error)
+	ExtractListenerMetrics(brokerId int32, headlessServiceEnabled bool) (map[string]ListenerMetrics, error)
+	ExtractRequestLatencies(brokerId int32, headlessServiceEnabled bool) (*RequestLatencies, error)
+	ExtractControllerMetrics(brokerId int32, headlessServiceEnabled bool) (*ControllerMetrics, error)
+}
+
+// RequestLatencies holds the p99 total time of the produce and the consumer fetch requests of a broker in milliseconds
+type RequestLatencies struct {
+	ProduceP99Ms float64
+	FetchP99Ms   float64
+}
+
+// ControllerMetrics holds whether a broker is the active controller and, when it is a KRaft quorum voter, its view
+// of the metadata quorum
+type ControllerMetrics struct {
+	ActiveController bool
+	Raft             *RaftMetrics
+}
+
+// RaftMetrics holds the raft metrics of a KRaft quorum voter
+type RaftMetrics struct {
+	// CurrentLeader is -1 when the voter does not know the leader of the quorum
+	CurrentLeader int32
+	CurrentEpoch  int64
+	HighWatermark int64
+	LogEndOffset  int64
+}
+
+// ListenerMetrics holds the connections and the byte rates of a listener of a broker summed over its network processors
+type ListenerMetrics struct {
+	Connections float64
+	// IncomingByteRate and OutgoingByteRate are in bytes per second
+	IncomingByteRate float64
+	OutgoingByteRate float64
 }
 
 type jmxExtractor struct {
@@ -73,6 +127,168 @@ func NewMockJMXExtractor() {
 
 func (exp *jmxExtractor) ExtractDockerImageAndVersion(brokerId int32, brokerConfig *v1beta1.BrokerConfig,
 	clusterImage string, headlessServiceEnabled bool) (*v1beta1.KafkaVersion, error) {
+	body, err := exp.scrape(brokerId, headlessServiceEnabled)
+	if err != nil {
+		return nil, err
+	}
+	index := jmxMetricRegex.SubexpIndex(versionRegexGroup)
+	var version string
+	if index > -1 {
+		if metrics := jmxMetricRegex.FindStringSubmatch(string(body)); len(metrics) > index {
+			version = metrics[index]
+		}
+	}
+
+	brokerImage := util.GetBrokerImage(brokerConfig, clusterImage)
+	return &v1beta1.KafkaVersion{Version: version, Image: brokerImage}, nil
+}
+
+// ExtractListenerMetrics returns the connections and the byte rates of the listeners of the broker by listener name
+func (exp *jmxExtractor) ExtractListenerMetrics(brokerId int32, headlessServiceEnabled bool) (map[string]ListenerMetrics, error) {
+	body, err := exp.scrape(brokerId, headlessServiceEnabled)
+	if err != nil {
+		return nil, err
+	}
+	return parseListenerMetrics(bytes.NewReader(body))
+}
+
+// parseListenerMetrics sums the socket server metrics of the network processors of each listener exported by the
+// default JMX exporter rules of the brokers
+func parseListenerMetrics(in io.Reader) (map[string]ListenerMetrics, error) {
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(in)
+	if err != nil {
+		return nil, err
+	}
+
+	listeners := make(map[string]ListenerMetrics)
+	for name, family := range families {
+		switch name {
+		case socketServerConnectionCountMetric, socketServerIncomingByteRateMetric, socketServerOutgoingByteRateMetric:
+		default:
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			var listener string
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "listener" {
+					listener = strings.ToLower(label.GetValue())
+				}
+			}
+			if listener == "" {
+				continue
+			}
+			m := listeners[listener]
+			switch name {
+			case socketServerConnectionCountMetric:
+				m.Connections += metric.GetGauge().GetValue()
+			case socketServerIncomingByteRateMetric:
+				m.IncomingByteRate += metric.GetGauge().GetValue()
+			case socketServerOutgoingByteRateMetric:
+				m.OutgoingByteRate += metric.GetGauge().GetValue()
+			}
+			listeners[listener] = m
+		}
+	}
+	return listeners, nil
+}
+
+// ExtractRequestLatencies returns the p99 produce and consumer fetch request latencies of the broker
+func (exp *jmxExtractor) ExtractRequestLatencies(brokerId int32, headlessServiceEnabled bool) (*RequestLatencies, error) {
+	body, err := exp.scrape(brokerId, headlessServiceEnabled)
+	if err != nil {
+		return nil, err
+	}
+	return parseRequestLatencies(bytes.NewReader(body))
+}
+
+// parseRequestLatencies returns the 99th percentile of the TotalTimeMs request metrics of the produce and the
+// consumer fetch requests exported by the default JMX exporter rules of the brokers
+func parseRequestLatencies(in io.Reader) (*RequestLatencies, error) {
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(in)
+	if err != nil {
+		return nil, err
+	}
+
+	family, ok := families[requestTotalTimeMetric]
+	if !ok {
+		return nil, errors.New("request latencies are not exported by the broker")
+	}
+
+	latencies := &RequestLatencies{}
+	for _, metric := range family.GetMetric() {
+		var request, quantile string
+		for _, label := range metric.GetLabel() {
+			switch label.GetName() {
+			case "request":
+				request = label.GetValue()
+			case "quantile":
+				quantile = label.GetValue()
+			}
+		}
+		if quantile != p99Quantile {
+			continue
+		}
+		switch request {
+		case produceRequest:
+			latencies.ProduceP99Ms = metric.GetGauge().GetValue()
+		case fetchConsumerRequest:
+			latencies.FetchP99Ms = metric.GetGauge().GetValue()
+		}
+	}
+	return latencies, nil
+}
+
+// ExtractControllerMetrics returns the controller and the KRaft quorum metrics of the broker
+func (exp *jmxExtractor) ExtractControllerMetrics(brokerId int32, headlessServiceEnabled bool) (*ControllerMetrics, error) {
+	body, err := exp.scrape(brokerId, headlessServiceEnabled)
+	if err != nil {
+		return nil, err
+	}
+	return parseControllerMetrics(bytes.NewReader(body))
+}
+
+// parseControllerMetrics returns the active controller count and the raft metrics exported by the default JMX
+// exporter rules of the brokers, the raft metrics are only exported by the KRaft quorum voters
+func parseControllerMetrics(in io.Reader) (*ControllerMetrics, error) {
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(in)
+	if err != nil {
+		return nil, err
+	}
+
+	value := func(name string) (float64, bool) {
+		family, ok := families[name]
+		if !ok || len(family.GetMetric()) == 0 {
+			return 0, false
+		}
+		metric := family.GetMetric()[0]
+		if metric.GetGauge() != nil {
+			return metric.GetGauge().GetValue(), true
+		}
+		return metric.GetUntyped().GetValue(), true
+	}
+
+	activeControllerCount, ok := value(activeControllerCountMetric)
+	if !ok {
+		return nil, errors.New("controller metrics are not exported by the broker")
+	}
+	metrics := &ControllerMetrics{ActiveController: activeControllerCount > 0}
+
+	if leader, ok := value(raftCurrentLeaderMetric); ok {
+		epoch, _ := value(raftCurrentEpochMetric)
+		highWatermark, _ := value(raftHighWatermarkMetric)
+		logEndOffset, _ := value(raftLogEndOffsetMetric)
+		metrics.Raft = &RaftMetrics{
+			CurrentLeader: int32(leader),
+			CurrentEpoch:  int64(epoch),
+			HighWatermark: int64(highWatermark),
+			LogEndOffset:  int64(logEndOffset),
+		}
+	}
+	return metrics, nil
+}
+
+// scrape returns the metrics exported by the JMX exporter of the broker
+func (exp *jmxExtractor) scrape(brokerId int32, headlessServiceEnabled bool) ([]byte, error) {
 	var requestURL string
 	if headlessServiceEnabled {
 		requestURL =
@@ -95,18 +311,5 @@ func (exp *jmxExtractor) ExtractDockerImageAndVersion(brokerId int32, brokerConf
 		}
 	}()
 
-	body, err := ioutil.ReadAll(rsp.Body)
-	if err != nil {
-		return nil, err
-	}
-	index := jmxMetricRegex.SubexpIndex(versionRegexGroup)
-	var version string
-	if index > -1 {
-		if metrics := jmxMetricRegex.FindStringSubmatch(string(body)); len(metrics) > index {
-			version = metrics[index]
-		}
-	}
-
-	brokerImage := util.GetBrokerImage(brokerConfig, clusterImage)
-	return &v1beta1.KafkaVersion{Version: version, Image: brokerImage}, nil
+	return ioutil.ReadAll(rsp.Body)
 }
@@ -0,0 +1,112 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jmxextractor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseListenerMetrics(t *testing.T) {
+	metrics := `# HELP kafka_server_socket_server_metrics_connection_count
+# TYPE kafka_server_socket_server_metrics_connection_count gauge
+kafka_server_socket_server_metrics_connection_count{listener="EXTERNAL1",networkProcessor="0",} 3.0
+kafka_server_socket_server_metrics_connection_count{listener="EXTERNAL1",networkProcessor="1",} 4.0
+kafka_server_socket_server_metrics_connection_count{listener="INTERNAL",networkProcessor="2",} 2.0
+# HELP kafka_server_socket_server_metrics_incoming_byte_rate
+# TYPE kafka_server_socket_server_metrics_incoming_byte_rate gauge
+kafka_server_socket_server_metrics_incoming_byte_rate{listener="EXTERNAL1",networkProcessor="0",} 1024.0
+kafka_server_socket_server_metrics_incoming_byte_rate{listener="EXTERNAL1",networkProcessor="1",} 512.0
+# HELP kafka_server_socket_server_metrics_outgoing_byte_rate
+# TYPE kafka_server_socket_server_metrics_outgoing_byte_rate gauge
+kafka_server_socket_server_metrics_outgoing_byte_rate{listener="INTERNAL",networkProcessor="2",} 2048.0
+# HELP kafka_server_app_info_version
+# TYPE kafka_server_app_info_version counter
+kafka_server_app_info_version{broker_id="0",version="2.8.1",} 1.0
+`
+	expected := map[string]ListenerMetrics{
+		"external1": {Connections: 7, IncomingByteRate: 1536},
+		"internal":  {Connections: 2, OutgoingByteRate: 2048},
+	}
+	listeners, err := parseListenerMetrics(strings.NewReader(metrics))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(listeners, expected) {
+		t.Errorf("expected listener metrics: %+v, got: %+v", expected, listeners)
+	}
+}
+
+func TestParseRequestLatencies(t *testing.T) {
+	metrics := `# HELP kafka_network_requestmetrics_totaltimems
+# TYPE kafka_network_requestmetrics_totaltimems gauge
+kafka_network_requestmetrics_totaltimems{request="Produce",quantile="0.50",} 2.0
+kafka_network_requestmetrics_totaltimems{request="Produce",quantile="0.99",} 35.5
+kafka_network_requestmetrics_totaltimems{request="FetchConsumer",quantile="0.99",} 502.0
+kafka_network_requestmetrics_totaltimems{request="FetchFollower",quantile="0.99",} 501.0
+`
+	latencies, err := parseRequestLatencies(strings.NewReader(metrics))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := (&RequestLatencies{ProduceP99Ms: 35.5, FetchP99Ms: 502}); !reflect.DeepEqual(latencies, expected) {
+		t.Errorf("expected request latencies: %+v, got: %+v", expected, latencies)
+	}
+
+	if _, err := parseRequestLatencies(strings.NewReader("")); err == nil {
+		t.Error("expected error when the request latencies are not exported")
+	}
+}
+
+func TestParseControllerMetrics(t *testing.T) {
+	metrics := `# HELP kafka_controller_kafkacontroller_activecontrollercount
+# TYPE kafka_controller_kafkacontroller_activecontrollercount gauge
+kafka_controller_kafkacontroller_activecontrollercount 1.0
+`
+	controller, err := parseControllerMetrics(strings.NewReader(metrics))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := (&ControllerMetrics{ActiveController: true}); !reflect.DeepEqual(controller, expected) {
+		t.Errorf("expected controller metrics: %+v, got: %+v", expected, controller)
+	}
+
+	metrics = `# TYPE kafka_controller_kafkacontroller_activecontrollercount gauge
+kafka_controller_kafkacontroller_activecontrollercount 0.0
+# TYPE kafka_server_raft_metrics_current_leader gauge
+kafka_server_raft_metrics_current_leader 3.0
+# TYPE kafka_server_raft_metrics_current_epoch gauge
+kafka_server_raft_metrics_current_epoch 7.0
+# TYPE kafka_server_raft_metrics_high_watermark gauge
+kafka_server_raft_metrics_high_watermark 1200.0
+# TYPE kafka_server_raft_metrics_log_end_offset gauge
+kafka_server_raft_metrics_log_end_offset 1150.0
+`
+	controller, err = parseControllerMetrics(strings.NewReader(metrics))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := &ControllerMetrics{
+		Raft: &RaftMetrics{CurrentLeader: 3, CurrentEpoch: 7, HighWatermark: 1200, LogEndOffset: 1150},
+	}
+	if !reflect.DeepEqual(controller, expected) {
+		t.Errorf("expected controller metrics: %+v, got: %+v", expected, controller)
+	}
+
+	if _, err := parseControllerMetrics(strings.NewReader("")); err == nil {
+		t.Error("expected error when the controller metrics are not exported")
+	}
+}
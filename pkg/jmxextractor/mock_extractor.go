@@ -22,3 +22,15 @@ func (exp *mockJmxExtractor) ExtractDockerImageAndVersion(brokerId int32, broker
 	clusterImage string, headlessServiceEnabled bool) (*v1beta1.KafkaVersion, error) {
 	return &v1beta1.KafkaVersion{Image: clusterImage, Version: "2.7.0"}, nil
 }
+
+func (exp *mockJmxExtractor) ExtractListenerMetrics(brokerId int32, headlessServiceEnabled bool) (map[string]ListenerMetrics, error) {
+	return map[string]ListenerMetrics{}, nil
+}
+
+func (exp *mockJmxExtractor) ExtractRequestLatencies(brokerId int32, headlessServiceEnabled bool) (*RequestLatencies, error) {
+	return &RequestLatencies{}, nil
+}
+
+func (exp *mockJmxExtractor) ExtractControllerMetrics(brokerId int32, headlessServiceEnabled bool) (*ControllerMetrics, error) {
+	return &ControllerMetrics{}, nil
+}
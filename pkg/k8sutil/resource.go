@@ -28,6 +28,7 @@ import (
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -120,6 +121,14 @@ func Reconcile(log logr.Logger, client runtimeClient.Client, desired runtime.Obj
 				svc.Spec.ClusterIP = current.(*corev1.Service).Spec.ClusterIP
 				svc.Spec.HealthCheckNodePort = current.(*corev1.Service).Spec.HealthCheckNodePort
 				desired = svc
+			case *appsv1.Deployment:
+				deploy := desired.(*appsv1.Deployment)
+				deploy.ResourceVersion = current.(*appsv1.Deployment).ResourceVersion
+				// keep the replicas set by a horizontal pod autoscaler
+				if deploy.Spec.Replicas == nil {
+					deploy.Spec.Replicas = current.(*appsv1.Deployment).Spec.Replicas
+				}
+				desired = deploy
 			}
 
 			if err := client.Update(context.TODO(), desired.(runtimeClient.Object)); err != nil {
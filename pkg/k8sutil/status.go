@@ -17,6 +17,7 @@ package k8sutil
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -121,6 +122,9 @@ func generateBrokerState(brokerIDs []string, cluster *banzaicloudv1beta1.KafkaCl
 			for mountPath, volumeState := range state {
 				brokerState.GracefulActionState.VolumeStates[mountPath] = volumeState
 			}
+		case *banzaicloudv1beta1.MaintenanceState:
+			// a nil state clears the maintenance state of the brokers
+			brokerState.GracefulActionState.MaintenanceState = s.DeepCopy()
 		case banzaicloudv1beta1.KafkaVersion:
 			brokerState.Image = s.Image
 			brokerState.Version = s.Version
@@ -184,6 +188,50 @@ func UpdateCRStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, s
 		cluster.Status.State = s
 	case banzaicloudv1beta1.CruiseControlTopicStatus:
 		cluster.Status.CruiseControlTopicStatus = s
+	case *banzaicloudv1beta1.PreflightChecksStatus:
+		cluster.Status.PreflightChecks = s
+	case *banzaicloudv1beta1.SmokeTestStatus:
+		cluster.Status.SmokeTest = s
+	case *banzaicloudv1beta1.BrokerConfigValidationStatus:
+		cluster.Status.BrokerConfigValidation = s
+	case *banzaicloudv1beta1.CruiseControlLoadStatus:
+		cluster.Status.CruiseControlLoad = s
+	case *banzaicloudv1beta1.GoalViolationRemediationStatus:
+		cluster.Status.GoalViolationRemediation = s
+	case map[string]banzaicloudv1beta1.TenantUsage:
+		cluster.Status.TenantUsage = s
+	case *banzaicloudv1beta1.AdoptionStatus:
+		cluster.Status.Adoption = s
+	case *banzaicloudv1beta1.CapacityHeadroomStatus:
+		cluster.Status.CapacityHeadroom = s
+	case *banzaicloudv1beta1.InternalTopicsStatus:
+		cluster.Status.InternalTopics = s
+	case *banzaicloudv1beta1.ListenerMetricsStatus:
+		cluster.Status.ListenerMetrics = s
+	case *banzaicloudv1beta1.RequestLatencyStatus:
+		cluster.Status.RequestLatency = s
+	case *banzaicloudv1beta1.ControllerHealthStatus:
+		cluster.Status.ControllerHealth = s
+	case []banzaicloudv1beta1.DecommissionedBroker:
+		cluster.Status.DecommissionedBrokers = s
+	case *banzaicloudv1beta1.CruiseControlRightsizeStatus:
+		cluster.Status.CruiseControlRightsize = s
+	case *banzaicloudv1beta1.TopicRebalanceStatus:
+		cluster.Status.TopicRebalance = s
+	case *banzaicloudv1beta1.ScheduledRebalanceStatus:
+		cluster.Status.ScheduledRebalance = s
+	case *banzaicloudv1beta1.DiskSkewRebalanceStatus:
+		cluster.Status.DiskSkewRebalance = s
+	case *banzaicloudv1beta1.CruiseControlOperationsStatus:
+		cluster.Status.CruiseControlOperations = s
+	case *banzaicloudv1beta1.KRaftStatus:
+		cluster.Status.KRaft = s
+	case *banzaicloudv1beta1.VersionUpgradeStatus:
+		cluster.Status.VersionUpgrade = s
+	case *banzaicloudv1beta1.BrokerAutoscalingStatus:
+		cluster.Status.BrokerAutoscaling = s
+	case map[string]banzaicloudv1beta1.ResourceRecommendationStatus:
+		cluster.Status.ResourceRecommendations = s
 	}
 
 	err := c.Status().Update(context.Background(), cluster)
@@ -206,6 +254,50 @@ func UpdateCRStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, s
 			cluster.Status.State = s
 		case banzaicloudv1beta1.CruiseControlTopicStatus:
 			cluster.Status.CruiseControlTopicStatus = s
+		case *banzaicloudv1beta1.PreflightChecksStatus:
+			cluster.Status.PreflightChecks = s
+		case *banzaicloudv1beta1.SmokeTestStatus:
+			cluster.Status.SmokeTest = s
+		case *banzaicloudv1beta1.BrokerConfigValidationStatus:
+			cluster.Status.BrokerConfigValidation = s
+		case *banzaicloudv1beta1.CruiseControlLoadStatus:
+			cluster.Status.CruiseControlLoad = s
+		case *banzaicloudv1beta1.GoalViolationRemediationStatus:
+			cluster.Status.GoalViolationRemediation = s
+		case map[string]banzaicloudv1beta1.TenantUsage:
+			cluster.Status.TenantUsage = s
+		case *banzaicloudv1beta1.AdoptionStatus:
+			cluster.Status.Adoption = s
+		case *banzaicloudv1beta1.CapacityHeadroomStatus:
+			cluster.Status.CapacityHeadroom = s
+		case *banzaicloudv1beta1.InternalTopicsStatus:
+			cluster.Status.InternalTopics = s
+		case *banzaicloudv1beta1.ListenerMetricsStatus:
+			cluster.Status.ListenerMetrics = s
+		case *banzaicloudv1beta1.RequestLatencyStatus:
+			cluster.Status.RequestLatency = s
+		case *banzaicloudv1beta1.ControllerHealthStatus:
+			cluster.Status.ControllerHealth = s
+		case []banzaicloudv1beta1.DecommissionedBroker:
+			cluster.Status.DecommissionedBrokers = s
+		case *banzaicloudv1beta1.CruiseControlRightsizeStatus:
+			cluster.Status.CruiseControlRightsize = s
+		case *banzaicloudv1beta1.TopicRebalanceStatus:
+			cluster.Status.TopicRebalance = s
+		case *banzaicloudv1beta1.ScheduledRebalanceStatus:
+			cluster.Status.ScheduledRebalance = s
+		case *banzaicloudv1beta1.DiskSkewRebalanceStatus:
+			cluster.Status.DiskSkewRebalance = s
+		case *banzaicloudv1beta1.CruiseControlOperationsStatus:
+			cluster.Status.CruiseControlOperations = s
+		case *banzaicloudv1beta1.KRaftStatus:
+			cluster.Status.KRaft = s
+		case *banzaicloudv1beta1.VersionUpgradeStatus:
+			cluster.Status.VersionUpgrade = s
+		case *banzaicloudv1beta1.BrokerAutoscalingStatus:
+			cluster.Status.BrokerAutoscaling = s
+		case map[string]banzaicloudv1beta1.ResourceRecommendationStatus:
+			cluster.Status.ResourceRecommendations = s
 		}
 
 		err = c.Status().Update(context.Background(), cluster)
@@ -261,15 +353,109 @@ func UpdateRollingUpgradeState(c client.Client, cluster *banzaicloudv1beta1.Kafk
 	return nil
 }
 
-func UpdateListenerStatuses(ctx context.Context, c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, intListenerStatuses, extListenerStatuses map[string]banzaicloudv1beta1.ListenerStatusList) error {
-	logger := logr.FromContextOrDiscard(ctx)
+// RecordOperation records the operation in the operation history of the cluster
+func RecordOperation(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, record banzaicloudv1beta1.OperationRecord, logger logr.Logger) error {
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.RecordOperation(record, cluster.Spec.GetOperationHistoryLimit())
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not update operation history")
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
 
+		cluster.Status.RecordOperation(record, cluster.Spec.GetOperationHistoryLimit())
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not update operation history")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("Operation recorded", "operation", record.Type, "outcome", record.Outcome)
+	return nil
+}
+
+// PruneStatus removes the finished entries of the status of the cluster with prune, which returns false if there is
+// nothing to remove
+func PruneStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, prune func(*banzaicloudv1beta1.KafkaClusterStatus) bool, logger logr.Logger) error {
 	typeMeta := cluster.TypeMeta
 
-	cluster.Status.ListenerStatuses = banzaicloudv1beta1.ListenerStatuses{
+	if !prune(&cluster.Status) {
+		return nil
+	}
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, "could not prune status")
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
+
+		if !prune(&cluster.Status) {
+			return nil
+		}
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, "could not prune status")
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	logger.Info("Finished entries pruned from the CR status")
+	return nil
+}
+
+func UpdateListenerStatuses(ctx context.Context, c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, intListenerStatuses, extListenerStatuses map[string]banzaicloudv1beta1.ListenerStatusList) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	listenerStatuses := banzaicloudv1beta1.ListenerStatuses{
 		InternalListeners: intListenerStatuses,
 		ExternalListeners: extListenerStatuses,
 	}
+	// empty maps are omitted from the stored status
+	if len(listenerStatuses.InternalListeners) == 0 {
+		listenerStatuses.InternalListeners = nil
+	}
+	if len(listenerStatuses.ExternalListeners) == 0 {
+		listenerStatuses.ExternalListeners = nil
+	}
+	// skip the update when none of the addresses changed, e.g. the LoadBalancers and node ports are already resolved
+	if reflect.DeepEqual(cluster.Status.ListenerStatuses, listenerStatuses) {
+		return nil
+	}
+
+	typeMeta := cluster.TypeMeta
+
+	cluster.Status.ListenerStatuses = listenerStatuses
 
 	err := c.Status().Update(ctx, cluster)
 	if apierrors.IsNotFound(err) {
@@ -287,10 +473,7 @@ func UpdateListenerStatuses(ctx context.Context, c client.Client, cluster *banza
 			return errors.WrapIf(err, "could not get config for updating listener status")
 		}
 
-		cluster.Status.ListenerStatuses = banzaicloudv1beta1.ListenerStatuses{
-			InternalListeners: intListenerStatuses,
-			ExternalListeners: extListenerStatuses,
-		}
+		cluster.Status.ListenerStatuses = listenerStatuses
 
 		err = c.Status().Update(ctx, cluster)
 		if apierrors.IsNotFound(err) {
@@ -0,0 +1,45 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// ReferencesKafkaCluster returns true if the cluster reference of an object in the given namespace
+// points to the cluster, the namespace of the reference defaults to the namespace of the object
+func ReferencesKafkaCluster(ref v1alpha1.ClusterReference, namespace string, cluster *v1beta1.KafkaCluster) bool {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return ref.Name == cluster.GetName() && namespace == cluster.GetNamespace()
+}
+
+// TenantUsageOfKafkaTopics returns the usage of the cluster by the given KafkaTopics per namespace,
+// the KafkaTopics referencing other clusters and the ones being deleted are not counted
+func TenantUsageOfKafkaTopics(topics []v1alpha1.KafkaTopic, cluster *v1beta1.KafkaCluster) map[string]v1beta1.TenantUsage {
+	usage := make(map[string]v1beta1.TenantUsage)
+	for _, topic := range topics {
+		if IsMarkedForDeletion(topic.ObjectMeta) || !ReferencesKafkaCluster(topic.Spec.ClusterRef, topic.GetNamespace(), cluster) {
+			continue
+		}
+		u := usage[topic.GetNamespace()]
+		u.Topics++
+		u.Partitions += topic.Spec.Partitions
+		usage[topic.GetNamespace()] = u
+	}
+	return usage
+}
@@ -34,7 +34,7 @@ type KafkaClient interface {
 	NumBrokers() int
 	ListTopics() (map[string]sarama.TopicDetail, error)
 	CreateTopic(*CreateTopicOptions) error
-	EnsurePartitionCount(string, int32) (bool, error)
+	EnsurePartitionCount(string, int32, *TopicPlacement) (bool, error)
 	EnsureTopicConfig(string, map[string]*string) error
 	DeleteTopic(string, bool) error
 	GetTopic(string) (*sarama.TopicDetail, error)
@@ -42,6 +42,8 @@ type KafkaClient interface {
 	CreateUserACLs(v1alpha1.KafkaAccessType, v1alpha1.KafkaPatternType, string, string) error
 	ListUserACLs() ([]sarama.ResourceAcls, error)
 	DeleteUserACLs(string) error
+	RevokeUserACLs(string) error
+	RestoreUserACLs(string) error
 
 	Brokers() map[int32]string
 	DescribeCluster() ([]*sarama.Broker, int32, error)
@@ -52,6 +54,18 @@ type KafkaClient interface {
 	// OutOfSyncReplicas returns the list of unique out of sync replica (broker) ids
 	OutOfSyncReplicas() ([]int32, error)
 
+	// TopicHealth returns the replication and the leadership of the partitions of the topic
+	TopicHealth(string) (*TopicHealth, error)
+	// LeadersByBroker returns the number of partitions led by each broker
+	LeadersByBroker() (map[int32]int32, error)
+	// PartitionReplicas returns the replicas and the in-sync replicas of all partitions
+	PartitionReplicas() ([]PartitionReplicas, error)
+
+	// RebalanceBrokers reassigns the partition replicas off the removed brokers and to the added ones
+	RebalanceBrokers(addedBrokers, removedBrokers []int32) (int, error)
+	// PartitionReassignmentsInProgress returns true if the replicas of any partition are being reassigned
+	PartitionReassignmentsInProgress() (bool, error)
+
 	AlterPerBrokerConfig(int32, map[string]*string, bool) error
 	DescribePerBrokerConfig(int32, []string) ([]*sarama.ConfigEntry, error)
 
@@ -59,6 +73,25 @@ type KafkaClient interface {
 	DescribeClusterWideConfig() ([]sarama.ConfigEntry, error)
 
 	TopicMetaToStatus(meta *sarama.TopicMetadata) *v1alpha1.KafkaTopicStatus
+	// TopicPartitionSizes returns the size of the largest replica of each partition of the topic
+	TopicPartitionSizes(string) (map[int32]int64, error)
+	// TopicUsage returns the end offsets of the topic and the consumer groups having committed offsets for it
+	TopicUsage(string) (*TopicUsage, error)
+
+	// TopicOffsets returns the log start and the end offsets of the partitions of the topics
+	TopicOffsets([]string) (map[string]map[int32]PartitionOffsets, error)
+	// ConsumerGroupOffsets returns the offsets the consumer groups committed for the given partitions by group
+	ConsumerGroupOffsets(map[string][]int32) (map[string]map[string]map[int32]int64, error)
+	// CommitConsumerGroupOffsets commits the offsets of the partitions for the consumer group
+	CommitConsumerGroupOffsets(string, map[string]map[int32]int64) error
+	// DefaultProducerByteRates returns the default producer quotas by entity type
+	DefaultProducerByteRates() (map[string]int64, error)
+	// SetDefaultProducerByteRate sets the default producer quota of the entity type, nil removes the quota
+	SetDefaultProducerByteRate(string, *int64) error
+	// EntityProducerByteRates returns the producer quotas set for users, client IDs or their combinations
+	EntityProducerByteRates() ([]v1alpha1.ProducerQuota, error)
+	// SetEntityProducerByteRate sets the producer quota of the quota entity
+	SetEntityProducerByteRate([]v1alpha1.QuotaEntityComponent, int64) error
 
 	Open() error
 	Close() error
@@ -31,6 +31,8 @@ func (k *kafkaClient) DescribeClusterWideConfig() ([]sarama.ConfigEntry, error)
 	return k.admin.DescribeConfig(sarama.ConfigResource{Type: sarama.BrokerResource, Name: "", ConfigNames: []string{}})
 }
 
+// AlterPerBrokerConfig updates the given dynamic configs of the broker incrementally, the configs with nil value are
+// deleted so that the broker falls back to its static or cluster-wide config
 func (k *kafkaClient) AlterPerBrokerConfig(brokerId int32, configChange map[string]*string, validateOnly bool) error {
 	broker := k.GetBroker(brokerId)
 	if broker == nil {
@@ -42,17 +44,34 @@ func (k *kafkaClient) AlterPerBrokerConfig(brokerId int32, configChange map[stri
 	}
 	defer broker.Close()
 
-	_, err = broker.AlterConfigs(&sarama.AlterConfigsRequest{
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(configChange))
+	for name, value := range configChange {
+		if value == nil {
+			entries[name] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationDelete}
+			continue
+		}
+		entries[name] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationSet, Value: value}
+	}
+
+	response, err := broker.IncrementalAlterConfigs(&sarama.IncrementalAlterConfigsRequest{
 		ValidateOnly: validateOnly,
-		Resources: []*sarama.AlterConfigsResource{
+		Resources: []*sarama.IncrementalAlterConfigsResource{
 			{
 				Type:          sarama.BrokerResource,
 				Name:          strconv.Itoa(int(brokerId)),
-				ConfigEntries: configChange,
+				ConfigEntries: entries,
 			},
 		},
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	for _, resource := range response.Resources {
+		if resource.ErrorCode != int16(sarama.ErrNoError) {
+			return errors.WrapIf(sarama.KError(resource.ErrorCode), resource.ErrorMsg)
+		}
+	}
+	return nil
 }
 
 func (k *kafkaClient) DescribePerBrokerConfig(brokerId int32, config []string) ([]*sarama.ConfigEntry, error) {
@@ -16,6 +16,7 @@ package kafkaclient
 
 import (
 	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
 )
 
 func (k *kafkaClient) AllOfflineReplicas() ([]int32, error) {
@@ -91,3 +92,99 @@ func (k *kafkaClient) OutOfSyncReplicas() ([]int32, error) {
 	}
 	return brokerIDs, nil
 }
+
+// TopicHealth describes the replication and the leadership of the partitions of a topic
+type TopicHealth struct {
+	Partitions int32
+	// ReplicationFactor is the smallest number of replicas of the partitions
+	ReplicationFactor         int32
+	UnderReplicatedPartitions int32
+	// OfflinePartitions is the number of partitions without a leader
+	OfflinePartitions int32
+	// NonPreferredLeaders is the number of partitions not led by their first replica
+	NonPreferredLeaders int32
+	LeadersByBroker     map[int32]int32
+}
+
+func (k *kafkaClient) TopicHealth(topic string) (*TopicHealth, error) {
+	meta, err := k.DescribeTopic(topic)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not describe topic", "topic", topic)
+	}
+	return topicHealthFromMetadata(meta), nil
+}
+
+// LeadersByBroker returns the number of partitions of all topics led by each broker
+func (k *kafkaClient) LeadersByBroker() (map[int32]int32, error) {
+	topics, err := k.client.Topics()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not fetch topics")
+	}
+	metas, err := k.admin.DescribeTopics(topics)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not describe topics")
+	}
+	leaders := make(map[int32]int32)
+	for _, meta := range metas {
+		for brokerID, count := range topicHealthFromMetadata(meta).LeadersByBroker {
+			leaders[brokerID] += count
+		}
+	}
+	return leaders, nil
+}
+
+// PartitionReplicas describes the replicas of a partition
+type PartitionReplicas struct {
+	Topic     string
+	Partition int32
+	Replicas  []int32
+	Isr       []int32
+}
+
+// PartitionReplicas returns the replicas and the in-sync replicas of the partitions of all topics
+func (k *kafkaClient) PartitionReplicas() ([]PartitionReplicas, error) {
+	topics, err := k.client.Topics()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not fetch topics")
+	}
+	metas, err := k.admin.DescribeTopics(topics)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not describe topics")
+	}
+	var partitions []PartitionReplicas
+	for _, meta := range metas {
+		for _, partition := range meta.Partitions {
+			partitions = append(partitions, PartitionReplicas{
+				Topic:     meta.Name,
+				Partition: partition.ID,
+				Replicas:  partition.Replicas,
+				Isr:       partition.Isr,
+			})
+		}
+	}
+	return partitions, nil
+}
+
+func topicHealthFromMetadata(meta *sarama.TopicMetadata) *TopicHealth {
+	health := &TopicHealth{
+		Partitions:      int32(len(meta.Partitions)),
+		LeadersByBroker: make(map[int32]int32),
+	}
+	for i, partition := range meta.Partitions {
+		if replicas := int32(len(partition.Replicas)); i == 0 || replicas < health.ReplicationFactor {
+			health.ReplicationFactor = replicas
+		}
+		if len(partition.Isr) < len(partition.Replicas) {
+			health.UnderReplicatedPartitions++
+		}
+		if partition.Leader < 0 {
+			health.OfflinePartitions++
+			continue
+		}
+		health.LeadersByBroker[partition.Leader]++
+		if len(partition.Replicas) > 0 && partition.Replicas[0] != partition.Leader {
+			health.NonPreferredLeaders++
+		}
+	}
+	return health
+}
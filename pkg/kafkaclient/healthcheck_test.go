@@ -0,0 +1,46 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestTopicHealthFromMetadata(t *testing.T) {
+	meta := &sarama.TopicMetadata{
+		Name: "__consumer_offsets",
+		Partitions: []*sarama.PartitionMetadata{
+			{ID: 0, Leader: 0, Replicas: []int32{0, 1, 2}, Isr: []int32{0, 1, 2}},
+			{ID: 1, Leader: 2, Replicas: []int32{1, 2, 0}, Isr: []int32{2, 0}},
+			{ID: 2, Leader: -1, Replicas: []int32{2}, Isr: []int32{}},
+			{ID: 3, Leader: 0, Replicas: []int32{0, 1}, Isr: []int32{0, 1}},
+		},
+	}
+
+	expected := &TopicHealth{
+		Partitions:                4,
+		ReplicationFactor:         1,
+		UnderReplicatedPartitions: 2,
+		OfflinePartitions:         1,
+		NonPreferredLeaders:       1,
+		LeadersByBroker:           map[int32]int32{0: 2, 2: 1},
+	}
+	if health := topicHealthFromMetadata(meta); !reflect.DeepEqual(health, expected) {
+		t.Errorf("expected topic health: %+v, got: %+v", expected, health)
+	}
+}
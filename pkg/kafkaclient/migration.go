@@ -0,0 +1,220 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+)
+
+const producerByteRateQuotaKey = "producer_byte_rate"
+
+// ProducerQuotaEntityTypes are the entity types whose default producer quotas are clamped to pause the producers
+var ProducerQuotaEntityTypes = []string{string(sarama.QuotaEntityUser), string(sarama.QuotaEntityClientID)}
+
+// PartitionOffsets holds the log start and the end offset of a partition
+type PartitionOffsets struct {
+	Start int64
+	End   int64
+}
+
+// TopicOffsets returns the log start and the end offsets of the partitions of the topics
+func (k *kafkaClient) TopicOffsets(topics []string) (map[string]map[int32]PartitionOffsets, error) {
+	offsets := make(map[string]map[int32]PartitionOffsets, len(topics))
+	for _, topic := range topics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch partitions", "topic", topic)
+		}
+		offsets[topic] = make(map[int32]PartitionOffsets, len(partitions))
+		for _, partition := range partitions {
+			start, err := k.client.GetOffset(topic, partition, sarama.OffsetOldest)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch start offset", "topic", topic, "partition", partition)
+			}
+			end, err := k.client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch end offset", "topic", topic, "partition", partition)
+			}
+			offsets[topic][partition] = PartitionOffsets{Start: start, End: end}
+		}
+	}
+	return offsets, nil
+}
+
+// ConsumerGroupOffsets returns the offsets the consumer groups committed for the given partitions by group, the
+// groups not having committed offsets for any of them are left out
+func (k *kafkaClient) ConsumerGroupOffsets(topicPartitions map[string][]int32) (map[string]map[string]map[int32]int64, error) {
+	groups, err := k.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list consumer groups")
+	}
+	offsets := make(map[string]map[string]map[int32]int64)
+	for group, protocolType := range groups {
+		if protocolType != consumerProtocolType {
+			continue
+		}
+		response, err := k.admin.ListConsumerGroupOffsets(group, topicPartitions)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch consumer group offsets", "group", group)
+		}
+		for topic, partitions := range topicPartitions {
+			for _, partition := range partitions {
+				block := response.GetBlock(topic, partition)
+				if block == nil || block.Err != sarama.ErrNoError || block.Offset < 0 {
+					continue
+				}
+				if offsets[group] == nil {
+					offsets[group] = make(map[string]map[int32]int64)
+				}
+				if offsets[group][topic] == nil {
+					offsets[group][topic] = make(map[int32]int64)
+				}
+				offsets[group][topic][partition] = block.Offset
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// CommitConsumerGroupOffsets commits the offsets of the partitions for the consumer group, the group must not have
+// active members
+func (k *kafkaClient) CommitConsumerGroupOffsets(group string, offsets map[string]map[int32]int64) error {
+	coordinator, err := k.client.Coordinator(group)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not find the coordinator of the consumer group", "group", group)
+	}
+	request := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: -1,
+		RetentionTime:           -1,
+	}
+	for topic, partitions := range offsets {
+		for partition, offset := range partitions {
+			request.AddBlock(topic, partition, offset, 0, "")
+		}
+	}
+	response, err := coordinator.CommitOffset(request)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not commit consumer group offsets", "group", group)
+	}
+	for topic, partitions := range response.Errors {
+		for partition, kerr := range partitions {
+			if kerr != sarama.ErrNoError {
+				return errors.WrapIfWithDetails(kerr, "could not commit consumer group offset",
+					"group", group, "topic", topic, "partition", partition)
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultProducerByteRates returns the default producer quotas by entity type, the entity types without a default
+// producer quota are left out
+func (k *kafkaClient) DefaultProducerByteRates() (map[string]int64, error) {
+	rates := make(map[string]int64, len(ProducerQuotaEntityTypes))
+	for _, entityType := range ProducerQuotaEntityTypes {
+		entries, err := k.admin.DescribeClientQuotas([]sarama.QuotaFilterComponent{{
+			EntityType: sarama.QuotaEntityType(entityType),
+			MatchType:  sarama.QuotaMatchDefault,
+		}}, true)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not describe default client quotas", "entityType", entityType)
+		}
+		for _, entry := range entries {
+			if rate, ok := entry.Values[producerByteRateQuotaKey]; ok {
+				rates[entityType] = int64(rate)
+			}
+		}
+	}
+	return rates, nil
+}
+
+// SetDefaultProducerByteRate sets the default producer quota of the entity type, the quota is removed when the rate
+// is nil
+func (k *kafkaClient) SetDefaultProducerByteRate(entityType string, rate *int64) error {
+	op := sarama.ClientQuotasOp{Key: producerByteRateQuotaKey, Remove: true}
+	if rate != nil {
+		op = sarama.ClientQuotasOp{Key: producerByteRateQuotaKey, Value: float64(*rate)}
+	}
+	err := k.admin.AlterClientQuotas([]sarama.QuotaEntityComponent{{
+		EntityType: sarama.QuotaEntityType(entityType),
+		MatchType:  sarama.QuotaMatchDefault,
+	}}, op, false)
+	return errors.WrapIfWithDetails(err, "could not alter default client quota", "entityType", entityType)
+}
+
+// EntityProducerByteRates returns the producer quotas set for users, client IDs or their combinations, i.e. the
+// ones of all quota entities except the default users and the default client IDs
+func (k *kafkaClient) EntityProducerByteRates() ([]v1alpha1.ProducerQuota, error) {
+	entries, err := k.admin.DescribeClientQuotas(nil, false)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not describe client quotas")
+	}
+	var quotas []v1alpha1.ProducerQuota
+	for _, entry := range entries {
+		rate, ok := entry.Values[producerByteRateQuotaKey]
+		if !ok || len(entry.Entity) == 1 && entry.Entity[0].MatchType == sarama.QuotaMatchDefault {
+			continue
+		}
+		quota := v1alpha1.ProducerQuota{
+			Entity:   make([]v1alpha1.QuotaEntityComponent, 0, len(entry.Entity)),
+			ByteRate: int64(rate),
+		}
+		for _, component := range entry.Entity {
+			c := v1alpha1.QuotaEntityComponent{EntityType: string(component.EntityType)}
+			if component.MatchType != sarama.QuotaMatchDefault {
+				name := component.Name
+				c.Name = &name
+			}
+			quota.Entity = append(quota.Entity, c)
+		}
+		quotas = append(quotas, quota)
+	}
+	return quotas, nil
+}
+
+// SetEntityProducerByteRate sets the producer quota of the quota entity
+func (k *kafkaClient) SetEntityProducerByteRate(entity []v1alpha1.QuotaEntityComponent, rate int64) error {
+	components := make([]sarama.QuotaEntityComponent, 0, len(entity))
+	for _, c := range entity {
+		component := sarama.QuotaEntityComponent{EntityType: sarama.QuotaEntityType(c.EntityType), MatchType: sarama.QuotaMatchDefault}
+		if c.Name != nil {
+			component.MatchType = sarama.QuotaMatchExact
+			component.Name = *c.Name
+		}
+		components = append(components, component)
+	}
+	err := k.admin.AlterClientQuotas(components, sarama.ClientQuotasOp{Key: producerByteRateQuotaKey, Value: float64(rate)}, false)
+	return errors.WrapIfWithDetails(err, "could not alter client quota", "entity", quotaEntityString(entity))
+}
+
+// quotaEntityString returns the quota entity the way Kafka prints it, e.g. user=alice,client-id=<default>
+func quotaEntityString(entity []v1alpha1.QuotaEntityComponent) string {
+	components := make([]string, 0, len(entity))
+	for _, c := range entity {
+		name := "<default>"
+		if c.Name != nil {
+			name = *c.Name
+		}
+		components = append(components, c.EntityType+"="+name)
+	}
+	return strings.Join(components, ",")
+}
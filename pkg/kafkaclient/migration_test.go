@@ -0,0 +1,103 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+)
+
+func TestTopicOffsets(t *testing.T) {
+	client := newOpenedMockClient()
+
+	offsets, err := client.TopicOffsets([]string{"test-topic"})
+	if err != nil {
+		t.Error("Expected no error on TopicOffsets, got:", err)
+	}
+	expected := map[string]map[int32]PartitionOffsets{"test-topic": {0: {}}}
+	if !reflect.DeepEqual(offsets, expected) {
+		t.Error("Expected the offsets of the partition of the topic, got:", offsets)
+	}
+
+	client.admin.(*mockClusterAdmin).failOps = true
+	client.client.(*mockClusterAdmin).failOps = true
+	if _, err := client.TopicOffsets([]string{"test-topic"}); err == nil {
+		t.Error("Expected error on TopicOffsets, got nil")
+	}
+}
+
+func TestDefaultProducerByteRates(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if rates, err := client.DefaultProducerByteRates(); err != nil {
+		t.Error("Expected no error on DefaultProducerByteRates, got:", err)
+	} else if len(rates) != 0 {
+		t.Error("Expected no default producer quotas, got:", rates)
+	}
+
+	rate := int64(1)
+	for _, entityType := range ProducerQuotaEntityTypes {
+		if err := client.SetDefaultProducerByteRate(entityType, &rate); err != nil {
+			t.Error("Expected no error on SetDefaultProducerByteRate, got:", err)
+		}
+	}
+	if rates, err := client.DefaultProducerByteRates(); err != nil {
+		t.Error("Expected no error on DefaultProducerByteRates, got:", err)
+	} else if !reflect.DeepEqual(rates, map[string]int64{"user": 1, "client-id": 1}) {
+		t.Error("Expected the clamped default producer quotas, got:", rates)
+	}
+
+	if err := client.SetDefaultProducerByteRate("user", nil); err != nil {
+		t.Error("Expected no error on SetDefaultProducerByteRate, got:", err)
+	}
+	if rates, err := client.DefaultProducerByteRates(); err != nil {
+		t.Error("Expected no error on DefaultProducerByteRates, got:", err)
+	} else if !reflect.DeepEqual(rates, map[string]int64{"client-id": 1}) {
+		t.Error("Expected the default producer quota of the users to be removed, got:", rates)
+	}
+}
+
+func TestEntityProducerByteRates(t *testing.T) {
+	client := newOpenedMockClient()
+
+	rate := int64(1048576)
+	if err := client.SetDefaultProducerByteRate("user", &rate); err != nil {
+		t.Error("Expected no error on SetDefaultProducerByteRate, got:", err)
+	}
+	if quotas, err := client.EntityProducerByteRates(); err != nil {
+		t.Error("Expected no error on EntityProducerByteRates, got:", err)
+	} else if len(quotas) != 0 {
+		t.Error("Expected the default producer quotas to be left out, got:", quotas)
+	}
+
+	alice := "alice"
+	entity := []v1alpha1.QuotaEntityComponent{{EntityType: "user", Name: &alice}, {EntityType: "client-id"}}
+	if err := client.SetEntityProducerByteRate(entity, rate); err != nil {
+		t.Error("Expected no error on SetEntityProducerByteRate, got:", err)
+	}
+	expected := []v1alpha1.ProducerQuota{{Entity: entity, ByteRate: rate}}
+	if quotas, err := client.EntityProducerByteRates(); err != nil {
+		t.Error("Expected no error on EntityProducerByteRates, got:", err)
+	} else if !reflect.DeepEqual(quotas, expected) {
+		t.Error("Expected the producer quota of the user, got:", quotas)
+	}
+
+	client.admin.(*mockClusterAdmin).failOps = true
+	if _, err := client.EntityProducerByteRates(); err == nil {
+		t.Error("Expected error on EntityProducerByteRates, got nil")
+	}
+}
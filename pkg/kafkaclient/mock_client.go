@@ -16,6 +16,8 @@ package kafkaclient
 
 import (
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,6 +33,8 @@ type mockClusterAdmin struct {
 	failOps    bool
 	mockTopics map[string]sarama.TopicDetail
 	mockACLs   map[sarama.Resource]*sarama.ResourceAcls
+	// mockQuotas holds the client quotas by the key of their entity
+	mockQuotas map[string]*sarama.DescribeClientQuotasEntry
 }
 
 func NewMockFromCluster(client client.Client, cluster *v1beta1.KafkaCluster) (KafkaClient, func(), error) {
@@ -42,6 +46,7 @@ func newEmptyMockClusterAdmin(failOps bool) *mockClusterAdmin {
 	return &mockClusterAdmin{
 		mockTopics: make(map[string]sarama.TopicDetail, 0),
 		mockACLs:   make(map[sarama.Resource]*sarama.ResourceAcls, 0),
+		mockQuotas: make(map[string]*sarama.DescribeClientQuotasEntry, 0),
 		failOps:    failOps,
 	}
 }
@@ -170,6 +175,60 @@ func (m *mockClusterAdmin) CreatePartitions(topic string, count int32, assn [][]
 	return nil
 }
 
+func (m *mockClusterAdmin) AlterPartitionReassignments(topic string, assignment [][]int32) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad alter partition reassignments")
+	}
+	detail, ok := m.mockTopics[topic]
+	if !ok {
+		return errors.New("does not exist")
+	}
+	detail.ReplicaAssignment = make(map[int32][]int32, len(assignment))
+	for partition, replicas := range assignment {
+		detail.ReplicaAssignment[int32(partition)] = replicas
+	}
+	m.mockTopics[topic] = detail
+	return nil
+}
+
+func (m *mockClusterAdmin) ListPartitionReassignments(topic string, partitions []int32) (map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus, error) {
+	if m.failOps {
+		return nil, errors.New("bad list partition reassignments")
+	}
+	return map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus{}, nil
+}
+
+func (m *mockClusterAdmin) DescribeLogDirs(brokers []int32) (map[int32][]sarama.DescribeLogDirsResponseDirMetadata, error) {
+	if m.failOps {
+		return nil, errors.New("bad describe log dirs")
+	}
+	return map[int32][]sarama.DescribeLogDirsResponseDirMetadata{}, nil
+}
+
+func (m *mockClusterAdmin) Partitions(topic string) ([]int32, error) {
+	if m.failOps {
+		return nil, errors.New("bad partitions")
+	}
+	return []int32{0}, nil
+}
+
+func (m *mockClusterAdmin) GetOffset(topic string, partitionID int32, time int64) (int64, error) {
+	if m.failOps {
+		return 0, errors.New("bad get offset")
+	}
+	return 0, nil
+}
+
+func (m *mockClusterAdmin) ListConsumerGroups() (map[string]string, error) {
+	if m.failOps {
+		return nil, errors.New("bad list consumer groups")
+	}
+	return map[string]string{}, nil
+}
+
 func (m *mockClusterAdmin) CreateACL(resource sarama.Resource, acl sarama.Acl) error {
 	m.Lock()
 	defer m.Unlock()
@@ -219,10 +278,42 @@ func (m *mockClusterAdmin) DeleteACL(filter sarama.AclFilter, validateOnly bool)
 	case "with-error":
 		return []sarama.MatchingAcl{sarama.MatchingAcl{Err: sarama.ErrUnknown}}, nil
 	default:
-		// for mock it's enough to erase the whole map
-		m.mockACLs = make(map[sarama.Resource]*sarama.ResourceAcls, 0)
-		return []sarama.MatchingAcl{sarama.MatchingAcl{}}, nil
+		var matches []sarama.MatchingAcl
+		for resource, resourceAcls := range m.mockACLs {
+			kept := make([]*sarama.Acl, 0, len(resourceAcls.Acls))
+			for _, acl := range resourceAcls.Acls {
+				if !aclFilterMatches(filter, resource, acl) {
+					kept = append(kept, acl)
+					continue
+				}
+				matches = append(matches, sarama.MatchingAcl{Resource: resource, Acl: *acl})
+			}
+			if len(kept) == 0 {
+				delete(m.mockACLs, resource)
+				continue
+			}
+			resourceAcls.Acls = kept
+		}
+		return matches, nil
+	}
+}
+
+// aclFilterMatches returns whether the ACL of the resource is matched by the filter, the zero values of the filter
+// match any value
+func aclFilterMatches(filter sarama.AclFilter, resource sarama.Resource, acl *sarama.Acl) bool {
+	switch {
+	case filter.ResourceType != sarama.AclResourceUnknown && filter.ResourceType != sarama.AclResourceAny &&
+		filter.ResourceType != resource.ResourceType:
+		return false
+	case filter.ResourceName != nil && *filter.ResourceName != resource.ResourceName:
+		return false
+	case filter.Principal != nil && *filter.Principal != acl.Principal:
+		return false
+	case filter.PermissionType != sarama.AclPermissionUnknown && filter.PermissionType != sarama.AclPermissionAny &&
+		filter.PermissionType != acl.PermissionType:
+		return false
 	}
+	return true
 }
 
 func (m *mockClusterAdmin) DescribeConfig(resource sarama.ConfigResource) ([]sarama.ConfigEntry, error) {
@@ -233,6 +324,102 @@ func (m *mockClusterAdmin) Controller() (*sarama.Broker, error) {
 	return &sarama.Broker{}, nil
 }
 
+func (m *mockClusterAdmin) DescribeClientQuotas(components []sarama.QuotaFilterComponent, strict bool) ([]sarama.DescribeClientQuotasEntry, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad describe client quotas")
+	}
+	keys := make([]string, 0, len(m.mockQuotas))
+	for key, entry := range m.mockQuotas {
+		if quotaEntityMatches(entry.Entity, components, strict) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	entries := make([]sarama.DescribeClientQuotasEntry, 0, len(keys))
+	for _, key := range keys {
+		entry := sarama.DescribeClientQuotasEntry{
+			Entity: m.mockQuotas[key].Entity,
+			Values: make(map[string]float64, len(m.mockQuotas[key].Values)),
+		}
+		for k, v := range m.mockQuotas[key].Values {
+			entry.Values[k] = v
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// quotaEntityMatches returns true if the quota entity matches the filter components the way the brokers match them
+func quotaEntityMatches(entity []sarama.QuotaEntityComponent, components []sarama.QuotaFilterComponent, strict bool) bool {
+	if strict && len(entity) != len(components) {
+		return false
+	}
+	for _, component := range components {
+		matched := false
+		for _, c := range entity {
+			if c.EntityType != component.EntityType {
+				continue
+			}
+			switch component.MatchType {
+			case sarama.QuotaMatchExact:
+				matched = c.MatchType == sarama.QuotaMatchExact && c.Name == component.Match
+			case sarama.QuotaMatchDefault:
+				matched = c.MatchType == sarama.QuotaMatchDefault
+			case sarama.QuotaMatchAny:
+				matched = c.MatchType == sarama.QuotaMatchExact
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// quotaEntityKey returns the key of the quota entity in the mocked quotas
+func quotaEntityKey(entity []sarama.QuotaEntityComponent) string {
+	components := make([]string, 0, len(entity))
+	for _, c := range entity {
+		name := "<default>"
+		if c.MatchType != sarama.QuotaMatchDefault {
+			name = c.Name
+		}
+		components = append(components, string(c.EntityType)+"="+name)
+	}
+	sort.Strings(components)
+	return strings.Join(components, ",")
+}
+
+func (m *mockClusterAdmin) AlterClientQuotas(entity []sarama.QuotaEntityComponent, op sarama.ClientQuotasOp, validateOnly bool) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad alter client quotas")
+	}
+	key := quotaEntityKey(entity)
+	if op.Remove {
+		if entry, ok := m.mockQuotas[key]; ok {
+			delete(entry.Values, op.Key)
+			if len(entry.Values) == 0 {
+				delete(m.mockQuotas, key)
+			}
+		}
+		return nil
+	}
+	if m.mockQuotas[key] == nil {
+		m.mockQuotas[key] = &sarama.DescribeClientQuotasEntry{
+			Entity: append([]sarama.QuotaEntityComponent(nil), entity...),
+			Values: make(map[string]float64),
+		}
+	}
+	m.mockQuotas[key].Values[op.Key] = op.Value
+	return nil
+}
+
 func shallowCopy(original map[string]sarama.TopicDetail) map[string]sarama.TopicDetail {
 	returnMap := make(map[string]sarama.TopicDetail, len(original))
 	for k, v := range original {
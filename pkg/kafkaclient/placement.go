@@ -0,0 +1,149 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// TopicPlacement holds the constraints the replicas of a topic are placed by
+type TopicPlacement struct {
+	MinRacks         int
+	PreferredBrokers []int32
+	ExcludedBrokers  []int32
+}
+
+// replicaAssignment returns the explicit replica assignment of numPartitions partitions starting with firstPartition
+// honoring the placement constraints. The brokers of the cluster are given with their racks.
+// The replicas of a partition are placed on distinct racks until MinRacks is reached and then filled up from the
+// preferred brokers, the starting broker is rotated by partition to spread the leaders over the brokers.
+func replicaAssignment(brokerRacks map[int32]string, placement *TopicPlacement, firstPartition, numPartitions int32,
+	replicationFactor int) ([][]int32, error) {
+	excluded := make(map[int32]bool, len(placement.ExcludedBrokers))
+	for _, id := range placement.ExcludedBrokers {
+		excluded[id] = true
+	}
+	isPreferred := make(map[int32]bool, len(placement.PreferredBrokers))
+	for _, id := range placement.PreferredBrokers {
+		isPreferred[id] = true
+	}
+
+	var preferred, others []int32
+	racks := make(map[string]bool)
+	for id, rack := range brokerRacks {
+		if excluded[id] {
+			continue
+		}
+		racks[rack] = true
+		if isPreferred[id] {
+			preferred = append(preferred, id)
+		} else {
+			others = append(others, id)
+		}
+	}
+	sort.Slice(preferred, func(i, j int) bool { return preferred[i] < preferred[j] })
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+
+	switch {
+	case len(preferred)+len(others) < replicationFactor:
+		return nil, errorfactory.New(errorfactory.InternalError{}, errors.New("not enough brokers"),
+			fmt.Sprintf("only %d brokers are eligible for a replication factor of %d", len(preferred)+len(others), replicationFactor))
+	case placement.MinRacks > replicationFactor:
+		return nil, errorfactory.New(errorfactory.InternalError{}, errors.New("not enough replicas"),
+			fmt.Sprintf("a replication factor of %d can not span %d racks", replicationFactor, placement.MinRacks))
+	case len(racks) < placement.MinRacks:
+		return nil, errorfactory.New(errorfactory.InternalError{}, errors.New("not enough racks"),
+			fmt.Sprintf("only %d racks are eligible for spanning %d racks", len(racks), placement.MinRacks))
+	}
+
+	assignment := make([][]int32, 0, numPartitions)
+	for partition := firstPartition; partition < firstPartition+numPartitions; partition++ {
+		order := append(rotate(preferred, int(partition)), rotate(others, int(partition))...)
+		replicas := make([]int32, 0, replicationFactor)
+		usedRacks := make(map[string]bool)
+		for _, id := range order {
+			if len(usedRacks) >= placement.MinRacks {
+				break
+			}
+			if !usedRacks[brokerRacks[id]] {
+				usedRacks[brokerRacks[id]] = true
+				replicas = append(replicas, id)
+			}
+		}
+		for _, id := range order {
+			if len(replicas) == replicationFactor {
+				break
+			}
+			if !containsBroker(replicas, id) {
+				replicas = append(replicas, id)
+			}
+		}
+		assignment = append(assignment, replicas)
+	}
+	return assignment, nil
+}
+
+// placeReplicas returns the replica assignment of the given partitions by partition index
+func (k *kafkaClient) placeReplicas(placement *TopicPlacement, firstPartition, numPartitions int32,
+	replicationFactor int) (map[int32][]int32, error) {
+	racks, err := k.brokerRacks()
+	if err != nil {
+		return nil, err
+	}
+	assignment, err := replicaAssignment(racks, placement, firstPartition, numPartitions, replicationFactor)
+	if err != nil {
+		return nil, err
+	}
+	replicas := make(map[int32][]int32, len(assignment))
+	for i, partitionReplicas := range assignment {
+		replicas[firstPartition+int32(i)] = partitionReplicas
+	}
+	return replicas, nil
+}
+
+// brokerRacks returns the racks of the brokers of the cluster by broker ID
+func (k *kafkaClient) brokerRacks() (map[int32]string, error) {
+	brokers, _, err := k.admin.DescribeCluster()
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing cluster")
+	}
+	racks := make(map[int32]string, len(brokers))
+	for _, broker := range brokers {
+		racks[broker.ID()] = broker.Rack()
+	}
+	return racks, nil
+}
+
+func rotate(ids []int32, n int) []int32 {
+	rotated := make([]int32, 0, len(ids))
+	if len(ids) == 0 {
+		return rotated
+	}
+	n %= len(ids)
+	return append(append(rotated, ids[n:]...), ids[:n]...)
+}
+
+func containsBroker(ids []int32, id int32) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
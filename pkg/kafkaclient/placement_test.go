@@ -0,0 +1,103 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplicaAssignment(t *testing.T) {
+	brokerRacks := map[int32]string{0: "a", 1: "a", 2: "b", 3: "b", 4: "c"}
+
+	testCases := []struct {
+		testName          string
+		placement         TopicPlacement
+		firstPartition    int32
+		numPartitions     int32
+		replicationFactor int
+		expected          [][]int32
+		expectedErr       bool
+	}{
+		{
+			testName:          "replicas spanning racks",
+			placement:         TopicPlacement{MinRacks: 2},
+			numPartitions:     3,
+			replicationFactor: 2,
+			expected:          [][]int32{{0, 2}, {1, 2}, {2, 4}},
+		},
+		{
+			testName:          "preferred brokers first",
+			placement:         TopicPlacement{PreferredBrokers: []int32{3, 4}},
+			numPartitions:     2,
+			replicationFactor: 3,
+			expected:          [][]int32{{3, 4, 0}, {4, 3, 1}},
+		},
+		{
+			testName:          "preferred brokers across racks",
+			placement:         TopicPlacement{MinRacks: 2, PreferredBrokers: []int32{0, 1}},
+			numPartitions:     1,
+			replicationFactor: 2,
+			expected:          [][]int32{{0, 2}},
+		},
+		{
+			testName:          "excluded brokers",
+			placement:         TopicPlacement{MinRacks: 3, ExcludedBrokers: []int32{0, 2}},
+			firstPartition:    4,
+			numPartitions:     2,
+			replicationFactor: 3,
+			expected:          [][]int32{{3, 4, 1}, {4, 1, 3}},
+		},
+		{
+			testName:          "not enough brokers",
+			placement:         TopicPlacement{ExcludedBrokers: []int32{0, 1, 2}},
+			numPartitions:     1,
+			replicationFactor: 3,
+			expectedErr:       true,
+		},
+		{
+			testName:          "not enough racks",
+			placement:         TopicPlacement{MinRacks: 3, ExcludedBrokers: []int32{4}},
+			numPartitions:     1,
+			replicationFactor: 3,
+			expectedErr:       true,
+		},
+		{
+			testName:          "more racks than replicas",
+			placement:         TopicPlacement{MinRacks: 3},
+			numPartitions:     1,
+			replicationFactor: 2,
+			expectedErr:       true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			assignment, err := replicaAssignment(brokerRacks, &test.placement, test.firstPartition, test.numPartitions, test.replicationFactor)
+			if test.expectedErr {
+				if err == nil {
+					t.Error("Expected error, got:", assignment)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Expected no error, got:", err)
+			}
+			if !reflect.DeepEqual(assignment, test.expected) {
+				t.Errorf("Expected assignment %v, got %v", test.expected, assignment)
+			}
+		})
+	}
+}
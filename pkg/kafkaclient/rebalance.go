@@ -0,0 +1,259 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// RebalanceBrokers reassigns the partition replicas so that the removed brokers hold none of them and the added
+// brokers hold their share, it returns the number of the reassigned partitions. The reassignments are computed by
+// brokerRebalance and executed by the brokers asynchronously.
+func (k *kafkaClient) RebalanceBrokers(addedBrokers, removedBrokers []int32) (int, error) {
+	racks, err := k.brokerRacks()
+	if err != nil {
+		return 0, err
+	}
+	assignments, err := k.replicaAssignments()
+	if err != nil {
+		return 0, err
+	}
+
+	reassignments, err := brokerRebalance(racks, assignments, addedBrokers, removedBrokers)
+	if err != nil {
+		return 0, err
+	}
+
+	topics := make([]string, 0, len(reassignments))
+	for topic := range reassignments {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	reassigned := 0
+	for _, topic := range topics {
+		// the partitions left out of the request would have their ongoing reassignments cancelled, so all the
+		// partitions of the topic are sent with their current replicas when not reassigned
+		partitions := make([][]int32, len(assignments[topic]))
+		for partition, replicas := range assignments[topic] {
+			if int(partition) >= len(partitions) {
+				return reassigned, errorfactory.New(errorfactory.InternalError{}, errors.New("missing partition"),
+					"partitions of the topic are not contiguous", "topic", topic)
+			}
+			partitions[partition] = replicas
+		}
+		for partition, replicas := range reassignments[topic] {
+			partitions[partition] = replicas
+		}
+		if err := k.admin.AlterPartitionReassignments(topic, partitions); err != nil {
+			return reassigned, errorfactory.New(errorfactory.BrokersRequestError{}, err,
+				"reassigning partitions failed", "topic", topic)
+		}
+		reassigned += len(reassignments[topic])
+	}
+	return reassigned, nil
+}
+
+// PartitionReassignmentsInProgress returns true if the replicas of any partition are being reassigned
+func (k *kafkaClient) PartitionReassignmentsInProgress() (bool, error) {
+	assignments, err := k.replicaAssignments()
+	if err != nil {
+		return false, err
+	}
+	for topic, partitions := range assignments {
+		ids := make([]int32, 0, len(partitions))
+		for partition := range partitions {
+			ids = append(ids, partition)
+		}
+		status, err := k.admin.ListPartitionReassignments(topic, ids)
+		if err != nil {
+			return false, errorfactory.New(errorfactory.BrokersRequestError{}, err,
+				"listing partition reassignments failed", "topic", topic)
+		}
+		if len(status[topic]) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// replicaAssignments returns the replicas of the partitions of the topics by topic and partition
+func (k *kafkaClient) replicaAssignments() (map[string]map[int32][]int32, error) {
+	topics, err := k.admin.ListTopics()
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "listing topics failed")
+	}
+	assignments := make(map[string]map[int32][]int32, len(topics))
+	for name, topic := range topics {
+		assignments[name] = topic.ReplicaAssignment
+	}
+	return assignments, nil
+}
+
+// brokerRebalance returns the new replicas of the partitions to reassign by topic and partition. The brokers of the
+// cluster are given with their racks. The replicas of the removed brokers are moved to the eligible brokers holding
+// the fewest replicas, then replicas are moved one at a time from the brokers holding the most replicas to the added
+// brokers until they hold their share. A replica is moved to a broker of a rack not used by the other replicas of the
+// partition whenever possible and the position of the moved replica is kept, so the preferred leaders follow them.
+func brokerRebalance(brokerRacks map[int32]string, assignments map[string]map[int32][]int32,
+	addedBrokers, removedBrokers []int32) (map[string]map[int32][]int32, error) {
+	removed := make(map[int32]bool, len(removedBrokers))
+	for _, id := range removedBrokers {
+		removed[id] = true
+	}
+	replicaCounts := make(map[int32]int)
+	for id := range brokerRacks {
+		if !removed[id] {
+			replicaCounts[id] = 0
+		}
+	}
+	for _, id := range addedBrokers {
+		if _, ok := replicaCounts[id]; !ok {
+			return nil, errorfactory.New(errorfactory.BrokersNotReady{}, errors.New("broker not available"),
+				fmt.Sprintf("broker %d to add is not part of the cluster yet", id))
+		}
+	}
+
+	type partitionReplicas struct {
+		topic     string
+		partition int32
+		replicas  []int32
+	}
+	var partitions []*partitionReplicas
+	for topic, topicPartitions := range assignments {
+		for partition, replicas := range topicPartitions {
+			partitions = append(partitions, &partitionReplicas{
+				topic:     topic,
+				partition: partition,
+				replicas:  append([]int32(nil), replicas...),
+			})
+			for _, id := range replicas {
+				if _, ok := replicaCounts[id]; ok {
+					replicaCounts[id]++
+				}
+			}
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].topic != partitions[j].topic {
+			return partitions[i].topic < partitions[j].topic
+		}
+		return partitions[i].partition < partitions[j].partition
+	})
+
+	// rackConflict returns true if the rack of the broker is used by the replicas of the partition other than the
+	// one at the given position
+	rackConflict := func(replicas []int32, position int, id int32) bool {
+		for i, replica := range replicas {
+			if i != position && brokerRacks[replica] == brokerRacks[id] {
+				return true
+			}
+		}
+		return false
+	}
+
+	reassignments := make(map[string]map[int32][]int32)
+	reassign := func(p *partitionReplicas, position int, id int32) {
+		if _, ok := replicaCounts[p.replicas[position]]; ok {
+			replicaCounts[p.replicas[position]]--
+		}
+		p.replicas[position] = id
+		replicaCounts[id]++
+		if reassignments[p.topic] == nil {
+			reassignments[p.topic] = make(map[int32][]int32)
+		}
+		reassignments[p.topic][p.partition] = p.replicas
+	}
+
+	for _, p := range partitions {
+		for position, replica := range p.replicas {
+			if !removed[replica] {
+				continue
+			}
+			target, found := int32(0), false
+			for id, count := range replicaCounts {
+				if containsBroker(p.replicas, id) {
+					continue
+				}
+				if !found || lessLoaded(id, count, rackConflict(p.replicas, position, id),
+					target, replicaCounts[target], rackConflict(p.replicas, position, target)) {
+					target, found = id, true
+				}
+			}
+			if !found {
+				return nil, errorfactory.New(errorfactory.InternalError{}, errors.New("not enough brokers"),
+					fmt.Sprintf("no broker left for the replica of partition %d of topic %s on broker %d",
+						p.partition, p.topic, replica))
+			}
+			reassign(p, position, target)
+		}
+	}
+
+	total := 0
+	for _, count := range replicaCounts {
+		total += count
+	}
+	share := 0
+	if len(replicaCounts) > 0 {
+		share = total / len(replicaCounts)
+	}
+	added := append([]int32(nil), addedBrokers...)
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+	for _, id := range added {
+		for replicaCounts[id] < share {
+			var source *partitionReplicas
+			sourcePosition := -1
+			for _, p := range partitions {
+				if containsBroker(p.replicas, id) {
+					continue
+				}
+				for position, replica := range p.replicas {
+					count, ok := replicaCounts[replica]
+					// moving the replica must not leave its broker with fewer replicas than the added one
+					if !ok || count <= replicaCounts[id]+1 {
+						continue
+					}
+					if brokerRacks[replica] != brokerRacks[id] && rackConflict(p.replicas, position, id) {
+						continue
+					}
+					if source == nil || count > replicaCounts[source.replicas[sourcePosition]] {
+						source, sourcePosition = p, position
+					}
+				}
+			}
+			if source == nil {
+				break
+			}
+			reassign(source, sourcePosition, id)
+		}
+	}
+	return reassignments, nil
+}
+
+// lessLoaded returns true if the first broker is a better target for a replica than the second one: brokers of racks
+// not used by the partition come first, then the ones with fewer replicas, then the ones with lower IDs
+func lessLoaded(id int32, count int, conflict bool, otherID int32, otherCount int, otherConflict bool) bool {
+	if conflict != otherConflict {
+		return !conflict
+	}
+	if count != otherCount {
+		return count < otherCount
+	}
+	return id < otherID
+}
@@ -0,0 +1,120 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBrokerRebalance(t *testing.T) {
+	testCases := []struct {
+		testName       string
+		brokerRacks    map[int32]string
+		assignments    map[string]map[int32][]int32
+		addedBrokers   []int32
+		removedBrokers []int32
+		expected       map[string]map[int32][]int32
+		expectedErr    bool
+	}{
+		{
+			testName:    "replicas moved off the removed broker across racks",
+			brokerRacks: map[int32]string{0: "a", 1: "b", 2: "a", 3: "b"},
+			assignments: map[string]map[int32][]int32{
+				"topic": {0: {0, 3}, 1: {3, 1}, 2: {1, 2}},
+			},
+			removedBrokers: []int32{3},
+			expected: map[string]map[int32][]int32{
+				"topic": {0: {0, 1}, 1: {0, 1}},
+			},
+		},
+		{
+			testName:    "added broker takes its share from the most loaded brokers",
+			brokerRacks: map[int32]string{0: "a", 1: "a", 2: "a"},
+			assignments: map[string]map[int32][]int32{
+				"topic": {0: {0, 1}, 1: {1, 0}, 2: {0, 1}},
+			},
+			addedBrokers: []int32{2},
+			expected: map[string]map[int32][]int32{
+				"topic": {0: {2, 1}, 1: {2, 0}},
+			},
+		},
+		{
+			testName:    "replicas not moved to a rack used by the partition",
+			brokerRacks: map[int32]string{0: "a", 1: "b", 2: "b"},
+			assignments: map[string]map[int32][]int32{
+				"topic": {0: {0, 1}, 1: {1, 0}},
+			},
+			addedBrokers: []int32{2},
+			expected: map[string]map[int32][]int32{
+				"topic": {0: {0, 2}},
+			},
+		},
+		{
+			testName:    "balanced cluster",
+			brokerRacks: map[int32]string{0: "a", 1: "b"},
+			assignments: map[string]map[int32][]int32{
+				"topic": {0: {0, 1}, 1: {1, 0}},
+			},
+			expected: map[string]map[int32][]int32{},
+		},
+		{
+			testName:     "added broker not in the cluster",
+			brokerRacks:  map[int32]string{0: "a", 1: "b"},
+			assignments:  map[string]map[int32][]int32{"topic": {0: {0, 1}}},
+			addedBrokers: []int32{2},
+			expectedErr:  true,
+		},
+		{
+			testName:       "not enough brokers for the replication factor",
+			brokerRacks:    map[int32]string{0: "a", 1: "b"},
+			assignments:    map[string]map[int32][]int32{"topic": {0: {0, 1}}},
+			removedBrokers: []int32{1},
+			expectedErr:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		actual, err := brokerRebalance(test.brokerRacks, test.assignments, test.addedBrokers, test.removedBrokers)
+		if test.expectedErr {
+			if err == nil {
+				t.Errorf("test case %q: expected error", test.testName)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test case %q: unexpected error: %s", test.testName, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("test case %q: expected %v, got: %v", test.testName, test.expected, actual)
+		}
+	}
+}
+
+func TestPartitionReassignmentsInProgress(t *testing.T) {
+	client := newOpenedMockClient()
+	if err := client.CreateTopic(&CreateTopicOptions{Name: "test-topic", Partitions: 1, ReplicationFactor: 1}); err != nil {
+		t.Fatal("failed to create test topic:", err)
+	}
+
+	inProgress, err := client.PartitionReassignmentsInProgress()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if inProgress {
+		t.Error("expected no partition reassignments in progress")
+	}
+}
@@ -17,9 +17,11 @@ package kafkaclient
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
 )
 
@@ -29,6 +31,7 @@ type CreateTopicOptions struct {
 	Partitions        int32
 	ReplicationFactor int16
 	Config            map[string]*string
+	Placement         *TopicPlacement
 }
 
 // ListTopics is used primarily for checking the existence of topics
@@ -70,11 +73,22 @@ func (k *kafkaClient) DescribeTopic(topic string) (meta *sarama.TopicMetadata, e
 
 // CreateTopic creates a topic with the given options
 func (k *kafkaClient) CreateTopic(opts *CreateTopicOptions) (err error) {
-	err = k.admin.CreateTopic(opts.Name, &sarama.TopicDetail{
+	detail := &sarama.TopicDetail{
 		NumPartitions:     opts.Partitions,
 		ReplicationFactor: opts.ReplicationFactor,
 		ConfigEntries:     opts.Config,
-	}, false)
+	}
+	if opts.Placement != nil {
+		// the partition count and the replication factor must not be set along with an explicit assignment
+		var assignment map[int32][]int32
+		if assignment, err = k.placeReplicas(opts.Placement, 0, opts.Partitions, int(opts.ReplicationFactor)); err != nil {
+			return errorfactory.New(errorfactory.CreateTopicError{}, err, "failed to place the replicas of the topic")
+		}
+		detail.NumPartitions = -1
+		detail.ReplicationFactor = -1
+		detail.ReplicaAssignment = assignment
+	}
+	err = k.admin.CreateTopic(opts.Name, detail, false)
 	if err != nil {
 		err = errorfactory.New(errorfactory.CreateTopicError{}, err, "failed to create topic")
 	}
@@ -108,8 +122,9 @@ func (k *kafkaClient) DeleteTopic(topicName string, wait bool) error {
 }
 
 // EnsurePartitionCount will check if a partition increase is requested and apply
-// the changed.
-func (k *kafkaClient) EnsurePartitionCount(topic string, desired int32) (changed bool, err error) {
+// the changed. The replicas of the new partitions are placed by the given placement
+// constraints when it is not nil.
+func (k *kafkaClient) EnsurePartitionCount(topic string, desired int32, placement *TopicPlacement) (changed bool, err error) {
 	changed = false
 	meta, err := k.admin.DescribeTopics([]string{topic})
 
@@ -123,9 +138,17 @@ func (k *kafkaClient) EnsurePartitionCount(topic string, desired int32) (changed
 		return
 	}
 
-	if desired != int32(len(meta[0].Partitions)) {
-		// TODO (tinyzimmer): maybe let the user specify partition assignments
+	if current := int32(len(meta[0].Partitions)); desired != current {
 		assn := make([][]int32, 0)
+		if placement != nil && desired > current && current > 0 {
+			var assignment map[int32][]int32
+			if assignment, err = k.placeReplicas(placement, current, desired-current, len(meta[0].Partitions[0].Replicas)); err != nil {
+				return
+			}
+			for partition := current; partition < desired; partition++ {
+				assn = append(assn, assignment[partition])
+			}
+		}
 		changed = true
 		err = k.admin.CreatePartitions(topic, desired, assn, false)
 	}
@@ -136,3 +159,70 @@ func (k *kafkaClient) EnsurePartitionCount(topic string, desired int32) (changed
 func (k *kafkaClient) EnsureTopicConfig(topic string, desiredConf map[string]*string) error {
 	return k.admin.AlterConfig(sarama.TopicResource, topic, desiredConf, false)
 }
+
+// TopicMetaToStatus returns the status of the partitions of the topic described by the metadata, the state and the
+// sizes of the partitions are left for the caller to fill
+func (k *kafkaClient) TopicMetaToStatus(meta *sarama.TopicMetadata) *v1alpha1.KafkaTopicStatus {
+	status := &v1alpha1.KafkaTopicStatus{
+		Partitions: make([]v1alpha1.TopicPartitionStatus, 0, len(meta.Partitions)),
+	}
+	for _, partition := range meta.Partitions {
+		status.Partitions = append(status.Partitions, v1alpha1.TopicPartitionStatus{
+			Partition:       partition.ID,
+			Leader:          partition.Leader,
+			Replicas:        partition.Replicas,
+			InSyncReplicas:  partition.Isr,
+			OfflineReplicas: partition.OfflineReplicas,
+		})
+		if len(partition.Isr) < len(partition.Replicas) {
+			status.UnderReplicatedPartitions++
+		}
+		if partition.Leader < 0 {
+			status.OfflinePartitions++
+		}
+	}
+	sort.Slice(status.Partitions, func(i, j int) bool {
+		return status.Partitions[i].Partition < status.Partitions[j].Partition
+	})
+	return status
+}
+
+// TopicPartitionSizes returns the size of the largest replica of each partition of the topic, reported by the
+// log directories of the brokers
+func (k *kafkaClient) TopicPartitionSizes(topic string) (map[int32]int64, error) {
+	brokerIDs := make([]int32, 0, len(k.brokers))
+	for _, broker := range k.brokers {
+		brokerIDs = append(brokerIDs, broker.ID())
+	}
+	logDirsByBroker, err := k.admin.DescribeLogDirs(brokerIDs)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing log dirs")
+	}
+	return partitionSizesFromLogDirs(topic, logDirsByBroker), nil
+}
+
+func partitionSizesFromLogDirs(topic string, logDirsByBroker map[int32][]sarama.DescribeLogDirsResponseDirMetadata) map[int32]int64 {
+	sizes := make(map[int32]int64)
+	for _, logDirs := range logDirsByBroker {
+		for _, logDir := range logDirs {
+			if logDir.ErrorCode != sarama.ErrNoError {
+				continue
+			}
+			for _, logDirTopic := range logDir.Topics {
+				if logDirTopic.Topic != topic {
+					continue
+				}
+				for _, partition := range logDirTopic.Partitions {
+					// future replicas being moved between log dirs are not counted
+					if partition.IsTemporary {
+						continue
+					}
+					if partition.Size > sizes[partition.PartitionID] {
+						sizes[partition.PartitionID] = partition.Size
+					}
+				}
+			}
+		}
+	}
+	return sizes
+}
@@ -134,20 +134,97 @@ func TestEnsureTopicConfig(t *testing.T) {
 
 func TestEnsurePartitionCount(t *testing.T) {
 	client := newOpenedMockClient()
-	if changed, err := client.EnsurePartitionCount("test-topic", 1); err != nil {
+	if changed, err := client.EnsurePartitionCount("test-topic", 1, nil); err != nil {
 		t.Error("Expected no error, got:", err)
 	} else if changed {
 		t.Error("Expected no changed to be false, got true")
 	}
-	if changed, _ := client.EnsurePartitionCount("test-topic", 2); !changed {
+	if changed, _ := client.EnsurePartitionCount("test-topic", 2, nil); !changed {
 		t.Error("Expected to attempt partition increase, got no change")
 	}
-	if _, err := client.EnsurePartitionCount("not-exists", 9000); err == nil {
+	if _, err := client.EnsurePartitionCount("not-exists", 9000, nil); err == nil {
 		t.Error("Expected error for non-existant topic, got nil")
 	}
 
 	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
-	if _, err := client.EnsurePartitionCount("test-topic", 1); err == nil {
+	if _, err := client.EnsurePartitionCount("test-topic", 1, nil); err == nil {
 		t.Error("Expected error, got nil")
 	}
 }
+
+func TestTopicMetaToStatus(t *testing.T) {
+	client := newOpenedMockClient()
+
+	meta := &sarama.TopicMetadata{
+		Name: "test-topic",
+		Partitions: []*sarama.PartitionMetadata{
+			{ID: 2, Leader: -1, Replicas: []int32{2, 0}, OfflineReplicas: []int32{2, 0}},
+			{ID: 0, Leader: 0, Replicas: []int32{0, 1}, Isr: []int32{0, 1}},
+			{ID: 1, Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1}, OfflineReplicas: []int32{2}},
+		},
+	}
+	status := client.TopicMetaToStatus(meta)
+
+	if status.UnderReplicatedPartitions != 2 {
+		t.Error("Expected 2 under-replicated partitions, got:", status.UnderReplicatedPartitions)
+	}
+	if status.OfflinePartitions != 1 {
+		t.Error("Expected 1 offline partition, got:", status.OfflinePartitions)
+	}
+	if len(status.Partitions) != 3 {
+		t.Fatal("Expected 3 partitions, got:", len(status.Partitions))
+	}
+	for i, partition := range status.Partitions {
+		if partition.Partition != int32(i) {
+			t.Errorf("Expected partition %d at index %d, got: %d", i, i, partition.Partition)
+		}
+	}
+	if partition := status.Partitions[1]; partition.Leader != 1 || len(partition.InSyncReplicas) != 1 || len(partition.OfflineReplicas) != 1 {
+		t.Error("Expected the details of partition 1 to be kept, got:", partition)
+	}
+}
+
+func TestPartitionSizesFromLogDirs(t *testing.T) {
+	logDirsByBroker := map[int32][]sarama.DescribeLogDirsResponseDirMetadata{
+		0: {
+			{
+				Path: "/kafka-logs",
+				Topics: []sarama.DescribeLogDirsResponseTopic{
+					{Topic: "test-topic", Partitions: []sarama.DescribeLogDirsResponsePartition{
+						{PartitionID: 0, Size: 100},
+						{PartitionID: 1, Size: 40},
+					}},
+					{Topic: "other-topic", Partitions: []sarama.DescribeLogDirsResponsePartition{
+						{PartitionID: 0, Size: 1000},
+					}},
+				},
+			},
+			{
+				Path:      "/broken-logs",
+				ErrorCode: sarama.ErrKafkaStorageError,
+				Topics: []sarama.DescribeLogDirsResponseTopic{
+					{Topic: "test-topic", Partitions: []sarama.DescribeLogDirsResponsePartition{
+						{PartitionID: 0, Size: 500},
+					}},
+				},
+			},
+		},
+		1: {
+			{
+				Path: "/kafka-logs",
+				Topics: []sarama.DescribeLogDirsResponseTopic{
+					{Topic: "test-topic", Partitions: []sarama.DescribeLogDirsResponsePartition{
+						{PartitionID: 0, Size: 90},
+						{PartitionID: 1, Size: 50},
+						{PartitionID: 1, Size: 70, IsTemporary: true},
+					}},
+				},
+			},
+		},
+	}
+
+	sizes := partitionSizesFromLogDirs("test-topic", logDirsByBroker)
+	if len(sizes) != 2 || sizes[0] != 100 || sizes[1] != 50 {
+		t.Error("Expected sizes 100 and 50 of partitions 0 and 1, got:", sizes)
+	}
+}
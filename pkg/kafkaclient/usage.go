@@ -0,0 +1,132 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"sort"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+)
+
+const consumerProtocolType = "consumer"
+
+// TopicUsage describes the production to and the consumption of a topic
+type TopicUsage struct {
+	// EndOffset is the sum of the end offsets of the partitions of the topic
+	EndOffset      int64
+	ConsumerGroups []ConsumerGroupUsage
+}
+
+// ConsumerGroupUsage describes the consumption of a topic by a consumer group
+type ConsumerGroupUsage struct {
+	Name string
+	// Consumers is the number of members of the group assigned partitions of the topic
+	Consumers int32
+	// Lag is the number of messages the group lags behind the end of the partitions it committed offsets for
+	Lag int64
+}
+
+// TopicUsage returns the end offsets of the topic and the consumer groups having committed offsets for it
+func (k *kafkaClient) TopicUsage(topic string) (*TopicUsage, error) {
+	partitions, err := k.client.Partitions(topic)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not fetch partitions", "topic", topic)
+	}
+	usage := &TopicUsage{}
+	endOffsets := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		offset, err := k.client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch end offset", "topic", topic, "partition", partition)
+		}
+		endOffsets[partition] = offset
+		usage.EndOffset += offset
+	}
+
+	groups, err := k.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list consumer groups")
+	}
+	lags := make(map[string]int64)
+	for group, protocolType := range groups {
+		if protocolType != consumerProtocolType {
+			continue
+		}
+		offsets, err := k.admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch consumer group offsets", "group", group)
+		}
+		if lag, consumes := consumerGroupLag(topic, endOffsets, offsets); consumes {
+			lags[group] = lag
+		}
+	}
+	if len(lags) == 0 {
+		return usage, nil
+	}
+
+	names := make([]string, 0, len(lags))
+	for group := range lags {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	descriptions, err := k.admin.DescribeConsumerGroups(names)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not describe consumer groups")
+	}
+	consumers := make(map[string]int32, len(descriptions))
+	for _, description := range descriptions {
+		consumers[description.GroupId] = assignedConsumers(topic, description)
+	}
+	for _, group := range names {
+		usage.ConsumerGroups = append(usage.ConsumerGroups, ConsumerGroupUsage{
+			Name:      group,
+			Consumers: consumers[group],
+			Lag:       lags[group],
+		})
+	}
+	return usage, nil
+}
+
+// consumerGroupLag returns the number of messages the group lags behind the end of the partitions of the topic, the
+// group does not consume the topic if it has not committed offsets for any of its partitions
+func consumerGroupLag(topic string, endOffsets map[int32]int64, offsets *sarama.OffsetFetchResponse) (lag int64, consumes bool) {
+	for partition, endOffset := range endOffsets {
+		block := offsets.GetBlock(topic, partition)
+		if block == nil || block.Err != sarama.ErrNoError || block.Offset < 0 {
+			continue
+		}
+		consumes = true
+		if endOffset > block.Offset {
+			lag += endOffset - block.Offset
+		}
+	}
+	return lag, consumes
+}
+
+// assignedConsumers returns the number of members of the consumer group assigned partitions of the topic
+func assignedConsumers(topic string, description *sarama.GroupDescription) int32 {
+	var consumers int32
+	for _, member := range description.Members {
+		assignment, err := member.GetMemberAssignment()
+		if err != nil || assignment == nil {
+			continue
+		}
+		if len(assignment.Topics[topic]) > 0 {
+			consumers++
+		}
+	}
+	return consumers
+}
@@ -0,0 +1,88 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+// encodeMemberAssignment encodes the assignment of a consumer group member the way the consumer protocol does
+func encodeMemberAssignment(topics map[string][]int32) []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.BigEndian, int16(0))
+	_ = binary.Write(buf, binary.BigEndian, int32(len(topics)))
+	for topic, partitions := range topics {
+		_ = binary.Write(buf, binary.BigEndian, int16(len(topic)))
+		buf.WriteString(topic)
+		_ = binary.Write(buf, binary.BigEndian, int32(len(partitions)))
+		_ = binary.Write(buf, binary.BigEndian, partitions)
+	}
+	// no user data
+	_ = binary.Write(buf, binary.BigEndian, int32(-1))
+	return buf.Bytes()
+}
+
+func TestConsumerGroupLag(t *testing.T) {
+	endOffsets := map[int32]int64{0: 100, 1: 50, 2: 10}
+
+	offsets := &sarama.OffsetFetchResponse{}
+	offsets.AddBlock("test-topic", 0, &sarama.OffsetFetchResponseBlock{Offset: 80})
+	offsets.AddBlock("test-topic", 1, &sarama.OffsetFetchResponseBlock{Offset: 50})
+	offsets.AddBlock("test-topic", 2, &sarama.OffsetFetchResponseBlock{Offset: -1})
+	if lag, consumes := consumerGroupLag("test-topic", endOffsets, offsets); !consumes || lag != 20 {
+		t.Errorf("Expected the group to consume the topic with a lag of 20, got: %t, %d", consumes, lag)
+	}
+
+	offsets = &sarama.OffsetFetchResponse{}
+	offsets.AddBlock("test-topic", 0, &sarama.OffsetFetchResponseBlock{Offset: -1})
+	if _, consumes := consumerGroupLag("test-topic", endOffsets, offsets); consumes {
+		t.Error("Expected the group without committed offsets not to consume the topic")
+	}
+}
+
+func TestAssignedConsumers(t *testing.T) {
+	description := &sarama.GroupDescription{
+		GroupId: "test-group",
+		Members: map[string]*sarama.GroupMemberDescription{
+			"consumer-1": {MemberAssignment: encodeMemberAssignment(map[string][]int32{"test-topic": {0, 1}})},
+			"consumer-2": {MemberAssignment: encodeMemberAssignment(map[string][]int32{"test-topic": {2}, "other-topic": {0}})},
+			"consumer-3": {MemberAssignment: encodeMemberAssignment(map[string][]int32{"other-topic": {1}})},
+			"consumer-4": {},
+		},
+	}
+	if consumers := assignedConsumers("test-topic", description); consumers != 2 {
+		t.Error("Expected 2 consumers assigned partitions of the topic, got:", consumers)
+	}
+}
+
+func TestTopicUsage(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if usage, err := client.TopicUsage("test-topic"); err != nil {
+		t.Error("Expected no error on TopicUsage, got:", err)
+	} else if usage.EndOffset != 0 || len(usage.ConsumerGroups) != 0 {
+		t.Error("Expected no usage of the topic, got:", usage)
+	}
+
+	client.client, _ = newMockKafkaClient([]string{}, sarama.NewConfig())
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err := client.TopicUsage("test-topic"); err == nil {
+		t.Error("Expected error on TopicUsage, got nil")
+	}
+}
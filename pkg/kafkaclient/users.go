@@ -83,6 +83,72 @@ func (k *kafkaClient) DeleteUserACLs(dn string) (err error) {
 	return
 }
 
+// revokedUserResources are the resources every operation of a revoked user is denied on
+var revokedUserResources = []sarama.Resource{
+	{ResourceType: sarama.AclResourceTopic, ResourceName: "*", ResourcePatternType: sarama.AclPatternLiteral},
+	{ResourceType: sarama.AclResourceGroup, ResourceName: "*", ResourcePatternType: sarama.AclPatternLiteral},
+	{ResourceType: sarama.AclResourceTransactionalID, ResourceName: "*", ResourcePatternType: sarama.AclPatternLiteral},
+	{ResourceType: sarama.AclResourceCluster, ResourceName: "kafka-cluster", ResourcePatternType: sarama.AclPatternLiteral},
+}
+
+// RevokeUserACLs replaces the ACLs of the given user with DENY ACLs for every operation on every resource so that
+// the certificate of the user is rejected even if it is still trusted by the brokers
+func (k *kafkaClient) RevokeUserACLs(dn string) (err error) {
+	userName := fmt.Sprintf("User:%s", dn)
+	matches, err := k.admin.DeleteACL(sarama.AclFilter{
+		Principal:      &userName,
+		PermissionType: sarama.AclPermissionAllow,
+	}, false)
+	if err != nil {
+		return
+	}
+	for _, x := range matches {
+		if x.Err != sarama.ErrNoError {
+			return x.Err
+		}
+	}
+
+	for _, resource := range revokedUserResources {
+		if err = k.admin.CreateACL(resource, sarama.Acl{
+			Principal:      userName,
+			Host:           "*",
+			Operation:      sarama.AclOperationAll,
+			PermissionType: sarama.AclPermissionDeny,
+		}); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// RestoreUserACLs removes the DENY ACLs created by RevokeUserACLs for the given user, so that the ACLs of its topic
+// grants take effect again
+func (k *kafkaClient) RestoreUserACLs(dn string) error {
+	userName := fmt.Sprintf("User:%s", dn)
+	host := "*"
+	for _, resource := range revokedUserResources {
+		resourceName := resource.ResourceName
+		matches, err := k.admin.DeleteACL(sarama.AclFilter{
+			ResourceType:              resource.ResourceType,
+			ResourceName:              &resourceName,
+			ResourcePatternTypeFilter: resource.ResourcePatternType,
+			Principal:                 &userName,
+			Host:                      &host,
+			Operation:                 sarama.AclOperationAll,
+			PermissionType:            sarama.AclPermissionDeny,
+		}, false)
+		if err != nil {
+			return err
+		}
+		for _, x := range matches {
+			if x.Err != sarama.ErrNoError {
+				return x.Err
+			}
+		}
+	}
+	return nil
+}
+
 func (k *kafkaClient) createReadACLs(dn string, topic string, patternType sarama.AclResourcePatternType) (err error) {
 	if err = k.createCommonACLs(dn, topic, patternType); err != nil {
 		return
@@ -94,3 +94,78 @@ func TestDeleteUserACLs(t *testing.T) {
 		t.Error("Expected error, got nil")
 	}
 }
+
+func TestRevokeUserACLs(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if err := client.CreateUserACLs(v1alpha1.KafkaAccessTypeRead, v1alpha1.KafkaPatternTypeLiteral, "revoked-user", "test-topic"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := client.RevokeUserACLs("revoked-user"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	acls, err := client.ListUserACLs()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	var denyACLs int
+	for _, resourceACLs := range acls {
+		for _, acl := range resourceACLs.Acls {
+			if acl.PermissionType != sarama.AclPermissionDeny {
+				t.Errorf("Expected only DENY ACLs, got: %+v", acl)
+				continue
+			}
+			denyACLs++
+		}
+	}
+	if denyACLs != 4 {
+		t.Error("Expected 4 DENY ACLs, got:", denyACLs)
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if err := client.RevokeUserACLs("revoked-user"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestRestoreUserACLs(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if err := client.CreateUserACLs(v1alpha1.KafkaAccessTypeRead, v1alpha1.KafkaPatternTypeLiteral, "revoked-user", "test-topic"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := client.RevokeUserACLs("revoked-user"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := client.RestoreUserACLs("revoked-user"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	// the ACLs of the topic grants are recreated once the user is no longer revoked
+	if err := client.CreateUserACLs(v1alpha1.KafkaAccessTypeRead, v1alpha1.KafkaPatternTypeLiteral, "revoked-user", "test-topic"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	acls, err := client.ListUserACLs()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	var allowACLs int
+	for _, resourceACLs := range acls {
+		for _, acl := range resourceACLs.Acls {
+			if acl.PermissionType != sarama.AclPermissionAllow {
+				t.Errorf("Expected only ALLOW ACLs, got: %+v", acl)
+				continue
+			}
+			allowACLs++
+		}
+	}
+	if allowACLs == 0 {
+		t.Error("Expected the ALLOW ACLs of the topic grants to be recreated")
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if err := client.RestoreUserACLs("revoked-user"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"emperror.dev/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+	clientutil "github.com/banzaicloud/koperator/pkg/util/client"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	// MirrorMakerTemplate is the name template of the ConfigMap and the Deployment of MirrorMaker 2
+	MirrorMakerTemplate = "%s-mirrormaker2"
+
+	sourceAlias = "source"
+	targetAlias = "target"
+
+	configFileName   = "mm2.properties"
+	configVolume     = "config"
+	configVolumePath = "/config"
+	// keystoreVolumePathTemplate is the path the client certificates of the source and the target cluster are
+	// mounted under
+	keystoreVolumePathTemplate = "/var/run/secrets/%s"
+	configHashAnnotation       = "mirrorMakerConfig.sha256"
+
+	maxInternalTopicReplicationFactor = 3
+)
+
+// Name returns the name of the ConfigMap and the Deployment of MirrorMaker 2 of the migration
+func Name(migration *v1alpha1.KafkaMigration) string {
+	return fmt.Sprintf(MirrorMakerTemplate, migration.Name)
+}
+
+func labelsForMirrorMaker(migration *v1alpha1.KafkaMigration) map[string]string {
+	return map[string]string{"app": "mirrormaker2", "kafka_migration": migration.Name}
+}
+
+func objectMeta(migration *v1alpha1.KafkaMigration) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      Name(migration),
+		Namespace: migration.Namespace,
+		Labels:    labelsForMirrorMaker(migration),
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion:         v1alpha1.GroupVersion.String(),
+				Kind:               "KafkaMigration",
+				Name:               migration.Name,
+				UID:                migration.UID,
+				Controller:         util.BoolPointer(true),
+				BlockOwnerDeletion: util.BoolPointer(true),
+			},
+		},
+	}
+}
+
+// ConfigMap returns the ConfigMap holding the configuration of MirrorMaker 2 replicating the topics of the migration
+// from the source to the target cluster
+func ConfigMap(migration *v1alpha1.KafkaMigration, source, target *v1beta1.KafkaCluster) (*corev1.ConfigMap, error) {
+	config, err := mirrorMakerConfig(migration, source, target)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: objectMeta(migration),
+		Data:       map[string]string{configFileName: config},
+	}, nil
+}
+
+func mirrorMakerConfig(migration *v1alpha1.KafkaMigration, source, target *v1beta1.KafkaCluster) (string, error) {
+	config := map[string]string{
+		"clusters":                                    sourceAlias + ", " + targetAlias,
+		"replication.policy.class":                    "org.apache.kafka.connect.mirror.IdentityReplicationPolicy",
+		"config.providers":                            "dir",
+		"config.providers.dir.class":                  "org.apache.kafka.common.config.provider.DirectoryConfigProvider",
+		sourceAlias + "->" + targetAlias + ".enabled": "true",
+		sourceAlias + "->" + targetAlias + ".topics":  migration.Spec.GetTopics(),
+		targetAlias + "->" + sourceAlias + ".enabled": "false",
+		// the offsets of the consumer groups are translated by the operator during the cutover
+		"emit.checkpoints.enabled":   "false",
+		"emit.heartbeats.enabled":    "false",
+		"sync.group.offsets.enabled": "false",
+		// the ACLs of the target cluster are managed through KafkaUsers
+		"sync.topic.acls.enabled": "false",
+
+		"replication.factor":                    internalTopicReplicationFactor(target),
+		"offset-syncs.topic.replication.factor": internalTopicReplicationFactor(source),
+		"checkpoints.topic.replication.factor":  internalTopicReplicationFactor(target),
+		"heartbeats.topic.replication.factor":   internalTopicReplicationFactor(target),
+		"config.storage.replication.factor":     internalTopicReplicationFactor(target),
+		"offset.storage.replication.factor":     internalTopicReplicationFactor(target),
+		"status.storage.replication.factor":     internalTopicReplicationFactor(target),
+	}
+	if migration.Spec.MirrorMaker.Tasks > 0 {
+		config["tasks.max"] = fmt.Sprint(migration.Spec.MirrorMaker.Tasks)
+	}
+	for alias, cluster := range map[string]*v1beta1.KafkaCluster{sourceAlias: source, targetAlias: target} {
+		config[alias+".bootstrap.servers"] = clientutil.GenerateKafkaAddress(cluster)
+		if !clientutil.UseSSL(cluster) {
+			continue
+		}
+		if cluster.Namespace != migration.Namespace {
+			return "", errors.NewWithDetails("the client certificate of a cluster using SSL must be in the namespace of the migration",
+				"cluster", cluster.Name, "namespace", cluster.Namespace)
+		}
+		keystorePath := fmt.Sprintf(keystoreVolumePathTemplate, alias)
+		config[alias+".security.protocol"] = "SSL"
+		config[alias+".ssl.truststore.location"] = keystorePath + "/" + v1alpha1.TLSJKSTrustStore
+		config[alias+".ssl.truststore.password"] = fmt.Sprintf("${dir:%s:%s}", keystorePath, v1alpha1.PasswordKey)
+		config[alias+".ssl.keystore.location"] = keystorePath + "/" + v1alpha1.TLSJKSKeyStore
+		config[alias+".ssl.keystore.password"] = fmt.Sprintf("${dir:%s:%s}", keystorePath, v1alpha1.PasswordKey)
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	props := properties.NewProperties()
+	for _, key := range keys {
+		if err := props.Set(key, config[key]); err != nil {
+			return "", errors.WrapIfWithDetails(err, "could not set MirrorMaker 2 configuration", "key", key)
+		}
+	}
+	return props.String(), nil
+}
+
+// internalTopicReplicationFactor returns the replication factor of the internal topics MirrorMaker 2 creates in the
+// cluster
+func internalTopicReplicationFactor(cluster *v1beta1.KafkaCluster) string {
+	replicationFactor := len(cluster.Spec.Brokers)
+	if replicationFactor > maxInternalTopicReplicationFactor {
+		replicationFactor = maxInternalTopicReplicationFactor
+	}
+	if replicationFactor < 1 {
+		replicationFactor = 1
+	}
+	return fmt.Sprint(replicationFactor)
+}
+
+// Deployment returns the Deployment running MirrorMaker 2 with the given ConfigMap, the pods are rolled when the
+// configuration changes
+func Deployment(migration *v1alpha1.KafkaMigration, source, target *v1beta1.KafkaCluster, configMap *corev1.ConfigMap) *appsv1.Deployment {
+	configHash := sha256.Sum256([]byte(configMap.Data[configFileName]))
+
+	image := migration.Spec.MirrorMaker.Image
+	if image == "" {
+		image = target.Spec.GetClusterImage()
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: configVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: configMap.Name},
+				},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{{Name: configVolume, MountPath: configVolumePath}}
+	for _, alias := range []string{sourceAlias, targetAlias} {
+		cluster := source
+		if alias == targetAlias {
+			cluster = target
+		}
+		if !clientutil.UseSSL(cluster) {
+			continue
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: alias + "-keystore",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  clientSecretName(cluster),
+					DefaultMode: util.Int32Pointer(0644),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      alias + "-keystore",
+			MountPath: fmt.Sprintf(keystoreVolumePathTemplate, alias),
+		})
+	}
+
+	container := corev1.Container{
+		Name:         "mirrormaker2",
+		Image:        image,
+		Command:      []string{"/opt/kafka/bin/connect-mirror-maker.sh", configVolumePath + "/" + configFileName},
+		VolumeMounts: volumeMounts,
+	}
+	if migration.Spec.MirrorMaker.Resources != nil {
+		container.Resources = *migration.Spec.MirrorMaker.Resources
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: objectMeta(migration),
+		Spec: appsv1.DeploymentSpec{
+			// MirrorMaker 2 runs in dedicated mode, which does not support more than one node before Kafka 3.5
+			Replicas: util.Int32Pointer(1),
+			Selector: &metav1.LabelSelector{MatchLabels: labelsForMirrorMaker(migration)},
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labelsForMirrorMaker(migration),
+					Annotations: map[string]string{configHashAnnotation: hex.EncodeToString(configHash[:])},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{container},
+					Volumes:    volumes,
+				},
+			},
+		},
+	}
+}
+
+// clientSecretName returns the name of the Secret holding the client certificate the cluster is accessed with
+func clientSecretName(cluster *v1beta1.KafkaCluster) string {
+	if cluster.Spec.GetClientSSLCertSecretName() != "" {
+		return cluster.Spec.GetClientSSLCertSecretName()
+	}
+	return fmt.Sprintf(pkicommon.BrokerControllerTemplate, cluster.Name)
+}
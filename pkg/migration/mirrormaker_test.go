@@ -0,0 +1,116 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func testCluster(name string, listenerType v1beta1.SecurityProtocol, brokers int) *v1beta1.KafkaCluster {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Type: listenerType, Name: "internal", ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+				},
+			},
+		},
+	}
+	for i := 0; i < brokers; i++ {
+		cluster.Spec.Brokers = append(cluster.Spec.Brokers, v1beta1.Broker{Id: int32(i)})
+	}
+	return cluster
+}
+
+func TestConfigMap(t *testing.T) {
+	migration := &v1alpha1.KafkaMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "blue-green", Namespace: "kafka"},
+		Spec:       v1alpha1.KafkaMigrationSpec{Topics: "orders.*", MirrorMaker: v1alpha1.MirrorMakerConfig{Tasks: 4}},
+	}
+	source := testCluster("blue", v1beta1.SecurityProtocolPlaintext, 5)
+	target := testCluster("green", v1beta1.SecurityProtocolSSL, 2)
+
+	configMap, err := ConfigMap(migration, source, target)
+	if err != nil {
+		t.Fatal("Expected no error on ConfigMap, got:", err)
+	}
+	if configMap.Name != "blue-green-mirrormaker2" {
+		t.Error("Expected the name of the ConfigMap to be blue-green-mirrormaker2, got:", configMap.Name)
+	}
+	config := configMap.Data[configFileName]
+	for _, expected := range []string{
+		"source->target.topics=orders.*\n",
+		"target->source.enabled=false\n",
+		"tasks.max=4\n",
+		"source.bootstrap.servers=blue-all-broker.kafka.svc.cluster.local:29092\n",
+		"target.bootstrap.servers=green-all-broker.kafka.svc.cluster.local:29092\n",
+		"offset-syncs.topic.replication.factor=3\n",
+		"offset.storage.replication.factor=2\n",
+		"target.security.protocol=SSL\n",
+		"target.ssl.keystore.password=${dir:/var/run/secrets/target:password}\n",
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("Expected the configuration to contain %q, got:\n%s", expected, config)
+		}
+	}
+	if strings.Contains(config, "source.security.protocol") {
+		t.Errorf("Expected no security protocol for the plaintext source cluster, got:\n%s", config)
+	}
+
+	target.Namespace = "other"
+	if _, err := ConfigMap(migration, source, target); err == nil {
+		t.Error("Expected error on ConfigMap with an SSL cluster in another namespace, got nil")
+	}
+}
+
+func TestDeployment(t *testing.T) {
+	migration := &v1alpha1.KafkaMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "blue-green", Namespace: "kafka"},
+	}
+	source := testCluster("blue", v1beta1.SecurityProtocolSSL, 3)
+	target := testCluster("green", v1beta1.SecurityProtocolPlaintext, 3)
+
+	configMap, err := ConfigMap(migration, source, target)
+	if err != nil {
+		t.Fatal("Expected no error on ConfigMap, got:", err)
+	}
+	deployment := Deployment(migration, source, target, configMap)
+
+	podSpec := deployment.Spec.Template.Spec
+	if image := podSpec.Containers[0].Image; image != target.Spec.GetClusterImage() {
+		t.Error("Expected the image of the target cluster, got:", image)
+	}
+	if len(podSpec.Volumes) != 2 || podSpec.Volumes[1].Secret == nil || podSpec.Volumes[1].Secret.SecretName != "blue-controller" {
+		t.Error("Expected the client certificate of the source cluster to be mounted, got:", podSpec.Volumes)
+	}
+	if deployment.OwnerReferences[0].Kind != "KafkaMigration" {
+		t.Error("Expected the Deployment to be owned by the KafkaMigration, got:", deployment.OwnerReferences)
+	}
+
+	configMap.Data[configFileName] += "tasks.max=2\n"
+	if Deployment(migration, source, target, configMap).Spec.Template.Annotations[configHashAnnotation] == deployment.Spec.Template.Annotations[configHashAnnotation] {
+		t.Error("Expected the pods to be rolled when the configuration changes")
+	}
+}
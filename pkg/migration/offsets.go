@@ -0,0 +1,120 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"regexp"
+	"sort"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// internalTopic matches the topics MirrorMaker 2 does not replicate by default
+var internalTopic = regexp.MustCompile(`^(__.*|.*[\-.]internal|.*\.replica|heartbeats)$`)
+
+// CompilePattern compiles the regular expression of the topics or consumer groups of a migration, it has to match
+// the whole name the way MirrorMaker 2 matches the names of the topics
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	return re, errors.WrapIfWithDetails(err, "invalid regular expression", "pattern", pattern)
+}
+
+// MigratedTopics returns the sorted names of the topics matching the pattern, the internal topics are left out
+func MigratedTopics(topics map[string]sarama.TopicDetail, pattern *regexp.Regexp) []string {
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		if internalTopic.MatchString(name) || !pattern.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TopicPartitions returns the partitions of the topics the offsets are known for
+func TopicPartitions(offsets map[string]map[int32]kafkaclient.PartitionOffsets) map[string][]int32 {
+	partitions := make(map[string][]int32, len(offsets))
+	for topic, partitionOffsets := range offsets {
+		for partition := range partitionOffsets {
+			partitions[topic] = append(partitions[topic], partition)
+		}
+		sort.Slice(partitions[topic], func(i, j int) bool { return partitions[topic][i] < partitions[topic][j] })
+	}
+	return partitions
+}
+
+// Lag returns the number of messages the target cluster lags behind the source cluster. MirrorMaker 2 does not
+// preserve the offsets, so the lag is estimated by comparing the number of messages retained in the partitions,
+// transaction markers and compaction may keep it above zero.
+func Lag(source, target map[string]map[int32]kafkaclient.PartitionOffsets) int64 {
+	var lag int64
+	for topic, partitions := range source {
+		for partition, sourceOffsets := range partitions {
+			targetOffsets := target[topic][partition]
+			if behind := (sourceOffsets.End - sourceOffsets.Start) - (targetOffsets.End - targetOffsets.Start); behind > 0 {
+				lag += behind
+			}
+		}
+	}
+	return lag
+}
+
+// TranslateOffsets translates the committed offsets of a consumer group in the source cluster to the target cluster
+// keeping the number of messages left to consume. The translated offsets are clamped to the log start of the target
+// partitions, which may lead to messages being consumed again but never to messages being skipped.
+func TranslateOffsets(committed map[string]map[int32]int64, source, target map[string]map[int32]kafkaclient.PartitionOffsets) map[string]map[int32]int64 {
+	translated := make(map[string]map[int32]int64, len(committed))
+	for topic, partitions := range committed {
+		for partition, offset := range partitions {
+			sourceOffsets, ok := source[topic][partition]
+			if !ok {
+				continue
+			}
+			targetOffsets, ok := target[topic][partition]
+			if !ok {
+				continue
+			}
+			remaining := sourceOffsets.End - offset
+			if remaining < 0 {
+				remaining = 0
+			}
+			targetOffset := targetOffsets.End - remaining
+			if targetOffset < targetOffsets.Start {
+				targetOffset = targetOffsets.Start
+			}
+			if translated[topic] == nil {
+				translated[topic] = make(map[int32]int64, len(partitions))
+			}
+			translated[topic][partition] = targetOffset
+		}
+	}
+	return translated
+}
+
+// MatchingGroups returns the sorted names of the consumer groups matching the pattern
+func MatchingGroups(groups map[string]map[string]map[int32]int64, pattern *regexp.Regexp) []string {
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		if pattern.MatchString(group) {
+			names = append(names, group)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,116 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+func TestMigratedTopics(t *testing.T) {
+	topics := map[string]sarama.TopicDetail{
+		"orders":                      {},
+		"payments":                    {},
+		"audit":                       {},
+		"__consumer_offsets":          {},
+		"mm2-offsets.target.internal": {},
+		"heartbeats":                  {},
+	}
+
+	pattern, err := CompilePattern(".*")
+	if err != nil {
+		t.Fatal("Expected no error on CompilePattern, got:", err)
+	}
+	if migrated := MigratedTopics(topics, pattern); !reflect.DeepEqual(migrated, []string{"audit", "orders", "payments"}) {
+		t.Error("Expected the internal topics to be left out, got:", migrated)
+	}
+
+	pattern, err = CompilePattern("orders|pay")
+	if err != nil {
+		t.Fatal("Expected no error on CompilePattern, got:", err)
+	}
+	if migrated := MigratedTopics(topics, pattern); !reflect.DeepEqual(migrated, []string{"orders"}) {
+		t.Error("Expected the pattern to match the whole name of the topics, got:", migrated)
+	}
+
+	if _, err := CompilePattern("("); err == nil {
+		t.Error("Expected error on CompilePattern with an invalid regular expression, got nil")
+	}
+}
+
+func TestLag(t *testing.T) {
+	source := map[string]map[int32]kafkaclient.PartitionOffsets{
+		"orders": {0: {Start: 100, End: 1100}, 1: {Start: 0, End: 500}},
+		"audit":  {0: {Start: 0, End: 20}},
+	}
+	target := map[string]map[int32]kafkaclient.PartitionOffsets{
+		"orders": {0: {Start: 0, End: 900}, 1: {Start: 0, End: 500}},
+	}
+	if lag := Lag(source, target); lag != 120 {
+		t.Error("Expected a lag of 120 messages, got:", lag)
+	}
+	if lag := Lag(source, source); lag != 0 {
+		t.Error("Expected no lag, got:", lag)
+	}
+}
+
+func TestTranslateOffsets(t *testing.T) {
+	source := map[string]map[int32]kafkaclient.PartitionOffsets{
+		"orders": {0: {Start: 100, End: 1100}, 1: {Start: 0, End: 500}, 2: {Start: 0, End: 10}},
+	}
+	target := map[string]map[int32]kafkaclient.PartitionOffsets{
+		"orders": {0: {Start: 0, End: 1000}, 1: {Start: 50, End: 500}},
+	}
+	committed := map[string]map[int32]int64{
+		"orders": {0: 1050, 1: 10, 2: 5},
+		"audit":  {0: 1},
+	}
+
+	expected := map[string]map[int32]int64{
+		"orders": {
+			// 50 messages left to consume
+			0: 950,
+			// the translated offset is clamped to the log start of the target partition
+			1: 50,
+		},
+	}
+	if translated := TranslateOffsets(committed, source, target); !reflect.DeepEqual(translated, expected) {
+		t.Error("Expected the offsets to keep the number of messages left to consume, got:", translated)
+	}
+}
+
+func TestTopicPartitions(t *testing.T) {
+	offsets := map[string]map[int32]kafkaclient.PartitionOffsets{
+		"orders": {2: {}, 0: {}, 1: {}},
+	}
+	if partitions := TopicPartitions(offsets); !reflect.DeepEqual(partitions, map[string][]int32{"orders": {0, 1, 2}}) {
+		t.Error("Expected the sorted partitions of the topics, got:", partitions)
+	}
+}
+
+func TestMatchingGroups(t *testing.T) {
+	pattern, err := CompilePattern("app-.*")
+	if err != nil {
+		t.Fatal("Expected no error on CompilePattern, got:", err)
+	}
+	groups := map[string]map[string]map[int32]int64{"app-b": {}, "app-a": {}, "other": {}}
+	if matching := MatchingGroups(groups, pattern); !reflect.DeepEqual(matching, []string{"app-a", "app-b"}) {
+		t.Error("Expected the sorted matching consumer groups, got:", matching)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectioninfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+	envoyutils "github.com/banzaicloud/koperator/pkg/util/envoy"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+const (
+	componentName = "connectioninfo"
+	// ConnectionInfoTemplate is the name template of the ConfigMap and Secret holding the client connection details
+	ConnectionInfoTemplate = "%s-connection-info"
+
+	listenersKey        = "listeners"
+	bootstrapServersKey = "%s.bootstrap.servers"
+	securityProtocolKey = "%s.security.protocol"
+	caCertKey           = "%s.ca.crt"
+	trustStoreKey       = "%s.truststore.jks"
+	trustStorePassKey   = "%s.truststore.password"
+
+	topologyFingerprintKey = "topology.fingerprint"
+	topologyGenerationKey  = "topology.generation"
+	topologyChangedAtKey   = "topology.changedAt"
+	topologyBrokersKey     = "topology.brokers"
+
+	topologyChangedReason = "TopologyChanged"
+)
+
+// Reconciler implements the Component Reconciler
+type Reconciler struct {
+	resources.Reconciler
+	recorder record.EventRecorder
+}
+
+// New creates a new reconciler for the client connection details
+func New(client client.Client, cluster *v1beta1.KafkaCluster, recorder record.EventRecorder) *Reconciler {
+	return &Reconciler{
+		Reconciler: resources.Reconciler{
+			Client:       client,
+			KafkaCluster: cluster,
+		},
+		recorder: recorder,
+	}
+}
+
+func labelsForConnectionInfo(clusterName string) map[string]string {
+	return map[string]string{"app": "kafka-connection-info", "kafka_cr": clusterName}
+}
+
+// listenerInfo holds the client connection details of a listener
+type listenerInfo struct {
+	name             string
+	securityProtocol v1beta1.SecurityProtocol
+	bootstrapServers []string
+	caCert           []byte
+}
+
+// Reconcile implements the reconcile logic for the client connection details
+func (r *Reconciler) Reconcile(log logr.Logger) error {
+	log = log.WithValues("component", componentName)
+
+	log.V(1).Info("Reconciling")
+
+	name := fmt.Sprintf(ConnectionInfoTemplate, r.KafkaCluster.Name)
+	if !r.KafkaCluster.Spec.ConnectionInfo.Enabled {
+		for _, o := range []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}} {
+			o.SetName(name)
+			o.SetNamespace(r.KafkaCluster.Namespace)
+			if err := r.Client.Delete(context.TODO(), o); err != nil && !apierrors.IsNotFound(err) {
+				return errorfactory.New(errorfactory.APIFailure{}, err, "deleting connection info failed", "name", name)
+			}
+		}
+		return nil
+	}
+
+	listeners, err := r.listenerInfos()
+	if err != nil {
+		return err
+	}
+
+	configMap := r.configMap(name, listeners)
+	if r.KafkaCluster.Spec.ConnectionInfo.TopologyChangeHints {
+		if err := r.setTopologyHints(log, configMap); err != nil {
+			return err
+		}
+	}
+	if err := k8sutil.Reconcile(log, r.Client, configMap, r.KafkaCluster); err != nil {
+		return err
+	}
+
+	currentSecret := &corev1.Secret{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Namespace: r.KafkaCluster.Namespace, Name: name}, currentSecret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "getting connection info secret failed", "name", name)
+	}
+	secret, err := r.secret(name, listeners, currentSecret)
+	if err != nil {
+		return err
+	}
+	if err := k8sutil.Reconcile(log, r.Client, secret, r.KafkaCluster); err != nil {
+		return err
+	}
+
+	log.V(1).Info("Reconciled")
+
+	return nil
+}
+
+// listenerInfos collects the connection details of the listeners clients can connect to
+func (r *Reconciler) listenerInfos() ([]listenerInfo, error) {
+	listenersConfig := r.KafkaCluster.Spec.ListenersConfig
+	statuses := r.KafkaCluster.Status.ListenerStatuses
+
+	listeners := make([]listenerInfo, 0, len(listenersConfig.InternalListeners)+len(listenersConfig.ExternalListeners))
+	for _, iListener := range listenersConfig.InternalListeners {
+		// the controller listener is used by the brokers only
+		if iListener.UsedForControllerCommunication {
+			continue
+		}
+		info, err := r.listenerInfo(iListener.CommonListenerSpec, statuses.InternalListeners[iListener.Name])
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, info)
+	}
+	for _, eListener := range listenersConfig.ExternalListeners {
+		statusList, ok := statuses.ExternalListeners[eListener.Name]
+		if !ok {
+			// the address of the listener is not known yet
+			continue
+		}
+		if r.envoyTerminatesTLS(eListener) {
+			// the clients are presented the certificate of Envoy, which is not issued by the internal CA
+			listeners = append(listeners, listenerInfo{
+				name:             eListener.Name,
+				securityProtocol: terminatedSecurityProtocol(eListener.Type),
+				bootstrapServers: statusList.BootstrapAddresses(),
+			})
+			continue
+		}
+		info, err := r.listenerInfo(eListener.CommonListenerSpec, statusList)
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, info)
+	}
+	return listeners, nil
+}
+
+// envoyTerminatesTLS returns true if the client connections of the external listener are TLS terminated by Envoy
+// through all of its ingress configs
+func (r *Reconciler) envoyTerminatesTLS(eListener v1beta1.ExternalListenerConfig) bool {
+	if eListener.GetIngressController(r.KafkaCluster.Spec.GetIngressController()) != envoyutils.IngressControllerName {
+		return false
+	}
+	ingressConfigs, _, err := util.GetIngressConfigs(r.KafkaCluster.Spec, eListener)
+	if err != nil || len(ingressConfigs) == 0 {
+		return false
+	}
+	for _, ingressConfig := range ingressConfigs {
+		if !ingressConfig.EnvoyConfig.TerminatesTLS() {
+			return false
+		}
+	}
+	return true
+}
+
+// terminatedSecurityProtocol returns the security protocol the clients use towards Envoy terminating their TLS
+func terminatedSecurityProtocol(listenerType v1beta1.SecurityProtocol) v1beta1.SecurityProtocol {
+	if listenerType == v1beta1.SecurityProtocolSaslPlaintext || listenerType == v1beta1.SecurityProtocolSaslSSL {
+		return v1beta1.SecurityProtocolSaslSSL
+	}
+	return v1beta1.SecurityProtocolSSL
+}
+
+func (r *Reconciler) listenerInfo(listener v1beta1.CommonListenerSpec, statusList v1beta1.ListenerStatusList) (listenerInfo, error) {
+	info := listenerInfo{
+		name:             listener.Name,
+		securityProtocol: listener.Type,
+		bootstrapServers: statusList.BootstrapAddresses(),
+	}
+	if listener.Type != v1beta1.SecurityProtocolSSL && listener.Type != v1beta1.SecurityProtocolSaslSSL {
+		return info, nil
+	}
+
+	secretName := fmt.Sprintf(pkicommon.BrokerServerCertTemplate, r.KafkaCluster.Name)
+	if listener.GetServerSSLCertSecretName() != "" {
+		secretName = listener.GetServerSSLCertSecretName()
+	}
+	serverSecret := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: r.KafkaCluster.Namespace, Name: secretName}, serverSecret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return info, errorfactory.New(errorfactory.ResourceNotReady{}, err, "server secret not ready", "name", secretName)
+		}
+		return info, errorfactory.New(errorfactory.APIFailure{}, err, "getting server secret failed", "name", secretName)
+	}
+	info.caCert = serverSecret.Data[v1alpha1.CoreCACertKey]
+	return info, nil
+}
+
+func (r *Reconciler) configMap(name string, listeners []listenerInfo) *corev1.ConfigMap {
+	listenerNames := make([]string, 0, len(listeners))
+	data := make(map[string]string, 3*len(listeners)+1)
+	for _, listener := range listeners {
+		listenerNames = append(listenerNames, listener.name)
+		data[fmt.Sprintf(bootstrapServersKey, listener.name)] = strings.Join(listener.bootstrapServers, ",")
+		data[fmt.Sprintf(securityProtocolKey, listener.name)] = listener.securityProtocol.ToUpperString()
+		if len(listener.caCert) > 0 {
+			data[fmt.Sprintf(caCertKey, listener.name)] = string(listener.caCert)
+		}
+	}
+	data[listenersKey] = strings.Join(listenerNames, ",")
+
+	return &corev1.ConfigMap{
+		ObjectMeta: templates.ObjectMeta(name, labelsForConnectionInfo(r.KafkaCluster.Name), r.KafkaCluster),
+		Data:       data,
+	}
+}
+
+// secret returns the Secret holding a JKS truststore for the CA certificate of each SSL listener. The truststores of the
+// current Secret are reused until the CA certificates change, so that their passwords stay stable.
+func (r *Reconciler) secret(name string, listeners []listenerInfo, current *corev1.Secret) (*corev1.Secret, error) {
+	data := make(map[string][]byte, 3*len(listeners))
+	for _, listener := range listeners {
+		if len(listener.caCert) == 0 {
+			continue
+		}
+		caKey := fmt.Sprintf(caCertKey, listener.name)
+		storeKey := fmt.Sprintf(trustStoreKey, listener.name)
+		passKey := fmt.Sprintf(trustStorePassKey, listener.name)
+
+		data[caKey] = listener.caCert
+		if bytes.Equal(current.Data[caKey], listener.caCert) && len(current.Data[storeKey]) > 0 && len(current.Data[passKey]) > 0 {
+			data[storeKey] = current.Data[storeKey]
+			data[passKey] = current.Data[passKey]
+			continue
+		}
+
+		caCerts, err := certutil.ParseCertificates(listener.caCert)
+		if err != nil {
+			return nil, errorfactory.New(errorfactory.InternalError{}, err, "could not parse CA certificate", "listener", listener.name)
+		}
+		trustStore, password, err := certutil.GenerateTrustStoreJKS(certutil.GetCertBundle(caCerts))
+		if err != nil {
+			return nil, errorfactory.New(errorfactory.InternalError{}, err, "could not generate truststore", "listener", listener.name)
+		}
+		data[storeKey] = trustStore
+		data[passKey] = password
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: templates.ObjectMeta(name, labelsForConnectionInfo(r.KafkaCluster.Name), r.KafkaCluster),
+		Data:       data,
+	}, nil
+}
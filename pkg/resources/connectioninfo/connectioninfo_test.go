@@ -0,0 +1,229 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectioninfo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+)
+
+func TestReconcile(t *testing.T) {
+	caCert, _, _, err := certutil.GenerateTestCert()
+	if err != nil {
+		t.Fatal("failed to generate test certificate")
+	}
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ConnectionInfo: v1beta1.ConnectionInfoConfig{Enabled: true},
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Type: v1beta1.SecurityProtocolPlaintext, Name: "internal", ContainerPort: 29092}},
+					{
+						CommonListenerSpec:             v1beta1.CommonListenerSpec{Type: v1beta1.SecurityProtocolPlaintext, Name: "controller", ContainerPort: 29093},
+						UsedForControllerCommunication: true,
+					},
+				},
+				ExternalListeners: []v1beta1.ExternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Type: v1beta1.SecurityProtocolSSL, Name: "external", ContainerPort: 9094}},
+				},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			ListenerStatuses: v1beta1.ListenerStatuses{
+				InternalListeners: map[string]v1beta1.ListenerStatusList{
+					"internal": {{Name: "any-broker", Address: "kafka-all-broker.kafka.svc.cluster.local:29092"}},
+				},
+				ExternalListeners: map[string]v1beta1.ListenerStatusList{
+					"external": {{Name: "any-broker", Address: "kafka.example.com:29092"}},
+				},
+			},
+		},
+	}
+	serverSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka-server-certificate", Namespace: "kafka"},
+		Data:       map[string][]byte{"ca.crt": caCert},
+	}
+
+	r := New(fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(serverSecret).Build(), cluster, record.NewFakeRecorder(10))
+	if err := r.Reconcile(logr.Discard()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	key := types.NamespacedName{Namespace: "kafka", Name: "kafka-connection-info"}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(context.TODO(), key, configMap); err != nil {
+		t.Fatalf("expected connection info ConfigMap: %s", err)
+	}
+	for k, expected := range map[string]string{
+		"listeners":                  "internal,external",
+		"internal.bootstrap.servers": "kafka-all-broker.kafka.svc.cluster.local:29092",
+		"internal.security.protocol": "PLAINTEXT",
+		"external.bootstrap.servers": "kafka.example.com:29092",
+		"external.security.protocol": "SSL",
+		"external.ca.crt":            string(caCert),
+	} {
+		if configMap.Data[k] != expected {
+			t.Errorf("expected %s=%q in ConfigMap, got: %q", k, expected, configMap.Data[k])
+		}
+	}
+	if _, ok := configMap.Data["controller.bootstrap.servers"]; ok {
+		t.Error("expected the controller listener to be left out")
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), key, secret); err != nil {
+		t.Fatalf("expected connection info Secret: %s", err)
+	}
+	password := string(secret.Data["external.truststore.password"])
+	if len(secret.Data["external.truststore.jks"]) == 0 || password == "" {
+		t.Fatal("expected truststore for the SSL listener")
+	}
+
+	// the truststore must not be regenerated while the CA certificate does not change
+	if err := r.Reconcile(logr.Discard()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := r.Client.Get(context.TODO(), key, secret); err != nil {
+		t.Fatalf("expected connection info Secret: %s", err)
+	}
+	if string(secret.Data["external.truststore.password"]) != password {
+		t.Error("expected the truststore password to be stable")
+	}
+
+	cluster.Spec.ConnectionInfo.Enabled = false
+	if err := r.Reconcile(logr.Discard()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := r.Client.Get(context.TODO(), key, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected connection info ConfigMap to be deleted, got: %v", err)
+	}
+}
+
+func TestReconcileTopologyChangeHints(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ConnectionInfo: v1beta1.ConnectionInfoConfig{Enabled: true, TopologyChangeHints: true},
+			Brokers:        []v1beta1.Broker{{Id: 1}, {Id: 0}},
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Type: v1beta1.SecurityProtocolPlaintext, Name: "internal", ContainerPort: 29092}},
+				},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			ListenerStatuses: v1beta1.ListenerStatuses{
+				InternalListeners: map[string]v1beta1.ListenerStatusList{
+					"internal": {
+						{Name: "any-broker", Address: "kafka-all-broker.kafka.svc.cluster.local:29092"},
+						{Name: "broker-0", Address: "kafka-0.kafka.svc.cluster.local:29092"},
+						{Name: "broker-1", Address: "kafka-1.kafka.svc.cluster.local:29092"},
+					},
+				},
+			},
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := New(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), cluster, recorder)
+
+	key := types.NamespacedName{Namespace: "kafka", Name: "kafka-connection-info"}
+	reconcile := func() *corev1.ConfigMap {
+		if err := r.Reconcile(logr.Discard()); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		configMap := &corev1.ConfigMap{}
+		if err := r.Client.Get(context.TODO(), key, configMap); err != nil {
+			t.Fatalf("expected connection info ConfigMap: %s", err)
+		}
+		return configMap
+	}
+
+	configMap := reconcile()
+	if configMap.Data["topology.generation"] != "1" || configMap.Data["topology.brokers"] != "0,1" {
+		t.Errorf("expected first topology generation with brokers 0,1, got: %v", configMap.Data)
+	}
+	if len(recorder.Events) != 0 {
+		t.Error("expected no event for the first topology of the cluster")
+	}
+
+	// the generation must not change while the topology does not change
+	configMap = reconcile()
+	if configMap.Data["topology.generation"] != "1" {
+		t.Errorf("expected topology generation 1, got: %s", configMap.Data["topology.generation"])
+	}
+
+	cluster.Spec.Brokers = append(cluster.Spec.Brokers, v1beta1.Broker{Id: 2})
+	cluster.Status.ListenerStatuses.InternalListeners["internal"] = append(cluster.Status.ListenerStatuses.InternalListeners["internal"],
+		v1beta1.ListenerStatus{Name: "broker-2", Address: "kafka-2.kafka.svc.cluster.local:29092"})
+	configMap = reconcile()
+	if configMap.Data["topology.generation"] != "2" || configMap.Data["topology.brokers"] != "0,1,2" {
+		t.Errorf("expected second topology generation with brokers 0,1,2, got: %v", configMap.Data)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected a TopologyChanged event, got %d events", len(recorder.Events))
+	}
+}
+
+func TestListenerInfosEnvoyTLSTermination(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			EnvoyConfig: v1beta1.EnvoyConfig{
+				TLS: &v1beta1.EnvoyTLSConfig{Mode: v1beta1.EnvoyTLSModeTerminate, SecretName: "kafka-public-tls"},
+			},
+			ListenersConfig: v1beta1.ListenersConfig{
+				ExternalListeners: []v1beta1.ExternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Type: v1beta1.SecurityProtocolSaslPlaintext, Name: "external", ContainerPort: 9094}},
+				},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			ListenerStatuses: v1beta1.ListenerStatuses{
+				ExternalListeners: map[string]v1beta1.ListenerStatusList{
+					"external": {{Name: "any-broker", Address: "kafka.example.com:29092"}},
+				},
+			},
+		},
+	}
+
+	r := New(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), cluster, record.NewFakeRecorder(10))
+	listeners, err := r.listenerInfos()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(listeners) != 1 {
+		t.Fatalf("expected 1 listener, got: %v", listeners)
+	}
+	if listeners[0].securityProtocol != v1beta1.SecurityProtocolSaslSSL {
+		t.Errorf("expected the clients to use SASL_SSL towards Envoy, got: %s", listeners[0].securityProtocol)
+	}
+	if len(listeners[0].caCert) != 0 {
+		t.Error("the internal CA must not be published for a listener Envoy terminates the TLS of")
+	}
+}
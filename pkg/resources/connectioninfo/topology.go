@@ -0,0 +1,113 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectioninfo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// setTopologyHints sets the topology hints of the cluster in the connection info ConfigMap. The generation is bumped
+// and a TopologyChanged event is recorded when the brokers or the advertised addresses of the listeners differ from
+// the ones of the current ConfigMap.
+func (r *Reconciler) setTopologyHints(log logr.Logger, configMap *corev1.ConfigMap) error {
+	current := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}, current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "getting connection info configmap failed", "name", configMap.Name)
+	}
+
+	brokerIDs := brokerIDsFromSpec(r.KafkaCluster)
+	fingerprint := topologyFingerprint(brokerIDs, r.KafkaCluster.Status.ListenerStatuses)
+
+	configMap.Data[topologyBrokersKey] = strings.Join(brokerIDs, ",")
+	configMap.Data[topologyFingerprintKey] = fingerprint
+
+	currentFingerprint, ok := current.Data[topologyFingerprintKey]
+	generation, parseErr := strconv.ParseInt(current.Data[topologyGenerationKey], 10, 64)
+	switch {
+	case !ok || parseErr != nil:
+		// the first topology of the cluster is not a change clients have to react to
+		configMap.Data[topologyGenerationKey] = "1"
+		configMap.Data[topologyChangedAtKey] = time.Now().UTC().Format(time.RFC3339)
+	case currentFingerprint == fingerprint:
+		configMap.Data[topologyGenerationKey] = current.Data[topologyGenerationKey]
+		configMap.Data[topologyChangedAtKey] = current.Data[topologyChangedAtKey]
+	default:
+		configMap.Data[topologyGenerationKey] = strconv.FormatInt(generation+1, 10)
+		configMap.Data[topologyChangedAtKey] = time.Now().UTC().Format(time.RFC3339)
+		log.Info("topology of the cluster changed", "generation", configMap.Data[topologyGenerationKey],
+			"brokers", configMap.Data[topologyBrokersKey])
+		if r.recorder != nil {
+			r.recorder.Eventf(r.KafkaCluster, corev1.EventTypeNormal, topologyChangedReason,
+				"brokers or advertised addresses changed, clients should refresh their bootstrap lists from %s (generation %s)",
+				configMap.Name, configMap.Data[topologyGenerationKey])
+		}
+	}
+	return nil
+}
+
+// brokerIDsFromSpec returns the sorted IDs of the brokers of the cluster
+func brokerIDsFromSpec(cluster *v1beta1.KafkaCluster) []string {
+	ids := make([]int, 0, len(cluster.Spec.Brokers))
+	for _, broker := range cluster.Spec.Brokers {
+		ids = append(ids, int(broker.Id))
+	}
+	sort.Ints(ids)
+
+	brokerIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		brokerIDs = append(brokerIDs, strconv.Itoa(id))
+	}
+	return brokerIDs
+}
+
+// topologyFingerprint returns the hash of the broker IDs and the addresses of the listeners, it does not depend on the
+// order of the listeners and their addresses
+func topologyFingerprint(brokerIDs []string, statuses v1beta1.ListenerStatuses) string {
+	var entries []string
+	for kind, listeners := range map[string]map[string]v1beta1.ListenerStatusList{
+		"internal": statuses.InternalListeners,
+		"external": statuses.ExternalListeners,
+	} {
+		for name, statusList := range listeners {
+			for _, status := range statusList {
+				entries = append(entries, fmt.Sprintf("%s/%s/%s=%s", kind, name, status.Name, status.Address))
+			}
+		}
+	}
+	sort.Strings(entries)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "brokers=%s\n", strings.Join(brokerIDs, ","))
+	for _, entry := range entries {
+		fmt.Fprintln(hash, entry)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
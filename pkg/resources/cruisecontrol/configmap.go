@@ -60,10 +60,28 @@ func (r *Reconciler) configMap(clientPass, capacityConfig string, log logr.Logge
 		log.Error(err, "setting bootstrap.servers in Cruise Control configuration failed", "config", bootstrapServers)
 	}
 
-	// Add Zookeeper configuration
-	zkConnect := zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())
-	if err = ccConfig.Set("zookeeper.connect", zkConnect); err != nil {
-		log.Error(err, "setting zookeeper.connect in Cruise Control configuration failed", "config", zkConnect)
+	if r.KafkaCluster.Spec.KRaftMode {
+		// Without ZooKeeper Cruise Control detects the broker failures through the Kafka admin client
+		if _, ok := ccConfig.Get("kafka.broker.failure.detection.enable"); !ok {
+			if err = ccConfig.Set("kafka.broker.failure.detection.enable", true); err != nil {
+				log.Error(err, "setting kafka.broker.failure.detection.enable in Cruise Control configuration failed")
+			}
+		}
+	} else {
+		// Add Zookeeper configuration
+		zkConnect := zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())
+		if err = ccConfig.Set("zookeeper.connect", zkConnect); err != nil {
+			log.Error(err, "setting zookeeper.connect in Cruise Control configuration failed", "config", zkConnect)
+		}
+	}
+
+	// Cruise Control samples the metrics from the topic the metrics reporters of the brokers produce to
+	if metricsTopic := cruiseControlMetricsTopic(r.KafkaCluster.Spec.ReadOnlyConfig); metricsTopic != cruiseControlTopicName {
+		if _, ok := ccConfig.Get("metric.reporter.topic"); !ok {
+			if err = ccConfig.Set("metric.reporter.topic", metricsTopic); err != nil {
+				log.Error(err, "setting metric.reporter.topic in Cruise Control configuration failed", "config", metricsTopic)
+			}
+		}
 	}
 
 	// Add SSL configuration
@@ -139,8 +157,10 @@ type JBODInvariantCapacityConfig struct {
 	Capacities []interface{} `json:"brokerCapacities"`
 }
 
-// GenerateCapacityConfig generates a CC capacity config with default values or returns the manually overridden value if it exists
-func GenerateCapacityConfig(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger, config *corev1.ConfigMap) (string, error) {
+// GenerateCapacityConfig generates a CC capacity config with default values or returns the manually overridden value if it exists.
+// The network capacities of the nodes are used for the brokers which do not set them in their network config.
+func GenerateCapacityConfig(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger, config *corev1.ConfigMap,
+	nodeNetworkCapacities map[string]NetworkCapacity) (string, error) {
 	var err error
 
 	log.Info("generating capacity config")
@@ -177,17 +197,22 @@ func GenerateCapacityConfig(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger,
 		}
 	}
 	// During cluster downscale the CR does not contain data for brokers being downscaled which is
-	// required to generate the proper capacity json for CC so we are reusing the old one.
+	// required to generate the proper capacity json for CC so we are reusing their old values.
 	// We can only remove brokers from capacity config when they were removed (pods deleted) from CC as well.
+	var previousCapacities map[string]interface{}
 	if config != nil {
 		if data, ok := config.Data["capacity.json"]; ok {
-			return data, err
+			previousCapacities, err = brokerCapacitiesById(data)
+			if err != nil {
+				return "", errors.WrapIf(err, "could not unmarshal the current broker capacity config")
+			}
 		}
 	}
 
 	// If there was no user provided config we shall generate all configuration or
 	// adding generated values to all Brokers not provided by the user.
-	brokerCapacities, err := appendGeneratedBrokerCapacities(kafkaCluster, log, userConfigBrokerIds)
+	brokerCapacities, err := appendGeneratedBrokerCapacities(kafkaCluster, log, userConfigBrokerIds, nodeNetworkCapacities,
+		previousCapacities)
 	if err != nil {
 		return "", err
 	}
@@ -201,7 +226,30 @@ func GenerateCapacityConfig(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger,
 	return string(result), err
 }
 
-func appendGeneratedBrokerCapacities(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger, userConfigBrokerIds []string) ([]interface{}, error) {
+// brokerCapacitiesById returns the broker capacities of the capacity config by the ID of their broker. They are kept
+// as they were rendered, so that the capacity config does not change by reusing them.
+func brokerCapacitiesById(data string) (map[string]interface{}, error) {
+	var capacityConfig struct {
+		Capacities []json.RawMessage `json:"brokerCapacities"`
+	}
+	if err := json.Unmarshal([]byte(data), &capacityConfig); err != nil {
+		return nil, err
+	}
+	capacities := make(map[string]interface{}, len(capacityConfig.Capacities))
+	for _, brokerCapacity := range capacityConfig.Capacities {
+		var id struct {
+			BrokerID string `json:"brokerId"`
+		}
+		if err := json.Unmarshal(brokerCapacity, &id); err != nil || id.BrokerID == "" {
+			continue
+		}
+		capacities[id.BrokerID] = brokerCapacity
+	}
+	return capacities, nil
+}
+
+func appendGeneratedBrokerCapacities(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger, userConfigBrokerIds []string,
+	nodeNetworkCapacities map[string]NetworkCapacity, previousCapacities map[string]interface{}) ([]interface{}, error) {
 	var brokerCapacities []interface{}
 
 	brokerIdFromStatus := make([]string, 0, len(kafkaCluster.Status.BrokersState))
@@ -234,17 +282,22 @@ func appendGeneratedBrokerCapacities(kafkaCluster *v1beta1.KafkaCluster, log log
 					Capacity: Capacity{
 						DISK:  brokerDisks,
 						CPU:   generateBrokerCPU(broker, kafkaCluster.Spec, log),
-						NWIN:  generateBrokerNetworkIn(broker, kafkaCluster.Spec, log),
-						NWOUT: generateBrokerNetworkOut(broker, kafkaCluster.Spec, log),
+						NWIN:  generateBrokerNetworkIn(broker, kafkaCluster.Spec, nodeNetworkCapacities[brokerId], log),
+						NWOUT: generateBrokerNetworkOut(broker, kafkaCluster.Spec, nodeNetworkCapacities[brokerId], log),
 					},
 					Doc: defaultDoc,
 				}
 			}
 		}
-		// When removing a broker it still needs to have values assigned in capacity config
-		// although it doesn't really matter what the values are, so we are setting defaults
-		// here, this way we don't have to deal with a universal default.
+		// When removing a broker it still needs to have values assigned in capacity config,
+		// the ones it had before are kept, otherwise defaults are set, this way we don't have
+		// to deal with a universal default.
 		if !brokerFoundInSpec {
+			if previousCapacity, ok := previousCapacities[brokerId]; ok {
+				log.V(1).Info("broker spec not found, keeping its current capacity config", "brokerId", brokerId)
+				brokerCapacities = append(brokerCapacities, previousCapacity)
+				continue
+			}
 			log.Info("broker spec not found, using default fallback")
 			brokerCapacity = generateDefaultBrokerCapacityWithId(brokerId)
 		}
@@ -272,7 +325,8 @@ func generateDefaultBrokerCapacityWithId(brokerId string) BrokerCapacity {
 	}
 }
 
-func generateBrokerNetworkIn(broker v1beta1.Broker, kafkaClusterSpec v1beta1.KafkaClusterSpec, log logr.Logger) string {
+func generateBrokerNetworkIn(broker v1beta1.Broker, kafkaClusterSpec v1beta1.KafkaClusterSpec, nodeCapacity NetworkCapacity,
+	log logr.Logger) string {
 	brokerConfig, err := broker.GetBrokerConfig(kafkaClusterSpec)
 	if err != nil {
 		log.V(warnLevel).Info("could not get incoming network resource limits falling back to default value")
@@ -281,12 +335,16 @@ func generateBrokerNetworkIn(broker v1beta1.Broker, kafkaClusterSpec v1beta1.Kaf
 	if brokerConfig.NetworkConfig != nil && brokerConfig.NetworkConfig.IncomingNetworkThroughPut != "" {
 		return brokerConfig.NetworkConfig.IncomingNetworkThroughPut
 	}
+	if nodeCapacity.In != "" {
+		return nodeCapacity.In
+	}
 
 	log.Info("incoming network throughput is not set falling back to default value")
 	return storageConfigNWINDefaultValue
 }
 
-func generateBrokerNetworkOut(broker v1beta1.Broker, kafkaClusterSpec v1beta1.KafkaClusterSpec, log logr.Logger) string {
+func generateBrokerNetworkOut(broker v1beta1.Broker, kafkaClusterSpec v1beta1.KafkaClusterSpec, nodeCapacity NetworkCapacity,
+	log logr.Logger) string {
 	brokerConfig, err := broker.GetBrokerConfig(kafkaClusterSpec)
 	if err != nil {
 		log.V(warnLevel).Info("could not get outgoing network resource limits falling back to default value")
@@ -295,6 +353,9 @@ func generateBrokerNetworkOut(broker v1beta1.Broker, kafkaClusterSpec v1beta1.Ka
 	if brokerConfig.NetworkConfig != nil && brokerConfig.NetworkConfig.OutgoingNetworkThroughPut != "" {
 		return brokerConfig.NetworkConfig.OutgoingNetworkThroughPut
 	}
+	if nodeCapacity.Out != "" {
+		return nodeCapacity.Out
+	}
 
 	log.Info("outgoing network throughput is not set falling back to default value")
 	return storageConfigNWOUTDefaultValue
@@ -307,7 +368,17 @@ func generateBrokerCPU(broker v1beta1.Broker, kafkaClusterSpec v1beta1.KafkaClus
 		return storageConfigCPUDefaultValue
 	}
 
-	return strconv.Itoa(int(brokerConfig.GetResources().Limits.Cpu().ScaledValue(-2)))
+	// The brokers without CPU limit can use at least the requested CPU
+	resources := brokerConfig.GetResources()
+	cpu := resources.Limits.Cpu()
+	if cpu.IsZero() {
+		cpu = resources.Requests.Cpu()
+	}
+	if cpu.IsZero() {
+		log.Info("neither cpu limit nor request is set falling back to default value")
+		return storageConfigCPUDefaultValue
+	}
+	return strconv.Itoa(int(cpu.ScaledValue(-2)))
 }
 
 func generateBrokerDisks(brokerState v1beta1.Broker, kafkaClusterSpec v1beta1.KafkaClusterSpec, log logr.Logger) (map[string]string, error) {
@@ -560,7 +560,7 @@ func TestGenerateCapacityConfig_JBOD(t *testing.T) {
 
 		t.Run(test.testName, func(t *testing.T) {
 			var actual CapacityConfig
-			rawStringActual, _ := GenerateCapacityConfig(&test.kafkaCluster, logr.Discard(), nil)
+			rawStringActual, _ := GenerateCapacityConfig(&test.kafkaCluster, logr.Discard(), nil, nil)
 			err := json.Unmarshal([]byte(rawStringActual), &actual)
 			if err != nil {
 				t.Error(err, "could not unmarshal actual json")
@@ -616,7 +616,7 @@ func TestReturnErrorStorageConfigLessThan1MB(t *testing.T) {
 		},
 	}
 
-	_, err := GenerateCapacityConfig(&kafkaCluster, logr.Discard(), nil)
+	_, err := GenerateCapacityConfig(&kafkaCluster, logr.Discard(), nil, nil)
 
 	if err == nil {
 		t.Error("Expected error to be thrown when storage config < 1MB")
@@ -999,7 +999,7 @@ func TestGenerateCapacityConfigWithUserProvidedInput(t *testing.T) {
 				},
 			}
 			var actual JBODInvariantCapacityConfig
-			rawStringActual, _ := GenerateCapacityConfig(&kafkaCluster, logr.Discard(), nil)
+			rawStringActual, _ := GenerateCapacityConfig(&kafkaCluster, logr.Discard(), nil, nil)
 			err := json.Unmarshal([]byte(rawStringActual), &actual)
 			if err != nil {
 				t.Error(err, "could not unmarshal actual json")
@@ -1017,3 +1017,117 @@ func TestGenerateCapacityConfigWithUserProvidedInput(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCapacityConfigDuringDownscale(t *testing.T) {
+	storageQuantity, _ := resource.ParseQuantity("10Gi")
+	cpuQuantity, _ := resource.ParseQuantity("3000m")
+
+	brokerConfig := func(storage resource.Quantity) *v1beta1.BrokerConfig {
+		return &v1beta1.BrokerConfig{
+			StorageConfigs: []v1beta1.StorageConfig{
+				{
+					MountPath: "/path1",
+					PvcSpec: &v1.PersistentVolumeClaimSpec{
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceStorage: storage},
+						},
+					},
+				},
+			},
+			Resources: &v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceCPU: cpuQuantity},
+			},
+		}
+	}
+	kafkaCluster := v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfig: brokerConfig(storageQuantity)},
+				{Id: 1, BrokerConfig: brokerConfig(storageQuantity)},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{"0": {}, "1": {}},
+		},
+	}
+
+	current, err := GenerateCapacityConfig(&kafkaCluster, logr.Discard(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &v1.ConfigMap{Data: map[string]string{"capacity.json": current}}
+
+	// broker 1 is being removed
+	kafkaCluster.Spec.Brokers = kafkaCluster.Spec.Brokers[:1]
+	actual, err := GenerateCapacityConfig(&kafkaCluster, logr.Discard(), config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != current {
+		t.Error("Expected the capacity config to stay the same:", current, ", got:", actual)
+	}
+
+	// the storage of broker 0 is extended while broker 1 is being removed
+	kafkaCluster.Spec.Brokers[0].BrokerConfig = brokerConfig(resource.MustParse("20Gi"))
+	actual, err = GenerateCapacityConfig(&kafkaCluster, logr.Discard(), config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var capacityConfig CapacityConfig
+	if err := json.Unmarshal([]byte(actual), &capacityConfig); err != nil {
+		t.Fatal(err, "could not unmarshal actual json")
+	}
+	expected := []BrokerCapacity{
+		{
+			BrokerID: "0",
+			Capacity: Capacity{DISK: map[string]string{"/path1/kafka": "21474"}, CPU: "300", NWIN: "125000", NWOUT: "125000"},
+			Doc:      defaultDoc,
+		},
+		{
+			BrokerID: "1",
+			Capacity: Capacity{DISK: map[string]string{"/path1/kafka": "10737"}, CPU: "300", NWIN: "125000", NWOUT: "125000"},
+			Doc:      defaultDoc,
+		},
+	}
+	if !reflect.DeepEqual(capacityConfig.BrokerCapacities, expected) {
+		t.Error("Expected:", expected, ", got:", capacityConfig.BrokerCapacities)
+	}
+}
+
+func TestGenerateBrokerCPU(t *testing.T) {
+	testCases := []struct {
+		testName  string
+		resources *v1.ResourceRequirements
+		expected  string
+	}{
+		{
+			testName: "cpu limit is set",
+			resources: &v1.ResourceRequirements{
+				Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			},
+			expected: "200",
+		},
+		{
+			testName: "only cpu request is set",
+			resources: &v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m")},
+			},
+			expected: "150",
+		},
+		{
+			testName:  "neither cpu limit nor request is set",
+			resources: &v1.ResourceRequirements{},
+			expected:  storageConfigCPUDefaultValue,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			broker := v1beta1.Broker{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{Resources: test.resources}}
+			if actual := generateBrokerCPU(broker, v1beta1.KafkaClusterSpec{}, logr.Discard()); actual != test.expected {
+				t.Errorf("expected cpu capacity: %s, got: %s", test.expected, actual)
+			}
+		})
+	}
+}
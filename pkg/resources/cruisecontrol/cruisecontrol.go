@@ -78,6 +78,11 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 
 	log.V(1).Info("Reconciling")
 
+	if r.KafkaCluster.Spec.IsCruiseControlDisabled() {
+		log.V(1).Info("Skipped, not deployed with the dev profile or the built-in rebalancer")
+		return nil
+	}
+
 	clientPass, err := r.getClientPassword()
 	if err != nil {
 		return err
@@ -118,7 +123,11 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 					)
 				}
 			}
-			capacityConfig, err := GenerateCapacityConfig(r.KafkaCluster, log, config)
+			nodeNetworkCapacities, err := r.getNodeNetworkCapacities(log)
+			if err != nil {
+				return errors.WrapIf(err, "failed to get the network capacities of the broker nodes")
+			}
+			capacityConfig, err := GenerateCapacityConfig(r.KafkaCluster, log, config, nodeNetworkCapacities)
 			if err != nil {
 				return errors.WrapIf(err, "failed to generate capacity config")
 			}
@@ -51,6 +51,8 @@ func (r *Reconciler) deployment(podAnnotations map[string]string) runtime.Object
 			MountPath: "/opt/cruise-control/config",
 		},
 	}...)
+	affinity := util.AddArchitectureAffinity(nil,
+		r.KafkaCluster.Spec.CruiseControlConfig.GetArchitectures(r.KafkaCluster.Spec.Architectures))
 
 	return &appsv1.Deployment{
 		ObjectMeta: templates.ObjectMeta(
@@ -74,6 +76,7 @@ func (r *Reconciler) deployment(podAnnotations map[string]string) runtime.Object
 					ImagePullSecrets:              r.KafkaCluster.Spec.CruiseControlConfig.GetImagePullSecrets(),
 					Tolerations:                   r.KafkaCluster.Spec.CruiseControlConfig.GetTolerations(),
 					NodeSelector:                  r.KafkaCluster.Spec.CruiseControlConfig.GetNodeSelector(),
+					Affinity:                      affinity,
 					TerminationGracePeriodSeconds: util.Int64Pointer(30),
 					InitContainers: append(initContainers, []corev1.Container{
 						{
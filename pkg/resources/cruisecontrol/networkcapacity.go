@@ -0,0 +1,106 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// NetworkCapacity holds the NW_IN and NW_OUT capacities of a broker in KB/s, empty values are not known
+type NetworkCapacity struct {
+	In  string
+	Out string
+}
+
+// getNodeNetworkCapacities returns the network capacities of the brokers, by broker ID, derived from the nodes
+// their pods are scheduled to. Brokers whose node has no known network capacity are left out.
+func (r *Reconciler) getNodeNetworkCapacities(log logr.Logger) (map[string]NetworkCapacity, error) {
+	config := r.KafkaCluster.Spec.CruiseControlConfig.NodeNetworkCapacity
+	if config == nil {
+		return nil, nil
+	}
+
+	podList := &corev1.PodList{}
+	err := r.Client.List(context.TODO(), podList,
+		client.InNamespace(r.KafkaCluster.Namespace),
+		client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name)),
+	)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "listing broker pods failed")
+	}
+
+	capacities := make(map[string]NetworkCapacity, len(podList.Items))
+	for _, pod := range podList.Items {
+		brokerId, ok := pod.Labels["brokerId"]
+		if !ok || pod.Spec.NodeName == "" {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errorfactory.New(errorfactory.APIFailure{}, err, "getting node of broker pod failed",
+				"brokerId", brokerId, "node", pod.Spec.NodeName)
+		}
+		capacity := nodeNetworkCapacity(node, config, log)
+		if capacity.In != "" || capacity.Out != "" {
+			capacities[brokerId] = capacity
+		}
+	}
+	return capacities, nil
+}
+
+// nodeNetworkCapacity returns the network capacity of the node, set by its annotations or by the network
+// capacity of its instance type
+func nodeNetworkCapacity(node *corev1.Node, config *v1beta1.NodeNetworkCapacityConfig, log logr.Logger) NetworkCapacity {
+	var capacity NetworkCapacity
+
+	instanceType, ok := node.Labels[corev1.LabelInstanceTypeStable]
+	if !ok {
+		instanceType = node.Labels[corev1.LabelInstanceType]
+	}
+	if throughput, ok := config.InstanceTypes[instanceType]; ok && instanceType != "" {
+		capacity.In, capacity.Out = throughput, throughput
+	}
+	if throughput, ok := node.Annotations[v1beta1.IncomingNetworkThroughputAnnotation]; ok {
+		capacity.In = throughput
+	}
+	if throughput, ok := node.Annotations[v1beta1.OutgoingNetworkThroughputAnnotation]; ok {
+		capacity.Out = throughput
+	}
+
+	// CC fails to load capacities which are not numbers
+	if _, err := strconv.ParseFloat(capacity.In, 64); capacity.In != "" && err != nil {
+		log.Info("ignoring invalid incoming network capacity of node", "node", node.Name, "capacity", capacity.In)
+		capacity.In = ""
+	}
+	if _, err := strconv.ParseFloat(capacity.Out, 64); capacity.Out != "" && err != nil {
+		log.Info("ignoring invalid outgoing network capacity of node", "node", node.Name, "capacity", capacity.Out)
+		capacity.Out = ""
+	}
+	return capacity
+}
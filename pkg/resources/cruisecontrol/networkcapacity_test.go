@@ -0,0 +1,103 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestNodeNetworkCapacity(t *testing.T) {
+	config := &v1beta1.NodeNetworkCapacityConfig{
+		InstanceTypes: map[string]string{
+			"m5.xlarge":  "1250000",
+			"m5.4xlarge": "1250000",
+			"r5.large":   "937500",
+		},
+	}
+
+	testCases := []struct {
+		testName         string
+		labels           map[string]string
+		annotations      map[string]string
+		expectedCapacity NetworkCapacity
+	}{
+		{
+			testName:         "instance type",
+			labels:           map[string]string{v1.LabelInstanceTypeStable: "r5.large"},
+			expectedCapacity: NetworkCapacity{In: "937500", Out: "937500"},
+		},
+		{
+			testName:         "deprecated instance type label",
+			labels:           map[string]string{v1.LabelInstanceType: "m5.xlarge"},
+			expectedCapacity: NetworkCapacity{In: "1250000", Out: "1250000"},
+		},
+		{
+			testName: "unknown instance type",
+			labels:   map[string]string{v1.LabelInstanceTypeStable: "c5.large"},
+		},
+		{
+			testName:         "annotations override the instance type",
+			labels:           map[string]string{v1.LabelInstanceTypeStable: "r5.large"},
+			annotations:      map[string]string{v1beta1.OutgoingNetworkThroughputAnnotation: "500000"},
+			expectedCapacity: NetworkCapacity{In: "937500", Out: "500000"},
+		},
+		{
+			testName:         "invalid annotation",
+			annotations:      map[string]string{v1beta1.IncomingNetworkThroughputAnnotation: "10Gbps"},
+			expectedCapacity: NetworkCapacity{},
+		},
+	}
+
+	for _, test := range testCases {
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: test.labels, Annotations: test.annotations}}
+		if capacity := nodeNetworkCapacity(node, config, logr.Discard()); capacity != test.expectedCapacity {
+			t.Errorf("%s: expected network capacity: %+v, got: %+v", test.testName, test.expectedCapacity, capacity)
+		}
+	}
+}
+
+func TestGenerateBrokerNetworkCapacityFromNode(t *testing.T) {
+	spec := v1beta1.KafkaClusterSpec{
+		BrokerConfigGroups: map[string]v1beta1.BrokerConfig{
+			"default": {},
+		},
+		Brokers: []v1beta1.Broker{
+			{Id: 0, BrokerConfigGroup: "default"},
+			{Id: 1, BrokerConfigGroup: "default", BrokerConfig: &v1beta1.BrokerConfig{
+				NetworkConfig: &v1beta1.NetworkConfig{IncomingNetworkThroughPut: "200000"},
+			}},
+		},
+	}
+	nodeCapacity := NetworkCapacity{In: "937500", Out: "937500"}
+
+	if in := generateBrokerNetworkIn(spec.Brokers[0], spec, nodeCapacity, logr.Discard()); in != "937500" {
+		t.Errorf("expected the incoming network capacity of the node, got: %s", in)
+	}
+	if in := generateBrokerNetworkIn(spec.Brokers[1], spec, nodeCapacity, logr.Discard()); in != "200000" {
+		t.Errorf("expected the incoming network capacity of the broker config, got: %s", in)
+	}
+	if out := generateBrokerNetworkOut(spec.Brokers[1], spec, nodeCapacity, logr.Discard()); out != "937500" {
+		t.Errorf("expected the outgoing network capacity of the node, got: %s", out)
+	}
+	if out := generateBrokerNetworkOut(spec.Brokers[0], spec, NetworkCapacity{}, logr.Discard()); out != storageConfigNWOUTDefaultValue {
+		t.Errorf("expected the default outgoing network capacity, got: %s", out)
+	}
+}
@@ -34,6 +34,7 @@ import (
 
 const (
 	ccMetricTopicAutoCreate             = "cruise.control.metrics.topic.auto.create"
+	ccMetricTopic                       = "cruise.control.metrics.topic"
 	cruiseControlTopicFormat            = "%s-cruise-control-topic"
 	cruiseControlTopicName              = "__CruiseControlMetrics"
 	cruiseControlTopicPartitions        = 12
@@ -60,7 +61,7 @@ func newCruiseControlTopic(cluster *v1beta1.KafkaCluster) *v1alpha1.KafkaTopic {
 			cluster,
 		),
 		Spec: v1alpha1.KafkaTopicSpec{
-			Name:              cruiseControlTopicName,
+			Name:              cruiseControlMetricsTopic(cluster.Spec.ReadOnlyConfig),
 			Partitions:        topicPartitions,
 			ReplicationFactor: topicReplicationFactor,
 			ClusterRef: v1alpha1.ClusterReference{
@@ -71,6 +72,17 @@ func newCruiseControlTopic(cluster *v1beta1.KafkaCluster) *v1alpha1.KafkaTopic {
 	}
 }
 
+// cruiseControlMetricsTopic returns the name of the topic the Cruise Control metrics reporters of the brokers
+// produce to, it is set by cruise.control.metrics.topic in the read-only config of the cluster
+func cruiseControlMetricsTopic(readOnlyConfig string) string {
+	if config, err := properties.NewFromString(readOnlyConfig); err == nil {
+		if topic, ok := config.Get(ccMetricTopic); ok && topic.Value() != "" {
+			return topic.Value()
+		}
+	}
+	return cruiseControlTopicName
+}
+
 func generateCCTopic(cluster *v1beta1.KafkaCluster, client client.Client, log logr.Logger) error {
 	readOnlyConfigProperties, err := properties.NewFromString(cluster.Spec.ReadOnlyConfig)
 	if err != nil {
@@ -0,0 +1,49 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"testing"
+)
+
+func TestCruiseControlMetricsTopic(t *testing.T) {
+	testCases := []struct {
+		testName       string
+		readOnlyConfig string
+		expected       string
+	}{
+		{
+			testName:       "default metrics topic",
+			readOnlyConfig: "auto.create.topics.enable=false",
+			expected:       "__CruiseControlMetrics",
+		},
+		{
+			testName:       "metrics topic set in the read-only config",
+			readOnlyConfig: "cruise.control.metrics.topic=__CruiseControlMetrics-kafka-b",
+			expected:       "__CruiseControlMetrics-kafka-b",
+		},
+		{
+			testName:       "empty metrics topic in the read-only config",
+			readOnlyConfig: "cruise.control.metrics.topic=",
+			expected:       "__CruiseControlMetrics",
+		},
+	}
+
+	for _, test := range testCases {
+		if actual := cruiseControlMetricsTopic(test.readOnlyConfig); actual != test.expected {
+			t.Errorf("test case %q: expected %q, got %q", test.testName, test.expected, actual)
+		}
+	}
+}
@@ -50,6 +50,11 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 
 	log.V(1).Info("Reconciling")
 
+	if r.KafkaCluster.Spec.IsCruiseControlDisabled() {
+		log.V(1).Info("Skipped, not deployed with the dev profile or the built-in rebalancer")
+		return nil
+	}
+
 	if r.KafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint == "" {
 		o := r.configMap()
 		err := k8sutil.Reconcile(log, r.Client, o, r.KafkaCluster)
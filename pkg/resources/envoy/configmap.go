@@ -36,6 +36,7 @@ import (
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -209,6 +210,17 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 	var listeners []*envoylistener.Listener
 	var clusters []*envoycluster.Cluster
 
+	downstreamTLS, err := downstreamTransportSocket(ingressConfig.EnvoyConfig)
+	if err != nil {
+		log.Error(err, "could not marshall envoy downstream tls config")
+		return ""
+	}
+	tlsListenerFilters, err := listenerFilters(ingressConfig.EnvoyConfig)
+	if err != nil {
+		log.Error(err, "could not marshall envoy tls_inspector config")
+		return ""
+	}
+
 	for _, brokerId := range util.GetBrokerIdsFromStatusAndSpec(kc.Status.BrokersState, kc.Spec.Brokers, log) {
 		brokerConfig, err := kafkautils.GatherBrokerConfigIfAvailable(kc.Spec, brokerId)
 		if err != nil {
@@ -229,6 +241,11 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 				log.Error(err, "could not marshall envoy tcp_proxy config")
 				return ""
 			}
+			upstreamTLS, err := upstreamTransportSocket(ingressConfig.EnvoyConfig, generateAddressValue(kc, brokerId))
+			if err != nil {
+				log.Error(err, "could not marshall envoy upstream tls config")
+				return ""
+			}
 			listeners = append(listeners, &envoylistener.Listener{
 				Address: &envoycore.Address{
 					Address: &envoycore.Address_SocketAddress{
@@ -240,8 +257,11 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 						},
 					},
 				},
+				ListenerFilters: tlsListenerFilters,
 				FilterChains: []*envoylistener.FilterChain{
 					{
+						FilterChainMatch: filterChainMatch(ingressConfig.EnvoyConfig),
+						TransportSocket:  downstreamTLS,
 						Filters: []*envoylistener.Filter{
 							{
 								Name: wellknown.TCPProxy,
@@ -259,6 +279,7 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 				ConnectTimeout:       &durationpb.Duration{Seconds: 1},
 				ClusterDiscoveryType: &envoycluster.Cluster_Type{Type: envoycluster.Cluster_STRICT_DNS},
 				LbPolicy:             envoycluster.Cluster_ROUND_ROBIN,
+				TransportSocket:      upstreamTLS,
 				// disable circuit breakingL:
 				// https://www.envoyproxy.io/docs/envoy/latest/faq/load_balancing/disable_circuit_breaking
 				CircuitBreakers: &envoycluster.CircuitBreakers{
@@ -330,8 +351,11 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 				},
 			},
 		},
+		ListenerFilters: tlsListenerFilters,
 		FilterChains: []*envoylistener.FilterChain{
 			{
+				FilterChainMatch: filterChainMatch(ingressConfig.EnvoyConfig),
+				TransportSocket:  downstreamTLS,
 				Filters: []*envoylistener.Filter{
 					{
 						Name: wellknown.TCPProxy,
@@ -351,6 +375,21 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 	}
 	listeners = append(listeners, healthCheckListener)
 
+	upstreamTLS, err := upstreamTransportSocket(ingressConfig.EnvoyConfig, generateAnyCastAddressValue(kc))
+	if err != nil {
+		log.Error(err, "could not marshall envoy upstream tls config")
+		return ""
+	}
+	var healthCheckTransportSockets []*envoycluster.Cluster_TransportSocketMatch
+	var healthCheckTransportSocketCriteria *structpb.Struct
+	if upstreamTLS != nil {
+		healthCheckTransportSockets, healthCheckTransportSocketCriteria, err = healthCheckTransportSocketMatches()
+		if err != nil {
+			log.Error(err, "could not marshall envoy health-check transport socket config")
+			return ""
+		}
+	}
+
 	clusters = append(clusters, &envoycluster.Cluster{
 		Name:                      envoyutils.AllBrokerEnvoyConfigName,
 		ConnectTimeout:            &durationpb.Duration{Seconds: 1},
@@ -365,6 +404,8 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 				UnhealthyThreshold: wrapperspb.UInt32(2),
 				HealthyThreshold:   wrapperspb.UInt32(1),
 				EventLogPath:       "/dev/stdout",
+				// the metrics endpoint of the brokers is plaintext even if the connections are re-encrypted
+				TransportSocketMatchCriteria: healthCheckTransportSocketCriteria,
 				HealthChecker: &envoycore.HealthCheck_HttpHealthCheck_{
 					HttpHealthCheck: &envoycore.HealthCheck_HttpHealthCheck{
 						Path: kafka.MetricsHealthCheck,
@@ -372,8 +413,10 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 				},
 			},
 		},
-		ClusterDiscoveryType: &envoycluster.Cluster_Type{Type: envoycluster.Cluster_STRICT_DNS},
-		LbPolicy:             envoycluster.Cluster_ROUND_ROBIN,
+		ClusterDiscoveryType:   &envoycluster.Cluster_Type{Type: envoycluster.Cluster_STRICT_DNS},
+		LbPolicy:               envoycluster.Cluster_ROUND_ROBIN,
+		TransportSocket:        upstreamTLS,
+		TransportSocketMatches: healthCheckTransportSockets,
 		// disable circuit breakingL:
 		// https://www.envoyproxy.io/docs/envoy/latest/faq/load_balancing/disable_circuit_breaking
 		CircuitBreakers: &envoycluster.CircuitBreakers{
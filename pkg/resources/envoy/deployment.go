@@ -66,10 +66,27 @@ func (r *Reconciler) deployment(log logr.Logger, extListener v1beta1.ExternalLis
 		},
 	}
 
+	secretVolumes, secretVolumeMounts := tlsVolumes(r.KafkaCluster, extListener, ingressConfig.EnvoyConfig)
+	volumes = append(volumes, secretVolumes...)
+	volumeMounts = append(volumeMounts, secretVolumeMounts...)
+
+	podAnnotations := generatePodAnnotations(r.KafkaCluster, extListener, ingressConfig, ingressConfigName,
+		defaultIngressConfigName, log)
+	if tlsHash := r.tlsSecretsHash(log, extListener, ingressConfig.EnvoyConfig); tlsHash != "" {
+		podAnnotations[tlsSecretsHashAnnotation] = tlsHash
+	}
+
 	arguments := []string{"-c", "/etc/envoy/envoy.yaml"}
 	if ingressConfig.EnvoyConfig.GetConcurrency() > 0 {
 		arguments = append(arguments, "--concurrency", strconv.Itoa(int(ingressConfig.EnvoyConfig.GetConcurrency())))
 	}
+	// the replicas are managed by the horizontal pod autoscaler when autoscaling is enabled
+	var replicas *int32
+	if !ingressConfig.EnvoyConfig.IsAutoscalingEnabled() {
+		replicas = util.Int32Pointer(ingressConfig.EnvoyConfig.GetReplicas())
+	}
+	affinity := util.AddArchitectureAffinity(ingressConfig.EnvoyConfig.GetAffinity(),
+		ingressConfig.EnvoyConfig.GetArchitectures(r.KafkaCluster.Spec.Architectures))
 
 	return &appsv1.Deployment{
 		ObjectMeta: templates.ObjectMetaWithAnnotations(
@@ -81,19 +98,18 @@ func (r *Reconciler) deployment(log logr.Logger, extListener v1beta1.ExternalLis
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labelsForEnvoyIngress(r.KafkaCluster.GetName(), eListenerLabelName),
 			},
-			Replicas: util.Int32Pointer(ingressConfig.EnvoyConfig.GetReplicas()),
+			Replicas: replicas,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: templates.ObjectMetaLabels(r.KafkaCluster, labelsForEnvoyIngress(r.KafkaCluster.GetName(), eListenerLabelName)),
-					Annotations: generatePodAnnotations(r.KafkaCluster, extListener, ingressConfig, ingressConfigName,
-						defaultIngressConfigName, log),
+					Labels:      templates.ObjectMetaLabels(r.KafkaCluster, labelsForEnvoyIngress(r.KafkaCluster.GetName(), eListenerLabelName)),
+					Annotations: podAnnotations,
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName:        ingressConfig.EnvoyConfig.GetServiceAccount(),
 					ImagePullSecrets:          ingressConfig.EnvoyConfig.GetImagePullSecrets(),
 					Tolerations:               ingressConfig.EnvoyConfig.GetTolerations(),
 					NodeSelector:              ingressConfig.EnvoyConfig.GetNodeSelector(),
-					Affinity:                  ingressConfig.EnvoyConfig.GetAffinity(),
+					Affinity:                  affinity,
 					TopologySpreadConstraints: ingressConfig.EnvoyConfig.GetTopologySpreadConstaints(),
 					Containers: []corev1.Container{
 						{
@@ -54,8 +54,13 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 
 	log.V(1).Info("Reconciling")
 
-	if r.KafkaCluster.Spec.GetIngressController() == envoyutils.IngressControllerName {
-		for _, eListener := range r.KafkaCluster.Spec.ListenersConfig.ExternalListeners {
+	if r.KafkaCluster.Spec.IsDevProfileEnabled() {
+		log.V(1).Info("Skipped, not deployed with the dev profile")
+		return nil
+	}
+
+	for _, eListener := range r.KafkaCluster.Spec.ListenersConfig.ExternalListeners {
+		if eListener.GetIngressController(r.KafkaCluster.Spec.GetIngressController()) == envoyutils.IngressControllerName {
 			if eListener.GetAccessMethod() == corev1.ServiceTypeLoadBalancer {
 				ingressConfigs, defaultControllerName, err := util.GetIngressConfigs(r.KafkaCluster.Spec, eListener)
 				if err != nil {
@@ -83,6 +88,15 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 							return err
 						}
 					}
+
+					if ingressConfig.EnvoyConfig.IsAutoscalingEnabled() {
+						o := r.horizontalPodAutoscaler(log, eListener, ingressConfig, name, defaultControllerName)
+						if err := k8sutil.Reconcile(log, r.Client, o, r.KafkaCluster); err != nil {
+							return err
+						}
+					} else if err := r.deleteHorizontalPodAutoscaler(eListener, ingressConfig, name); err != nil {
+						return err
+					}
 				}
 			}
 		}
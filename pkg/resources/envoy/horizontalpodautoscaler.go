@@ -0,0 +1,111 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util"
+	envoyutils "github.com/banzaicloud/koperator/pkg/util/envoy"
+)
+
+const horizontalPodAutoscalerSuffix = "-hpa"
+
+func (r *Reconciler) horizontalPodAutoscaler(log logr.Logger, extListener v1beta1.ExternalListenerConfig,
+	ingressConfig v1beta1.IngressConfig, ingressConfigName, defaultIngressConfigName string) runtime.Object {
+	eListenerLabelName := util.ConstructEListenerLabelName(ingressConfigName, extListener.Name)
+
+	var deploymentName string = util.GenerateEnvoyResourceName(envoyutils.EnvoyDeploymentName, envoyutils.EnvoyDeploymentNameWithScope,
+		extListener, ingressConfig, ingressConfigName, r.KafkaCluster.GetName())
+
+	autoscaling := ingressConfig.EnvoyConfig.Autoscaling
+
+	var metrics []autoscalingv2beta2.MetricSpec
+	if target := autoscaling.GetTargetCPUUtilizationPercentage(); target != nil {
+		metrics = append(metrics, autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:               autoscalingv2beta2.UtilizationMetricType,
+					AverageUtilization: util.Int32Pointer(*target),
+				},
+			},
+		})
+	}
+	if autoscaling.TargetConnectionsPerPod != nil {
+		metrics = append(metrics, autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.PodsMetricSourceType,
+			Pods: &autoscalingv2beta2.PodsMetricSource{
+				Metric: autoscalingv2beta2.MetricIdentifier{
+					Name: autoscaling.GetConnectionsMetricName(),
+				},
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:         autoscalingv2beta2.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(int64(*autoscaling.TargetConnectionsPerPod), resource.DecimalSI),
+				},
+			},
+		})
+	}
+
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: templates.ObjectMetaWithAnnotations(
+			deploymentName+horizontalPodAutoscalerSuffix,
+			labelsForEnvoyIngress(r.KafkaCluster.GetName(), eListenerLabelName),
+			ingressConfig.EnvoyConfig.GetAnnotations(),
+			r.KafkaCluster,
+		),
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deploymentName,
+			},
+			MinReplicas: util.Int32Pointer(ingressConfig.EnvoyConfig.GetMinReplicas()),
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+}
+
+// deleteHorizontalPodAutoscaler removes the HorizontalPodAutoscaler of an Envoy Deployment whose autoscaling
+// has been disabled, so that the Deployment is scaled to the configured replicas again
+func (r *Reconciler) deleteHorizontalPodAutoscaler(extListener v1beta1.ExternalListenerConfig,
+	ingressConfig v1beta1.IngressConfig, ingressConfigName string) error {
+	var deploymentName string = util.GenerateEnvoyResourceName(envoyutils.EnvoyDeploymentName, envoyutils.EnvoyDeploymentNameWithScope,
+		extListener, ingressConfig, ingressConfigName, r.KafkaCluster.GetName())
+
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentName + horizontalPodAutoscalerSuffix,
+			Namespace: r.KafkaCluster.GetNamespace(),
+		},
+	}
+	if err := r.Client.Delete(context.TODO(), hpa); err != nil && !apierrors.IsNotFound(err) {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "deleting envoy horizontal pod autoscaler failed", "name", hpa.GetName())
+	}
+	return nil
+}
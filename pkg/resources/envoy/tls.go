@@ -0,0 +1,245 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	envoycluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoycore "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoylistener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoytlsinspector "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/tls_inspector/v3"
+	envoyrawbuffer "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/raw_buffer/v3"
+	envoytls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+const (
+	tlsVolumeName        = "envoy-tls"
+	tlsMountPath         = "/etc/envoy-tls"
+	upstreamCAVolumeName = "envoy-upstream-ca"
+	upstreamCAMountPath  = "/etc/envoy-upstream-ca"
+
+	tlsSecretsHashAnnotation = "envoy.tls.hash"
+
+	// healthCheckTransportSocketMatch selects the plaintext transport socket of the all-brokers cluster for the
+	// health checks of the metrics endpoint when the connections to the brokers are re-encrypted
+	healthCheckTransportSocketMatch = "healthCheck"
+)
+
+// downstreamTransportSocket returns the transport socket terminating the TLS of the client connections, nil when
+// the connections are passed through
+func downstreamTransportSocket(envoyConfig *v1beta1.EnvoyConfig) (*envoycore.TransportSocket, error) {
+	if !envoyConfig.TerminatesTLS() {
+		return nil, nil
+	}
+	tlsContext := &envoytls.DownstreamTlsContext{
+		CommonTlsContext: &envoytls.CommonTlsContext{
+			TlsCertificates: []*envoytls.TlsCertificate{
+				{
+					CertificateChain: fileDataSource(tlsMountPath, corev1.TLSCertKey),
+					PrivateKey:       fileDataSource(tlsMountPath, corev1.TLSPrivateKeyKey),
+				},
+			},
+		},
+	}
+	return tlsTransportSocket(tlsContext)
+}
+
+// upstreamTransportSocket returns the transport socket opening TLS connections to the given broker address, nil
+// unless the connections are re-encrypted
+func upstreamTransportSocket(envoyConfig *v1beta1.EnvoyConfig, address string) (*envoycore.TransportSocket, error) {
+	if envoyConfig.GetTLSMode() != v1beta1.EnvoyTLSModeReencrypt {
+		return nil, nil
+	}
+	tlsContext := &envoytls.UpstreamTlsContext{
+		Sni: address,
+		CommonTlsContext: &envoytls.CommonTlsContext{
+			ValidationContextType: &envoytls.CommonTlsContext_ValidationContext{
+				ValidationContext: &envoytls.CertificateValidationContext{
+					TrustedCa: fileDataSource(upstreamCAMountPath, v1alpha1.CoreCACertKey),
+				},
+			},
+		},
+	}
+	return tlsTransportSocket(tlsContext)
+}
+
+// healthCheckTransportSocketMatches returns the transport socket matches making the health checks of a cluster
+// with re-encrypted connections use plaintext, since the metrics endpoint of the brokers does not serve TLS
+func healthCheckTransportSocketMatches() ([]*envoycluster.Cluster_TransportSocketMatch, *structpb.Struct, error) {
+	pbstRawBuffer, err := anypb.New(&envoyrawbuffer.RawBuffer{})
+	if err != nil {
+		return nil, nil, err
+	}
+	criteria, err := structpb.NewStruct(map[string]interface{}{healthCheckTransportSocketMatch: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*envoycluster.Cluster_TransportSocketMatch{
+		{
+			Name:  "plaintext-health-check",
+			Match: criteria,
+			TransportSocket: &envoycore.TransportSocket{
+				Name:       wellknown.TransportSocketRawBuffer,
+				ConfigType: &envoycore.TransportSocket_TypedConfig{TypedConfig: pbstRawBuffer},
+			},
+		},
+	}, criteria, nil
+}
+
+// filterChainMatch returns the SNI server names the client connections are accepted for, nil when all the
+// connections are accepted
+func filterChainMatch(envoyConfig *v1beta1.EnvoyConfig) *envoylistener.FilterChainMatch {
+	if envoyConfig.TLS == nil || len(envoyConfig.TLS.ServerNames) == 0 {
+		return nil
+	}
+	return &envoylistener.FilterChainMatch{
+		ServerNames: envoyConfig.TLS.ServerNames,
+	}
+}
+
+// listenerFilters returns the TLS inspector listener filter extracting the SNI server name the filter chains are
+// matched by when server names are set
+func listenerFilters(envoyConfig *v1beta1.EnvoyConfig) ([]*envoylistener.ListenerFilter, error) {
+	if filterChainMatch(envoyConfig) == nil {
+		return nil, nil
+	}
+	pbstTLSInspector, err := anypb.New(&envoytlsinspector.TlsInspector{})
+	if err != nil {
+		return nil, err
+	}
+	return []*envoylistener.ListenerFilter{
+		{
+			Name:       wellknown.TlsInspector,
+			ConfigType: &envoylistener.ListenerFilter_TypedConfig{TypedConfig: pbstTLSInspector},
+		},
+	}, nil
+}
+
+func tlsTransportSocket(tlsContext proto.Message) (*envoycore.TransportSocket, error) {
+	pbstTLSContext, err := anypb.New(tlsContext)
+	if err != nil {
+		return nil, err
+	}
+	return &envoycore.TransportSocket{
+		Name:       wellknown.TransportSocketTls,
+		ConfigType: &envoycore.TransportSocket_TypedConfig{TypedConfig: pbstTLSContext},
+	}, nil
+}
+
+func fileDataSource(dir, key string) *envoycore.DataSource {
+	return &envoycore.DataSource{
+		Specifier: &envoycore.DataSource_Filename{Filename: fmt.Sprintf("%s/%s", dir, key)},
+	}
+}
+
+// upstreamCASecretName returns the name of the Secret holding the CA certificate the brokers are validated by
+func upstreamCASecretName(kc *v1beta1.KafkaCluster, extListener v1beta1.ExternalListenerConfig,
+	envoyConfig *v1beta1.EnvoyConfig) string {
+	if envoyConfig.TLS != nil && envoyConfig.TLS.UpstreamCASecretName != "" {
+		return envoyConfig.TLS.UpstreamCASecretName
+	}
+	if extListener.GetServerSSLCertSecretName() != "" {
+		return extListener.GetServerSSLCertSecretName()
+	}
+	return fmt.Sprintf(pkicommon.BrokerServerCertTemplate, kc.GetName())
+}
+
+// tlsVolumes returns the volumes and mounts of the certificates Envoy uses for the TLS of the connections
+func tlsVolumes(kc *v1beta1.KafkaCluster, extListener v1beta1.ExternalListenerConfig,
+	envoyConfig *v1beta1.EnvoyConfig) ([]corev1.Volume, []corev1.VolumeMount) {
+	if !envoyConfig.TerminatesTLS() {
+		return nil, nil
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: tlsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: envoyConfig.TLS.SecretName},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      tlsVolumeName,
+			MountPath: tlsMountPath,
+			ReadOnly:  true,
+		},
+	}
+	if envoyConfig.GetTLSMode() == v1beta1.EnvoyTLSModeReencrypt {
+		volumes = append(volumes, corev1.Volume{
+			Name: upstreamCAVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: upstreamCASecretName(kc, extListener, envoyConfig),
+					Items:      []corev1.KeyToPath{{Key: v1alpha1.CoreCACertKey, Path: v1alpha1.CoreCACertKey}},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      upstreamCAVolumeName,
+			MountPath: upstreamCAMountPath,
+			ReadOnly:  true,
+		})
+	}
+	return volumes, volumeMounts
+}
+
+// tlsSecretsHash returns the hash of the certificates Envoy uses, so that the Envoy pods are rolled when they are
+// renewed as Envoy reads them only on startup. Secrets which can not be read are left out
+func (r *Reconciler) tlsSecretsHash(log logr.Logger, extListener v1beta1.ExternalListenerConfig,
+	envoyConfig *v1beta1.EnvoyConfig) string {
+	if !envoyConfig.TerminatesTLS() {
+		return ""
+	}
+	secretNames := []string{envoyConfig.TLS.SecretName}
+	if envoyConfig.GetTLSMode() == v1beta1.EnvoyTLSModeReencrypt {
+		secretNames = append(secretNames, upstreamCASecretName(r.KafkaCluster, extListener, envoyConfig))
+	}
+	hash := sha256.New()
+	for _, secretName := range secretNames {
+		secret := &corev1.Secret{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: r.KafkaCluster.GetNamespace(), Name: secretName}, secret)
+		if err != nil {
+			log.V(1).Info("could not get envoy TLS secret", "name", secretName, "error", err.Error())
+			continue
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			hash.Write([]byte(key))
+			hash.Write(secret.Data[key])
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,98 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestGenerateEnvoyConfigTLS(t *testing.T) {
+	testCases := []struct {
+		testName    string
+		tls         *v1beta1.EnvoyTLSConfig
+		contains    []string
+		notContains []string
+	}{
+		{
+			testName:    "passthrough",
+			notContains: []string{"transportSocket", "listenerFilters", "serverNames"},
+		},
+		{
+			testName: "passthrough with server names",
+			tls: &v1beta1.EnvoyTLSConfig{
+				Mode:        v1beta1.EnvoyTLSModePassthrough,
+				ServerNames: []string{"*.kafka.example.com"},
+			},
+			contains:    []string{"envoy.filters.listener.tls_inspector", "'*.kafka.example.com'"},
+			notContains: []string{"transportSocket"},
+		},
+		{
+			testName: "terminate",
+			tls: &v1beta1.EnvoyTLSConfig{
+				Mode:       v1beta1.EnvoyTLSModeTerminate,
+				SecretName: "kafka-public-tls",
+			},
+			contains:    []string{"DownstreamTlsContext", tlsMountPath + "/tls.crt", tlsMountPath + "/tls.key"},
+			notContains: []string{"UpstreamTlsContext", "transportSocketMatches"},
+		},
+		{
+			testName: "reencrypt",
+			tls: &v1beta1.EnvoyTLSConfig{
+				Mode:       v1beta1.EnvoyTLSModeReencrypt,
+				SecretName: "kafka-public-tls",
+			},
+			contains: []string{"DownstreamTlsContext", "UpstreamTlsContext", upstreamCAMountPath + "/ca.crt",
+				"sni: kafka-0.kafka.svc.cluster.local", "transportSocketMatchCriteria", "envoy.transport_sockets.raw_buffer"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			cluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec: v1beta1.KafkaClusterSpec{
+					Brokers: []v1beta1.Broker{{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{}}},
+				},
+			}
+			listener := v1beta1.ExternalListenerConfig{
+				CommonListenerSpec:   v1beta1.CommonListenerSpec{Name: "external", ContainerPort: 9094},
+				ExternalStartingPort: 19090,
+			}
+			ingressConfig := v1beta1.IngressConfig{EnvoyConfig: &v1beta1.EnvoyConfig{TLS: test.tls}}
+
+			config := GenerateEnvoyConfig(cluster, listener, ingressConfig, util.IngressConfigGlobalName, "", logr.Discard())
+			if config == "" {
+				t.Fatal("envoy config could not be generated")
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(config, s) {
+					t.Errorf("expected the envoy config to contain %q:\n%s", s, config)
+				}
+			}
+			for _, s := range test.notContains {
+				if strings.Contains(config, s) {
+					t.Errorf("expected the envoy config not to contain %q:\n%s", s, config)
+				}
+			}
+		})
+	}
+}
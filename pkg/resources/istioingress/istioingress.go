@@ -62,8 +62,8 @@ func New(client client.Client, cluster *v1beta1.KafkaCluster) *Reconciler {
 func (r *Reconciler) Reconcile(log logr.Logger) error {
 	log = log.WithValues("component", componentName)
 	log.V(1).Info("Reconciling")
-	if r.KafkaCluster.Spec.GetIngressController() == istioingress.IngressControllerName {
-		for _, eListener := range r.KafkaCluster.Spec.ListenersConfig.ExternalListeners {
+	for _, eListener := range r.KafkaCluster.Spec.ListenersConfig.ExternalListeners {
+		if eListener.GetIngressController(r.KafkaCluster.Spec.GetIngressController()) == istioingress.IngressControllerName {
 			if eListener.GetAccessMethod() == corev1.ServiceTypeLoadBalancer {
 				if r.KafkaCluster.Spec.IstioControlPlane == nil {
 					log.Error(errors.NewPlain("reference to Istio Control Plane is missing"), "skip external listener reconciliation", "external listener", eListener.Name)
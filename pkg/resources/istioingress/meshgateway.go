@@ -20,6 +20,7 @@ import (
 	istioOperatorApi "github.com/banzaicloud/istio-operator/api/v2/v1alpha1"
 
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -67,6 +68,20 @@ func (r *Reconciler) meshgateway(log logr.Logger, externalListenerConfig v1beta1
 					Min:   util.Int32Pointer(ingressConfig.IstioIngressConfig.GetReplicas()),
 					Max:   util.Int32Pointer(ingressConfig.IstioIngressConfig.GetReplicas()),
 				},
+				PodMetadata: &istioOperatorApi.K8SObjectMeta{
+					Labels: map[string]string{
+						istioOperatorApi.RevisionedAutoInjectionLabel: r.KafkaCluster.Spec.IstioControlPlane.GetNamespacedRevision(),
+					},
+				},
+				// The gateway pods of a new Istio revision are started before the old ones are stopped
+				// to keep the external listeners available while the mesh is upgraded
+				DeploymentStrategy: &istioOperatorApi.DeploymentStrategy{
+					Type: string(appsv1.RollingUpdateDeploymentStrategyType),
+					RollingUpdate: &istioOperatorApi.DeploymentStrategy_RollingUpdateDeployment{
+						MaxUnavailable: &istioOperatorApi.IntOrString{IntOrString: intstr.FromInt(0)},
+						MaxSurge:       &istioOperatorApi.IntOrString{IntOrString: intstr.FromInt(1)},
+					},
+				},
 			},
 			Service: &istioOperatorApi.Service{
 				Metadata: &istioOperatorApi.K8SObjectMeta{
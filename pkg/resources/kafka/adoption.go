@@ -0,0 +1,225 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// internalTopicPrefix is the prefix of the internal topics of Kafka and Cruise Control which are not imported
+const internalTopicPrefix = "__"
+
+var invalidResourceNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// adoptBrokers initializes the status of the broker pods of an adopted cluster created outside of the operator, the
+// brokers are considered to be in sync and to hold their data already so that they are neither restarted nor
+// rebalanced, and their removal goes through the graceful downscale
+func (r *Reconciler) adoptBrokers(log logr.Logger) error {
+	podList := &corev1.PodList{}
+	err := r.Client.List(context.TODO(), podList,
+		client.InNamespace(r.KafkaCluster.Namespace),
+		client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name)),
+	)
+	if err != nil {
+		return errors.WrapIf(err, "failed to list broker pods of the adopted cluster")
+	}
+
+	brokerIDs := brokersToAdopt(podList.Items, r.KafkaCluster.Status.BrokersState)
+	if len(brokerIDs) == 0 {
+		return nil
+	}
+
+	log.Info("adopting brokers", "brokers", brokerIDs)
+	for _, state := range []interface{}{
+		v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded},
+		v1beta1.ConfigInSync,
+		v1beta1.PerBrokerConfigInSync,
+	} {
+		if err := k8sutil.UpdateBrokerStatus(r.Client, brokerIDs, r.KafkaCluster, state, log); err != nil {
+			return errors.WrapIfWithDetails(err, "could not update status of adopted brokers", "brokers", brokerIDs)
+		}
+	}
+	return nil
+}
+
+// brokersToAdopt returns the sorted IDs of the broker pods without broker status
+func brokersToAdopt(pods []corev1.Pod, brokersState map[string]v1beta1.BrokerState) []string {
+	var brokerIDs []string
+	for _, pod := range pods {
+		brokerID, ok := pod.Labels["brokerId"]
+		if !ok || k8sutil.IsMarkedForDeletion(pod.ObjectMeta) {
+			continue
+		}
+		if _, ok := brokersState[brokerID]; !ok {
+			brokerIDs = append(brokerIDs, brokerID)
+		}
+	}
+	sort.Strings(brokerIDs)
+	return brokerIDs
+}
+
+// holdBackAdoptionChange records a change not applied until the adoption of the cluster is approved
+func (r *Reconciler) holdBackAdoptionChange(log logr.Logger, change string) {
+	log.Info("change held back until the adoption of the cluster is approved", "change", change)
+	r.adoptionHeldBackChanges = append(r.adoptionHeldBackChanges, change)
+}
+
+// reconcileAdoption imports the topics of the adopted cluster and records the held back changes in the status
+func (r *Reconciler) reconcileAdoption(log logr.Logger) error {
+	status := &v1beta1.AdoptionStatus{}
+	if r.KafkaCluster.Status.Adoption != nil {
+		status.ImportedTopics = r.KafkaCluster.Status.Adoption.ImportedTopics
+	}
+
+	if r.KafkaCluster.Spec.Adoption.ImportTopics {
+		imported, err := r.importTopics(log)
+		if err != nil {
+			return err
+		}
+		status.ImportedTopics += imported
+	}
+
+	if len(r.adoptionHeldBackChanges) > 0 {
+		status.HeldBackChanges = r.adoptionHeldBackChanges
+		sort.Strings(status.HeldBackChanges)
+	}
+
+	if !reflect.DeepEqual(r.KafkaCluster.Status.Adoption, status) {
+		if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+			return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not update adoption status")
+		}
+	}
+	return nil
+}
+
+// importTopics creates KafkaTopics for the topics of the cluster which are not referenced by any KafkaTopic yet
+func (r *Reconciler) importTopics(log logr.Logger) (int32, error) {
+	kClient, closeClient, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return 0, errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer closeClient()
+
+	topics, err := kClient.ListTopics()
+	if err != nil {
+		return 0, errors.WrapIf(err, "could not list the topics of the adopted cluster")
+	}
+
+	var kafkaTopics v1alpha1.KafkaTopicList
+	if err := r.Client.List(context.TODO(), &kafkaTopics); err != nil {
+		return 0, errors.WrapIf(err, "failed to list KafkaTopics")
+	}
+
+	var imported int32
+	for _, topic := range topicsToImport(r.KafkaCluster, kafkaTopics.Items, topics) {
+		if err := r.Client.Create(context.TODO(), topic); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				log.Info("could not import topic, a KafkaTopic with the same name already exists", "topic", topic.Spec.Name, "kafkaTopic", topic.GetName())
+				continue
+			}
+			return imported, errors.WrapIfWithDetails(err, "could not create KafkaTopic for topic of the adopted cluster", "topic", topic.Spec.Name)
+		}
+		log.Info("topic imported", "topic", topic.Spec.Name, "kafkaTopic", topic.GetName())
+		imported++
+	}
+	return imported, nil
+}
+
+// topicsToImport returns the KafkaTopics to be created for the topics of the cluster not referenced by any of the
+// existing KafkaTopics, sorted by topic name
+func topicsToImport(cluster *v1beta1.KafkaCluster, existing []v1alpha1.KafkaTopic, topics map[string]sarama.TopicDetail) []*v1alpha1.KafkaTopic {
+	managed := make(map[string]bool, len(existing))
+	for _, kafkaTopic := range existing {
+		if k8sutil.ReferencesKafkaCluster(kafkaTopic.Spec.ClusterRef, kafkaTopic.GetNamespace(), cluster) {
+			managed[kafkaTopic.Spec.Name] = true
+		}
+	}
+
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		if !managed[name] && !strings.HasPrefix(name, internalTopicPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	kafkaTopics := make([]*v1alpha1.KafkaTopic, 0, len(names))
+	for _, name := range names {
+		detail := topics[name]
+		var config map[string]string
+		for key, value := range detail.ConfigEntries {
+			if value == nil {
+				continue
+			}
+			if config == nil {
+				config = make(map[string]string, len(detail.ConfigEntries))
+			}
+			config[key] = *value
+		}
+		kafkaTopics = append(kafkaTopics, &v1alpha1.KafkaTopic{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        adoptedTopicName(name),
+				Namespace:   cluster.GetNamespace(),
+				Annotations: map[string]string{v1beta1.AdoptedAnnotation: "true"},
+			},
+			Spec: v1alpha1.KafkaTopicSpec{
+				Name:              name,
+				Partitions:        detail.NumPartitions,
+				ReplicationFactor: int32(detail.ReplicationFactor),
+				Config:            config,
+				ClusterRef: v1alpha1.ClusterReference{
+					Name:      cluster.GetName(),
+					Namespace: cluster.GetNamespace(),
+				},
+			},
+		})
+	}
+	return kafkaTopics
+}
+
+// adoptedTopicName returns the name of the KafkaTopic of the topic, the topic names which are not valid resource
+// names are sanitized and suffixed with their hash to keep them unique
+func adoptedTopicName(topic string) string {
+	name := strings.Trim(invalidResourceNameChars.ReplaceAllString(strings.ToLower(topic), "-"), ".-")
+	if name == topic {
+		return name
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(topic))
+	if name == "" {
+		return fmt.Sprintf("topic-%08x", h.Sum32())
+	}
+	return fmt.Sprintf("%s-%08x", name, h.Sum32())
+}
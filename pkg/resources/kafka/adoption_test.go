@@ -0,0 +1,115 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestBrokersToAdopt(t *testing.T) {
+	now := metav1.Now()
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "kafka-2", Labels: map[string]string{"brokerId": "2"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "kafka-0", Labels: map[string]string{"brokerId": "0"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "kafka-1", Labels: map[string]string{"brokerId": "1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "kafka-3", Labels: map[string]string{"brokerId": "3"}, DeletionTimestamp: &now}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+	}
+	brokersState := map[string]v1beta1.BrokerState{"1": {}}
+
+	if brokerIDs := brokersToAdopt(pods, brokersState); !reflect.DeepEqual(brokerIDs, []string{"0", "2"}) {
+		t.Errorf("expected brokers 0 and 2 to be adopted, got: %v", brokerIDs)
+	}
+}
+
+func TestTopicsToImport(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	retention := "3600000"
+	topics := map[string]sarama.TopicDetail{
+		"orders":             {NumPartitions: 6, ReplicationFactor: 3, ConfigEntries: map[string]*string{"retention.ms": &retention}},
+		"payments":           {NumPartitions: 3, ReplicationFactor: 2},
+		"managed":            {NumPartitions: 1, ReplicationFactor: 1},
+		"__consumer_offsets": {NumPartitions: 50, ReplicationFactor: 3},
+	}
+	existing := []v1alpha1.KafkaTopic{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "managed", Namespace: "team-a"},
+			Spec:       v1alpha1.KafkaTopicSpec{Name: "managed", ClusterRef: v1alpha1.ClusterReference{Name: "kafka", Namespace: "kafka"}},
+		},
+		{
+			// references another cluster
+			ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "kafka"},
+			Spec:       v1alpha1.KafkaTopicSpec{Name: "payments", ClusterRef: v1alpha1.ClusterReference{Name: "other"}},
+		},
+	}
+
+	imported := topicsToImport(cluster, existing, topics)
+	if len(imported) != 2 {
+		t.Fatalf("expected 2 topics to be imported, got: %d", len(imported))
+	}
+
+	orders := imported[0]
+	switch {
+	case orders.GetName() != "orders" || orders.GetNamespace() != "kafka":
+		t.Errorf("expected KafkaTopic kafka/orders, got: %s/%s", orders.GetNamespace(), orders.GetName())
+	case orders.GetAnnotations()[v1beta1.AdoptedAnnotation] != "true":
+		t.Errorf("expected imported KafkaTopic to be annotated as adopted, got: %v", orders.GetAnnotations())
+	case orders.Spec.Partitions != 6 || orders.Spec.ReplicationFactor != 3:
+		t.Errorf("expected 6 partitions with replication factor 3, got: %+v", orders.Spec)
+	case !reflect.DeepEqual(orders.Spec.Config, map[string]string{"retention.ms": "3600000"}):
+		t.Errorf("expected the configs of the topic to be imported, got: %v", orders.Spec.Config)
+	case orders.Spec.ClusterRef != (v1alpha1.ClusterReference{Name: "kafka", Namespace: "kafka"}):
+		t.Errorf("expected reference to the adopted cluster, got: %+v", orders.Spec.ClusterRef)
+	}
+	if payments := imported[1]; payments.Spec.Name != "payments" || payments.Spec.Config != nil {
+		t.Errorf("expected payments topic without configs, got: %+v", payments.Spec)
+	}
+}
+
+func TestAdoptedTopicName(t *testing.T) {
+	testCases := []struct {
+		topic    string
+		expected string
+	}{
+		{topic: "orders.v1", expected: "orders.v1"},
+		{topic: "Orders_V1", expected: "orders-v1-"},
+		{topic: "_schemas", expected: "schemas-"},
+		{topic: "___", expected: "topic-"},
+	}
+
+	for _, test := range testCases {
+		name := adoptedTopicName(test.topic)
+		if test.expected == test.topic {
+			if name != test.expected {
+				t.Errorf("expected %s to be kept, got: %s", test.topic, name)
+			}
+			continue
+		}
+		if len(name) != len(test.expected)+8 || name[:len(test.expected)] != test.expected {
+			t.Errorf("expected %s followed by hash for %s, got: %s", test.expected, test.topic, name)
+		}
+	}
+	if adoptedTopicName("orders_v1") == adoptedTopicName("orders-v1") {
+		t.Error("expected different names for topics sanitized to the same name")
+	}
+}
@@ -44,6 +44,12 @@ func (r *Reconciler) allBrokerService() runtime.Object {
 	usedPorts = append(usedPorts,
 		generateServicePortForEListeners(r.KafkaCluster.Spec.ListenersConfig.ExternalListeners)...)
 
+	// the controller-only nodes of KRaft mode do not serve clients
+	selector := apiutil.LabelsForKafka(r.KafkaCluster.GetName())
+	if r.KafkaCluster.Spec.KRaftMode {
+		selector[brokerNodeLabel] = "true"
+	}
+
 	return &corev1.Service{
 		ObjectMeta: templates.ObjectMetaWithAnnotations(
 			fmt.Sprintf(kafkautils.AllBrokerServiceTemplate, r.KafkaCluster.GetName()),
@@ -53,7 +59,7 @@ func (r *Reconciler) allBrokerService() runtime.Object {
 		Spec: corev1.ServiceSpec{
 			Type:            corev1.ServiceTypeClusterIP,
 			SessionAffinity: corev1.ServiceAffinityNone,
-			Selector:        apiutil.LabelsForKafka(r.KafkaCluster.GetName()),
+			Selector:        selector,
 			Ports:           usedPorts,
 		},
 	}
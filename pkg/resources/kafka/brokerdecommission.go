@@ -0,0 +1,123 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+const decommissionTimeFormat = "2006-01-02 15:04:05"
+
+// retainDecommissionedBrokerData records the tombstone of the removed broker the PVCs of which are kept for the data
+// retention period of the cluster
+func (r *Reconciler) retainDecommissionedBrokerData(log logr.Logger, brokerID string, pvcs []string) error {
+	tombstone := newDecommissionedBroker(brokerID, pvcs, time.Now(), r.KafkaCluster.Spec.GetBrokerDataRetentionPeriod())
+
+	decommissionedBrokers := make([]v1beta1.DecommissionedBroker, 0, len(r.KafkaCluster.Status.DecommissionedBrokers)+1)
+	for _, decommissionedBroker := range r.KafkaCluster.Status.DecommissionedBrokers {
+		if decommissionedBroker.BrokerID != brokerID {
+			decommissionedBrokers = append(decommissionedBrokers, decommissionedBroker)
+		}
+	}
+	decommissionedBrokers = append(decommissionedBrokers, tombstone)
+
+	if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, decommissionedBrokers, log); err != nil {
+		return errors.WrapIfWithDetails(err, "could not record tombstone for broker", "id", brokerID)
+	}
+	log.Info("pvcs for broker retained", "brokerId", brokerID, "pvcs", pvcs, "retainUntil", tombstone.RetainUntil)
+	return nil
+}
+
+// reconcileDecommissionedBrokers deletes the PVCs of the removed brokers the data retention period of which is over
+// and drops the tombstones of the brokers re-added to the cluster, the kept PVCs of which are reused
+func (r *Reconciler) reconcileDecommissionedBrokers(log logr.Logger) error {
+	if len(r.KafkaCluster.Status.DecommissionedBrokers) == 0 {
+		return nil
+	}
+
+	kept, expired, readded := decommissionedBrokerChanges(r.KafkaCluster, time.Now())
+	if len(expired) == 0 && len(readded) == 0 {
+		return nil
+	}
+
+	for _, decommissionedBroker := range expired {
+		for _, pvcName := range decommissionedBroker.PersistentVolumeClaims {
+			err := r.Client.Delete(context.TODO(), &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Name:      pvcName,
+				Namespace: r.KafkaCluster.Namespace,
+			}})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return errors.WrapIfWithDetails(err, "could not delete retained pvc for broker",
+					"id", decommissionedBroker.BrokerID, "pvc name", pvcName)
+			}
+		}
+		log.Info("data retention period of broker is over, pvcs deleted", "brokerId", decommissionedBroker.BrokerID,
+			"pvcs", decommissionedBroker.PersistentVolumeClaims)
+	}
+	if len(readded) > 0 {
+		log.Info("brokers re-added within their data retention period, their pvcs are reused", "brokers", readded)
+	}
+
+	if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, kept, log); err != nil {
+		return errors.WrapIf(err, "could not update tombstones of decommissioned brokers")
+	}
+	return nil
+}
+
+// newDecommissionedBroker returns the tombstone of the broker removed at the given time
+func newDecommissionedBroker(brokerID string, pvcs []string, now time.Time, retention time.Duration) v1beta1.DecommissionedBroker {
+	now = now.UTC()
+	return v1beta1.DecommissionedBroker{
+		BrokerID:               brokerID,
+		DecommissionedAt:       now.Format(decommissionTimeFormat),
+		RetainUntil:            now.Add(retention).Format(decommissionTimeFormat),
+		PersistentVolumeClaims: pvcs,
+	}
+}
+
+// decommissionedBrokerChanges splits the tombstones of the cluster into the ones to keep and the ones the data
+// retention period of which is over, and returns the IDs of the brokers re-added to spec.brokers. Tombstones with
+// an unparsable retention time are kept so that no data is deleted by mistake.
+func decommissionedBrokerChanges(cluster *v1beta1.KafkaCluster, now time.Time) (kept, expired []v1beta1.DecommissionedBroker, readded []string) {
+	brokerIDsFromSpec := make(map[string]bool, len(cluster.Spec.Brokers))
+	for _, broker := range cluster.Spec.Brokers {
+		brokerIDsFromSpec[strconv.Itoa(int(broker.Id))] = true
+	}
+
+	for _, decommissionedBroker := range cluster.Status.DecommissionedBrokers {
+		if brokerIDsFromSpec[decommissionedBroker.BrokerID] {
+			readded = append(readded, decommissionedBroker.BrokerID)
+			continue
+		}
+		retainUntil, err := time.Parse(decommissionTimeFormat, decommissionedBroker.RetainUntil)
+		if err == nil && !now.UTC().Before(retainUntil) {
+			expired = append(expired, decommissionedBroker)
+			continue
+		}
+		kept = append(kept, decommissionedBroker)
+	}
+	return kept, expired, readded
+}
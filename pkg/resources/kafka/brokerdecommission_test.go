@@ -0,0 +1,85 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestNewDecommissionedBroker(t *testing.T) {
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	tombstone := newDecommissionedBroker("3", []string{"kafka-3-storage-0-abcde"}, now, 24*time.Hour)
+
+	expected := v1beta1.DecommissionedBroker{
+		BrokerID:               "3",
+		DecommissionedAt:       "2022-03-01 10:00:00",
+		RetainUntil:            "2022-03-02 10:00:00",
+		PersistentVolumeClaims: []string{"kafka-3-storage-0-abcde"},
+	}
+	if !reflect.DeepEqual(tombstone, expected) {
+		t.Errorf("expected tombstone %+v, got: %+v", expected, tombstone)
+	}
+}
+
+func TestDecommissionedBrokerChanges(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}},
+			BrokerDecommission: &v1beta1.BrokerDecommissionConfig{
+				DataRetentionPeriod: &metav1.Duration{Duration: 24 * time.Hour},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			DecommissionedBrokers: []v1beta1.DecommissionedBroker{
+				// re-added within the retention period
+				{BrokerID: "2", RetainUntil: "2022-03-02 10:00:00"},
+				{BrokerID: "3", RetainUntil: "2022-03-01 09:00:00"},
+				{BrokerID: "4", RetainUntil: "2022-03-02 10:00:00"},
+				{BrokerID: "5", RetainUntil: "not a time"},
+			},
+		},
+	}
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	kept, expired, readded := decommissionedBrokerChanges(cluster, now)
+	if !reflect.DeepEqual(readded, []string{"2"}) {
+		t.Errorf("expected broker 2 to be re-added, got: %v", readded)
+	}
+	if len(expired) != 1 || expired[0].BrokerID != "3" {
+		t.Errorf("expected the data retention period of broker 3 to be over, got: %+v", expired)
+	}
+	if len(kept) != 2 || kept[0].BrokerID != "4" || kept[1].BrokerID != "5" {
+		t.Errorf("expected the tombstones of brokers 4 and 5 to be kept, got: %+v", kept)
+	}
+}
+
+func TestGetBrokerDataRetentionPeriod(t *testing.T) {
+	spec := v1beta1.KafkaClusterSpec{}
+	if period := spec.GetBrokerDataRetentionPeriod(); period != 0 {
+		t.Errorf("expected no data retention when not set, got: %v", period)
+	}
+	spec.BrokerDecommission = &v1beta1.BrokerDecommissionConfig{
+		DataRetentionPeriod: &metav1.Duration{Duration: 2 * time.Hour},
+	}
+	if period := spec.GetBrokerDataRetentionPeriod(); period != 2*time.Hour {
+		t.Errorf("expected data retention of 2h, got: %v", period)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// reconcileBrokerMaintenance requests the Cruise Control demotion of the brokers put in maintenance and clears the
+// maintenance state of the brokers taken out of it
+func (r *Reconciler) reconcileBrokerMaintenance(log logr.Logger) error {
+	brokersToDemote, brokersBackFromMaintenance := brokerMaintenanceChanges(r.KafkaCluster)
+
+	if len(brokersToDemote) > 0 {
+		log.Info("requesting demotion of brokers in maintenance", "brokers", brokersToDemote)
+		state := &v1beta1.MaintenanceState{CruiseControlMaintenanceState: v1beta1.GracefulDemotionRequired}
+		if err := k8sutil.UpdateBrokerStatus(r.Client, brokersToDemote, r.KafkaCluster, state, log); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to request demotion of brokers in maintenance", "brokers", brokersToDemote)
+		}
+	}
+
+	if len(brokersBackFromMaintenance) > 0 {
+		log.Info("brokers are back from maintenance", "brokers", brokersBackFromMaintenance)
+		if err := k8sutil.UpdateBrokerStatus(r.Client, brokersBackFromMaintenance, r.KafkaCluster, (*v1beta1.MaintenanceState)(nil), log); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to clear maintenance state of brokers", "brokers", brokersBackFromMaintenance)
+		}
+	}
+	return nil
+}
+
+// brokerMaintenanceChanges returns the IDs of the existing brokers put in maintenance which have not been demoted yet
+// and the IDs of the brokers taken out of maintenance which still have a maintenance state
+func brokerMaintenanceChanges(cluster *v1beta1.KafkaCluster) (toDemote []string, backFromMaintenance []string) {
+	for _, broker := range cluster.Spec.Brokers {
+		brokerID := strconv.Itoa(int(broker.Id))
+		brokerState, ok := cluster.Status.BrokersState[brokerID]
+		if !ok {
+			continue
+		}
+		maintenanceState := brokerState.GracefulActionState.MaintenanceState
+		switch {
+		case broker.Maintenance && maintenanceState == nil:
+			toDemote = append(toDemote, brokerID)
+		case !broker.Maintenance && maintenanceState != nil:
+			backFromMaintenance = append(backFromMaintenance, brokerID)
+		}
+	}
+	return toDemote, backFromMaintenance
+}
@@ -0,0 +1,56 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestBrokerMaintenanceChanges(t *testing.T) {
+	demoted := v1beta1.BrokerState{GracefulActionState: v1beta1.GracefulActionState{
+		MaintenanceState: &v1beta1.MaintenanceState{CruiseControlMaintenanceState: v1beta1.GracefulDemotionSucceeded},
+	}}
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{Id: 0},
+				{Id: 1, Maintenance: true},
+				{Id: 2, Maintenance: true},
+				{Id: 3},
+				// broker not created yet
+				{Id: 4, Maintenance: true},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {},
+				"1": {},
+				"2": demoted,
+				"3": demoted,
+			},
+		},
+	}
+
+	toDemote, backFromMaintenance := brokerMaintenanceChanges(cluster)
+	if !reflect.DeepEqual(toDemote, []string{"1"}) {
+		t.Errorf("expected broker 1 to be demoted, got: %v", toDemote)
+	}
+	if !reflect.DeepEqual(backFromMaintenance, []string{"3"}) {
+		t.Errorf("expected broker 3 to be back from maintenance, got: %v", backFromMaintenance)
+	}
+}
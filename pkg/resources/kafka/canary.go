@@ -0,0 +1,97 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"sort"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+// checkCanaryGate returns a CanaryBrokersUnhealthy error if the Kafka version the pod is upgraded to already runs
+// on other brokers, e.g. on the canaries of a broker config group, and any of them has offline or out-of-sync
+// replicas, so that a release misbehaving on the canaries is not rolled out to the rest of the brokers
+func (r *Reconciler) checkCanaryGate(log logr.Logger, desiredPod *corev1.Pod) error {
+	if _, ok := r.KafkaCluster.GetAnnotations()[v1beta1.EmergencyOverrideAnnotation]; ok {
+		return nil
+	}
+	version := r.kafkaVersionOfImage(kafkaImage(desiredPod))
+	if version == "" {
+		return nil
+	}
+	canaries := canaryBrokers(r.KafkaCluster.Status.BrokersState, desiredPod.Labels["brokerId"], version)
+	if len(canaries) == 0 {
+		return nil
+	}
+
+	kClient, closeClient, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.CanaryBrokersUnhealthy{}, err,
+			"could not connect to kafka brokers to check the canary brokers", "kafkaVersion", version)
+	}
+	defer closeClient()
+	offlineReplicas, err := kClient.AllOfflineReplicas()
+	if err != nil {
+		return errorfactory.New(errorfactory.CanaryBrokersUnhealthy{}, err, "could not get offline replicas")
+	}
+	outOfSyncReplicas, err := kClient.OutOfSyncReplicas()
+	if err != nil {
+		return errorfactory.New(errorfactory.CanaryBrokersUnhealthy{}, err, "could not get out-of-sync replicas")
+	}
+
+	if unhealthy := unhealthyCanaries(canaries, offlineReplicas, outOfSyncReplicas); len(unhealthy) > 0 {
+		return errorfactory.New(errorfactory.CanaryBrokersUnhealthy{}, errors.New("canary brokers are unhealthy"),
+			"holding back the rollout of the Kafka version", "kafkaVersion", version, "brokers", unhealthy)
+	}
+	log.Info("canary brokers are healthy, rolling out the Kafka version", "kafkaVersion", version, "canaries", canaries)
+	return nil
+}
+
+// canaryBrokers returns the IDs of the brokers, other than the given one, already running the given Kafka version
+func canaryBrokers(brokersState map[string]v1beta1.BrokerState, brokerID, version string) []int32 {
+	var canaries []int32
+	for id, state := range brokersState {
+		if id == brokerID || kafkautils.ParseKafkaVersion(state.Version) != version {
+			continue
+		}
+		if canary, err := strconv.ParseInt(id, 10, 32); err == nil {
+			canaries = append(canaries, int32(canary))
+		}
+	}
+	sort.Slice(canaries, func(i, j int) bool { return canaries[i] < canaries[j] })
+	return canaries
+}
+
+// unhealthyCanaries returns the canary brokers having offline or out-of-sync replicas
+func unhealthyCanaries(canaries, offlineReplicas, outOfSyncReplicas []int32) []int32 {
+	unhealthyBrokers := make(map[int32]struct{}, len(offlineReplicas)+len(outOfSyncReplicas))
+	for _, id := range append(offlineReplicas, outOfSyncReplicas...) {
+		unhealthyBrokers[id] = struct{}{}
+	}
+	var unhealthy []int32
+	for _, id := range canaries {
+		if _, ok := unhealthyBrokers[id]; ok {
+			unhealthy = append(unhealthy, id)
+		}
+	}
+	return unhealthy
+}
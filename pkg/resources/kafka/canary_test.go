@@ -0,0 +1,61 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestCanaryBrokers(t *testing.T) {
+	brokersState := map[string]v1beta1.BrokerState{
+		"0": {Version: "3.1.0"},
+		"1": {Version: "3.1.2"},
+		"2": {Version: "3.1.2"},
+		"3": {},
+	}
+
+	testCases := []struct {
+		testName string
+		brokerID string
+		version  string
+		expected []int32
+	}{
+		{testName: "canaries of the version", brokerID: "0", version: "3.1.2", expected: []int32{1, 2}},
+		{testName: "the upgraded broker is not a canary", brokerID: "1", version: "3.1.2", expected: []int32{2}},
+		{testName: "no canaries", brokerID: "0", version: "3.2.0"},
+	}
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			if canaries := canaryBrokers(brokersState, test.brokerID, test.version); !reflect.DeepEqual(canaries, test.expected) {
+				t.Errorf("expected canaries %v, got: %v", test.expected, canaries)
+			}
+		})
+	}
+}
+
+func TestUnhealthyCanaries(t *testing.T) {
+	if unhealthy := unhealthyCanaries([]int32{1, 2}, nil, nil); len(unhealthy) != 0 {
+		t.Error("expected healthy canaries, got:", unhealthy)
+	}
+	if unhealthy := unhealthyCanaries([]int32{1, 2}, []int32{0}, []int32{3}); len(unhealthy) != 0 {
+		t.Error("expected the replicas of other brokers not to matter, got:", unhealthy)
+	}
+	if unhealthy := unhealthyCanaries([]int32{1, 2, 4}, []int32{2}, []int32{4, 0}); !reflect.DeepEqual(unhealthy, []int32{2, 4}) {
+		t.Error("expected canaries 2 and 4 to be unhealthy, got:", unhealthy)
+	}
+}
@@ -36,13 +36,34 @@ import (
 
 func (r *Reconciler) getConfigProperties(bConfig *v1beta1.BrokerConfig, id int32,
 	extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList,
-	serverPasses map[string]string, clientPass string, superUsers []string, log logr.Logger) *properties.Properties {
+	serverPasses map[string]string, clientPass string, superUsers []string, saslPlain saslPlainConfig, log logr.Logger) *properties.Properties {
 	config := properties.NewProperties()
 
+	kraftMode := r.KafkaCluster.Spec.KRaftMode
+	controllerOnly := kraftMode && !bConfig.IsBrokerNode()
+
 	// Add listener configuration
 	listenerConf := generateListenerSpecificConfig(&r.KafkaCluster.Spec.ListenersConfig, serverPasses, log)
 	config.Merge(listenerConf)
 
+	if kraftMode {
+		// Add KRaft configuration
+		r.generateKRaftConfig(config, bConfig, id, controllerIntListenerStatuses, log)
+
+		// The controller listener is not advertised in KRaft mode, the nodes find the controllers through
+		// controller.quorum.voters. The controller-only nodes advertise no listener at all.
+		controllerIntListenerStatuses = nil
+		if controllerOnly {
+			extListenerStatuses, intListenerStatuses = nil, nil
+		}
+	}
+
+	// Add SASL/PLAIN configuration
+	generateSASLPlainConfig(config, saslPlain, log)
+
+	// Add custom principal builder configuration
+	generatePrincipalBuilderConfig(config, r.KafkaCluster.Spec.ListenersConfig.PrincipalBuilder, log)
+
 	// Add listener configuration
 	advertisedListenerConf := generateAdvertisedListenerConfig(id, r.KafkaCluster.Spec.ListenersConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses)
 	if len(advertisedListenerConf) > 0 {
@@ -51,59 +72,70 @@ func (r *Reconciler) getConfigProperties(bConfig *v1beta1.BrokerConfig, id int32
 		}
 	}
 
-	// Add control plane listener
-	cclConf := generateControlPlaneListener(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
-	if cclConf != "" {
-		if err := config.Set("control.plane.listener.name", cclConf); err != nil {
-			log.Error(err, "setting control.plane.listener.name parameter in broker configuration resulted an error")
+	if !kraftMode {
+		// Add control plane listener
+		cclConf := generateControlPlaneListener(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
+		if cclConf != "" {
+			if err := config.Set("control.plane.listener.name", cclConf); err != nil {
+				log.Error(err, "setting control.plane.listener.name parameter in broker configuration resulted an error")
+			}
+		}
+
+		// Add Zookeeper configuration
+		if err := config.Set("zookeeper.connect", zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())); err != nil {
+			log.Error(err, "setting zookeeper.connect parameter in broker configuration resulted an error")
 		}
-	}
 
-	// Add Zookeeper configuration
-	if err := config.Set("zookeeper.connect", zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())); err != nil {
-		log.Error(err, "setting zookeeper.connect parameter in broker configuration resulted an error")
+		// Pin the protocol versions while a staged Kafka version upgrade rolls the brokers
+		generateVersionUpgradeConfig(config, r.KafkaCluster.Status.VersionUpgrade, log)
 	}
 
-	// Add Cruise Control SSL configuration
-	if util.IsSSLEnabledForInternalCommunication(r.KafkaCluster.Spec.ListenersConfig.InternalListeners) {
-		if !r.KafkaCluster.Spec.IsClientSSLSecretPresent() {
-			log.Error(errors.New("cruise control metrics reporter needs ssl but client certificate hasn't specified"), "")
-		}
-		if err := config.Set("cruise.control.metrics.reporter.security.protocol", "SSL"); err != nil {
-			log.Error(err, "setting cruise.control.metrics.reporter.security.protocol in broker configuration resulted an error")
+	// Cruise Control is not deployed with the dev profile and the built-in rebalancer, so the brokers do not need
+	// its metrics reporter then. The controller-only nodes have no partitions for Cruise Control to balance.
+	if !r.KafkaCluster.Spec.IsCruiseControlDisabled() && !controllerOnly {
+		// Add Cruise Control SSL configuration
+		if util.IsSSLEnabledForInternalCommunication(r.KafkaCluster.Spec.ListenersConfig.InternalListeners) {
+			if !r.KafkaCluster.Spec.IsClientSSLSecretPresent() {
+				log.Error(errors.New("cruise control metrics reporter needs ssl but client certificate hasn't specified"), "")
+			}
+			if err := config.Set("cruise.control.metrics.reporter.security.protocol", "SSL"); err != nil {
+				log.Error(err, "setting cruise.control.metrics.reporter.security.protocol in broker configuration resulted an error")
+			}
+			if err := config.Set("cruise.control.metrics.reporter.ssl.truststore.location", clientKeystorePath+"/"+v1alpha1.TLSJKSTrustStore); err != nil {
+				log.Error(err, "setting cruise.control.metrics.reporter.ssl.truststore.location in broker configuration resulted an error")
+			}
+			if err := config.Set("cruise.control.metrics.reporter.ssl.truststore.password", clientPass); err != nil {
+				log.Error(err, "setting cruise.control.metrics.reporter.ssl.truststore.password parameter in broker configuration resulted an error")
+			}
+			if err := config.Set("cruise.control.metrics.reporter.ssl.keystore.location", clientKeystorePath+"/"+v1alpha1.TLSJKSKeyStore); err != nil {
+				log.Error(err, "setting cruise.control.metrics.reporter.ssl.keystore.location parameter in broker configuration resulted an error")
+			}
+			if err := config.Set("cruise.control.metrics.reporter.ssl.keystore.password", clientPass); err != nil {
+				log.Error(err, "setting cruise.control.metrics.reporter.ssl.keystore.password parameter in broker configuration resulted an error")
+			}
 		}
-		if err := config.Set("cruise.control.metrics.reporter.ssl.truststore.location", clientKeystorePath+"/"+v1alpha1.TLSJKSTrustStore); err != nil {
-			log.Error(err, "setting cruise.control.metrics.reporter.ssl.truststore.location in broker configuration resulted an error")
+
+		// Add Cruise Control Metrics Reporter configuration
+		if err := config.Set("metric.reporters", "com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter"); err != nil {
+			log.Error(err, "setting metric.reporters in broker configuration resulted an error")
 		}
-		if err := config.Set("cruise.control.metrics.reporter.ssl.truststore.password", clientPass); err != nil {
-			log.Error(err, "setting cruise.control.metrics.reporter.ssl.truststore.password parameter in broker configuration resulted an error")
+		bootstrapServers, err := kafkautils.GetBootstrapServersService(r.KafkaCluster)
+		if err != nil {
+			log.Error(err, "getting Kafka bootstrap servers for Cruise Control failed")
 		}
-		if err := config.Set("cruise.control.metrics.reporter.ssl.keystore.location", clientKeystorePath+"/"+v1alpha1.TLSJKSKeyStore); err != nil {
-			log.Error(err, "setting cruise.control.metrics.reporter.ssl.keystore.location parameter in broker configuration resulted an error")
+		if err := config.Set("cruise.control.metrics.reporter.bootstrap.servers", bootstrapServers); err != nil {
+			log.Error(err, "setting cruise.control.metrics.reporter.bootstrap.servers in broker configuration resulted an error")
 		}
-		if err := config.Set("cruise.control.metrics.reporter.ssl.keystore.password", clientPass); err != nil {
-			log.Error(err, "setting cruise.control.metrics.reporter.ssl.keystore.password parameter in broker configuration resulted an error")
+		if err := config.Set("cruise.control.metrics.reporter.kubernetes.mode", true); err != nil {
+			log.Error(err, "setting cruise.control.metrics.reporter.kubernetes.mode in broker configuration resulted an error")
 		}
 	}
 
-	// Add Cruise Control Metrics Reporter configuration
-	if err := config.Set("metric.reporters", "com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter"); err != nil {
-		log.Error(err, "setting metric.reporters in broker configuration resulted an error")
-	}
-	bootstrapServers, err := kafkautils.GetBootstrapServersService(r.KafkaCluster)
-	if err != nil {
-		log.Error(err, "getting Kafka bootstrap servers for Cruise Control failed")
-	}
-	if err := config.Set("cruise.control.metrics.reporter.bootstrap.servers", bootstrapServers); err != nil {
-		log.Error(err, "setting cruise.control.metrics.reporter.bootstrap.servers in broker configuration resulted an error")
-	}
-	if err := config.Set("cruise.control.metrics.reporter.kubernetes.mode", true); err != nil {
-		log.Error(err, "setting cruise.control.metrics.reporter.kubernetes.mode in broker configuration resulted an error")
-	}
-
-	// Kafka Broker configuration
-	if err := config.Set("broker.id", id); err != nil {
-		log.Error(err, "setting broker.id in broker configuration resulted an error")
+	// Kafka Broker configuration, the ID of the broker is its node.id in KRaft mode
+	if !kraftMode {
+		if err := config.Set("broker.id", id); err != nil {
+			log.Error(err, "setting broker.id in broker configuration resulted an error")
+		}
 	}
 
 	// Storage configuration
@@ -124,6 +156,88 @@ func (r *Reconciler) getConfigProperties(bConfig *v1beta1.BrokerConfig, id int32
 	return config
 }
 
+// generateKRaftConfig sets the process roles of the node and the metadata quorum it joins in KRaft mode. Only the
+// nodes with the controller role bind the controller listener, and the controller-only nodes bind nothing else.
+func (r *Reconciler) generateKRaftConfig(config *properties.Properties, bConfig *v1beta1.BrokerConfig, id int32,
+	controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList, log logr.Logger) {
+	roles := make([]string, 0, len(bConfig.GetProcessRoles()))
+	for _, role := range bConfig.GetProcessRoles() {
+		roles = append(roles, string(role))
+	}
+	if err := config.Set("process.roles", roles); err != nil {
+		log.Error(err, "setting process.roles in broker configuration resulted an error")
+	}
+	if err := config.Set("node.id", id); err != nil {
+		log.Error(err, "setting node.id in broker configuration resulted an error")
+	}
+
+	controllerListener := generateControlPlaneListener(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
+	if controllerListener == "" {
+		log.Error(errors.New("KRaft mode requires an internal listener used for controller communication"), "config error")
+		return
+	}
+	if err := config.Set("controller.listener.names", controllerListener); err != nil {
+		log.Error(err, "setting controller.listener.names in broker configuration resulted an error")
+	}
+
+	voters, err := generateQuorumVoters(r.KafkaCluster.Spec, controllerListener, controllerIntListenerStatuses)
+	if err != nil {
+		log.Error(err, "generating controller.quorum.voters for broker configuration failed")
+	}
+	if len(voters) > 0 {
+		if err := config.Set("controller.quorum.voters", voters); err != nil {
+			log.Error(err, "setting controller.quorum.voters in broker configuration resulted an error")
+		}
+	}
+
+	if property, ok := config.Get("listeners"); ok {
+		listeners, _ := property.List()
+		nodeListeners := make([]string, 0, len(listeners))
+		for _, listener := range listeners {
+			bound := bConfig.IsBrokerNode()
+			if strings.HasPrefix(listener, controllerListener+"://") {
+				bound = bConfig.IsControllerNode()
+			}
+			if bound {
+				nodeListeners = append(nodeListeners, listener)
+			}
+		}
+		if err := config.Set("listeners", nodeListeners); err != nil {
+			log.Error(err, "setting listeners parameter in broker configuration resulted an error")
+		}
+	}
+	if !bConfig.IsBrokerNode() {
+		config.Delete("inter.broker.listener.name")
+	}
+}
+
+// generateQuorumVoters returns the voters of the metadata quorum as id@host:port, the address of each broker with the
+// controller role on the controller listener
+func generateQuorumVoters(spec v1beta1.KafkaClusterSpec, controllerListener string,
+	controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList) ([]string, error) {
+	controllerIDs, err := spec.GetKRaftControllerIDs()
+	if err != nil {
+		return nil, err
+	}
+	var statuses v1beta1.ListenerStatusList
+	for name, listenerStatuses := range controllerIntListenerStatuses {
+		if strings.ToUpper(name) == controllerListener {
+			statuses = listenerStatuses
+		}
+	}
+
+	voters := make([]string, 0, len(controllerIDs))
+	for _, id := range controllerIDs {
+		for _, status := range statuses {
+			if status.Name == fmt.Sprintf("broker-%d", id) {
+				voters = append(voters, fmt.Sprintf("%d@%s", id, status.Address))
+				break
+			}
+		}
+	}
+	return voters, nil
+}
+
 func generateSuperUsers(users []string) (suStrings []string) {
 	suStrings = make([]string, 0)
 	for _, x := range users {
@@ -132,9 +246,7 @@ func generateSuperUsers(users []string) (suStrings []string) {
 	return
 }
 
-func (r *Reconciler) configMap(id int32, brokerConfig *v1beta1.BrokerConfig, extListenerStatuses,
-	intListenerStatuses, controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList,
-	serverPasses map[string]string, clientPass string, superUsers []string, log logr.Logger) *corev1.ConfigMap {
+func (r *Reconciler) configMap(id int32, brokerConfig *v1beta1.BrokerConfig, renderedConfig *kafkautils.SourcedBrokerConfig) *corev1.ConfigMap {
 	brokerConf := &corev1.ConfigMap{
 		ObjectMeta: templates.ObjectMeta(
 			fmt.Sprintf(brokerConfigTemplate+"-%d", r.KafkaCluster.Name, id),
@@ -144,12 +256,14 @@ func (r *Reconciler) configMap(id int32, brokerConfig *v1beta1.BrokerConfig, ext
 			),
 			r.KafkaCluster,
 		),
-		Data: map[string]string{kafkautils.ConfigPropertyName: r.generateBrokerConfig(id, brokerConfig, extListenerStatuses,
-			intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, log)},
+		Data: map[string]string{kafkautils.ConfigPropertyName: renderedConfig.Static().String()},
 	}
 	if brokerConfig.Log4jConfig != "" {
 		brokerConf.Data["log4j.properties"] = brokerConfig.Log4jConfig
 	}
+	if securityProps := javaSecurityProperties(r.KafkaCluster.Spec.ListenersConfig.CertificateRevocation); securityProps != "" {
+		brokerConf.Data[javaSecurityPropertiesName] = securityProps
+	}
 	return brokerConf
 }
 
@@ -276,49 +390,80 @@ func generateListenerSSLConfig(config *properties.Properties, name string, sslCl
 	}
 }
 
-func (r Reconciler) generateBrokerConfig(id int32, brokerConfig *v1beta1.BrokerConfig, extListenerStatuses,
+// renderBrokerConfig renders the configuration of the broker tagging each config with its source: the static configs
+// of server.properties are the read-only configs merged over the operator defaults and overridden by the operator
+// generated configs, the dynamic configs are the cluster-wide configs overridden by the per-broker ones
+func (r Reconciler) renderBrokerConfig(id int32, brokerConfig *v1beta1.BrokerConfig, extListenerStatuses,
 	intListenerStatuses, controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList,
-	serverPasses map[string]string, clientPass string, superUsers []string, log logr.Logger) string {
-	finalBrokerConfig := getBrokerReadOnlyConfig(id, r.KafkaCluster, log)
-
-	// Get operator generated configuration
-	opGenConf := r.getConfigProperties(brokerConfig, id, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, log)
+	serverPasses map[string]string, clientPass string, superUsers []string, saslPlain saslPlainConfig, log logr.Logger) *kafkautils.SourcedBrokerConfig {
+	config := kafkautils.NewSourcedBrokerConfig()
 
-	// Merge operator generated configuration to the final one
-	if opGenConf != nil {
-		finalBrokerConfig.Merge(opGenConf)
+	// The internal topics of a single broker cluster can not have more than one replica
+	if r.KafkaCluster.Spec.IsDevProfileEnabled() {
+		config.MergeStatic(devProfileBrokerConfig(), kafkautils.ConfigSourceOperatorDefault)
 	}
 
-	finalBrokerConfig.Sort()
-
-	return finalBrokerConfig.String()
-}
-
-// TODO move this into api in the future (adamantal)
-func getBrokerReadOnlyConfig(id int32, kafkaCluster *v1beta1.KafkaCluster, log logr.Logger) *properties.Properties {
 	// Parse cluster-wide readonly configuration
-	finalBrokerConfig, err := properties.NewFromString(kafkaCluster.Spec.ReadOnlyConfig)
+	clusterReadOnlyConfig, err := properties.NewFromString(r.KafkaCluster.Spec.ReadOnlyConfig)
 	if err != nil {
 		log.Error(err, "failed to parse readonly cluster configuration")
 	}
+	config.MergeStatic(clusterReadOnlyConfig, kafkautils.ConfigSourceReadOnlyConfig)
 
 	// Parse readonly broker configuration
-	var parsedReadOnlyBrokerConfig *properties.Properties
-	// Find configuration for broker with id
-	for _, broker := range kafkaCluster.Spec.Brokers {
+	var perBrokerConfigSource kafkautils.ConfigSource
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
 		if broker.Id == id {
-			parsedReadOnlyBrokerConfig, err = properties.NewFromString(broker.ReadOnlyConfig)
+			brokerReadOnlyConfig, err := properties.NewFromString(broker.ReadOnlyConfig)
 			if err != nil {
 				log.Error(err, fmt.Sprintf("failed to parse readonly broker configuration for broker with id: %d", id))
 			}
+			config.MergeStatic(brokerReadOnlyConfig, kafkautils.ConfigSourceBrokerReadOnlyConfig)
+
+			// the config of the broker overrides the one of its group instead of extending it
+			if broker.BrokerConfigGroup != "" && (broker.BrokerConfig == nil || broker.BrokerConfig.Config == "") {
+				perBrokerConfigSource = kafkautils.ConfigSourceBrokerConfigGroup
+			} else {
+				perBrokerConfigSource = kafkautils.ConfigSourceBrokerConfig
+			}
 			break
 		}
 	}
 
-	// Merge cluster-wide configuration into broker-level configuration
-	if parsedReadOnlyBrokerConfig != nil {
-		finalBrokerConfig.Merge(parsedReadOnlyBrokerConfig)
+	// Get operator generated configuration
+	opGenConf := r.getConfigProperties(brokerConfig, id, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, saslPlain, log)
+	mergeConfigProviders(config.Static(), opGenConf)
+	config.MergeStatic(opGenConf, kafkautils.ConfigSourceOperator)
+
+	// Parse the dynamic configuration
+	clusterWideConfig, err := properties.NewFromString(r.KafkaCluster.Spec.ClusterWideConfig)
+	if err != nil {
+		log.Error(err, "failed to parse cluster-wide configuration")
 	}
+	config.MergeDynamic(clusterWideConfig, kafkautils.ConfigSourceClusterWideConfig)
+	if brokerConfig != nil {
+		perBrokerConfig, err := properties.NewFromString(brokerConfig.Config)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("failed to parse per-broker configuration for broker with id: %d", id))
+		}
+		config.MergeDynamic(perBrokerConfig, perBrokerConfigSource)
+	}
+
+	return config
+}
 
-	return finalBrokerConfig
+// devProfileBrokerConfig returns the defaults of the broker configuration with the dev profile, they can be
+// overridden by the read-only configuration
+func devProfileBrokerConfig() *properties.Properties {
+	config := properties.NewProperties()
+	for key, value := range map[string]string{
+		"default.replication.factor":               "1",
+		"min.insync.replicas":                      "1",
+		"offsets.topic.replication.factor":         "1",
+		"transaction.state.log.replication.factor": "1",
+		"transaction.state.log.min.isr":            "1",
+	} {
+		_ = config.Set(key, value)
+	}
+	return config
 }
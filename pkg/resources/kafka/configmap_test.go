@@ -444,7 +444,7 @@ zookeeper.connect=example.zk:2181/`,
 				superUsers = []string{"CN=kafka-headless.kafka.svc.cluster.local"}
 			}
 
-			generatedConfig := r.generateBrokerConfig(0, r.KafkaCluster.Spec.Brokers[0].BrokerConfig, map[string]v1beta1.ListenerStatusList{}, map[string]v1beta1.ListenerStatusList{}, controllerListenerStatus, serverPasses, clientPass, superUsers, logr.Discard())
+			generatedConfig := r.renderBrokerConfig(0, r.KafkaCluster.Spec.Brokers[0].BrokerConfig, map[string]v1beta1.ListenerStatusList{}, map[string]v1beta1.ListenerStatusList{}, controllerListenerStatus, serverPasses, clientPass, superUsers, saslPlainConfig{}, logr.Discard()).Static().String()
 
 			generated, err := properties.NewFromString(generatedConfig)
 			if err != nil {
@@ -462,3 +462,182 @@ zookeeper.connect=example.zk:2181/`,
 		})
 	}
 }
+
+func TestGenerateBrokerConfigWithDevProfile(t *testing.T) {
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			KafkaCluster: &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kafka",
+					Namespace: "kafka",
+				},
+				Spec: v1beta1.KafkaClusterSpec{
+					ZKAddresses:    []string{"example.zk:2181"},
+					ReadOnlyConfig: "min.insync.replicas=2",
+					DevProfile:     v1beta1.DevProfileConfig{Enabled: true},
+					ListenersConfig: v1beta1.ListenersConfig{
+						InternalListeners: []v1beta1.InternalListenerConfig{
+							{
+								CommonListenerSpec: v1beta1.CommonListenerSpec{
+									Type:          v1beta1.SecurityProtocolPlaintext,
+									Name:          "internal",
+									ContainerPort: 9092,
+								},
+								UsedForInnerBrokerCommunication: true,
+							},
+						},
+					},
+					Brokers: []v1beta1.Broker{{
+						Id:           0,
+						BrokerConfig: &v1beta1.BrokerConfig{},
+					}},
+				},
+			},
+		},
+	}
+
+	generatedConfig := r.renderBrokerConfig(0, r.KafkaCluster.Spec.Brokers[0].BrokerConfig, map[string]v1beta1.ListenerStatusList{},
+		map[string]v1beta1.ListenerStatusList{}, map[string]v1beta1.ListenerStatusList{}, nil, "", nil, saslPlainConfig{}, logr.Discard()).Static().String()
+	generated, err := properties.NewFromString(generatedConfig)
+	if err != nil {
+		t.Fatalf("failed parsing generated configuration as Properties: %s", generatedConfig)
+	}
+
+	expected := map[string]string{
+		"default.replication.factor":               "1",
+		"offsets.topic.replication.factor":         "1",
+		"transaction.state.log.replication.factor": "1",
+		"transaction.state.log.min.isr":            "1",
+		// the read-only configuration overrides the defaults of the dev profile
+		"min.insync.replicas": "2",
+	}
+	for key, value := range expected {
+		if p, ok := generated.Get(key); !ok || p.Value() != value {
+			t.Errorf("expected %s=%s in the generated configuration:\n%s", key, value, generatedConfig)
+		}
+	}
+	if _, ok := generated.Get("metric.reporters"); ok {
+		t.Errorf("expected no Cruise Control metrics reporter in the generated configuration:\n%s", generatedConfig)
+	}
+}
+
+func TestGenerateBrokerConfigInKRaftMode(t *testing.T) {
+	controller := &v1beta1.BrokerConfig{ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleController}}
+	combined := &v1beta1.BrokerConfig{ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleBroker, v1beta1.ProcessRoleController}}
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			KafkaCluster: &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kafka",
+					Namespace: "kafka",
+				},
+				Spec: v1beta1.KafkaClusterSpec{
+					KRaftMode: true,
+					ListenersConfig: v1beta1.ListenersConfig{
+						InternalListeners: []v1beta1.InternalListenerConfig{
+							{
+								CommonListenerSpec: v1beta1.CommonListenerSpec{
+									Type:          v1beta1.SecurityProtocolPlaintext,
+									Name:          "internal",
+									ContainerPort: 9092,
+								},
+								UsedForInnerBrokerCommunication: true,
+							},
+							{
+								CommonListenerSpec: v1beta1.CommonListenerSpec{
+									Type:          v1beta1.SecurityProtocolPlaintext,
+									Name:          "controller",
+									ContainerPort: 9093,
+								},
+								UsedForControllerCommunication: true,
+							},
+						},
+					},
+					Brokers: []v1beta1.Broker{
+						{Id: 0, BrokerConfig: controller},
+						{Id: 1, BrokerConfig: &v1beta1.BrokerConfig{}},
+						{Id: 2, BrokerConfig: combined},
+					},
+				},
+			},
+		},
+	}
+
+	statuses := func(port string) v1beta1.ListenerStatusList {
+		var list v1beta1.ListenerStatusList
+		for _, id := range []string{"0", "1", "2"} {
+			list = append(list, v1beta1.ListenerStatus{Name: "broker-" + id, Address: "kafka-" + id + ".kafka.svc.cluster.local:" + port})
+		}
+		return list
+	}
+	intListenerStatuses := map[string]v1beta1.ListenerStatusList{"internal": statuses("9092")}
+	controllerListenerStatuses := map[string]v1beta1.ListenerStatusList{"controller": statuses("9093")}
+
+	tests := []struct {
+		testName       string
+		id             int32
+		expectedConfig string
+	}{
+		{
+			testName: "controller-only node",
+			id:       0,
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,2@kafka-2.kafka.svc.cluster.local:9093
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=CONTROLLER://:9093
+node.id=0
+process.roles=controller`,
+		},
+		{
+			testName: "broker-only node",
+			id:       1,
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-1.kafka.svc.cluster.local:9092
+controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,2@kafka-2.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+node.id=1
+process.roles=broker`,
+		},
+		{
+			testName: "combined node",
+			id:       2,
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-2.kafka.svc.cluster.local:9092
+controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,2@kafka-2.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092,CONTROLLER://:9093
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+node.id=2
+process.roles=broker,controller`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			brokerConfig := r.KafkaCluster.Spec.Brokers[test.id].BrokerConfig
+			generatedConfig := r.renderBrokerConfig(test.id, brokerConfig, map[string]v1beta1.ListenerStatusList{},
+				intListenerStatuses, controllerListenerStatuses, nil, "", nil, saslPlainConfig{}, logr.Discard()).Static().String()
+
+			generated, err := properties.NewFromString(generatedConfig)
+			if err != nil {
+				t.Fatalf("failed parsing generated configuration as Properties: %s", generatedConfig)
+			}
+			expected, err := properties.NewFromString(test.expectedConfig)
+			if err != nil {
+				t.Fatalf("failed parsing expected configuration as Properties: %s", test.expectedConfig)
+			}
+			if !expected.Equal(generated) {
+				t.Errorf("the expected config is:\n%s\nreceived:\n%s\n", test.expectedConfig, generatedConfig)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package kafka
 
 import (
+	"sort"
 	"strconv"
 
 	"emperror.dev/errors"
@@ -32,7 +33,16 @@ import (
 	properties "github.com/banzaicloud/koperator/properties/pkg"
 )
 
-func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfig *v1beta1.BrokerConfig, configMap *corev1.ConfigMap, log logr.Logger) error {
+// externallyManagedPerBrokerConfigs are the dynamic broker configs set by Cruise Control and the Kafka tools to throttle
+// the partition reassignments, these are kept even if they are not present in the broker config
+var externallyManagedPerBrokerConfigs = []string{
+	"leader.replication.throttled.rate",
+	"follower.replication.throttled.rate",
+	"replica.alter.log.dirs.io.max.bytes.per.second",
+}
+
+func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfig *v1beta1.BrokerConfig, configMap *corev1.ConfigMap,
+	saslPlain saslPlainConfig, log logr.Logger) error {
 	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
 	if err != nil {
 		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
@@ -54,20 +64,24 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 	if err != nil {
 		return errors.WrapIf(err, "could not parse broker configuration from configmap")
 	}
-	for _, perBrokerConfig := range kafka.PerBrokerConfigs {
-		if configProperty, ok := configsFromConfigMap.Get(perBrokerConfig); ok {
-			fullPerBrokerConfig.Put(configProperty)
+	for _, config := range configsFromConfigMap.Keys() {
+		if kafka.IsPerBrokerConfig(config) {
+			if configProperty, ok := configsFromConfigMap.Get(config); ok {
+				fullPerBrokerConfig.Put(configProperty)
+			}
 		}
 	}
+	saslPlain.resolve(fullPerBrokerConfig)
 
-	// query the current config
-	brokerConfigKeys := fullPerBrokerConfig.Keys()
-	response, err := kClient.DescribePerBrokerConfig(brokerId, brokerConfigKeys)
+	// query the current config, all of it so that the dynamic configs no longer desired can be deleted
+	response, err := kClient.DescribePerBrokerConfig(brokerId, nil)
 	if err != nil {
 		return errors.WrapIfWithDetails(err, "could not describe broker config", "brokerId", brokerId)
 	}
 
-	if shouldUpdatePerBrokerConfig(response, fullPerBrokerConfig) {
+	// the values of sensitive configs are not returned by the brokers so these are updated whenever the configmap changes
+	configChanges := perBrokerConfigChanges(response, fullPerBrokerConfig, currentPerBrokerConfigState == v1beta1.PerBrokerConfigOutOfSync)
+	if len(configChanges) > 0 {
 		if currentPerBrokerConfigState == v1beta1.PerBrokerConfigInSync {
 			log.V(1).Info("setting per broker config status to out of sync")
 			statusErr := k8sutil.UpdateBrokerStatus(r.Client, []string{strconv.Itoa(int(brokerId))}, r.KafkaCluster, v1beta1.PerBrokerConfigOutOfSync, log)
@@ -77,7 +91,7 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 		}
 
 		// validate the config
-		err := kClient.AlterPerBrokerConfig(brokerId, util.ConvertPropertiesToMapStringPointer(fullPerBrokerConfig), true)
+		err := kClient.AlterPerBrokerConfig(brokerId, configChanges, true)
 		if err != nil {
 			statusErr := k8sutil.UpdateBrokerStatus(r.Client, []string{strconv.Itoa(int(brokerId))}, r.KafkaCluster, v1beta1.PerBrokerConfigError, log)
 			if statusErr != nil {
@@ -86,14 +100,15 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 			return errors.WrapIfWithDetails(err, "could not validate per-broker broker config", "brokerId", brokerId)
 		}
 
-		// alter the config
-		err = kClient.AlterPerBrokerConfig(brokerId, util.ConvertPropertiesToMapStringPointer(fullPerBrokerConfig), false)
+		// alter only the changed configs so that the broker does not reload the ones left intact
+		log.Info("updating per-broker configs dynamically", "brokerId", brokerId, "configs", sortedConfigNames(configChanges))
+		err = kClient.AlterPerBrokerConfig(brokerId, configChanges, false)
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not alter broker config", "brokerId", brokerId)
 		}
 
 		// query the updated config
-		response, err := kClient.DescribePerBrokerConfig(brokerId, brokerConfigKeys)
+		response, err := kClient.DescribePerBrokerConfig(brokerId, fullPerBrokerConfig.Keys())
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not describe broker config", "brokerId", brokerId)
 		}
@@ -118,7 +133,7 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 	return nil
 }
 
-func (r *Reconciler) reconcileClusterWideDynamicConfig() error {
+func (r *Reconciler) reconcileClusterWideDynamicConfig(log logr.Logger) error {
 	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
 	if err != nil {
 		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
@@ -141,6 +156,10 @@ func (r *Reconciler) reconcileClusterWideDynamicConfig() error {
 	}
 
 	if !currentClusterWideConfig.Equal(parsedClusterWideConfig) {
+		if r.KafkaCluster.Spec.IsAdoptionPending() {
+			r.holdBackAdoptionChange(log, "the cluster-wide configs would be replaced by spec.clusterWideConfig")
+			return nil
+		}
 		err = kClient.AlterClusterWideConfig(util.ConvertPropertiesToMapStringPointer(parsedClusterWideConfig), true)
 		if err != nil {
 			return errors.WrapIf(err, "validation of cluster wide config update failed")
@@ -161,6 +180,9 @@ func shouldUpdatePerBrokerConfig(response []*sarama.ConfigEntry, brokerConfig *p
 	}
 
 	for _, conf := range response {
+		if conf.Sensitive {
+			continue
+		}
 		if val, ok := brokerConfig.Get(conf.Name); ok {
 			if val.Value() != conf.Value {
 				return true
@@ -170,3 +192,33 @@ func shouldUpdatePerBrokerConfig(response []*sarama.ConfigEntry, brokerConfig *p
 
 	return false
 }
+
+// perBrokerConfigChanges returns the configs to be altered to get from the current configs of a broker to the desired
+// ones: the differing configs are set while the dynamic configs of the broker which are no longer desired are deleted
+// (nil value). Sensitive configs are set if refreshSensitive is true as their values are not returned by the brokers.
+func perBrokerConfigChanges(response []*sarama.ConfigEntry, desired *properties.Properties, refreshSensitive bool) map[string]*string {
+	changes := make(map[string]*string)
+	for _, conf := range response {
+		property, ok := desired.Get(conf.Name)
+		if !ok {
+			if conf.Source == sarama.SourceDynamicBroker && !util.StringSliceContains(externallyManagedPerBrokerConfigs, conf.Name) {
+				changes[conf.Name] = nil
+			}
+			continue
+		}
+		if (conf.Sensitive && refreshSensitive) || (!conf.Sensitive && property.Value() != conf.Value) {
+			value := property.Value()
+			changes[conf.Name] = &value
+		}
+	}
+	return changes
+}
+
+func sortedConfigNames(configs map[string]*string) []string {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -95,3 +95,37 @@ config2=value3
 		}
 	}
 }
+
+func TestPerBrokerConfigChanges(t *testing.T) {
+	response := []*sarama.ConfigEntry{
+		{Name: "num.io.threads", Value: "8", Source: sarama.SourceStaticBroker},
+		{Name: "log.cleaner.threads", Value: "1", Source: sarama.SourceDefault},
+		{Name: "num.network.threads", Value: "5", Source: sarama.SourceDynamicBroker},
+		{Name: "leader.replication.throttled.rate", Value: "1000", Source: sarama.SourceDynamicBroker},
+		{Name: "listener.name.internal.plain.sasl.jaas.config", Sensitive: true, Source: sarama.SourceDynamicBroker},
+	}
+	desired, err := properties.NewFromString(`num.io.threads=16
+log.cleaner.threads=1
+listener.name.internal.plain.sasl.jaas.config=secret
+unknown.config=value
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := perBrokerConfigChanges(response, desired, false)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got: %v", sortedConfigNames(changes))
+	}
+	if value := changes["num.io.threads"]; value == nil || *value != "16" {
+		t.Errorf("expected num.io.threads to be set to 16")
+	}
+	if value, ok := changes["num.network.threads"]; !ok || value != nil {
+		t.Errorf("expected num.network.threads to be deleted")
+	}
+
+	changes = perBrokerConfigChanges(response, desired, true)
+	if value := changes["listener.name.internal.plain.sasl.jaas.config"]; value == nil || *value != "secret" {
+		t.Errorf("expected the sensitive config to be refreshed, got: %v", sortedConfigNames(changes))
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/util"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+// validateBrokerConfigs validates the broker configurations of the KafkaCluster against the config schema of the Kafka
+// version the brokers run and records the issues found in the status. With the Enforce policy an InvalidBrokerConfig
+// error is returned if any of the issues would make the brokers crash or refuse the config.
+func (r *Reconciler) validateBrokerConfigs(log logr.Logger) error {
+	policy := r.KafkaCluster.Spec.BrokerConfigValidation.GetPolicy()
+	if policy == v1beta1.BrokerConfigValidationPolicyDisabled {
+		return nil
+	}
+
+	status := r.brokerConfigValidationStatus()
+	if !reflect.DeepEqual(status, r.KafkaCluster.Status.BrokerConfigValidation) {
+		if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+			return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not update broker config validation status")
+		}
+	}
+
+	if policy == v1beta1.BrokerConfigValidationPolicyEnforce && status.HasErrors() {
+		return errorfactory.New(errorfactory.InvalidBrokerConfig{}, errors.New("broker configuration is invalid"),
+			"holding back broker configuration changes", "kafkaVersion", status.KafkaVersion)
+	}
+	return nil
+}
+
+func (r *Reconciler) brokerConfigValidationStatus() *v1beta1.BrokerConfigValidationStatus {
+	spec := r.KafkaCluster.Spec
+	clusterVersion := r.kafkaVersionOfImage(spec.GetClusterImage())
+	status := &v1beta1.BrokerConfigValidationStatus{KafkaVersion: clusterVersion}
+
+	status.Issues = appendConfigIssues(status.Issues, "readOnlyConfig", spec.ReadOnlyConfig,
+		clusterVersion, kafkautils.ConfigUpdateModeReadOnly)
+	status.Issues = appendConfigIssues(status.Issues, "clusterWideConfig", spec.ClusterWideConfig,
+		clusterVersion, kafkautils.ConfigUpdateModeClusterWide)
+
+	groupNames := make([]string, 0, len(spec.BrokerConfigGroups))
+	for name := range spec.BrokerConfigGroups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+	for _, name := range groupNames {
+		group := spec.BrokerConfigGroups[name]
+		status.Issues = appendConfigIssues(status.Issues, fmt.Sprintf("brokerConfigGroups[%s].config", name), group.Config,
+			r.kafkaVersionOfImage(util.GetBrokerImage(&group, spec.GetClusterImage())), kafkautils.ConfigUpdateModePerBroker)
+	}
+
+	for i, broker := range spec.Brokers {
+		brokerVersion := clusterVersion
+		if brokerConfig, err := broker.GetBrokerConfig(spec); err == nil && brokerConfig != nil {
+			brokerVersion = r.kafkaVersionOfImage(util.GetBrokerImage(brokerConfig, spec.GetClusterImage()))
+		}
+		status.Issues = appendConfigIssues(status.Issues, fmt.Sprintf("brokers[%d].readOnlyConfig", i), broker.ReadOnlyConfig,
+			brokerVersion, kafkautils.ConfigUpdateModeReadOnly)
+		if broker.BrokerConfig != nil {
+			status.Issues = appendConfigIssues(status.Issues, fmt.Sprintf("brokers[%d].brokerConfig.config", i), broker.BrokerConfig.Config,
+				brokerVersion, kafkautils.ConfigUpdateModePerBroker)
+		}
+	}
+	return status
+}
+
+// kafkaVersionOfImage returns the Kafka version of the given image based on its tag or, if the tag does not contain
+// the version, on the version reported by the brokers already running it
+func (r *Reconciler) kafkaVersionOfImage(image string) string {
+	if version := kafkautils.ParseKafkaVersion(image); version != "" {
+		return version
+	}
+
+	brokerIDs := make([]string, 0, len(r.KafkaCluster.Status.BrokersState))
+	for id := range r.KafkaCluster.Status.BrokersState {
+		brokerIDs = append(brokerIDs, id)
+	}
+	sort.Strings(brokerIDs)
+	for _, id := range brokerIDs {
+		brokerState := r.KafkaCluster.Status.BrokersState[id]
+		if brokerState.Image == image && brokerState.Version != "" {
+			return kafkautils.ParseKafkaVersion(brokerState.Version)
+		}
+	}
+	return ""
+}
+
+func appendConfigIssues(issues []v1beta1.BrokerConfigValidationIssue, source, config, kafkaVersion string,
+	updateMode kafkautils.ConfigUpdateMode) []v1beta1.BrokerConfigValidationIssue {
+	if config == "" {
+		return issues
+	}
+	parsedConfig, err := properties.NewFromString(config)
+	if err != nil {
+		return append(issues, v1beta1.BrokerConfigValidationIssue{
+			Source:   source,
+			Severity: v1beta1.ConfigIssueSeverityError,
+			Message:  fmt.Sprintf("could not parse config: %s", err),
+		})
+	}
+	for _, issue := range kafkautils.ValidateBrokerConfig(parsedConfig, kafkaVersion, updateMode) {
+		issues = append(issues, v1beta1.BrokerConfigValidationIssue{
+			Source:   source,
+			Key:      issue.Key,
+			Severity: issue.Severity,
+			Message:  issue.Message,
+		})
+	}
+	return issues
+}
@@ -0,0 +1,92 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestBrokerConfigValidationStatus(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			ClusterImage:      "ghcr.io/banzaicloud/kafka:2.13-3.1.0",
+			ReadOnlyConfig:    "auto.create.topics.enable=false\nport=9092",
+			ClusterWideConfig: "log.retention.ms=86400000\nnum.partitions=3",
+			BrokerConfigGroups: map[string]v1beta1.BrokerConfig{
+				"legacy": {
+					Image:  "example.com/kafka:custom",
+					Config: "leader.replication.throttled.rate=-1",
+				},
+			},
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfigGroup: "legacy", ReadOnlyConfig: "process.roles=broker"},
+				{Id: 1, BrokerConfig: &v1beta1.BrokerConfig{Config: "num.io.threads=8"}},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {Image: "example.com/kafka:custom", Version: "2.7.0"},
+			},
+		},
+	}
+
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			KafkaCluster: cluster,
+		},
+	}
+
+	expected := &v1beta1.BrokerConfigValidationStatus{
+		KafkaVersion: "3.1.0",
+		Issues: []v1beta1.BrokerConfigValidationIssue{
+			{
+				Source:   "readOnlyConfig",
+				Key:      "port",
+				Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message:  "config was removed in Kafka 3.0.0 and is ignored by the brokers",
+			},
+			{
+				Source:   "clusterWideConfig",
+				Key:      "num.partitions",
+				Severity: v1beta1.ConfigIssueSeverityError,
+				Message:  "config is read-only, it can not be updated dynamically, use readOnlyConfig instead",
+			},
+			{
+				Source:   "brokerConfigGroups[legacy].config",
+				Key:      "leader.replication.throttled.rate",
+				Severity: v1beta1.ConfigIssueSeverityError,
+				Message:  "value -1 is out of range, it must be at least 0",
+			},
+			{
+				Source:   "brokers[0].readOnlyConfig",
+				Key:      "process.roles",
+				Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message:  "config is only available from Kafka 2.8.0",
+			},
+		},
+	}
+
+	status := r.brokerConfigValidationStatus()
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected status: %+v, got: %+v", expected, status)
+	}
+	if !status.HasErrors() {
+		t.Error("expected the status to have errors")
+	}
+}
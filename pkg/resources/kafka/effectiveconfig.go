@@ -0,0 +1,79 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	brokerEffectiveConfigTemplate = "%s-effective-config"
+	// effectiveConfigPropertyName is the key of the broker configuration in the effective config ConfigMap
+	effectiveConfigPropertyName = "effective.properties"
+	// configSourcesPropertyName is the key of the sources of the broker configs in the effective config ConfigMap
+	configSourcesPropertyName = "sources.properties"
+	// hiddenConfigValue replaces the values of the sensitive configs in the effective config ConfigMap
+	hiddenConfigValue = "[hidden]"
+)
+
+// effectiveConfigMap returns the ConfigMap holding the configuration the broker runs with for auditing: the static
+// configs of server.properties (read-only configs, config group settings and operator generated configs) overridden
+// by the cluster-wide and the per-broker dynamic configs, in the same order of precedence as the broker applies them.
+// The source of each config and whether its change requires a broker restart are listed as well. The values of the
+// sensitive configs are hidden.
+func (r *Reconciler) effectiveConfigMap(id int32, renderedConfig *kafkautils.SourcedBrokerConfig) *corev1.ConfigMap {
+	effectiveConfig := properties.NewProperties()
+	configSources := properties.NewProperties()
+	for _, config := range renderedConfig.Effective() {
+		value := config.Value
+		if kafkautils.IsSensitiveConfig(config.Key) {
+			value = hiddenConfigValue
+		}
+		_ = effectiveConfig.Set(config.Key, value)
+
+		var mode string
+		switch {
+		case config.Dynamic:
+			mode = "dynamic"
+		case config.RestartRequired:
+			mode = "restart required"
+		default:
+			mode = "reloaded without restart"
+		}
+		_ = configSources.Set(config.Key, fmt.Sprintf("%s (%s)", config.Source, mode))
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: templates.ObjectMeta(
+			fmt.Sprintf(brokerEffectiveConfigTemplate+"-%d", r.KafkaCluster.Name, id),
+			apiutil.MergeLabels(
+				apiutil.LabelsForKafka(r.KafkaCluster.Name),
+				map[string]string{"brokerId": fmt.Sprintf("%d", id)},
+			),
+			r.KafkaCluster,
+		),
+		Data: map[string]string{
+			effectiveConfigPropertyName: effectiveConfig.String(),
+			configSourcesPropertyName:   configSources.String(),
+		},
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestEffectiveConfigMap(t *testing.T) {
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			KafkaCluster: &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec: v1beta1.KafkaClusterSpec{
+					ZKAddresses:       []string{"example.zk:2181"},
+					ReadOnlyConfig:    "background.threads=10\nlog.cleaner.threads=1\npassword.encoder.secret=secret",
+					ClusterWideConfig: "background.threads=20\nlog.cleaner.threads=2",
+					DevProfile:        v1beta1.DevProfileConfig{Enabled: true},
+					ListenersConfig: v1beta1.ListenersConfig{
+						InternalListeners: []v1beta1.InternalListenerConfig{
+							{
+								CommonListenerSpec: v1beta1.CommonListenerSpec{
+									Type:          v1beta1.SecurityProtocolPlaintext,
+									Name:          "internal",
+									ContainerPort: 9092,
+								},
+								UsedForInnerBrokerCommunication: true,
+							},
+						},
+					},
+					BrokerConfigGroups: map[string]v1beta1.BrokerConfig{
+						"default": {Config: "log.cleaner.threads=4"},
+					},
+					Brokers: []v1beta1.Broker{{
+						Id:                0,
+						BrokerConfigGroup: "default",
+						ReadOnlyConfig:    "auto.create.topics.enable=false",
+					}},
+				},
+			},
+		},
+	}
+	brokerConfig, err := r.KafkaCluster.Spec.Brokers[0].GetBrokerConfig(r.KafkaCluster.Spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderedConfig := r.renderBrokerConfig(0, brokerConfig, map[string]v1beta1.ListenerStatusList{},
+		map[string]v1beta1.ListenerStatusList{}, map[string]v1beta1.ListenerStatusList{}, nil, "", nil, saslPlainConfig{}, logr.Discard())
+
+	effectiveConfigMap := r.effectiveConfigMap(0, renderedConfig)
+
+	if effectiveConfigMap.Name != "kafka-effective-config-0" {
+		t.Errorf("unexpected name of effective config ConfigMap: %s", effectiveConfigMap.Name)
+	}
+	if effectiveConfigMap.Labels["brokerId"] != "0" {
+		t.Errorf("expected brokerId label, got labels: %v", effectiveConfigMap.Labels)
+	}
+	expected := `auto.create.topics.enable=false
+background.threads=20
+broker.id=0
+default.replication.factor=1
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT
+listeners=INTERNAL://:9092
+log.cleaner.threads=4
+min.insync.replicas=1
+offsets.topic.replication.factor=1
+password.encoder.secret=[hidden]
+transaction.state.log.min.isr=1
+transaction.state.log.replication.factor=1
+zookeeper.connect=example.zk:2181/
+`
+	if effective := effectiveConfigMap.Data[effectiveConfigPropertyName]; effective != expected {
+		t.Errorf("expected effective config:\n%s\ngot:\n%s", expected, effective)
+	}
+	expectedSources := `auto.create.topics.enable=brokerReadOnlyConfig (restart required)
+background.threads=clusterWideConfig (dynamic)
+broker.id=operator (restart required)
+default.replication.factor=operatorDefault (restart required)
+inter.broker.listener.name=operator (restart required)
+listener.security.protocol.map=operator (reloaded without restart)
+listeners=operator (reloaded without restart)
+log.cleaner.threads=brokerConfigGroup (dynamic)
+min.insync.replicas=operatorDefault (reloaded without restart)
+offsets.topic.replication.factor=operatorDefault (restart required)
+password.encoder.secret=readOnlyConfig (restart required)
+transaction.state.log.min.isr=operatorDefault (restart required)
+transaction.state.log.replication.factor=operatorDefault (restart required)
+zookeeper.connect=operator (restart required)
+`
+	if sources := effectiveConfigMap.Data[configSourcesPropertyName]; sources != expectedSources {
+		t.Errorf("expected config sources:\n%s\ngot:\n%s", expectedSources, sources)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+)
+
+// killBrokerDuringRollingUpgrade deletes the pod of the broker selected by the KillBrokerDuringRollingUpgrade fault
+// after the pod of the given broker was restarted, then removes the fault from the KafkaCluster so it is injected once
+func (r *Reconciler) killBrokerDuringRollingUpgrade(log logr.Logger, restartedBrokerID string) error {
+	brokerID, ok := faultinjection.BrokerToKill(log, r.KafkaCluster, restartedBrokerID)
+	if !ok {
+		return nil
+	}
+
+	podList := &corev1.PodList{}
+	err := r.Client.List(context.TODO(), podList, client.InNamespace(r.KafkaCluster.Namespace),
+		client.MatchingLabels(apiutil.MergeLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name), map[string]string{"brokerId": brokerID})))
+	if err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "listing broker pods failed", "brokerId", brokerID)
+	}
+	for i := range podList.Items {
+		if err := r.Client.Delete(context.TODO(), &podList.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return errorfactory.New(errorfactory.APIFailure{}, err, "deleting broker pod failed", "brokerId", brokerID)
+		}
+	}
+
+	typeMeta := r.KafkaCluster.TypeMeta
+	patch := client.MergeFrom(r.KafkaCluster.DeepCopy())
+	delete(r.KafkaCluster.Annotations, string(faultinjection.KillBrokerDuringRollingUpgrade))
+	if err := r.Client.Patch(context.TODO(), r.KafkaCluster, patch); err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "removing injected fault from KafkaCluster failed")
+	}
+	// patch loses the typeMeta of the config that's used later when setting ownerrefs
+	r.KafkaCluster.TypeMeta = typeMeta
+	return nil
+}
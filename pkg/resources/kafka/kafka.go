@@ -21,6 +21,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
@@ -29,6 +30,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiutil "github.com/banzaicloud/koperator/api/util"
@@ -38,6 +40,7 @@ import (
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
 	"github.com/banzaicloud/koperator/pkg/jmxextractor"
 	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	"github.com/banzaicloud/koperator/pkg/kafkaclient"
@@ -58,6 +61,7 @@ type brokerReconcilePriority int
 
 const (
 	componentName         = "kafka"
+	kafkaContainerName    = "kafka"
 	brokerConfigTemplate  = "%s-config"
 	brokerStorageTemplate = "%s-%d-storage-%d-"
 
@@ -90,10 +94,15 @@ const (
 type Reconciler struct {
 	resources.Reconciler
 	kafkaClientProvider kafkaclient.Provider
+	recorder            record.EventRecorder
+	// adoptionHeldBackChanges are the changes of the adopted cluster held back during the reconciliation
+	adoptionHeldBackChanges []string
+	// pendingRestarts are the broker pod restarts deferred until the brokers can be ordered by their load
+	pendingRestarts []pendingBrokerRestart
 }
 
 // New creates a new reconciler for Kafka
-func New(client client.Client, directClient client.Reader, cluster *v1beta1.KafkaCluster, kafkaClientProvider kafkaclient.Provider) *Reconciler {
+func New(client client.Client, directClient client.Reader, cluster *v1beta1.KafkaCluster, kafkaClientProvider kafkaclient.Provider, recorder record.EventRecorder) *Reconciler {
 	return &Reconciler{
 		Reconciler: resources.Reconciler{
 			Client:       client,
@@ -101,6 +110,7 @@ func New(client client.Client, directClient client.Reader, cluster *v1beta1.Kafk
 			KafkaCluster: cluster,
 		},
 		kafkaClientProvider: kafkaClientProvider,
+		recorder:            recorder,
 	}
 }
 func getCreatedPvcForBroker(c client.Client, brokerID int32, namespace, crName string) ([]corev1.PersistentVolumeClaim, error) {
@@ -176,12 +186,26 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
+	if r.KafkaCluster.Spec.Adoption != nil {
+		if err := r.adoptBrokers(log); err != nil {
+			return err
+		}
+	}
+
 	// Handle Pod delete
 	err := r.reconcileKafkaPodDelete(log)
 	if err != nil {
 		return errors.WrapIf(err, "failed to reconcile resource")
 	}
 
+	if err := r.reconcileDecommissionedBrokers(log); err != nil {
+		return err
+	}
+
+	if err := r.reconcileBrokerMaintenance(log); err != nil {
+		return err
+	}
+
 	extListenerStatuses, err := r.createExternalListenerStatuses(log)
 	if err != nil {
 		return errors.WrapIf(err, "could not update status for external listeners")
@@ -207,6 +231,30 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		return err
 	}
 
+	saslPlain, err := r.getSASLPlainConfig(log)
+	if err != nil {
+		return err
+	}
+
+	if err := validatePrincipalBuilder(r.KafkaCluster.Spec.ListenersConfig.PrincipalBuilder); err != nil {
+		return err
+	}
+
+	if err := r.validateBrokerConfigs(log); err != nil {
+		return err
+	}
+
+	if r.KafkaCluster.Spec.KRaftMode {
+		if err := r.reconcileKRaftClusterID(log); err != nil {
+			return err
+		}
+	}
+
+	if r.KafkaCluster.Spec.IsDevProfileEnabled() && len(r.KafkaCluster.Spec.Brokers) != 1 {
+		return errorfactory.New(errorfactory.InvalidBrokerConfig{}, errors.New("dev profile requires exactly one broker"),
+			"holding back broker changes", "brokers", len(r.KafkaCluster.Spec.Brokers))
+	}
+
 	brokersVolumes := make(map[string][]*corev1.PersistentVolumeClaim, len(r.KafkaCluster.Spec.Brokers))
 	for _, broker := range r.KafkaCluster.Spec.Brokers {
 		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
@@ -214,6 +262,11 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 			return errors.WrapIf(err, "failed to reconcile resource")
 		}
 
+		// brokers with ephemeral storage keep their data on emptyDir volumes
+		if brokerConfig.IsEphemeralStorageEnabled() {
+			continue
+		}
+
 		var brokerVolumes []*corev1.PersistentVolumeClaim
 		for index, storage := range brokerConfig.StorageConfigs {
 			o := r.pvc(broker.Id, index, storage, log)
@@ -237,12 +290,26 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		return errors.WrapIf(err, "failed to list broker pods that belong to Kafka cluster")
 	}
 
+	if err := r.reconcileVersionUpgrade(log, brokerPods.Items); err != nil {
+		return err
+	}
+
+	if err := r.reconcileResourceRecommendations(log); err != nil {
+		return err
+	}
+
 	controllerID, err := r.determineControllerId()
 	if err != nil {
 		log.Error(err, "could not find controller broker")
 	}
 
-	reorderedBrokers := reorderBrokers(brokerPods, r.KafkaCluster.Spec.Brokers, r.KafkaCluster.Status.BrokersState, controllerID)
+	if r.KafkaCluster.Spec.RollingUpgradeConfig.GetOrder() != v1beta1.RollingUpgradeOrderControllerLast {
+		// the controller is restarted in its turn of the chosen order
+		controllerID = -1
+	}
+	restartRanks := r.brokerRestartRanks()
+
+	reorderedBrokers := reorderBrokers(brokerPods, r.KafkaCluster.Spec.Brokers, r.KafkaCluster.Status.BrokersState, controllerID, restartRanks)
 	allBrokerDynamicConfigSucceeded := true
 	for _, broker := range reorderedBrokers {
 		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
@@ -250,22 +317,30 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 			return errors.WrapIf(err, "failed to reconcile resource")
 		}
 
+		renderedConfig := r.renderBrokerConfig(broker.Id, brokerConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, saslPlain, log)
 		var configMap *corev1.ConfigMap
 		if r.KafkaCluster.Spec.RackAwareness == nil {
-			configMap = r.configMap(broker.Id, brokerConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, log)
+			configMap = r.configMap(broker.Id, brokerConfig, renderedConfig)
 			err := k8sutil.Reconcile(log, r.Client, configMap, r.KafkaCluster)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", configMap.GetObjectKind().GroupVersionKind())
 			}
 		} else if brokerState, ok := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(broker.Id))]; ok {
 			if brokerState.RackAwarenessState != "" {
-				configMap = r.configMap(broker.Id, brokerConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, log)
+				configMap = r.configMap(broker.Id, brokerConfig, renderedConfig)
 				err := k8sutil.Reconcile(log, r.Client, configMap, r.KafkaCluster)
 				if err != nil {
 					return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", configMap.GetObjectKind().GroupVersionKind())
 				}
 			}
 		}
+		if configMap != nil {
+			effectiveConfigMap := r.effectiveConfigMap(broker.Id, renderedConfig)
+			err := k8sutil.Reconcile(log, r.Client, effectiveConfigMap, r.KafkaCluster)
+			if err != nil {
+				return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", effectiveConfigMap.GetObjectKind().GroupVersionKind())
+			}
+		}
 
 		pvcs, err := getCreatedPvcForBroker(r.Client, broker.Id, r.KafkaCluster.Namespace, r.KafkaCluster.Name)
 		if err != nil {
@@ -287,16 +362,24 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		if err = r.updateStatusWithDockerImageAndVersion(broker.Id, brokerConfig, log); err != nil {
 			return err
 		}
+		// The controller-only nodes of KRaft mode are not reachable through the admin client
+		if r.KafkaCluster.Spec.KRaftMode && !brokerConfig.IsBrokerNode() {
+			continue
+		}
 		// If dynamic configs can not be set then let the loop continue to the next broker,
 		// after the loop we return error. This solve that case when other brokers could get healthy,
 		// but the loop exits too soon because dynamic configs can not be set.
-		err = r.reconcilePerBrokerDynamicConfig(broker.Id, brokerConfig, configMap, log)
+		err = r.reconcilePerBrokerDynamicConfig(broker.Id, brokerConfig, configMap, saslPlain, log)
 		if err != nil {
 			log.Error(err, "setting dynamic configs has failed", "brokerID", broker.Id)
 			allBrokerDynamicConfigSucceeded = false
 		}
 	}
 
+	if err := r.restartPendingBrokers(log); err != nil {
+		return err
+	}
+
 	if !allBrokerDynamicConfigSucceeded {
 		// re-reconcile to retry setting the dynamic configs
 		return errors.NewWithDetails("setting dynamic configs for some brokers has failed",
@@ -305,7 +388,7 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 			"clusterNamespace", r.KafkaCluster.Namespace)
 	}
 
-	if err = r.reconcileClusterWideDynamicConfig(); err != nil {
+	if err = r.reconcileClusterWideDynamicConfig(log); err != nil {
 		return err
 	}
 
@@ -334,6 +417,16 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
+	if r.KafkaCluster.Spec.Adoption != nil {
+		if err := r.reconcileAdoption(log); err != nil {
+			return err
+		}
+	} else if r.KafkaCluster.Status.Adoption != nil {
+		if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, (*v1beta1.AdoptionStatus)(nil), log); err != nil {
+			return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not clear adoption status")
+		}
+	}
+
 	log.V(1).Info("Reconciled")
 
 	return nil
@@ -367,15 +460,23 @@ func (r *Reconciler) reconcileKafkaPodDelete(log logr.Logger) error {
 		}
 	}
 
+	if len(podsDeletedFromSpec) > 0 && r.KafkaCluster.Spec.IsAdoptionPending() {
+		for _, pod := range podsDeletedFromSpec {
+			r.holdBackAdoptionChange(log, fmt.Sprintf("broker %s not in spec.brokers would be removed", pod.Labels["brokerId"]))
+		}
+		return nil
+	}
+
 	if len(podsDeletedFromSpec) > 0 {
 		if !arePodsAlreadyDeleted(podsDeletedFromSpec, log) {
 			cruiseControlURL := scale.CruiseControlURLFromKafkaCluster(r.KafkaCluster)
 			// FIXME: we should reuse the context of the Kafka Controller
-			cc, err := scale.NewCruiseControlScaler(context.TODO(), scale.CruiseControlURLFromKafkaCluster(r.KafkaCluster))
+			cc, err := scale.DefaultScalerRegistry.Scaler(context.TODO(), r.Client, r.KafkaCluster)
 			if err != nil {
 				return errorfactory.New(errorfactory.CruiseControlNotReady{}, err,
 					"failed to initialize Cruise Control Scaler", "cruise control url", cruiseControlURL)
 			}
+			cc = faultinjection.CruiseControlScaler(log, r.KafkaCluster, cc)
 
 			brokerStates := []scale.KafkaBrokerState{
 				scale.KafkaBrokerNew,
@@ -383,7 +484,7 @@ func (r *Reconciler) reconcileKafkaPodDelete(log logr.Logger) error {
 				scale.KafkaBrokerDemoted,
 				scale.KafkaBrokerBadDisks,
 			}
-			availableBrokers, err := cc.BrokersWithState(brokerStates...)
+			availableBrokers, err := cc.BrokersWithState(context.TODO(), brokerStates...)
 			if err != nil {
 				log.Error(err, "failed to get the list of available brokers from Cruise Control")
 				return errorfactory.New(errorfactory.CruiseControlNotReady{}, err,
@@ -413,6 +514,12 @@ func (r *Reconciler) reconcileKafkaPodDelete(log logr.Logger) error {
 				}
 
 				if len(brokersPendingGracefulDownscale) > 0 {
+					if err := r.checkSmokeTestGate(); err != nil {
+						return err
+					}
+					if err := r.runPreflightChecks(log, v1beta1.PreflightOperationScaleDown, brokersPendingGracefulDownscale); err != nil {
+						return err
+					}
 					err = k8sutil.UpdateBrokerStatus(r.Client, brokersPendingGracefulDownscale, r.KafkaCluster,
 						v1beta1.GracefulActionState{
 							CruiseControlState: v1beta1.GracefulDownscaleRequired,
@@ -459,6 +566,11 @@ func (r *Reconciler) reconcileKafkaPodDelete(log logr.Logger) error {
 			} else {
 				log.V(1).Info("configMap for broker deleted", "configMap name", configMapName, "brokerId", broker.Labels["brokerId"])
 			}
+			effectiveConfigMapName := fmt.Sprintf(brokerEffectiveConfigTemplate+"-%s", r.KafkaCluster.Name, broker.Labels["brokerId"])
+			err = r.Client.Delete(context.TODO(), &corev1.ConfigMap{ObjectMeta: templates.ObjectMeta(effectiveConfigMapName, apiutil.LabelsForKafka(r.KafkaCluster.Name), r.KafkaCluster)})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return errors.WrapIfWithDetails(err, "could not delete effective config configmap for broker", "id", broker.Labels["brokerId"])
+			}
 			if !r.KafkaCluster.Spec.HeadlessServiceEnabled {
 				serviceName := fmt.Sprintf("%s-%s", r.KafkaCluster.Name, broker.Labels["brokerId"])
 				err = r.Client.Delete(context.TODO(), &corev1.Service{ObjectMeta: templates.ObjectMeta(serviceName, apiutil.LabelsForKafka(r.KafkaCluster.Name), r.KafkaCluster)})
@@ -471,8 +583,13 @@ func (r *Reconciler) reconcileKafkaPodDelete(log logr.Logger) error {
 				}
 				log.V(1).Info("service for broker deleted", "service name", serviceName, "brokerId", broker.Labels["brokerId"])
 			}
+			var retainedPvcs []string
 			for _, volume := range broker.Spec.Volumes {
 				if strings.HasPrefix(volume.Name, kafkaDataVolumeMount) {
+					if r.KafkaCluster.Spec.GetBrokerDataRetentionPeriod() > 0 {
+						retainedPvcs = append(retainedPvcs, volume.PersistentVolumeClaim.ClaimName)
+						continue
+					}
 					err = r.Client.Delete(context.TODO(), &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
 						Name:      volume.PersistentVolumeClaim.ClaimName,
 						Namespace: r.KafkaCluster.Namespace,
@@ -487,6 +604,11 @@ func (r *Reconciler) reconcileKafkaPodDelete(log logr.Logger) error {
 					log.V(1).Info("pvc for broker deleted", "pvc name", volume.PersistentVolumeClaim.ClaimName, "brokerId", broker.Labels["brokerId"])
 				}
 			}
+			if len(retainedPvcs) > 0 {
+				if err := r.retainDecommissionedBrokerData(log, broker.Labels["brokerId"], retainedPvcs); err != nil {
+					return err
+				}
+			}
 			err = k8sutil.DeleteStatus(r.Client, broker.Labels["brokerId"], r.KafkaCluster, log)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "could not delete status for broker", "id", broker.Labels["brokerId"])
@@ -699,10 +821,23 @@ func (r *Reconciler) reconcileKafkaPod(log logr.Logger, desiredPod *corev1.Pod,
 			return errorfactory.New(errorfactory.StatusUpdateError{}, statusErr, "updating per broker config status for resource failed", "kind", desiredType)
 		}
 
-		if val, ok := r.KafkaCluster.Status.BrokersState[desiredPod.Labels["brokerId"]]; ok && val.GracefulActionState.CruiseControlState != v1beta1.GracefulUpscaleSucceeded {
+		val, ok := r.KafkaCluster.Status.BrokersState[desiredPod.Labels["brokerId"]]
+		// the data of a broker with ephemeral storage is lost with its pod, the new pod starts with empty log dirs
+		// so the partitions have to be re-replicated to the broker again
+		dataLost := ok && bConfig.IsEphemeralStorageEnabled() && val.GracefulActionState.CruiseControlState == v1beta1.GracefulUpscaleSucceeded
+		if dataLost {
+			log.Info("broker pod with ephemeral storage was replaced, its data is lost", "brokerId", desiredPod.Labels["brokerId"])
+		}
+		if ok && (val.GracefulActionState.CruiseControlState != v1beta1.GracefulUpscaleSucceeded || dataLost) {
 			gracefulActionState := v1beta1.GracefulActionState{ErrorMessage: "CruiseControl not yet ready", CruiseControlState: v1beta1.GracefulUpscaleSucceeded}
 
-			if r.KafkaCluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady {
+			switch {
+			case r.KafkaCluster.Spec.IsDevProfileEnabled():
+				// there is no Cruise Control to wait for with the dev profile
+				gracefulActionState = v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}
+			case r.KafkaCluster.Spec.UsesBuiltInRebalancer():
+				gracefulActionState = v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleRequired}
+			case r.KafkaCluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady:
 				gracefulActionState = v1beta1.GracefulActionState{ErrorMessage: "", CruiseControlState: v1beta1.GracefulUpscaleRequired}
 			}
 			statusErr = k8sutil.UpdateBrokerStatus(r.Client, []string{desiredPod.Labels["brokerId"]}, r.KafkaCluster, gracefulActionState, log)
@@ -734,7 +869,7 @@ func (r *Reconciler) reconcileKafkaPod(log logr.Logger, desiredPod *corev1.Pod,
 	default:
 		return errorfactory.New(errorfactory.TooManyResources{}, errors.New("reconcile failed"), "more then one matching pod found", "labels", matchingLabels)
 	}
-	err = r.handleRollingUpgrade(log, desiredPod, currentPod, desiredType)
+	err = r.handleRollingUpgrade(log, desiredPod, currentPod, desiredType, bConfig)
 	if err != nil {
 		return errors.Wrap(err, "could not handle rolling upgrade")
 	}
@@ -747,7 +882,7 @@ func (r *Reconciler) updateStatusWithDockerImageAndVersion(brokerId int32, broke
 		r.KafkaCluster.Spec.GetKubernetesClusterDomain(), r.KafkaCluster.GetName(), log)
 
 	kafkaVersion, err := jmxExp.ExtractDockerImageAndVersion(brokerId, brokerConfig,
-		r.KafkaCluster.Spec.GetClusterImage(), r.KafkaCluster.Spec.HeadlessServiceEnabled)
+		r.clusterImage(), r.KafkaCluster.Spec.HeadlessServiceEnabled)
 	if err != nil {
 		return err
 	}
@@ -759,7 +894,8 @@ func (r *Reconciler) updateStatusWithDockerImageAndVersion(brokerId int32, broke
 	return nil
 }
 
-func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPod *corev1.Pod, desiredType reflect.Type) error {
+func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPod *corev1.Pod, desiredType reflect.Type,
+	bConfig *v1beta1.BrokerConfig) error {
 	// Since toleration does not support patchStrategy:"merge,retainKeys",
 	// we need to add all toleration from the current pod if the toleration is set in the CR
 	if len(desiredPod.Spec.Tolerations) > 0 {
@@ -775,15 +911,13 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 		desiredPod.Spec.Tolerations = uniqueTolerations
 	}
 	// Check if the resource actually updated
+	metadataOnly := false
 	patchResult, err := patch.DefaultPatchMaker.Calculate(currentPod, desiredPod)
 	switch {
 	case err != nil:
 		log.Error(err, "could not match objects", "kind", desiredType)
 	case patchResult.IsEmpty():
-		if !k8sutil.IsPodContainsTerminatedContainer(currentPod) &&
-			r.KafkaCluster.Status.BrokersState[currentPod.Labels["brokerId"]].ConfigurationState == v1beta1.ConfigInSync &&
-			!k8sutil.IsPodContainsEvictedContainer(currentPod) &&
-			!k8sutil.IsPodContainsShutdownContainer(currentPod) {
+		if r.isKafkaPodInSync(currentPod) {
 			log.V(1).Info("resource is in sync")
 			return nil
 		}
@@ -793,17 +927,81 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 			"current", string(patchResult.Current),
 			"modified", string(patchResult.Modified),
 			"original", string(patchResult.Original))
+		metadataOnly, err = isKafkaPodMetadataOnlyChanged(currentPod, desiredPod)
+		if err != nil {
+			log.Error(err, "could not match objects", "kind", desiredType)
+		}
 	}
 
 	if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(desiredPod); err != nil {
 		return errors.WrapIf(err, "could not apply last state to annotation")
 	}
 
+	// Labels and annotations of a running pod can be changed without restarting the broker
+	if metadataOnly && r.isKafkaPodInSync(currentPod) {
+		return r.patchKafkaPodMetadata(log, desiredPod, currentPod, desiredType)
+	}
+
+	if r.KafkaCluster.Spec.IsAdoptionPending() {
+		r.holdBackAdoptionChange(log, fmt.Sprintf("broker %s would be restarted", currentPod.Labels["brokerId"]))
+		return nil
+	}
+
+	// the rolling upgrades of a paused broker group are held back, its failed broker pods are replaced regardless
+	if bConfig.IsRollingUpgradePaused() && podRestartUrgency(currentPod) != v1beta1.OperationUrgencyUrgent {
+		log.Info("broker pod restart held back as the rolling upgrade of the broker is paused",
+			"pod", currentPod.GetName(), "brokerId", currentPod.Labels["brokerId"])
+		return nil
+	}
+
+	bypassReason, err := r.checkMaintenanceWindow(time.Now(), podRestartUrgency(currentPod))
+	if err != nil {
+		return errorfactory.New(errorfactory.MaintenanceWindowClosed{}, err, "broker pod restart postponed", "pod", currentPod.GetName())
+	}
+
+	if r.KafkaCluster.Spec.RollingUpgradeConfig.GetOrder() == v1beta1.RollingUpgradeOrderLowestLoadFirst {
+		// the load of the brokers is looked up only once all the brokers waiting for a restart are known
+		r.pendingRestarts = append(r.pendingRestarts, pendingBrokerRestart{
+			brokerID: currentPod.Labels["brokerId"],
+			restart: func() error {
+				return r.restartBrokerPod(log, desiredPod, currentPod, desiredType, bConfig, bypassReason)
+			},
+		})
+		return nil
+	}
+	return r.restartBrokerPod(log, desiredPod, currentPod, desiredType, bConfig, bypassReason)
+}
+
+// restartBrokerPod deletes the broker pod to be recreated with the desired spec, once the rolling upgrade gates and
+// the health of the cluster let it restart
+func (r *Reconciler) restartBrokerPod(log logr.Logger, desiredPod, currentPod *corev1.Pod, desiredType reflect.Type,
+	bConfig *v1beta1.BrokerConfig, bypassReason string) error {
 	if !k8sutil.IsPodContainsTerminatedContainer(currentPod) {
 		if r.KafkaCluster.Status.State != v1beta1.KafkaClusterRollingUpgrading {
+			if err := r.checkSmokeTestGate(); err != nil {
+				return err
+			}
+			if isKafkaVersionUpgrade(currentPod, desiredPod) {
+				if err := r.checkCanaryGate(log, desiredPod); err != nil {
+					return err
+				}
+				if err := r.runPreflightChecks(log, v1beta1.PreflightOperationVersionUpgrade, nil); err != nil {
+					return err
+				}
+			}
 			if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, v1beta1.KafkaClusterRollingUpgrading, log); err != nil {
 				return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting state to rolling upgrade failed")
 			}
+			record := v1beta1.OperationRecord{
+				Type:        v1beta1.OperationTypeRollingUpgrade,
+				TriggeredBy: rollingUpgradeTrigger(currentPod, desiredPod),
+				StartedAt:   time.Now().Format(operationTimeFormat),
+				Outcome:     v1beta1.OperationRunning,
+			}
+			if err := k8sutil.RecordOperation(r.Client, r.KafkaCluster, record, log); err != nil {
+				return errorfactory.New(errorfactory.StatusUpdateError{}, err, "recording rolling upgrade failed")
+			}
+			r.pauseSamplingForRollingUpgrade(log)
 		}
 
 		if r.KafkaCluster.Status.State == v1beta1.KafkaClusterRollingUpgrading {
@@ -814,13 +1012,14 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 			if err != nil {
 				return errors.WrapIf(err, "failed to reconcile resource")
 			}
-			for _, pod := range podList.Items {
-				pod := pod
-				if k8sutil.IsMarkedForDeletion(pod.ObjectMeta) {
-					return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("pod is still terminating"), "rolling upgrade in progress")
-				}
-				if k8sutil.IsPodContainsPendingContainer(&pod) {
-					return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("pod is still creating"), "rolling upgrade in progress")
+			restartingPods := restartingBrokerPods(podList.Items)
+			if err := r.checkRollingUpgradeConcurrency(currentPod, restartingPods); err != nil {
+				return err
+			}
+
+			if r.KafkaCluster.Spec.KRaftMode {
+				if err := r.checkControllerQuorum(podList.Items, currentPod); err != nil {
+					return err
 				}
 			}
 
@@ -846,23 +1045,27 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 				log.Info("out-of-sync replicas", "IDs", outOfSyncReplicas)
 			}
 
-			impactedReplicas := make(map[int32]struct{})
-			for _, brokerID := range allOfflineReplicas {
-				impactedReplicas[brokerID] = struct{}{}
+			// the replicas of the brokers being restarted are expected to be offline
+			restartingBrokers := brokerIDsOfPods(restartingPods)
+			if err := r.checkRestartingPartitions(kClient, currentPod, restartingBrokers); err != nil {
+				return err
 			}
-			for _, brokerID := range outOfSyncReplicas {
-				impactedReplicas[brokerID] = struct{}{}
+			impactedReplicas := make(map[int32]struct{})
+			for _, brokerID := range append(allOfflineReplicas, outOfSyncReplicas...) {
+				if _, ok := restartingBrokers[brokerID]; !ok {
+					impactedReplicas[brokerID] = struct{}{}
+				}
 			}
 
 			errorCount += len(impactedReplicas)
 
-			if errorCount >= r.KafkaCluster.Spec.RollingUpgradeConfig.FailureThreshold {
+			if errorCount >= bConfig.GetRollingUpgradeFailureThreshold(r.KafkaCluster.Spec.RollingUpgradeConfig) {
 				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("cluster is not healthy"), "rolling upgrade in progress")
 			}
 		}
 	}
 
-	err = r.Client.Delete(context.TODO(), currentPod)
+	err := r.Client.Delete(context.TODO(), currentPod)
 	if err != nil {
 		return errorfactory.New(errorfactory.APIFailure{}, err, "deleting resource failed", "kind", desiredType)
 	}
@@ -877,6 +1080,51 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 		}
 	}
 	log.Info("broker pod deleted", "pod", currentPod.GetName(), "brokerId", currentPod.Labels["brokerId"])
+	if err := r.killBrokerDuringRollingUpgrade(log, currentPod.Labels["brokerId"]); err != nil {
+		return err
+	}
+	if bypassReason != "" {
+		r.recorder.Eventf(r.KafkaCluster, corev1.EventTypeWarning, maintenanceWindowBypassedReason,
+			"broker pod %s restarted outside of maintenance windows: %s", currentPod.GetName(), bypassReason)
+	}
+	return nil
+}
+
+// isKafkaPodInSync returns whether the broker pod is running with the current configuration of the broker
+func (r *Reconciler) isKafkaPodInSync(currentPod *corev1.Pod) bool {
+	return !k8sutil.IsPodContainsTerminatedContainer(currentPod) &&
+		r.KafkaCluster.Status.BrokersState[currentPod.Labels["brokerId"]].ConfigurationState == v1beta1.ConfigInSync &&
+		!k8sutil.IsPodContainsEvictedContainer(currentPod) &&
+		!k8sutil.IsPodContainsShutdownContainer(currentPod)
+}
+
+// isKafkaPodMetadataOnlyChanged returns whether the desired broker pod differs from the current one only in its
+// labels and annotations, a change of the restart generations requires a restart
+func isKafkaPodMetadataOnlyChanged(currentPod, desiredPod *corev1.Pod) (bool, error) {
+	if isRestartRequested(currentPod, desiredPod) {
+		return false, nil
+	}
+	desiredPodWithCurrentMetadata := desiredPod.DeepCopy()
+	desiredPodWithCurrentMetadata.Labels = currentPod.Labels
+	desiredPodWithCurrentMetadata.Annotations = currentPod.Annotations
+	patchResult, err := patch.DefaultPatchMaker.Calculate(currentPod, desiredPodWithCurrentMetadata)
+	if err != nil {
+		return false, err
+	}
+	return patchResult.IsEmpty(), nil
+}
+
+// patchKafkaPodMetadata patches the labels and annotations of the current broker pod to the desired ones in place
+func (r *Reconciler) patchKafkaPodMetadata(log logr.Logger, desiredPod, currentPod *corev1.Pod, desiredType reflect.Type) error {
+	patchResult, err := patch.DefaultPatchMaker.Calculate(currentPod, desiredPod)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not match objects", "kind", desiredType)
+	}
+	err = r.Client.Patch(context.TODO(), currentPod, client.RawPatch(types.StrategicMergePatchType, patchResult.Patch))
+	if err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "patching resource failed", "kind", desiredType)
+	}
+	log.Info("broker pod labels and annotations updated", "pod", currentPod.GetName(), "brokerId", currentPod.Labels["brokerId"])
 	return nil
 }
 
@@ -914,6 +1162,9 @@ func (r *Reconciler) reconcileKafkaPvc(log logr.Logger, brokersDesiredPvcs map[s
 				if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(desiredPvc); err != nil {
 					return errors.WrapIf(err, "could not apply last state to annotation")
 				}
+				if err := faultinjection.PVCProvisioningError(log, r.KafkaCluster); err != nil {
+					return errorfactory.New(errorfactory.APIFailure{}, err, "creating resource failed", "kind", desiredType)
+				}
 				if err := r.Client.Create(context.TODO(), desiredPvc); err != nil {
 					return errorfactory.New(errorfactory.APIFailure{}, err, "creating resource failed", "kind", desiredType)
 				}
@@ -930,7 +1181,12 @@ func (r *Reconciler) reconcileKafkaPvc(log logr.Logger, brokersDesiredPvcs map[s
 					// then we make it happening with status update.
 					if _, ok := r.KafkaCluster.Status.BrokersState[brokerId].GracefulActionState.VolumeStates[mountPath]; !ok &&
 						currentPvc.Status.Phase == corev1.ClaimBound {
-						brokerVolumesState[mountPath] = v1beta1.VolumeState{CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired}
+						volumeState := v1beta1.GracefulDiskRebalanceRequired
+						if r.KafkaCluster.Spec.UsesBuiltInRebalancer() {
+							// the built-in rebalancer does not move replicas between the disks of a broker
+							volumeState = v1beta1.GracefulDiskRebalanceSucceeded
+						}
+						brokerVolumesState[mountPath] = v1beta1.VolumeState{CruiseControlVolumeState: volumeState}
 					}
 					break
 				}
@@ -941,6 +1197,9 @@ func (r *Reconciler) reconcileKafkaPvc(log logr.Logger, brokersDesiredPvcs map[s
 				if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(desiredPvc); err != nil {
 					return errors.WrapIf(err, "could not apply last state to annotation")
 				}
+				if err := faultinjection.PVCProvisioningError(log, r.KafkaCluster); err != nil {
+					return errorfactory.New(errorfactory.APIFailure{}, err, "creating resource failed", "kind", desiredType)
+				}
 				if err := r.Client.Create(context.TODO(), desiredPvc); err != nil {
 					return errorfactory.New(errorfactory.APIFailure{}, err, "creating resource failed", "kind", desiredType)
 				}
@@ -1018,7 +1277,7 @@ func (r *Reconciler) createExternalListenerStatuses(log logr.Logger) (map[string
 	extListenerStatuses := make(map[string]v1beta1.ListenerStatusList, len(r.KafkaCluster.Spec.ListenersConfig.ExternalListeners))
 	for _, eListener := range r.KafkaCluster.Spec.ListenersConfig.ExternalListeners {
 		// in case if external listener uses loadbalancer type of service and istioControlPlane is not specified than we skip this listener from status update. In this way this external listener will not be in the configmap.
-		if eListener.GetAccessMethod() == corev1.ServiceTypeLoadBalancer && r.KafkaCluster.Spec.GetIngressController() == istioingressutils.IngressControllerName && r.KafkaCluster.Spec.IstioControlPlane == nil {
+		if eListener.GetAccessMethod() == corev1.ServiceTypeLoadBalancer && eListener.GetIngressController(r.KafkaCluster.Spec.GetIngressController()) == istioingressutils.IngressControllerName && r.KafkaCluster.Spec.IstioControlPlane == nil {
 			continue
 		}
 		var host string
@@ -1036,7 +1295,7 @@ func (r *Reconciler) createExternalListenerStatuses(log logr.Logger) (map[string
 			if iConfig.HostnameOverride != "" {
 				host = iConfig.HostnameOverride
 			} else if eListener.GetAccessMethod() == corev1.ServiceTypeLoadBalancer {
-				foundLBService, err = getServiceFromExternalListener(r.Client, r.KafkaCluster, eListener.Name, iConfigName)
+				foundLBService, err = getServiceFromExternalListener(r.Client, r.KafkaCluster, eListener, iConfigName)
 				if err != nil {
 					return nil, errors.WrapIfWithDetails(err, "could not get service corresponding to the external listener", "externalListenerName", eListener.Name)
 				}
@@ -1050,7 +1309,7 @@ func (r *Reconciler) createExternalListenerStatuses(log logr.Logger) (map[string
 			// optionally add all brokers service to the top of the list
 			if eListener.GetAccessMethod() != corev1.ServiceTypeNodePort {
 				if foundLBService == nil {
-					foundLBService, err = getServiceFromExternalListener(r.Client, r.KafkaCluster, eListener.Name, iConfigName)
+					foundLBService, err = getServiceFromExternalListener(r.Client, r.KafkaCluster, eListener, iConfigName)
 					if err != nil {
 						return nil, errors.WrapIfWithDetails(err, "could not get service corresponding to the external listener", "externalListenerName", eListener.Name)
 					}
@@ -1173,10 +1432,11 @@ func (r *Reconciler) determineControllerId() (int32, error) {
 }
 
 func getServiceFromExternalListener(client client.Client, cluster *v1beta1.KafkaCluster,
-	eListenerName string, ingressConfigName string) (*corev1.Service, error) {
+	eListener v1beta1.ExternalListenerConfig, ingressConfigName string) (*corev1.Service, error) {
 	foundLBService := &corev1.Service{}
+	eListenerName := eListener.Name
 	var iControllerServiceName string
-	switch cluster.Spec.GetIngressController() {
+	switch eListener.GetIngressController(cluster.Spec.GetIngressController()) {
 	case istioingressutils.IngressControllerName:
 		if ingressConfigName == util.IngressConfigGlobalName {
 			iControllerServiceName = fmt.Sprintf(istioingressutils.MeshGatewayNameTemplate, eListenerName, cluster.GetName())
@@ -1203,7 +1463,9 @@ func getServiceFromExternalListener(client client.Client, cluster *v1beta1.Kafka
 // - prioritize upscale in order to allow upscaling the cluster even when there is a stuck RU
 // - prioritize missing broker pods to be able for escaping from offline partitions, not all replicas in sync which
 //		could stall RU flow
-func reorderBrokers(brokerPods corev1.PodList, desiredBrokers []v1beta1.Broker, brokersState map[string]v1beta1.BrokerState, controllerBrokerID int32) []v1beta1.Broker {
+// - the brokers of the same priority are ordered by their restart ranks, lower ranks first
+func reorderBrokers(brokerPods corev1.PodList, desiredBrokers []v1beta1.Broker, brokersState map[string]v1beta1.BrokerState,
+	controllerBrokerID int32, restartRanks map[int32]int) []v1beta1.Broker {
 	runningBrokers := make(map[string]struct{})
 	for _, b := range brokerPods.Items {
 		brokerID := b.GetLabels()["brokerId"]
@@ -1234,6 +1496,9 @@ func reorderBrokers(brokerPods corev1.PodList, desiredBrokers []v1beta1.Broker,
 		brokerID1 := fmt.Sprintf("%d", reorderedBrokers[i].Id)
 		brokerID2 := fmt.Sprintf("%d", reorderedBrokers[j].Id)
 
+		if brokersReconcilePriority[brokerID1] == brokersReconcilePriority[brokerID2] {
+			return restartRanks[reorderedBrokers[i].Id] < restartRanks[reorderedBrokers[j].Id]
+		}
 		return brokersReconcilePriority[brokerID1] < brokersReconcilePriority[brokerID2]
 	})
 
@@ -21,6 +21,7 @@ import (
 
 	"errors"
 
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	"github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -312,6 +313,7 @@ func TestReorderBrokers(t *testing.T) {
 		desiredBrokers           []v1beta1.Broker
 		brokersState             map[string]v1beta1.BrokerState
 		controllerBrokerID       int32
+		restartRanks             map[int32]int
 		expectedReorderedBrokers []v1beta1.Broker
 	}{
 		{
@@ -438,6 +440,36 @@ func TestReorderBrokers(t *testing.T) {
 				{Id: 1}, // controller broker should be last
 			},
 		},
+		{
+			testName: "running brokers ordered by restart ranks",
+			brokerPods: corev1.PodList{
+				Items: []corev1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"brokerId": "0"}}},
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"brokerId": "1"}}},
+					{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"brokerId": "2"}}},
+				},
+			},
+			desiredBrokers: []v1beta1.Broker{
+				{Id: 0},
+				{Id: 1},
+				{Id: 2},
+				{Id: 3},
+			},
+			brokersState: map[string]v1beta1.BrokerState{
+				"0": {ConfigurationState: v1beta1.ConfigOutOfSync},
+				"1": {ConfigurationState: v1beta1.ConfigOutOfSync},
+				"2": {ConfigurationState: v1beta1.ConfigOutOfSync},
+				"3": {ConfigurationState: v1beta1.ConfigOutOfSync},
+			},
+			controllerBrokerID: -1, // the controller is restarted in its turn
+			restartRanks:       map[int32]int{0: 12, 1: 3, 2: 7, 3: 0},
+			expectedReorderedBrokers: []v1beta1.Broker{
+				{Id: 3}, // broker pod 3 missing thus should have higher prio regardless of its rank
+				{Id: 1},
+				{Id: 2},
+				{Id: 0},
+			},
+		},
 	}
 
 	t.Parallel()
@@ -447,7 +479,7 @@ func TestReorderBrokers(t *testing.T) {
 
 		t.Run(test.testName, func(t *testing.T) {
 			g := gomega.NewWithT(t)
-			reorderedBrokers := reorderBrokers(test.brokerPods, test.desiredBrokers, test.brokersState, test.controllerBrokerID)
+			reorderedBrokers := reorderBrokers(test.brokerPods, test.desiredBrokers, test.brokersState, test.controllerBrokerID, test.restartRanks)
 
 			g.Expect(reorderedBrokers).To(gomega.Equal(test.expectedReorderedBrokers))
 		})
@@ -834,3 +866,67 @@ func TestGetServerPasswordKeysAndUsers(t *testing.T) { //nolint funlen
 		})
 	}
 }
+
+func TestIsKafkaPodMetadataOnlyChanged(t *testing.T) {
+	newPod := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "kafka-0",
+				Namespace:   "kafka",
+				Labels:      map[string]string{"app": "kafka", "brokerId": "0"},
+				Annotations: map[string]string{"team": "a"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "kafka", Image: "ghcr.io/banzaicloud/kafka:2.13-3.1.0"}},
+			},
+		}
+	}
+	currentPod := newPod()
+	if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(currentPod); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		testName             string
+		modify               func(pod *corev1.Pod)
+		expectedMetadataOnly bool
+	}{
+		{
+			testName:             "label changed",
+			modify:               func(pod *corev1.Pod) { pod.Labels["tier"] = "gold" },
+			expectedMetadataOnly: true,
+		},
+		{
+			testName:             "annotation removed",
+			modify:               func(pod *corev1.Pod) { delete(pod.Annotations, "team") },
+			expectedMetadataOnly: true,
+		},
+		{
+			testName: "image changed",
+			modify: func(pod *corev1.Pod) {
+				pod.Spec.Containers[0].Image = "ghcr.io/banzaicloud/kafka:2.13-3.2.0"
+			},
+			expectedMetadataOnly: false,
+		},
+		{
+			testName: "label and image changed",
+			modify: func(pod *corev1.Pod) {
+				pod.Labels["tier"] = "gold"
+				pod.Spec.Containers[0].Image = "ghcr.io/banzaicloud/kafka:2.13-3.2.0"
+			},
+			expectedMetadataOnly: false,
+		},
+	}
+
+	for _, test := range testCases {
+		desiredPod := newPod()
+		test.modify(desiredPod)
+		metadataOnly, err := isKafkaPodMetadataOnlyChanged(currentPod, desiredPod)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.testName, err)
+		}
+		if metadataOnly != test.expectedMetadataOnly {
+			t.Errorf("%s: expected metadata only change: %v, got: %v", test.testName, test.expectedMetadataOnly, metadataOnly)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// reconcileKRaftClusterID generates the ID the storage of the nodes is formatted with the first time the cluster is
+// reconciled in KRaft mode, it is kept in the status of the cluster afterwards
+func (r *Reconciler) reconcileKRaftClusterID(log logr.Logger) error {
+	if r.KafkaCluster.Status.KRaft != nil && r.KafkaCluster.Status.KRaft.ClusterID != "" {
+		return nil
+	}
+	clusterID, err := newKRaftClusterID()
+	if err != nil {
+		return errorfactory.New(errorfactory.InternalError{}, err, "could not generate KRaft cluster ID")
+	}
+	log.Info("generated KRaft cluster ID", "clusterId", clusterID)
+	if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, &v1beta1.KRaftStatus{ClusterID: clusterID}, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not save KRaft cluster ID")
+	}
+	return nil
+}
+
+// newKRaftClusterID returns a random cluster ID in the format of kafka-storage.sh random-uuid: 16 random bytes
+// encoded as URL-safe base64 without padding, not starting with a dash so that it is not taken for a flag
+func newKRaftClusterID() (string, error) {
+	id := make([]byte, 16)
+	for {
+		if _, err := rand.Read(id); err != nil {
+			return "", err
+		}
+		if encoded := base64.RawURLEncoding.EncodeToString(id); !strings.HasPrefix(encoded, "-") {
+			return encoded, nil
+		}
+	}
+}
+
+// checkControllerQuorum holds back the restart of a voter of the KRaft metadata quorum until enough of the other voters
+// are ready for the quorum to keep its majority without it
+func (r *Reconciler) checkControllerQuorum(pods []corev1.Pod, currentPod *corev1.Pod) error {
+	voters, err := r.KafkaCluster.Spec.GetKRaftControllerIDs()
+	if err != nil {
+		return errors.WrapIf(err, "could not determine the voters of the metadata quorum")
+	}
+	brokerID := currentPod.Labels["brokerId"]
+	isVoter := false
+	for _, id := range voters {
+		if strconv.Itoa(int(id)) == brokerID {
+			isVoter = true
+			break
+		}
+	}
+	if !isVoter {
+		return nil
+	}
+
+	readyVoters := make(map[string]bool, len(voters))
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Labels["brokerId"] != brokerID && !k8sutil.IsMarkedForDeletion(pod.ObjectMeta) && isPodReady(pod) {
+			readyVoters[pod.Labels["brokerId"]] = true
+		}
+	}
+	ready := 0
+	for _, id := range voters {
+		if readyVoters[strconv.Itoa(int(id))] {
+			ready++
+		}
+	}
+
+	// the quorum of less than three voters can not tolerate a failure, all the other voters have to be ready then
+	required := len(voters)/2 + 1
+	if required > len(voters)-1 {
+		required = len(voters) - 1
+	}
+	if ready < required {
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{},
+			errors.NewWithDetails("not enough voters are ready", "ready", ready, "required", required),
+			"restarting controller would break the metadata quorum", "brokerId", brokerID)
+	}
+	return nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
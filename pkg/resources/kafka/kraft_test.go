@@ -0,0 +1,122 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"encoding/base64"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestNewKRaftClusterID(t *testing.T) {
+	clusterID, err := newKRaftClusterID()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(clusterID)
+	if err != nil || len(decoded) != 16 {
+		t.Errorf("expected 16 bytes encoded as URL-safe base64, got: %s", clusterID)
+	}
+}
+
+func TestCheckControllerQuorum(t *testing.T) {
+	controller := &v1beta1.BrokerConfig{ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleController}}
+	newReconciler := func(voters int) *Reconciler {
+		brokers := []v1beta1.Broker{{Id: 100, BrokerConfig: &v1beta1.BrokerConfig{}}}
+		for id := 0; id < voters; id++ {
+			brokers = append(brokers, v1beta1.Broker{Id: int32(id), BrokerConfig: controller})
+		}
+		return &Reconciler{Reconciler: resources.Reconciler{KafkaCluster: &v1beta1.KafkaCluster{
+			Spec: v1beta1.KafkaClusterSpec{KRaftMode: true, Brokers: brokers},
+		}}}
+	}
+	newPod := func(id int, ready bool) corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"brokerId": strconv.Itoa(id)}},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+		}
+	}
+
+	testCases := []struct {
+		testName  string
+		voters    int
+		pods      []corev1.Pod
+		restarted int
+		allowed   bool
+	}{
+		{
+			testName:  "broker is not a voter",
+			voters:    3,
+			pods:      []corev1.Pod{newPod(0, false), newPod(1, false), newPod(2, false), newPod(100, true)},
+			restarted: 100,
+			allowed:   true,
+		},
+		{
+			testName:  "single voter",
+			voters:    1,
+			pods:      []corev1.Pod{newPod(0, true)},
+			restarted: 0,
+			allowed:   true,
+		},
+		{
+			testName:  "all other voters are ready",
+			voters:    3,
+			pods:      []corev1.Pod{newPod(0, true), newPod(1, true), newPod(2, true)},
+			restarted: 0,
+			allowed:   true,
+		},
+		{
+			testName:  "quorum would lose its majority",
+			voters:    3,
+			pods:      []corev1.Pod{newPod(0, true), newPod(1, true), newPod(2, false)},
+			restarted: 0,
+		},
+		{
+			testName:  "quorum keeps its majority",
+			voters:    5,
+			pods:      []corev1.Pod{newPod(0, true), newPod(1, true), newPod(2, true), newPod(3, true), newPod(4, false)},
+			restarted: 0,
+			allowed:   true,
+		},
+	}
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			var currentPod *corev1.Pod
+			for i := range test.pods {
+				if test.pods[i].Labels["brokerId"] == strconv.Itoa(test.restarted) {
+					currentPod = &test.pods[i]
+				}
+			}
+			err := newReconciler(test.voters).checkControllerQuorum(test.pods, currentPod)
+			if test.allowed && err != nil {
+				t.Errorf("expected the restart to be allowed, got: %v", err)
+			}
+			if !test.allowed && err == nil {
+				t.Error("expected the restart to be held back")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+const maintenanceWindowBypassedReason = "MaintenanceWindowBypassed"
+
+// podRestartUrgency returns the urgency of restarting the given broker pod. The urgency set explicitly on the pod takes
+// precedence, otherwise replacing a failed broker can not wait for the next maintenance window.
+func podRestartUrgency(pod *corev1.Pod) v1beta1.OperationUrgency {
+	switch urgency := v1beta1.OperationUrgency(pod.GetAnnotations()[v1beta1.RestartUrgencyAnnotation]); urgency {
+	case v1beta1.OperationUrgencyNormal, v1beta1.OperationUrgencyUrgent:
+		return urgency
+	}
+	if k8sutil.IsPodContainsTerminatedContainer(pod) || k8sutil.IsPodContainsEvictedContainer(pod) {
+		return v1beta1.OperationUrgencyUrgent
+	}
+	return v1beta1.OperationUrgencyNormal
+}
+
+// checkMaintenanceWindow returns an error if a disruptive operation with the given urgency is not allowed at the given
+// time. In case the operation is allowed only because it bypasses the maintenance windows the reason of the bypass
+// is returned so that it can be audited.
+func (r *Reconciler) checkMaintenanceWindow(now time.Time, urgency v1beta1.OperationUrgency) (string, error) {
+	if r.KafkaCluster.Spec.InMaintenanceWindow(now) {
+		return "", nil
+	}
+	if reason, ok := r.KafkaCluster.GetAnnotations()[v1beta1.EmergencyOverrideAnnotation]; ok {
+		if reason == "" {
+			reason = "no reason given"
+		}
+		return "emergency override (" + reason + ")", nil
+	}
+	if urgency == v1beta1.OperationUrgencyUrgent {
+		return "operation is urgent", nil
+	}
+	return "", errors.New("there is no open maintenance window")
+}
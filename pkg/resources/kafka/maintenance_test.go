@@ -0,0 +1,170 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestCheckMaintenanceWindow(t *testing.T) {
+	// Wednesday
+	now := time.Date(2022, time.March, 30, 12, 0, 0, 0, time.UTC)
+	closedWindows := []v1beta1.MaintenanceWindow{
+		{Start: "01:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+	}
+
+	testCases := []struct {
+		testName       string
+		windows        []v1beta1.MaintenanceWindow
+		annotations    map[string]string
+		urgency        v1beta1.OperationUrgency
+		expectedReason string
+		expectedErr    bool
+	}{
+		{
+			testName: "no maintenance windows",
+			urgency:  v1beta1.OperationUrgencyNormal,
+		},
+		{
+			testName: "open maintenance window",
+			windows: []v1beta1.MaintenanceWindow{
+				{Days: []v1beta1.MaintenanceWindowDay{"Wednesday"}, Start: "11:30", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			urgency: v1beta1.OperationUrgencyNormal,
+		},
+		{
+			testName:    "closed maintenance window",
+			windows:     closedWindows,
+			urgency:     v1beta1.OperationUrgencyNormal,
+			expectedErr: true,
+		},
+		{
+			testName:       "closed maintenance window with urgent operation",
+			windows:        closedWindows,
+			urgency:        v1beta1.OperationUrgencyUrgent,
+			expectedReason: "operation is urgent",
+		},
+		{
+			testName:       "closed maintenance window with emergency override",
+			windows:        closedWindows,
+			annotations:    map[string]string{v1beta1.EmergencyOverrideAnnotation: "disk full"},
+			urgency:        v1beta1.OperationUrgencyNormal,
+			expectedReason: "emergency override (disk full)",
+		},
+	}
+
+	for _, test := range testCases {
+		r := Reconciler{
+			Reconciler: resources.Reconciler{
+				KafkaCluster: &v1beta1.KafkaCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "kafka",
+						Namespace:   "kafka",
+						Annotations: test.annotations,
+					},
+					Spec: v1beta1.KafkaClusterSpec{
+						MaintenanceWindows: test.windows,
+					},
+				},
+			},
+		}
+		reason, err := r.checkMaintenanceWindow(now, test.urgency)
+		if test.expectedErr != (err != nil) {
+			t.Errorf("%s: expected error: %v, got: %v", test.testName, test.expectedErr, err)
+		}
+		if reason != test.expectedReason {
+			t.Errorf("%s: expected bypass reason: %q, got: %q", test.testName, test.expectedReason, reason)
+		}
+	}
+}
+
+func TestPodRestartUrgency(t *testing.T) {
+	terminated := corev1.PodStatus{
+		ContainerStatuses: []corev1.ContainerStatus{
+			{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}},
+		},
+	}
+
+	testCases := []struct {
+		testName        string
+		annotations     map[string]string
+		status          corev1.PodStatus
+		expectedUrgency v1beta1.OperationUrgency
+	}{
+		{
+			testName:        "running pod",
+			expectedUrgency: v1beta1.OperationUrgencyNormal,
+		},
+		{
+			testName:        "terminated pod",
+			status:          terminated,
+			expectedUrgency: v1beta1.OperationUrgencyUrgent,
+		},
+		{
+			testName:        "running pod with urgent restart",
+			annotations:     map[string]string{v1beta1.RestartUrgencyAnnotation: "Urgent"},
+			expectedUrgency: v1beta1.OperationUrgencyUrgent,
+		},
+		{
+			testName:        "terminated pod with normal restart",
+			annotations:     map[string]string{v1beta1.RestartUrgencyAnnotation: "Normal"},
+			status:          terminated,
+			expectedUrgency: v1beta1.OperationUrgencyNormal,
+		},
+		{
+			testName:        "invalid urgency",
+			annotations:     map[string]string{v1beta1.RestartUrgencyAnnotation: "now"},
+			expectedUrgency: v1beta1.OperationUrgencyNormal,
+		},
+	}
+
+	for _, test := range testCases {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka-0", Annotations: test.annotations},
+			Status:     test.status,
+		}
+		if urgency := podRestartUrgency(pod); urgency != test.expectedUrgency {
+			t.Errorf("%s: expected urgency: %s, got: %s", test.testName, test.expectedUrgency, urgency)
+		}
+	}
+
+	// the explicitly urgent restart bypasses the closed maintenance windows
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			KafkaCluster: &v1beta1.KafkaCluster{
+				Spec: v1beta1.KafkaClusterSpec{
+					MaintenanceWindows: []v1beta1.MaintenanceWindow{
+						{Start: "01:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+					},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "kafka-0",
+		Annotations: map[string]string{v1beta1.RestartUrgencyAnnotation: "Urgent"},
+	}}
+	now := time.Date(2022, time.March, 30, 12, 0, 0, 0, time.UTC)
+	if reason, err := r.checkMaintenanceWindow(now, podRestartUrgency(pod)); err != nil || reason != "operation is urgent" {
+		t.Errorf("expected the urgent restart to bypass the maintenance window, got reason: %q, error: %v", reason, err)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+const operationTimeFormat = time.RFC3339
+
+// rollingUpgradeTrigger describes the change of the broker pod starting the rolling upgrade
+func rollingUpgradeTrigger(currentPod, desiredPod *corev1.Pod) string {
+	brokerID := currentPod.Labels["brokerId"]
+	switch {
+	case k8sutil.IsPodContainsTerminatedContainer(currentPod):
+		return fmt.Sprintf("broker %s terminated", brokerID)
+	case isKafkaVersionUpgrade(currentPod, desiredPod):
+		return fmt.Sprintf("image change of broker %s", brokerID)
+	case isRestartRequested(currentPod, desiredPod):
+		return fmt.Sprintf("restart generation change of broker %s", brokerID)
+	default:
+		return fmt.Sprintf("pod spec change of broker %s", brokerID)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	apiutil "github.com/banzaicloud/koperator/api/util"
 	"github.com/banzaicloud/koperator/api/v1beta1"
@@ -36,6 +37,15 @@ import (
 	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
 )
 
+const (
+	// brokerNodeLabel marks the pods of the brokers serving clients in KRaft mode, the all-broker service leaves out
+	// the controller-only nodes by it
+	brokerNodeLabel = "isBrokerNode"
+	// kraftClusterIDEnvVar is the cluster ID the storage of the node is formatted with before starting it in KRaft
+	// mode
+	kraftClusterIDEnvVar = "KAFKA_CLUSTER_ID"
+)
+
 var (
 	//go:embed wait-for-envoy-sidecar.sh
 	envoySidecarScript string
@@ -80,7 +90,13 @@ func (r *Reconciler) pod(id int32, brokerConfig *v1beta1.BrokerConfig, pvcs []co
 		}
 	}
 
-	dataVolume, dataVolumeMount := generateDataVolumeAndVolumeMount(pvcs)
+	var dataVolume []corev1.Volume
+	var dataVolumeMount []corev1.VolumeMount
+	if brokerConfig.IsEphemeralStorageEnabled() {
+		dataVolume, dataVolumeMount = generateEphemeralDataVolumeAndVolumeMount(brokerConfig.StorageConfigs, brokerConfig.EphemeralStorage.Medium)
+	} else {
+		dataVolume, dataVolumeMount = generateDataVolumeAndVolumeMount(pvcs)
+	}
 
 	// TODO remove this bash envoy sidecar checker script once sidecar precedence becomes available to Kubernetes(baluchicken)
 	command := []string{"bash", "-c", envoySidecarScript}
@@ -98,26 +114,10 @@ func (r *Reconciler) pod(id int32, brokerConfig *v1beta1.BrokerConfig, pvcs []co
 			Affinity:        getAffinity(brokerConfig, r.KafkaCluster),
 			Containers: append([]corev1.Container{
 				{
-					Name:  "kafka",
-					Image: util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage()),
-					Lifecycle: &corev1.Lifecycle{
-						PreStop: &corev1.LifecycleHandler{
-							Exec: &corev1.ExecAction{
-								Command: []string{"bash", "-c", `
-if [[ -n "$ENVOY_SIDECAR_STATUS" ]]; then
-  HEALTHYSTATUSCODE="200"
-  SC=$(curl -s -o /dev/null -w "%{http_code}" http://localhost:15000/ready)
-  if [[ "$SC" == "$HEALTHYSTATUSCODE" ]]; then
-    kill -s TERM $(pidof java)
-  else
-    kill -s KILL $(pidof java)
-  fi
-else
-  kill -s TERM $(pidof java)
-fi`},
-							},
-						},
-					},
+					Name:            kafkaContainerName,
+					Image:           util.GetBrokerImage(brokerConfig, r.clusterImage()),
+					Lifecycle:       getLifecycle(brokerConfig),
+					StartupProbe:    getStartupProbe(brokerConfig, r.KafkaCluster.Spec.ListenersConfig, r.KafkaCluster.Spec.KRaftMode),
 					SecurityContext: brokerConfig.SecurityContext,
 					Env: generateEnvConfig(brokerConfig, []corev1.EnvVar{
 						{
@@ -125,8 +125,9 @@ fi`},
 							Value: "/opt/kafka/libs/extensions/*",
 						},
 						{
-							Name:  "KAFKA_OPTS",
-							Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml",
+							Name: "KAFKA_OPTS",
+							Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml" +
+								certificateRevocationJVMOpts(r.KafkaCluster.Spec.ListenersConfig.CertificateRevocation),
 						},
 						{
 							Name: "ENVOY_SIDECAR_STATUS",
@@ -147,7 +148,7 @@ fi`},
 						},
 					}...),
 					VolumeMounts: getVolumeMounts(brokerConfig.VolumeMounts, dataVolumeMount, r.KafkaCluster.Spec, r.KafkaCluster.Name),
-					Resources:    *brokerConfig.GetResources(),
+					Resources:    *r.brokerResources(brokerConfig),
 				},
 			}, brokerConfig.Containers...),
 			Volumes:                       getVolumes(brokerConfig.Volumes, dataVolume, r.KafkaCluster.Spec, r.KafkaCluster.Name, id),
@@ -159,14 +160,98 @@ fi`},
 			NodeSelector:                  brokerConfig.GetNodeSelector(),
 		},
 	}
+	if generation := restartGeneration(r.KafkaCluster.Spec, id); generation != "" {
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string, 1)
+		}
+		pod.Annotations[v1beta1.RestartGenerationAnnotation] = generation
+	}
 	if r.KafkaCluster.Spec.HeadlessServiceEnabled {
 		pod.Spec.Hostname = fmt.Sprintf("%s-%d", r.KafkaCluster.Name, id)
 		pod.Spec.Subdomain = fmt.Sprintf(kafkautils.HeadlessServiceTemplate, r.KafkaCluster.Name)
 	}
+	if r.KafkaCluster.Spec.KRaftMode {
+		if brokerConfig.IsBrokerNode() {
+			pod.Labels[brokerNodeLabel] = "true"
+		}
+		if r.KafkaCluster.Status.KRaft != nil {
+			pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{
+				Name:  kraftClusterIDEnvVar,
+				Value: r.KafkaCluster.Status.KRaft.ClusterID,
+			})
+		}
+	}
 
 	return pod
 }
 
+// defaultPreStopCommand stops the broker gracefully unless the Envoy sidecar is not ready to proxy the remaining
+// requests
+var defaultPreStopCommand = []string{"bash", "-c", `
+if [[ -n "$ENVOY_SIDECAR_STATUS" ]]; then
+  HEALTHYSTATUSCODE="200"
+  SC=$(curl -s -o /dev/null -w "%{http_code}" http://localhost:15000/ready)
+  if [[ "$SC" == "$HEALTHYSTATUSCODE" ]]; then
+    kill -s TERM $(pidof java)
+  else
+    kill -s KILL $(pidof java)
+  fi
+else
+  kill -s TERM $(pidof java)
+fi`}
+
+// getLifecycle returns the lifecycle hooks of the kafka container
+func getLifecycle(brokerConfig *v1beta1.BrokerConfig) *corev1.Lifecycle {
+	lifecycle := &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: defaultPreStopCommand},
+		},
+	}
+	if brokerConfig.Lifecycle == nil {
+		return lifecycle
+	}
+	if len(brokerConfig.Lifecycle.PreStopCommand) > 0 {
+		lifecycle.PreStop.Exec.Command = brokerConfig.Lifecycle.PreStopCommand
+	}
+	if len(brokerConfig.Lifecycle.PostStartCommand) > 0 {
+		lifecycle.PostStart = &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: brokerConfig.Lifecycle.PostStartCommand},
+		}
+	}
+	return lifecycle
+}
+
+// getStartupProbe returns the startup probe of the kafka container checking the listener used for the inter-broker
+// communication, nil if it is not requested
+func getStartupProbe(brokerConfig *v1beta1.BrokerConfig, listenersConfig v1beta1.ListenersConfig, kraftMode bool) *corev1.Probe {
+	if brokerConfig.Lifecycle == nil || brokerConfig.Lifecycle.StartupProbe == nil {
+		return nil
+	}
+	// the controller-only nodes of KRaft mode bind the controller listener only
+	controllerOnly := kraftMode && !brokerConfig.IsBrokerNode()
+	var port int32
+	for _, iListener := range listenersConfig.InternalListeners {
+		if (!controllerOnly && iListener.UsedForInnerBrokerCommunication) ||
+			(controllerOnly && iListener.UsedForControllerCommunication) {
+			port = iListener.ContainerPort
+			break
+		}
+	}
+	if port == 0 {
+		return nil
+	}
+	probeConfig := brokerConfig.Lifecycle.StartupProbe
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(int(port))},
+		},
+		InitialDelaySeconds: probeConfig.InitialDelaySeconds,
+		PeriodSeconds:       probeConfig.GetPeriodSeconds(),
+		TimeoutSeconds:      probeConfig.GetTimeoutSeconds(),
+		FailureThreshold:    probeConfig.GetFailureThreshold(),
+	}
+}
+
 func getInitContainers(brokerConfig *v1beta1.BrokerConfig, kafkaClusterSpec v1beta1.KafkaClusterSpec) []corev1.Container {
 	initContainers := make([]corev1.Container, 0, len(brokerConfig.InitContainers))
 	initContainers = append(initContainers, brokerConfig.InitContainers...)
@@ -196,6 +281,10 @@ func getInitContainers(brokerConfig *v1beta1.BrokerConfig, kafkaClusterSpec v1be
 		},
 	}...)
 
+	if container, ok := principalBuilderInitContainer(kafkaClusterSpec.ListenersConfig.PrincipalBuilder); ok {
+		initContainers = append(initContainers, container)
+	}
+
 	sort.Slice(initContainers, func(i, j int) bool {
 		return initContainers[i].Name < initContainers[j].Name
 	})
@@ -215,6 +304,13 @@ func getVolumeMounts(brokerConfigVolumeMounts, dataVolumeMount []corev1.VolumeMo
 	}
 
 	volumeMounts = append(volumeMounts, generateVolumeMountForListenerCerts(kafkaClusterSpec.ListenersConfig)...)
+	if len(saslPlainListeners(kafkaClusterSpec.ListenersConfig)) > 0 {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      saslPlainVolumeName,
+			MountPath: saslPlainVolumePath,
+			ReadOnly:  true,
+		})
+	}
 	volumeMounts = append(volumeMounts, []corev1.VolumeMount{
 		{
 			Name:      brokerConfigMapVolumeMount,
@@ -256,6 +352,17 @@ func getVolumes(brokerConfigVolumes, dataVolume []corev1.Volume, kafkaClusterSpe
 	}
 
 	volumes = append(volumes, generateVolumesForListenerCerts(kafkaClusterSpec.ListenersConfig, kafkaClusterName)...)
+	if len(saslPlainListeners(kafkaClusterSpec.ListenersConfig)) > 0 {
+		volumes = append(volumes, corev1.Volume{
+			Name: saslPlainVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  fmt.Sprintf(saslPlainSecretTemplate, kafkaClusterName),
+					DefaultMode: util.Int32Pointer(0644),
+				},
+			},
+		})
+	}
 	volumes = append(volumes, []corev1.Volume{
 		{
 			Name: "exitfile",
@@ -302,44 +409,58 @@ func getVolumes(brokerConfigVolumes, dataVolume []corev1.Volume, kafkaClusterSpe
 	return volumes
 }
 
-// getAffinity returns a default `v1.Affinity` which is generated regarding the `OneBrokerPerNode` value
-// or if there is any user Affinity definition provided by the user the latter will be used ignoring the value of `OneBrokerPerNode`
+// getAffinity returns a default `v1.Affinity` which is generated regarding the broker anti-affinity config
+// or if there is any user Affinity definition provided by the user the latter will be used ignoring the generated
+// anti-affinity, unless the Append merge strategy adds the generated terms to it
 func getAffinity(bc *v1beta1.BrokerConfig, cluster *v1beta1.KafkaCluster) *corev1.Affinity {
-	if bc.Affinity == nil {
-		return &corev1.Affinity{PodAntiAffinity: generatePodAntiAffinity(cluster.Name, cluster.Spec.OneBrokerPerNode)}
+	antiAffinityConfig := cluster.Spec.BrokerAntiAffinity
+	podAntiAffinity := generatePodAntiAffinity(cluster.Name, cluster.Spec.GetBrokerAntiAffinityType(), antiAffinityConfig.GetTopologyKey())
+
+	affinity := bc.Affinity
+	switch {
+	case affinity == nil && podAntiAffinity != nil:
+		affinity = &corev1.Affinity{PodAntiAffinity: podAntiAffinity}
+	case affinity != nil && podAntiAffinity != nil && antiAffinityConfig.GetMergeStrategy() == v1beta1.AffinityMergeAppend:
+		affinity = affinity.DeepCopy()
+		if affinity.PodAntiAffinity == nil {
+			affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+		}
+		affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+			affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
 	}
-	return bc.Affinity
+	return util.AddArchitectureAffinity(affinity, bc.GetArchitectures(cluster.Spec.Architectures))
 }
 
-func generatePodAntiAffinity(clusterName string, hardRuleEnabled bool) *corev1.PodAntiAffinity {
-	podAntiAffinity := corev1.PodAntiAffinity{}
-	if hardRuleEnabled {
-		podAntiAffinity = corev1.PodAntiAffinity{
-			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
-				{
-					LabelSelector: &metav1.LabelSelector{
-						MatchLabels: apiutil.LabelsForKafka(clusterName),
-					},
-					TopologyKey: "kubernetes.io/hostname",
-				},
-			},
+// generatePodAntiAffinity returns the anti-affinity spreading the brokers across the given topology, it returns nil
+// for the None anti-affinity type
+func generatePodAntiAffinity(clusterName string, antiAffinityType v1beta1.AntiAffinityType, topologyKey string) *corev1.PodAntiAffinity {
+	term := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: apiutil.LabelsForKafka(clusterName),
+		},
+		TopologyKey: topologyKey,
+	}
+	switch antiAffinityType {
+	case v1beta1.AntiAffinityNone:
+		return nil
+	case v1beta1.AntiAffinityRequired:
+		return &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{term},
 		}
-	} else {
-		podAntiAffinity = corev1.PodAntiAffinity{
+	default:
+		return &corev1.PodAntiAffinity{
 			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
 				{
-					Weight: int32(100),
-					PodAffinityTerm: corev1.PodAffinityTerm{
-						LabelSelector: &metav1.LabelSelector{
-							MatchLabels: apiutil.LabelsForKafka(clusterName),
-						},
-						TopologyKey: "kubernetes.io/hostname",
-					},
+					Weight:          int32(100),
+					PodAffinityTerm: term,
 				},
 			},
 		}
 	}
-	return &podAntiAffinity
 }
 
 func generateDataVolumeAndVolumeMount(pvcs []corev1.PersistentVolumeClaim) (volume []corev1.Volume, volumeMount []corev1.VolumeMount) {
@@ -360,6 +481,28 @@ func generateDataVolumeAndVolumeMount(pvcs []corev1.PersistentVolumeClaim) (volu
 	return
 }
 
+func generateEphemeralDataVolumeAndVolumeMount(storageConfigs []v1beta1.StorageConfig, medium corev1.StorageMedium) (volume []corev1.Volume, volumeMount []corev1.VolumeMount) {
+	for i, storage := range storageConfigs {
+		emptyDir := &corev1.EmptyDirVolumeSource{Medium: medium}
+		if storage.PvcSpec != nil {
+			if size, ok := storage.PvcSpec.Resources.Requests[corev1.ResourceStorage]; ok {
+				emptyDir.SizeLimit = &size
+			}
+		}
+		volume = append(volume, corev1.Volume{
+			Name: fmt.Sprintf(kafkaDataVolumeMount+"-%d", i),
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: emptyDir,
+			},
+		})
+		volumeMount = append(volumeMount, corev1.VolumeMount{
+			Name:      fmt.Sprintf(kafkaDataVolumeMount+"-%d", i),
+			MountPath: storage.MountPath,
+		})
+	}
+	return
+}
+
 func generateVolumeForListenersCertsFromCommonSpec(commonSpec v1beta1.CommonListenerSpec, clusterName string) corev1.Volume {
 	// Use default one if custom has not specified
 	secretName := fmt.Sprintf(pkicommon.BrokerServerCertTemplate, clusterName)
@@ -20,7 +20,9 @@ import (
 
 	"gotest.tools/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
 )
@@ -98,6 +100,46 @@ func TestGetAffinity(t *testing.T) {
 	// should just return what was given as an input
 	affinity = getAffinity(&nonNilAffinityBrokerConfig, &cluster)
 	assert.DeepEqual(t, affinity, nonNilAffinityBrokerConfig.Affinity.DeepCopy())
+
+	// the anti-affinity config takes precedence over OneBrokerPerNode
+	cluster.Spec.BrokerAntiAffinity = &v1beta1.BrokerAntiAffinityConfig{
+		Type:        v1beta1.AntiAffinityRequired,
+		TopologyKey: "topology.kubernetes.io/zone",
+	}
+	affinity = getAffinity(&nilAffinityBrokerConfig, &cluster)
+	if len(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 1 ||
+		affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey != "topology.kubernetes.io/zone" {
+		t.Error("Given affinity does not match expectations")
+	}
+
+	// no anti-affinity is generated
+	cluster.Spec.OneBrokerPerNode = true
+	cluster.Spec.BrokerAntiAffinity = &v1beta1.BrokerAntiAffinityConfig{Type: v1beta1.AntiAffinityNone}
+	affinity = getAffinity(&nilAffinityBrokerConfig, &cluster)
+	if affinity != nil {
+		t.Error("Expected no affinity")
+	}
+
+	// the generated anti-affinity terms are appended to the given affinity
+	userAffinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{Weight: 1}},
+		},
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: "rack"}},
+		},
+	}
+	cluster.Spec.BrokerAntiAffinity = &v1beta1.BrokerAntiAffinityConfig{MergeStrategy: v1beta1.AffinityMergeAppend}
+	affinity = getAffinity(&v1beta1.BrokerConfig{Affinity: userAffinity}, &cluster)
+	expected := userAffinity.DeepCopy()
+	expected.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+		expected.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+		defaultPodAntiAffinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+	assert.DeepEqual(t, affinity, expected)
+	// the given affinity is left intact
+	if len(userAffinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 1 {
+		t.Error("The given affinity was modified")
+	}
 }
 
 func Test_generateEnvConfig(t *testing.T) {
@@ -185,3 +227,91 @@ func Test_generateEnvConfig(t *testing.T) {
 		t.Error("Expected:", expected, "Got:", result)
 	}
 }
+
+func TestGenerateEphemeralDataVolumeAndVolumeMount(t *testing.T) {
+	storageConfigs := []v1beta1.StorageConfig{
+		{
+			MountPath: "/kafka-logs",
+			PvcSpec: &corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+		},
+		{
+			MountPath: "/kafka-logs2",
+		},
+	}
+
+	volumes, volumeMounts := generateEphemeralDataVolumeAndVolumeMount(storageConfigs, corev1.StorageMediumMemory)
+
+	sizeLimit := resource.MustParse("10Gi")
+	expectedVolumes := []corev1.Volume{
+		{
+			Name: "kafka-data-0",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: &sizeLimit},
+			},
+		},
+		{
+			Name: "kafka-data-1",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+			},
+		},
+	}
+	expectedVolumeMounts := []corev1.VolumeMount{
+		{Name: "kafka-data-0", MountPath: "/kafka-logs"},
+		{Name: "kafka-data-1", MountPath: "/kafka-logs2"},
+	}
+	assert.DeepEqual(t, volumes, expectedVolumes)
+	assert.DeepEqual(t, volumeMounts, expectedVolumeMounts)
+}
+
+func TestGetLifecycle(t *testing.T) {
+	lifecycle := getLifecycle(&v1beta1.BrokerConfig{})
+	assert.DeepEqual(t, lifecycle, &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: defaultPreStopCommand}},
+	})
+
+	lifecycle = getLifecycle(&v1beta1.BrokerConfig{
+		Lifecycle: &v1beta1.BrokerLifecycleConfig{
+			PreStopCommand:   []string{"/bin/sh", "-c", "sleep 30; kill -s TERM $(pidof java)"},
+			PostStartCommand: []string{"/bin/wait-for-agent"},
+		},
+	})
+	assert.DeepEqual(t, lifecycle, &corev1.Lifecycle{
+		PostStart: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/wait-for-agent"}}},
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "sleep 30; kill -s TERM $(pidof java)"}},
+		},
+	})
+}
+
+func TestGetStartupProbe(t *testing.T) {
+	listenersConfig := v1beta1.ListenersConfig{
+		InternalListeners: []v1beta1.InternalListenerConfig{
+			{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "controller", ContainerPort: 29093}},
+			{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", ContainerPort: 29092}, UsedForInnerBrokerCommunication: true},
+		},
+	}
+
+	if probe := getStartupProbe(&v1beta1.BrokerConfig{}, listenersConfig, false); probe != nil {
+		t.Errorf("expected no startup probe, got: %+v", probe)
+	}
+
+	brokerConfig := &v1beta1.BrokerConfig{
+		Lifecycle: &v1beta1.BrokerLifecycleConfig{
+			StartupProbe: &v1beta1.StartupProbeConfig{InitialDelaySeconds: 15, FailureThreshold: 90},
+		},
+	}
+	assert.DeepEqual(t, getStartupProbe(brokerConfig, listenersConfig, false), &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(29092)},
+		},
+		InitialDelaySeconds: 15,
+		PeriodSeconds:       10,
+		TimeoutSeconds:      5,
+		FailureThreshold:    90,
+	})
+}
@@ -0,0 +1,232 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const (
+	preflightCheckUnderReplicatedPartitions = "UnderReplicatedPartitions"
+	preflightCheckActiveControllerPresent   = "ActiveControllerPresent"
+	preflightCheckCruiseControlReady        = "CruiseControlReady"
+	preflightCheckNoInFlightReassignments   = "NoInFlightReassignments"
+	preflightCheckDiskHeadroom              = "DiskHeadroom"
+)
+
+// runPreflightChecks runs the pre-flight checks for the given operation and records the report in the KafkaCluster status.
+// It returns a PreflightChecksFailed error if any of the checks failed. brokersToRemove is only used for scale downs.
+func (r *Reconciler) runPreflightChecks(log logr.Logger, operation v1beta1.PreflightOperation, brokersToRemove []string) error {
+	if !r.KafkaCluster.Spec.PreflightChecks.Enabled {
+		return nil
+	}
+
+	checks := r.kafkaPreflightChecks()
+	// there is no Cruise Control to check with the dev profile and the built-in rebalancer
+	if !r.KafkaCluster.Spec.IsCruiseControlDisabled() {
+		checks = append(checks, r.cruiseControlPreflightChecks(log, operation, brokersToRemove)...)
+	}
+
+	failedChecks := make([]string, 0, len(checks))
+	for _, check := range checks {
+		if !check.Passed {
+			failedChecks = append(failedChecks, check.Name)
+		}
+	}
+
+	status := &v1beta1.PreflightChecksStatus{
+		Operation: operation,
+		Passed:    len(failedChecks) == 0,
+		CheckedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Checks:    checks,
+	}
+	if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not update pre-flight checks status")
+	}
+
+	if len(failedChecks) > 0 {
+		return errorfactory.New(errorfactory.PreflightChecksFailed{}, errors.New("pre-flight checks failed"),
+			"refusing to start operation", "operation", operation, "failed checks", strings.Join(failedChecks, ","))
+	}
+	log.Info("pre-flight checks passed", "operation", operation)
+	return nil
+}
+
+func (r *Reconciler) kafkaPreflightChecks() []v1beta1.PreflightCheck {
+	kClient, closeClient, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		msg := fmt.Sprintf("could not connect to kafka brokers: %s", err)
+		return []v1beta1.PreflightCheck{
+			{Name: preflightCheckUnderReplicatedPartitions, Message: msg},
+			{Name: preflightCheckActiveControllerPresent, Message: msg},
+		}
+	}
+	defer closeClient()
+
+	return []v1beta1.PreflightCheck{
+		underReplicatedPartitionsCheck(kClient),
+		activeControllerPresentCheck(kClient),
+	}
+}
+
+func underReplicatedPartitionsCheck(kClient kafkaclient.KafkaClient) v1beta1.PreflightCheck {
+	check := v1beta1.PreflightCheck{Name: preflightCheckUnderReplicatedPartitions}
+
+	offlineReplicas, err := kClient.AllOfflineReplicas()
+	if err != nil {
+		check.Message = fmt.Sprintf("could not get offline replicas: %s", err)
+		return check
+	}
+	outOfSyncReplicas, err := kClient.OutOfSyncReplicas()
+	if err != nil {
+		check.Message = fmt.Sprintf("could not get out-of-sync replicas: %s", err)
+		return check
+	}
+
+	if len(offlineReplicas) > 0 || len(outOfSyncReplicas) > 0 {
+		check.Message = fmt.Sprintf("brokers with offline replicas: %v, brokers with out-of-sync replicas: %v",
+			offlineReplicas, outOfSyncReplicas)
+		return check
+	}
+	check.Passed = true
+	return check
+}
+
+// activeControllerPresentCheck checks whether the brokers report an active controller. It does not tell the health of
+// the ZooKeeper ensemble or of the KRaft quorum, the latter is reported in status.controllerHealth.
+func activeControllerPresentCheck(kClient kafkaclient.KafkaClient) v1beta1.PreflightCheck {
+	check := v1beta1.PreflightCheck{Name: preflightCheckActiveControllerPresent}
+
+	_, controllerID, err := kClient.DescribeCluster()
+	if err != nil {
+		check.Message = fmt.Sprintf("could not describe cluster: %s", err)
+		return check
+	}
+	if controllerID < 0 {
+		check.Message = "the brokers report no active controller"
+		return check
+	}
+	check.Passed = true
+	check.Message = fmt.Sprintf("active controller is broker %d", controllerID)
+	return check
+}
+
+func (r *Reconciler) cruiseControlPreflightChecks(log logr.Logger, operation v1beta1.PreflightOperation, brokersToRemove []string) []v1beta1.PreflightCheck {
+	checkNames := []string{preflightCheckCruiseControlReady, preflightCheckNoInFlightReassignments}
+	if operation == v1beta1.PreflightOperationScaleDown {
+		checkNames = append(checkNames, preflightCheckDiskHeadroom)
+	}
+	failAll := func(msg string) []v1beta1.PreflightCheck {
+		checks := make([]v1beta1.PreflightCheck, 0, len(checkNames))
+		for _, name := range checkNames {
+			checks = append(checks, v1beta1.PreflightCheck{Name: name, Message: msg})
+		}
+		return checks
+	}
+
+	ctx := context.TODO()
+	cc, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, r.KafkaCluster)
+	if err != nil {
+		return failAll(fmt.Sprintf("failed to initialize Cruise Control Scaler: %s", err))
+	}
+	cc = faultinjection.CruiseControlScaler(log, r.KafkaCluster, cc)
+	if !cc.IsUp(ctx) {
+		return failAll("Cruise Control is not reachable")
+	}
+
+	status := cc.Status(ctx)
+	checks := []v1beta1.PreflightCheck{
+		{Name: preflightCheckCruiseControlReady, Passed: status.IsReady()},
+		{Name: preflightCheckNoInFlightReassignments, Passed: !status.InExecution()},
+	}
+	if !status.IsReady() {
+		checks[0].Message = fmt.Sprintf("analyzer ready: %t, monitor ready: %t", status.AnalyzerReady, status.MonitorReady)
+	}
+	if status.InExecution() {
+		checks[1].Message = "Cruise Control executor has an operation in progress"
+	}
+
+	if operation == v1beta1.PreflightOperationScaleDown {
+		diskUsage, err := cc.DiskUsageByBroker(ctx)
+		if err != nil {
+			checks = append(checks, v1beta1.PreflightCheck{
+				Name:    preflightCheckDiskHeadroom,
+				Message: fmt.Sprintf("could not get disk usage of brokers: %s", err),
+			})
+		} else {
+			checks = append(checks, diskHeadroomCheck(diskUsage, brokersToRemove,
+				r.KafkaCluster.Spec.PreflightChecks.GetMaxDiskUsagePercentage()))
+		}
+	}
+	return checks
+}
+
+// diskHeadroomCheck checks whether the remaining brokers have enough disk capacity to take over the data of the brokers
+// to be removed without exceeding maxDiskUsagePercentage.
+func diskHeadroomCheck(diskUsage map[string]scale.DiskUsage, brokersToRemove []string, maxDiskUsagePercentage int) v1beta1.PreflightCheck {
+	check := v1beta1.PreflightCheck{Name: preflightCheckDiskHeadroom}
+
+	removed := make(map[string]struct{}, len(brokersToRemove))
+	for _, id := range brokersToRemove {
+		removed[id] = struct{}{}
+	}
+
+	var usedMB, remainingCapacityMB float64
+	for brokerID, usage := range diskUsage {
+		usedMB += usage.UsedMB
+		if _, ok := removed[brokerID]; !ok {
+			remainingCapacityMB += usage.CapacityMB
+		}
+	}
+
+	if remainingCapacityMB <= 0 {
+		check.Message = "disk capacity of the remaining brokers is unknown"
+		return check
+	}
+
+	usagePercentage := usedMB / remainingCapacityMB * 100
+	check.Message = fmt.Sprintf("disk usage of the remaining brokers would be %.1f%% (max %d%%)", usagePercentage, maxDiskUsagePercentage)
+	check.Passed = usagePercentage <= float64(maxDiskUsagePercentage)
+	return check
+}
+
+// isKafkaVersionUpgrade returns true if the image of the Kafka container differs between the current and desired pods
+func isKafkaVersionUpgrade(currentPod, desiredPod *corev1.Pod) bool {
+	return kafkaImage(currentPod) != kafkaImage(desiredPod)
+}
+
+// kafkaImage returns the image of the Kafka container of the pod
+func kafkaImage(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == kafkaContainerName {
+			return container.Image
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// describeClusterKafkaClient reports the given controller ID, the other methods of the client are not implemented
+type describeClusterKafkaClient struct {
+	kafkaclient.KafkaClient
+	controllerID int32
+}
+
+func (c describeClusterKafkaClient) DescribeCluster() ([]*sarama.Broker, int32, error) {
+	return nil, c.controllerID, nil
+}
+
+func TestActiveControllerPresentCheck(t *testing.T) {
+	check := activeControllerPresentCheck(describeClusterKafkaClient{controllerID: 1})
+	if !check.Passed || check.Name != preflightCheckActiveControllerPresent {
+		t.Errorf("expected passed %s check, got: %+v", preflightCheckActiveControllerPresent, check)
+	}
+
+	check = activeControllerPresentCheck(describeClusterKafkaClient{controllerID: -1})
+	if check.Passed {
+		t.Errorf("expected failed check without active controller, got: %+v", check)
+	}
+}
+
+func TestDiskHeadroomCheck(t *testing.T) {
+	diskUsage := map[string]scale.DiskUsage{
+		"0": {UsedMB: 300, CapacityMB: 1000},
+		"1": {UsedMB: 300, CapacityMB: 1000},
+		"2": {UsedMB: 300, CapacityMB: 1000},
+	}
+
+	testCases := []struct {
+		testName        string
+		brokersToRemove []string
+		maxDiskUsage    int
+		expectedPassed  bool
+	}{
+		{
+			testName:        "remaining brokers have enough headroom",
+			brokersToRemove: []string{"2"},
+			maxDiskUsage:    80,
+			expectedPassed:  true,
+		},
+		{
+			testName:        "remaining brokers would exceed max disk usage",
+			brokersToRemove: []string{"1", "2"},
+			maxDiskUsage:    80,
+			expectedPassed:  false,
+		},
+		{
+			testName:        "all brokers removed",
+			brokersToRemove: []string{"0", "1", "2"},
+			maxDiskUsage:    80,
+			expectedPassed:  false,
+		},
+	}
+
+	for _, test := range testCases {
+		check := diskHeadroomCheck(diskUsage, test.brokersToRemove, test.maxDiskUsage)
+		if check.Passed != test.expectedPassed {
+			t.Errorf("%s: expected passed: %v, got: %v (%s)", test.testName, test.expectedPassed, check.Passed, check.Message)
+		}
+	}
+}
+
+func TestIsKafkaVersionUpgrade(t *testing.T) {
+	podWithImage := func(image string) *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "jmx-exporter", Image: "jmx:1.0"},
+					{Name: kafkaContainerName, Image: image},
+				},
+			},
+		}
+	}
+
+	if isKafkaVersionUpgrade(podWithImage("kafka:3.1.0"), podWithImage("kafka:3.1.0")) {
+		t.Error("Expected no version upgrade for identical images")
+	}
+	if !isKafkaVersionUpgrade(podWithImage("kafka:3.0.0"), podWithImage("kafka:3.1.0")) {
+		t.Error("Expected version upgrade for different images")
+	}
+}
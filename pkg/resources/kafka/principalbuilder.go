@@ -0,0 +1,96 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	principalBuilderContainerName = "principal-builder"
+	principalBuilderClassConfig   = "principal.builder.class"
+	extensionsVolumeName          = "extensions"
+	extensionsVolumePath          = "/opt/kafka/libs/extensions"
+)
+
+var (
+	javaClassNameRegex = regexp.MustCompile(`^([a-zA-Z_$][a-zA-Z0-9_$]*\.)*[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+	jarPathRegex       = regexp.MustCompile(`^/[a-zA-Z0-9_./-]+\.jar$`)
+)
+
+// validatePrincipalBuilder checks the custom principal builder configuration before it is rendered into the broker
+// configuration and the init container of the broker pods
+func validatePrincipalBuilder(config *v1beta1.PrincipalBuilderConfig) error {
+	if config == nil {
+		return nil
+	}
+	if !javaClassNameRegex.MatchString(config.ClassName) {
+		return errorfactory.New(errorfactory.InternalError{}, errors.New("invalid principal builder class name"),
+			"principal builder class name must be a fully qualified java class name", "className", config.ClassName)
+	}
+	if config.JarPath == "" {
+		return nil
+	}
+	if config.Image == "" {
+		return errorfactory.New(errorfactory.InternalError{}, errors.New("principal builder image is not set"),
+			"jarPath can only be used together with image", "jarPath", config.JarPath)
+	}
+	if !jarPathRegex.MatchString(config.JarPath) || strings.Contains(config.JarPath, "..") {
+		return errorfactory.New(errorfactory.InternalError{}, errors.New("invalid principal builder jar path"),
+			"jarPath must be an absolute path of a jar file", "jarPath", config.JarPath)
+	}
+	return nil
+}
+
+// generatePrincipalBuilderConfig sets the custom principal builder class, it takes precedence over the one set in the
+// read-only configuration
+func generatePrincipalBuilderConfig(config *properties.Properties, principalBuilder *v1beta1.PrincipalBuilderConfig, log logr.Logger) {
+	if principalBuilder == nil {
+		return
+	}
+	if err := config.Set(principalBuilderClassConfig, principalBuilder.ClassName); err != nil {
+		log.Error(err, "setting principal.builder.class in broker configuration resulted an error")
+	}
+}
+
+// principalBuilderInitContainer returns the init container which copies the jar of the principal builder into the
+// extensions directory which is on the classpath of the brokers
+func principalBuilderInitContainer(principalBuilder *v1beta1.PrincipalBuilderConfig) (corev1.Container, bool) {
+	if principalBuilder == nil || principalBuilder.Image == "" {
+		return corev1.Container{}, false
+	}
+	jarPath := principalBuilder.GetJarPath()
+	return corev1.Container{
+		Name:    principalBuilderContainerName,
+		Image:   principalBuilder.Image,
+		Command: []string{"cp", "-v", jarPath, path.Join(extensionsVolumePath, path.Base(jarPath))},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      extensionsVolumeName,
+			MountPath: extensionsVolumePath,
+		}},
+		Resources: k8sutil.GetDefaultInitContainerResourceRequirements(),
+	}, true
+}
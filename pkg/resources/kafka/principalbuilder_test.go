@@ -0,0 +1,108 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+func TestValidatePrincipalBuilder(t *testing.T) {
+	testCases := []struct {
+		testName string
+		config   *v1beta1.PrincipalBuilderConfig
+		valid    bool
+	}{
+		{
+			testName: "principal builder not configured",
+			valid:    true,
+		},
+		{
+			testName: "class on the classpath of the Kafka image",
+			config:   &v1beta1.PrincipalBuilderConfig{ClassName: "io.spiffe.kafka.SpiffePrincipalBuilder"},
+			valid:    true,
+		},
+		{
+			testName: "class loaded from an image with custom jar path",
+			config: &v1beta1.PrincipalBuilderConfig{
+				ClassName: "io.spiffe.kafka.SpiffePrincipalBuilder",
+				Image:     "example.com/spiffe-principal-builder:1.0.0",
+				JarPath:   "/opt/spiffe/spiffe-principal-builder.jar",
+			},
+			valid: true,
+		},
+		{
+			testName: "invalid class name",
+			config:   &v1beta1.PrincipalBuilderConfig{ClassName: "io.spiffe.kafka.Spiffe-PrincipalBuilder"},
+		},
+		{
+			testName: "jar path without image",
+			config: &v1beta1.PrincipalBuilderConfig{
+				ClassName: "io.spiffe.kafka.SpiffePrincipalBuilder",
+				JarPath:   "/opt/spiffe/spiffe-principal-builder.jar",
+			},
+		},
+		{
+			testName: "jar path outside of the image directory tree",
+			config: &v1beta1.PrincipalBuilderConfig{
+				ClassName: "io.spiffe.kafka.SpiffePrincipalBuilder",
+				Image:     "example.com/spiffe-principal-builder:1.0.0",
+				JarPath:   "/opt/../etc/spiffe-principal-builder.jar",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		err := validatePrincipalBuilder(test.config)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected valid configuration, got error: %s", test.testName, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected validation error", test.testName)
+		}
+	}
+}
+
+func TestPrincipalBuilder(t *testing.T) {
+	principalBuilder := &v1beta1.PrincipalBuilderConfig{
+		ClassName: "io.spiffe.kafka.SpiffePrincipalBuilder",
+		Image:     "example.com/spiffe-principal-builder:1.0.0",
+	}
+
+	config := properties.NewProperties()
+	generatePrincipalBuilderConfig(config, principalBuilder, logr.Discard())
+	if value, ok := config.Get("principal.builder.class"); !ok || value.Value() != principalBuilder.ClassName {
+		t.Errorf("expected principal.builder.class to be %q, got: %q", principalBuilder.ClassName, value.Value())
+	}
+
+	container, ok := principalBuilderInitContainer(principalBuilder)
+	if !ok {
+		t.Fatal("expected principal builder init container")
+	}
+	expectedCommand := []string{"cp", "-v", "/opt/principal-builder/principal-builder.jar",
+		"/opt/kafka/libs/extensions/principal-builder.jar"}
+	if !reflect.DeepEqual(container.Command, expectedCommand) {
+		t.Errorf("expected init container command: %v, got: %v", expectedCommand, container.Command)
+	}
+
+	if _, ok := principalBuilderInitContainer(&v1beta1.PrincipalBuilderConfig{ClassName: principalBuilder.ClassName}); ok {
+		t.Error("expected no init container when the principal builder image is not set")
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+const resourceRecommendationTimeFormat = "2006-01-02 15:04:05"
+
+// verticalPodAutoscalerGVK is read through unstructured objects, the Vertical Pod Autoscaler is an optional add-on
+var verticalPodAutoscalerGVK = schema.GroupVersionKind{
+	Group:   "autoscaling.k8s.io",
+	Version: "v1",
+	Kind:    "VerticalPodAutoscaler",
+}
+
+// reconcileResourceRecommendations records the resource requests the brokers are to follow from the recommendations
+// of the VerticalPodAutoscalers of their resourceRecommendation. A recommendation is applied once it differs from the
+// applied requests by more than minChangePercent, the last applied requests are kept while the VerticalPodAutoscaler
+// has no recommendation or is missing.
+func (r *Reconciler) reconcileResourceRecommendations(log logr.Logger) error {
+	configs := make(map[string]*v1beta1.ResourceRecommendationConfig)
+	for i := range r.KafkaCluster.Spec.Brokers {
+		brokerConfig, err := r.KafkaCluster.Spec.Brokers[i].GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil || brokerConfig == nil || brokerConfig.ResourceRecommendation == nil {
+			continue
+		}
+		name := brokerConfig.ResourceRecommendation.VerticalPodAutoscaler
+		if _, ok := configs[name]; !ok {
+			configs[name] = brokerConfig.ResourceRecommendation
+		}
+	}
+
+	now := time.Now()
+	var statuses map[string]v1beta1.ResourceRecommendationStatus
+	for name, config := range configs {
+		if statuses == nil {
+			statuses = make(map[string]v1beta1.ResourceRecommendationStatus, len(configs))
+		}
+		current, applied := r.KafkaCluster.Status.ResourceRecommendations[name]
+		target, err := r.verticalPodAutoscalerRecommendation(name)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			log.V(1).Info("no resource recommendation for the brokers (yet)", "verticalPodAutoscaler", name)
+			if applied {
+				statuses[name] = current
+			}
+			continue
+		}
+		requests := boundResourceRecommendation(target, config)
+		if applied && !resourceRequestsChanged(current.Requests, requests, config.GetMinChangePercent()) {
+			statuses[name] = current
+			continue
+		}
+		log.Info("applying resource recommendation to the brokers", "verticalPodAutoscaler", name, "requests", requests)
+		statuses[name] = v1beta1.ResourceRecommendationStatus{
+			Requests:  requests,
+			AppliedAt: now.UTC().Format(resourceRecommendationTimeFormat),
+		}
+	}
+
+	if reflect.DeepEqual(statuses, r.KafkaCluster.Status.ResourceRecommendations) {
+		return nil
+	}
+	if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, statuses, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not update resource recommendations status")
+	}
+	return nil
+}
+
+// verticalPodAutoscalerRecommendation returns the target recommendation of the VerticalPodAutoscaler for the kafka
+// container, it is nil while there is no recommendation or the VerticalPodAutoscaler is missing
+func (r *Reconciler) verticalPodAutoscalerRecommendation(name string) (corev1.ResourceList, error) {
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(verticalPodAutoscalerGVK)
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: r.KafkaCluster.Namespace, Name: name}, vpa)
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "getting VerticalPodAutoscaler failed", "name", name)
+	}
+
+	recommendations, _, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+	for _, recommendation := range recommendations {
+		container, ok := recommendation.(map[string]interface{})
+		if !ok || container["containerName"] != kafkaContainerName {
+			continue
+		}
+		target, _, _ := unstructured.NestedStringMap(container, "target")
+		requests := make(corev1.ResourceList, len(target))
+		for resourceName, value := range target {
+			if quantity, err := resource.ParseQuantity(value); err == nil {
+				requests[corev1.ResourceName(resourceName)] = quantity
+			}
+		}
+		if len(requests) > 0 {
+			return requests, nil
+		}
+	}
+	return nil, nil
+}
+
+// boundResourceRecommendation returns the recommended requests within the minAllowed and maxAllowed bounds
+func boundResourceRecommendation(target corev1.ResourceList, config *v1beta1.ResourceRecommendationConfig) corev1.ResourceList {
+	requests := make(corev1.ResourceList, len(target))
+	for name, quantity := range target {
+		if min, ok := config.MinAllowed[name]; ok && quantity.Cmp(min) < 0 {
+			quantity = min.DeepCopy()
+		}
+		if max, ok := config.MaxAllowed[name]; ok && quantity.Cmp(max) > 0 {
+			quantity = max.DeepCopy()
+		}
+		requests[name] = quantity
+	}
+	return requests
+}
+
+// resourceRequestsChanged returns true if the requests differ from the applied ones in their resources or any of
+// them by more than the given percent
+func resourceRequestsChanged(applied, requests corev1.ResourceList, minChangePercent int32) bool {
+	if len(applied) != len(requests) {
+		return true
+	}
+	for name, quantity := range requests {
+		current, ok := applied[name]
+		if !ok {
+			return true
+		}
+		if current.IsZero() {
+			if !quantity.IsZero() {
+				return true
+			}
+			continue
+		}
+		change := math.Abs(quantity.AsApproximateFloat64()-current.AsApproximateFloat64()) / current.AsApproximateFloat64()
+		if change*100 > float64(minChangePercent) {
+			return true
+		}
+	}
+	return false
+}
+
+// brokerResources returns the resource requirements of the kafka container, the requests follow the recommendation
+// applied from the VerticalPodAutoscaler of the broker and the limits below them are raised to the requests
+func (r *Reconciler) brokerResources(brokerConfig *v1beta1.BrokerConfig) *corev1.ResourceRequirements {
+	resources := brokerConfig.GetResources()
+	if brokerConfig.ResourceRecommendation == nil {
+		return resources
+	}
+	status, ok := r.KafkaCluster.Status.ResourceRecommendations[brokerConfig.ResourceRecommendation.VerticalPodAutoscaler]
+	if !ok || len(status.Requests) == 0 {
+		return resources
+	}
+
+	resources = resources.DeepCopy()
+	if resources.Requests == nil {
+		resources.Requests = make(corev1.ResourceList, len(status.Requests))
+	}
+	for name, quantity := range status.Requests {
+		resources.Requests[name] = quantity.DeepCopy()
+		if limit, ok := resources.Limits[name]; ok && limit.Cmp(quantity) < 0 {
+			resources.Limits[name] = quantity.DeepCopy()
+		}
+	}
+	return resources
+}
@@ -0,0 +1,150 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func newVerticalPodAutoscaler(name string, target map[string]interface{}) *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{"containerName": "other", "target": map[string]interface{}{"cpu": "10"}},
+					map[string]interface{}{"containerName": kafkaContainerName, "target": target},
+				},
+			},
+		},
+	}}
+	vpa.SetGroupVersionKind(verticalPodAutoscalerGVK)
+	vpa.SetName(name)
+	vpa.SetNamespace("kafka")
+	return vpa
+}
+
+func TestReconcileResourceRecommendations(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}, {Id: 1, BrokerConfigGroup: "default"}},
+			BrokerConfigGroups: map[string]v1beta1.BrokerConfig{
+				"default": {
+					ResourceRecommendation: &v1beta1.ResourceRecommendationConfig{
+						VerticalPodAutoscaler: "kafka-brokers",
+						MaxAllowed:            corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+					},
+				},
+			},
+		},
+	}
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	vpa := newVerticalPodAutoscaler("kafka-brokers", map[string]interface{}{"cpu": "2", "memory": "6Gi"})
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			Client:       fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, vpa).Build(),
+			KafkaCluster: cluster,
+		},
+	}
+
+	if err := r.reconcileResourceRecommendations(logr.Discard()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status, ok := cluster.Status.ResourceRecommendations["kafka-brokers"]
+	if !ok || status.AppliedAt == "" {
+		t.Fatalf("expected the recommendation to be applied, got %v", cluster.Status.ResourceRecommendations)
+	}
+	if cpu := status.Requests[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected 2 CPUs requested, got %s", cpu.String())
+	}
+	if memory := status.Requests[corev1.ResourceMemory]; memory.Cmp(resource.MustParse("4Gi")) != 0 {
+		t.Errorf("expected the memory request bounded to 4Gi, got %s", memory.String())
+	}
+
+	brokerConfig, err := cluster.Spec.Brokers[0].GetBrokerConfig(cluster.Spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	brokerResources := r.brokerResources(brokerConfig)
+	if cpu := brokerResources.Requests[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected 2 CPUs requested by the broker, got %s", cpu.String())
+	}
+	if cpu := brokerResources.Limits[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected the CPU limit of the broker raised to 2, got %s", cpu.String())
+	}
+	if memory := brokerResources.Limits[corev1.ResourceMemory]; memory.Cmp(resource.MustParse("4Gi")) != 0 {
+		t.Errorf("expected the memory limit of the broker raised to 4Gi, got %s", memory.String())
+	}
+}
+
+func TestResourceRequestsChanged(t *testing.T) {
+	applied := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+	testCases := []struct {
+		testName string
+		requests corev1.ResourceList
+		changed  bool
+	}{
+		{
+			testName: "minor change",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1050m"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		{
+			testName: "major change",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("3Gi"),
+			},
+			changed: true,
+		},
+		{
+			testName: "changed resources",
+			requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			changed:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			if changed := resourceRequestsChanged(applied, test.requests, 10); changed != test.changed {
+				t.Errorf("expected changed %v, got %v", test.changed, changed)
+			}
+		})
+	}
+}
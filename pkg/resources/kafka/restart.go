@@ -0,0 +1,53 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// restartGeneration returns the restart generations requested for the broker by the cluster, its broker config group
+// and the broker itself. It is empty when no restart has ever been requested so that the pods of the clusters not
+// using restart generations are left untouched.
+func restartGeneration(kSpec v1beta1.KafkaClusterSpec, brokerID int32) string {
+	var clusterGeneration, groupGeneration, brokerGeneration int32
+	clusterGeneration = kSpec.RollingUpgradeConfig.RestartGeneration
+	for _, broker := range kSpec.Brokers {
+		if broker.Id != brokerID {
+			continue
+		}
+		if group, ok := kSpec.BrokerConfigGroups[broker.BrokerConfigGroup]; ok && broker.BrokerConfigGroup != "" {
+			groupGeneration = group.RestartGeneration
+		}
+		if broker.BrokerConfig != nil {
+			brokerGeneration = broker.BrokerConfig.RestartGeneration
+		}
+		break
+	}
+	if clusterGeneration == 0 && groupGeneration == 0 && brokerGeneration == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", clusterGeneration, groupGeneration, brokerGeneration)
+}
+
+// isRestartRequested returns whether the restart generations of the desired broker pod differ from the ones the
+// current pod was started with
+func isRestartRequested(currentPod, desiredPod *corev1.Pod) bool {
+	return currentPod.GetAnnotations()[v1beta1.RestartGenerationAnnotation] != desiredPod.GetAnnotations()[v1beta1.RestartGenerationAnnotation]
+}
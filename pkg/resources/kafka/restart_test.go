@@ -0,0 +1,73 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestRestartGeneration(t *testing.T) {
+	kSpec := v1beta1.KafkaClusterSpec{
+		BrokerConfigGroups: map[string]v1beta1.BrokerConfig{
+			"default": {RestartGeneration: 2},
+			"other":   {},
+		},
+		Brokers: []v1beta1.Broker{
+			{Id: 0, BrokerConfigGroup: "default"},
+			{Id: 1, BrokerConfigGroup: "default", BrokerConfig: &v1beta1.BrokerConfig{RestartGeneration: 1}},
+			{Id: 2, BrokerConfigGroup: "other"},
+		},
+	}
+
+	testCases := []struct {
+		clusterGeneration int32
+		brokerID          int32
+		expected          string
+	}{
+		{brokerID: 0, expected: "0.2.0"},
+		{brokerID: 1, expected: "0.2.1"},
+		{brokerID: 2, expected: ""},
+		{clusterGeneration: 3, brokerID: 2, expected: "3.0.0"},
+		{clusterGeneration: 3, brokerID: 1, expected: "3.2.1"},
+	}
+
+	for _, test := range testCases {
+		kSpec.RollingUpgradeConfig.RestartGeneration = test.clusterGeneration
+		if generation := restartGeneration(kSpec, test.brokerID); generation != test.expected {
+			t.Errorf("broker %d with cluster restart generation %d: expected %q, got: %q",
+				test.brokerID, test.clusterGeneration, test.expected, generation)
+		}
+	}
+}
+
+func TestIsKafkaPodMetadataOnlyChangedWithRestartGeneration(t *testing.T) {
+	currentPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"foo": "bar"}}}
+
+	desiredPod := currentPod.DeepCopy()
+	desiredPod.Annotations["foo"] = "baz"
+	if metadataOnly, err := isKafkaPodMetadataOnlyChanged(currentPod, desiredPod); err != nil || !metadataOnly {
+		t.Errorf("expected metadata only change, got: %v, %v", metadataOnly, err)
+	}
+
+	desiredPod.Annotations[v1beta1.RestartGenerationAnnotation] = "1.0.0"
+	if metadataOnly, err := isKafkaPodMetadataOnlyChanged(currentPod, desiredPod); err != nil || metadataOnly {
+		t.Errorf("expected the change of the restart generation to require a restart, got: %v, %v", metadataOnly, err)
+	}
+}
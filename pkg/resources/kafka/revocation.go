@@ -0,0 +1,54 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strings"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const (
+	// javaSecurityPropertiesName is the key of the java security properties overrides in the broker configmap
+	javaSecurityPropertiesName = "java.security"
+)
+
+// certificateRevocationJVMOpts returns the JVM options which enable revocation checking of the client certificates
+func certificateRevocationJVMOpts(config *v1beta1.CertificateRevocationConfig) string {
+	if !config.IsEnabled() {
+		return ""
+	}
+	opts := []string{"-Dcom.sun.net.ssl.checkRevocation=true"}
+	if config.EnableCRLDistributionPoints {
+		opts = append(opts, "-Dcom.sun.security.enableCRLDP=true")
+	}
+	if config.EnableOCSP {
+		opts = append(opts, "-Djava.security.properties=/config/"+javaSecurityPropertiesName)
+	}
+	return " " + strings.Join(opts, " ")
+}
+
+// javaSecurityProperties returns the java security properties overrides needed for OCSP as these can not be set
+// through system properties
+func javaSecurityProperties(config *v1beta1.CertificateRevocationConfig) string {
+	if config == nil || !config.EnableOCSP {
+		return ""
+	}
+	props := []string{"ocsp.enable=true"}
+	if config.OCSPResponderURL != "" {
+		props = append(props, "ocsp.responderURL="+config.OCSPResponderURL)
+	}
+	return strings.Join(props, "\n")
+}
@@ -0,0 +1,61 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestCertificateRevocation(t *testing.T) {
+	testCases := []struct {
+		testName              string
+		config                *v1beta1.CertificateRevocationConfig
+		expectedJVMOpts       string
+		expectedSecurityProps string
+	}{
+		{
+			testName: "revocation checking not configured",
+		},
+		{
+			testName: "revocation checking disabled",
+			config:   &v1beta1.CertificateRevocationConfig{},
+		},
+		{
+			testName:        "CRL distribution points",
+			config:          &v1beta1.CertificateRevocationConfig{EnableCRLDistributionPoints: true},
+			expectedJVMOpts: " -Dcom.sun.net.ssl.checkRevocation=true -Dcom.sun.security.enableCRLDP=true",
+		},
+		{
+			testName: "OCSP with responder override",
+			config: &v1beta1.CertificateRevocationConfig{
+				EnableOCSP:       true,
+				OCSPResponderURL: "http://ocsp.example.com",
+			},
+			expectedJVMOpts:       " -Dcom.sun.net.ssl.checkRevocation=true -Djava.security.properties=/config/java.security",
+			expectedSecurityProps: "ocsp.enable=true\nocsp.responderURL=http://ocsp.example.com",
+		},
+	}
+
+	for _, test := range testCases {
+		if opts := certificateRevocationJVMOpts(test.config); opts != test.expectedJVMOpts {
+			t.Errorf("%s: expected JVM opts: %q, got: %q", test.testName, test.expectedJVMOpts, opts)
+		}
+		if props := javaSecurityProperties(test.config); props != test.expectedSecurityProps {
+			t.Errorf("%s: expected security properties: %q, got: %q", test.testName, test.expectedSecurityProps, props)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+// restartingBrokerPods returns the broker pods being restarted, which are either terminating or waiting for their
+// containers to be created
+func restartingBrokerPods(pods []corev1.Pod) []corev1.Pod {
+	var restarting []corev1.Pod
+	for _, pod := range pods {
+		pod := pod
+		if k8sutil.IsMarkedForDeletion(pod.ObjectMeta) || k8sutil.IsPodContainsPendingContainer(&pod) {
+			restarting = append(restarting, pod)
+		}
+	}
+	return restarting
+}
+
+// checkRollingUpgradeConcurrency returns an error if the broker of currentPod can not be restarted while the given
+// broker pods are being restarted
+func (r *Reconciler) checkRollingUpgradeConcurrency(currentPod *corev1.Pod, restarting []corev1.Pod) error {
+	rollingUpgradeConfig := r.KafkaCluster.Spec.RollingUpgradeConfig
+	for _, pod := range restarting {
+		if pod.Name == currentPod.Name || len(restarting) >= rollingUpgradeConfig.GetConcurrency() {
+			reason := "pod is still creating"
+			if k8sutil.IsMarkedForDeletion(pod.ObjectMeta) {
+				reason = "pod is still terminating"
+			}
+			return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New(reason), "rolling upgrade in progress")
+		}
+	}
+
+	if rollingUpgradeConfig.GetConcurrencyPolicy() != v1beta1.RollingUpgradeConcurrencyRack {
+		return nil
+	}
+	rack := r.brokerRack(currentPod.Labels["brokerId"])
+	for _, pod := range restarting {
+		if podRack := r.brokerRack(pod.Labels["brokerId"]); rack == "" || podRack != rack {
+			return errorfactory.New(errorfactory.ReconcileRollingUpgrade{},
+				fmt.Errorf("broker %s of rack %q is still restarting", pod.Labels["brokerId"], podRack), "rolling upgrade in progress")
+		}
+	}
+	return nil
+}
+
+// checkRestartingPartitions returns an error if the broker of currentPod holds replicas of a partition together with a
+// broker being restarted, restarting both of them could take the partition offline. The brokers of different racks
+// hold the replicas of different partitions with the Rack concurrency policy.
+func (r *Reconciler) checkRestartingPartitions(kClient kafkaclient.KafkaClient, currentPod *corev1.Pod, restartingBrokers map[int32]struct{}) error {
+	if r.KafkaCluster.Spec.RollingUpgradeConfig.GetConcurrencyPolicy() == v1beta1.RollingUpgradeConcurrencyRack {
+		return nil
+	}
+	brokerID, err := strconv.ParseInt(currentPod.Labels["brokerId"], 10, 32)
+	if err != nil || len(restartingBrokers) == 0 {
+		return nil
+	}
+	partitions, err := kClient.PartitionReplicas()
+	if err != nil {
+		return errors.WrapIf(err, "health check failed")
+	}
+	if shared := sharedPartitions(partitions, int32(brokerID), restartingBrokers); len(shared) > 0 {
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{},
+			fmt.Errorf("broker %d shares partitions %v with brokers being restarted", brokerID, shared), "rolling upgrade in progress")
+	}
+	return nil
+}
+
+// sharedPartitions returns the partitions having replicas on the given broker and on any of the other brokers
+func sharedPartitions(partitions []kafkaclient.PartitionReplicas, brokerID int32, brokers map[int32]struct{}) []string {
+	var shared []string
+	for _, partition := range partitions {
+		var onBroker, onOthers bool
+		for _, replica := range partition.Replicas {
+			if replica == brokerID {
+				onBroker = true
+			} else if _, ok := brokers[replica]; ok {
+				onOthers = true
+			}
+		}
+		if onBroker && onOthers {
+			shared = append(shared, fmt.Sprintf("%s-%d", partition.Topic, partition.Partition))
+		}
+	}
+	return shared
+}
+
+// brokerRack returns the rack the broker was placed into by rack awareness, empty if it is not known
+func (r *Reconciler) brokerRack(brokerID string) string {
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		if strconv.Itoa(int(broker.Id)) != brokerID {
+			continue
+		}
+		readOnlyConfig, err := properties.NewFromString(broker.ReadOnlyConfig)
+		if err != nil {
+			return ""
+		}
+		if rack, ok := readOnlyConfig.Get("broker.rack"); ok {
+			return rack.Value()
+		}
+	}
+	return ""
+}
+
+// pendingBrokerRestart is the restart of a broker pod deferred until the brokers waiting for a restart are ordered
+type pendingBrokerRestart struct {
+	brokerID string
+	restart  func() error
+}
+
+// brokerRestartRanks returns the rank of each broker in the restart order of the rolling upgrades known before the
+// reconciliation of the brokers, the brokers of lower ranks are restarted first. It is nil if the order of the brokers
+// in the spec is kept or the brokers are ordered by their load only once their restarts are pending.
+func (r *Reconciler) brokerRestartRanks() map[int32]int {
+	rollingUpgradeConfig := r.KafkaCluster.Spec.RollingUpgradeConfig
+	if rollingUpgradeConfig.GetOrder() != v1beta1.RollingUpgradeOrderExplicit {
+		return nil
+	}
+	return explicitRestartRanks(rollingUpgradeConfig.BrokerOrder, r.KafkaCluster.Spec.Brokers)
+}
+
+// restartPendingBrokers restarts the brokers the restarts of which were deferred to order them by their load, the
+// brokers leading the fewest partitions first
+func (r *Reconciler) restartPendingBrokers(log logr.Logger) error {
+	if len(r.pendingRestarts) == 0 {
+		return nil
+	}
+	pending := r.pendingRestarts
+	r.pendingRestarts = nil
+	sortPendingRestarts(pending, r.leaderRestartRanks(log))
+	for _, p := range pending {
+		if err := p.restart(); err != nil {
+			return errors.Wrap(err, "could not handle rolling upgrade")
+		}
+	}
+	return nil
+}
+
+// leaderRestartRanks ranks the brokers by the number of partitions they lead. It is nil if the leaders could not be
+// looked up, so the brokers are restarted in the order of their IDs.
+func (r *Reconciler) leaderRestartRanks(log logr.Logger) map[int32]int {
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		log.Error(err, "could not connect to kafka brokers, the brokers are restarted in the order of their IDs")
+		return nil
+	}
+	defer close()
+	leaders, err := kClient.LeadersByBroker()
+	if err != nil {
+		log.Error(err, "could not get the partition leaders of the brokers, the brokers are restarted in the order of their IDs")
+		return nil
+	}
+	ranks := make(map[int32]int, len(leaders))
+	for brokerID, count := range leaders {
+		ranks[brokerID] = int(count)
+	}
+	return ranks
+}
+
+// sortPendingRestarts orders the pending restarts by the ranks of their brokers, and by the broker IDs within the
+// same rank
+func sortPendingRestarts(pending []pendingBrokerRestart, ranks map[int32]int) {
+	brokerID := func(p pendingBrokerRestart) int32 {
+		id, _ := strconv.ParseInt(p.brokerID, 10, 32)
+		return int32(id)
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		id1, id2 := brokerID(pending[i]), brokerID(pending[j])
+		if ranks[id1] != ranks[id2] {
+			return ranks[id1] < ranks[id2]
+		}
+		return id1 < id2
+	})
+}
+
+// explicitRestartRanks ranks the brokers by their position in brokerOrder, the brokers not listed are ranked after
+// the listed ones
+func explicitRestartRanks(brokerOrder []int32, brokers []v1beta1.Broker) map[int32]int {
+	ranks := make(map[int32]int, len(brokers))
+	for _, broker := range brokers {
+		ranks[broker.Id] = len(brokerOrder)
+	}
+	for i, brokerID := range brokerOrder {
+		if _, ok := ranks[brokerID]; ok {
+			ranks[brokerID] = i
+		}
+	}
+	return ranks
+}
+
+// brokerIDsOfPods returns the IDs of the brokers the given pods belong to
+func brokerIDsOfPods(pods []corev1.Pod) map[int32]struct{} {
+	brokerIDs := make(map[int32]struct{}, len(pods))
+	for _, pod := range pods {
+		if id, err := strconv.ParseInt(pod.Labels["brokerId"], 10, 32); err == nil {
+			brokerIDs[int32(id)] = struct{}{}
+		}
+	}
+	return brokerIDs
+}
@@ -0,0 +1,238 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestCheckRollingUpgradeConcurrency(t *testing.T) {
+	now := metav1.Now()
+	brokerPod := func(id string, terminating bool) corev1.Pod {
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-" + id, Labels: map[string]string{"brokerId": id}}}
+		if terminating {
+			pod.DeletionTimestamp = &now
+		}
+		return pod
+	}
+	brokers := []v1beta1.Broker{
+		{Id: 0, ReadOnlyConfig: "broker.rack=zone-a\n"},
+		{Id: 1, ReadOnlyConfig: "broker.rack=zone-a\n"},
+		{Id: 2, ReadOnlyConfig: "broker.rack=zone-b\n"},
+		{Id: 3, ReadOnlyConfig: "broker.rack=zone-a\n"},
+	}
+
+	tests := []struct {
+		testName    string
+		config      v1beta1.RollingUpgradeConfig
+		currentPod  corev1.Pod
+		restarting  []corev1.Pod
+		expectError bool
+	}{
+		{
+			testName:   "no broker is restarting",
+			currentPod: brokerPod("1", false),
+		},
+		{
+			testName:    "a broker is restarting by default",
+			currentPod:  brokerPod("1", false),
+			restarting:  []corev1.Pod{brokerPod("0", true)},
+			expectError: true,
+		},
+		{
+			testName:   "concurrency allows another broker",
+			config:     v1beta1.RollingUpgradeConfig{Concurrency: 2},
+			currentPod: brokerPod("2", false),
+			restarting: []corev1.Pod{brokerPod("0", true)},
+		},
+		{
+			testName:    "concurrency is exhausted",
+			config:      v1beta1.RollingUpgradeConfig{Concurrency: 2},
+			currentPod:  brokerPod("2", false),
+			restarting:  []corev1.Pod{brokerPod("0", true), brokerPod("1", false)},
+			expectError: true,
+		},
+		{
+			testName:    "the broker itself is restarting",
+			config:      v1beta1.RollingUpgradeConfig{Concurrency: 2},
+			currentPod:  brokerPod("1", false),
+			restarting:  []corev1.Pod{brokerPod("1", false)},
+			expectError: true,
+		},
+		{
+			testName:   "a broker of the same rack is restarting",
+			config:     v1beta1.RollingUpgradeConfig{Concurrency: 3, ConcurrencyPolicy: v1beta1.RollingUpgradeConcurrencyRack},
+			currentPod: brokerPod("3", false),
+			restarting: []corev1.Pod{brokerPod("0", true), brokerPod("1", false)},
+		},
+		{
+			testName:    "a broker of another rack is restarting",
+			config:      v1beta1.RollingUpgradeConfig{Concurrency: 3, ConcurrencyPolicy: v1beta1.RollingUpgradeConcurrencyRack},
+			currentPod:  brokerPod("2", false),
+			restarting:  []corev1.Pod{brokerPod("0", true)},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		r := Reconciler{
+			Reconciler: resources.Reconciler{
+				KafkaCluster: &v1beta1.KafkaCluster{
+					Spec: v1beta1.KafkaClusterSpec{
+						Brokers:              brokers,
+						RollingUpgradeConfig: test.config,
+					},
+				},
+			},
+		}
+		currentPod := test.currentPod
+		err := r.checkRollingUpgradeConcurrency(&currentPod, test.restarting)
+		if test.expectError && err == nil {
+			t.Errorf("%s: expected an error", test.testName)
+		}
+		if !test.expectError && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.testName, err)
+		}
+	}
+}
+
+// partitionsKafkaClient reports the given partitions, the other methods of the client are not implemented
+type partitionsKafkaClient struct {
+	kafkaclient.KafkaClient
+	partitions []kafkaclient.PartitionReplicas
+}
+
+func (c partitionsKafkaClient) PartitionReplicas() ([]kafkaclient.PartitionReplicas, error) {
+	return c.partitions, nil
+}
+
+func TestCheckRestartingPartitions(t *testing.T) {
+	kClient := partitionsKafkaClient{partitions: []kafkaclient.PartitionReplicas{
+		{Topic: "orders", Partition: 0, Replicas: []int32{0, 1}, Isr: []int32{0, 1}},
+		{Topic: "orders", Partition: 1, Replicas: []int32{1, 2}, Isr: []int32{1, 2}},
+	}}
+	currentPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-0", Labels: map[string]string{"brokerId": "0"}}}
+
+	tests := []struct {
+		testName    string
+		policy      v1beta1.RollingUpgradeConcurrencyPolicy
+		restarting  map[int32]struct{}
+		expectError bool
+	}{
+		{
+			testName: "no broker is restarting",
+		},
+		{
+			testName:    "a restarting broker shares a partition",
+			restarting:  map[int32]struct{}{1: {}},
+			expectError: true,
+		},
+		{
+			testName:   "a restarting broker shares no partition",
+			restarting: map[int32]struct{}{2: {}},
+		},
+		{
+			testName:   "the brokers of a rack are restarted together",
+			policy:     v1beta1.RollingUpgradeConcurrencyRack,
+			restarting: map[int32]struct{}{1: {}},
+		},
+	}
+
+	for _, test := range tests {
+		r := Reconciler{
+			Reconciler: resources.Reconciler{
+				KafkaCluster: &v1beta1.KafkaCluster{
+					Spec: v1beta1.KafkaClusterSpec{
+						RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{Concurrency: 2, ConcurrencyPolicy: test.policy},
+					},
+				},
+			},
+		}
+		err := r.checkRestartingPartitions(kClient, currentPod, test.restarting)
+		if test.expectError != (err != nil) {
+			t.Errorf("%s: expected error: %v, got: %v", test.testName, test.expectError, err)
+		}
+	}
+}
+
+func TestExplicitRestartRanks(t *testing.T) {
+	brokers := []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}, {Id: 3}}
+	ranks := explicitRestartRanks([]int32{2, 0, 5}, brokers)
+
+	expected := map[int32]int{0: 1, 1: 3, 2: 0, 3: 3}
+	if !reflect.DeepEqual(ranks, expected) {
+		t.Errorf("expected restart ranks: %v, got: %v", expected, ranks)
+	}
+}
+
+type unreachableKafkaProvider struct{}
+
+func (unreachableKafkaProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return nil, nil, errors.New("kafka is unreachable")
+}
+
+func TestSortPendingRestarts(t *testing.T) {
+	pending := []pendingBrokerRestart{{brokerID: "3"}, {brokerID: "1"}, {brokerID: "10"}, {brokerID: "2"}}
+	sortPendingRestarts(pending, map[int32]int{1: 5, 2: 0, 3: 5, 10: 1})
+
+	var order []string
+	for _, p := range pending {
+		order = append(order, p.brokerID)
+	}
+	expected := []string{"2", "10", "1", "3"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected restart order: %v, got: %v", expected, order)
+	}
+}
+
+func TestRestartPendingBrokersWithoutLoad(t *testing.T) {
+	r := &Reconciler{
+		Reconciler:          resources.Reconciler{KafkaCluster: &v1beta1.KafkaCluster{}},
+		kafkaClientProvider: unreachableKafkaProvider{},
+	}
+	var order []string
+	for _, id := range []string{"2", "0", "1"} {
+		id := id
+		r.pendingRestarts = append(r.pendingRestarts, pendingBrokerRestart{
+			brokerID: id,
+			restart: func() error {
+				order = append(order, id)
+				return nil
+			},
+		})
+	}
+
+	if err := r.restartPendingBrokers(logr.Discard()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"0", "1", "2"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected the brokers to be restarted in the order of their IDs: %v, got: %v", expected, order)
+	}
+	if len(r.pendingRestarts) != 0 {
+		t.Errorf("expected no pending restarts left, got %d", len(r.pendingRestarts))
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/faultinjection"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// rollingUpgradeSamplingPauseReason is the reason the metrics sampling of Cruise Control is paused with during rolling
+// upgrades, it tells apart the pauses of the operator from the ones requested by the users
+const rollingUpgradeSamplingPauseReason = "rolling upgrade of the Kafka brokers"
+
+// pauseSamplingForRollingUpgrade pauses the metrics sampling of Cruise Control, so that the metrics of the restarting
+// brokers do not skew its load model. The sampling is not paused if it is paused already, and failing to pause it
+// does not hold back the rolling upgrade.
+func (r *Reconciler) pauseSamplingForRollingUpgrade(log logr.Logger) {
+	if !cruiseControlSamplingManaged(r.KafkaCluster) {
+		return
+	}
+	ctx := context.TODO()
+	cc, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, r.Client, r.KafkaCluster)
+	if err != nil {
+		log.Error(err, "failed to initialize Cruise Control Scaler to pause its metrics sampling")
+		return
+	}
+	cc = faultinjection.CruiseControlScaler(log, r.KafkaCluster, cc)
+	if !cc.IsUp(ctx) || cc.Status(ctx).SamplingPaused {
+		return
+	}
+	if err := cc.PauseSampling(ctx, rollingUpgradeSamplingPauseReason); err != nil {
+		log.Error(err, "failed to pause the metrics sampling of Cruise Control for the rolling upgrade")
+		return
+	}
+	log.Info("paused the metrics sampling of Cruise Control for the rolling upgrade")
+}
+
+// ResumeSamplingAfterRollingUpgrade resumes the metrics sampling of Cruise Control if it was paused for the rolling
+// upgrade of the cluster, the sampling paused by the users is left paused
+func ResumeSamplingAfterRollingUpgrade(ctx context.Context, log logr.Logger, reader client.Reader, cluster *v1beta1.KafkaCluster) error {
+	if !cruiseControlSamplingManaged(cluster) {
+		return nil
+	}
+	cc, err := scale.DefaultScalerRegistry.CruiseControlScaler(ctx, reader, cluster)
+	if err != nil {
+		return err
+	}
+	cc = faultinjection.CruiseControlScaler(log, cluster, cc)
+	status := cc.Status(ctx)
+	if !status.SamplingPaused || status.SamplingPauseReason != rollingUpgradeSamplingPauseReason {
+		return nil
+	}
+	if err := cc.ResumeSampling(ctx, rollingUpgradeSamplingPauseReason); err != nil {
+		return err
+	}
+	log.Info("resumed the metrics sampling of Cruise Control after the rolling upgrade")
+	return nil
+}
+
+// cruiseControlSamplingManaged returns true if the cluster has a Cruise Control whose sampling can be paused
+func cruiseControlSamplingManaged(cluster *v1beta1.KafkaCluster) bool {
+	if cluster.Spec.IsCruiseControlDisabled() {
+		return false
+	}
+	return cluster.Spec.CruiseControlConfig.CruiseControlEndpoint != "" ||
+		cluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady
+}
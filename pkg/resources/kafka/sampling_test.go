@@ -0,0 +1,86 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	fakescale "github.com/banzaicloud/koperator/pkg/scale/fake"
+)
+
+func TestRollingUpgradeSampling(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Status:     v1beta1.KafkaClusterStatus{CruiseControlTopicStatus: v1beta1.CruiseControlTopicReady},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			Client:       kubeClient,
+			KafkaCluster: cluster,
+		},
+	}
+
+	cc := fakescale.NewScaler()
+	scale.MockNewCruiseControlScalerWith(cc)
+	defer scale.MockNewCruiseControlScaler()
+
+	r.pauseSamplingForRollingUpgrade(logr.Discard())
+	if !cc.SamplingPaused || cc.SamplingPauseReason != rollingUpgradeSamplingPauseReason {
+		t.Fatalf("expected the sampling to be paused for the rolling upgrade, got paused: %t, reason: %q",
+			cc.SamplingPaused, cc.SamplingPauseReason)
+	}
+	if err := ResumeSamplingAfterRollingUpgrade(context.TODO(), logr.Discard(), kubeClient, cluster); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cc.SamplingPaused {
+		t.Error("expected the sampling to be resumed after the rolling upgrade")
+	}
+
+	// the sampling paused by the users is left alone
+	cc.SamplingPaused, cc.SamplingPauseReason = true, "maintenance"
+	r.pauseSamplingForRollingUpgrade(logr.Discard())
+	if err := ResumeSamplingAfterRollingUpgrade(context.TODO(), logr.Discard(), kubeClient, cluster); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !cc.SamplingPaused || cc.SamplingPauseReason != "maintenance" {
+		t.Errorf("expected the sampling paused by the users to stay paused, got paused: %t, reason: %q",
+			cc.SamplingPaused, cc.SamplingPauseReason)
+	}
+	calls := make(map[fakescale.Method]int)
+	for _, call := range cc.Calls() {
+		calls[call]++
+	}
+	if calls[fakescale.MethodPauseSampling] != 1 || calls[fakescale.MethodResumeSampling] != 1 {
+		t.Errorf("expected the sampling to be paused and resumed once, got calls: %v", cc.Calls())
+	}
+
+	// there is no Cruise Control to pause with the built-in rebalancer
+	cc.SamplingPaused, cc.SamplingPauseReason = false, ""
+	cluster.Spec.Rebalancer = v1beta1.RebalancerBuiltIn
+	r.pauseSamplingForRollingUpgrade(logr.Discard())
+	if cc.SamplingPaused {
+		t.Error("expected the sampling not to be paused with the built-in rebalancer")
+	}
+}
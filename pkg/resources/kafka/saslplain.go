@@ -0,0 +1,287 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	passwordEncoderSecretTemplate = "%s-password-encoder"
+	passwordEncoderSecretKey      = "secret"
+	passwordEncoderSecretLength   = 32
+
+	// the rendered SASL/PLAIN configuration is kept in a secret mounted into the broker pods and the broker
+	// configuration refers to its files through a config provider so that no password is stored in the configmap
+	saslPlainSecretTemplate       = "%s-sasl-plain"
+	saslPlainVolumeName           = "sasl-plain-config"
+	saslPlainVolumePath           = "/var/run/secrets/kafka/sasl-plain"
+	saslPlainConfigProvider       = "saslplain"
+	directoryConfigProviderClass  = "org.apache.kafka.common.config.provider.DirectoryConfigProvider"
+	passwordEncoderSecretConfig   = "password.encoder.secret"
+	saslPlainJAASConfigKeyPattern = "%s.jaas.config"
+
+	plainLoginModule = "org.apache.kafka.common.security.plain.PlainLoginModule"
+)
+
+// saslPlainConfig holds the rendered SASL/PLAIN JAAS configuration of the listeners and the secret the brokers use
+// to encrypt it when it is updated dynamically
+type saslPlainConfig struct {
+	// jaasConfigs contains the JAAS configuration keyed by listener name
+	jaasConfigs           map[string]string
+	passwordEncoderSecret string
+}
+
+// saslPlainListeners returns the listeners using SASL/PLAIN with externally managed credentials
+func saslPlainListeners(listenersConfig v1beta1.ListenersConfig) []v1beta1.CommonListenerSpec {
+	var listeners []v1beta1.CommonListenerSpec
+	for _, iListener := range listenersConfig.InternalListeners {
+		if iListener.GetSASLPlainCredentialsSecretName() != "" {
+			listeners = append(listeners, iListener.CommonListenerSpec)
+		}
+	}
+	for _, eListener := range listenersConfig.ExternalListeners {
+		if eListener.GetSASLPlainCredentialsSecretName() != "" {
+			listeners = append(listeners, eListener.CommonListenerSpec)
+		}
+	}
+	return listeners
+}
+
+// getSASLPlainConfig renders the JAAS configuration of the listeners using SASL/PLAIN with externally managed credentials
+// and stores it in the secret mounted into the broker pods
+func (r *Reconciler) getSASLPlainConfig(log logr.Logger) (saslPlainConfig, error) {
+	config := saslPlainConfig{jaasConfigs: make(map[string]string)}
+
+	for _, listener := range saslPlainListeners(r.KafkaCluster.Spec.ListenersConfig) {
+		secretName := listener.GetSASLPlainCredentialsSecretName()
+		if !listener.Type.IsSasl() {
+			return config, errorfactory.New(errorfactory.InternalError{},
+				errors.New("SASL/PLAIN credentials can only be used with sasl_ssl or sasl_plaintext listeners"),
+				"invalid listener configuration", "listener", listener.Name, "type", listener.Type)
+		}
+
+		credentialsSecret := &corev1.Secret{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: r.KafkaCluster.Namespace}, credentialsSecret)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return config, errorfactory.New(errorfactory.ResourceNotReady{}, err, "SASL/PLAIN credentials secret not found",
+					"secretName", secretName)
+			}
+			return config, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get SASL/PLAIN credentials secret",
+				"secretName", secretName)
+		}
+		config.jaasConfigs[listener.Name] = plainJAASConfig(credentialsSecret.Data)
+	}
+
+	if len(config.jaasConfigs) == 0 {
+		return config, nil
+	}
+
+	passwordEncoderSecret, err := r.reconcilePasswordEncoderSecret(log)
+	if err != nil {
+		return config, err
+	}
+	config.passwordEncoderSecret = passwordEncoderSecret
+
+	if err := r.reconcileSASLPlainSecret(config, log); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// reconcileSASLPlainSecret stores the rendered SASL/PLAIN configuration in the secret mounted into the broker pods.
+// As the broker configmap only refers to the secret, the per-broker configs of the brokers are marked out of sync
+// when the secret changes so that the brokers reload the credentials.
+func (r *Reconciler) reconcileSASLPlainSecret(saslPlain saslPlainConfig, log logr.Logger) error {
+	name := fmt.Sprintf(saslPlainSecretTemplate, r.KafkaCluster.Name)
+	desired := &corev1.Secret{
+		ObjectMeta: templates.ObjectMeta(name, apiutil.LabelsForKafka(r.KafkaCluster.Name), r.KafkaCluster),
+		Data:       saslPlain.secretData(),
+	}
+
+	current := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: r.KafkaCluster.Namespace}, current)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := r.Client.Create(context.TODO(), desired); err != nil {
+			return errorfactory.New(errorfactory.APIFailure{}, err, "failed to create SASL/PLAIN secret", "secretName", name)
+		}
+		log.Info("SASL/PLAIN secret created", "secretName", name)
+		return nil
+	case err != nil:
+		return errorfactory.New(errorfactory.APIFailure{}, err, "failed to get SASL/PLAIN secret", "secretName", name)
+	case reflect.DeepEqual(current.Data, desired.Data):
+		return nil
+	}
+
+	current.Data = desired.Data
+	if err := r.Client.Update(context.TODO(), current); err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "failed to update SASL/PLAIN secret", "secretName", name)
+	}
+	log.Info("SASL/PLAIN secret updated", "secretName", name)
+
+	brokerIDs := make([]string, 0, len(r.KafkaCluster.Status.BrokersState))
+	for brokerID := range r.KafkaCluster.Status.BrokersState {
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+	if len(brokerIDs) == 0 {
+		return nil
+	}
+	sort.Strings(brokerIDs)
+	if err := k8sutil.UpdateBrokerStatus(r.Client, brokerIDs, r.KafkaCluster, v1beta1.PerBrokerConfigOutOfSync, log); err != nil {
+		return errors.WrapIfWithDetails(err, "updating status for per-broker configuration status failed", "brokerIds", brokerIDs)
+	}
+	return nil
+}
+
+// secretData returns the content of the secret mounted into the broker pods, the file names are the keys the
+// broker configuration refers to
+func (c saslPlainConfig) secretData() map[string][]byte {
+	data := map[string][]byte{
+		passwordEncoderSecretConfig: []byte(c.passwordEncoderSecret),
+	}
+	for listenerName, jaasConfig := range c.jaasConfigs {
+		data[fmt.Sprintf(saslPlainJAASConfigKeyPattern, listenerName)] = []byte(jaasConfig)
+	}
+	return data
+}
+
+// resolve replaces the references to the mounted secret with the rendered JAAS configuration in the given per-broker
+// configs, the brokers receive the actual values when the configs are altered dynamically
+func (c saslPlainConfig) resolve(config *properties.Properties) {
+	for listenerName, jaasConfig := range c.jaasConfigs {
+		key := fmt.Sprintf("listener.name.%s.plain.sasl.jaas.config", listenerName)
+		if _, ok := config.Get(key); ok {
+			_ = config.Set(key, jaasConfig)
+		}
+	}
+}
+
+// saslPlainConfigReference returns the broker config value referring to the given file of the mounted secret
+func saslPlainConfigReference(file string) string {
+	return fmt.Sprintf("${%s:%s:%s}", saslPlainConfigProvider, saslPlainVolumePath, file)
+}
+
+// reconcilePasswordEncoderSecret returns the secret used by the brokers to encrypt the dynamically updated password
+// configs and generates it on first use
+func (r *Reconciler) reconcilePasswordEncoderSecret(log logr.Logger) (string, error) {
+	name := fmt.Sprintf(passwordEncoderSecretTemplate, r.KafkaCluster.Name)
+	secret := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: r.KafkaCluster.Namespace}, secret)
+	switch {
+	case err == nil:
+		return string(secret.Data[passwordEncoderSecretKey]), nil
+	case !apierrors.IsNotFound(err):
+		return "", errorfactory.New(errorfactory.APIFailure{}, err, "failed to get password encoder secret", "secretName", name)
+	}
+
+	secret = &corev1.Secret{
+		ObjectMeta: templates.ObjectMeta(name, apiutil.LabelsForKafka(r.KafkaCluster.Name), r.KafkaCluster),
+		Data: map[string][]byte{
+			passwordEncoderSecretKey: certutil.GeneratePass(passwordEncoderSecretLength),
+		},
+	}
+	if err := r.Client.Create(context.TODO(), secret); err != nil {
+		return "", errorfactory.New(errorfactory.APIFailure{}, err, "failed to create password encoder secret", "secretName", name)
+	}
+	log.Info("password encoder secret created", "secretName", name)
+	return string(secret.Data[passwordEncoderSecretKey]), nil
+}
+
+// plainJAASConfig renders the JAAS configuration of the Kafka PlainLoginModule from the given username-password pairs
+func plainJAASConfig(credentials map[string][]byte) string {
+	usernames := make([]string, 0, len(credentials))
+	for username := range credentials {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	var b strings.Builder
+	b.WriteString(plainLoginModule + " required")
+	for _, username := range usernames {
+		fmt.Fprintf(&b, " user_%s=%s", username, quoteJAASValue(string(credentials[username])))
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+func quoteJAASValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// generateSASLPlainConfig adds the SASL/PLAIN configuration of the listeners to the broker configuration, the JAAS
+// configuration and the password encoder secret are read by the brokers from the mounted secret
+func generateSASLPlainConfig(config *properties.Properties, saslPlain saslPlainConfig, log logr.Logger) {
+	if len(saslPlain.jaasConfigs) == 0 {
+		return
+	}
+	saslPlainConf := map[string]string{
+		"config.providers":          saslPlainConfigProvider,
+		passwordEncoderSecretConfig: saslPlainConfigReference(passwordEncoderSecretConfig),
+	}
+	saslPlainConf[fmt.Sprintf("config.providers.%s.class", saslPlainConfigProvider)] = directoryConfigProviderClass
+	for listenerName := range saslPlain.jaasConfigs {
+		saslPlainConf[fmt.Sprintf("listener.name.%s.sasl.enabled.mechanisms", listenerName)] = "PLAIN"
+		saslPlainConf[fmt.Sprintf("listener.name.%s.plain.sasl.jaas.config", listenerName)] =
+			saslPlainConfigReference(fmt.Sprintf(saslPlainJAASConfigKeyPattern, listenerName))
+	}
+	for k, v := range saslPlainConf {
+		if err := config.Set(k, v); err != nil {
+			log.Error(err, fmt.Sprintf("setting %s parameter in broker configuration resulted an error", k))
+		}
+	}
+}
+
+// mergeConfigProviders keeps the config providers of the user configuration next to the ones added by the operator
+func mergeConfigProviders(userConfig, opGenConfig *properties.Properties) {
+	userProviders, ok := userConfig.Get("config.providers")
+	if !ok || userProviders.Value() == "" {
+		return
+	}
+	opGenProviders, ok := opGenConfig.Get("config.providers")
+	if !ok {
+		return
+	}
+	var providers []string
+	for _, provider := range strings.Split(userProviders.Value(), ",") {
+		providers = append(providers, strings.TrimSpace(provider))
+	}
+	for _, provider := range strings.Split(opGenProviders.Value(), ",") {
+		if !util.StringSliceContains(providers, provider) {
+			providers = append(providers, provider)
+		}
+	}
+	_ = opGenConfig.Set("config.providers", strings.Join(providers, ","))
+}
@@ -0,0 +1,244 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+func TestPlainJAASConfig(t *testing.T) {
+	jaasConfig := plainJAASConfig(map[string][]byte{
+		"bob":   []byte(`pass"word`),
+		"alice": []byte("secret"),
+	})
+	expected := `org.apache.kafka.common.security.plain.PlainLoginModule required user_alice="secret" user_bob="pass\"word";`
+	if jaasConfig != expected {
+		t.Errorf("expected JAAS config: %s, got: %s", expected, jaasConfig)
+	}
+}
+
+func TestGetSASLPlainConfig(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka",
+			Namespace: "kafka",
+		},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				ExternalListeners: []v1beta1.ExternalListenerConfig{
+					{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{
+							Type:                       v1beta1.SecurityProtocolSaslPlaintext,
+							Name:                       "legacy",
+							ContainerPort:              9094,
+							SASLPlainCredentialsSecret: &corev1.LocalObjectReference{Name: "legacy-credentials"},
+						},
+					},
+				},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {PerBrokerConfigurationState: v1beta1.PerBrokerConfigInSync},
+			},
+		},
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy-credentials", Namespace: "kafka"},
+		Data:       map[string][]byte{"alice": []byte("secret")},
+	}
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			Client:       fake.NewClientBuilder().WithScheme(s).WithObjects(cluster, credentials).Build(),
+			KafkaCluster: cluster,
+		},
+	}
+
+	saslPlain, err := r.getSASLPlainConfig(logr.Discard())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(saslPlain.passwordEncoderSecret) != passwordEncoderSecretLength {
+		t.Errorf("expected generated password encoder secret, got: %q", saslPlain.passwordEncoderSecret)
+	}
+
+	// the generated password encoder secret must be reused
+	again, err := r.getSASLPlainConfig(logr.Discard())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if again.passwordEncoderSecret != saslPlain.passwordEncoderSecret {
+		t.Error("expected the password encoder secret to be stable")
+	}
+
+	// the rendered configuration is stored in the secret mounted into the broker pods
+	saslPlainSecret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "kafka-sasl-plain", Namespace: "kafka"}, saslPlainSecret); err != nil {
+		t.Fatalf("expected SASL/PLAIN secret: %s", err)
+	}
+	for key, expected := range map[string]string{
+		"legacy.jaas.config":      `org.apache.kafka.common.security.plain.PlainLoginModule required user_alice="secret";`,
+		"password.encoder.secret": saslPlain.passwordEncoderSecret,
+	} {
+		if value := string(saslPlainSecret.Data[key]); value != expected {
+			t.Errorf("expected %s=%s in SASL/PLAIN secret, got: %s", key, expected, value)
+		}
+	}
+	if state := r.KafkaCluster.Status.BrokersState["0"].PerBrokerConfigurationState; state != v1beta1.PerBrokerConfigInSync {
+		t.Errorf("expected per-broker config state to be left in sync on creation, got: %s", state)
+	}
+
+	config := properties.NewProperties()
+	generateSASLPlainConfig(config, saslPlain, logr.Discard())
+	for key, expected := range map[string]string{
+		"config.providers":                             "saslplain",
+		"config.providers.saslplain.class":             "org.apache.kafka.common.config.provider.DirectoryConfigProvider",
+		"listener.name.legacy.sasl.enabled.mechanisms": "PLAIN",
+		"listener.name.legacy.plain.sasl.jaas.config":  "${saslplain:/var/run/secrets/kafka/sasl-plain:legacy.jaas.config}",
+		"password.encoder.secret":                      "${saslplain:/var/run/secrets/kafka/sasl-plain:password.encoder.secret}",
+	} {
+		if p, ok := config.Get(key); !ok || p.Value() != expected {
+			t.Errorf("expected %s=%s in broker config, got: %v", key, expected, p)
+		}
+	}
+
+	// the brokers receive the actual JAAS configuration when it is altered dynamically
+	saslPlain.resolve(config)
+	if p, _ := config.Get("listener.name.legacy.plain.sasl.jaas.config"); p.Value() != saslPlain.jaasConfigs["legacy"] {
+		t.Errorf("expected resolved JAAS config, got: %s", p.Value())
+	}
+
+	// changed credentials are reloaded by the brokers
+	credentials.Data["bob"] = []byte("other")
+	if err := r.Client.Update(context.TODO(), credentials); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.getSASLPlainConfig(logr.Discard()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "kafka-sasl-plain", Namespace: "kafka"}, saslPlainSecret); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saslPlainSecret.Data["legacy.jaas.config"]), `user_bob="other"`) {
+		t.Errorf("expected updated JAAS config in SASL/PLAIN secret, got: %s", saslPlainSecret.Data["legacy.jaas.config"])
+	}
+	if state := r.KafkaCluster.Status.BrokersState["0"].PerBrokerConfigurationState; state != v1beta1.PerBrokerConfigOutOfSync {
+		t.Errorf("expected per-broker config state to be out of sync, got: %s", state)
+	}
+
+	// SASL/PLAIN credentials are not allowed for non SASL listeners
+	cluster.Spec.ListenersConfig.ExternalListeners[0].Type = v1beta1.SecurityProtocolPlaintext
+	if _, err := r.getSASLPlainConfig(logr.Discard()); err == nil {
+		t.Error("expected error for plaintext listener")
+	}
+}
+
+func TestSASLPlainPasswordsNotInConfigMap(t *testing.T) {
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			KafkaCluster: &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kafka",
+					Namespace: "kafka",
+				},
+				Spec: v1beta1.KafkaClusterSpec{
+					ReadOnlyConfig: "config.providers=env\nconfig.providers.env.class=org.apache.kafka.common.config.provider.EnvVarConfigProvider",
+					ListenersConfig: v1beta1.ListenersConfig{
+						InternalListeners: []v1beta1.InternalListenerConfig{
+							{
+								CommonListenerSpec: v1beta1.CommonListenerSpec{
+									Type:                       v1beta1.SecurityProtocolSaslPlaintext,
+									Name:                       "internal",
+									ContainerPort:              9092,
+									SASLPlainCredentialsSecret: &corev1.LocalObjectReference{Name: "credentials"},
+								},
+								UsedForInnerBrokerCommunication: true,
+							},
+						},
+					},
+					Brokers: []v1beta1.Broker{{
+						Id:           0,
+						BrokerConfig: &v1beta1.BrokerConfig{},
+					}},
+				},
+			},
+		},
+	}
+	saslPlain := saslPlainConfig{
+		jaasConfigs: map[string]string{
+			"internal": plainJAASConfig(map[string][]byte{"alice": []byte("alice-password")}),
+		},
+		passwordEncoderSecret: "encoder-secret",
+	}
+
+	renderedConfig := r.renderBrokerConfig(0, r.KafkaCluster.Spec.Brokers[0].BrokerConfig, map[string]v1beta1.ListenerStatusList{},
+		map[string]v1beta1.ListenerStatusList{}, map[string]v1beta1.ListenerStatusList{}, nil, "", nil, saslPlain, logr.Discard())
+	for _, configMap := range []*corev1.ConfigMap{
+		r.configMap(0, r.KafkaCluster.Spec.Brokers[0].BrokerConfig, renderedConfig),
+		r.effectiveConfigMap(0, renderedConfig),
+	} {
+		for key, data := range configMap.Data {
+			for _, secret := range []string{"alice-password", "encoder-secret"} {
+				if strings.Contains(data, secret) {
+					t.Errorf("expected no password in %s of configmap %s, got: %s", key, configMap.Name, data)
+				}
+			}
+		}
+	}
+
+	// the config providers of the user are kept
+	if p, ok := renderedConfig.Static().Get("config.providers"); !ok || p.Value() != "env,saslplain" {
+		t.Errorf("expected config providers: env,saslplain, got: %v", p)
+	}
+
+	volumes := getVolumes(nil, nil, r.KafkaCluster.Spec, r.KafkaCluster.Name, 0)
+	volumeMounts := getVolumeMounts(nil, nil, r.KafkaCluster.Spec, r.KafkaCluster.Name)
+	var hasVolume, hasVolumeMount bool
+	for _, volume := range volumes {
+		if volume.Name == saslPlainVolumeName {
+			hasVolume = volume.Secret != nil && volume.Secret.SecretName == "kafka-sasl-plain"
+		}
+	}
+	for _, volumeMount := range volumeMounts {
+		if volumeMount.Name == saslPlainVolumeName {
+			hasVolumeMount = volumeMount.MountPath == saslPlainVolumePath
+		}
+	}
+	if !hasVolume || !hasVolumeMount {
+		t.Error("expected the SASL/PLAIN secret to be mounted into the broker pod")
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// checkSmokeTestGate returns a SmokeTestNotPassed error if the smoke test of the previous change is still running or
+// has failed, so that no further disruptive operation is started on a cluster that may be broken. The gate can be
+// bypassed with the emergency override annotation.
+func (r *Reconciler) checkSmokeTestGate() error {
+	if !r.KafkaCluster.Spec.SmokeTest.Enabled {
+		return nil
+	}
+	status := r.KafkaCluster.Status.SmokeTest
+	if status == nil || !status.State.IsBlocking() {
+		return nil
+	}
+	if _, ok := r.KafkaCluster.GetAnnotations()[v1beta1.EmergencyOverrideAnnotation]; ok {
+		return nil
+	}
+	return errorfactory.New(errorfactory.SmokeTestNotPassed{}, errors.New("smoke test has not passed"),
+		"refusing to start operation", "job", status.JobName, "state", status.State)
+}
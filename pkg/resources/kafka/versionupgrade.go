@@ -0,0 +1,272 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	versionUpgradeTimeFormat = "2006-01-02 15:04:05"
+
+	interBrokerProtocolVersionConfig = "inter.broker.protocol.version"
+	logMessageFormatVersionConfig    = "log.message.format.version"
+	// logMessageFormatIgnoredVersion is the Kafka version from which log.message.format.version is ignored
+	logMessageFormatIgnoredVersion = "3.0.0"
+
+	abortRefusedMessage = "abort refused, the brokers can not be rolled back once the protocol is bumped"
+)
+
+// reconcileVersionUpgrade moves the Kafka version upgrade staged by the operator to its next phase, the brokers are
+// rolled by the reconciliation of their pods and configurations following the phase
+func (r *Reconciler) reconcileVersionUpgrade(log logr.Logger, pods []corev1.Pod) error {
+	status, changed := stageVersionUpgrade(r.KafkaCluster, pods, time.Now())
+	if !changed {
+		return nil
+	}
+	if status != nil {
+		log.Info("kafka version upgrade updated", "phase", status.Phase,
+			"fromVersion", status.FromVersion, "toVersion", status.ToVersion)
+	}
+	if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not update version upgrade status")
+	}
+	return nil
+}
+
+// clusterImage returns the image of the brokers not setting their own one: the image the version upgrade started
+// from while an aborted upgrade rolls the brokers back, spec.clusterImage otherwise
+func (r *Reconciler) clusterImage() string {
+	image := r.KafkaCluster.Spec.GetClusterImage()
+	status := r.KafkaCluster.Status.VersionUpgrade
+	if status != nil && status.Phase == v1beta1.VersionUpgradeAborted && !status.IsFinished() && status.ToImage == image {
+		return status.FromImage
+	}
+	return image
+}
+
+// stageVersionUpgrade returns the status of the Kafka version upgrade of the cluster at the given time and whether it
+// changed. An upgrade starts when the brokers taking their image from spec.clusterImage run an older minor Kafka
+// version, the protocol is bumped once all of them run the new version for spec.versionUpgrade.protocolBumpDelay and
+// the upgrade completes once all brokers were restarted since the bump. The status is cleared when
+// spec.versionUpgrade is removed and in KRaft mode.
+func stageVersionUpgrade(cluster *v1beta1.KafkaCluster, pods []corev1.Pod, now time.Time) (*v1beta1.VersionUpgradeStatus, bool) {
+	current := cluster.Status.VersionUpgrade
+	if cluster.Spec.VersionUpgrade == nil || cluster.Spec.KRaftMode {
+		return nil, current != nil
+	}
+
+	var status *v1beta1.VersionUpgradeStatus
+	if current == nil || current.IsFinished() {
+		if status = newVersionUpgrade(cluster, pods, now); status == nil {
+			return current, false
+		}
+	} else {
+		status = current.DeepCopy()
+	}
+
+	transitionTime := now.UTC().Format(versionUpgradeTimeFormat)
+	clusterImage := cluster.Spec.GetClusterImage()
+	abortRequested := v1beta1.IsVersionUpgradeAbortRequested(cluster.GetAnnotations())
+	switch status.Phase {
+	case v1beta1.VersionUpgradeRollingOutBinaries:
+		switch {
+		case clusterImage == status.FromImage:
+			status.SetPhase(v1beta1.VersionUpgradeAborted,
+				fmt.Sprintf("spec.clusterImage was reverted to %s", status.FromImage), transitionTime)
+			status.FinishedAt = transitionTime
+		case abortRequested:
+			status.SetPhase(v1beta1.VersionUpgradeAborted,
+				fmt.Sprintf("abort requested, the brokers are rolled back to %s until spec.clusterImage is changed", status.FromImage),
+				transitionTime)
+		case clusterImage != status.ToImage:
+			status.ToImage = clusterImage
+			status.ToVersion = kafkautils.ParseKafkaVersion(clusterImage)
+			status.BinariesRolledOutAt = ""
+			status.SetPhase(v1beta1.VersionUpgradeRollingOutBinaries,
+				fmt.Sprintf("spec.clusterImage changed, rolling the brokers out to %s", clusterImage), transitionTime)
+		case isVersionRolledOut(cluster, pods, status.ToImage):
+			if status.BinariesRolledOutAt == "" {
+				status.BinariesRolledOutAt = transitionTime
+			}
+			rolledOutAt, err := time.Parse(versionUpgradeTimeFormat, status.BinariesRolledOutAt)
+			if err == nil && now.UTC().Before(rolledOutAt.Add(cluster.Spec.VersionUpgrade.GetProtocolBumpDelay())) {
+				break
+			}
+			status.ProtocolBumpedAt = transitionTime
+			status.SetPhase(v1beta1.VersionUpgradeBumpingProtocol,
+				fmt.Sprintf("all brokers run Kafka %s, lifting the protocol version pinned to %s", status.ToVersion, status.ProtocolVersion),
+				transitionTime)
+		}
+	case v1beta1.VersionUpgradeBumpingProtocol:
+		if abortRequested && status.Conditions[len(status.Conditions)-1].Message != abortRefusedMessage {
+			status.SetPhase(v1beta1.VersionUpgradeBumpingProtocol, abortRefusedMessage, transitionTime)
+		}
+		bumpedAt, err := time.Parse(versionUpgradeTimeFormat, status.ProtocolBumpedAt)
+		if err == nil && isProtocolBumpRolledOut(cluster, pods, bumpedAt) {
+			status.SetPhase(v1beta1.VersionUpgradeCompleted,
+				fmt.Sprintf("all brokers run Kafka %s and talk its protocol", status.ToVersion), transitionTime)
+			status.FinishedAt = transitionTime
+		}
+	case v1beta1.VersionUpgradeAborted:
+		if clusterImage != status.ToImage {
+			status.SetPhase(v1beta1.VersionUpgradeAborted,
+				fmt.Sprintf("spec.clusterImage changed, the brokers are no longer rolled back to %s", status.FromImage),
+				transitionTime)
+			status.FinishedAt = transitionTime
+		}
+	}
+	return status, !reflect.DeepEqual(status, current)
+}
+
+// newVersionUpgrade returns a new version upgrade if the brokers taking their image from spec.clusterImage run an
+// older minor Kafka version, nil otherwise. Patch upgrades and downgrades are not staged, neither are the upgrades of
+// the clusters setting the protocol versions in readOnlyConfig.
+func newVersionUpgrade(cluster *v1beta1.KafkaCluster, pods []corev1.Pod, now time.Time) *v1beta1.VersionUpgradeStatus {
+	clusterImage := cluster.Spec.GetClusterImage()
+	toVersion := kafkautils.ParseKafkaVersion(clusterImage)
+	if toVersion == "" || isProtocolVersionSetByUser(cluster) {
+		return nil
+	}
+
+	brokers := brokersTakingClusterImage(cluster)
+	for i := range pods {
+		if !brokers[pods[i].Labels["brokerId"]] {
+			continue
+		}
+		fromImage := kafkaImage(&pods[i])
+		fromVersion := kafkautils.ParseKafkaVersion(fromImage)
+		if fromImage == clusterImage || fromVersion == "" ||
+			kafkautils.CompareKafkaVersions(kafkautils.MinorVersion(fromVersion), kafkautils.MinorVersion(toVersion)) >= 0 {
+			continue
+		}
+
+		startedAt := now.UTC().Format(versionUpgradeTimeFormat)
+		status := &v1beta1.VersionUpgradeStatus{
+			FromImage:       fromImage,
+			ToImage:         clusterImage,
+			FromVersion:     fromVersion,
+			ToVersion:       toVersion,
+			ProtocolVersion: kafkautils.MinorVersion(fromVersion),
+			StartedAt:       startedAt,
+		}
+		status.SetPhase(v1beta1.VersionUpgradeRollingOutBinaries,
+			fmt.Sprintf("rolling the brokers out to Kafka %s with the protocol version pinned to %s", toVersion, status.ProtocolVersion),
+			startedAt)
+		return status
+	}
+	return nil
+}
+
+// isProtocolVersionSetByUser returns whether the readOnlyConfig of the cluster or of any broker sets the protocol
+// versions, the operator leaves their staging to the user then
+func isProtocolVersionSetByUser(cluster *v1beta1.KafkaCluster) bool {
+	readOnlyConfigs := []string{cluster.Spec.ReadOnlyConfig}
+	for _, broker := range cluster.Spec.Brokers {
+		readOnlyConfigs = append(readOnlyConfigs, broker.ReadOnlyConfig)
+	}
+	for _, readOnlyConfig := range readOnlyConfigs {
+		config, err := properties.NewFromString(readOnlyConfig)
+		if err != nil {
+			continue
+		}
+		for _, key := range []string{interBrokerProtocolVersionConfig, logMessageFormatVersionConfig} {
+			if _, ok := config.Get(key); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// brokersTakingClusterImage returns the IDs of the brokers not setting their own image
+func brokersTakingClusterImage(cluster *v1beta1.KafkaCluster) map[string]bool {
+	brokers := make(map[string]bool, len(cluster.Spec.Brokers))
+	for _, broker := range cluster.Spec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(cluster.Spec)
+		if err == nil && brokerConfig.Image == "" {
+			brokers[strconv.Itoa(int(broker.Id))] = true
+		}
+	}
+	return brokers
+}
+
+// podsByBrokerID returns the pods of the cluster by broker ID, the terminating pods are left out
+func podsByBrokerID(pods []corev1.Pod) map[string]*corev1.Pod {
+	podsByBroker := make(map[string]*corev1.Pod, len(pods))
+	for i := range pods {
+		if pods[i].DeletionTimestamp == nil {
+			podsByBroker[pods[i].Labels["brokerId"]] = &pods[i]
+		}
+	}
+	return podsByBroker
+}
+
+// isVersionRolledOut returns whether the brokers taking their image from spec.clusterImage all run the given image
+// and are ready
+func isVersionRolledOut(cluster *v1beta1.KafkaCluster, pods []corev1.Pod, image string) bool {
+	podsByBroker := podsByBrokerID(pods)
+	for brokerID := range brokersTakingClusterImage(cluster) {
+		pod, ok := podsByBroker[brokerID]
+		if !ok || kafkaImage(pod) != image || !isPodReady(pod) {
+			return false
+		}
+	}
+	return true
+}
+
+// isProtocolBumpRolledOut returns whether all brokers are ready and were restarted with their current configuration
+// since the given time
+func isProtocolBumpRolledOut(cluster *v1beta1.KafkaCluster, pods []corev1.Pod, bumpedAt time.Time) bool {
+	podsByBroker := podsByBrokerID(pods)
+	for _, broker := range cluster.Spec.Brokers {
+		brokerID := strconv.Itoa(int(broker.Id))
+		pod, ok := podsByBroker[brokerID]
+		if !ok || !isPodReady(pod) || pod.CreationTimestamp.UTC().Before(bumpedAt) ||
+			cluster.Status.BrokersState[brokerID].ConfigurationState != v1beta1.ConfigInSync {
+			return false
+		}
+	}
+	return true
+}
+
+// generateVersionUpgradeConfig pins inter.broker.protocol.version, and below Kafka 3.0 log.message.format.version, to
+// the old Kafka version while the brokers of a staged version upgrade are rolled out or rolled back
+func generateVersionUpgradeConfig(config *properties.Properties, status *v1beta1.VersionUpgradeStatus, log logr.Logger) {
+	protocolVersion := status.GetPinnedProtocolVersion()
+	if protocolVersion == "" {
+		return
+	}
+	if err := config.Set(interBrokerProtocolVersionConfig, protocolVersion); err != nil {
+		log.Error(err, "setting inter.broker.protocol.version in broker configuration resulted an error")
+	}
+	if kafkautils.CompareKafkaVersions(status.FromVersion, logMessageFormatIgnoredVersion) < 0 {
+		if err := config.Set(logMessageFormatVersionConfig, protocolVersion); err != nil {
+			log.Error(err, "setting log.message.format.version in broker configuration resulted an error")
+		}
+	}
+}
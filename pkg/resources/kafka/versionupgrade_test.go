@@ -0,0 +1,288 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	oldKafkaImage = "ghcr.io/banzaicloud/kafka:2.13-2.8.1"
+	newKafkaImage = "ghcr.io/banzaicloud/kafka:2.13-3.1.0"
+)
+
+func newVersionUpgradeCluster(clusterImage string) *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			ClusterImage:   clusterImage,
+			VersionUpgrade: &v1beta1.VersionUpgradeConfig{},
+			Brokers:        []v1beta1.Broker{{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{}}, {Id: 1, BrokerConfig: &v1beta1.BrokerConfig{}}},
+		},
+		Status: v1beta1.KafkaClusterStatus{BrokersState: map[string]v1beta1.BrokerState{
+			"0": {ConfigurationState: v1beta1.ConfigInSync},
+			"1": {ConfigurationState: v1beta1.ConfigInSync},
+		}},
+	}
+}
+
+func newVersionUpgradePod(id int, image string, createdAt time.Time) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:            map[string]string{"brokerId": strconv.Itoa(id)},
+			CreationTimestamp: metav1.NewTime(createdAt),
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: kafkaContainerName, Image: image}}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+}
+
+func TestStageVersionUpgrade(t *testing.T) {
+	start := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	cluster := newVersionUpgradeCluster(newKafkaImage)
+	cluster.Spec.VersionUpgrade.ProtocolBumpDelay = &metav1.Duration{Duration: time.Hour}
+	oldPods := []corev1.Pod{newVersionUpgradePod(0, oldKafkaImage, start), newVersionUpgradePod(1, oldKafkaImage, start)}
+
+	// the upgrade starts with the protocol versions pinned to the running version
+	status, changed := stageVersionUpgrade(cluster, oldPods, start)
+	if !changed || status == nil || status.Phase != v1beta1.VersionUpgradeRollingOutBinaries {
+		t.Fatalf("expected the upgrade to start, got: %+v", status)
+	}
+	if status.FromImage != oldKafkaImage || status.FromVersion != "2.8.1" || status.ToVersion != "3.1.0" || status.ProtocolVersion != "2.8" {
+		t.Errorf("unexpected upgrade: %+v", status)
+	}
+	cluster.Status.VersionUpgrade = status
+
+	// the protocol is not bumped until all brokers run the new version
+	mixedPods := []corev1.Pod{newVersionUpgradePod(0, newKafkaImage, start), newVersionUpgradePod(1, oldKafkaImage, start)}
+	if _, changed = stageVersionUpgrade(cluster, mixedPods, start.Add(time.Minute)); changed {
+		t.Error("expected no change while the brokers are rolled out")
+	}
+
+	// nor until the delay after the roll out is over
+	newPods := []corev1.Pod{newVersionUpgradePod(0, newKafkaImage, start), newVersionUpgradePod(1, newKafkaImage, start)}
+	status, _ = stageVersionUpgrade(cluster, newPods, start.Add(2*time.Minute))
+	if status.Phase != v1beta1.VersionUpgradeRollingOutBinaries || status.BinariesRolledOutAt != "2022-05-10 12:02:00" {
+		t.Fatalf("expected the roll out to be recorded, got: %+v", status)
+	}
+	cluster.Status.VersionUpgrade = status
+
+	bumpedAt := start.Add(time.Hour + 2*time.Minute)
+	status, _ = stageVersionUpgrade(cluster, newPods, bumpedAt)
+	if status.Phase != v1beta1.VersionUpgradeBumpingProtocol || status.GetPinnedProtocolVersion() != "" {
+		t.Fatalf("expected the protocol to be bumped, got: %+v", status)
+	}
+	cluster.Status.VersionUpgrade = status
+
+	// the upgrade completes once all brokers are restarted since the bump
+	restartedPods := []corev1.Pod{newVersionUpgradePod(0, newKafkaImage, bumpedAt.Add(time.Minute)), newPods[1]}
+	if _, changed = stageVersionUpgrade(cluster, restartedPods, bumpedAt.Add(2*time.Minute)); changed {
+		t.Error("expected no change while the brokers are restarted")
+	}
+	restartedPods[1] = newVersionUpgradePod(1, newKafkaImage, bumpedAt.Add(2*time.Minute))
+	status, _ = stageVersionUpgrade(cluster, restartedPods, bumpedAt.Add(3*time.Minute))
+	if status.Phase != v1beta1.VersionUpgradeCompleted || !status.IsFinished() || len(status.Conditions) != 3 {
+		t.Fatalf("expected the upgrade to complete, got: %+v", status)
+	}
+	cluster.Status.VersionUpgrade = status
+
+	if _, changed = stageVersionUpgrade(cluster, restartedPods, bumpedAt.Add(4*time.Minute)); changed {
+		t.Error("expected no new upgrade after the completed one")
+	}
+}
+
+func TestStageVersionUpgradeAbort(t *testing.T) {
+	start := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	cluster := newVersionUpgradeCluster(newKafkaImage)
+	pods := []corev1.Pod{newVersionUpgradePod(0, newKafkaImage, start), newVersionUpgradePod(1, oldKafkaImage, start)}
+	status, _ := stageVersionUpgrade(cluster, pods, start)
+	cluster.Status.VersionUpgrade = status
+
+	cluster.Annotations = map[string]string{v1beta1.AbortVersionUpgradeAnnotation: "true"}
+	status, _ = stageVersionUpgrade(cluster, pods, start.Add(time.Minute))
+	if status.Phase != v1beta1.VersionUpgradeAborted || status.IsFinished() {
+		t.Fatalf("expected the upgrade to be aborted, got: %+v", status)
+	}
+	cluster.Status.VersionUpgrade = status
+
+	r := &Reconciler{}
+	r.KafkaCluster = cluster
+	if image := r.clusterImage(); image != oldKafkaImage {
+		t.Errorf("expected the brokers to be rolled back to %s, got: %s", oldKafkaImage, image)
+	}
+	if status.GetPinnedProtocolVersion() != "2.8" {
+		t.Errorf("expected the protocol version to stay pinned, got: %+v", status)
+	}
+
+	// the abort is over once spec.clusterImage is reverted
+	cluster.Spec.ClusterImage = oldKafkaImage
+	status, _ = stageVersionUpgrade(cluster, pods, start.Add(2*time.Minute))
+	if !status.IsFinished() {
+		t.Fatalf("expected the aborted upgrade to finish, got: %+v", status)
+	}
+	cluster.Status.VersionUpgrade = status
+	if image := r.clusterImage(); image != oldKafkaImage {
+		t.Errorf("expected spec.clusterImage, got: %s", image)
+	}
+}
+
+func TestStageVersionUpgradeAbortAfterBump(t *testing.T) {
+	start := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	cluster := newVersionUpgradeCluster(newKafkaImage)
+	cluster.Annotations = map[string]string{v1beta1.AbortVersionUpgradeAnnotation: "true"}
+	cluster.Status.VersionUpgrade = &v1beta1.VersionUpgradeStatus{
+		Phase:            v1beta1.VersionUpgradeBumpingProtocol,
+		FromImage:        oldKafkaImage,
+		ToImage:          newKafkaImage,
+		ProtocolBumpedAt: "2022-05-10 12:00:00",
+		Conditions:       []v1beta1.VersionUpgradeCondition{{Phase: v1beta1.VersionUpgradeBumpingProtocol}},
+	}
+	pods := []corev1.Pod{newVersionUpgradePod(0, newKafkaImage, start.Add(-time.Hour)), newVersionUpgradePod(1, newKafkaImage, start.Add(-time.Hour))}
+
+	status, changed := stageVersionUpgrade(cluster, pods, start.Add(time.Minute))
+	if !changed || status.Phase != v1beta1.VersionUpgradeBumpingProtocol || status.Conditions[1].Message != abortRefusedMessage {
+		t.Fatalf("expected the abort to be refused, got: %+v", status)
+	}
+	cluster.Status.VersionUpgrade = status
+	if _, changed = stageVersionUpgrade(cluster, pods, start.Add(2*time.Minute)); changed {
+		t.Error("expected the refusal to be recorded once")
+	}
+}
+
+func TestStageVersionUpgradeSkipped(t *testing.T) {
+	start := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		testName string
+		modify   func(cluster *v1beta1.KafkaCluster)
+		oldImage string
+	}{
+		{
+			testName: "patch upgrade",
+			oldImage: "ghcr.io/banzaicloud/kafka:2.13-3.1.1",
+			modify: func(cluster *v1beta1.KafkaCluster) {
+				cluster.Spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-3.1.2"
+			},
+		},
+		{
+			testName: "downgrade",
+			oldImage: newKafkaImage,
+			modify: func(cluster *v1beta1.KafkaCluster) {
+				cluster.Spec.ClusterImage = oldKafkaImage
+			},
+		},
+		{
+			testName: "protocol version set by the user",
+			oldImage: oldKafkaImage,
+			modify: func(cluster *v1beta1.KafkaCluster) {
+				cluster.Spec.ReadOnlyConfig = "inter.broker.protocol.version=2.8"
+			},
+		},
+		{
+			testName: "brokers setting their own image",
+			oldImage: oldKafkaImage,
+			modify: func(cluster *v1beta1.KafkaCluster) {
+				for i := range cluster.Spec.Brokers {
+					cluster.Spec.Brokers[i].BrokerConfig.Image = oldKafkaImage
+				}
+			},
+		},
+		{
+			testName: "KRaft mode",
+			oldImage: oldKafkaImage,
+			modify: func(cluster *v1beta1.KafkaCluster) {
+				cluster.Spec.KRaftMode = true
+			},
+		},
+		{
+			testName: "not enabled",
+			oldImage: oldKafkaImage,
+			modify: func(cluster *v1beta1.KafkaCluster) {
+				cluster.Spec.VersionUpgrade = nil
+			},
+		},
+	}
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			cluster := newVersionUpgradeCluster(newKafkaImage)
+			test.modify(cluster)
+			pods := []corev1.Pod{newVersionUpgradePod(0, test.oldImage, start), newVersionUpgradePod(1, test.oldImage, start)}
+			if status, changed := stageVersionUpgrade(cluster, pods, start); changed || status != nil {
+				t.Errorf("expected no upgrade, got: %+v", status)
+			}
+		})
+	}
+}
+
+func TestGenerateVersionUpgradeConfig(t *testing.T) {
+	testCases := []struct {
+		testName                string
+		status                  *v1beta1.VersionUpgradeStatus
+		protocolVersion         string
+		logMessageFormatVersion string
+	}{
+		{
+			testName: "no upgrade",
+		},
+		{
+			testName:                "rolling out from Kafka 2.x",
+			status:                  &v1beta1.VersionUpgradeStatus{Phase: v1beta1.VersionUpgradeRollingOutBinaries, FromVersion: "2.8.1", ProtocolVersion: "2.8"},
+			protocolVersion:         "2.8",
+			logMessageFormatVersion: "2.8",
+		},
+		{
+			testName:        "rolling out from Kafka 3.x",
+			status:          &v1beta1.VersionUpgradeStatus{Phase: v1beta1.VersionUpgradeRollingOutBinaries, FromVersion: "3.1.0", ProtocolVersion: "3.1"},
+			protocolVersion: "3.1",
+		},
+		{
+			testName:                "aborted",
+			status:                  &v1beta1.VersionUpgradeStatus{Phase: v1beta1.VersionUpgradeAborted, FromVersion: "2.8.1", ProtocolVersion: "2.8"},
+			protocolVersion:         "2.8",
+			logMessageFormatVersion: "2.8",
+		},
+		{
+			testName: "bumping the protocol",
+			status:   &v1beta1.VersionUpgradeStatus{Phase: v1beta1.VersionUpgradeBumpingProtocol, FromVersion: "2.8.1", ProtocolVersion: "2.8"},
+		},
+	}
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			config := properties.NewProperties()
+			generateVersionUpgradeConfig(config, test.status, logr.Discard())
+			for key, expected := range map[string]string{
+				interBrokerProtocolVersionConfig: test.protocolVersion,
+				logMessageFormatVersionConfig:    test.logMessageFormatVersion,
+			} {
+				var actual string
+				if p, ok := config.Get(key); ok {
+					actual = p.Value()
+				}
+				if actual != expected {
+					t.Errorf("expected %s=%q, got: %q", key, expected, actual)
+				}
+			}
+		})
+	}
+}
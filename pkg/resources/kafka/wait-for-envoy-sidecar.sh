@@ -27,6 +27,9 @@ if [[ -n "$ENVOY_SIDECAR_STATUS" ]]; then
     fi
   done
 fi
+if [[ -n "$KAFKA_CLUSTER_ID" ]]; then
+  /opt/kafka/bin/kafka-storage.sh format --ignore-formatted --cluster-id "$KAFKA_CLUSTER_ID" --config /config/broker-config
+fi
 touch /var/run/wait/do-not-exit-yet
 /opt/kafka/bin/kafka-server-start.sh /config/broker-config
 rm /var/run/wait/do-not-exit-yet
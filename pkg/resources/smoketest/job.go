@@ -0,0 +1,125 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smoketest
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+var (
+	//go:embed smoke-test.sh
+	smokeTestScript string
+)
+
+func (r *Reconciler) job(name, bootstrapServers string) *batchv1.Job {
+	smokeTestConfig := r.KafkaCluster.Spec.SmokeTest
+
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if r.usesSSL() {
+		volumes = append(volumes, corev1.Volume{
+			Name: keystoreVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  r.clientSecretName(),
+					DefaultMode: util.Int32Pointer(0644),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      keystoreVolume,
+			MountPath: keystoreVolumePath,
+		})
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: templates.ObjectMeta(name, labelsForSmokeTest(r.KafkaCluster.Name), r.KafkaCluster),
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          util.Int32Pointer(2),
+			ActiveDeadlineSeconds: util.Int64Pointer(smokeTestConfig.GetTimeoutSeconds()),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: templates.ObjectMetaWithAnnotations(name, labelsForSmokeTest(r.KafkaCluster.Name),
+					map[string]string{"sidecar.istio.io/inject": "false"}, r.KafkaCluster),
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "smoke-test",
+							Image:   smokeTestConfig.GetImage(r.KafkaCluster.Spec.GetClusterImage()),
+							Command: []string{"/bin/bash", "-c", smokeTestScript},
+							Env: []corev1.EnvVar{
+								{Name: "BOOTSTRAP_SERVERS", Value: bootstrapServers},
+								{Name: "TOPIC", Value: smokeTestTopicName},
+								{Name: "NUM_RECORDS", Value: strconv.Itoa(int(smokeTestConfig.GetNumRecords()))},
+								{Name: "KEYSTORE_PATH", Value: keystoreVolumePath},
+							},
+							VolumeMounts: volumeMounts,
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+func (r *Reconciler) topic() *v1alpha1.KafkaTopic {
+	partitions := int32(len(r.KafkaCluster.Spec.Brokers))
+	replicationFactor := partitions
+	if replicationFactor > maxReplicationFactor {
+		replicationFactor = maxReplicationFactor
+	}
+	return &v1alpha1.KafkaTopic{
+		ObjectMeta: templates.ObjectMeta(fmt.Sprintf(topicNameTemplate, r.KafkaCluster.Name),
+			labelsForSmokeTest(r.KafkaCluster.Name), r.KafkaCluster),
+		Spec: v1alpha1.KafkaTopicSpec{
+			Name:              smokeTestTopicName,
+			Partitions:        partitions,
+			ReplicationFactor: replicationFactor,
+			ClusterRef: v1alpha1.ClusterReference{
+				Name:      r.KafkaCluster.Name,
+				Namespace: r.KafkaCluster.Namespace,
+			},
+		},
+	}
+}
+
+func (r *Reconciler) user() *v1alpha1.KafkaUser {
+	name := fmt.Sprintf(userNameTemplate, r.KafkaCluster.Name)
+	return &v1alpha1.KafkaUser{
+		ObjectMeta: templates.ObjectMeta(name, labelsForSmokeTest(r.KafkaCluster.Name), r.KafkaCluster),
+		Spec: v1alpha1.KafkaUserSpec{
+			SecretName: name,
+			ClusterRef: v1alpha1.ClusterReference{
+				Name:      r.KafkaCluster.Name,
+				Namespace: r.KafkaCluster.Namespace,
+			},
+			IncludeJKS: true,
+			TopicGrants: []v1alpha1.UserTopicGrant{
+				{TopicName: smokeTestTopicName, AccessType: v1alpha1.KafkaAccessTypeRead},
+				{TopicName: smokeTestTopicName, AccessType: v1alpha1.KafkaAccessTypeWrite},
+			},
+		},
+	}
+}
@@ -0,0 +1,30 @@
+#!/bin/bash
+set -euo pipefail
+
+CLIENT_CONFIG=/tmp/client.properties
+touch "${CLIENT_CONFIG}"
+if [ -f "${KEYSTORE_PATH}/keystore.jks" ]; then
+  PASSWORD=$(cat "${KEYSTORE_PATH}/password")
+  cat > "${CLIENT_CONFIG}" <<CONFIG
+security.protocol=SSL
+ssl.keystore.location=${KEYSTORE_PATH}/keystore.jks
+ssl.keystore.password=${PASSWORD}
+ssl.truststore.location=${KEYSTORE_PATH}/truststore.jks
+ssl.truststore.password=${PASSWORD}
+CONFIG
+fi
+
+for broker in ${BOOTSTRAP_SERVERS//,/ }; do
+  echo "checking broker ${broker}"
+  /opt/kafka/bin/kafka-broker-api-versions.sh --bootstrap-server "${broker}" --command-config "${CLIENT_CONFIG}" > /dev/null
+done
+
+echo "producing ${NUM_RECORDS} records to ${TOPIC}"
+/opt/kafka/bin/kafka-producer-perf-test.sh --topic "${TOPIC}" --num-records "${NUM_RECORDS}" --record-size 100 \
+  --throughput -1 --producer.config "${CLIENT_CONFIG}" --producer-props bootstrap.servers="${BOOTSTRAP_SERVERS}" acks=all
+
+echo "consuming ${NUM_RECORDS} records from ${TOPIC}"
+/opt/kafka/bin/kafka-console-consumer.sh --bootstrap-server "${BOOTSTRAP_SERVERS}" --consumer.config "${CLIENT_CONFIG}" \
+  --topic "${TOPIC}" --from-beginning --max-messages "${NUM_RECORDS}" --timeout-ms 60000 > /dev/null
+
+echo "smoke test passed"
@@ -0,0 +1,306 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smoketest
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/util"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+	"github.com/banzaicloud/koperator/pkg/webhook"
+)
+
+const (
+	componentName        = "smoketest"
+	jobNameTemplate      = "%s-smoke-test-%s"
+	topicNameTemplate    = "%s-smoke-test-topic"
+	userNameTemplate     = "%s-smoke-test"
+	smokeTestTopicName   = "koperator-smoke-test"
+	keystoreVolume       = "ks-files"
+	keystoreVolumePath   = "/var/run/secrets/java.io/keystores"
+	fingerprintLength    = 10
+	maxReplicationFactor = 3
+)
+
+// Reconciler implements the Component Reconciler
+type Reconciler struct {
+	resources.Reconciler
+}
+
+// New creates a new reconciler for the post-change smoke test
+func New(client client.Client, cluster *v1beta1.KafkaCluster) *Reconciler {
+	return &Reconciler{
+		Reconciler: resources.Reconciler{
+			Client:       client,
+			KafkaCluster: cluster,
+		},
+	}
+}
+
+func labelsForSmokeTest(clusterName string) map[string]string {
+	return map[string]string{"app": "kafka-smoke-test", "kafka_cr": clusterName}
+}
+
+// Reconcile implements the reconcile logic for the post-change smoke test
+func (r *Reconciler) Reconcile(log logr.Logger) error {
+	log = log.WithValues("component", componentName)
+
+	if !r.KafkaCluster.Spec.SmokeTest.Enabled {
+		return nil
+	}
+
+	log.V(1).Info("Reconciling")
+
+	stable, err := r.isClusterStable()
+	if err != nil {
+		return err
+	}
+	if !stable {
+		log.V(1).Info("brokers are not stable yet, postponing smoke test")
+		return nil
+	}
+
+	fingerprint := clusterFingerprint(r.KafkaCluster)
+	status := r.KafkaCluster.Status.SmokeTest
+	if status != nil && status.Fingerprint == fingerprint && status.State == v1beta1.SmokeTestPassed {
+		log.V(1).Info("smoke test already passed")
+		return nil
+	}
+
+	if err := r.reconcileTopic(); err != nil {
+		return err
+	}
+	if err := r.reconcileUser(); err != nil {
+		return err
+	}
+
+	jobName := fmt.Sprintf(jobNameTemplate, r.KafkaCluster.Name, fingerprint)
+	current := &batchv1.Job{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Namespace: r.KafkaCluster.Namespace, Name: jobName}, current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "getting smoke test job failed", "name", jobName)
+	}
+
+	if apierrors.IsNotFound(err) {
+		if status != nil && status.JobName != jobName {
+			if err := r.deleteJob(status.JobName); err != nil {
+				return err
+			}
+		}
+
+		bootstrapServers, err := kafkautils.GetBootstrapServers(r.KafkaCluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not determine bootstrap servers for smoke test")
+		}
+		if err := r.Client.Create(context.TODO(), r.job(jobName, bootstrapServers)); err != nil {
+			return errorfactory.New(errorfactory.APIFailure{}, err, "creating smoke test job failed", "name", jobName)
+		}
+		log.Info("smoke test job created", "name", jobName)
+
+		return k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, &v1beta1.SmokeTestStatus{
+			State:       v1beta1.SmokeTestRunning,
+			Fingerprint: fingerprint,
+			JobName:     jobName,
+			StartTime:   time.Now().Format("2006-01-02 15:04:05"),
+		}, log)
+	}
+
+	state, completionTime := jobState(current)
+	if status != nil && status.JobName == jobName && status.State == state {
+		return nil
+	}
+
+	newStatus := &v1beta1.SmokeTestStatus{
+		State:          state,
+		Fingerprint:    fingerprint,
+		JobName:        jobName,
+		CompletionTime: completionTime,
+	}
+	if current.Status.StartTime != nil {
+		newStatus.StartTime = current.Status.StartTime.Format("2006-01-02 15:04:05")
+	}
+	if state == v1beta1.SmokeTestFailed {
+		log.Info("smoke test failed, delete the job to rerun it", "name", jobName)
+	}
+	return k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, newStatus, log)
+}
+
+// isClusterStable returns true if all the brokers in the spec are up and ready and there is no pending or running
+// Cruise Control operation (e.g. graceful scaling) for any of them.
+func (r *Reconciler) isClusterStable() (bool, error) {
+	brokerIDs := make(map[string]struct{}, len(r.KafkaCluster.Spec.Brokers))
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		brokerIDs[strconv.Itoa(int(broker.Id))] = struct{}{}
+	}
+
+	for id, brokerState := range r.KafkaCluster.Status.BrokersState {
+		if _, ok := brokerIDs[id]; !ok {
+			return false, nil
+		}
+		if brokerState.GracefulActionState.CruiseControlState.IsActive() {
+			return false, nil
+		}
+		for _, volumeState := range brokerState.GracefulActionState.VolumeStates {
+			if volumeState.CruiseControlVolumeState.IsActive() {
+				return false, nil
+			}
+		}
+	}
+
+	podList := &corev1.PodList{}
+	err := r.Client.List(context.TODO(), podList, client.InNamespace(r.KafkaCluster.Namespace),
+		client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name)))
+	if err != nil {
+		return false, errorfactory.New(errorfactory.APIFailure{}, err, "listing broker pods failed")
+	}
+	if len(podList.Items) != len(brokerIDs) {
+		return false, nil
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if k8sutil.IsMarkedForDeletion(pod.ObjectMeta) || !isPodReady(pod) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// clusterFingerprint identifies the set of brokers and the images they are running so that a new smoke test is run
+// after each rolling upgrade and scaling event.
+func clusterFingerprint(cluster *v1beta1.KafkaCluster) string {
+	brokers := make([]string, 0, len(cluster.Spec.Brokers))
+	for _, broker := range cluster.Spec.Brokers {
+		image := cluster.Spec.GetClusterImage()
+		if brokerConfig, err := broker.GetBrokerConfig(cluster.Spec); err == nil && brokerConfig != nil {
+			image = util.GetBrokerImage(brokerConfig, cluster.Spec.GetClusterImage())
+		}
+		brokers = append(brokers, fmt.Sprintf("%d=%s", broker.Id, image))
+	}
+	sort.Strings(brokers)
+
+	sum := sha256.Sum256([]byte(strings.Join(brokers, ",")))
+	return fmt.Sprintf("%x", sum)[:fingerprintLength]
+}
+
+func jobState(job *batchv1.Job) (v1beta1.SmokeTestState, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return v1beta1.SmokeTestPassed, condition.LastTransitionTime.Format("2006-01-02 15:04:05")
+		case batchv1.JobFailed:
+			return v1beta1.SmokeTestFailed, condition.LastTransitionTime.Format("2006-01-02 15:04:05")
+		}
+	}
+	return v1beta1.SmokeTestRunning, ""
+}
+
+func (r *Reconciler) deleteJob(name string) error {
+	job := &batchv1.Job{}
+	job.Name = name
+	job.Namespace = r.KafkaCluster.Namespace
+	err := r.Client.Delete(context.TODO(), job, client.PropagationPolicy("Background"))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "deleting previous smoke test job failed", "name", name)
+	}
+	return nil
+}
+
+func (r *Reconciler) reconcileTopic() error {
+	desired := r.topic()
+	current := &v1alpha1.KafkaTopic{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, current)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := r.Client.Create(context.TODO(), desired); err != nil {
+			if webhook.IsAdmissionCantConnect(err) || webhook.IsInvalidReplicationFactor(err) {
+				return errorfactory.New(errorfactory.ResourceNotReady{}, err, "smoke test topic admission failed")
+			}
+			return errorfactory.New(errorfactory.APIFailure{}, err, "could not create smoke test topic")
+		}
+	case err != nil:
+		return errorfactory.New(errorfactory.APIFailure{}, err, "failed to lookup smoke test topic")
+	case current.Spec.Partitions < desired.Spec.Partitions:
+		// have a partition led by every broker after scaling up
+		current.Spec.Partitions = desired.Spec.Partitions
+		if err := r.Client.Update(context.TODO(), current); err != nil {
+			return errorfactory.New(errorfactory.APIFailure{}, err, "could not update smoke test topic")
+		}
+	}
+	return nil
+}
+
+// reconcileUser creates the KafkaUser used by the smoke test when the brokers can only be reached through SSL and
+// the operator does not use a custom client certificate
+func (r *Reconciler) reconcileUser() error {
+	if !r.usesSSL() || r.KafkaCluster.Spec.GetClientSSLCertSecretName() != "" {
+		return nil
+	}
+
+	desired := r.user()
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, &v1alpha1.KafkaUser{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := r.Client.Create(context.TODO(), desired); err != nil {
+			return errorfactory.New(errorfactory.APIFailure{}, err, "could not create smoke test user")
+		}
+	case err != nil:
+		return errorfactory.New(errorfactory.APIFailure{}, err, "failed to lookup smoke test user")
+	}
+	return nil
+}
+
+func (r *Reconciler) usesSSL() bool {
+	return r.KafkaCluster.Spec.IsClientSSLSecretPresent() &&
+		util.IsSSLEnabledForInternalCommunication(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
+}
+
+func (r *Reconciler) clientSecretName() string {
+	if secretName := r.KafkaCluster.Spec.GetClientSSLCertSecretName(); secretName != "" {
+		return secretName
+	}
+	return fmt.Sprintf(userNameTemplate, r.KafkaCluster.Name)
+}
@@ -0,0 +1,101 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smoketest
+
+import (
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestClusterFingerprint(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			ClusterImage: "ghcr.io/banzaicloud/kafka:2.13-3.1.0",
+			Brokers: []v1beta1.Broker{
+				{Id: 0},
+				{Id: 1},
+			},
+		},
+	}
+
+	fingerprint := clusterFingerprint(cluster)
+	if len(fingerprint) != fingerprintLength {
+		t.Errorf("expected fingerprint of length %d, got: %q", fingerprintLength, fingerprint)
+	}
+
+	reordered := cluster.DeepCopy()
+	reordered.Spec.Brokers = []v1beta1.Broker{{Id: 1}, {Id: 0}}
+	if got := clusterFingerprint(reordered); got != fingerprint {
+		t.Errorf("expected the fingerprint not to depend on the order of brokers, got: %q and %q", fingerprint, got)
+	}
+
+	scaled := cluster.DeepCopy()
+	scaled.Spec.Brokers = append(scaled.Spec.Brokers, v1beta1.Broker{Id: 2})
+	if got := clusterFingerprint(scaled); got == fingerprint {
+		t.Error("expected the fingerprint to change after scaling")
+	}
+
+	upgraded := cluster.DeepCopy()
+	upgraded.Spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-3.2.0"
+	if got := clusterFingerprint(upgraded); got == fingerprint {
+		t.Error("expected the fingerprint to change after upgrading the image")
+	}
+}
+
+func TestJobState(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		conditions    []batchv1.JobCondition
+		expectedState v1beta1.SmokeTestState
+	}{
+		{
+			testName:      "no conditions",
+			expectedState: v1beta1.SmokeTestRunning,
+		},
+		{
+			testName: "job complete",
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			},
+			expectedState: v1beta1.SmokeTestPassed,
+		},
+		{
+			testName: "job failed",
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue},
+			},
+			expectedState: v1beta1.SmokeTestFailed,
+		},
+		{
+			testName: "job condition not true",
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionFalse},
+			},
+			expectedState: v1beta1.SmokeTestRunning,
+		},
+	}
+
+	for _, test := range testCases {
+		job := &batchv1.Job{Status: batchv1.JobStatus{Conditions: test.conditions}}
+		state, _ := jobState(job)
+		if state != test.expectedState {
+			t.Errorf("%s: expected state: %s, got: %s", test.testName, test.expectedState, state)
+		}
+	}
+}
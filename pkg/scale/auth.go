@@ -0,0 +1,361 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// TokenSource supplies the bearer token sent with the requests to Cruise Control
+type TokenSource interface {
+	// Token returns the token to send. A new token is obtained instead of the cached one if refresh is true, e.g.
+	// after Cruise Control rejected the cached one.
+	Token(ctx context.Context, refresh bool) (string, error)
+}
+
+// TokenSourceFunc fetches a bearer token
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// WithBearerToken makes the client send the token of the given TokenSource in the Authorization header of its
+// requests. A request rejected with 401 Unauthorized is retried once with a refreshed token.
+func WithBearerToken(source TokenSource) ClientOption {
+	return func(c *clientConfig) {
+		c.tokenSource = source
+	}
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+// WithBasicAuth makes the client authenticate its requests with HTTP basic authentication. It is ignored if a bearer
+// token is set by WithBearerToken.
+func WithBasicAuth(username, password string) ClientOption {
+	return func(c *clientConfig) {
+		c.basicAuth = &basicAuth{username: username, password: password}
+	}
+}
+
+type cachedTokenSource struct {
+	mu    sync.Mutex
+	fetch TokenSourceFunc
+	token string
+}
+
+// NewCachedTokenSource returns a TokenSource calling fetch only for the first token and when the token is refreshed
+func NewCachedTokenSource(fetch TokenSourceFunc) TokenSource {
+	return &cachedTokenSource{fetch: fetch}
+}
+
+func (s *cachedTokenSource) Token(ctx context.Context, refresh bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && !refresh {
+		return s.token, nil
+	}
+	token, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	return token, nil
+}
+
+type clientCredentialsTokenSource struct {
+	mu        sync.Mutex
+	cfg       *clientcredentials.Config
+	issuerURL string
+	token     *oauth2.Token
+}
+
+// NewClientCredentialsTokenSource returns a TokenSource obtaining the tokens from an OAuth2 token endpoint with the
+// client credentials grant. A token is reused until it expires or it is refreshed.
+func NewClientCredentialsTokenSource(cfg *clientcredentials.Config) TokenSource {
+	return &clientCredentialsTokenSource{cfg: cfg}
+}
+
+// NewOIDCClientCredentialsTokenSource returns a TokenSource like NewClientCredentialsTokenSource, the token endpoint
+// of which is discovered from the OpenID Connect issuer at issuerURL before the first token is requested
+func NewOIDCClientCredentialsTokenSource(issuerURL string, cfg *clientcredentials.Config) TokenSource {
+	return &clientCredentialsTokenSource{cfg: cfg, issuerURL: issuerURL}
+}
+
+func (s *clientCredentialsTokenSource) Token(ctx context.Context, refresh bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() && !refresh {
+		return s.token.AccessToken, nil
+	}
+	// the token endpoint is reached with the HTTP client in oauth2.HTTPClient of the context if there is one
+	if s.cfg.TokenURL == "" && s.issuerURL != "" {
+		tokenURL, err := discoverTokenURL(ctx, s.issuerURL)
+		if err != nil {
+			return "", err
+		}
+		s.cfg.TokenURL = tokenURL
+	}
+	token, err := s.cfg.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	return token.AccessToken, nil
+}
+
+// discoverTokenURL returns the token endpoint published in the OpenID Connect discovery document of the issuer. The
+// document is requested with the HTTP client in oauth2.HTTPClient of the context, or http.DefaultClient if not set.
+func discoverTokenURL(ctx context.Context, issuerURL string) (string, error) {
+	wellKnown := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return "", err
+	}
+	httpClient := http.DefaultClient
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		httpClient = c
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the OpenID Connect discovery document of %s: %w", issuerURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get the OpenID Connect discovery document of %s: %s", issuerURL, resp.Status)
+	}
+
+	var discovery struct {
+		TokenEndpoint string `json:"token_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return "", fmt.Errorf("failed to decode the OpenID Connect discovery document of %s: %w", issuerURL, err)
+	}
+	if discovery.TokenEndpoint == "" {
+		return "", fmt.Errorf("no token endpoint found in the OpenID Connect discovery document of %s", issuerURL)
+	}
+	return discovery.TokenEndpoint, nil
+}
+
+// bearerTokenTransport sets the Authorization header of the requests and retries them with a refreshed token once
+// they are rejected with 401 Unauthorized. The token source gets authClient in oauth2.HTTPClient of the context, so
+// the token requests go through the same TLS config and proxy as the requests to Cruise Control.
+type bearerTokenTransport struct {
+	base       http.RoundTripper
+	source     TokenSource
+	authClient *http.Client
+}
+
+func (t *bearerTokenTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.roundTrip(r, false)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// the request can not be sent again if its body can not be rewound
+	if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return t.roundTrip(r, true)
+}
+
+func (t *bearerTokenTransport) roundTrip(r *http.Request, refresh bool) (*http.Response, error) {
+	ctx := r.Context()
+	if t.authClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, t.authClient)
+	}
+	token, err := t.source.Token(ctx, refresh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bearer token for Cruise Control: %w", err)
+	}
+	req := r.Clone(r.Context())
+	if refresh && r.GetBody != nil {
+		if req.Body, err = r.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// basicAuthTransport sets the basic authentication credentials of the requests
+type basicAuthTransport struct {
+	base        http.RoundTripper
+	credentials basicAuth
+}
+
+func (t *basicAuthTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	req := r.Clone(r.Context())
+	req.SetBasicAuth(t.credentials.username, t.credentials.password)
+	return t.base.RoundTrip(req)
+}
+
+// ClientOptionsFromKafkaCluster returns the options of the client connecting to the Cruise Control of the
+// KafkaCluster configured by spec.cruiseControlConfig, e.g. its authentication and TLS config
+func ClientOptionsFromKafkaCluster(ctx context.Context, reader client.Reader, cluster *v1beta1.KafkaCluster) ([]ClientOption, error) {
+	if cluster == nil {
+		return nil, nil
+	}
+	opts := []ClientOption{WithMetricLabels(cluster.Namespace, cluster.Name)}
+	if clientTLS := cluster.Spec.CruiseControlConfig.ClientTLS; clientTLS != nil {
+		tlsConfig, err := clientTLSConfig(ctx, reader, cluster.Namespace, clientTLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	if proxy := cluster.Spec.CruiseControlConfig.Proxy; proxy != nil {
+		proxyURL, err := clientProxyURL(ctx, reader, cluster.Namespace, proxy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithProxy(proxyURL))
+	}
+	authOpts, err := authClientOptions(ctx, reader, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, authOpts...), nil
+}
+
+// authClientOptions returns the options authenticating the requests of the client to Cruise Control
+func authClientOptions(ctx context.Context, reader client.Reader, cluster *v1beta1.KafkaCluster) ([]ClientOption, error) {
+	auth := cluster.Spec.CruiseControlConfig.Authentication
+	if auth == nil {
+		return nil, nil
+	}
+
+	switch {
+	case auth.BearerTokenSecretRef != nil:
+		ref := *auth.BearerTokenSecretRef
+		source := NewCachedTokenSource(func(ctx context.Context) (string, error) {
+			return secretValue(ctx, reader, cluster.Namespace, ref)
+		})
+		return []ClientOption{WithBearerToken(source)}, nil
+	case auth.TokenExchange != nil:
+		clientSecret, err := secretValue(ctx, reader, cluster.Namespace, auth.TokenExchange.ClientSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		if auth.TokenExchange.TokenURL == "" && auth.TokenExchange.IssuerURL == "" {
+			return nil, fmt.Errorf("either the token URL or the issuer URL of the token exchange has to be set")
+		}
+		source := NewOIDCClientCredentialsTokenSource(auth.TokenExchange.IssuerURL, &clientcredentials.Config{
+			ClientID:     auth.TokenExchange.ClientID,
+			ClientSecret: clientSecret,
+			TokenURL:     auth.TokenExchange.TokenURL,
+			Scopes:       auth.TokenExchange.Scopes,
+		})
+		return []ClientOption{WithBearerToken(source)}, nil
+	case auth.BasicAuth != nil:
+		password, err := secretValue(ctx, reader, cluster.Namespace, auth.BasicAuth.PasswordSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		return []ClientOption{WithBasicAuth(auth.BasicAuth.Username, password)}, nil
+	}
+	return nil, nil
+}
+
+// clientTLSConfig returns the TLS config of the connections to Cruise Control with the CA bundle and the client
+// certificate read from the Secrets referenced by clientTLS
+func clientTLSConfig(ctx context.Context, reader client.Reader, namespace string, clientTLS *v1beta1.CruiseControlClientTLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if clientTLS.CASecretRef != nil {
+		caBundle, err := secretValue(ctx, reader, namespace, *clientTLS.CASecretRef)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if clientTLS.IncludeSystemCAs {
+			if tlsConfig.RootCAs, err = x509.SystemCertPool(); err != nil {
+				return nil, fmt.Errorf("failed to load the system CAs: %w", err)
+			}
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(caBundle)) {
+			return nil, fmt.Errorf("no CA certificate found in key %q of Secret %s/%s", clientTLS.CASecretRef.Key,
+				namespace, clientTLS.CASecretRef.Name)
+		}
+	}
+	if clientTLS.ClientCertSecretName != "" {
+		secret := &corev1.Secret{}
+		if err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: clientTLS.ClientCertSecretName}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get Secret %s/%s: %w", namespace, clientTLS.ClientCertSecretName, err)
+		}
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate of Secret %s/%s: %w", namespace,
+				clientTLS.ClientCertSecretName, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// clientProxyURL returns the URL of the proxy with the credentials read from the Secret referenced by proxy
+func clientProxyURL(ctx context.Context, reader client.Reader, namespace string, proxy *v1beta1.CruiseControlClientProxy) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", proxy.URL, err)
+	}
+	if proxy.CredentialsSecretName == "" {
+		return proxyURL, nil
+	}
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: proxy.CredentialsSecretName}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s/%s: %w", namespace, proxy.CredentialsSecretName, err)
+	}
+	proxyURL.User = url.UserPassword(string(secret.Data[corev1.BasicAuthUsernameKey]),
+		string(secret.Data[corev1.BasicAuthPasswordKey]))
+	return proxyURL, nil
+}
+
+// NewCruiseControlScalerFromKafkaCluster returns a CruiseControlScaler connecting to the Cruise Control of the
+// KafkaCluster with the client options configured by spec.cruiseControlConfig
+func NewCruiseControlScalerFromKafkaCluster(ctx context.Context, reader client.Reader, cluster *v1beta1.KafkaCluster) (CruiseControlScaler, error) {
+	opts, err := ClientOptionsFromKafkaCluster(ctx, reader, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return NewCruiseControlScaler(ctx, CruiseControlURLFromKafkaCluster(cluster), opts...)
+}
+
+func secretValue(ctx context.Context, reader client.Reader, namespace string, ref corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", fmt.Errorf("failed to get Secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok || len(value) == 0 {
+		return "", fmt.Errorf("key %q of Secret %s/%s is empty", ref.Key, namespace, ref.Name)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,481 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newAuthenticatingCruiseControlServer(token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errorMessage": "unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+}
+
+func TestBearerTokenRefreshedOnUnauthorized(t *testing.T) {
+	server := newAuthenticatingCruiseControlServer("new")
+	defer server.Close()
+
+	tokens := []string{"expired", "new"}
+	fetched := 0
+	source := NewCachedTokenSource(func(context.Context) (string, error) {
+		token := tokens[fetched]
+		fetched++
+		return token, nil
+	})
+
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL, WithBearerToken(source))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if fetched != 2 {
+		t.Errorf("expected the token to be fetched once more after it was rejected, got %d fetches", fetched)
+	}
+}
+
+func TestClientOptionsFromKafkaCluster(t *testing.T) {
+	server := newAuthenticatingCruiseControlServer("secret-token")
+	defer server.Close()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				CruiseControlEndpoint: server.URL,
+				Authentication: &v1beta1.CruiseControlAuthentication{
+					BearerTokenSecretRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "cc-token"},
+						Key:                  "token",
+					},
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-token", Namespace: "kafka"},
+		Data:       map[string][]byte{"token": []byte("secret-token")},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+	opts, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Errorf("expected the token of the Secret to be accepted, got: %s", err)
+	}
+
+	cluster.Spec.CruiseControlConfig.Authentication.BearerTokenSecretRef.Key = "missing"
+	opts, _ = ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster)
+	cruisecontrol, _ = NewCruiseControlClient(context.TODO(), server.URL, opts...)
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err == nil {
+		t.Error("expected error for missing Secret key")
+	}
+}
+
+func TestOIDCTokenExchangeFromKafkaCluster(t *testing.T) {
+	server := newAuthenticatingCruiseControlServer("oidc-token")
+	defer server.Close()
+
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/realms/kafka/.well-known/openid-configuration":
+			_, _ = w.Write([]byte(`{"issuer": "` + issuer.URL + `/realms/kafka", "token_endpoint": "` + issuer.URL + `/realms/kafka/token"}`))
+		case "/realms/kafka/token":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "koperator" || pass != "client-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token": "oidc-token", "token_type": "Bearer", "expires_in": 300}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer issuer.Close()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				CruiseControlEndpoint: server.URL,
+				Authentication: &v1beta1.CruiseControlAuthentication{
+					TokenExchange: &v1beta1.CruiseControlTokenExchange{
+						IssuerURL: issuer.URL + "/realms/kafka",
+						ClientID:  "koperator",
+						ClientSecretRef: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "cc-oidc"},
+							Key:                  "clientSecret",
+						},
+					},
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-oidc", Namespace: "kafka"},
+		Data:       map[string][]byte{"clientSecret": []byte("client-secret")},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+	opts, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Errorf("expected the token of the discovered token endpoint to be accepted, got: %s", err)
+	}
+
+	cluster.Spec.CruiseControlConfig.Authentication.TokenExchange.IssuerURL = ""
+	if _, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster); err == nil {
+		t.Error("expected error for token exchange without token and issuer URL")
+	}
+}
+
+func TestOIDCTokenExchangeWithPrivateCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if r.Header.Get("Authorization") != "Bearer private-ca-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errorMessage": "unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	var issuer *httptest.Server
+	issuer = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_, _ = w.Write([]byte(`{"issuer": "` + issuer.URL + `", "token_endpoint": "` + issuer.URL + `/token"}`))
+		case "/token":
+			_, _ = w.Write([]byte(`{"access_token": "private-ca-token", "token_type": "Bearer", "expires_in": 300}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer issuer.Close()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				CruiseControlEndpoint: server.URL,
+				ClientTLS: &v1beta1.CruiseControlClientTLS{
+					CASecretRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "cc-ca"},
+						Key:                  "ca.crt",
+					},
+				},
+				Authentication: &v1beta1.CruiseControlAuthentication{
+					TokenExchange: &v1beta1.CruiseControlTokenExchange{
+						IssuerURL: issuer.URL,
+						ClientID:  "koperator",
+						ClientSecretRef: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "cc-oidc"},
+							Key:                  "clientSecret",
+						},
+					},
+				},
+			},
+		},
+	}
+	// the servers of httptest share their certificate, so the CA bundle makes both of them trusted
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-ca", Namespace: "kafka"},
+		Data: map[string][]byte{
+			"ca.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: issuer.Certificate().Raw}),
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-oidc", Namespace: "kafka"},
+		Data:       map[string][]byte{"clientSecret": []byte("client-secret")},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(caSecret, secret).Build()
+
+	opts, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Errorf("expected the issuer to be reached with the CA bundle of the client TLS config, got: %s", err)
+	}
+}
+
+func TestBasicAuthFromKafkaCluster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if username, password, ok := r.BasicAuth(); !ok || username != "operator" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errorMessage": "unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				Authentication: &v1beta1.CruiseControlAuthentication{
+					BasicAuth: &v1beta1.CruiseControlBasicAuth{
+						Username: "operator",
+						PasswordSecretRef: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "cc-credentials"},
+							Key:                  "password",
+						},
+					},
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-credentials", Namespace: "kafka"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+	opts, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Errorf("expected the credentials of the Secret to be accepted, got: %s", err)
+	}
+
+	cluster.Spec.CruiseControlConfig.Authentication.BasicAuth.PasswordSecretRef.Key = "missing"
+	if _, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster); err == nil {
+		t.Error("expected error for missing Secret key")
+	}
+}
+
+func TestClientTLSFromKafkaCluster(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				ClientTLS: &v1beta1.CruiseControlClientTLS{
+					CASecretRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "cc-ca"},
+						Key:                  "ca.crt",
+					},
+				},
+			},
+		},
+	}
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-ca", Namespace: "kafka"},
+		Data: map[string][]byte{
+			"ca.crt":  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+			"invalid": []byte("not a certificate"),
+		},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(caSecret).Build()
+
+	// the server is not trusted without the CA bundle
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err == nil {
+		t.Error("expected error for the server certificate issued by an unknown CA")
+	}
+
+	opts, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cruisecontrol, err = NewCruiseControlClient(context.TODO(), server.URL, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Errorf("expected the server certificate to be verified with the CA bundle, got: %s", err)
+	}
+
+	cluster.Spec.CruiseControlConfig.ClientTLS.CASecretRef.Key = "invalid"
+	if _, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster); err == nil {
+		t.Error("expected error for the CA bundle without certificates")
+	}
+	cluster.Spec.CruiseControlConfig.ClientTLS.CASecretRef = nil
+	cluster.Spec.CruiseControlConfig.ClientTLS.ClientCertSecretName = "missing"
+	if _, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster); err == nil {
+		t.Error("expected error for missing client certificate Secret")
+	}
+}
+
+func TestClientProxyFromKafkaCluster(t *testing.T) {
+	var proxied []*http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer proxy.Close()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				CruiseControlEndpoint: "cruisecontrol.example.com:8090",
+				Proxy: &v1beta1.CruiseControlClientProxy{
+					URL:                   proxy.URL,
+					CredentialsSecretName: "egress-proxy",
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "egress-proxy", Namespace: "kafka"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("koperator"),
+			corev1.BasicAuthPasswordKey: []byte("proxy-pass"),
+		},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+	opts, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), CruiseControlURLFromKafkaCluster(cluster), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(proxied) != 1 {
+		t.Fatalf("expected the request to be sent through the proxy, got %d requests", len(proxied))
+	}
+	if host := proxied[0].URL.Host; host != "cruisecontrol.example.com:8090" {
+		t.Errorf("unexpected target of the proxied request: %s", host)
+	}
+	if auth := proxied[0].Header.Get("Proxy-Authorization"); auth != "Basic a29wZXJhdG9yOnByb3h5LXBhc3M=" {
+		t.Errorf("unexpected Proxy-Authorization header: %s", auth)
+	}
+}
+
+func TestClientProxyWithTokenExchange(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if r.URL.Host == "idp.example.com" {
+			_, _ = w.Write([]byte(`{"access_token": "proxied-token", "token_type": "Bearer", "expires_in": 300}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer proxied-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errorMessage": "unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer proxy.Close()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				CruiseControlEndpoint: "cruisecontrol.example.com:8090",
+				Proxy:                 &v1beta1.CruiseControlClientProxy{URL: proxy.URL},
+				Authentication: &v1beta1.CruiseControlAuthentication{
+					TokenExchange: &v1beta1.CruiseControlTokenExchange{
+						TokenURL: "http://idp.example.com/token",
+						ClientID: "koperator",
+						ClientSecretRef: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "cc-oidc"},
+							Key:                  "clientSecret",
+						},
+					},
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-oidc", Namespace: "kafka"},
+		Data:       map[string][]byte{"clientSecret": []byte("client-secret")},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+	opts, err := ClientOptionsFromKafkaCluster(context.TODO(), reader, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), CruiseControlURLFromKafkaCluster(cluster), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(proxied) != 2 || proxied[0] != "idp.example.com" || proxied[1] != "cruisecontrol.example.com:8090" {
+		t.Errorf("expected the token and the Cruise Control requests to be sent through the proxy, got: %v", proxied)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// builtInTaskIDPrefix is the prefix of the IDs of the tasks of the built-in rebalancer, the IDs hold the start time
+// of the tasks in Unix milliseconds
+const builtInTaskIDPrefix = "builtin-"
+
+// ErrNotSupportedByBuiltInRebalancer is returned by the operations of the built-in rebalancer which need Cruise Control
+var ErrNotSupportedByBuiltInRebalancer = errors.New("operation is not supported by the built-in rebalancer")
+
+// NewScalerFromKafkaCluster returns the built-in rebalancer of the cluster when it is selected by spec.rebalancer and
+// a CruiseControlScaler connecting to the Cruise Control of the cluster otherwise
+func NewScalerFromKafkaCluster(ctx context.Context, c client.Client, cluster *v1beta1.KafkaCluster) (CruiseControlScaler, error) {
+	if !cluster.Spec.UsesBuiltInRebalancer() {
+		return NewCruiseControlScalerFromKafkaCluster(ctx, c, cluster)
+	}
+	log := logr.FromContextOrDiscard(ctx).WithName("BuiltInRebalancer")
+	return NewBuiltInRebalancer(log, func() (kafkaclient.KafkaClient, func(), error) {
+		return kafkaclient.NewFromCluster(c, cluster)
+	}), nil
+}
+
+// NewBuiltInRebalancer returns a CruiseControlScaler reassigning the partition replicas via the admin API of Kafka
+// instead of Cruise Control. Only adding and removing brokers are supported, the disk rebalances are reported done
+// right away and the other operations return ErrNotSupportedByBuiltInRebalancer. A new Kafka client is opened by
+// newKafkaClient for every operation.
+func NewBuiltInRebalancer(log logr.Logger, newKafkaClient func() (kafkaclient.KafkaClient, func(), error)) CruiseControlScaler {
+	return &builtInRebalancer{
+		log:            log,
+		newKafkaClient: newKafkaClient,
+	}
+}
+
+type builtInRebalancer struct {
+	log            logr.Logger
+	newKafkaClient func() (kafkaclient.KafkaClient, func(), error)
+}
+
+// withKafkaClient calls fn with a new Kafka client unless the context is done already, the admin API of Kafka is not
+// context-aware so the calls of fn are bound by the operation timeout of the client only
+func (b *builtInRebalancer) withKafkaClient(ctx context.Context, fn func(kafkaclient.KafkaClient) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	kClient, closeClient, err := b.newKafkaClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+	return fn(kClient)
+}
+
+func (b *builtInRebalancer) reassignmentsInProgress(ctx context.Context) (bool, error) {
+	var inProgress bool
+	err := b.withKafkaClient(ctx, func(kClient kafkaclient.KafkaClient) error {
+		var err error
+		inProgress, err = kClient.PartitionReassignmentsInProgress()
+		return err
+	})
+	return inProgress, err
+}
+
+// Status returns the executor as not ready while partitions are being reassigned, there is nothing to monitor or
+// analyze for the built-in rebalancer so the other components are always ready
+func (b *builtInRebalancer) Status(ctx context.Context) CruiseControlStatus {
+	inProgress, err := b.reassignmentsInProgress(ctx)
+	if err != nil {
+		b.log.Error(err, "failed to get the partition reassignments in progress")
+		return CruiseControlStatus{}
+	}
+	return CruiseControlStatus{
+		MonitorReady:       true,
+		ExecutorReady:      !inProgress,
+		AnalyzerReady:      true,
+		ProposalReady:      true,
+		GoalsReady:         true,
+		MonitoringCoverage: 100,
+	}
+}
+
+// IsReady returns true if the brokers are reachable
+func (b *builtInRebalancer) IsReady(ctx context.Context) bool {
+	return b.Status(ctx).IsReady()
+}
+
+// IsUp returns true if the brokers are reachable
+func (b *builtInRebalancer) IsUp(ctx context.Context) bool {
+	return b.withKafkaClient(ctx, func(kafkaclient.KafkaClient) error { return nil }) == nil
+}
+
+// GetUserTasks returns the given tasks of the built-in rebalancer as in execution while partitions are being
+// reassigned and as completed afterwards. Only one task runs at a time since the executor is not ready while the
+// partitions of a task are being reassigned.
+func (b *builtInRebalancer) GetUserTasks(ctx context.Context, taskIDs ...string) ([]*Result, error) {
+	inProgress, err := b.reassignmentsInProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state := v1beta1.CruiseControlTaskCompleted
+	if inProgress {
+		state = v1beta1.CruiseControlTaskInExecution
+	}
+
+	results := make([]*Result, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		startedAt, ok := builtInTaskStartTime(taskID)
+		if !ok {
+			continue
+		}
+		results = append(results, &Result{
+			TaskID:    taskID,
+			StartedAt: startedAt.UTC().Format(time.RFC3339),
+			State:     state,
+		})
+	}
+	return results, nil
+}
+
+// ListUserTasks returns the tasks of the built-in rebalancer selected by the filter, only the tasks with the given IDs
+// are known by the built-in rebalancer and the endpoints are ignored
+func (b *builtInRebalancer) ListUserTasks(ctx context.Context, filter UserTaskFilter) ([]*Result, error) {
+	results, err := b.GetUserTasks(ctx, filter.TaskIDs...)
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]*Result, 0, len(results))
+	for _, result := range results {
+		startedAt, _ := builtInTaskStartTime(result.TaskID)
+		if filter.Matches(result.TaskID, result.State, startedAt) {
+			selected = append(selected, result)
+		}
+	}
+	return selected, nil
+}
+
+// AddBrokers moves replicas from the brokers holding the most replicas to the provided brokers, the options of Cruise
+// Control are ignored
+func (b *builtInRebalancer) AddBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	if len(brokerIDs) == 0 {
+		return nil, errors.New("no broker id(s) provided for add brokers request")
+	}
+	brokersToAdd, err := brokerIDsFromStringSlice(brokerIDs)
+	if err != nil {
+		return nil, err
+	}
+	return b.rebalance(ctx, brokersToAdd, nil)
+}
+
+// RemoveBrokers moves the replicas off the provided brokers to the brokers holding the fewest replicas, the options
+// of Cruise Control are ignored
+func (b *builtInRebalancer) RemoveBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	if len(brokerIDs) == 0 {
+		return nil, errors.New("no broker id(s) provided for remove brokers request")
+	}
+	brokersToRemove, err := brokerIDsFromStringSlice(brokerIDs)
+	if err != nil {
+		return nil, err
+	}
+	return b.rebalance(ctx, nil, brokersToRemove)
+}
+
+func (b *builtInRebalancer) rebalance(ctx context.Context, addedBrokers, removedBrokers []int32) (*Result, error) {
+	startedAt := time.Now()
+	result := &Result{
+		TaskID:    builtInTaskIDPrefix + strconv.FormatInt(startedAt.UnixMilli(), 10),
+		StartedAt: startedAt.UTC().Format(time.RFC3339),
+	}
+
+	var reassigned int
+	err := b.withKafkaClient(ctx, func(kClient kafkaclient.KafkaClient) error {
+		var err error
+		reassigned, err = kClient.RebalanceBrokers(addedBrokers, removedBrokers)
+		return err
+	})
+	if err != nil {
+		result.State = v1beta1.CruiseControlTaskCompletedWithError
+		result.Err = fmt.Sprintf("%v", err)
+		return result, err
+	}
+
+	b.log.Info("partitions reassigned", "added brokers", addedBrokers, "removed brokers", removedBrokers,
+		"partitions", reassigned, "taskId", result.TaskID)
+	result.State = v1beta1.CruiseControlTaskInExecution
+	if reassigned == 0 {
+		result.State = v1beta1.CruiseControlTaskCompleted
+	}
+	return result, nil
+}
+
+// RebalanceDisks is reported done right away, the replicas are not moved between the disks of the brokers by the
+// built-in rebalancer, the new partitions are placed on the new disks by Kafka
+func (b *builtInRebalancer) RebalanceDisks(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	startedAt := time.Now()
+	b.log.Info("disks are not rebalanced by the built-in rebalancer", "brokers", brokerIDs)
+	return &Result{
+		TaskID:    builtInTaskIDPrefix + strconv.FormatInt(startedAt.UnixMilli(), 10),
+		StartedAt: startedAt.UTC().Format(time.RFC3339),
+		State:     v1beta1.CruiseControlTaskCompleted,
+	}, nil
+}
+
+// BrokersWithState returns the IDs of the brokers reachable via the admin API of Kafka as alive and new brokers,
+// the other states are known by Cruise Control only
+func (b *builtInRebalancer) BrokersWithState(ctx context.Context, states ...KafkaBrokerState) ([]string, error) {
+	statesMap := kafkaBrokerStatesToMap(states...)
+	_, alive := statesMap[KafkaBrokerAlive]
+	_, isNew := statesMap[KafkaBrokerNew]
+	if !alive && !isNew {
+		return []string{}, nil
+	}
+
+	var brokerIDs []string
+	err := b.withKafkaClient(ctx, func(kClient kafkaclient.KafkaClient) error {
+		for id := range kClient.Brokers() {
+			brokerIDs = append(brokerIDs, strconv.Itoa(int(id)))
+		}
+		return nil
+	})
+	sort.Strings(brokerIDs)
+	return brokerIDs, err
+}
+
+func (b *builtInRebalancer) PartitionReplicasByBroker(ctx context.Context) (map[string]int32, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) BrokerWithLeastPartitionReplicas(ctx context.Context, mode BrokerSelectionMode) (string, error) {
+	return "", ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) LogDirsByBroker(ctx context.Context) (map[string]map[LogDirState][]LogDir, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) DiskUsageByBroker(ctx context.Context) (map[string]DiskUsage, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) BrokerLoads(ctx context.Context) (map[string]BrokerLoad, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) GoalViolations(ctx context.Context) ([]GoalViolation, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) RebalanceWithGoals(ctx context.Context, excludedBrokerIDs []string, topics string, goals ...string) (*Result, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*Result, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) UpdateTopicReplicationFactor(ctx context.Context, topic string, replicationFactor int32) (*Result, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) StopExecution(ctx context.Context) error {
+	return ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) PauseSampling(ctx context.Context, reason string) error {
+	return ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) ResumeSampling(ctx context.Context, reason string) error {
+	return ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) IntraBrokerRebalance(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) UpdateExecutorConcurrency(ctx context.Context, concurrency ExecutorConcurrency) error {
+	return ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) UpdateSelfHealing(ctx context.Context, enabled, disabled []string) error {
+	return ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) Rightsize(ctx context.Context, opts RightsizeOptions) (*RightsizeResult, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) FixOfflineReplicas(ctx context.Context) (*Result, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) RemoveDisks(ctx context.Context, brokerID string, logDirs []string) (*Result, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) GetProposals(ctx context.Context, goals ...string) (*ProposalSummary, error) {
+	return nil, ErrNotSupportedByBuiltInRebalancer
+}
+
+func (b *builtInRebalancer) WatchTask(ctx context.Context, taskID string) <-chan TaskUpdate {
+	return PollTask(ctx, b, taskID, DefaultPollConfig)
+}
+
+// builtInTaskStartTime returns the start time of the task of the built-in rebalancer with the given ID
+func builtInTaskStartTime(taskID string) (time.Time, bool) {
+	if !strings.HasPrefix(taskID, builtInTaskIDPrefix) {
+		return time.Time{}, false
+	}
+	millis, err := strconv.ParseInt(strings.TrimPrefix(taskID, builtInTaskIDPrefix), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(millis), true
+}
@@ -0,0 +1,99 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+func newMockBuiltInRebalancer() CruiseControlScaler {
+	return NewBuiltInRebalancer(logr.Discard(), func() (kafkaclient.KafkaClient, func(), error) {
+		return kafkaclient.NewMockFromCluster(nil, nil)
+	})
+}
+
+func TestBuiltInRebalancerAddBrokers(t *testing.T) {
+	rebalancer := newMockBuiltInRebalancer()
+
+	if !rebalancer.IsUp(context.TODO()) || !rebalancer.IsReady(context.TODO()) || rebalancer.Status(context.TODO()).InExecution() {
+		t.Fatal("expected the built-in rebalancer to be up, ready and not in execution")
+	}
+
+	result, err := rebalancer.AddBrokers(context.TODO(), OperationOptions{}, "0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// there are no partitions to reassign
+	if result.State != v1beta1.CruiseControlTaskCompleted {
+		t.Errorf("expected completed task, got: %s", result.State)
+	}
+
+	tasks, err := rebalancer.GetUserTasks(context.TODO(), result.TaskID, "cruise-control-task-id")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(tasks) != 1 || tasks[0].TaskID != result.TaskID || tasks[0].State != v1beta1.CruiseControlTaskCompleted {
+		t.Errorf("expected the completed task of the built-in rebalancer only, got: %+v", tasks)
+	}
+
+	if _, err := rebalancer.AddBrokers(context.TODO(), OperationOptions{}, "3"); err == nil {
+		t.Error("expected error for a broker not in the cluster")
+	}
+}
+
+func TestBuiltInRebalancerBrokersWithState(t *testing.T) {
+	rebalancer := newMockBuiltInRebalancer()
+
+	brokers, err := rebalancer.BrokersWithState(context.TODO(), KafkaBrokerAlive, KafkaBrokerNew)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(brokers, []string{"0"}) {
+		t.Errorf("expected broker 0 to be alive, got: %v", brokers)
+	}
+
+	brokers, err = rebalancer.BrokersWithState(context.TODO(), KafkaBrokerDemoted)
+	if err != nil || len(brokers) != 0 {
+		t.Errorf("expected no demoted brokers, got: %v, %v", brokers, err)
+	}
+}
+
+func TestBuiltInRebalancerUnsupportedOperations(t *testing.T) {
+	rebalancer := newMockBuiltInRebalancer()
+
+	if _, err := rebalancer.DemoteBrokers(context.TODO(), "0"); !errors.Is(err, ErrNotSupportedByBuiltInRebalancer) {
+		t.Errorf("expected demoting brokers not to be supported, got: %v", err)
+	}
+	if _, err := rebalancer.BrokerLoads(context.TODO()); !errors.Is(err, ErrNotSupportedByBuiltInRebalancer) {
+		t.Errorf("expected broker loads not to be supported, got: %v", err)
+	}
+}
+
+func TestBuiltInTaskStartTime(t *testing.T) {
+	if startedAt, ok := builtInTaskStartTime("builtin-1646128800000"); !ok || startedAt.UTC().Format("2006-01-02 15:04:05") != "2022-03-01 10:00:00" {
+		t.Errorf("expected start time of the task, got: %v, %t", startedAt, ok)
+	}
+	if _, ok := builtInTaskStartTime("5f1a3c2e-cruise-control"); ok {
+		t.Error("expected no start time for a Cruise Control task")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+)
+
+// DefaultClusterStateCacheTTL is the time the Kafka cluster state and load returned by Cruise Control are reused by
+// the CruiseControlScaler before requesting them again
+const DefaultClusterStateCacheTTL = 10 * time.Second
+
+// clusterStateCache holds the last Kafka cluster state and load responses of Cruise Control
+type clusterStateCache struct {
+	mu  sync.Mutex
+	ttl time.Duration
+	now func() time.Time
+
+	state   *api.KafkaClusterStateResponse
+	stateAt time.Time
+	load    *api.KafkaClusterLoadResponse
+	loadAt  time.Time
+	// diskLoad is the load of the log dirs, it is requested only by the operations needing it
+	diskLoad   *KafkaClusterDiskLoadResponse
+	diskLoadAt time.Time
+}
+
+func newClusterStateCache(ttl time.Duration) *clusterStateCache {
+	return &clusterStateCache{
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// kafkaClusterState returns the Kafka cluster state from Cruise Control, the last response is reused within the TTL
+func (cc *cruiseControlScaler) kafkaClusterState(ctx context.Context) (*api.KafkaClusterStateResponse, error) {
+	cc.cache.mu.Lock()
+	defer cc.cache.mu.Unlock()
+
+	now := cc.cache.now()
+	if cc.cache.state != nil && now.Sub(cc.cache.stateAt) < cc.cache.ttl {
+		return cc.cache.state, nil
+	}
+	resp, err := cc.client.KafkaClusterState(ctx, api.KafkaClusterStateRequestWithDefaults())
+	if err != nil {
+		return nil, err
+	}
+	cc.cache.state, cc.cache.stateAt = resp, now
+	return resp, nil
+}
+
+// kafkaClusterLoad returns the Kafka cluster load from Cruise Control, the last response is reused within the TTL
+func (cc *cruiseControlScaler) kafkaClusterLoad(ctx context.Context) (*api.KafkaClusterLoadResponse, error) {
+	cc.cache.mu.Lock()
+	defer cc.cache.mu.Unlock()
+
+	now := cc.cache.now()
+	if cc.cache.load != nil && now.Sub(cc.cache.loadAt) < cc.cache.ttl {
+		return cc.cache.load, nil
+	}
+	resp, err := cc.client.KafkaClusterLoad(ctx, api.KafkaClusterLoadRequestWithDefaults())
+	if err != nil {
+		return nil, err
+	}
+	cc.cache.load, cc.cache.loadAt = resp, now
+	return resp, nil
+}
+
+// kafkaClusterDiskLoad returns the load of the log dirs of the brokers from Cruise Control, the last response is
+// reused within the TTL
+func (cc *cruiseControlScaler) kafkaClusterDiskLoad(ctx context.Context) (*KafkaClusterDiskLoadResponse, error) {
+	cc.cache.mu.Lock()
+	defer cc.cache.mu.Unlock()
+
+	now := cc.cache.now()
+	if cc.cache.diskLoad != nil && now.Sub(cc.cache.diskLoadAt) < cc.cache.ttl {
+		return cc.cache.diskLoad, nil
+	}
+	resp, err := cc.client.KafkaClusterDiskLoad(ctx, api.KafkaClusterLoadRequestWithDefaults())
+	if err != nil {
+		return nil, err
+	}
+	cc.cache.diskLoad, cc.cache.diskLoadAt = resp, now
+	return resp, nil
+}
+
+// invalidateCache drops the cached Kafka cluster state and load, it is called by the operations changing the
+// placement of the partition replicas
+func (cc *cruiseControlScaler) invalidateCache() {
+	cc.cache.mu.Lock()
+	defer cc.cache.mu.Unlock()
+
+	cc.cache.state = nil
+	cc.cache.load = nil
+	cc.cache.diskLoad = nil
+}
@@ -0,0 +1,393 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/client"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+)
+
+const (
+	// DefaultRequestTimeout is the time limit of the requests sent to Cruise Control if no other is set
+	DefaultRequestTimeout = client.DefaultRequestTimeout
+	// DefaultUserAgent is the User-Agent header of the requests sent to Cruise Control if no other is set
+	DefaultUserAgent = "koperator"
+)
+
+// ClientOption configures the HTTP client used by the CruiseControlScaler to send requests to Cruise Control
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	transport      http.RoundTripper
+	requestTimeout time.Duration
+	userAgent      string
+	tlsConfig      *tls.Config
+	proxyURL       *url.URL
+	tokenSource    TokenSource
+	basicAuth      *basicAuth
+	retry          RetryConfig
+	circuitBreaker CircuitBreakerConfig
+	metricLabels   prometheus.Labels
+}
+
+// WithTransport makes the client send its requests with the given http.RoundTripper, e.g. to go through a proxy, to
+// use mutual TLS or to sign the requests. http.DefaultTransport is used if it is not set.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *clientConfig) {
+		c.transport = transport
+	}
+}
+
+// WithTLSConfig makes the client connect to Cruise Control with the given TLS config, e.g. to verify its certificate
+// with a private CA or to present a client certificate. It is applied to a copy of the transport, which has to be an
+// *http.Transport; other transports set by WithTransport have to be configured on their own. The token requests of
+// the authentication set by WithBearerToken are sent with the same TLS config.
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(c *clientConfig) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithProxy makes the client send its requests through the HTTP(S) proxy at proxyURL, the user info of the URL is
+// used to authenticate to the proxy. Like WithTLSConfig, it is applied to a copy of the transport, which has to be an
+// *http.Transport, and it is used by the token requests as well.
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(c *clientConfig) {
+		c.proxyURL = proxyURL
+	}
+}
+
+// WithRequestTimeout sets the time limit of each request sent to Cruise Control
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.requestTimeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests sent to Cruise Control
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *clientConfig) {
+		c.userAgent = userAgent
+	}
+}
+
+// httpClient is a CruiseControlClient sending its requests to the Cruise Control REST API over HTTP
+type httpClient struct {
+	log    logr.Logger
+	client *http.Client
+	url    *url.URL
+
+	requestTimeout time.Duration
+	userAgent      string
+	retry          RetryConfig
+	breaker        *circuitBreaker
+	metricLabels   prometheus.Labels
+}
+
+// NewCruiseControlClient returns a CruiseControlClient sending its requests to the Cruise Control server at serverURL.
+// The requests are bound to the context given to each call and to the request timeout of the client. Requests failing
+// with a transient error are retried, and a circuit breaker stops sending them while Cruise Control keeps failing.
+func NewCruiseControlClient(ctx context.Context, serverURL string, opts ...ClientOption) (CruiseControlClient, error) {
+	cfg := &clientConfig{
+		transport:      http.DefaultTransport,
+		requestTimeout: DefaultRequestTimeout,
+		userAgent:      DefaultUserAgent,
+		retry: RetryConfig{
+			MaxRetries:     DefaultMaxRetries,
+			InitialBackoff: DefaultInitialBackoff,
+			MaxBackoff:     DefaultMaxBackoff,
+		},
+		circuitBreaker: CircuitBreakerConfig{
+			FailureThreshold: DefaultFailureThreshold,
+			OpenTimeout:      DefaultOpenTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.transport == nil {
+		cfg.transport = http.DefaultTransport
+	}
+	if cfg.requestTimeout <= 0 {
+		cfg.requestTimeout = DefaultRequestTimeout
+	}
+	if cfg.retry.InitialBackoff <= 0 {
+		cfg.retry.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.retry.MaxBackoff < cfg.retry.InitialBackoff {
+		cfg.retry.MaxBackoff = cfg.retry.InitialBackoff
+	}
+	if cfg.tlsConfig != nil || cfg.proxyURL != nil {
+		transport, ok := cfg.transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("the TLS config and the proxy can not be applied to transport of type %T", cfg.transport)
+		}
+		transport = transport.Clone()
+		if cfg.tlsConfig != nil {
+			transport.TLSClientConfig = cfg.tlsConfig
+		}
+		if cfg.proxyURL != nil {
+			transport.Proxy = http.ProxyURL(cfg.proxyURL)
+		}
+		cfg.transport = transport
+	}
+	switch {
+	case cfg.tokenSource != nil:
+		cfg.transport = &bearerTokenTransport{
+			base:       cfg.transport,
+			source:     cfg.tokenSource,
+			authClient: &http.Client{Transport: cfg.transport, Timeout: cfg.requestTimeout},
+		}
+	case cfg.basicAuth != nil:
+		cfg.transport = &basicAuthTransport{base: cfg.transport, credentials: *cfg.basicAuth}
+	}
+
+	if serverURL == "" {
+		serverURL = client.DefaultServerURL
+	}
+	// the server URLs without scheme are reached over https when the client is configured with TLS
+	if !strings.Contains(serverURL, "://") {
+		scheme := "http"
+		if cfg.tlsConfig != nil {
+			scheme = "https"
+		}
+		serverURL = fmt.Sprintf("%s://%s", scheme, serverURL)
+	}
+	if !strings.HasSuffix(serverURL, "/") {
+		serverURL += "/"
+	}
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid Cruise Control server URL %q: missing host", serverURL)
+	}
+
+	return &httpClient{
+		log:            logr.FromContextOrDiscard(ctx),
+		client:         &http.Client{Transport: cfg.transport},
+		url:            u,
+		requestTimeout: cfg.requestTimeout,
+		userAgent:      cfg.userAgent,
+		retry:          cfg.retry,
+		breaker:        circuitBreakerFor(u.String(), cfg.circuitBreaker),
+		metricLabels:   cfg.metricLabels,
+	}, nil
+}
+
+func (c *httpClient) request(ctx context.Context, req interface{}, resp types.APIResponse, endpoint types.APIEndpoint, method string) (err error) {
+	defer func(start time.Time) {
+		c.observeRequest(endpoint, start, err)
+	}(time.Now())
+
+	r, err := client.MarshalRequest(req)
+	if err != nil {
+		return err
+	}
+	if r.URL == nil {
+		r.URL = &url.URL{}
+	}
+	r.URL = c.url.ResolveReference(r.URL)
+	r.URL.Path = path.Join(r.URL.Path, endpoint.Path())
+	query := r.URL.Query()
+	query.Set("json", "true")
+	r.URL.RawQuery = query.Encode()
+
+	r.Method = method
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set(client.HTTPHeaderUserAgent, c.userAgent)
+	r.Header.Set(client.HTTPHeaderAccept, client.MIMETypeJSON)
+	r.Header.Set(client.HTTPHeaderContentType, fmt.Sprintf("%s; charset=%s", client.MIMETypeJSON, client.ChartSetUTF8))
+
+	err = c.doWithRetry(ctx, func() error {
+		return c.send(ctx, r, resp)
+	})
+	var pending *pendingReviewError
+	if !errors.As(err, &pending) {
+		return err
+	}
+
+	// two-step verification is enabled in Cruise Control, so the request is approved and then submitted again
+	c.log.Info("approving request pending review in Cruise Control", "endpoint", endpoint, "reviewID", pending.reviewID)
+	if err = c.approveReview(ctx, pending.reviewID); err != nil {
+		return fmt.Errorf("approving review %d of request to %s failed: %w", pending.reviewID, endpoint, err)
+	}
+	r = withReviewID(r, pending.reviewID)
+	return c.doWithRetry(ctx, func() error {
+		return c.send(ctx, r, resp)
+	})
+}
+
+// send sends the request once. Errors which might not occur if the request is sent again are returned as a
+// *transientError.
+func (c *httpClient) send(ctx context.Context, r *http.Request, resp types.APIResponse) error {
+	ctx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+	defer cancel()
+
+	c.log.V(1).Info("sending request", "url", r.URL, "method", r.Method)
+	httpResp, err := c.client.Do(r.Clone(ctx))
+	if err != nil {
+		// a request which timed out might have been processed, so only those which are safe to repeat are retried
+		var netErr net.Error
+		timedOut := errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+		return &transientError{err: err, retryable: !timedOut || r.Method == http.MethodGet}
+	}
+	defer func(body io.ReadCloser) {
+		if err := body.Close(); err != nil {
+			c.log.V(1).Info("failed to close response body for request", "url", r.URL)
+		}
+	}(httpResp.Body)
+
+	mediaType, params, _ := mime.ParseMediaType(httpResp.Header.Get(client.HTTPHeaderContentType))
+	if isTransientStatus(httpResp.StatusCode, mediaType == client.MIMETypeJSON) {
+		return &transientError{
+			err:       fmt.Errorf("request %s failed with status %s", r.URL, httpResp.Status),
+			retryable: true,
+		}
+	}
+	if mediaType != client.MIMETypeJSON && strings.ToLower(params["charset"]) != client.ChartSetUTF8 {
+		return fmt.Errorf("content type mismatch for request %s: expected %s; %s, got %q", r.URL,
+			client.MIMETypeJSON, client.ChartSetUTF8, httpResp.Header.Get(client.HTTPHeaderContentType))
+	}
+
+	if r.Method == http.MethodPost && r.URL.Query().Get(reviewIDParam) == "" {
+		body, err := io.ReadAll(httpResp.Body)
+		if err != nil {
+			return err
+		}
+		if reviewID, ok := pendingReviewID(httpResp.StatusCode, body); ok {
+			return &pendingReviewError{reviewID: reviewID}
+		}
+		httpResp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if err := resp.UnmarshalResponse(httpResp); err != nil {
+		return err
+	}
+	if resp.Failed() {
+		return resp.Err()
+	}
+	return nil
+}
+
+func (c *httpClient) State(ctx context.Context, r *api.StateRequest) (*api.StateResponse, error) {
+	resp := &api.StateResponse{}
+	if err := c.request(ctx, r, resp, api.EndpointState, http.MethodGet); err != nil {
+		return resp, err
+	}
+	c.observeMonitorState(resp.Result.MonitorState)
+	return resp, nil
+}
+
+func (c *httpClient) UserTasks(ctx context.Context, r *api.UserTasksRequest) (*api.UserTasksResponse, error) {
+	resp := &api.UserTasksResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointUserTasks, http.MethodGet)
+}
+
+func (c *httpClient) AddBroker(ctx context.Context, r *api.AddBrokerRequest) (*api.AddBrokerResponse, error) {
+	resp := &api.AddBrokerResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointAddBroker, http.MethodPost)
+}
+
+func (c *httpClient) RemoveBroker(ctx context.Context, r *api.RemoveBrokerRequest) (*api.RemoveBrokerResponse, error) {
+	resp := &api.RemoveBrokerResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointRemoveBroker, http.MethodPost)
+}
+
+func (c *httpClient) Rebalance(ctx context.Context, r *api.RebalanceRequest) (*api.RebalanceResponse, error) {
+	resp := &api.RebalanceResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointRebalance, http.MethodPost)
+}
+
+func (c *httpClient) DemoteBroker(ctx context.Context, r *api.DemoteBrokerRequest) (*api.DemoteBrokerResponse, error) {
+	resp := &api.DemoteBrokerResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointDemoteBroker, http.MethodPost)
+}
+
+func (c *httpClient) KafkaClusterLoad(ctx context.Context, r *api.KafkaClusterLoadRequest) (*api.KafkaClusterLoadResponse, error) {
+	resp := &api.KafkaClusterLoadResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointKafkaClusterLoad, http.MethodGet)
+}
+
+func (c *httpClient) KafkaClusterState(ctx context.Context, r *api.KafkaClusterStateRequest) (*api.KafkaClusterStateResponse, error) {
+	resp := &api.KafkaClusterStateResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointKafkaClusterState, http.MethodGet)
+}
+
+func (c *httpClient) TopicConfiguration(ctx context.Context, r *api.TopicConfigurationRequest) (*api.TopicConfigurationResponse, error) {
+	resp := &api.TopicConfigurationResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointTopicConfiguration, http.MethodPost)
+}
+
+func (c *httpClient) StopProposalExecution(ctx context.Context, r *api.StopProposalExecutionRequest) (*api.StopProposalExecutionResponse, error) {
+	resp := &api.StopProposalExecutionResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointStopProposalExecution, http.MethodPost)
+}
+
+func (c *httpClient) FixOfflineReplicas(ctx context.Context, r *api.FixOfflineReplicasRequest) (*api.FixOfflineReplicasResponse, error) {
+	resp := &api.FixOfflineReplicasResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointFixOfflineReplicas, http.MethodPost)
+}
+
+func (c *httpClient) RemoveDisks(ctx context.Context, r *RemoveDisksRequest) (*RemoveDisksResponse, error) {
+	resp := &RemoveDisksResponse{}
+	return resp, c.request(ctx, r, resp, EndpointRemoveDisks, http.MethodPost)
+}
+
+func (c *httpClient) Proposals(ctx context.Context, r *api.ProposalsRequest) (*api.ProposalsResponse, error) {
+	resp := &api.ProposalsResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointProposals, http.MethodGet)
+}
+
+func (c *httpClient) PauseSampling(ctx context.Context, r *api.PauseSamplingRequest) (*api.PauseSamplingResponse, error) {
+	resp := &api.PauseSamplingResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointPauseSampling, http.MethodPost)
+}
+
+func (c *httpClient) ResumeSampling(ctx context.Context, r *api.ResumeSamplingRequest) (*api.ResumeSamplingResponse, error) {
+	resp := &api.ResumeSamplingResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointResumeSampling, http.MethodPost)
+}
+
+func (c *httpClient) Rightsize(ctx context.Context, r *api.RightsizeRequest) (*api.RightsizeResponse, error) {
+	resp := &api.RightsizeResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointRightsize, http.MethodPost)
+}
+
+func (c *httpClient) Admin(ctx context.Context, r *api.AdminRequest) (*api.AdminResponse, error) {
+	resp := &api.AdminResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointAdmin, http.MethodPost)
+}
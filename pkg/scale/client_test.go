@@ -0,0 +1,371 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+)
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, r)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newCruiseControlServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte("{}"))
+	}))
+}
+
+func TestNewCruiseControlClientWithTransport(t *testing.T) {
+	server := newCruiseControlServer(0)
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL+"/kafkacruisecontrol",
+		WithTransport(transport), WithUserAgent("test"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(transport.requests) != 1 {
+		t.Fatalf("expected the request to be sent with the custom transport, got %d requests", len(transport.requests))
+	}
+	req := transport.requests[0]
+	if req.URL.Path != "/kafkacruisecontrol/state" {
+		t.Errorf("unexpected request path: %s", req.URL.Path)
+	}
+	if req.URL.Query().Get("json") != "true" {
+		t.Errorf("expected JSON response to be requested, got query: %s", req.URL.RawQuery)
+	}
+	if req.Header.Get("User-Agent") != "test" {
+		t.Errorf("unexpected User-Agent header: %s", req.Header.Get("User-Agent"))
+	}
+}
+
+func TestNewCruiseControlClientWithTransportAndProxy(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.example.com:3128")
+	if _, err := NewCruiseControlClient(context.TODO(), "cruisecontrol.example.com:8090",
+		WithTransport(&recordingTransport{}), WithProxy(proxyURL)); err == nil {
+		t.Error("expected error for proxy set for a transport which is not an *http.Transport")
+	}
+	if _, err := NewCruiseControlClient(context.TODO(), "cruisecontrol.example.com:8090",
+		WithTransport(http.DefaultTransport.(*http.Transport).Clone()), WithProxy(proxyURL)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestNewCruiseControlClientRequestTimeout(t *testing.T) {
+	server := newCruiseControlServer(500 * time.Millisecond)
+	defer server.Close()
+
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL, WithRequestTimeout(10*time.Millisecond),
+		WithRetry(RetryConfig{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); !errors.Is(err, ErrCruiseControlUnavailable) {
+		t.Errorf("expected the request to time out, got: %v", err)
+	}
+}
+
+func TestCruiseControlClientContextDeadline(t *testing.T) {
+	server := newCruiseControlServer(500 * time.Millisecond)
+	defer server.Close()
+
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cruisecontrol.State(ctx, api.StateRequestWithDefaults()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the request to be bound to the deadline of the context, got: %v", err)
+	}
+}
+
+func TestNewCruiseControlClientWithPathPrefix(t *testing.T) {
+	server := newCruiseControlServer(0)
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), CruiseControlURL("", "", server.URL+"/kafka/cc", ""),
+		WithTransport(transport))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.KafkaClusterState(context.TODO(), api.KafkaClusterStateRequestWithDefaults()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(transport.requests) != 1 || transport.requests[0].URL.Path != "/kafka/cc/kafkacruisecontrol/kafka_cluster_state" {
+		t.Errorf("expected request to the path-prefixed endpoint, got: %v", transport.requests)
+	}
+}
+
+func TestCruiseControlClientKafkaClusterDiskLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if r.URL.Query().Get("populate_disk_info") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errorMessage": "disk info is not requested"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"version": 1, "brokers": [{"Broker": 0, "DiskState": {
+			"/kafka-logs/kafka": {"DiskMB": 120.5, "DiskPct": 1.2, "NumLeaderReplicas": 3, "NumReplicas": 7},
+			"/kafka-logs2/kafka": {"DiskMB": "DEAD", "DiskPct": "DEAD", "NumLeaderReplicas": 0, "NumReplicas": 0}}}]}`))
+	}))
+	defer server.Close()
+
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp, err := cruisecontrol.KafkaClusterDiskLoad(context.TODO(), api.KafkaClusterLoadRequestWithDefaults())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Result.Brokers) != 1 {
+		t.Fatalf("expected the disk load of one broker, got: %+v", resp.Result)
+	}
+	diskState := resp.Result.Brokers[0].DiskState
+	if stats := diskState["/kafka-logs/kafka"]; stats.NumReplicas != 7 || stats.DiskMB.Usage != 120.5 {
+		t.Errorf("unexpected disk stats of the online log dir: %+v", stats)
+	}
+	if stats := diskState["/kafka-logs2/kafka"]; !stats.DiskMB.Dead {
+		t.Errorf("expected the offline log dir to be dead, got: %+v", stats)
+	}
+}
+
+func TestNewCruiseControlClientWithBaseURL(t *testing.T) {
+	server := newCruiseControlServer(0)
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(),
+		strings.TrimPrefix(server.URL, "http://")+"/cruise-control/kafkacruisecontrol", WithTransport(transport))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(transport.requests) != 1 || transport.requests[0].URL.Path != "/cruise-control/kafkacruisecontrol/state" {
+		t.Errorf("expected request to the path-prefixed endpoint, got: %v", transport.requests)
+	}
+
+	if _, err := NewCruiseControlClient(context.TODO(), "https:///kafkacruisecontrol"); err == nil {
+		t.Error("expected the server URL without host to be rejected")
+	}
+}
+
+func TestCruiseControlClientRemoveDisks(t *testing.T) {
+	server := newCruiseControlServer(0)
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL+"/kafkacruisecontrol", WithTransport(transport))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	req := &RemoveDisksRequest{BrokerIDAndLogDirs: types.BrokerIDAndLogDirs{1: {"/kafka-logs2/kafka"}}}
+	if _, err := cruisecontrol.RemoveDisks(context.TODO(), req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(transport.requests) != 1 {
+		t.Fatalf("expected one request, got %d requests", len(transport.requests))
+	}
+	sent := transport.requests[0]
+	if sent.Method != http.MethodPost || sent.URL.Path != "/kafkacruisecontrol/remove_disks" {
+		t.Errorf("unexpected request: %s %s", sent.Method, sent.URL.Path)
+	}
+	if logDirs := sent.URL.Query().Get("brokerid_and_logdirs"); logDirs != "1-/kafka-logs2/kafka" {
+		t.Errorf("unexpected broker id and log dir pairs: %s", logDirs)
+	}
+}
+
+// newFlakyCruiseControlServer returns a server responding with 503 to the first failures requests
+func newFlakyCruiseControlServer(failures int32) (*httptest.Server, *int32) {
+	var requests int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte("{}"))
+	})), &requests
+}
+
+func TestCruiseControlClientRetry(t *testing.T) {
+	server, requests := newFlakyCruiseControlServer(2)
+	defer server.Close()
+
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL,
+		WithRetry(RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Fatalf("expected the request to succeed after retries, got: %s", err)
+	}
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected 3 requests, got: %d", n)
+	}
+
+	atomic.StoreInt32(requests, -10)
+	if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); !errors.Is(err, ErrCruiseControlUnavailable) {
+		t.Errorf("expected Cruise Control to be unavailable after the retries ran out, got: %v", err)
+	}
+	if n := atomic.LoadInt32(requests); n != -7 {
+		t.Errorf("expected the request to be sent 3 times, got: %d", n+10)
+	}
+}
+
+func TestCruiseControlClientCircuitBreaker(t *testing.T) {
+	server, requests := newFlakyCruiseControlServer(3)
+	defer server.Close()
+
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL,
+		WithRetry(RetryConfig{}), WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults()); !errors.Is(err, ErrCruiseControlUnavailable) {
+			t.Fatalf("expected Cruise Control to be unavailable, got: %v", err)
+		}
+	}
+
+	// the breaker is shared with the other clients of the server
+	other, err := NewCruiseControlClient(context.TODO(), server.URL,
+		WithRetry(RetryConfig{}), WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := other.State(context.TODO(), api.StateRequestWithDefaults()); !errors.Is(err, ErrCruiseControlUnavailable) {
+		t.Fatalf("expected the circuit breaker to be open, got: %v", err)
+	}
+	if n := atomic.LoadInt32(requests); n != 2 {
+		t.Errorf("expected no request to be sent while the circuit breaker is open, got: %d", n-2)
+	}
+
+	breaker := other.(*httpClient).breaker
+	breaker.now = func() time.Time { return time.Now().Add(time.Minute) }
+	if _, err := other.State(context.TODO(), api.StateRequestWithDefaults()); !errors.Is(err, ErrCruiseControlUnavailable) {
+		t.Fatalf("expected the failing request let through the half-open breaker, got: %v", err)
+	}
+	if _, err := other.State(context.TODO(), api.StateRequestWithDefaults()); !errors.Is(err, ErrCruiseControlUnavailable) {
+		t.Fatalf("expected the circuit breaker to be open again, got: %v", err)
+	}
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected a single request through the half-open breaker, got: %d", n-2)
+	}
+
+	breaker.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := other.State(context.TODO(), api.StateRequestWithDefaults()); err != nil {
+		t.Fatalf("expected the request to succeed once Cruise Control is back, got: %s", err)
+	}
+	if !breaker.allow() {
+		t.Error("expected the circuit breaker to be closed")
+	}
+}
+
+func TestCruiseControlClientMetrics(t *testing.T) {
+	server, _ := newFlakyCruiseControlServer(1)
+	defer server.Close()
+
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL,
+		WithRetry(RetryConfig{}), WithMetricLabels("kafka", "metrics-test"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, _ = cruisecontrol.State(context.TODO(), api.StateRequestWithDefaults())
+	}
+
+	labels := prometheus.Labels{"namespace": "kafka", "kafka_cr": "metrics-test", "endpoint": "state"}
+	if n := testutil.ToFloat64(cruiseControlRequestsCounter.With(labels)); n != 2 {
+		t.Errorf("expected 2 requests, got: %v", n)
+	}
+	if n := testutil.ToFloat64(cruiseControlRequestErrorsCounter.With(labels)); n != 1 {
+		t.Errorf("expected 1 failed request, got: %v", n)
+	}
+	if n := testutil.CollectAndCount(cruiseControlMonitoringCoverageGauge, "kafka_operator_cruise_control_monitoring_coverage_percent"); n == 0 {
+		t.Error("expected the monitoring coverage to be recorded")
+	}
+}
+
+func TestCruiseControlClientTwoStepVerification(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		switch {
+		case r.URL.Path == "/rebalance" && r.URL.Query().Get("review_id") == "":
+			_, _ = w.Write([]byte(`{"RequestInfo":[{"Id":7,"Status":"PENDING_REVIEW","EndpointWithParams":"REBALANCE"}],"version":1}`))
+		case r.URL.Path == "/review":
+			_, _ = w.Write([]byte(`{"RequestInfo":[{"Id":7,"Status":"APPROVED","EndpointWithParams":"REBALANCE"}],"version":1}`))
+		default:
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+
+	cruisecontrol, err := NewCruiseControlClient(context.TODO(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cruisecontrol.Rebalance(context.TODO(), &api.RebalanceRequest{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		"POST /rebalance?json=true",
+		"POST /review?approve=7&json=true&reason=approved+by+koperator",
+		"POST /rebalance?json=true&review_id=7",
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("expected requests %v, got: %v", expected, requests)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("expected request %q, got: %q", expected[i], requests[i])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+)
+
+// The load endpoint of Cruise Control returns the disk info of the brokers by their log dirs when populate_disk_info
+// is set, while go-cruise-control decodes it as a list losing the log dirs
+
+// BrokerDiskLoad is the load of the log dirs of a broker by their path
+type BrokerDiskLoad struct {
+	Broker    int32                      `json:"Broker"`
+	DiskState map[string]types.DiskStats `json:"DiskState"`
+}
+
+// KafkaClusterDiskLoadResult is the load of the log dirs of the brokers in the Kafka cluster
+type KafkaClusterDiskLoadResult struct {
+	types.Version
+
+	Brokers []BrokerDiskLoad `json:"brokers"`
+}
+
+// KafkaClusterDiskLoadResponse is the response of Cruise Control to a load request with populate_disk_info
+type KafkaClusterDiskLoadResponse struct {
+	types.GenericResponse
+
+	Result *KafkaClusterDiskLoadResult
+}
+
+func (r *KafkaClusterDiskLoadResponse) UnmarshalResponse(resp *http.Response) error {
+	if err := r.GenericResponse.UnmarshalResponse(resp); err != nil {
+		return err
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var d interface{}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		r.Result = &KafkaClusterDiskLoadResult{}
+		d = r.Result
+	default:
+		r.Error = &types.APIError{}
+		d = r.Error
+	}
+
+	return json.Unmarshal(bodyBytes, d)
+}
+
+// KafkaClusterDiskLoad returns the load of the log dirs of the brokers, the disk info is requested regardless of the
+// PopulateDiskInfo field of the request
+func (c *httpClient) KafkaClusterDiskLoad(ctx context.Context, r *api.KafkaClusterLoadRequest) (*KafkaClusterDiskLoadResponse, error) {
+	req := *r
+	req.PopulateDiskInfo = true
+	resp := &KafkaClusterDiskLoadResponse{}
+	return resp, c.request(ctx, &req, resp, api.EndpointKafkaClusterLoad, http.MethodGet)
+}
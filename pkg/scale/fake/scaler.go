@@ -0,0 +1,688 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fake provides an in-memory scale.CruiseControlScaler, so that the controllers scaling Kafka clusters through
+// Cruise Control can be unit tested without a running Cruise Control.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+// Method is the name of a method of the scale.CruiseControlScaler
+type Method string
+
+const (
+	MethodIsReady                          Method = "IsReady"
+	MethodStatus                           Method = "Status"
+	MethodGetUserTasks                     Method = "GetUserTasks"
+	MethodListUserTasks                    Method = "ListUserTasks"
+	MethodIsUp                             Method = "IsUp"
+	MethodAddBrokers                       Method = "AddBrokers"
+	MethodRemoveBrokers                    Method = "RemoveBrokers"
+	MethodRebalanceDisks                   Method = "RebalanceDisks"
+	MethodIntraBrokerRebalance             Method = "IntraBrokerRebalance"
+	MethodBrokersWithState                 Method = "BrokersWithState"
+	MethodPartitionReplicasByBroker        Method = "PartitionReplicasByBroker"
+	MethodBrokerWithLeastPartitionReplicas Method = "BrokerWithLeastPartitionReplicas"
+	MethodLogDirsByBroker                  Method = "LogDirsByBroker"
+	MethodDiskUsageByBroker                Method = "DiskUsageByBroker"
+	MethodBrokerLoads                      Method = "BrokerLoads"
+	MethodGoalViolations                   Method = "GoalViolations"
+	MethodRebalanceWithGoals               Method = "RebalanceWithGoals"
+	MethodDemoteBrokers                    Method = "DemoteBrokers"
+	MethodUpdateTopicReplicationFactor     Method = "UpdateTopicReplicationFactor"
+	MethodStopExecution                    Method = "StopExecution"
+	MethodFixOfflineReplicas               Method = "FixOfflineReplicas"
+	MethodRemoveDisks                      Method = "RemoveDisks"
+	MethodGetProposals                     Method = "GetProposals"
+	MethodPauseSampling                    Method = "PauseSampling"
+	MethodResumeSampling                   Method = "ResumeSampling"
+	MethodUpdateExecutorConcurrency        Method = "UpdateExecutorConcurrency"
+	MethodUpdateSelfHealing                Method = "UpdateSelfHealing"
+	MethodRightsize                        Method = "Rightsize"
+)
+
+// methodEndpoints are the Cruise Control endpoints of the methods starting tasks
+var methodEndpoints = map[Method]string{
+	MethodAddBrokers:                   "ADD_BROKER",
+	MethodRemoveBrokers:                "REMOVE_BROKER",
+	MethodRebalanceDisks:               "REBALANCE",
+	MethodIntraBrokerRebalance:         "REBALANCE",
+	MethodRebalanceWithGoals:           "REBALANCE",
+	MethodDemoteBrokers:                "DEMOTE_BROKER",
+	MethodUpdateTopicReplicationFactor: "TOPIC_CONFIGURATION",
+	MethodFixOfflineReplicas:           "FIX_OFFLINE_REPLICAS",
+	MethodRemoveDisks:                  "REMOVE_DISKS",
+}
+
+// ErrExecutionStopped is the error of the tasks stopped by StopExecution
+var ErrExecutionStopped = errors.New("execution stopped")
+
+// WatchPollConfig is the PollConfig of the tasks watched by the Scaler, it is short to keep the tests fast
+var WatchPollConfig = scale.PollConfig{
+	MinInterval: time.Millisecond,
+	MaxInterval: 10 * time.Millisecond,
+}
+
+// Broker describes a broker of the Kafka cluster simulated by the Scaler
+type Broker struct {
+	ID             string
+	State          scale.KafkaBrokerState
+	Rack           string
+	Replicas       int32
+	Leaders        int32
+	OnlineLogDirs  []string
+	OfflineLogDirs []string
+	// LogDirLoads holds the replicas and the disk usage of the log dirs by their path
+	LogDirLoads map[string]scale.LogDir
+	DiskUsage   scale.DiskUsage
+	// Load is the resource utilization of the broker, its replicas and leaders are taken from the broker
+	Load scale.BrokerLoad
+}
+
+// Task describes a task started by the Scaler
+type Task struct {
+	ID        string
+	Method    Method
+	BrokerIDs []string
+	// LogDirs are the log dirs the replicas are moved off from by a remove disks task
+	LogDirs    []string
+	StartedAt  string
+	FinishedAt string
+	State      v1beta1.CruiseControlUserTaskState
+	Err        string
+
+	startedAt   time.Time
+	progression []v1beta1.CruiseControlUserTaskState
+}
+
+// Scaler is an in-memory scale.CruiseControlScaler simulating Cruise Control. Each operation starts a task going
+// through the TaskProgression, one step for every GetUserTasks call, and applies its changes on the brokers once it
+// completes. Returning errors can be scripted for each method.
+type Scaler struct {
+	// StatusResult is returned by Status, a ready Cruise Control is reported when it is nil
+	StatusResult *scale.CruiseControlStatus
+	// Down makes Cruise Control look unreachable: IsUp and IsReady return false and Status reports nothing ready
+	Down bool
+	// Brokers are the brokers of the simulated Kafka cluster
+	Brokers []Broker
+	// GoalViolationsResult is returned by GoalViolations
+	GoalViolationsResult []scale.GoalViolation
+	// ProposalsResult is returned by GetProposals, proposals without any movement are reported when it is nil
+	ProposalsResult *scale.ProposalSummary
+	// TaskProgression is the list of states the new tasks go through, the tasks complete at the first GetUserTasks
+	// call when it is empty
+	TaskProgression []v1beta1.CruiseControlUserTaskState
+	// SamplingPaused and SamplingPauseReason are set by PauseSampling and reported by Status unless StatusResult is
+	// set
+	SamplingPaused      bool
+	SamplingPauseReason string
+	// ExecutorConcurrency is the executor concurrency set by UpdateExecutorConcurrency
+	ExecutorConcurrency scale.ExecutorConcurrency
+	// SelfHealing tells whether the self-healing is enabled for the anomaly types, it is changed by UpdateSelfHealing
+	// and reported by Status unless StatusResult is set
+	SelfHealing map[string]bool
+	// Rightsizes are the requests received by Rightsize, which reports the completion of each of them
+	Rightsizes []scale.RightsizeOptions
+
+	mu         sync.Mutex
+	errors     map[Method][]error
+	tasks      []*Task
+	calls      []Method
+	nextTaskID int
+}
+
+var _ scale.CruiseControlScaler = &Scaler{}
+
+// NewScaler returns a Scaler simulating a Kafka cluster with the given brokers
+func NewScaler(brokers ...Broker) *Scaler {
+	return &Scaler{
+		Brokers: brokers,
+		errors:  make(map[Method][]error),
+	}
+}
+
+// FailNext makes the next calls of the method return the given errors, one error for each call. The operations
+// failing with an error wrapping scale.ErrCruiseControlUnavailable report the v1beta1.CruiseControlTaskUnavailable
+// state like the scale.CruiseControlScaler does.
+func (s *Scaler) FailNext(method Method, errs ...error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.errors == nil {
+		s.errors = make(map[Method][]error)
+	}
+	s.errors[method] = append(s.errors[method], errs...)
+}
+
+// Calls returns the methods called so far in the order of the calls
+func (s *Scaler) Calls() []Method {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := make([]Method, len(s.calls))
+	copy(calls, s.calls)
+	return calls
+}
+
+// Tasks returns the tasks started so far
+func (s *Scaler) Tasks() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, *task)
+	}
+	return tasks
+}
+
+// call records the call and returns the scripted error of the method or the error of the context if it is done,
+// the lock must be held
+func (s *Scaler) call(ctx context.Context, method Method) error {
+	s.calls = append(s.calls, method)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	errs := s.errors[method]
+	if len(errs) == 0 {
+		return nil
+	}
+	s.errors[method] = errs[1:]
+	return errs[0]
+}
+
+// startTask records the call and starts a task for it, the lock must be held
+func (s *Scaler) startTask(ctx context.Context, method Method, brokerIDs []string, logDirs []string) (*scale.Result, error) {
+	if err := s.call(ctx, method); err != nil {
+		state := v1beta1.CruiseControlTaskCompletedWithError
+		if errors.Is(err, scale.ErrCruiseControlUnavailable) {
+			state = v1beta1.CruiseControlTaskUnavailable
+		}
+		return &scale.Result{State: state, Err: err.Error()}, err
+	}
+
+	s.nextTaskID++
+	now := time.Now()
+	task := &Task{
+		ID:          fmt.Sprintf("fake-task-%d", s.nextTaskID),
+		Method:      method,
+		BrokerIDs:   append([]string{}, brokerIDs...),
+		LogDirs:     append([]string{}, logDirs...),
+		StartedAt:   now.UTC().Format(time.RFC3339),
+		State:       v1beta1.CruiseControlTaskActive,
+		startedAt:   now,
+		progression: append([]v1beta1.CruiseControlUserTaskState{}, s.TaskProgression...),
+	}
+	s.tasks = append(s.tasks, task)
+	return task.result(), nil
+}
+
+func (t *Task) result() *scale.Result {
+	return &scale.Result{
+		TaskID:     t.ID,
+		StartedAt:  t.StartedAt,
+		FinishedAt: t.FinishedAt,
+		State:      t.State,
+		Err:        t.Err,
+	}
+}
+
+func (t *Task) finished() bool {
+	return t.State == v1beta1.CruiseControlTaskCompleted || t.State == v1beta1.CruiseControlTaskCompletedWithError
+}
+
+// progress moves the task to its next state and applies its changes on the brokers once it completes, the lock must
+// be held
+func (s *Scaler) progress(task *Task) {
+	if task.finished() {
+		return
+	}
+	if len(task.progression) == 0 {
+		task.State = v1beta1.CruiseControlTaskCompleted
+	} else {
+		task.State = task.progression[0]
+		task.progression = task.progression[1:]
+	}
+	if task.State != v1beta1.CruiseControlTaskCompleted {
+		return
+	}
+	task.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+
+	for _, brokerID := range task.BrokerIDs {
+		broker := s.broker(brokerID)
+		if broker == nil {
+			continue
+		}
+		switch task.Method {
+		case MethodAddBrokers:
+			broker.State = scale.KafkaBrokerAlive
+		case MethodRemoveBrokers:
+			broker.Replicas = 0
+			broker.Leaders = 0
+		case MethodDemoteBrokers:
+			broker.State = scale.KafkaBrokerDemoted
+			broker.Leaders = 0
+		}
+	}
+}
+
+func (s *Scaler) broker(id string) *Broker {
+	for i := range s.Brokers {
+		if s.Brokers[i].ID == id {
+			return &s.Brokers[i]
+		}
+	}
+	return nil
+}
+
+func (s *Scaler) IsReady(ctx context.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodIsReady); err != nil {
+		return false
+	}
+	return s.status().IsReady()
+}
+
+func (s *Scaler) Status(ctx context.Context) scale.CruiseControlStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodStatus); err != nil {
+		return scale.CruiseControlStatus{}
+	}
+	return s.status()
+}
+
+// status returns the simulated status of Cruise Control, the lock must be held
+func (s *Scaler) status() scale.CruiseControlStatus {
+	if s.Down {
+		return scale.CruiseControlStatus{}
+	}
+	if s.StatusResult != nil {
+		return *s.StatusResult
+	}
+	return scale.CruiseControlStatus{
+		MonitorReady:        !s.SamplingPaused,
+		ExecutorReady:       true,
+		AnalyzerReady:       true,
+		ProposalReady:       true,
+		GoalsReady:          true,
+		MonitoredWindows:    1,
+		MonitoringCoverage:  100,
+		SamplingPaused:      s.SamplingPaused,
+		SamplingPauseReason: s.SamplingPauseReason,
+		AnomalyDetector:     s.anomalyDetectorStatus(),
+	}
+}
+
+// anomalyDetectorStatus returns the self-healing settings sorted by anomaly type, the lock must be held
+func (s *Scaler) anomalyDetectorStatus() scale.AnomalyDetectorStatus {
+	status := scale.AnomalyDetectorStatus{}
+	anomalyTypes := make([]string, 0, len(s.SelfHealing))
+	for anomalyType := range s.SelfHealing {
+		anomalyTypes = append(anomalyTypes, anomalyType)
+	}
+	sort.Strings(anomalyTypes)
+	for _, anomalyType := range anomalyTypes {
+		if s.SelfHealing[anomalyType] {
+			status.SelfHealingEnabled = append(status.SelfHealingEnabled, anomalyType)
+		} else {
+			status.SelfHealingDisabled = append(status.SelfHealingDisabled, anomalyType)
+		}
+	}
+	return status
+}
+
+func (s *Scaler) GetUserTasks(ctx context.Context, taskIDs ...string) ([]*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodGetUserTasks); err != nil {
+		return nil, err
+	}
+
+	results := make([]*scale.Result, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if len(taskIDs) > 0 && !util.StringSliceContains(taskIDs, task.ID) {
+			continue
+		}
+		s.progress(task)
+		results = append(results, task.result())
+	}
+	return results, nil
+}
+
+// ListUserTasks returns the tasks selected by the filter, the endpoint of a task is the Cruise Control endpoint of the
+// method which started it
+func (s *Scaler) ListUserTasks(ctx context.Context, filter scale.UserTaskFilter) ([]*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodListUserTasks); err != nil {
+		return nil, err
+	}
+
+	results := make([]*scale.Result, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if len(filter.Endpoints) > 0 && !util.StringSliceContains(filter.Endpoints, methodEndpoints[task.Method]) {
+			continue
+		}
+		s.progress(task)
+		if filter.Matches(task.ID, task.State, task.startedAt) {
+			results = append(results, task.result())
+		}
+	}
+	return results, nil
+}
+
+func (s *Scaler) IsUp(ctx context.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.call(ctx, MethodIsUp) == nil && !s.Down
+}
+
+func (s *Scaler) AddBrokers(ctx context.Context, _ scale.OperationOptions, brokerIDs ...string) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodAddBrokers, brokerIDs, nil)
+}
+
+func (s *Scaler) RemoveBrokers(ctx context.Context, _ scale.OperationOptions, brokerIDs ...string) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodRemoveBrokers, brokerIDs, nil)
+}
+
+func (s *Scaler) RebalanceDisks(ctx context.Context, _ scale.OperationOptions, brokerIDs ...string) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodRebalanceDisks, brokerIDs, nil)
+}
+
+func (s *Scaler) IntraBrokerRebalance(ctx context.Context, _ scale.OperationOptions, brokerIDs ...string) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodIntraBrokerRebalance, brokerIDs, nil)
+}
+
+func (s *Scaler) BrokersWithState(ctx context.Context, states ...scale.KafkaBrokerState) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodBrokersWithState); err != nil {
+		return nil, err
+	}
+
+	brokerIDs := make([]string, 0, len(s.Brokers))
+	for _, broker := range s.Brokers {
+		for _, state := range states {
+			if broker.State == state {
+				brokerIDs = append(brokerIDs, broker.ID)
+				break
+			}
+		}
+	}
+	return brokerIDs, nil
+}
+
+func (s *Scaler) PartitionReplicasByBroker(ctx context.Context) (map[string]int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodPartitionReplicasByBroker); err != nil {
+		return nil, err
+	}
+
+	replicasByBroker := make(map[string]int32, len(s.Brokers))
+	for _, broker := range s.Brokers {
+		replicasByBroker[broker.ID] = broker.Replicas
+	}
+	return replicasByBroker, nil
+}
+
+func (s *Scaler) BrokerWithLeastPartitionReplicas(ctx context.Context, mode scale.BrokerSelectionMode) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodBrokerWithLeastPartitionReplicas); err != nil {
+		return "", err
+	}
+
+	if mode == scale.BrokerSelectionByUtilization {
+		brokers := make([]scale.BrokerUtilization, 0, len(s.Brokers))
+		for _, broker := range s.Brokers {
+			if broker.State != scale.KafkaBrokerAlive {
+				continue
+			}
+			brokers = append(brokers, scale.BrokerUtilization{
+				ID:        broker.ID,
+				Rack:      broker.Rack,
+				Replicas:  broker.Replicas,
+				DiskUsage: broker.DiskUsage,
+			})
+		}
+		return scale.LeastUtilizedBroker(brokers), nil
+	}
+
+	var brokerID string
+	replicas := int32(math.MaxInt32)
+	for _, broker := range s.Brokers {
+		if broker.Replicas < replicas {
+			replicas = broker.Replicas
+			brokerID = broker.ID
+		}
+	}
+	return brokerID, nil
+}
+
+func (s *Scaler) LogDirsByBroker(ctx context.Context) (map[string]map[scale.LogDirState][]scale.LogDir, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodLogDirsByBroker); err != nil {
+		return nil, err
+	}
+
+	logDirsByBroker := make(map[string]map[scale.LogDirState][]scale.LogDir, len(s.Brokers))
+	for _, broker := range s.Brokers {
+		logDirsByBroker[broker.ID] = map[scale.LogDirState][]scale.LogDir{
+			scale.LogDirStateOnline:  broker.logDirs(broker.OnlineLogDirs),
+			scale.LogDirStateOffline: broker.logDirs(broker.OfflineLogDirs),
+		}
+	}
+	return logDirsByBroker, nil
+}
+
+// logDirs returns the log dirs at the given paths with their loads
+func (b Broker) logDirs(paths []string) []scale.LogDir {
+	logDirs := make([]scale.LogDir, 0, len(paths))
+	for _, path := range paths {
+		logDir := b.LogDirLoads[path]
+		logDir.Path = path
+		logDirs = append(logDirs, logDir)
+	}
+	return logDirs
+}
+
+func (s *Scaler) DiskUsageByBroker(ctx context.Context) (map[string]scale.DiskUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodDiskUsageByBroker); err != nil {
+		return nil, err
+	}
+
+	usageByBroker := make(map[string]scale.DiskUsage, len(s.Brokers))
+	for _, broker := range s.Brokers {
+		usageByBroker[broker.ID] = broker.DiskUsage
+	}
+	return usageByBroker, nil
+}
+
+func (s *Scaler) BrokerLoads(ctx context.Context) (map[string]scale.BrokerLoad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodBrokerLoads); err != nil {
+		return nil, err
+	}
+
+	loads := make(map[string]scale.BrokerLoad, len(s.Brokers))
+	for _, broker := range s.Brokers {
+		load := broker.Load
+		load.Replicas = broker.Replicas
+		load.Leaders = broker.Leaders
+		loads[broker.ID] = load
+	}
+	return loads, nil
+}
+
+func (s *Scaler) GoalViolations(ctx context.Context) ([]scale.GoalViolation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodGoalViolations); err != nil {
+		return nil, err
+	}
+	return append([]scale.GoalViolation{}, s.GoalViolationsResult...), nil
+}
+
+func (s *Scaler) RebalanceWithGoals(ctx context.Context, _ []string, _ string, _ ...string) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodRebalanceWithGoals, nil, nil)
+}
+
+func (s *Scaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodDemoteBrokers, brokerIDs, nil)
+}
+
+func (s *Scaler) UpdateTopicReplicationFactor(ctx context.Context, _ string, _ int32) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodUpdateTopicReplicationFactor, nil, nil)
+}
+
+// StopExecution completes the unfinished tasks with ErrExecutionStopped
+func (s *Scaler) StopExecution(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodStopExecution); err != nil {
+		return err
+	}
+
+	for _, task := range s.tasks {
+		if !task.finished() {
+			task.State = v1beta1.CruiseControlTaskCompletedWithError
+			task.Err = ErrExecutionStopped.Error()
+			task.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+		}
+	}
+	return nil
+}
+
+func (s *Scaler) PauseSampling(ctx context.Context, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodPauseSampling); err != nil {
+		return err
+	}
+	s.SamplingPaused, s.SamplingPauseReason = true, reason
+	return nil
+}
+
+func (s *Scaler) ResumeSampling(ctx context.Context, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodResumeSampling); err != nil {
+		return err
+	}
+	s.SamplingPaused, s.SamplingPauseReason = false, ""
+	return nil
+}
+
+func (s *Scaler) UpdateExecutorConcurrency(ctx context.Context, concurrency scale.ExecutorConcurrency) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodUpdateExecutorConcurrency); err != nil {
+		return err
+	}
+	s.ExecutorConcurrency = concurrency
+	return nil
+}
+
+func (s *Scaler) UpdateSelfHealing(ctx context.Context, enabled, disabled []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodUpdateSelfHealing); err != nil {
+		return err
+	}
+	if s.SelfHealing == nil {
+		s.SelfHealing = make(map[string]bool)
+	}
+	for _, anomalyType := range enabled {
+		s.SelfHealing[anomalyType] = true
+	}
+	for _, anomalyType := range disabled {
+		s.SelfHealing[anomalyType] = false
+	}
+	return nil
+}
+
+func (s *Scaler) Rightsize(ctx context.Context, opts scale.RightsizeOptions) (*scale.RightsizeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodRightsize); err != nil {
+		return nil, err
+	}
+	s.Rightsizes = append(s.Rightsizes, opts)
+	return &scale.RightsizeResult{
+		BrokersToAdd:     opts.BrokersToAdd,
+		PartitionCount:   opts.PartitionCount,
+		Topic:            opts.Topic,
+		ProvisionerState: "COMPLETED",
+	}, nil
+}
+
+func (s *Scaler) FixOfflineReplicas(ctx context.Context) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodFixOfflineReplicas, nil, nil)
+}
+
+func (s *Scaler) RemoveDisks(ctx context.Context, brokerID string, logDirs []string) (*scale.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTask(ctx, MethodRemoveDisks, []string{brokerID}, logDirs)
+}
+
+func (s *Scaler) GetProposals(ctx context.Context, _ ...string) (*scale.ProposalSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call(ctx, MethodGetProposals); err != nil {
+		return nil, err
+	}
+	if s.ProposalsResult != nil {
+		proposals := *s.ProposalsResult
+		return &proposals, nil
+	}
+	return &scale.ProposalSummary{}, nil
+}
+
+// WatchTask polls the task with the intervals of WatchPollConfig
+func (s *Scaler) WatchTask(ctx context.Context, taskID string) <-chan scale.TaskUpdate {
+	return scale.PollTask(ctx, s, taskID, WatchPollConfig)
+}
@@ -0,0 +1,196 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func newFakeScaler() *Scaler {
+	return NewScaler(
+		Broker{ID: "0", State: scale.KafkaBrokerAlive, Replicas: 10, Leaders: 5},
+		Broker{ID: "1", State: scale.KafkaBrokerAlive, Replicas: 12, Leaders: 6},
+		Broker{ID: "2", State: scale.KafkaBrokerNew},
+	)
+}
+
+func TestScalerTaskProgression(t *testing.T) {
+	scaler := newFakeScaler()
+	scaler.TaskProgression = []v1beta1.CruiseControlUserTaskState{
+		v1beta1.CruiseControlTaskInExecution,
+		v1beta1.CruiseControlTaskCompleted,
+	}
+
+	result, err := scaler.AddBrokers(context.TODO(), scale.OperationOptions{}, "2")
+	if err != nil || result.State != v1beta1.CruiseControlTaskActive {
+		t.Fatalf("expected active task, got: %+v, error: %v", result, err)
+	}
+
+	for _, expected := range scaler.TaskProgression {
+		results, err := scaler.GetUserTasks(context.TODO(), result.TaskID)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(results) != 1 || results[0].State != expected {
+			t.Fatalf("expected task state %s, got: %+v", expected, results)
+		}
+	}
+
+	alive, err := scaler.BrokersWithState(context.TODO(), scale.KafkaBrokerAlive)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(alive, []string{"0", "1", "2"}) {
+		t.Errorf("expected the added broker to be alive, got: %v", alive)
+	}
+}
+
+func TestScalerCompletedTaskChanges(t *testing.T) {
+	scaler := newFakeScaler()
+
+	if _, err := scaler.RemoveBrokers(context.TODO(), scale.OperationOptions{}, "1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := scaler.DemoteBrokers(context.TODO(), "0"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := scaler.GetUserTasks(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	replicas, err := scaler.PartitionReplicasByBroker(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if replicas["1"] != 0 {
+		t.Errorf("expected the removed broker to have no replicas, got: %d", replicas["1"])
+	}
+	demoted, _ := scaler.BrokersWithState(context.TODO(), scale.KafkaBrokerDemoted)
+	if !reflect.DeepEqual(demoted, []string{"0"}) {
+		t.Errorf("expected the broker to be demoted, got: %v", demoted)
+	}
+	loads, _ := scaler.BrokerLoads(context.TODO())
+	if loads["0"].Leaders != 0 || loads["1"].Replicas != 0 {
+		t.Errorf("unexpected broker loads: %+v", loads)
+	}
+}
+
+func TestScalerFailNext(t *testing.T) {
+	scaler := newFakeScaler()
+	scaler.FailNext(MethodRemoveBrokers, errors.New("boom"), fmt.Errorf("%w: timeout", scale.ErrCruiseControlUnavailable))
+
+	result, err := scaler.RemoveBrokers(context.TODO(), scale.OperationOptions{}, "1")
+	if err == nil || result.State != v1beta1.CruiseControlTaskCompletedWithError {
+		t.Errorf("expected failed task, got: %+v, error: %v", result, err)
+	}
+	result, err = scaler.RemoveBrokers(context.TODO(), scale.OperationOptions{}, "1")
+	if !errors.Is(err, scale.ErrCruiseControlUnavailable) || result.State != v1beta1.CruiseControlTaskUnavailable {
+		t.Errorf("expected Cruise Control to be unavailable, got: %+v, error: %v", result, err)
+	}
+	if _, err = scaler.RemoveBrokers(context.TODO(), scale.OperationOptions{}, "1"); err != nil {
+		t.Errorf("expected the scripted errors to be used up, got: %s", err)
+	}
+
+	if len(scaler.Tasks()) != 1 {
+		t.Errorf("expected a single task, got: %+v", scaler.Tasks())
+	}
+	if calls := scaler.Calls(); len(calls) != 3 || calls[0] != MethodRemoveBrokers {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+}
+
+func TestScalerStopExecution(t *testing.T) {
+	scaler := newFakeScaler()
+	scaler.TaskProgression = []v1beta1.CruiseControlUserTaskState{v1beta1.CruiseControlTaskInExecution}
+
+	result, _ := scaler.RebalanceDisks(context.TODO(), scale.OperationOptions{}, "0", "1")
+	if err := scaler.StopExecution(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	results, _ := scaler.GetUserTasks(context.TODO(), result.TaskID)
+	if len(results) != 1 || results[0].State != v1beta1.CruiseControlTaskCompletedWithError ||
+		results[0].Err != ErrExecutionStopped.Error() {
+		t.Errorf("expected the task to be stopped, got: %+v", results)
+	}
+}
+
+func TestScalerDown(t *testing.T) {
+	scaler := newFakeScaler()
+	if !scaler.IsUp(context.TODO()) || !scaler.IsReady(context.TODO()) {
+		t.Error("expected Cruise Control to be up and ready")
+	}
+	scaler.Down = true
+	if scaler.IsUp(context.TODO()) || scaler.IsReady(context.TODO()) {
+		t.Error("expected Cruise Control to be down")
+	}
+}
+
+func TestMockNewCruiseControlScalerWith(t *testing.T) {
+	fake := newFakeScaler()
+	scale.MockNewCruiseControlScalerWith(fake)
+	defer scale.MockNewCruiseControlScaler()
+
+	scaler, err := scale.NewCruiseControlScaler(context.TODO(), "http://cruisecontrol")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if scaler != fake {
+		t.Errorf("expected the fake scaler to be returned, got: %T", scaler)
+	}
+}
+
+func TestScalerWatchTask(t *testing.T) {
+	scaler := newFakeScaler()
+	scaler.TaskProgression = []v1beta1.CruiseControlUserTaskState{
+		v1beta1.CruiseControlTaskInExecution,
+		v1beta1.CruiseControlTaskInExecution,
+		v1beta1.CruiseControlTaskCompleted,
+	}
+
+	result, _ := scaler.AddBrokers(context.TODO(), scale.OperationOptions{}, "2")
+	var states []v1beta1.CruiseControlUserTaskState
+	for update := range scaler.WatchTask(context.TODO(), result.TaskID) {
+		if update.Err != nil {
+			t.Fatalf("unexpected error: %s", update.Err)
+		}
+		states = append(states, update.Result.State)
+	}
+
+	expected := []v1beta1.CruiseControlUserTaskState{v1beta1.CruiseControlTaskInExecution, v1beta1.CruiseControlTaskCompleted}
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("expected task states %v, got: %v", expected, states)
+	}
+}
+
+func TestScalerListUserTasks(t *testing.T) {
+	scaler := newFakeScaler()
+	_, _ = scaler.AddBrokers(context.TODO(), scale.OperationOptions{}, "2")
+	_, _ = scaler.RemoveBrokers(context.TODO(), scale.OperationOptions{}, "1")
+
+	results, err := scaler.ListUserTasks(context.TODO(), scale.UserTaskFilter{Endpoints: []string{"REMOVE_BROKER"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(results) != 1 || results[0].TaskID != "fake-task-2" {
+		t.Errorf("expected the remove broker task, got: %+v", results)
+	}
+}
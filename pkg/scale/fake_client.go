@@ -0,0 +1,651 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+// FakeBroker describes a broker of the Kafka cluster simulated by the FakeCruiseControlClient
+type FakeBroker struct {
+	ID             int32
+	State          KafkaBrokerState
+	Rack           string
+	Replicas       int32
+	DiskMB         float64
+	DiskCapacityMB float64
+	Leaders        int32
+	CPUPct         float64
+	// NwInRate and NwOutRate are the leader incoming and the outgoing network rates of the broker in KB/s
+	NwInRate  float64
+	NwOutRate float64
+	// DiskReplicas holds the number of partition replicas on each disk of the broker
+	DiskReplicas   []int32
+	OnlineLogDirs  []string
+	OfflineLogDirs []string
+}
+
+// FakeUserTask describes a user task created by the FakeCruiseControlClient
+type FakeUserTask struct {
+	ID        string
+	Endpoint  types.APIEndpoint
+	BrokerIDs []int32
+	// LogDirs are the log dirs of the brokers the replicas are moved off from by a remove disks task
+	LogDirs []string
+	// IntraBroker is true for the rebalance tasks moving replicas only between the disks of the brokers
+	IntraBroker bool
+	// ExcludedTopics is the regular expression of the topics excluded from a rebalance task
+	ExcludedTopics string
+	Status         types.UserTaskStatus
+	// StartedAt is the time the task was created at
+	StartedAt time.Time
+
+	progression []types.UserTaskStatus
+}
+
+// FakeCruiseControlClient is an in-memory CruiseControlClient simulating Cruise Control for the tests of the
+// controllers using the CruiseControlScaler. Each add broker, remove broker and rebalance request creates a user
+// task going through the TaskProgression, one step for every user tasks request. Returning errors can be scripted
+// for each endpoint.
+type FakeCruiseControlClient struct {
+	// StateResult is returned by the state endpoint, a ready Cruise Control is reported when it is nil
+	StateResult *types.StateResult
+	// ProposalsResult is returned by the proposals endpoint, proposals without any movement are reported when it is nil
+	ProposalsResult *types.OptimizationResult
+	// RightsizeResult is returned by the rightsize endpoint, the completion of the request is reported when it is nil
+	RightsizeResult *types.RightsizeResult
+	// Brokers are the brokers of the simulated Kafka cluster
+	Brokers []FakeBroker
+	// TaskProgression is the list of statuses the new user tasks go through, the tasks complete at the first user
+	// tasks request when it is empty
+	TaskProgression []types.UserTaskStatus
+
+	mu          sync.Mutex
+	errors      map[types.APIEndpoint][]error
+	tasks       []*FakeUserTask
+	requests    []types.APIEndpoint
+	nextTaskID  int
+	paused      bool
+	pauseReason string
+	concurrency ExecutorConcurrency
+	selfHealing map[string]bool
+}
+
+var _ CruiseControlClient = &FakeCruiseControlClient{}
+
+// NewFakeCruiseControlClient returns a FakeCruiseControlClient simulating a Kafka cluster with the given brokers
+func NewFakeCruiseControlClient(brokers ...FakeBroker) *FakeCruiseControlClient {
+	return &FakeCruiseControlClient{
+		Brokers: brokers,
+		errors:  make(map[types.APIEndpoint][]error),
+	}
+}
+
+// FailNext makes the next requests to the endpoint return the given errors, one error for each request
+func (f *FakeCruiseControlClient) FailNext(endpoint types.APIEndpoint, errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.errors == nil {
+		f.errors = make(map[types.APIEndpoint][]error)
+	}
+	f.errors[endpoint] = append(f.errors[endpoint], errs...)
+}
+
+// Requests returns the endpoints requested so far in the order of the requests
+func (f *FakeCruiseControlClient) Requests() []types.APIEndpoint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := make([]types.APIEndpoint, len(f.requests))
+	copy(requests, f.requests)
+	return requests
+}
+
+// Tasks returns the user tasks created so far
+func (f *FakeCruiseControlClient) Tasks() []FakeUserTask {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tasks := make([]FakeUserTask, 0, len(f.tasks))
+	for _, task := range f.tasks {
+		tasks = append(tasks, *task)
+	}
+	return tasks
+}
+
+// request records the request and returns the scripted error of the endpoint or the error of the context if it is
+// done, the lock must be held
+func (f *FakeCruiseControlClient) request(ctx context.Context, endpoint types.APIEndpoint) error {
+	f.requests = append(f.requests, endpoint)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	errs := f.errors[endpoint]
+	if len(errs) == 0 {
+		return nil
+	}
+	f.errors[endpoint] = errs[1:]
+	return errs[0]
+}
+
+// newTask creates a user task for the request, the lock must be held
+func (f *FakeCruiseControlClient) newTask(endpoint types.APIEndpoint, brokerIDs []int32) types.GenericResponse {
+	f.nextTaskID++
+	task := &FakeUserTask{
+		ID:          fmt.Sprintf("fake-task-%d", f.nextTaskID),
+		Endpoint:    endpoint,
+		BrokerIDs:   brokerIDs,
+		Status:      types.UserTaskStatusActive,
+		StartedAt:   time.Now(),
+		progression: append([]types.UserTaskStatus{}, f.TaskProgression...),
+	}
+	f.tasks = append(f.tasks, task)
+	return types.GenericResponse{
+		TaskID: task.ID,
+		Date:   task.StartedAt.UTC().Format(time.RFC1123),
+	}
+}
+
+// progress moves the task to its next status and applies its changes on the brokers once it completes, the lock
+// must be held
+func (f *FakeCruiseControlClient) progress(task *FakeUserTask) {
+	switch task.Status {
+	case types.UserTaskStatusCompleted, types.UserTaskStatusCompletedWithError:
+		return
+	}
+	if len(task.progression) == 0 {
+		task.Status = types.UserTaskStatusCompleted
+	} else {
+		task.Status = task.progression[0]
+		task.progression = task.progression[1:]
+	}
+	if task.Status != types.UserTaskStatusCompleted {
+		return
+	}
+
+	for _, brokerID := range task.BrokerIDs {
+		broker := f.broker(brokerID)
+		if broker == nil {
+			continue
+		}
+		switch task.Endpoint {
+		case api.EndpointRemoveBroker:
+			broker.Replicas = 0
+			for i := range broker.DiskReplicas {
+				broker.DiskReplicas[i] = 0
+			}
+		case api.EndpointRebalance:
+			if task.IntraBroker && broker.Replicas == 0 {
+				// the brokers without replicas have nothing to move between their disks
+				break
+			}
+			for i := range broker.DiskReplicas {
+				if broker.DiskReplicas[i] == 0 {
+					broker.DiskReplicas[i] = 1
+				}
+			}
+		case api.EndpointDemoteBroker:
+			broker.State = KafkaBrokerDemoted
+			broker.Leaders = 0
+		case EndpointRemoveDisks:
+			for i, logDir := range broker.OnlineLogDirs {
+				if i < len(broker.DiskReplicas) && util.StringSliceContains(task.LogDirs, logDir) {
+					broker.DiskReplicas[i] = 0
+				}
+			}
+		}
+	}
+}
+
+func (f *FakeCruiseControlClient) broker(id int32) *FakeBroker {
+	for i := range f.Brokers {
+		if f.Brokers[i].ID == id {
+			return &f.Brokers[i]
+		}
+	}
+	return nil
+}
+
+func (f *FakeCruiseControlClient) State(ctx context.Context, r *api.StateRequest) (*api.StateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.StateResponse{}
+	if err := f.request(ctx, api.EndpointState); err != nil {
+		return resp, err
+	}
+	if f.StateResult != nil {
+		result := *f.StateResult
+		resp.Result = &result
+		return resp, nil
+	}
+	resp.Result = &types.StateResult{
+		MonitorState:  types.LoadMonitorState{State: types.MonitorStateRunning, MonitoringCoveragePercentage: 100},
+		ExecutorState: types.ExecutorState{State: types.ExecutorStateTypeNoTaskInProgress},
+		AnalyzerState: types.AnalyzerState{IsProposalReady: true},
+	}
+	if f.paused {
+		resp.Result.MonitorState.State = types.MonitorStatePaused
+		resp.Result.MonitorState.ReasonOfLatestPauseOrResume = f.pauseReason
+	}
+	for _, task := range f.tasks {
+		if task.Status == types.UserTaskStatusInExecution {
+			resp.Result.ExecutorState.State = types.ExecutorStateTypeInterBrokerReplicaMovementTaskInProgress
+			resp.Result.ExecutorState.TriggeredUserTaskID = task.ID
+		}
+	}
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) UserTasks(ctx context.Context, r *api.UserTasksRequest) (*api.UserTasksResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.UserTasksResponse{}
+	if err := f.request(ctx, api.EndpointUserTasks); err != nil {
+		return resp, err
+	}
+
+	taskIDs := stringSliceToMap(r.UserTaskIDs)
+	resp.Result = &types.UserTaskState{}
+	for _, task := range f.tasks {
+		if len(taskIDs) > 0 && !taskIDs[task.ID] || len(r.Endpoints) > 0 && !endpointsContain(r.Endpoints, task.Endpoint) {
+			continue
+		}
+		f.progress(task)
+		if len(r.Types) > 0 && !userTaskStatusesContain(r.Types, task.Status) {
+			continue
+		}
+		if r.Entries > 0 && len(resp.Result.UserTasks) >= int(r.Entries) {
+			break
+		}
+		resp.Result.UserTasks = append(resp.Result.UserTasks, types.UserTaskInfo{
+			UserTaskID: task.ID,
+			StartMs:    types.DateTime{Time: task.StartedAt},
+			Status:     task.Status,
+		})
+	}
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) AddBroker(ctx context.Context, r *api.AddBrokerRequest) (*api.AddBrokerResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.AddBrokerResponse{}
+	if err := f.request(ctx, api.EndpointAddBroker); err != nil {
+		return resp, err
+	}
+	resp.GenericResponse = f.newTask(api.EndpointAddBroker, r.BrokerIDs)
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) RemoveBroker(ctx context.Context, r *api.RemoveBrokerRequest) (*api.RemoveBrokerResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.RemoveBrokerResponse{}
+	if err := f.request(ctx, api.EndpointRemoveBroker); err != nil {
+		return resp, err
+	}
+	resp.GenericResponse = f.newTask(api.EndpointRemoveBroker, r.BrokerIDs)
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) Rebalance(ctx context.Context, r *api.RebalanceRequest) (*api.RebalanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.RebalanceResponse{}
+	if err := f.request(ctx, api.EndpointRebalance); err != nil {
+		return resp, err
+	}
+	if !r.RebalanceDisk {
+		resp.GenericResponse = f.newTask(api.EndpointRebalance, r.DestinationBrokerIDs)
+		f.tasks[len(f.tasks)-1].ExcludedTopics = r.ExcludedTopics
+		return resp, nil
+	}
+	// the disks of all the brokers are rebalanced
+	brokerIDs := make([]int32, 0, len(f.Brokers))
+	for _, broker := range f.Brokers {
+		brokerIDs = append(brokerIDs, broker.ID)
+	}
+	resp.GenericResponse = f.newTask(api.EndpointRebalance, brokerIDs)
+	f.tasks[len(f.tasks)-1].IntraBroker = true
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) DemoteBroker(ctx context.Context, r *api.DemoteBrokerRequest) (*api.DemoteBrokerResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.DemoteBrokerResponse{}
+	if err := f.request(ctx, api.EndpointDemoteBroker); err != nil {
+		return resp, err
+	}
+	resp.GenericResponse = f.newTask(api.EndpointDemoteBroker, r.BrokerIDs)
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) KafkaClusterLoad(ctx context.Context, r *api.KafkaClusterLoadRequest) (*api.KafkaClusterLoadResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.KafkaClusterLoadResponse{}
+	if err := f.request(ctx, api.EndpointKafkaClusterLoad); err != nil {
+		return resp, err
+	}
+
+	resp.Result = &types.BrokerStats{}
+	for _, broker := range f.Brokers {
+		diskState := make([]types.DiskStats, 0, len(broker.DiskReplicas))
+		for _, replicas := range broker.DiskReplicas {
+			diskState = append(diskState, types.DiskStats{NumReplicas: replicas})
+		}
+		var diskPct float64
+		if broker.DiskCapacityMB > 0 {
+			diskPct = broker.DiskMB / broker.DiskCapacityMB * 100
+		}
+		resp.Result.Brokers = append(resp.Result.Brokers, types.BrokerLoadStats{
+			Broker:         broker.ID,
+			BrokerState:    broker.State,
+			Rack:           broker.Rack,
+			Replicas:       broker.Replicas,
+			DiskMB:         broker.DiskMB,
+			DiskCapacityMB: broker.DiskCapacityMB,
+			DiskPct:        diskPct,
+			Leaders:        broker.Leaders,
+			CPUPct:         broker.CPUPct,
+			LeaderNwInRate: broker.NwInRate,
+			NwOutRate:      broker.NwOutRate,
+			DiskState:      diskState,
+		})
+	}
+	return resp, nil
+}
+
+// KafkaClusterDiskLoad returns the load of the online and offline log dirs of the brokers. The replicas of DiskReplicas
+// are hosted by the online log dirs in the same order, and the disk usage of a broker is split evenly between them.
+func (f *FakeCruiseControlClient) KafkaClusterDiskLoad(ctx context.Context, r *api.KafkaClusterLoadRequest) (*KafkaClusterDiskLoadResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &KafkaClusterDiskLoadResponse{}
+	if err := f.request(ctx, api.EndpointKafkaClusterLoad); err != nil {
+		return resp, err
+	}
+
+	resp.Result = &KafkaClusterDiskLoadResult{}
+	for _, broker := range f.Brokers {
+		diskState := make(map[string]types.DiskStats, len(broker.OnlineLogDirs)+len(broker.OfflineLogDirs))
+		for idx, logDir := range broker.OnlineLogDirs {
+			stats := types.DiskStats{DiskMB: types.DiskUsageStat{Usage: broker.DiskMB / float64(len(broker.OnlineLogDirs))}}
+			if broker.DiskCapacityMB > 0 {
+				stats.DiskPct.Usage = broker.DiskMB / broker.DiskCapacityMB * 100
+			}
+			if idx < len(broker.DiskReplicas) {
+				stats.NumReplicas = broker.DiskReplicas[idx]
+			}
+			diskState[logDir] = stats
+		}
+		for _, logDir := range broker.OfflineLogDirs {
+			diskState[logDir] = types.DiskStats{DiskMB: types.DiskUsageStat{Dead: true}, DiskPct: types.DiskUsageStat{Dead: true}}
+		}
+		resp.Result.Brokers = append(resp.Result.Brokers, BrokerDiskLoad{Broker: broker.ID, DiskState: diskState})
+	}
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) KafkaClusterState(ctx context.Context, r *api.KafkaClusterStateRequest) (*api.KafkaClusterStateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.KafkaClusterStateResponse{}
+	if err := f.request(ctx, api.EndpointKafkaClusterState); err != nil {
+		return resp, err
+	}
+
+	brokerState := types.KafkaBrokerState{
+		ReplicaCountByBrokerID:   make(map[string]int32, len(f.Brokers)),
+		OnlineLogDirsByBrokerID:  make(map[string][]string, len(f.Brokers)),
+		OfflineLogDirsByBrokerID: make(map[string][]string, len(f.Brokers)),
+	}
+	for _, broker := range f.Brokers {
+		id := strconv.Itoa(int(broker.ID))
+		brokerState.ReplicaCountByBrokerID[id] = broker.Replicas
+		brokerState.OnlineLogDirsByBrokerID[id] = broker.OnlineLogDirs
+		brokerState.OfflineLogDirsByBrokerID[id] = broker.OfflineLogDirs
+	}
+	resp.Result = &types.KafkaClusterState{KafkaBrokerState: brokerState}
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) TopicConfiguration(ctx context.Context, r *api.TopicConfigurationRequest) (*api.TopicConfigurationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.TopicConfigurationResponse{}
+	if err := f.request(ctx, api.EndpointTopicConfiguration); err != nil {
+		return resp, err
+	}
+	resp.GenericResponse = f.newTask(api.EndpointTopicConfiguration, nil)
+	return resp, nil
+}
+
+// StopProposalExecution completes the user tasks in progress with error
+func (f *FakeCruiseControlClient) StopProposalExecution(ctx context.Context, r *api.StopProposalExecutionRequest) (*api.StopProposalExecutionResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.StopProposalExecutionResponse{}
+	if err := f.request(ctx, api.EndpointStopProposalExecution); err != nil {
+		return resp, err
+	}
+	for _, task := range f.tasks {
+		switch task.Status {
+		case types.UserTaskStatusActive, types.UserTaskStatusInExecution:
+			task.Status = types.UserTaskStatusCompletedWithError
+			task.progression = nil
+		}
+	}
+	resp.Result = &types.StopProposalResult{}
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) FixOfflineReplicas(ctx context.Context, r *api.FixOfflineReplicasRequest) (*api.FixOfflineReplicasResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.FixOfflineReplicasResponse{}
+	if err := f.request(ctx, api.EndpointFixOfflineReplicas); err != nil {
+		return resp, err
+	}
+	resp.GenericResponse = f.newTask(api.EndpointFixOfflineReplicas, nil)
+	return resp, nil
+}
+
+// RemoveDisks creates a user task emptying the given log dirs of the brokers once it completes
+func (f *FakeCruiseControlClient) RemoveDisks(ctx context.Context, r *RemoveDisksRequest) (*RemoveDisksResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &RemoveDisksResponse{}
+	if err := f.request(ctx, EndpointRemoveDisks); err != nil {
+		return resp, err
+	}
+	brokerIDs := make([]int32, 0, len(r.BrokerIDAndLogDirs))
+	var logDirs []string
+	for brokerID, dirs := range r.BrokerIDAndLogDirs {
+		brokerIDs = append(brokerIDs, brokerID)
+		logDirs = append(logDirs, dirs...)
+	}
+	resp.GenericResponse = f.newTask(EndpointRemoveDisks, brokerIDs)
+	f.tasks[len(f.tasks)-1].LogDirs = logDirs
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) Proposals(ctx context.Context, r *api.ProposalsRequest) (*api.ProposalsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.ProposalsResponse{}
+	if err := f.request(ctx, api.EndpointProposals); err != nil {
+		return resp, err
+	}
+	if f.ProposalsResult != nil {
+		result := *f.ProposalsResult
+		resp.Result = &result
+		return resp, nil
+	}
+	resp.Result = &types.OptimizationResult{
+		Summary: types.OptimizerResult{
+			OnDemandBalancednessScoreBefore: 100,
+			OnDemandBalancednessScoreAfter:  100,
+		},
+	}
+	return resp, nil
+}
+
+// PauseSampling pauses the sampling reported by the state endpoint unless its StateResult is set
+func (f *FakeCruiseControlClient) PauseSampling(ctx context.Context, r *api.PauseSamplingRequest) (*api.PauseSamplingResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.PauseSamplingResponse{}
+	if err := f.request(ctx, api.EndpointPauseSampling); err != nil {
+		return resp, err
+	}
+	f.paused, f.pauseReason = true, r.Reason
+	resp.Result = &types.SamplingResult{Message: "Metric sampling paused."}
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) ResumeSampling(ctx context.Context, r *api.ResumeSamplingRequest) (*api.ResumeSamplingResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.ResumeSamplingResponse{}
+	if err := f.request(ctx, api.EndpointResumeSampling); err != nil {
+		return resp, err
+	}
+	f.paused, f.pauseReason = false, ""
+	resp.Result = &types.SamplingResult{Message: "Metric sampling resumed."}
+	return resp, nil
+}
+
+// Review returns an empty review board, as two-step verification is not enabled in the fake Cruise Control
+func (f *FakeCruiseControlClient) Review(ctx context.Context, r *api.ReviewRequest) (*ReviewResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &ReviewResponse{}
+	if err := f.request(ctx, api.EndpointReview); err != nil {
+		return resp, err
+	}
+	resp.Result = &ReviewBoardResult{}
+	return resp, nil
+}
+
+// ReviewBoard returns an empty review board, as two-step verification is not enabled in the fake Cruise Control
+func (f *FakeCruiseControlClient) ReviewBoard(ctx context.Context, r *api.ReviewBoardRequest) (*ReviewResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &ReviewResponse{}
+	if err := f.request(ctx, api.EndpointReviewBoard); err != nil {
+		return resp, err
+	}
+	resp.Result = &ReviewBoardResult{}
+	return resp, nil
+}
+
+func (f *FakeCruiseControlClient) Rightsize(ctx context.Context, r *api.RightsizeRequest) (*api.RightsizeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.RightsizeResponse{}
+	if err := f.request(ctx, api.EndpointRightsize); err != nil {
+		return resp, err
+	}
+	if f.RightsizeResult != nil {
+		result := *f.RightsizeResult
+		resp.Result = &result
+		return resp, nil
+	}
+	resp.Result = &types.RightsizeResult{
+		NumberOfBrokersToAdd: r.NumberOfBrokersToAdd,
+		PartitionCount:       r.PartitionCount,
+		Topic:                r.Topic,
+		ProvisionerState:     types.ProvisionerStateCompleted,
+	}
+	return resp, nil
+}
+
+// Admin records the executor concurrency and the self-healing changed by the request, see Concurrency and
+// SelfHealing
+func (f *FakeCruiseControlClient) Admin(ctx context.Context, r *api.AdminRequest) (*api.AdminResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &api.AdminResponse{}
+	if err := f.request(ctx, api.EndpointAdmin); err != nil {
+		return resp, err
+	}
+	if r.ConcurrentPartitionMovementsPerBroker > 0 {
+		f.concurrency.PartitionMovementsPerBroker = r.ConcurrentPartitionMovementsPerBroker
+	}
+	if r.ConcurrentLeaderMovements > 0 {
+		f.concurrency.LeaderMovements = r.ConcurrentLeaderMovements
+	}
+	if r.ConcurrentIntraBrokerPartitionMovements > 0 {
+		f.concurrency.IntraBrokerPartitionMovements = r.ConcurrentIntraBrokerPartitionMovements
+	}
+	if len(r.EnableSelfHealingFor) > 0 || len(r.DisableSelfHealingFor) > 0 {
+		if f.selfHealing == nil {
+			f.selfHealing = make(map[string]bool)
+		}
+		for _, anomalyType := range r.EnableSelfHealingFor {
+			f.selfHealing[anomalyType.String()] = true
+		}
+		for _, anomalyType := range r.DisableSelfHealingFor {
+			f.selfHealing[anomalyType.String()] = false
+		}
+	}
+	resp.Result = &types.AdminResult{}
+	return resp, nil
+}
+
+// Concurrency returns the executor concurrency changed by the admin requests so far
+func (f *FakeCruiseControlClient) Concurrency() ExecutorConcurrency {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.concurrency
+}
+
+// SelfHealing returns whether the self-healing is enabled by the admin requests so far for each anomaly type changed
+func (f *FakeCruiseControlClient) SelfHealing() map[string]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	selfHealing := make(map[string]bool, len(f.selfHealing))
+	for anomalyType, enabled := range f.selfHealing {
+		selfHealing[anomalyType] = enabled
+	}
+	return selfHealing
+}
+
+func endpointsContain(endpoints []types.APIEndpoint, endpoint types.APIEndpoint) bool {
+	for _, e := range endpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+func userTaskStatusesContain(statuses []types.UserTaskStatus, status types.UserTaskStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+)
+
+var (
+	clusterMetricLabels = []string{"namespace", "kafka_cr"}
+	requestMetricLabels = []string{"namespace", "kafka_cr", "endpoint"}
+
+	cruiseControlRequestsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_operator_cruise_control_requests_total",
+		Help: "Number of requests sent to Cruise Control by endpoint",
+	}, requestMetricLabels)
+	cruiseControlRequestErrorsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_operator_cruise_control_request_errors_total",
+		Help: "Number of requests sent to Cruise Control by endpoint which failed, including their retries",
+	}, requestMetricLabels)
+	cruiseControlRequestDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kafka_operator_cruise_control_request_duration_seconds",
+		Help:    "Duration of the requests sent to Cruise Control by endpoint, including their retries",
+		Buckets: prometheus.DefBuckets,
+	}, requestMetricLabels)
+	cruiseControlMonitoredWindowsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_cruise_control_monitored_windows",
+		Help: "Number of load monitoring windows of Cruise Control",
+	}, clusterMetricLabels)
+	cruiseControlMonitoringCoverageGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_operator_cruise_control_monitoring_coverage_percent",
+		Help: "Percentage of the partitions covered by the load monitor of Cruise Control",
+	}, clusterMetricLabels)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		cruiseControlRequestsCounter,
+		cruiseControlRequestErrorsCounter,
+		cruiseControlRequestDurationHistogram,
+		cruiseControlMonitoredWindowsGauge,
+		cruiseControlMonitoringCoverageGauge,
+	)
+}
+
+// WithMetricLabels sets the namespace and name of the KafkaCluster which the metrics of the requests sent by the client
+// are labelled with
+func WithMetricLabels(namespace, kafkaCR string) ClientOption {
+	return func(c *clientConfig) {
+		c.metricLabels = prometheus.Labels{"namespace": namespace, "kafka_cr": kafkaCR}
+	}
+}
+
+// requestLabels returns the labels of the metrics of the requests sent to the endpoint
+func (c *httpClient) requestLabels(endpoint types.APIEndpoint) prometheus.Labels {
+	return prometheus.Labels{
+		"namespace": c.metricLabels["namespace"],
+		"kafka_cr":  c.metricLabels["kafka_cr"],
+		"endpoint":  endpoint.Path(),
+	}
+}
+
+// observeRequest records the outcome and duration of a request sent to the endpoint
+func (c *httpClient) observeRequest(endpoint types.APIEndpoint, start time.Time, err error) {
+	labels := c.requestLabels(endpoint)
+	cruiseControlRequestsCounter.With(labels).Inc()
+	if err != nil {
+		cruiseControlRequestErrorsCounter.With(labels).Inc()
+	}
+	cruiseControlRequestDurationHistogram.With(labels).Observe(time.Since(start).Seconds())
+}
+
+// observeMonitorState records the state of the load monitor of Cruise Control
+func (c *httpClient) observeMonitorState(state types.LoadMonitorState) {
+	labels := prometheus.Labels{"namespace": c.metricLabels["namespace"], "kafka_cr": c.metricLabels["kafka_cr"]}
+	cruiseControlMonitoredWindowsGauge.With(labels).Set(float64(state.NumMonitoredWindows))
+	cruiseControlMonitoringCoverageGauge.With(labels).Set(state.MonitoringCoveragePercentage)
+}
@@ -16,58 +16,151 @@ package scale
 
 import (
 	"context"
+
+	"github.com/go-logr/logr"
 )
 
 func MockNewCruiseControlScaler() {
 	newCruiseControlScaler = createMockCruiseControlScaler
+	DefaultScalerRegistry.Reset()
+}
+
+// MockNewCruiseControlScalerWithClient makes NewCruiseControlScaler return scalers using the given client, e.g. a
+// FakeCruiseControlClient, instead of connecting to Cruise Control
+func MockNewCruiseControlScalerWithClient(cruisecontrol CruiseControlClient) {
+	newCruiseControlScaler = func(ctx context.Context, _ string, _ ...ClientOption) (CruiseControlScaler, error) {
+		return NewCruiseControlScalerWithClient(logr.FromContextOrDiscard(ctx).WithName("Scaler"), cruisecontrol), nil
+	}
+	DefaultScalerRegistry.Reset()
 }
 
-func createMockCruiseControlScaler(_ context.Context, _ string) (CruiseControlScaler, error) {
+// MockNewCruiseControlScalerWith makes NewCruiseControlScaler return the given scaler, e.g. a fake.Scaler, instead of
+// connecting to Cruise Control
+func MockNewCruiseControlScalerWith(scaler CruiseControlScaler) {
+	newCruiseControlScaler = func(context.Context, string, ...ClientOption) (CruiseControlScaler, error) {
+		return scaler, nil
+	}
+	DefaultScalerRegistry.Reset()
+}
+
+func createMockCruiseControlScaler(_ context.Context, _ string, _ ...ClientOption) (CruiseControlScaler, error) {
 	return &mockCruiseControlScaler{}, nil
 }
 
 type mockCruiseControlScaler struct{}
 
-func (mc *mockCruiseControlScaler) IsReady() bool {
+func (mc *mockCruiseControlScaler) IsReady(ctx context.Context) bool {
 	return true
 }
 
-func (mc *mockCruiseControlScaler) Status() CruiseControlStatus {
+func (mc *mockCruiseControlScaler) Status(ctx context.Context) CruiseControlStatus {
 	return CruiseControlStatus{}
 }
 
-func (mc *mockCruiseControlScaler) GetUserTasks(taskIDs ...string) ([]*Result, error) {
+func (mc *mockCruiseControlScaler) GetUserTasks(ctx context.Context, taskIDs ...string) ([]*Result, error) {
+	return []*Result{}, nil
+}
+
+func (mc *mockCruiseControlScaler) ListUserTasks(ctx context.Context, filter UserTaskFilter) ([]*Result, error) {
 	return []*Result{}, nil
 }
 
-func (mc *mockCruiseControlScaler) IsUp() bool {
+func (mc *mockCruiseControlScaler) IsUp(ctx context.Context) bool {
 	return true
 }
 
-func (mc *mockCruiseControlScaler) AddBrokers(brokerIDs ...string) (*Result, error) {
+func (mc *mockCruiseControlScaler) AddBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (mc *mockCruiseControlScaler) RemoveBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
 	return &Result{}, nil
 }
 
-func (mc *mockCruiseControlScaler) RemoveBrokers(brokerIDs ...string) (*Result, error) {
+func (mc *mockCruiseControlScaler) RebalanceDisks(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
 	return &Result{}, nil
 }
 
-func (mc *mockCruiseControlScaler) RebalanceDisks(brokerIDs ...string) (*Result, error) {
+func (mc *mockCruiseControlScaler) IntraBrokerRebalance(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
 	return &Result{}, nil
 }
 
-func (mc *mockCruiseControlScaler) BrokersWithState(states ...KafkaBrokerState) ([]string, error) {
+func (mc *mockCruiseControlScaler) BrokersWithState(ctx context.Context, states ...KafkaBrokerState) ([]string, error) {
 	return []string{}, nil
 }
 
-func (mc *mockCruiseControlScaler) PartitionReplicasByBroker() (map[string]int32, error) {
+func (mc *mockCruiseControlScaler) PartitionReplicasByBroker(ctx context.Context) (map[string]int32, error) {
 	return map[string]int32{}, nil
 }
 
-func (mc *mockCruiseControlScaler) BrokerWithLeastPartitionReplicas() (string, error) {
+func (mc *mockCruiseControlScaler) BrokerWithLeastPartitionReplicas(ctx context.Context, mode BrokerSelectionMode) (string, error) {
 	return "", nil
 }
 
-func (mc *mockCruiseControlScaler) LogDirsByBroker() (map[string]map[LogDirState][]string, error) {
-	return make(map[string]map[LogDirState][]string), nil
+func (mc *mockCruiseControlScaler) LogDirsByBroker(ctx context.Context) (map[string]map[LogDirState][]LogDir, error) {
+	return make(map[string]map[LogDirState][]LogDir), nil
+}
+
+func (mc *mockCruiseControlScaler) DiskUsageByBroker(ctx context.Context) (map[string]DiskUsage, error) {
+	return make(map[string]DiskUsage), nil
+}
+
+func (mc *mockCruiseControlScaler) BrokerLoads(ctx context.Context) (map[string]BrokerLoad, error) {
+	return make(map[string]BrokerLoad), nil
+}
+
+func (mc *mockCruiseControlScaler) GoalViolations(ctx context.Context) ([]GoalViolation, error) {
+	return nil, nil
+}
+
+func (mc *mockCruiseControlScaler) RebalanceWithGoals(ctx context.Context, excludedBrokerIDs []string, topics string, goals ...string) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (mc *mockCruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (mc *mockCruiseControlScaler) UpdateTopicReplicationFactor(ctx context.Context, topic string, replicationFactor int32) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (mc *mockCruiseControlScaler) StopExecution(ctx context.Context) error {
+	return nil
+}
+
+func (mc *mockCruiseControlScaler) FixOfflineReplicas(ctx context.Context) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (mc *mockCruiseControlScaler) RemoveDisks(ctx context.Context, brokerID string, logDirs []string) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (mc *mockCruiseControlScaler) GetProposals(ctx context.Context, goals ...string) (*ProposalSummary, error) {
+	return &ProposalSummary{}, nil
+}
+
+func (mc *mockCruiseControlScaler) PauseSampling(ctx context.Context, reason string) error {
+	return nil
+}
+
+func (mc *mockCruiseControlScaler) ResumeSampling(ctx context.Context, reason string) error {
+	return nil
+}
+
+func (mc *mockCruiseControlScaler) UpdateExecutorConcurrency(ctx context.Context, concurrency ExecutorConcurrency) error {
+	return nil
+}
+
+func (mc *mockCruiseControlScaler) UpdateSelfHealing(ctx context.Context, enabled, disabled []string) error {
+	return nil
+}
+
+func (mc *mockCruiseControlScaler) Rightsize(ctx context.Context, opts RightsizeOptions) (*RightsizeResult, error) {
+	return &RightsizeResult{}, nil
+}
+
+func (mc *mockCruiseControlScaler) WatchTask(ctx context.Context, taskID string) <-chan TaskUpdate {
+	return PollTask(ctx, mc, taskID, DefaultPollConfig)
 }
@@ -0,0 +1,335 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// DefaultOperationQueueExpiry is the time an operation stays queued without being requested again, it is longer than
+// the period of any of the controllers starting Cruise Control tasks
+const DefaultOperationQueueExpiry = 10 * time.Minute
+
+// ErrOperationQueued is returned if the task of an operation was not started as other operations of the cluster are
+// queued ahead of it or Cruise Control is executing a task. It wraps ErrCruiseControlUnavailable, so the operation is
+// requested again like the ones not started for Cruise Control being unavailable.
+var ErrOperationQueued = fmt.Errorf("%w: the operation is queued", ErrCruiseControlUnavailable)
+
+// DefaultOperationQueue is the OperationQueue shared by the controllers of the operator
+var DefaultOperationQueue = NewOperationQueue(DefaultOperationQueueExpiry)
+
+// OperationTrigger tells who asked for an operation, the operations asked for by the users come first
+type OperationTrigger int
+
+const (
+	// TriggerUser is the trigger of the operations following a change made by the users, e.g. adding brokers or
+	// creating a CruiseControlOperation
+	TriggerUser OperationTrigger = iota
+	// TriggerPeriodic is the trigger of the operations the operator starts on its own, e.g. goal violation remediations
+	TriggerPeriodic
+)
+
+// taskType is the type of the Cruise Control task started by an operation
+type taskType string
+
+const (
+	taskFixOfflineReplicas           taskType = "fix_offline_replicas"
+	taskRemoveBrokers                taskType = "remove_brokers"
+	taskRemoveDisks                  taskType = "remove_disks"
+	taskDemoteBrokers                taskType = "demote_brokers"
+	taskAddBrokers                   taskType = "add_brokers"
+	taskRebalanceDisks               taskType = "rebalance_disks"
+	taskUpdateTopicReplicationFactor taskType = "update_topic_replication_factor"
+	taskRebalance                    taskType = "rebalance"
+)
+
+// taskTypesByPriority lists the types of the tasks in the order they are started when several operations with the same
+// trigger are queued: the tasks restoring replicas and moving them off the brokers come first, the rebalances last
+var taskTypesByPriority = []taskType{
+	taskFixOfflineReplicas,
+	taskRemoveBrokers,
+	taskRemoveDisks,
+	taskDemoteBrokers,
+	taskAddBrokers,
+	taskRebalanceDisks,
+	taskUpdateTopicReplicationFactor,
+	taskRebalance,
+}
+
+// operationPriority returns the priority of an operation, the operations with lower priority are started first
+func operationPriority(trigger OperationTrigger, task taskType) int {
+	for i, t := range taskTypesByPriority {
+		if t == task {
+			return int(trigger)*len(taskTypesByPriority) + i
+		}
+	}
+	return (int(trigger) + 1) * len(taskTypesByPriority)
+}
+
+// OperationQueue serializes the Cruise Control tasks started by the controllers of the operator. Cruise Control
+// executes one task at a time, so each controller queues its operation for the cluster and only the operation with
+// the highest priority may start its task, once Cruise Control finished executing the previous one. Each controller
+// has at most one operation queued per cluster, which is requested again on every reconcile until its task is started.
+type OperationQueue struct {
+	mu       sync.Mutex
+	clusters map[types.NamespacedName]*clusterQueue
+	expiry   time.Duration
+	now      func() time.Time
+}
+
+// clusterQueue holds the operations queued for a cluster by their requester
+type clusterQueue struct {
+	// mu is held while the task of an operation is started
+	mu         sync.Mutex
+	operations map[string]*queuedOperation
+	// activeTaskID is the ID of the last task started through the queue until it is seen finished
+	activeTaskID string
+}
+
+type queuedOperation struct {
+	requester  string
+	priority   int
+	queuedAt   time.Time
+	lastSeenAt time.Time
+}
+
+// NewOperationQueue returns an empty OperationQueue, the operations not requested again within the expiry are dropped
+func NewOperationQueue(expiry time.Duration) *OperationQueue {
+	return &OperationQueue{
+		clusters: make(map[types.NamespacedName]*clusterQueue),
+		expiry:   expiry,
+		now:      time.Now,
+	}
+}
+
+// Queued returns the requesters of the operations queued for the cluster in the order their tasks are started
+func (q *OperationQueue) Queued(namespace, name string) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	cluster, ok := q.clusters[types.NamespacedName{Namespace: namespace, Name: name}]
+	if !ok {
+		return nil
+	}
+	operations := cluster.ordered(q.now().Add(-q.expiry))
+	requesters := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		requesters = append(requesters, operation.requester)
+	}
+	return requesters
+}
+
+// Forget drops the operations queued for the cluster, e.g. once it is deleted
+func (q *OperationQueue) Forget(namespace, name string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.clusters, types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+// Remove drops the operation of the requester queued for the cluster, e.g. once it is paused, so the operations
+// queued behind it are not held back until it expires
+func (q *OperationQueue) Remove(namespace, name, requester string) {
+	q.dequeue(types.NamespacedName{Namespace: namespace, Name: name}, requester)
+}
+
+// enqueue queues the operation of the requester or refreshes it if it is queued already, and returns the queue of the
+// cluster with the requester of the operation at its head
+func (q *OperationQueue) enqueue(cluster types.NamespacedName, requester string, priority int) (*clusterQueue, string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue, ok := q.clusters[cluster]
+	if !ok {
+		queue = &clusterQueue{operations: make(map[string]*queuedOperation)}
+		q.clusters[cluster] = queue
+	}
+	now := q.now()
+	operation, ok := queue.operations[requester]
+	if !ok || operation.priority != priority {
+		operation = &queuedOperation{requester: requester, priority: priority, queuedAt: now}
+		queue.operations[requester] = operation
+	}
+	operation.lastSeenAt = now
+	return queue, queue.ordered(now.Add(-q.expiry))[0].requester
+}
+
+// dequeue removes the operation of the requester from the queue of the cluster
+func (q *OperationQueue) dequeue(cluster types.NamespacedName, requester string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if queue, ok := q.clusters[cluster]; ok {
+		delete(queue.operations, requester)
+	}
+}
+
+// ordered drops the operations not requested since the given time and returns the rest in the order their tasks are
+// started, the lock of the OperationQueue must be held
+func (c *clusterQueue) ordered(since time.Time) []*queuedOperation {
+	operations := make([]*queuedOperation, 0, len(c.operations))
+	for requester, operation := range c.operations {
+		if operation.lastSeenAt.Before(since) {
+			delete(c.operations, requester)
+			continue
+		}
+		operations = append(operations, operation)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].priority != operations[j].priority {
+			return operations[i].priority < operations[j].priority
+		}
+		if !operations[i].queuedAt.Equal(operations[j].queuedAt) {
+			return operations[i].queuedAt.Before(operations[j].queuedAt)
+		}
+		return operations[i].requester < operations[j].requester
+	})
+	return operations
+}
+
+// start starts the task of the operation if it is at the head of the queue of the cluster and Cruise Control is not
+// executing another task. The operation is removed from the queue unless its task could not be started for Cruise
+// Control being unavailable.
+func (q *OperationQueue) start(ctx context.Context, scaler CruiseControlScaler, cluster types.NamespacedName,
+	requester string, priority int, startTask func() (*Result, error)) (*Result, error) {
+	queue, head := q.enqueue(cluster, requester, priority)
+	if head != requester {
+		return queuedResult(fmt.Errorf("%w behind the operation of %s", ErrOperationQueued, head))
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if queue.activeTaskID != "" {
+		tasks, err := scaler.GetUserTasks(ctx, queue.activeTaskID)
+		if err != nil {
+			return queuedResult(fmt.Errorf("%w: the state of task %s is unknown: %s", ErrOperationQueued, queue.activeTaskID, err))
+		}
+		for _, task := range tasks {
+			if task.TaskID == queue.activeTaskID && (task.State == v1beta1.CruiseControlTaskActive ||
+				task.State == v1beta1.CruiseControlTaskInExecution) {
+				return queuedResult(fmt.Errorf("%w until task %s finishes", ErrOperationQueued, queue.activeTaskID))
+			}
+		}
+		queue.activeTaskID = ""
+	}
+	if scaler.Status(ctx).InExecution() {
+		return queuedResult(fmt.Errorf("%w until Cruise Control finishes its ongoing execution", ErrOperationQueued))
+	}
+
+	result, err := startTask()
+	if err != nil && errors.Is(err, ErrCruiseControlUnavailable) {
+		return result, err
+	}
+	q.dequeue(cluster, requester)
+	if err == nil && result != nil {
+		queue.activeTaskID = result.TaskID
+	}
+	return result, err
+}
+
+// queuedResult returns the result of an operation whose task was not started as it is queued
+func queuedResult(err error) (*Result, error) {
+	return &Result{State: v1beta1.CruiseControlTaskUnavailable, Err: err.Error()}, err
+}
+
+// queuedCruiseControlScaler starts the tasks of the wrapped scaler through the OperationQueue
+type queuedCruiseControlScaler struct {
+	CruiseControlScaler
+
+	queue     *OperationQueue
+	cluster   types.NamespacedName
+	requester string
+	trigger   OperationTrigger
+}
+
+// QueuedCruiseControlScaler returns a CruiseControlScaler starting the tasks of the wrapped scaler through the queue of
+// the cluster. The requester identifies the controller the operations are queued for, the operations which could
+// not be started yet return a Result in the v1beta1.CruiseControlTaskUnavailable state with an error wrapping
+// ErrOperationQueued.
+func QueuedCruiseControlScaler(queue *OperationQueue, cluster *v1beta1.KafkaCluster, requester string,
+	trigger OperationTrigger, scaler CruiseControlScaler) CruiseControlScaler {
+	return &queuedCruiseControlScaler{
+		CruiseControlScaler: scaler,
+		queue:               queue,
+		cluster:             types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name},
+		requester:           requester,
+		trigger:             trigger,
+	}
+}
+
+func (s *queuedCruiseControlScaler) start(ctx context.Context, task taskType, startTask func() (*Result, error)) (*Result, error) {
+	return s.queue.start(ctx, s.CruiseControlScaler, s.cluster, s.requester, operationPriority(s.trigger, task), startTask)
+}
+
+func (s *queuedCruiseControlScaler) AddBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	return s.start(ctx, taskAddBrokers, func() (*Result, error) {
+		return s.CruiseControlScaler.AddBrokers(ctx, opts, brokerIDs...)
+	})
+}
+
+func (s *queuedCruiseControlScaler) RemoveBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	return s.start(ctx, taskRemoveBrokers, func() (*Result, error) {
+		return s.CruiseControlScaler.RemoveBrokers(ctx, opts, brokerIDs...)
+	})
+}
+
+func (s *queuedCruiseControlScaler) RebalanceDisks(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	return s.start(ctx, taskRebalanceDisks, func() (*Result, error) {
+		return s.CruiseControlScaler.RebalanceDisks(ctx, opts, brokerIDs...)
+	})
+}
+
+func (s *queuedCruiseControlScaler) IntraBrokerRebalance(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	return s.start(ctx, taskRebalanceDisks, func() (*Result, error) {
+		return s.CruiseControlScaler.IntraBrokerRebalance(ctx, opts, brokerIDs...)
+	})
+}
+
+func (s *queuedCruiseControlScaler) RebalanceWithGoals(ctx context.Context, excludedBrokerIDs []string, topics string, goals ...string) (*Result, error) {
+	return s.start(ctx, taskRebalance, func() (*Result, error) {
+		return s.CruiseControlScaler.RebalanceWithGoals(ctx, excludedBrokerIDs, topics, goals...)
+	})
+}
+
+func (s *queuedCruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*Result, error) {
+	return s.start(ctx, taskDemoteBrokers, func() (*Result, error) {
+		return s.CruiseControlScaler.DemoteBrokers(ctx, brokerIDs...)
+	})
+}
+
+func (s *queuedCruiseControlScaler) UpdateTopicReplicationFactor(ctx context.Context, topic string, replicationFactor int32) (*Result, error) {
+	return s.start(ctx, taskUpdateTopicReplicationFactor, func() (*Result, error) {
+		return s.CruiseControlScaler.UpdateTopicReplicationFactor(ctx, topic, replicationFactor)
+	})
+}
+
+func (s *queuedCruiseControlScaler) FixOfflineReplicas(ctx context.Context) (*Result, error) {
+	return s.start(ctx, taskFixOfflineReplicas, func() (*Result, error) {
+		return s.CruiseControlScaler.FixOfflineReplicas(ctx)
+	})
+}
+
+func (s *queuedCruiseControlScaler) RemoveDisks(ctx context.Context, brokerID string, logDirs []string) (*Result, error) {
+	return s.start(ctx, taskRemoveDisks, func() (*Result, error) {
+		return s.CruiseControlScaler.RemoveDisks(ctx, brokerID, logDirs)
+	})
+}
@@ -0,0 +1,138 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// queueTestScaler starts tasks which stay active until they are completed by the test
+type queueTestScaler struct {
+	mockCruiseControlScaler
+	tasks map[string]v1beta1.CruiseControlUserTaskState
+}
+
+func (s *queueTestScaler) Status(ctx context.Context) CruiseControlStatus {
+	return CruiseControlStatus{ExecutorReady: true}
+}
+
+func (s *queueTestScaler) GetUserTasks(ctx context.Context, taskIDs ...string) ([]*Result, error) {
+	results := make([]*Result, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		if state, ok := s.tasks[id]; ok {
+			results = append(results, &Result{TaskID: id, State: state})
+		}
+	}
+	return results, nil
+}
+
+func (s *queueTestScaler) startTask() (*Result, error) {
+	id := fmt.Sprintf("task-%d", len(s.tasks)+1)
+	s.tasks[id] = v1beta1.CruiseControlTaskActive
+	return &Result{TaskID: id, State: v1beta1.CruiseControlTaskActive}, nil
+}
+
+func (s *queueTestScaler) RemoveBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	return s.startTask()
+}
+
+func (s *queueTestScaler) RebalanceWithGoals(ctx context.Context, excludedBrokerIDs []string, topics string, goals ...string) (*Result, error) {
+	return s.startTask()
+}
+
+func TestOperationQueue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	queue := NewOperationQueue(10 * time.Minute)
+	queue.now = func() time.Time { return now }
+
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	cc := &queueTestScaler{tasks: make(map[string]v1beta1.CruiseControlUserTaskState)}
+	remediation := QueuedCruiseControlScaler(queue, cluster, "remediation", TriggerPeriodic, cc)
+	topicRebalance := QueuedCruiseControlScaler(queue, cluster, "topicrebalance", TriggerPeriodic, cc)
+	tasks := QueuedCruiseControlScaler(queue, cluster, "tasks", TriggerUser, cc)
+
+	result, err := remediation.RebalanceWithGoals(ctx, nil, "")
+	if err != nil || result.TaskID != "task-1" {
+		t.Fatalf("expected the rebalance to be started, got: %+v, %v", result, err)
+	}
+
+	// the operations are queued while the task is active
+	now = now.Add(time.Second)
+	if result, err = topicRebalance.RebalanceWithGoals(ctx, nil, "events"); !errors.Is(err, ErrOperationQueued) ||
+		result.State != v1beta1.CruiseControlTaskUnavailable {
+		t.Fatalf("expected the rebalance to be queued, got: %+v, %v", result, err)
+	}
+	if !errors.Is(err, ErrCruiseControlUnavailable) {
+		t.Error("expected the queued operation to be reported like Cruise Control being unavailable")
+	}
+	now = now.Add(time.Second)
+	if _, err = tasks.RemoveBrokers(ctx, OperationOptions{}, "2"); !errors.Is(err, ErrOperationQueued) {
+		t.Fatalf("expected the broker removal to be queued, got: %v", err)
+	}
+	if queued := queue.Queued("kafka", "kafka"); !reflect.DeepEqual(queued, []string{"tasks", "topicrebalance"}) {
+		t.Errorf("expected the broker removal the user asked for to be ahead of the rebalance, got: %v", queued)
+	}
+
+	// the operation at the head of the queue is started once the task finished
+	cc.tasks["task-1"] = v1beta1.CruiseControlTaskCompleted
+	if _, err = topicRebalance.RebalanceWithGoals(ctx, nil, "events"); !errors.Is(err, ErrOperationQueued) {
+		t.Fatalf("expected the rebalance to stay queued behind the broker removal, got: %v", err)
+	}
+	if result, err = tasks.RemoveBrokers(ctx, OperationOptions{}, "2"); err != nil || result.TaskID != "task-2" {
+		t.Fatalf("expected the broker removal to be started, got: %+v, %v", result, err)
+	}
+	if queued := queue.Queued("kafka", "kafka"); !reflect.DeepEqual(queued, []string{"topicrebalance"}) {
+		t.Errorf("expected the started operation to be removed from the queue, got: %v", queued)
+	}
+
+	// the removed operations, e.g. the paused ones, are queued again when they are requested
+	queue.Remove("kafka", "kafka", "topicrebalance")
+	if queued := queue.Queued("kafka", "kafka"); len(queued) != 0 {
+		t.Errorf("expected the removed operation to be dropped from the queue, got: %v", queued)
+	}
+	if _, err = topicRebalance.RebalanceWithGoals(ctx, nil, "events"); !errors.Is(err, ErrOperationQueued) {
+		t.Fatalf("expected the rebalance to be queued again, got: %v", err)
+	}
+
+	// the operations not requested again expire
+	now = now.Add(11 * time.Minute)
+	if queued := queue.Queued("kafka", "kafka"); len(queued) != 0 {
+		t.Errorf("expected the queued operation to expire, got: %v", queued)
+	}
+
+	queue.Forget("kafka", "kafka")
+	if queued := queue.Queued("kafka", "kafka"); queued != nil {
+		t.Errorf("expected the queue of the forgotten cluster to be dropped, got: %v", queued)
+	}
+}
+
+func TestOperationPriority(t *testing.T) {
+	if operationPriority(TriggerUser, taskRemoveBrokers) >= operationPriority(TriggerUser, taskRebalance) {
+		t.Error("expected removing brokers to come before rebalancing")
+	}
+	if operationPriority(TriggerUser, taskRebalance) >= operationPriority(TriggerPeriodic, taskFixOfflineReplicas) {
+		t.Error("expected the operations the users asked for to come before the periodic ones")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// DefaultScalerRegistry is the ScalerRegistry shared by the controllers of the operator
+var DefaultScalerRegistry = NewScalerRegistry()
+
+// ScalerRegistry caches a CruiseControlScaler for each KafkaCluster, so that the client connecting to its Cruise
+// Control and the state cached by the scaler are reused between reconciles instead of being created again. The scaler
+// of a cluster is replaced once its Cruise Control URL, its client config or the Secrets holding the credentials of
+// the client change.
+type ScalerRegistry struct {
+	mu      sync.Mutex
+	scalers map[types.NamespacedName]*registeredScaler
+}
+
+type registeredScaler struct {
+	scaler CruiseControlScaler
+	// fingerprint identifies the config of the client the scaler was created with
+	fingerprint string
+}
+
+// NewScalerRegistry returns an empty ScalerRegistry
+func NewScalerRegistry() *ScalerRegistry {
+	return &ScalerRegistry{
+		scalers: make(map[types.NamespacedName]*registeredScaler),
+	}
+}
+
+// Scaler returns the built-in rebalancer of the cluster when it is selected by spec.rebalancer and the cached
+// CruiseControlScaler connecting to the Cruise Control of the cluster otherwise. The built-in rebalancer is not
+// cached as it opens a new Kafka client for every operation anyway.
+func (r *ScalerRegistry) Scaler(ctx context.Context, c client.Client, cluster *v1beta1.KafkaCluster) (CruiseControlScaler, error) {
+	if cluster.Spec.UsesBuiltInRebalancer() {
+		return NewScalerFromKafkaCluster(ctx, c, cluster)
+	}
+	return r.CruiseControlScaler(ctx, c, cluster)
+}
+
+// CruiseControlScaler returns the cached CruiseControlScaler connecting to the Cruise Control of the cluster, a new
+// one is created if there is none yet or its client config changed
+func (r *ScalerRegistry) CruiseControlScaler(ctx context.Context, reader client.Reader, cluster *v1beta1.KafkaCluster) (CruiseControlScaler, error) {
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	fingerprint, err := clientFingerprint(ctx, reader, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if registered, ok := r.scalers[key]; ok && registered.fingerprint == fingerprint {
+		return registered.scaler, nil
+	}
+	scaler, err := NewCruiseControlScalerFromKafkaCluster(ctx, reader, cluster)
+	if err != nil {
+		return nil, err
+	}
+	r.scalers[key] = &registeredScaler{scaler: scaler, fingerprint: fingerprint}
+	return scaler, nil
+}
+
+// Forget drops the cached scaler of the cluster, e.g. once the cluster is deleted
+func (r *ScalerRegistry) Forget(namespace, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.scalers, types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+// Reset drops all cached scalers
+func (r *ScalerRegistry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scalers = make(map[types.NamespacedName]*registeredScaler)
+}
+
+// clientFingerprint returns a string identifying the config of the client connecting to the Cruise Control of the
+// cluster: its URL, its authentication, TLS and proxy config and the versions of the Secrets they reference
+func clientFingerprint(ctx context.Context, reader client.Reader, cluster *v1beta1.KafkaCluster) (string, error) {
+	ccConfig := cluster.Spec.CruiseControlConfig
+	secretVersions := make(map[string]string)
+	for _, name := range clientSecretNames(ccConfig) {
+		secret := &corev1.Secret{}
+		err := reader.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, secret)
+		if client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		// a missing Secret is reported by the client once it needs it
+		secretVersions[name] = secret.ResourceVersion
+	}
+
+	fingerprint, err := json.Marshal(struct {
+		URL            string                               `json:"url"`
+		Authentication *v1beta1.CruiseControlAuthentication `json:"authentication,omitempty"`
+		ClientTLS      *v1beta1.CruiseControlClientTLS      `json:"clientTLS,omitempty"`
+		Proxy          *v1beta1.CruiseControlClientProxy    `json:"proxy,omitempty"`
+		SecretVersions map[string]string                    `json:"secretVersions,omitempty"`
+	}{
+		URL:            CruiseControlURLFromKafkaCluster(cluster),
+		Authentication: ccConfig.Authentication,
+		ClientTLS:      ccConfig.ClientTLS,
+		Proxy:          ccConfig.Proxy,
+		SecretVersions: secretVersions,
+	})
+	return string(fingerprint), err
+}
+
+// clientSecretNames returns the names of the Secrets referenced by the authentication, TLS and proxy config of the
+// client
+func clientSecretNames(ccConfig v1beta1.CruiseControlConfig) []string {
+	var names []string
+	if auth := ccConfig.Authentication; auth != nil {
+		if auth.BearerTokenSecretRef != nil {
+			names = append(names, auth.BearerTokenSecretRef.Name)
+		}
+		if auth.TokenExchange != nil {
+			names = append(names, auth.TokenExchange.ClientSecretRef.Name)
+		}
+		if auth.BasicAuth != nil {
+			names = append(names, auth.BasicAuth.PasswordSecretRef.Name)
+		}
+	}
+	if clientTLS := ccConfig.ClientTLS; clientTLS != nil {
+		if clientTLS.CASecretRef != nil {
+			names = append(names, clientTLS.CASecretRef.Name)
+		}
+		if clientTLS.ClientCertSecretName != "" {
+			names = append(names, clientTLS.ClientCertSecretName)
+		}
+	}
+	if proxy := ccConfig.Proxy; proxy != nil && proxy.CredentialsSecretName != "" {
+		names = append(names, proxy.CredentialsSecretName)
+	}
+	return names
+}
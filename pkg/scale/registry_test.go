@@ -0,0 +1,100 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestScalerRegistry(t *testing.T) {
+	MockNewCruiseControlScalerWithClient(newFakeCluster())
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				CruiseControlEndpoint: "cruisecontrol:8090",
+				Authentication: &v1beta1.CruiseControlAuthentication{
+					BasicAuth: &v1beta1.CruiseControlBasicAuth{
+						Username: "operator",
+						PasswordSecretRef: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "cc-password"},
+							Key:                  "password",
+						},
+					},
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-password", Namespace: "kafka"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+	registry := NewScalerRegistry()
+
+	scaler, err := registry.CruiseControlScaler(context.TODO(), c, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cached, err := registry.CruiseControlScaler(context.TODO(), c, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cached != scaler {
+		t.Error("expected the scaler to be reused")
+	}
+
+	// the password is rotated
+	secret.Data["password"] = []byte("rotated")
+	if err := c.Update(context.TODO(), secret); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rotated, err := registry.CruiseControlScaler(context.TODO(), c, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rotated == scaler {
+		t.Error("expected a new scaler after the credentials were rotated")
+	}
+
+	cluster.Spec.CruiseControlConfig.CruiseControlEndpoint = "cruisecontrol-new:8090"
+	moved, err := registry.CruiseControlScaler(context.TODO(), c, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if moved == rotated {
+		t.Error("expected a new scaler after the URL of Cruise Control changed")
+	}
+
+	registry.Forget(cluster.Namespace, cluster.Name)
+	if forgotten, _ := registry.CruiseControlScaler(context.TODO(), c, cluster); forgotten == moved {
+		t.Error("expected a new scaler after the cluster was forgotten")
+	}
+
+	cluster.Spec.Rebalancer = v1beta1.RebalancerBuiltIn
+	builtIn, err := registry.Scaler(context.TODO(), c, cluster)
+	if _, ok := builtIn.(*builtInRebalancer); err != nil || !ok {
+		t.Errorf("expected the built-in rebalancer for the cluster, got: %T, error: %v", builtIn, err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+)
+
+// The remove disks endpoint of Cruise Control 2.5+ is not covered by the go-cruise-control API yet
+
+const (
+	EndpointRemoveDisks types.APIEndpoint = "REMOVE_DISKS"
+)
+
+// RemoveDisksRequest requests Cruise Control to move the partition replicas off from the given log dirs of the
+// brokers to their other log dirs
+type RemoveDisksRequest struct {
+	types.GenericRequestWithReason
+
+	// List of broker id and logdir pair to be removed from the cluster
+	BrokerIDAndLogDirs types.BrokerIDAndLogDirs `param:"brokerid_and_logdirs"`
+	// Whether to dry-run the request or not
+	DryRun bool `param:"dryrun"`
+	// Execution progress check interval in milliseconds
+	ExecutionProgressCheckIntervalMs int64 `param:"execution_progress_check_interval_ms,omitempty"`
+	// Upper bound on the bandwidth in bytes per second used to move replicas
+	ReplicationThrottle int64 `param:"replication_throttle,omitempty"`
+	// Review id for 2-step verification
+	ReviewID int32 `param:"review_id,omitempty"`
+	// Whether to stop the ongoing execution (if any) and start executing the given request
+	StopOngoingExecution bool `param:"stop_ongoing_execution,omitempty"`
+}
+
+func (s RemoveDisksRequest) Validate() error {
+	if len(s.BrokerIDAndLogDirs) < 1 {
+		return errors.New("list of broker id and logdir pairs must not be empty (BrokerIDAndLogDirs)")
+	}
+	return nil
+}
+
+type RemoveDisksResponse struct {
+	types.GenericResponse
+
+	Result *types.OptimizationResult
+}
+
+func (r *RemoveDisksResponse) UnmarshalResponse(resp *http.Response) error {
+	if err := r.GenericResponse.UnmarshalResponse(resp); err != nil {
+		return err
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var d interface{}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		r.Result = &types.OptimizationResult{}
+		d = r.Result
+	case http.StatusAccepted:
+		r.Progress = &types.ProgressResult{}
+		d = r.Progress
+	default:
+		r.Error = &types.APIError{}
+		d = r.Error
+	}
+
+	return json.Unmarshal(bodyBytes, d)
+}
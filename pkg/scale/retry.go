@@ -0,0 +1,218 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a request failing with a transient error is retried if no other is set
+	DefaultMaxRetries = 3
+	// DefaultInitialBackoff is the time waited before the first retry of a request if no other is set
+	DefaultInitialBackoff = 200 * time.Millisecond
+	// DefaultMaxBackoff is the upper limit of the exponentially growing time waited between retries if no other is set
+	DefaultMaxBackoff = 2 * time.Second
+	// DefaultFailureThreshold is the number of consecutive transient errors opening the circuit breaker if no other is set
+	DefaultFailureThreshold = 5
+	// DefaultOpenTimeout is the time the circuit breaker stays open before letting requests through again if no other
+	// is set
+	DefaultOpenTimeout = 30 * time.Second
+)
+
+// ErrCruiseControlUnavailable is returned if Cruise Control could not be reached or kept failing with server errors,
+// or if the circuit breaker of its client is open after repeated failures
+var ErrCruiseControlUnavailable = errors.New("cruise control is unavailable")
+
+// RetryConfig configures the retries of the requests to Cruise Control failing with a transient error, i.e. a network
+// error, a timeout or a 5xx response not sent by Cruise Control itself
+type RetryConfig struct {
+	// MaxRetries is the number of times a failed request is retried, 0 disables the retries
+	MaxRetries int
+	// InitialBackoff is the time waited before the first retry, doubled after each further attempt
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper limit of the time waited between retries
+	MaxBackoff time.Duration
+}
+
+// CircuitBreakerConfig configures the circuit breaker short-circuiting the requests to a Cruise Control server which
+// keeps failing with transient errors
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive transient errors opening the circuit breaker, 0 disables it
+	FailureThreshold int
+	// OpenTimeout is the time the circuit breaker stays open before letting requests through again
+	OpenTimeout time.Duration
+}
+
+// WithRetry sets how the client retries the requests failing with a transient error. Requests are retried with
+// DefaultMaxRetries and exponential backoff if it is not set.
+func WithRetry(cfg RetryConfig) ClientOption {
+	return func(c *clientConfig) {
+		c.retry = cfg
+	}
+}
+
+// WithCircuitBreaker sets when the client stops sending requests to a Cruise Control server failing repeatedly. The
+// state of the circuit breaker is shared by the clients of the same server, so that it is kept between reconciles.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) ClientOption {
+	return func(c *clientConfig) {
+		c.circuitBreaker = cfg
+	}
+}
+
+// transientError is an error of a request which might succeed if it is sent again
+type transientError struct {
+	err error
+	// retryable is false if the request might have been processed by Cruise Control, e.g. it timed out
+	retryable bool
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// isTransientStatus returns true if the status code of the response means that the server is temporarily unavailable.
+// Other 5xx responses are only transient if they are not JSON, as Cruise Control reports the failure of an operation
+// e.g. on a goal violation with a 500 JSON response.
+func isTransientStatus(statusCode int, isJSON bool) bool {
+	switch statusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return statusCode >= http.StatusInternalServerError && !isJSON
+}
+
+// doWithRetry calls send until it succeeds, fails with an error which is not transient or runs out of retries. A
+// wrapped ErrCruiseControlUnavailable is returned if the circuit breaker is open or the retries ran out.
+func (c *httpClient) doWithRetry(ctx context.Context, send func() error) error {
+	backoff := c.retry.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		if !c.breaker.allow() {
+			return fmt.Errorf("%w: circuit breaker is open after repeated failures", ErrCruiseControlUnavailable)
+		}
+
+		err := send()
+		if err != nil && ctx.Err() != nil {
+			// the caller gave up on the request, which says nothing about the availability of Cruise Control
+			return err
+		}
+		var transient *transientError
+		if !errors.As(err, &transient) {
+			c.breaker.success()
+			return err
+		}
+		c.breaker.failure()
+		if !transient.retryable || attempt >= c.retry.MaxRetries {
+			return fmt.Errorf("%w: %v", ErrCruiseControlUnavailable, err)
+		}
+
+		c.log.V(1).Info("retrying request failed with transient error", "attempt", attempt+1, "backoff", backoff,
+			"error", err.Error())
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > c.retry.MaxBackoff {
+			backoff = c.retry.MaxBackoff
+		}
+	}
+}
+
+// circuitBreaker stops letting requests through after FailureThreshold consecutive failures for OpenTimeout. After
+// that it is half-open: requests are let through again, but the next failure opens it right away.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	cfg      CircuitBreakerConfig
+	now      func() time.Time
+	failures int
+	openedAt time.Time
+}
+
+// circuitBreakers holds the circuit breakers of the Cruise Control servers by URL
+var circuitBreakers = struct {
+	sync.Mutex
+	byURL map[string]*circuitBreaker
+}{byURL: make(map[string]*circuitBreaker)}
+
+// circuitBreakerFor returns the circuit breaker of the Cruise Control server at serverURL, or nil if it is disabled
+func circuitBreakerFor(serverURL string, cfg CircuitBreakerConfig) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = DefaultOpenTimeout
+	}
+
+	circuitBreakers.Lock()
+	defer circuitBreakers.Unlock()
+
+	b, ok := circuitBreakers.byURL[serverURL]
+	if !ok {
+		b = &circuitBreaker{now: time.Now}
+		circuitBreakers.byURL[serverURL] = b
+	}
+	b.mu.Lock()
+	b.cfg = cfg
+	b.mu.Unlock()
+	return b
+}
+
+// allow returns false if the circuit breaker is open
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures < b.cfg.FailureThreshold || b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout
+}
+
+// success closes the circuit breaker
+func (b *circuitBreaker) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+}
+
+// failure counts a failed request and (re)opens the circuit breaker if the failures reached the threshold
+func (b *circuitBreaker) failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+)
+
+// When two-step verification is enabled in Cruise Control, every POST request is put on its review board instead of
+// being executed. Such a request has to be approved on the review endpoint and then submitted again with its review id.
+
+const (
+	reviewIDParam = "review_id"
+
+	reviewApprovalReason = "approved by koperator"
+)
+
+// ReviewBoardResult is the list of the requests on the review board of Cruise Control. The review result of
+// go-cruise-control holds a single request, while Cruise Control returns a list of them.
+type ReviewBoardResult struct {
+	types.Version
+
+	RequestInfo []types.RequestInfo `json:"RequestInfo"`
+}
+
+// ReviewResponse is the response of Cruise Control to a request of the review or review board endpoints
+type ReviewResponse struct {
+	types.GenericResponse
+
+	Result *ReviewBoardResult
+}
+
+func (r *ReviewResponse) UnmarshalResponse(resp *http.Response) error {
+	if err := r.GenericResponse.UnmarshalResponse(resp); err != nil {
+		return err
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var d interface{}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		r.Result = &ReviewBoardResult{}
+		d = r.Result
+	default:
+		r.Error = &types.APIError{}
+		d = r.Error
+	}
+
+	return json.Unmarshal(bodyBytes, d)
+}
+
+// pendingReviewError is returned by send if Cruise Control put the request on its review board instead of executing it
+type pendingReviewError struct {
+	reviewID int32
+}
+
+func (e *pendingReviewError) Error() string {
+	return fmt.Sprintf("request is pending review with id %d", e.reviewID)
+}
+
+// pendingReviewID returns the review id of the request if the response of Cruise Control means that the request is
+// waiting for review
+func pendingReviewID(statusCode int, body []byte) (int32, bool) {
+	if statusCode != http.StatusOK {
+		return 0, false
+	}
+	result := &ReviewBoardResult{}
+	if err := json.Unmarshal(body, result); err != nil || len(result.RequestInfo) != 1 {
+		return 0, false
+	}
+	info := result.RequestInfo[0]
+	return info.ID, info.Status == types.RequestStatusPendingReview
+}
+
+// approveReview approves the request waiting for review with the given id
+func (c *httpClient) approveReview(ctx context.Context, reviewID int32) error {
+	req := &api.ReviewRequest{
+		GenericRequestWithReason: types.GenericRequestWithReason{Reason: reviewApprovalReason},
+		Approve:                  []int32{reviewID},
+	}
+	resp := &ReviewResponse{}
+	if err := c.request(ctx, req, resp, api.EndpointReview, http.MethodPost); err != nil {
+		return err
+	}
+	if resp.Result != nil {
+		for _, info := range resp.Result.RequestInfo {
+			if info.ID == reviewID && info.Status != types.RequestStatusApproved {
+				return fmt.Errorf("review %d is %s instead of %s", reviewID, info.Status, types.RequestStatusApproved)
+			}
+		}
+	}
+	return nil
+}
+
+// withReviewID returns a copy of the request submitting the approved review with the given id. Cruise Control executes
+// the request with the parameters it was reviewed with, so only the review id is sent.
+func withReviewID(r *http.Request, reviewID int32) *http.Request {
+	r = r.Clone(r.Context())
+	query := url.Values{}
+	query.Set("json", "true")
+	query.Set(reviewIDParam, strconv.FormatInt(int64(reviewID), 10))
+	r.URL.RawQuery = query.Encode()
+	return r
+}
+
+// Review approves or discards requests on the review board of Cruise Control
+func (c *httpClient) Review(ctx context.Context, r *api.ReviewRequest) (*ReviewResponse, error) {
+	resp := &ReviewResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointReview, http.MethodPost)
+}
+
+// ReviewBoard returns the requests on the review board of Cruise Control
+func (c *httpClient) ReviewBoard(ctx context.Context, r *api.ReviewBoardRequest) (*ReviewResponse, error) {
+	resp := &ReviewResponse{}
+	return resp, c.request(ctx, r, resp, api.EndpointReviewBoard, http.MethodGet)
+}
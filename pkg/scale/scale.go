@@ -19,53 +19,60 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	"github.com/banzaicloud/go-cruise-control/pkg/api"
-	"github.com/banzaicloud/go-cruise-control/pkg/client"
 	"github.com/banzaicloud/go-cruise-control/pkg/types"
 	"github.com/banzaicloud/koperator/api/v1beta1"
 )
 
 var newCruiseControlScaler = createNewDefaultCruiseControlScaler
 
-func NewCruiseControlScaler(ctx context.Context, serverURL string) (CruiseControlScaler, error) {
-	return newCruiseControlScaler(ctx, serverURL)
+// NewCruiseControlScaler returns a CruiseControlScaler sending its requests to the Cruise Control server at serverURL.
+// The serverURL is the full base URL of the REST API including its scheme, port and path prefix, e.g.
+// "https://gw.example.com:443/cruise-control/kafkacruisecontrol" for Cruise Control behind a reverse proxy.
+// The options configure the underlying HTTP client, e.g. its transport and request timeout.
+func NewCruiseControlScaler(ctx context.Context, serverURL string, opts ...ClientOption) (CruiseControlScaler, error) {
+	return newCruiseControlScaler(ctx, serverURL, opts...)
 }
 
-func createNewDefaultCruiseControlScaler(ctx context.Context, serverURL string) (CruiseControlScaler, error) {
+func createNewDefaultCruiseControlScaler(ctx context.Context, serverURL string, opts ...ClientOption) (CruiseControlScaler, error) {
 	log := logr.FromContextOrDiscard(ctx).WithName("Scaler")
 
-	cfg := &client.Config{
-		ServerURL: serverURL,
-		UserAgent: "koperator",
-	}
-
-	cruisecontrol, err := client.NewClient(ctx, cfg)
+	cruisecontrol, err := NewCruiseControlClient(ctx, serverURL, opts...)
 	if err != nil {
 		log.Error(err, "creating Cruise Control client failed")
 		return nil, err
 	}
+	return NewCruiseControlScalerWithClient(log, cruisecontrol), nil
+}
+
+// NewCruiseControlScalerWithClient returns a CruiseControlScaler sending its requests to Cruise Control with the given client
+func NewCruiseControlScalerWithClient(log logr.Logger, cruisecontrol CruiseControlClient) CruiseControlScaler {
 	return &cruiseControlScaler{
 		log:    log,
 		client: cruisecontrol,
-	}, nil
+		cache:  newClusterStateCache(DefaultClusterStateCacheTTL),
+	}
 }
 
 type cruiseControlScaler struct {
 	CruiseControlScaler
 
 	log    logr.Logger
-	client *client.Client
+	client CruiseControlClient
+	cache  *clusterStateCache
 }
 
 // Status returns a CruiseControlStatus describing the internal state of Cruise Control.
-func (cc *cruiseControlScaler) Status() CruiseControlStatus {
+func (cc *cruiseControlScaler) Status(ctx context.Context) CruiseControlStatus {
 	req := api.StateRequestWithDefaults()
 	req.Verbose = true
-	resp, err := cc.client.State(req)
+	resp, err := cc.client.State(ctx, req)
 	if err != nil {
 		cc.log.Error(err, "failed to get Cruise Control state")
 		return CruiseControlStatus{}
@@ -81,7 +88,7 @@ func (cc *cruiseControlScaler) Status() CruiseControlStatus {
 		}
 	}
 
-	return CruiseControlStatus{
+	status := CruiseControlStatus{
 		MonitorReady:       resp.Result.MonitorState.State == types.MonitorStateRunning,
 		ExecutorReady:      resp.Result.ExecutorState.State == types.ExecutorStateTypeNoTaskInProgress,
 		AnalyzerReady:      resp.Result.AnalyzerState.IsProposalReady && goalsReady,
@@ -89,12 +96,80 @@ func (cc *cruiseControlScaler) Status() CruiseControlStatus {
 		GoalsReady:         goalsReady,
 		MonitoredWindows:   resp.Result.MonitorState.NumMonitoredWindows,
 		MonitoringCoverage: resp.Result.MonitorState.MonitoringCoveragePercentage,
+		SamplingPaused:     resp.Result.MonitorState.State == types.MonitorStatePaused,
+		AnomalyDetector:    anomalyDetectorStatus(&resp.Result.AnomalyDetectorState),
+	}
+	if status.SamplingPaused {
+		status.SamplingPauseReason = resp.Result.MonitorState.ReasonOfLatestPauseOrResume
+	}
+	return status
+}
+
+// anomalyDetectorStatus returns the self-healing settings and the recent anomalies reported by the anomaly detector
+func anomalyDetectorStatus(state *types.AnomalyDetectorState) AnomalyDetectorStatus {
+	status := AnomalyDetectorStatus{
+		SelfHealingEnabled:   anomalyTypeNames(state.SelfHealingEnabled),
+		SelfHealingDisabled:  anomalyTypeNames(state.SelfHealingDisabled),
+		RecentGoalViolations: goalViolations(state.RecentGoalViolations),
+		RecentBrokerFailures: failures(state.RecentBrokerFailures, func(a types.AnomalyDetails) map[string]int64 {
+			return a.FailedBrokersByTimeMs
+		}),
+		RecentDiskFailures: failures(state.RecentDiskFailures, func(a types.AnomalyDetails) map[string]int64 {
+			return a.FailedDisksByTimeMs
+		}),
+		BalancednessScore: state.BalancednessScore,
+	}
+	if state.OngoingSelfHealingAnomaly != types.AnomalyTypeUndefined {
+		status.OngoingSelfHealingAnomaly = state.OngoingSelfHealingAnomaly.String()
+	}
+	return status
+}
+
+func anomalyTypeNames(anomalyTypes []types.AnomalyType) []string {
+	names := make([]string, 0, len(anomalyTypes))
+	for _, anomalyType := range anomalyTypes {
+		names = append(names, anomalyType.String())
+	}
+	return names
+}
+
+func goalViolations(anomalies []types.AnomalyDetails) []GoalViolation {
+	violations := make([]GoalViolation, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		goals := make([]string, 0, len(anomaly.FixableViolatedGoals))
+		for _, goal := range anomaly.FixableViolatedGoals {
+			goals = append(goals, goal.String())
+		}
+		violations = append(violations, GoalViolation{
+			AnomalyID:    anomaly.AnomalyID,
+			DetectedAt:   time.UnixMilli(anomaly.DetectionMs),
+			FixableGoals: goals,
+		})
 	}
+	return violations
+}
+
+// failures returns the broker or disk failures, failedAt returns the failure times of the brokers or the disks
+func failures(anomalies []types.AnomalyDetails, failedAt func(types.AnomalyDetails) map[string]int64) []Failure {
+	result := make([]Failure, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		failure := Failure{
+			AnomalyID:  anomaly.AnomalyID,
+			DetectedAt: time.UnixMilli(anomaly.DetectionMs),
+			Status:     anomaly.Status.String(),
+			FailedAt:   make(map[string]time.Time, len(failedAt(anomaly))),
+		}
+		for id, ms := range failedAt(anomaly) {
+			failure.FailedAt[id] = time.UnixMilli(ms)
+		}
+		result = append(result, failure)
+	}
+	return result
 }
 
 // IsReady returns true if the Analyzer and Monitor components of Cruise Control are in ready state.
-func (cc *cruiseControlScaler) IsReady() bool {
-	status := cc.Status()
+func (cc *cruiseControlScaler) IsReady(ctx context.Context) bool {
+	status := cc.Status(ctx)
 	cc.log.Info("cruise control readiness",
 		"analyzer", status.AnalyzerReady,
 		"monitor", status.MonitorReady,
@@ -106,43 +181,149 @@ func (cc *cruiseControlScaler) IsReady() bool {
 }
 
 // IsUp returns true if Cruise Control is online.
-func (cc *cruiseControlScaler) IsUp() bool {
-	_, err := cc.client.State(api.StateRequestWithDefaults())
+func (cc *cruiseControlScaler) IsUp(ctx context.Context) bool {
+	_, err := cc.client.State(ctx, api.StateRequestWithDefaults())
 	return err == nil
 }
 
 // GetUserTasks returns list of Result describing User Tasks from Cruise Control for the provided task IDs.
-func (cc *cruiseControlScaler) GetUserTasks(taskIDs ...string) ([]*Result, error) {
+func (cc *cruiseControlScaler) GetUserTasks(ctx context.Context, taskIDs ...string) ([]*Result, error) {
+	return cc.ListUserTasks(ctx, UserTaskFilter{TaskIDs: taskIDs})
+}
+
+// ListUserTasks returns list of Result describing the User Tasks from Cruise Control selected by the filter. Cruise
+// Control only limits the number of the reported tasks, so the tasks are requested in growing pages until fewer tasks
+// are reported than requested.
+func (cc *cruiseControlScaler) ListUserTasks(ctx context.Context, filter UserTaskFilter) ([]*Result, error) {
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultUserTasksPageSize
+	}
 	req := &api.UserTasksRequest{
-		UserTaskIDs: taskIDs,
-		Entries:     100,
+		UserTaskIDs: filter.TaskIDs,
+		Entries:     pageSize,
+	}
+	for _, state := range filter.States {
+		// the states not known by Cruise Control are filtered by the operator
+		if status := types.UserTaskStatusFromString(string(state)); status != types.UserTaskStatusUndefined {
+			req.Types = append(req.Types, status)
+		}
+	}
+	for _, endpoint := range filter.Endpoints {
+		req.Endpoints = append(req.Endpoints, types.APIEndpoint(endpoint))
 	}
 
-	resp, err := cc.client.UserTasks(req)
-	if err != nil {
-		return nil, err
+	// Cruise Control can not skip the tasks already listed, so more of them are requested until all of them are listed
+	// or the ones started outside of the time window of the filter are reached
+	var userTasks []types.UserTaskInfo
+	for {
+		resp, err := cc.client.UserTasks(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		userTasks = resp.Result.UserTasks
+		if int64(len(userTasks)) < int64(req.Entries) || req.Entries > math.MaxInt32-pageSize ||
+			beyondWindow(userTasks, filter) {
+			break
+		}
+		req.Entries += pageSize
 	}
 
-	results := make([]*Result, len(resp.Result.UserTasks))
-	for idx, taskInfo := range resp.Result.UserTasks {
-		results[idx] = &Result{
+	now := time.Now()
+	var executorState *types.ExecutorState
+	results := make([]*Result, 0, len(userTasks))
+	for _, taskInfo := range userTasks {
+		state := v1beta1.CruiseControlUserTaskState(taskInfo.Status.String())
+		if !filter.Matches(taskInfo.UserTaskID, state, taskInfo.StartMs.Time) {
+			continue
+		}
+		result := &Result{
 			TaskID:    taskInfo.UserTaskID,
-			StartedAt: taskInfo.StartMs.UTC().String(),
-			State:     v1beta1.CruiseControlUserTaskState(taskInfo.Status.String()),
+			StartedAt: taskInfo.StartMs.UTC().Format(time.RFC3339),
+			State:     state,
+		}
+		if result.State == v1beta1.CruiseControlTaskInExecution {
+			if executorState == nil {
+				executorState = cc.executorState(ctx)
+			}
+			if executorState.TriggeredUserTaskID == taskInfo.UserTaskID {
+				result.Progress = taskProgress(executorState, taskInfo.StartMs.Time, now)
+			}
 		}
+		results = append(results, result)
 	}
 
 	return results, nil
 }
 
+// beyondWindow returns true if the last of the tasks, which are listed in the order of their start time, started
+// outside of the time window of the filter, so the ones listed after it by requesting more tasks are outside as well
+func beyondWindow(userTasks []types.UserTaskInfo, filter UserTaskFilter) bool {
+	if len(userTasks) < 2 {
+		return false
+	}
+	first, last := userTasks[0].StartMs.Time, userTasks[len(userTasks)-1].StartMs.Time
+	if last.Before(first) {
+		return !filter.StartedAfter.IsZero() && last.Before(filter.StartedAfter)
+	}
+	return !filter.StartedBefore.IsZero() && !last.Before(filter.StartedBefore)
+}
+
+// executorState returns the state of the executor of Cruise Control, or an empty state if it can not be retrieved as
+// the progress of the tasks is only informational
+func (cc *cruiseControlScaler) executorState(ctx context.Context) *types.ExecutorState {
+	req := api.StateRequestWithDefaults()
+	req.Substates = []types.Substate{types.SubstateExecutor}
+	resp, err := cc.client.State(ctx, req)
+	if err != nil || resp.Result == nil {
+		cc.log.V(1).Info("failed to get the executor state of Cruise Control", "error", fmt.Sprintf("%v", err))
+		return &types.ExecutorState{}
+	}
+	return &resp.Result.ExecutorState
+}
+
+// taskProgress returns the progress of the replica movements of the task being executed by the executor
+func taskProgress(state *types.ExecutorState, startedAt, now time.Time) *TaskProgress {
+	progress := &TaskProgress{
+		DataMovedMB:  state.FinishedDataMovement,
+		DataToMoveMB: state.TotalDataToMove,
+	}
+
+	finished, total := state.NumFinishedPartitionMovements, state.NumTotalPartitionMovements
+	if total == 0 {
+		// only leaders are moved
+		finished, total = state.NumFinishedLeadershipMovements, state.NumTotalLeadershipMovements
+	}
+	if total > 0 {
+		progress.ReplicaMovementsCompletedPct = 100 * float64(finished) / float64(total)
+	}
+
+	done := progress.ReplicaMovementsCompletedPct / 100
+	if state.TotalDataToMove > 0 {
+		done = float64(state.FinishedDataMovement) / float64(state.TotalDataToMove)
+	}
+	if elapsed := now.Sub(startedAt); done > 0 && elapsed > 0 {
+		remaining := time.Duration(float64(elapsed) * (1 - done) / done)
+		progress.EstimatedCompletion = now.Add(remaining).UTC()
+	}
+	return progress
+}
+
 // AddBrokers requests Cruise Control to add the list of provided brokers to the Kafka cluster
 // by reassigning partition replicas to them.
 // Request returns an error if not all brokers are available in Cruise Control.
-func (cc *cruiseControlScaler) AddBrokers(brokerIDs ...string) (*Result, error) {
+func (cc *cruiseControlScaler) AddBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	defer cc.invalidateCache()
+
 	if len(brokerIDs) == 0 {
 		return nil, errors.New("no broker id(s) provided for add brokers request")
 	}
 
+	addBrokerGoals, err := goalsFromStringSlice(opts.Goals)
+	if err != nil {
+		return nil, err
+	}
+
 	brokersToAdd, err := brokerIDsFromStringSlice(brokerIDs)
 	if err != nil {
 		cc.log.Error(err, "failed to cast broker IDs from string slice")
@@ -150,7 +331,7 @@ func (cc *cruiseControlScaler) AddBrokers(brokerIDs ...string) (*Result, error)
 	}
 
 	states := []KafkaBrokerState{KafkaBrokerAlive, KafkaBrokerNew}
-	availableBrokers, err := cc.BrokersWithState(states...)
+	availableBrokers, err := cc.BrokersWithState(ctx, states...)
 	if err != nil {
 		cc.log.Error(err, "failed to retrieve list of available brokers from Cruise Control")
 		return nil, err
@@ -173,34 +354,41 @@ func (cc *cruiseControlScaler) AddBrokers(brokerIDs ...string) (*Result, error)
 		AllowCapacityEstimation: true,
 		BrokerIDs:               brokersToAdd,
 		DataFrom:                types.ProposalDataSourceValidWindows,
-		UseReadyDefaultGoals:    true,
+		Goals:                   addBrokerGoals,
+		SkipHardGoalCheck:       len(addBrokerGoals) > 0,
+		UseReadyDefaultGoals:    len(addBrokerGoals) == 0,
+		ReplicationThrottle:     opts.ReplicationThrottle,
+
+		ConcurrentPartitionMovementsPerBroker: opts.Concurrency.PartitionMovementsPerBroker,
+		ConcurrentLeaderMovements:             opts.Concurrency.LeaderMovements,
 	}
-	addBrokerResp, err := cc.client.AddBroker(addBrokerReq)
+	addBrokerResp, err := cc.client.AddBroker(ctx, addBrokerReq)
 	if err != nil {
-		return &Result{
-			TaskID:    addBrokerResp.TaskID,
-			StartedAt: addBrokerResp.Date,
-			State:     v1beta1.CruiseControlTaskCompletedWithError,
-			Err:       fmt.Sprintf("%v", err),
-		}, err
+		return failedResult(addBrokerResp.TaskID, addBrokerResp.Date, err), err
 	}
 
 	return &Result{
 		TaskID:    addBrokerResp.TaskID,
-		StartedAt: addBrokerResp.Date,
+		StartedAt: taskStartTime(addBrokerResp.Date),
 		State:     v1beta1.CruiseControlTaskActive,
 	}, nil
 }
 
 // RemoveBrokers requests Cruise Control to move partition replicase off from the provided brokers.
 // It does not attempt to remove the provided brokers in case none of them are available in Cruise Control.
-func (cc *cruiseControlScaler) RemoveBrokers(brokerIDs ...string) (*Result, error) {
+func (cc *cruiseControlScaler) RemoveBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	defer cc.invalidateCache()
+
 	if len(brokerIDs) == 0 {
 		return nil, errors.New("no broker id(s) provided for remove brokers request")
 	}
 
-	clusterStateReq := api.KafkaClusterStateRequestWithDefaults()
-	clusterStateResp, err := cc.client.KafkaClusterState(clusterStateReq)
+	removeBrokerGoals, err := goalsFromStringSlice(opts.Goals)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterStateResp, err := cc.kafkaClusterState(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -233,46 +421,39 @@ func (cc *cruiseControlScaler) RemoveBrokers(brokerIDs ...string) (*Result, erro
 		AllowCapacityEstimation: true,
 		BrokerIDs:               brokersToRemove,
 		DataFrom:                types.ProposalDataSourceValidWindows,
-		UseReadyDefaultGoals:    true,
+		Goals:                   removeBrokerGoals,
+		SkipHardGoalCheck:       len(removeBrokerGoals) > 0,
+		UseReadyDefaultGoals:    len(removeBrokerGoals) == 0,
+		ReplicationThrottle:     opts.ReplicationThrottle,
+
+		ConcurrentPartitionMovementsPerBroker: opts.Concurrency.PartitionMovementsPerBroker,
+		ConcurrentLeaderMovements:             opts.Concurrency.LeaderMovements,
 	}
-	rmBrokerResp, err := cc.client.RemoveBroker(rmBrokerReq)
+	rmBrokerResp, err := cc.client.RemoveBroker(ctx, rmBrokerReq)
 	if err != nil {
-		return &Result{
-			TaskID:    rmBrokerResp.TaskID,
-			StartedAt: rmBrokerResp.Date,
-			State:     v1beta1.CruiseControlTaskCompletedWithError,
-			Err:       fmt.Sprintf("%v", err),
-		}, err
+		return failedResult(rmBrokerResp.TaskID, rmBrokerResp.Date, err), err
 	}
 
 	return &Result{
 		TaskID:    rmBrokerResp.TaskID,
-		StartedAt: rmBrokerResp.Date,
+		StartedAt: taskStartTime(rmBrokerResp.Date),
 		State:     v1beta1.CruiseControlTaskActive,
 	}, nil
 }
 
 // RebalanceDisks performs a disk rebalance via Cruise Control for the provided list of brokers.
-func (cc *cruiseControlScaler) RebalanceDisks(brokerIDs ...string) (*Result, error) {
-	clusterLoadResp, err := cc.client.KafkaClusterLoad(api.KafkaClusterLoadRequestWithDefaults())
+func (cc *cruiseControlScaler) RebalanceDisks(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	defer cc.invalidateCache()
+
+	rebalanceGoals, err := goalsFromStringSlice(opts.Goals)
 	if err != nil {
 		return nil, err
 	}
 
-	brokerIDsMap := stringSliceToMap(brokerIDs)
-
-	brokersWithEmptyDisks := make([]int32, 0, len(brokerIDs))
-	for _, brokerStat := range clusterLoadResp.Result.Brokers {
-		if _, ok := brokerIDsMap[strconv.Itoa(int(brokerStat.Broker))]; !ok {
-			continue
-		}
-		for _, diskState := range brokerStat.DiskState {
-			if diskState.NumReplicas <= 0 && !diskState.DiskMB.Dead {
-				brokersWithEmptyDisks = append(brokersWithEmptyDisks, brokerStat.Broker)
-			}
-		}
+	brokersWithEmptyDisks, err := cc.brokersWithEmptyDisks(ctx, brokerIDs)
+	if err != nil {
+		return nil, err
 	}
-
 	if len(brokersWithEmptyDisks) == 0 {
 		return &Result{
 			State: v1beta1.CruiseControlTaskCompleted,
@@ -283,30 +464,110 @@ func (cc *cruiseControlScaler) RebalanceDisks(brokerIDs ...string) (*Result, err
 		AllowCapacityEstimation:       true,
 		DestinationBrokerIDs:          brokersWithEmptyDisks,
 		DataFrom:                      types.ProposalDataSourceValidWindows,
-		UseReadyDefaultGoals:          true,
+		Goals:                         rebalanceGoals,
+		SkipHardGoalCheck:             len(rebalanceGoals) > 0,
+		UseReadyDefaultGoals:          len(rebalanceGoals) == 0,
+		ReplicationThrottle:           opts.ReplicationThrottle,
 		ExcludeRecentlyRemovedBrokers: true,
+
+		ConcurrentPartitionMovementsPerBroker:   opts.Concurrency.PartitionMovementsPerBroker,
+		ConcurrentLeaderMovements:               opts.Concurrency.LeaderMovements,
+		ConcurrentIntraBrokerPartitionMovements: opts.Concurrency.IntraBrokerPartitionMovements,
 	}
-	rebalanceResp, err := cc.client.Rebalance(rebalanceReq)
+	rebalanceResp, err := cc.client.Rebalance(ctx, rebalanceReq)
 	if err != nil {
+		return failedResult(rebalanceResp.TaskID, rebalanceResp.Date, err), err
+	}
+
+	return &Result{
+		TaskID:    rebalanceResp.TaskID,
+		StartedAt: taskStartTime(rebalanceResp.Date),
+		State:     v1beta1.CruiseControlTaskActive,
+	}, nil
+}
+
+// IntraBrokerRebalance requests Cruise Control to move partition replicas between the disks of each broker, without
+// moving them between brokers, e.g. to balance the disks of a JBOD broker after a disk is added. The intra-broker
+// goals of Cruise Control are optimized if no goals are given. Cruise Control rebalances the disks of all the brokers,
+// the given brokers are only checked for empty disks, and no rebalance is started if they have none.
+func (cc *cruiseControlScaler) IntraBrokerRebalance(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error) {
+	defer cc.invalidateCache()
+
+	rebalanceGoals, err := goalsFromStringSlice(opts.Goals)
+	if err != nil {
+		return nil, err
+	}
+	for _, goal := range rebalanceGoals {
+		if !isIntraBrokerGoal(goal) {
+			return nil, fmt.Errorf("goal %s is not an intra-broker goal", goal)
+		}
+	}
+	if len(rebalanceGoals) == 0 {
+		rebalanceGoals = []types.Goal{types.IntraBrokerDiskCapacityGoal, types.IntraBrokerDiskUsageDistributionGoal}
+	}
+
+	brokersWithEmptyDisks, err := cc.brokersWithEmptyDisks(ctx, brokerIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(brokersWithEmptyDisks) == 0 {
 		return &Result{
-			TaskID:    rebalanceResp.TaskID,
-			StartedAt: rebalanceResp.Date,
-			State:     v1beta1.CruiseControlTaskCompletedWithError,
-			Err:       fmt.Sprintf("%v", err),
-		}, err
+			State: v1beta1.CruiseControlTaskCompleted,
+		}, nil
+	}
+
+	rebalanceReq := &api.RebalanceRequest{
+		AllowCapacityEstimation: true,
+		RebalanceDisk:           true,
+		DataFrom:                types.ProposalDataSourceValidWindows,
+		Goals:                   rebalanceGoals,
+		ReplicationThrottle:     opts.ReplicationThrottle,
+
+		ConcurrentIntraBrokerPartitionMovements: opts.Concurrency.IntraBrokerPartitionMovements,
+	}
+	rebalanceResp, err := cc.client.Rebalance(ctx, rebalanceReq)
+	if err != nil {
+		return failedResult(rebalanceResp.TaskID, rebalanceResp.Date, err), err
 	}
 
 	return &Result{
 		TaskID:    rebalanceResp.TaskID,
-		StartedAt: rebalanceResp.Date,
+		StartedAt: taskStartTime(rebalanceResp.Date),
 		State:     v1beta1.CruiseControlTaskActive,
 	}, nil
 }
 
+// brokersWithEmptyDisks returns the IDs of the given brokers having a healthy disk without any partition replicas
+func (cc *cruiseControlScaler) brokersWithEmptyDisks(ctx context.Context, brokerIDs []string) ([]int32, error) {
+	clusterLoadResp, err := cc.kafkaClusterLoad(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	brokerIDsMap := stringSliceToMap(brokerIDs)
+
+	brokersWithEmptyDisks := make([]int32, 0, len(brokerIDs))
+	for _, brokerStat := range clusterLoadResp.Result.Brokers {
+		if _, ok := brokerIDsMap[strconv.Itoa(int(brokerStat.Broker))]; !ok {
+			continue
+		}
+		for _, diskState := range brokerStat.DiskState {
+			if diskState.NumReplicas <= 0 && !diskState.DiskMB.Dead {
+				brokersWithEmptyDisks = append(brokersWithEmptyDisks, brokerStat.Broker)
+			}
+		}
+	}
+	return brokersWithEmptyDisks, nil
+}
+
+func isIntraBrokerGoal(goal types.Goal) bool {
+	return goal == types.IntraBrokerDiskCapacityGoal || goal == types.IntraBrokerDiskUsageDistributionGoal
+}
+
 // BrokersWithState returns a list of IDs for Kafka brokers which are available in Cruise Control
 // and have one of the expected states.
-func (cc *cruiseControlScaler) BrokersWithState(states ...KafkaBrokerState) ([]string, error) {
-	resp, err := cc.client.KafkaClusterLoad(api.KafkaClusterLoadRequestWithDefaults())
+func (cc *cruiseControlScaler) BrokersWithState(ctx context.Context, states ...KafkaBrokerState) ([]string, error) {
+	resp, err := cc.kafkaClusterLoad(ctx)
 	if err != nil {
 		cc.log.Error(err, "getting Kafka cluster load from Cruise Control returned an error")
 		return nil, err
@@ -326,20 +587,29 @@ func (cc *cruiseControlScaler) BrokersWithState(states ...KafkaBrokerState) ([]s
 }
 
 // PartitionReplicasByBroker returns the number of partition replicas for every broker in the Kafka cluster.
-func (cc *cruiseControlScaler) PartitionReplicasByBroker() (map[string]int32, error) {
-	clusterStateReq := api.KafkaClusterStateRequestWithDefaults()
-	clusterStateResp, err := cc.client.KafkaClusterState(clusterStateReq)
+func (cc *cruiseControlScaler) PartitionReplicasByBroker(ctx context.Context) (map[string]int32, error) {
+	clusterStateResp, err := cc.kafkaClusterState(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return clusterStateResp.Result.KafkaBrokerState.ReplicaCountByBrokerID, nil
+	// the map of the cached response is copied to not let the callers change it
+	replicasByBroker := make(map[string]int32, len(clusterStateResp.Result.KafkaBrokerState.ReplicaCountByBrokerID))
+	for brokerID, replicas := range clusterStateResp.Result.KafkaBrokerState.ReplicaCountByBrokerID {
+		replicasByBroker[brokerID] = replicas
+	}
+	return replicasByBroker, nil
 }
 
-// BrokerWithLeastPartitionReplicas returns the ID of the broker which host the least partition replicas.
-func (cc *cruiseControlScaler) BrokerWithLeastPartitionReplicas() (string, error) {
+// BrokerWithLeastPartitionReplicas returns the ID of the broker which host the least partition replicas, or the one
+// which is the least utilized with BrokerSelectionByUtilization.
+func (cc *cruiseControlScaler) BrokerWithLeastPartitionReplicas(ctx context.Context, mode BrokerSelectionMode) (string, error) {
+	if mode == BrokerSelectionByUtilization {
+		return cc.leastUtilizedBroker(ctx)
+	}
+
 	var brokerWithLeastPartitionReplicas string
 
-	brokerPartitions, err := cc.PartitionReplicasByBroker()
+	brokerPartitions, err := cc.PartitionReplicasByBroker(ctx)
 	if err != nil {
 		cc.log.Error(err, "could not retrieve partition map for brokers")
 		return brokerWithLeastPartitionReplicas, err
@@ -355,28 +625,117 @@ func (cc *cruiseControlScaler) BrokerWithLeastPartitionReplicas() (string, error
 	return brokerWithLeastPartitionReplicas, nil
 }
 
-// LogDirsByBroker returns the ID of the broker which host the least partition replicas.
-func (cc *cruiseControlScaler) LogDirsByBroker() (map[string]map[LogDirState][]string, error) {
-	resp, err := cc.client.KafkaClusterState(api.KafkaClusterStateRequestWithDefaults())
+// leastUtilizedBroker returns the ID of the least utilized alive broker based on the Kafka cluster load
+func (cc *cruiseControlScaler) leastUtilizedBroker(ctx context.Context) (string, error) {
+	resp, err := cc.kafkaClusterLoad(ctx)
+	if err != nil {
+		cc.log.Error(err, "getting Kafka cluster load from Cruise Control returned an error")
+		return "", err
+	}
+
+	brokers := make([]BrokerUtilization, 0, len(resp.Result.Brokers))
+	for _, broker := range resp.Result.Brokers {
+		if broker.BrokerState != KafkaBrokerAlive {
+			continue
+		}
+		brokers = append(brokers, BrokerUtilization{
+			ID:        strconv.Itoa(int(broker.Broker)),
+			Rack:      broker.Rack,
+			Replicas:  broker.Replicas,
+			DiskUsage: DiskUsage{UsedMB: broker.DiskMB, CapacityMB: broker.DiskCapacityMB},
+		})
+	}
+	return LeastUtilizedBroker(brokers), nil
+}
+
+// LeastUtilizedBroker returns the ID of the least utilized broker. The utilization of a broker is the sum of its
+// partition replicas relative to the broker hosting the most of them, the average replicas per broker of its rack
+// relative to the most loaded rack and the used fraction of its disk capacity. The brokers with unknown disk capacity
+// are considered to be full. The broker with the lowest ID wins a tie, an empty string is returned without brokers.
+func LeastUtilizedBroker(brokers []BrokerUtilization) string {
+	var maxReplicas int32
+	replicasByRack := make(map[string]int32)
+	brokersByRack := make(map[string]int32)
+	for _, broker := range brokers {
+		if broker.Replicas > maxReplicas {
+			maxReplicas = broker.Replicas
+		}
+		replicasByRack[broker.Rack] += broker.Replicas
+		brokersByRack[broker.Rack]++
+	}
+	var maxRackReplicas float64
+	rackReplicas := make(map[string]float64, len(replicasByRack))
+	for rack, replicas := range replicasByRack {
+		rackReplicas[rack] = float64(replicas) / float64(brokersByRack[rack])
+		if rackReplicas[rack] > maxRackReplicas {
+			maxRackReplicas = rackReplicas[rack]
+		}
+	}
+	relative := func(value, max float64) float64 {
+		if max <= 0 {
+			return 0
+		}
+		return value / max
+	}
+
+	var leastUtilized string
+	var leastUtilizedID int
+	lowestUtilization := math.MaxFloat64
+	for _, broker := range brokers {
+		diskUtilization := 1.0
+		if broker.DiskUsage.CapacityMB > 0 {
+			diskUtilization = broker.DiskUsage.UsedMB / broker.DiskUsage.CapacityMB
+		}
+		utilization := relative(float64(broker.Replicas), float64(maxReplicas)) +
+			relative(rackReplicas[broker.Rack], maxRackReplicas) + diskUtilization
+
+		id, _ := strconv.Atoi(broker.ID)
+		if utilization < lowestUtilization || (utilization == lowestUtilization && id < leastUtilizedID) {
+			leastUtilized, leastUtilizedID, lowestUtilization = broker.ID, id, utilization
+		}
+	}
+	return leastUtilized
+}
+
+// LogDirsByBroker returns the online and offline log dirs of every broker in the Kafka cluster with the number of
+// partition replicas they host and their disk usage.
+func (cc *cruiseControlScaler) LogDirsByBroker(ctx context.Context) (map[string]map[LogDirState][]LogDir, error) {
+	resp, err := cc.kafkaClusterState(ctx)
 	if err != nil {
 		cc.log.Error(err, "getting Kafka cluster state from Cruise Control returned an error")
 		return nil, err
 	}
+	diskLoadResp, err := cc.kafkaClusterDiskLoad(ctx)
+	if err != nil {
+		cc.log.Error(err, "getting Kafka cluster disk load from Cruise Control returned an error")
+		return nil, err
+	}
 
-	newLogDirsByBroker := func() map[LogDirState][]string {
-		return map[LogDirState][]string{
+	diskStatsByBroker := make(map[string]map[string]types.DiskStats, len(diskLoadResp.Result.Brokers))
+	for _, broker := range diskLoadResp.Result.Brokers {
+		diskStatsByBroker[strconv.Itoa(int(broker.Broker))] = broker.DiskState
+	}
+	logDirs := func(broker string, paths []string) []LogDir {
+		dirs := make([]LogDir, 0, len(paths))
+		for _, path := range paths {
+			dirs = append(dirs, newLogDir(path, diskStatsByBroker[broker][path]))
+		}
+		return dirs
+	}
+	newLogDirsByBroker := func() map[LogDirState][]LogDir {
+		return map[LogDirState][]LogDir{
 			LogDirStateOnline:  {},
 			LogDirStateOffline: {},
 		}
 	}
 
-	logDirsByBrokers := make(map[string]map[LogDirState][]string)
+	logDirsByBrokers := make(map[string]map[LogDirState][]LogDir)
 	for broker, onlineLogDirs := range resp.Result.KafkaBrokerState.OnlineLogDirsByBrokerID {
 		logDirsByBroker, ok := logDirsByBrokers[broker]
 		if !ok || logDirsByBroker == nil {
 			logDirsByBroker = newLogDirsByBroker()
 		}
-		logDirsByBroker[LogDirStateOnline] = onlineLogDirs
+		logDirsByBroker[LogDirStateOnline] = logDirs(broker, onlineLogDirs)
 		logDirsByBrokers[broker] = logDirsByBroker
 	}
 	for broker, offlineLogDirs := range resp.Result.KafkaBrokerState.OfflineLogDirsByBrokerID {
@@ -384,8 +743,472 @@ func (cc *cruiseControlScaler) LogDirsByBroker() (map[string]map[LogDirState][]s
 		if !ok || logDirsByBroker == nil {
 			logDirsByBroker = newLogDirsByBroker()
 		}
-		logDirsByBroker[LogDirStateOffline] = offlineLogDirs
+		logDirsByBroker[LogDirStateOffline] = logDirs(broker, offlineLogDirs)
 		logDirsByBrokers[broker] = logDirsByBroker
 	}
 	return logDirsByBrokers, nil
 }
+
+// newLogDir returns the log dir at path with the given disk stats
+func newLogDir(path string, stats types.DiskStats) LogDir {
+	logDir := LogDir{
+		Path:           path,
+		Replicas:       stats.NumReplicas,
+		LeaderReplicas: stats.NumLeaderReplicas,
+	}
+	if stats.DiskMB.Dead {
+		return logDir
+	}
+	logDir.UsedMB = stats.DiskMB.Usage
+	if stats.DiskPct.Usage > 0 {
+		logDir.CapacityMB = stats.DiskMB.Usage / stats.DiskPct.Usage * 100
+	}
+	return logDir
+}
+
+// DiskUsageByBroker returns the used and total disk capacity for every broker in the Kafka cluster.
+func (cc *cruiseControlScaler) DiskUsageByBroker(ctx context.Context) (map[string]DiskUsage, error) {
+	resp, err := cc.kafkaClusterLoad(ctx)
+	if err != nil {
+		cc.log.Error(err, "getting Kafka cluster load from Cruise Control returned an error")
+		return nil, err
+	}
+
+	diskUsageByBroker := make(map[string]DiskUsage, len(resp.Result.Brokers))
+	for _, broker := range resp.Result.Brokers {
+		diskUsageByBroker[strconv.Itoa(int(broker.Broker))] = DiskUsage{
+			UsedMB:     broker.DiskMB,
+			CapacityMB: broker.DiskCapacityMB,
+		}
+	}
+	return diskUsageByBroker, nil
+}
+
+// BrokerLoads returns the resource utilization of every broker in the Kafka cluster.
+func (cc *cruiseControlScaler) BrokerLoads(ctx context.Context) (map[string]BrokerLoad, error) {
+	resp, err := cc.kafkaClusterLoad(ctx)
+	if err != nil {
+		cc.log.Error(err, "getting Kafka cluster load from Cruise Control returned an error")
+		return nil, err
+	}
+
+	loadByBroker := make(map[string]BrokerLoad, len(resp.Result.Brokers))
+	for _, broker := range resp.Result.Brokers {
+		loadByBroker[strconv.Itoa(int(broker.Broker))] = BrokerLoad{
+			CPUPct:           broker.CPUPct,
+			DiskPct:          broker.DiskPct,
+			Leaders:          broker.Leaders,
+			Replicas:         broker.Replicas,
+			NetworkInRateKB:  broker.LeaderNwInRate + broker.FollowerNwInRate,
+			NetworkOutRateKB: broker.NwOutRate,
+		}
+	}
+	return loadByBroker, nil
+}
+
+// GoalViolations returns the recent goal violations detected by the anomaly detector of Cruise Control.
+func (cc *cruiseControlScaler) GoalViolations(ctx context.Context) ([]GoalViolation, error) {
+	req := api.StateRequestWithDefaults()
+	req.Substates = []types.Substate{types.SubstateAnomalyDetector}
+	req.Verbose = true
+	resp, err := cc.client.State(ctx, req)
+	if err != nil {
+		cc.log.Error(err, "getting anomaly detector state from Cruise Control returned an error")
+		return nil, err
+	}
+
+	return goalViolations(resp.Result.AnomalyDetectorState.RecentGoalViolations), nil
+}
+
+// RebalanceWithGoals performs a rebalance via Cruise Control optimizing only the given goals.
+// Replicas are not moved to the excluded brokers and leadership is not moved to them if they were demoted.
+// Only the replicas of the topics matching the topics regular expression are moved if it is not empty.
+func (cc *cruiseControlScaler) RebalanceWithGoals(ctx context.Context, excludedBrokerIDs []string, topics string, goals ...string) (*Result, error) {
+	defer cc.invalidateCache()
+
+	rebalanceGoals, err := goalsFromStringSlice(goals)
+	if err != nil {
+		return nil, err
+	}
+
+	rebalanceReq := &api.RebalanceRequest{
+		AllowCapacityEstimation:       true,
+		DataFrom:                      types.ProposalDataSourceValidWindows,
+		Goals:                         rebalanceGoals,
+		SkipHardGoalCheck:             true,
+		ExcludeRecentlyRemovedBrokers: true,
+		ExcludedTopics:                excludedTopicsPattern(topics),
+	}
+
+	if len(excludedBrokerIDs) > 0 {
+		availableBrokers, err := cc.BrokersWithState(ctx, KafkaBrokerAlive, KafkaBrokerNew)
+		if err != nil {
+			cc.log.Error(err, "failed to retrieve list of available brokers from Cruise Control")
+			return nil, err
+		}
+		excludedBrokersMap := stringSliceToMap(excludedBrokerIDs)
+		destinationBrokerIDs := make([]string, 0, len(availableBrokers))
+		for _, id := range availableBrokers {
+			if _, ok := excludedBrokersMap[id]; !ok {
+				destinationBrokerIDs = append(destinationBrokerIDs, id)
+			}
+		}
+		if len(destinationBrokerIDs) == 0 {
+			return nil, errors.New("no available brokers left to rebalance to after excluding brokers")
+		}
+		rebalanceReq.DestinationBrokerIDs, err = brokerIDsFromStringSlice(destinationBrokerIDs)
+		if err != nil {
+			cc.log.Error(err, "failed to cast broker IDs from string slice")
+			return nil, err
+		}
+		rebalanceReq.ExcludeRecentlyDemotedBrokers = true
+	}
+
+	rebalanceResp, err := cc.client.Rebalance(ctx, rebalanceReq)
+	if err != nil {
+		return failedResult(rebalanceResp.TaskID, rebalanceResp.Date, err), err
+	}
+
+	return &Result{
+		TaskID:    rebalanceResp.TaskID,
+		StartedAt: taskStartTime(rebalanceResp.Date),
+		State:     v1beta1.CruiseControlTaskActive,
+	}, nil
+}
+
+// GetProposals returns the optimization proposals of Cruise Control for the given goals, or for the default goals if
+// none is given, without executing them.
+func (cc *cruiseControlScaler) GetProposals(ctx context.Context, goals ...string) (*ProposalSummary, error) {
+	proposalGoals, err := goalsFromStringSlice(goals)
+	if err != nil {
+		return nil, err
+	}
+
+	proposalsReq := api.ProposalsRequestWithDefaults()
+	proposalsReq.Goals = proposalGoals
+	proposalsReq.ExcludeRecentlyRemovedBrokers = true
+	proposalsReq.ExcludeRecentlyDemotedBrokers = true
+
+	proposalsResp, err := cc.client.Proposals(ctx, proposalsReq)
+	if err != nil {
+		cc.log.Error(err, "getting optimization proposals from Cruise Control returned an error")
+		return nil, err
+	}
+	if proposalsResp.Result == nil {
+		return nil, errors.New("optimization proposals are still being computed by Cruise Control")
+	}
+
+	summary := proposalsResp.Result.Summary
+	goalSummaries := make([]ProposalGoalSummary, 0, len(proposalsResp.Result.GoalSummary))
+	for _, goal := range proposalsResp.Result.GoalSummary {
+		goalSummaries = append(goalSummaries, ProposalGoalSummary{
+			Goal:   goal.Goal.String(),
+			Status: goal.Status.String(),
+		})
+	}
+	return &ProposalSummary{
+		NumReplicaMovements:            summary.NumReplicaMovements,
+		DataToMoveMB:                   summary.DataToMoveMB,
+		NumIntraBrokerReplicaMovements: summary.NumIntraBrokerReplicaMovements,
+		IntraBrokerDataToMoveMB:        summary.IntraBrokerDataToMoveMB,
+		NumLeaderMovements:             summary.NumLeaderMovements,
+		BalancednessScoreBefore:        summary.OnDemandBalancednessScoreBefore,
+		BalancednessScoreAfter:         summary.OnDemandBalancednessScoreAfter,
+		Goals:                          goalSummaries,
+	}, nil
+}
+
+// failedResult returns the Result of an operation which could not be started. Its state tells apart the operations
+// which failed because Cruise Control is unavailable, so that they can be retried once it is back.
+func failedResult(taskID, startedAt string, err error) *Result {
+	state := v1beta1.CruiseControlTaskCompletedWithError
+	if errors.Is(err, ErrCruiseControlUnavailable) {
+		state = v1beta1.CruiseControlTaskUnavailable
+	}
+	return &Result{
+		TaskID:    taskID,
+		StartedAt: taskStartTime(startedAt),
+		State:     state,
+		Err:       fmt.Sprintf("%v", err),
+	}
+}
+
+// taskStartTime returns the time in the Date header of the response of Cruise Control to the request starting a task
+// in RFC3339, or the header as is if it can not be parsed
+func taskStartTime(date string) string {
+	startedAt, err := http.ParseTime(date)
+	if err != nil {
+		return date
+	}
+	return startedAt.UTC().Format(time.RFC3339)
+}
+
+// excludedTopicsPattern returns the regular expression of Cruise Control matching the topics which do not match the
+// given one, as Cruise Control can only be told the topics to exclude from a rebalance. Cruise Control matches the
+// whole topic names against Java regular expressions, which support the negative lookahead.
+func excludedTopicsPattern(topics string) string {
+	if topics == "" {
+		return ""
+	}
+	return "(?!(?:" + topics + ")$).*"
+}
+
+// goalsFromStringSlice parses the names of Cruise Control goals
+func goalsFromStringSlice(names []string) ([]types.Goal, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	goals := make([]types.Goal, 0, len(names))
+	for _, name := range names {
+		var goal types.Goal
+		if err := goal.UnmarshalText([]byte(name)); err != nil || goal == types.UndefinedGoal {
+			return nil, fmt.Errorf("unknown Cruise Control goal: %s", name)
+		}
+		goals = append(goals, goal)
+	}
+	return goals, nil
+}
+
+// WatchTask delivers the changes of the user task with the given ID until it is finished, the task is polled more
+// frequently while it is changing.
+func (cc *cruiseControlScaler) WatchTask(ctx context.Context, taskID string) <-chan TaskUpdate {
+	return PollTask(ctx, cc, taskID, DefaultPollConfig)
+}
+
+// DemoteBrokers requests Cruise Control to move the leadership of the partitions off from the provided brokers
+// without moving data, the demoted brokers are moved to the end of the replica lists to not regain leadership
+// by preferred leader election.
+func (cc *cruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*Result, error) {
+	defer cc.invalidateCache()
+
+	if len(brokerIDs) == 0 {
+		return nil, errors.New("no broker id(s) provided for demote brokers request")
+	}
+
+	brokersToDemote, err := brokerIDsFromStringSlice(brokerIDs)
+	if err != nil {
+		cc.log.Error(err, "failed to cast broker IDs from string slice")
+		return nil, err
+	}
+
+	demoteBrokerReq := &api.DemoteBrokerRequest{
+		AllowCapacityEstimation: true,
+		BrokerIDs:               brokersToDemote,
+	}
+	demoteBrokerResp, err := cc.client.DemoteBroker(ctx, demoteBrokerReq)
+	if err != nil {
+		return failedResult(demoteBrokerResp.TaskID, demoteBrokerResp.Date, err), err
+	}
+
+	return &Result{
+		TaskID:    demoteBrokerResp.TaskID,
+		StartedAt: taskStartTime(demoteBrokerResp.Date),
+		State:     v1beta1.CruiseControlTaskActive,
+	}, nil
+}
+
+// UpdateTopicReplicationFactor requests Cruise Control to change the replication factor of the topics matching
+// the provided pattern, the new replicas are placed honoring the goals of Cruise Control, e.g. rack awareness.
+func (cc *cruiseControlScaler) UpdateTopicReplicationFactor(ctx context.Context, topic string, replicationFactor int32) (*Result, error) {
+	defer cc.invalidateCache()
+
+	if topic == "" {
+		return nil, errors.New("no topic provided for topic configuration request")
+	}
+	if replicationFactor < 1 {
+		return nil, fmt.Errorf("invalid replication factor %d for topic configuration request", replicationFactor)
+	}
+
+	topicConfigurationReq := &api.TopicConfigurationRequest{
+		AllowCapacityEstimation: true,
+		Topic:                   topic,
+		ReplicationFactor:       replicationFactor,
+	}
+	topicConfigurationResp, err := cc.client.TopicConfiguration(ctx, topicConfigurationReq)
+	if err != nil {
+		return failedResult(topicConfigurationResp.TaskID, topicConfigurationResp.Date, err), err
+	}
+
+	return &Result{
+		TaskID:    topicConfigurationResp.TaskID,
+		StartedAt: taskStartTime(topicConfigurationResp.Date),
+		State:     v1beta1.CruiseControlTaskActive,
+	}, nil
+}
+
+// StopExecution requests Cruise Control to stop the ongoing proposal execution, e.g. the replica movements of a
+// remove brokers task. The partitions already reassigned are not moved back.
+func (cc *cruiseControlScaler) StopExecution(ctx context.Context) error {
+	defer cc.invalidateCache()
+
+	_, err := cc.client.StopProposalExecution(ctx, api.StopProposalExecutionRequestWithDefaults())
+	if err != nil {
+		cc.log.Error(err, "failed to stop the ongoing execution of Cruise Control")
+	}
+	return err
+}
+
+// PauseSampling requests Cruise Control to pause the metrics sampling of its load monitor, e.g. while the brokers are
+// restarted, so that the load model is not skewed by the metrics of the restarting brokers.
+func (cc *cruiseControlScaler) PauseSampling(ctx context.Context, reason string) error {
+	req := api.PauseSamplingRequestWithDefaults()
+	req.Reason = reason
+	if _, err := cc.client.PauseSampling(ctx, req); err != nil {
+		cc.log.Error(err, "failed to pause the metrics sampling of Cruise Control")
+		return err
+	}
+	return nil
+}
+
+// ResumeSampling requests Cruise Control to resume the metrics sampling of its load monitor.
+func (cc *cruiseControlScaler) ResumeSampling(ctx context.Context, reason string) error {
+	req := api.ResumeSamplingRequestWithDefaults()
+	req.Reason = reason
+	if _, err := cc.client.ResumeSampling(ctx, req); err != nil {
+		cc.log.Error(err, "failed to resume the metrics sampling of Cruise Control")
+		return err
+	}
+	return nil
+}
+
+// UpdateExecutorConcurrency requests Cruise Control to change the upper bounds of its concurrent movements, the ones
+// which are 0 are left unchanged. The new bounds apply to the ongoing execution too and last until Cruise Control is
+// restarted or they are changed again.
+func (cc *cruiseControlScaler) UpdateExecutorConcurrency(ctx context.Context, concurrency ExecutorConcurrency) error {
+	if concurrency.IsZero() {
+		return errors.New("no executor concurrency provided for admin request")
+	}
+	if concurrency.PartitionMovementsPerBroker < 0 || concurrency.LeaderMovements < 0 || concurrency.IntraBrokerPartitionMovements < 0 {
+		return fmt.Errorf("executor concurrency must not be negative: %+v", concurrency)
+	}
+
+	adminReq := &api.AdminRequest{
+		ConcurrentPartitionMovementsPerBroker:   concurrency.PartitionMovementsPerBroker,
+		ConcurrentLeaderMovements:               concurrency.LeaderMovements,
+		ConcurrentIntraBrokerPartitionMovements: concurrency.IntraBrokerPartitionMovements,
+	}
+	if _, err := cc.client.Admin(ctx, adminReq); err != nil {
+		cc.log.Error(err, "failed to update the executor concurrency of Cruise Control")
+		return err
+	}
+	return nil
+}
+
+// UpdateSelfHealing requests Cruise Control to enable and disable its self-healing for the given anomaly types, e.g.
+// BROKER_FAILURE. The settings last until Cruise Control is restarted or they are changed again.
+func (cc *cruiseControlScaler) UpdateSelfHealing(ctx context.Context, enabled, disabled []string) error {
+	if len(enabled) == 0 && len(disabled) == 0 {
+		return errors.New("no anomaly types provided for admin request")
+	}
+	enableFor, err := parseAnomalyTypes(enabled)
+	if err != nil {
+		return err
+	}
+	disableFor, err := parseAnomalyTypes(disabled)
+	if err != nil {
+		return err
+	}
+
+	adminReq := &api.AdminRequest{
+		EnableSelfHealingFor:  enableFor,
+		DisableSelfHealingFor: disableFor,
+	}
+	if _, err := cc.client.Admin(ctx, adminReq); err != nil {
+		cc.log.Error(err, "failed to update the self-healing of Cruise Control")
+		return err
+	}
+	return nil
+}
+
+func parseAnomalyTypes(names []string) ([]types.AnomalyType, error) {
+	anomalyTypes := make([]types.AnomalyType, 0, len(names))
+	for _, name := range names {
+		var anomalyType types.AnomalyType
+		if err := anomalyType.UnmarshalText([]byte(name)); err != nil || anomalyType == types.AnomalyTypeUndefined {
+			return nil, fmt.Errorf("unknown anomaly type: %s", name)
+		}
+		anomalyTypes = append(anomalyTypes, anomalyType)
+	}
+	return anomalyTypes, nil
+}
+
+// Rightsize requests the provisioner of Cruise Control to add brokers to the cluster or to increase the partition count
+// of topics, and returns the recommendation of the provisioner
+func (cc *cruiseControlScaler) Rightsize(ctx context.Context, opts RightsizeOptions) (*RightsizeResult, error) {
+	if opts.BrokersToAdd < 0 || opts.PartitionCount < 0 {
+		return nil, fmt.Errorf("rightsize request must not be negative: %+v", opts)
+	}
+	if opts.BrokersToAdd == 0 && opts.PartitionCount == 0 {
+		return nil, errors.New("no brokers to add or partition count provided for rightsize request")
+	}
+
+	rightsizeReq := &api.RightsizeRequest{
+		NumberOfBrokersToAdd: opts.BrokersToAdd,
+		PartitionCount:       opts.PartitionCount,
+		Topic:                opts.Topic,
+	}
+	resp, err := cc.client.Rightsize(ctx, rightsizeReq)
+	if err != nil {
+		cc.log.Error(err, "failed to rightsize the cluster via Cruise Control")
+		return nil, err
+	}
+	if resp.Result == nil {
+		return nil, errors.New("no rightsize result returned by Cruise Control")
+	}
+
+	return &RightsizeResult{
+		BrokersToAdd:     resp.Result.NumberOfBrokersToAdd,
+		PartitionCount:   resp.Result.PartitionCount,
+		Topic:            resp.Result.Topic,
+		ProvisionerState: resp.Result.ProvisionerState.String(),
+	}, nil
+}
+
+// FixOfflineReplicas requests Cruise Control to move the offline partition replicas, e.g. the ones on a dead disk,
+// to the healthy disks and brokers of the cluster, so they are replicated again.
+func (cc *cruiseControlScaler) FixOfflineReplicas(ctx context.Context) (*Result, error) {
+	defer cc.invalidateCache()
+
+	fixOfflineReplicasReq := api.FixOfflineReplicasRequestWithDefaults()
+	fixOfflineReplicasReq.UseReadyDefaultGoals = true
+	fixOfflineReplicasResp, err := cc.client.FixOfflineReplicas(ctx, fixOfflineReplicasReq)
+	if err != nil {
+		return failedResult(fixOfflineReplicasResp.TaskID, fixOfflineReplicasResp.Date, err), err
+	}
+
+	return &Result{
+		TaskID:    fixOfflineReplicasResp.TaskID,
+		StartedAt: taskStartTime(fixOfflineReplicasResp.Date),
+		State:     v1beta1.CruiseControlTaskActive,
+	}, nil
+}
+
+// RemoveDisks requests Cruise Control to move the partition replicas off from the provided log dirs of the broker to
+// its other log dirs, so that a JBOD volume can be detached without removing the whole broker.
+func (cc *cruiseControlScaler) RemoveDisks(ctx context.Context, brokerID string, logDirs []string) (*Result, error) {
+	defer cc.invalidateCache()
+
+	if len(logDirs) == 0 {
+		return nil, errors.New("no log dir(s) provided for remove disks request")
+	}
+
+	brokerIDs, err := brokerIDsFromStringSlice([]string{brokerID})
+	if err != nil {
+		cc.log.Error(err, "failed to cast broker ID from string")
+		return nil, err
+	}
+
+	removeDisksReq := &RemoveDisksRequest{
+		BrokerIDAndLogDirs: types.BrokerIDAndLogDirs{brokerIDs[0]: logDirs},
+	}
+	removeDisksResp, err := cc.client.RemoveDisks(ctx, removeDisksReq)
+	if err != nil {
+		return failedResult(removeDisksResp.TaskID, removeDisksResp.Date, err), err
+	}
+
+	return &Result{
+		TaskID:    removeDisksResp.TaskID,
+		StartedAt: taskStartTime(removeDisksResp.Date),
+		State:     v1beta1.CruiseControlTaskActive,
+	}, nil
+}
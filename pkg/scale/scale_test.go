@@ -0,0 +1,1056 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newFakeCluster() *FakeCruiseControlClient {
+	return NewFakeCruiseControlClient(
+		FakeBroker{ID: 0, State: KafkaBrokerAlive, Replicas: 10, DiskMB: 100, DiskCapacityMB: 1000, DiskReplicas: []int32{10}},
+		FakeBroker{ID: 1, State: KafkaBrokerAlive, Replicas: 12, DiskMB: 120, DiskCapacityMB: 1000, DiskReplicas: []int32{12, 0}},
+		FakeBroker{ID: 2, State: KafkaBrokerNew, DiskCapacityMB: 1000, DiskReplicas: []int32{0}},
+	)
+}
+
+func TestCruiseControlScalerStatus(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if !scaler.IsUp(context.TODO()) || !scaler.IsReady(context.TODO()) {
+		t.Error("expected Cruise Control to be up and ready")
+	}
+
+	fake.FailNext(api.EndpointState, errors.New("connection refused"))
+	if scaler.IsUp(context.TODO()) {
+		t.Error("expected Cruise Control to be down")
+	}
+	if !scaler.IsUp(context.TODO()) {
+		t.Error("expected Cruise Control to be up once the scripted error is returned")
+	}
+
+	fake.StateResult = &types.StateResult{}
+	if scaler.IsReady(context.TODO()) {
+		t.Error("expected Cruise Control not to be ready")
+	}
+}
+
+func TestCruiseControlScalerRemoveBrokers(t *testing.T) {
+	fake := newFakeCluster()
+	fake.TaskProgression = []types.UserTaskStatus{types.UserTaskStatusInExecution, types.UserTaskStatusCompleted}
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	result, err := scaler.RemoveBrokers(context.TODO(), OperationOptions{}, "1", "2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.State != v1beta1.CruiseControlTaskActive || result.TaskID == "" {
+		t.Fatalf("expected active task, got: %+v", result)
+	}
+	// broker 2 has no replicas so it is left out of the request
+	if tasks := fake.Tasks(); len(tasks) != 1 || !reflect.DeepEqual(tasks[0].BrokerIDs, []int32{1}) {
+		t.Errorf("expected a single task removing broker 1, got: %+v", tasks)
+	}
+
+	for _, expected := range []v1beta1.CruiseControlUserTaskState{v1beta1.CruiseControlTaskInExecution, v1beta1.CruiseControlTaskCompleted} {
+		tasks, err := scaler.GetUserTasks(context.TODO(), result.TaskID)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(tasks) != 1 || tasks[0].State != expected {
+			t.Fatalf("expected task state %s, got: %+v", expected, tasks)
+		}
+	}
+
+	replicas, err := scaler.PartitionReplicasByBroker(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if replicas["1"] != 0 {
+		t.Errorf("expected the replicas to be moved off broker 1, got: %d", replicas["1"])
+	}
+	if broker, _ := scaler.BrokerWithLeastPartitionReplicas(context.TODO(), BrokerSelectionByReplicas); broker != "1" && broker != "2" {
+		t.Errorf("expected one of the empty brokers, got: %s", broker)
+	}
+}
+
+func TestCruiseControlScalerBrokerWithLeastPartitionReplicasByUtilization(t *testing.T) {
+	fake := NewFakeCruiseControlClient(
+		// rack-a hosts the most replicas
+		FakeBroker{ID: 0, State: KafkaBrokerAlive, Rack: "rack-a", Replicas: 40, DiskMB: 400, DiskCapacityMB: 1000},
+		FakeBroker{ID: 1, State: KafkaBrokerAlive, Rack: "rack-a", Replicas: 10, DiskMB: 100, DiskCapacityMB: 1000},
+		// broker 2 hosts a few huge replicas
+		FakeBroker{ID: 2, State: KafkaBrokerAlive, Rack: "rack-b", Replicas: 10, DiskMB: 900, DiskCapacityMB: 1000},
+		FakeBroker{ID: 3, State: KafkaBrokerAlive, Rack: "rack-b", Replicas: 20, DiskMB: 200, DiskCapacityMB: 1000},
+		FakeBroker{ID: 4, State: KafkaBrokerDead, Rack: "rack-c"},
+	)
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	broker, err := scaler.BrokerWithLeastPartitionReplicas(context.TODO(), BrokerSelectionByReplicas)
+	if err != nil || broker != "4" {
+		t.Errorf("expected the broker without replicas, got: %s, error: %v", broker, err)
+	}
+	broker, err = scaler.BrokerWithLeastPartitionReplicas(context.TODO(), BrokerSelectionByUtilization)
+	if err != nil || broker != "3" {
+		t.Errorf("expected the least utilized alive broker, got: %s, error: %v", broker, err)
+	}
+}
+
+func TestLeastUtilizedBroker(t *testing.T) {
+	testCases := []struct {
+		testName string
+		brokers  []BrokerUtilization
+		expected string
+	}{
+		{
+			testName: "no brokers",
+		},
+		{
+			testName: "the broker with the lowest ID wins a tie",
+			brokers: []BrokerUtilization{
+				{ID: "10", Replicas: 5, DiskUsage: DiskUsage{UsedMB: 10, CapacityMB: 100}},
+				{ID: "2", Replicas: 5, DiskUsage: DiskUsage{UsedMB: 10, CapacityMB: 100}},
+			},
+			expected: "2",
+		},
+		{
+			testName: "the free disk capacity outweighs a few replicas",
+			brokers: []BrokerUtilization{
+				{ID: "0", Replicas: 10, DiskUsage: DiskUsage{UsedMB: 95, CapacityMB: 100}},
+				{ID: "1", Replicas: 12, DiskUsage: DiskUsage{UsedMB: 10, CapacityMB: 100}},
+			},
+			expected: "1",
+		},
+		{
+			testName: "the broker with unknown disk capacity is considered to be full",
+			brokers: []BrokerUtilization{
+				{ID: "0", Replicas: 10},
+				{ID: "1", Replicas: 12, DiskUsage: DiskUsage{UsedMB: 50, CapacityMB: 100}},
+			},
+			expected: "1",
+		},
+		{
+			testName: "the broker of the least loaded rack is preferred",
+			brokers: []BrokerUtilization{
+				{ID: "0", Rack: "rack-a", Replicas: 10, DiskUsage: DiskUsage{UsedMB: 10, CapacityMB: 100}},
+				{ID: "1", Rack: "rack-a", Replicas: 30, DiskUsage: DiskUsage{UsedMB: 30, CapacityMB: 100}},
+				{ID: "2", Rack: "rack-b", Replicas: 12, DiskUsage: DiskUsage{UsedMB: 12, CapacityMB: 100}},
+			},
+			expected: "2",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			if broker := LeastUtilizedBroker(test.brokers); broker != test.expected {
+				t.Errorf("expected broker %q, got %q", test.expected, broker)
+			}
+		})
+	}
+}
+
+func TestCruiseControlScalerAddBrokers(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if _, err := scaler.AddBrokers(context.TODO(), OperationOptions{}, "3"); err == nil {
+		t.Error("expected error for broker unknown to Cruise Control")
+	}
+
+	fake.FailNext(api.EndpointAddBroker, errors.New("proposal generation failed"))
+	result, err := scaler.AddBrokers(context.TODO(), OperationOptions{}, "2")
+	if err == nil || result.State != v1beta1.CruiseControlTaskCompletedWithError {
+		t.Errorf("expected failed task, got: %+v, error: %v", result, err)
+	}
+
+	result, err = scaler.AddBrokers(context.TODO(), OperationOptions{}, "2")
+	if err != nil || result.State != v1beta1.CruiseControlTaskActive {
+		t.Errorf("expected active task, got: %+v, error: %v", result, err)
+	}
+}
+
+// addBrokerRecordingClient records the add broker requests sent to the FakeCruiseControlClient
+type addBrokerRecordingClient struct {
+	*FakeCruiseControlClient
+	requests []*api.AddBrokerRequest
+}
+
+func (c *addBrokerRecordingClient) AddBroker(ctx context.Context, r *api.AddBrokerRequest) (*api.AddBrokerResponse, error) {
+	c.requests = append(c.requests, r)
+	return c.FakeCruiseControlClient.AddBroker(ctx, r)
+}
+
+func TestCruiseControlScalerAddBrokersWithOptions(t *testing.T) {
+	cruisecontrol := &addBrokerRecordingClient{FakeCruiseControlClient: newFakeCluster()}
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), cruisecontrol)
+
+	if _, err := scaler.AddBrokers(context.TODO(), OperationOptions{Goals: []string{"NoSuchGoal"}}, "2"); err == nil {
+		t.Error("expected error for unknown goal")
+	}
+	if len(cruisecontrol.requests) != 0 {
+		t.Errorf("expected no add broker request for unknown goal, got: %d", len(cruisecontrol.requests))
+	}
+
+	if _, err := scaler.AddBrokers(context.TODO(), OperationOptions{}, "2"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := scaler.AddBrokers(context.TODO(), OperationOptions{Goals: []string{"RackAwareGoal", "DiskCapacityGoal"}, ReplicationThrottle: 50000000}, "2"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(cruisecontrol.requests) != 2 {
+		t.Fatalf("expected two add broker requests, got: %d", len(cruisecontrol.requests))
+	}
+
+	// the ready default goals and the default throttle are used without options
+	req := cruisecontrol.requests[0]
+	if !req.UseReadyDefaultGoals || req.SkipHardGoalCheck || len(req.Goals) != 0 || req.ReplicationThrottle != 0 {
+		t.Errorf("expected the ready default goals to be used, got: %+v", req)
+	}
+	req = cruisecontrol.requests[1]
+	if req.UseReadyDefaultGoals || !req.SkipHardGoalCheck ||
+		!reflect.DeepEqual(req.Goals, []types.Goal{types.RackAwareGoal, types.DiskCapacityGoal}) {
+		t.Errorf("expected only the given goals to be used, got: %+v", req)
+	}
+	if req.ReplicationThrottle != 50000000 {
+		t.Errorf("expected replication throttle of 50000000 bytes/s, got: %d", req.ReplicationThrottle)
+	}
+}
+
+// unavailableClient fails the add broker requests as if Cruise Control was unavailable
+type unavailableClient struct {
+	*FakeCruiseControlClient
+}
+
+func (c *unavailableClient) AddBroker(ctx context.Context, r *api.AddBrokerRequest) (*api.AddBrokerResponse, error) {
+	return &api.AddBrokerResponse{}, fmt.Errorf("%w: connection refused", ErrCruiseControlUnavailable)
+}
+
+func TestCruiseControlScalerUnavailable(t *testing.T) {
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), &unavailableClient{FakeCruiseControlClient: newFakeCluster()})
+
+	result, err := scaler.AddBrokers(context.TODO(), OperationOptions{}, "2")
+	if !errors.Is(err, ErrCruiseControlUnavailable) {
+		t.Fatalf("expected Cruise Control to be unavailable, got: %v", err)
+	}
+	if result.State != v1beta1.CruiseControlTaskUnavailable || result.Err == "" {
+		t.Errorf("expected task state %s, got: %+v", v1beta1.CruiseControlTaskUnavailable, result)
+	}
+}
+
+func TestCruiseControlScalerRebalanceDisks(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	result, err := scaler.RebalanceDisks(context.TODO(), OperationOptions{}, "0", "1")
+	if err != nil || result.State != v1beta1.CruiseControlTaskActive {
+		t.Fatalf("expected active task, got: %+v, error: %v", result, err)
+	}
+	if tasks := fake.Tasks(); len(tasks) != 1 || !reflect.DeepEqual(tasks[0].BrokerIDs, []int32{1}) {
+		t.Errorf("expected a single task rebalancing the disks of broker 1, got: %+v", tasks)
+	}
+	if _, err := scaler.GetUserTasks(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, err = scaler.RebalanceDisks(context.TODO(), OperationOptions{}, "0", "1")
+	if err != nil || result.State != v1beta1.CruiseControlTaskCompleted {
+		t.Errorf("expected no rebalance once the disks are in use, got: %+v, error: %v", result, err)
+	}
+
+	usage, err := scaler.DiskUsageByBroker(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if usage["1"] != (DiskUsage{UsedMB: 120, CapacityMB: 1000}) {
+		t.Errorf("unexpected disk usage of broker 1: %+v", usage["1"])
+	}
+}
+
+func TestCruiseControlScalerIntraBrokerRebalance(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if _, err := scaler.IntraBrokerRebalance(context.TODO(), OperationOptions{Goals: []string{"DiskUsageDistributionGoal"}}, "1"); err == nil {
+		t.Error("expected error for goal which is not an intra-broker goal")
+	}
+
+	result, err := scaler.IntraBrokerRebalance(context.TODO(), OperationOptions{}, "0")
+	if err != nil || result.State != v1beta1.CruiseControlTaskCompleted {
+		t.Fatalf("expected no rebalance for broker without empty disks, got: %+v, error: %v", result, err)
+	}
+	if len(fake.Tasks()) != 0 {
+		t.Fatalf("expected no task to be started, got: %+v", fake.Tasks())
+	}
+
+	result, err = scaler.IntraBrokerRebalance(context.TODO(), OperationOptions{}, "1")
+	if err != nil || result.State != v1beta1.CruiseControlTaskActive {
+		t.Fatalf("expected active task, got: %+v, error: %v", result, err)
+	}
+	if tasks := fake.Tasks(); len(tasks) != 1 || !tasks[0].IntraBroker {
+		t.Errorf("expected a single intra-broker rebalance task, got: %+v", tasks)
+	}
+	if _, err := scaler.GetUserTasks(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, err = scaler.IntraBrokerRebalance(context.TODO(), OperationOptions{}, "1")
+	if err != nil || result.State != v1beta1.CruiseControlTaskCompleted {
+		t.Errorf("expected no rebalance once the disks are in use, got: %+v, error: %v", result, err)
+	}
+}
+
+func TestCruiseControlScalerBrokerLoads(t *testing.T) {
+	fake := NewFakeCruiseControlClient(
+		FakeBroker{ID: 0, State: KafkaBrokerAlive, Replicas: 10, Leaders: 4, CPUPct: 12.5, DiskMB: 250, DiskCapacityMB: 1000,
+			NwInRate: 100, NwOutRate: 300},
+	)
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	loads, err := scaler.BrokerLoads(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := BrokerLoad{CPUPct: 12.5, DiskPct: 25, Leaders: 4, Replicas: 10, NetworkInRateKB: 100, NetworkOutRateKB: 300}
+	if len(loads) != 1 || loads["0"] != expected {
+		t.Errorf("expected load %+v of broker 0, got: %+v", expected, loads)
+	}
+
+	// the load is not cached by a new scaler
+	scaler = NewCruiseControlScalerWithClient(logr.Discard(), fake)
+	fake.FailNext(api.EndpointKafkaClusterLoad, errors.New("connection refused"))
+	if _, err := scaler.BrokerLoads(context.TODO()); err == nil {
+		t.Error("expected error when Cruise Control is not available")
+	}
+}
+
+func TestCruiseControlScalerClusterStateCache(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+	now := time.Date(2022, 5, 4, 10, 30, 0, 0, time.UTC)
+	scaler.(*cruiseControlScaler).cache.now = func() time.Time { return now }
+
+	countRequests := func(endpoint types.APIEndpoint) int {
+		var count int
+		for _, requested := range fake.Requests() {
+			if requested == endpoint {
+				count++
+			}
+		}
+		return count
+	}
+
+	if _, err := scaler.PartitionReplicasByBroker(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := scaler.LogDirsByBroker(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := scaler.BrokersWithState(context.TODO(), KafkaBrokerAlive); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := scaler.DiskUsageByBroker(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// the load is requested once with and once without the disk info of the brokers
+	if state, load := countRequests(api.EndpointKafkaClusterState), countRequests(api.EndpointKafkaClusterLoad); state != 1 || load != 2 {
+		t.Errorf("expected the cluster state and load to be requested once, got: %d and %d requests", state, load)
+	}
+
+	// the responses are requested again once the TTL has passed
+	now = now.Add(DefaultClusterStateCacheTTL)
+	if _, err := scaler.PartitionReplicasByBroker(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if count := countRequests(api.EndpointKafkaClusterState); count != 2 {
+		t.Errorf("expected the cluster state to be requested again after the TTL, got: %d requests", count)
+	}
+
+	// the operations moving replicas invalidate the cache
+	if _, err := scaler.AddBrokers(context.TODO(), OperationOptions{}, "2"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := scaler.BrokersWithState(context.TODO(), KafkaBrokerAlive); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := scaler.PartitionReplicasByBroker(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if state, load := countRequests(api.EndpointKafkaClusterState), countRequests(api.EndpointKafkaClusterLoad); state != 3 || load != 4 {
+		t.Errorf("expected the cluster state and load to be requested after adding brokers, got: %d and %d requests", state, load)
+	}
+
+	// the failed requests are not cached
+	scaler.(*cruiseControlScaler).invalidateCache()
+	fake.FailNext(api.EndpointKafkaClusterState, errors.New("connection refused"))
+	if _, err := scaler.PartitionReplicasByBroker(context.TODO()); err == nil {
+		t.Error("expected error when Cruise Control is not available")
+	}
+	if _, err := scaler.PartitionReplicasByBroker(context.TODO()); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestMockNewCruiseControlScalerWithClient(t *testing.T) {
+	defer func() { newCruiseControlScaler = createNewDefaultCruiseControlScaler }()
+
+	fake := newFakeCluster()
+	MockNewCruiseControlScalerWithClient(fake)
+	scaler, err := NewCruiseControlScaler(context.TODO(), "http://localhost:8090/kafkacruisecontrol")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	brokers, err := scaler.BrokersWithState(context.TODO(), KafkaBrokerNew)
+	if err != nil || !reflect.DeepEqual(brokers, []string{"2"}) {
+		t.Errorf("expected new broker 2, got: %v, error: %v", brokers, err)
+	}
+	if requests := fake.Requests(); !reflect.DeepEqual(requests, []types.APIEndpoint{api.EndpointKafkaClusterLoad}) {
+		t.Errorf("unexpected requests: %v", requests)
+	}
+}
+
+func TestCruiseControlScalerGoalViolations(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	detectedAt := time.Date(2022, 5, 4, 10, 30, 0, 0, time.UTC)
+	fake.StateResult = &types.StateResult{
+		AnomalyDetectorState: types.AnomalyDetectorState{
+			RecentGoalViolations: []types.AnomalyDetails{
+				{
+					AnomalyID:            "a1",
+					DetectionMs:          detectedAt.UnixMilli(),
+					FixableViolatedGoals: []types.Goal{types.DiskUsageDistributionGoal, types.CPUCapacityGoal},
+				},
+			},
+		},
+	}
+
+	violations, err := scaler.GoalViolations(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []GoalViolation{
+		{AnomalyID: "a1", DetectedAt: time.UnixMilli(detectedAt.UnixMilli()), FixableGoals: []string{"DiskUsageDistributionGoal", "CpuCapacityGoal"}},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("expected goal violations: %+v, got: %+v", expected, violations)
+	}
+}
+
+func TestCruiseControlScalerStatusAnomalyDetector(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	detectedAt := time.Date(2022, 5, 4, 10, 30, 0, 0, time.UTC)
+	failedAt := detectedAt.Add(-time.Minute)
+	fake.StateResult = &types.StateResult{
+		AnomalyDetectorState: types.AnomalyDetectorState{
+			SelfHealingEnabled:  []types.AnomalyType{types.AnomalyTypeBrokerFailure},
+			SelfHealingDisabled: []types.AnomalyType{types.AnomalyTypeGoalViolation},
+			RecentBrokerFailures: []types.AnomalyDetails{
+				{
+					AnomalyID:             "b1",
+					DetectionMs:           detectedAt.UnixMilli(),
+					Status:                types.AnomalyStatusFixStarted,
+					FailedBrokersByTimeMs: map[string]int64{"1": failedAt.UnixMilli()},
+				},
+			},
+			OngoingSelfHealingAnomaly: types.AnomalyTypeBrokerFailure,
+			BalancednessScore:         87.5,
+		},
+	}
+
+	expected := AnomalyDetectorStatus{
+		SelfHealingEnabled:   []string{"BROKER_FAILURE"},
+		SelfHealingDisabled:  []string{"GOAL_VIOLATION"},
+		RecentGoalViolations: []GoalViolation{},
+		RecentBrokerFailures: []Failure{
+			{
+				AnomalyID:  "b1",
+				DetectedAt: time.UnixMilli(detectedAt.UnixMilli()),
+				Status:     "FIX_STARTED",
+				FailedAt:   map[string]time.Time{"1": time.UnixMilli(failedAt.UnixMilli())},
+			},
+		},
+		RecentDiskFailures:        []Failure{},
+		OngoingSelfHealingAnomaly: "BROKER_FAILURE",
+		BalancednessScore:         87.5,
+	}
+	status := scaler.Status(context.TODO()).AnomalyDetector
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected anomaly detector status: %+v, got: %+v", expected, status)
+	}
+	if !status.SelfHealingEnabledFor("BROKER_FAILURE") || status.SelfHealingEnabledFor("GOAL_VIOLATION") {
+		t.Errorf("unexpected self-healing settings: %+v", status)
+	}
+}
+
+func TestCruiseControlScalerPauseSampling(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if err := scaler.PauseSampling(context.TODO(), "rolling upgrade"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	status := scaler.Status(context.TODO())
+	if !status.SamplingPaused || status.SamplingPauseReason != "rolling upgrade" || status.MonitorReady {
+		t.Errorf("expected the sampling to be paused, got: %+v", status)
+	}
+
+	if err := scaler.ResumeSampling(context.TODO(), "rolling upgrade"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	status = scaler.Status(context.TODO())
+	if status.SamplingPaused || status.SamplingPauseReason != "" || !status.MonitorReady {
+		t.Errorf("expected the sampling to be resumed, got: %+v", status)
+	}
+}
+
+func TestCruiseControlScalerUpdateExecutorConcurrency(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if err := scaler.UpdateExecutorConcurrency(context.TODO(), ExecutorConcurrency{}); err == nil {
+		t.Error("expected error for empty executor concurrency")
+	}
+	if err := scaler.UpdateExecutorConcurrency(context.TODO(), ExecutorConcurrency{LeaderMovements: -1}); err == nil {
+		t.Error("expected error for negative executor concurrency")
+	}
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("expected no requests for invalid executor concurrency, got: %v", requests)
+	}
+
+	if err := scaler.UpdateExecutorConcurrency(context.TODO(), ExecutorConcurrency{PartitionMovementsPerBroker: 3}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := scaler.UpdateExecutorConcurrency(context.TODO(), ExecutorConcurrency{LeaderMovements: 500}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := ExecutorConcurrency{PartitionMovementsPerBroker: 3, LeaderMovements: 500}
+	if concurrency := fake.Concurrency(); concurrency != expected {
+		t.Errorf("expected executor concurrency: %+v, got: %+v", expected, concurrency)
+	}
+}
+
+func TestCruiseControlScalerUpdateSelfHealing(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if err := scaler.UpdateSelfHealing(context.TODO(), nil, nil); err == nil {
+		t.Error("expected error for empty self-healing settings")
+	}
+	if err := scaler.UpdateSelfHealing(context.TODO(), []string{"BROKER_OUTAGE"}, nil); err == nil {
+		t.Error("expected error for unknown anomaly type")
+	}
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("expected no requests for invalid self-healing settings, got: %v", requests)
+	}
+
+	if err := scaler.UpdateSelfHealing(context.TODO(), []string{"BROKER_FAILURE", "GOAL_VIOLATION"}, []string{"DISK_FAILURE"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := scaler.UpdateSelfHealing(context.TODO(), nil, []string{"GOAL_VIOLATION"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]bool{"BROKER_FAILURE": true, "GOAL_VIOLATION": false, "DISK_FAILURE": false}
+	if selfHealing := fake.SelfHealing(); !reflect.DeepEqual(selfHealing, expected) {
+		t.Errorf("expected self-healing settings: %v, got: %v", expected, selfHealing)
+	}
+}
+
+func TestCruiseControlScalerRightsize(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if _, err := scaler.Rightsize(context.TODO(), RightsizeOptions{Topic: "events"}); err == nil {
+		t.Error("expected error for rightsize request without brokers to add or partition count")
+	}
+	if _, err := scaler.Rightsize(context.TODO(), RightsizeOptions{BrokersToAdd: -1}); err == nil {
+		t.Error("expected error for negative brokers to add")
+	}
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("expected no requests for invalid rightsize request, got: %v", requests)
+	}
+
+	result, err := scaler.Rightsize(context.TODO(), RightsizeOptions{PartitionCount: 12, Topic: "events"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := RightsizeResult{PartitionCount: 12, Topic: "events", ProvisionerState: "COMPLETED"}
+	if *result != expected {
+		t.Errorf("expected rightsize result: %+v, got: %+v", expected, *result)
+	}
+
+	fake.RightsizeResult = &types.RightsizeResult{NumberOfBrokersToAdd: 2, ProvisionerState: types.ProvisionerStateInProgress}
+	if result, err = scaler.Rightsize(context.TODO(), RightsizeOptions{BrokersToAdd: 1}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.BrokersToAdd != 2 || result.ProvisionerState != "IN_PROGRESS" {
+		t.Errorf("expected the recommendation of the provisioner to be returned, got: %+v", *result)
+	}
+}
+
+func TestCruiseControlScalerRebalanceWithGoals(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if _, err := scaler.RebalanceWithGoals(context.TODO(), nil, "", "NoSuchGoal"); err == nil {
+		t.Error("expected error for unknown goal")
+	}
+	if len(fake.Tasks()) != 0 {
+		t.Errorf("expected no task to be started for unknown goal, got: %+v", fake.Tasks())
+	}
+
+	result, err := scaler.RebalanceWithGoals(context.TODO(), nil, "", "DiskUsageDistributionGoal")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.TaskID == "" || len(fake.Tasks()) != 1 || fake.Tasks()[0].BrokerIDs != nil {
+		t.Errorf("expected a rebalance task to all brokers to be started, got: %+v", fake.Tasks())
+	}
+
+	// replicas are moved only to the available brokers which are not excluded
+	if _, err := scaler.RebalanceWithGoals(context.TODO(), []string{"1"}, "", "DiskUsageDistributionGoal"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tasks := fake.Tasks(); len(tasks) != 2 || !reflect.DeepEqual(tasks[1].BrokerIDs, []int32{0, 2}) {
+		t.Errorf("expected a rebalance task to brokers 0 and 2, got: %+v", tasks)
+	}
+
+	if _, err := scaler.RebalanceWithGoals(context.TODO(), []string{"0", "1", "2"}, "", "DiskUsageDistributionGoal"); err == nil {
+		t.Error("expected error when all brokers are excluded")
+	}
+
+	// the topics not matching the topics to rebalance are excluded
+	if _, err := scaler.RebalanceWithGoals(context.TODO(), nil, "events|clicks-.*"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tasks := fake.Tasks(); len(tasks) != 3 || tasks[2].ExcludedTopics != "(?!(?:events|clicks-.*)$).*" {
+		t.Errorf("expected a rebalance task excluding the other topics, got: %+v", tasks)
+	}
+	if tasks := fake.Tasks(); tasks[0].ExcludedTopics != "" {
+		t.Errorf("expected no topics to be excluded from the rebalance of all topics, got: %s", tasks[0].ExcludedTopics)
+	}
+}
+
+func TestCruiseControlScalerListUserTasks(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+	for i := 0; i < 3; i++ {
+		if _, err := scaler.AddBrokers(context.TODO(), OperationOptions{}, "2"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := scaler.RemoveBrokers(context.TODO(), OperationOptions{}, "1"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	countUserTasksRequests := func() int {
+		count := 0
+		for _, endpoint := range fake.Requests() {
+			if endpoint == api.EndpointUserTasks {
+				count++
+			}
+		}
+		return count
+	}
+
+	results, err := scaler.ListUserTasks(context.TODO(), UserTaskFilter{PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(results) != 5 {
+		t.Errorf("expected all the tasks to be listed, got: %d", len(results))
+	}
+	if requests := countUserTasksRequests(); requests != 3 {
+		t.Errorf("expected the tasks to be requested in 3 pages, got: %d", requests)
+	}
+
+	testCases := []struct {
+		testName string
+		filter   UserTaskFilter
+		expected int
+	}{
+		{
+			testName: "endpoint",
+			filter:   UserTaskFilter{Endpoints: []string{string(api.EndpointRemoveBroker)}},
+			expected: 2,
+		},
+		{
+			testName: "completed state",
+			filter:   UserTaskFilter{States: []v1beta1.CruiseControlUserTaskState{v1beta1.CruiseControlTaskCompleted}},
+			expected: 5,
+		},
+		{
+			testName: "active state",
+			filter:   UserTaskFilter{States: []v1beta1.CruiseControlUserTaskState{v1beta1.CruiseControlTaskActive}},
+			expected: 0,
+		},
+		{
+			testName: "started after",
+			filter:   UserTaskFilter{StartedAfter: time.Now().Add(time.Hour)},
+			expected: 0,
+		},
+		{
+			testName: "started before",
+			filter:   UserTaskFilter{StartedBefore: time.Now().Add(time.Hour)},
+			expected: 5,
+		},
+		{
+			testName: "task ID",
+			filter:   UserTaskFilter{TaskIDs: []string{"fake-task-2"}},
+			expected: 1,
+		},
+	}
+	for _, test := range testCases {
+		results, err := scaler.ListUserTasks(context.TODO(), test.filter)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.testName, err)
+		}
+		if len(results) != test.expected {
+			t.Errorf("%s: expected %d tasks, got: %+v", test.testName, test.expected, results)
+		}
+	}
+
+	// no more tasks are requested once the listed ones started after the time window
+	tasks := fake.Tasks()
+	requestsBefore := countUserTasksRequests()
+	results, err = scaler.ListUserTasks(context.TODO(), UserTaskFilter{StartedBefore: tasks[2].StartedAt, PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected the tasks started before the third one to be listed, got: %+v", results)
+	}
+	if requests := countUserTasksRequests() - requestsBefore; requests != 2 {
+		t.Errorf("expected the tasks to be requested in 2 pages, got: %d", requests)
+	}
+}
+
+func TestCruiseControlScalerGetUserTasksProgress(t *testing.T) {
+	fake := newFakeCluster()
+	fake.TaskProgression = []types.UserTaskStatus{types.UserTaskStatusInExecution, types.UserTaskStatusCompleted}
+	fake.StateResult = &types.StateResult{
+		ExecutorState: types.ExecutorState{
+			State:                         types.ExecutorStateTypeInterBrokerReplicaMovementTaskInProgress,
+			TriggeredUserTaskID:           "fake-task-1",
+			NumTotalPartitionMovements:    8,
+			NumFinishedPartitionMovements: 2,
+			FinishedDataMovement:          100,
+			TotalDataToMove:               400,
+		},
+	}
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	result, err := scaler.AddBrokers(context.TODO(), OperationOptions{}, "2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	results, err := scaler.GetUserTasks(context.TODO(), result.TaskID)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected the task, got: %+v, error: %v", results, err)
+	}
+	progress := results[0].Progress
+	if progress == nil {
+		t.Fatal("expected the progress of the task in execution")
+	}
+	if progress.ReplicaMovementsCompletedPct != 25 || progress.DataMovedMB != 100 || progress.DataToMoveMB != 400 {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+	if progress.EstimatedCompletion.IsZero() {
+		t.Error("expected the completion of the task to be estimated")
+	}
+	if results[0].FinishedAt != "" {
+		t.Errorf("expected no finish time of the task in execution, got: %s", results[0].FinishedAt)
+	}
+
+	results, err = scaler.GetUserTasks(context.TODO(), result.TaskID)
+	if err != nil || len(results) != 1 || results[0].State != v1beta1.CruiseControlTaskCompleted {
+		t.Fatalf("expected the task to be completed, got: %+v, error: %v", results, err)
+	}
+	if results[0].Progress != nil || results[0].FinishedAt != "" {
+		t.Errorf("expected neither progress nor finish time of the completed task, got: %+v", results[0])
+	}
+	if _, err := time.Parse(time.RFC3339, results[0].StartedAt); err != nil {
+		t.Errorf("expected the start time of the task in RFC3339, got: %s", results[0].StartedAt)
+	}
+}
+
+func TestCruiseControlScalerGetProposals(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if _, err := scaler.GetProposals(context.TODO(), "NoSuchGoal"); err == nil {
+		t.Error("expected error for unknown goal")
+	}
+
+	fake.ProposalsResult = &types.OptimizationResult{
+		Summary: types.OptimizerResult{
+			NumReplicaMovements:             12,
+			DataToMoveMB:                    2048,
+			NumLeaderMovements:              3,
+			OnDemandBalancednessScoreBefore: 72.5,
+			OnDemandBalancednessScoreAfter:  96,
+		},
+		GoalSummary: []types.GoalSummary{
+			{Goal: types.DiskUsageDistributionGoal, Status: types.GoalStatusFixed},
+			{Goal: types.ReplicaCapacityGoal, Status: types.GoalStatusNoAction},
+		},
+	}
+	summary, err := scaler.GetProposals(context.TODO(), "DiskUsageDistributionGoal", "ReplicaCapacityGoal")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := &ProposalSummary{
+		NumReplicaMovements:     12,
+		DataToMoveMB:            2048,
+		NumLeaderMovements:      3,
+		BalancednessScoreBefore: 72.5,
+		BalancednessScoreAfter:  96,
+		Goals: []ProposalGoalSummary{
+			{Goal: "DiskUsageDistributionGoal", Status: "FIXED"},
+			{Goal: "ReplicaCapacityGoal", Status: "NO-ACTION"},
+		},
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected proposal summary: %+v, got: %+v", expected, summary)
+	}
+	// proposals are not executed
+	if len(fake.Tasks()) != 0 {
+		t.Errorf("expected no task to be started, got: %+v", fake.Tasks())
+	}
+
+	fake.FailNext(api.EndpointProposals, errors.New("proposals failed"))
+	if _, err := scaler.GetProposals(context.TODO()); err == nil {
+		t.Error("expected error when the proposals request fails")
+	}
+}
+
+func TestCruiseControlScalerDemoteBrokers(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if _, err := scaler.DemoteBrokers(context.TODO()); err == nil {
+		t.Error("expected error when no brokers are provided")
+	}
+
+	result, err := scaler.DemoteBrokers(context.TODO(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.State != v1beta1.CruiseControlTaskActive {
+		t.Errorf("expected active demote task, got: %+v", result)
+	}
+	if _, err := scaler.GetUserTasks(context.TODO(), result.TaskID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	brokers, err := scaler.BrokersWithState(context.TODO(), KafkaBrokerDemoted)
+	if err != nil || !reflect.DeepEqual(brokers, []string{"1"}) {
+		t.Errorf("expected demoted broker 1, got: %v, error: %v", brokers, err)
+	}
+
+	fake.FailNext(api.EndpointDemoteBroker, errors.New("connection refused"))
+	if result, err := scaler.DemoteBrokers(context.TODO(), "0"); err == nil || result.State != v1beta1.CruiseControlTaskCompletedWithError {
+		t.Errorf("expected failed demote task, got: %+v", result)
+	}
+}
+
+func TestCruiseControlScalerUpdateTopicReplicationFactor(t *testing.T) {
+	fake := newFakeCluster()
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if _, err := scaler.UpdateTopicReplicationFactor(context.TODO(), "", 3); err == nil {
+		t.Error("expected error when no topic is provided")
+	}
+	if _, err := scaler.UpdateTopicReplicationFactor(context.TODO(), "__consumer_offsets", 0); err == nil {
+		t.Error("expected error with invalid replication factor")
+	}
+
+	result, err := scaler.UpdateTopicReplicationFactor(context.TODO(), "__consumer_offsets", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.State != v1beta1.CruiseControlTaskActive || result.TaskID == "" {
+		t.Errorf("expected active topic configuration task, got: %+v", result)
+	}
+
+	fake.FailNext(api.EndpointTopicConfiguration, errors.New("connection refused"))
+	if result, err := scaler.UpdateTopicReplicationFactor(context.TODO(), "__consumer_offsets", 3); err == nil || result.State != v1beta1.CruiseControlTaskCompletedWithError {
+		t.Errorf("expected failed topic configuration task, got: %+v", result)
+	}
+}
+
+func TestCruiseControlScalerStopExecution(t *testing.T) {
+	fake := newFakeCluster()
+	fake.TaskProgression = []types.UserTaskStatus{types.UserTaskStatusInExecution, types.UserTaskStatusCompleted}
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	result, err := scaler.RemoveBrokers(context.TODO(), OperationOptions{}, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := scaler.StopExecution(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tasks, err := scaler.GetUserTasks(context.TODO(), result.TaskID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(tasks) != 1 || tasks[0].State != v1beta1.CruiseControlTaskCompletedWithError {
+		t.Errorf("expected stopped task, got: %+v", tasks)
+	}
+
+	fake.FailNext(api.EndpointStopProposalExecution, errors.New("stop failed"))
+	if err := scaler.StopExecution(context.TODO()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCruiseControlScalerFixOfflineReplicas(t *testing.T) {
+	fake := newFakeCluster()
+	fake.TaskProgression = []types.UserTaskStatus{types.UserTaskStatusInExecution, types.UserTaskStatusCompleted}
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	result, err := scaler.FixOfflineReplicas(context.TODO())
+	if err != nil || result.State != v1beta1.CruiseControlTaskActive || result.TaskID == "" {
+		t.Fatalf("expected active task, got: %+v, error: %v", result, err)
+	}
+	for _, expected := range []v1beta1.CruiseControlUserTaskState{v1beta1.CruiseControlTaskInExecution, v1beta1.CruiseControlTaskCompleted} {
+		tasks, err := scaler.GetUserTasks(context.TODO(), result.TaskID)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(tasks) != 1 || tasks[0].State != expected {
+			t.Fatalf("expected task state %s, got: %+v", expected, tasks)
+		}
+	}
+
+	fake.FailNext(api.EndpointFixOfflineReplicas, errors.New("proposal generation failed"))
+	result, err = scaler.FixOfflineReplicas(context.TODO())
+	if err == nil || result.State != v1beta1.CruiseControlTaskCompletedWithError {
+		t.Errorf("expected failed task, got: %+v, error: %v", result, err)
+	}
+}
+
+func TestCruiseControlScalerLogDirsByBroker(t *testing.T) {
+	fake := NewFakeCruiseControlClient(
+		FakeBroker{ID: 1, State: KafkaBrokerAlive, Replicas: 12, DiskReplicas: []int32{8, 4}, DiskMB: 400, DiskCapacityMB: 1000,
+			OnlineLogDirs: []string{"/kafka-logs/kafka", "/kafka-logs2/kafka"}, OfflineLogDirs: []string{"/kafka-logs3/kafka"}},
+	)
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	logDirs, err := scaler.LogDirsByBroker(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]map[LogDirState][]LogDir{
+		"1": {
+			LogDirStateOnline: {
+				{Path: "/kafka-logs/kafka", Replicas: 8, UsedMB: 200, CapacityMB: 500},
+				{Path: "/kafka-logs2/kafka", Replicas: 4, UsedMB: 200, CapacityMB: 500},
+			},
+			LogDirStateOffline: {
+				{Path: "/kafka-logs3/kafka"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(logDirs, expected) {
+		t.Errorf("expected log dirs: %+v, got: %+v", expected, logDirs)
+	}
+
+	scaler = NewCruiseControlScalerWithClient(logr.Discard(), fake)
+	fake.FailNext(api.EndpointKafkaClusterLoad, errors.New("connection refused"))
+	if _, err := scaler.LogDirsByBroker(context.TODO()); err == nil {
+		t.Error("expected error when the disk load is not available")
+	}
+}
+
+func TestCruiseControlScalerRemoveDisks(t *testing.T) {
+	fake := NewFakeCruiseControlClient(
+		FakeBroker{ID: 1, State: KafkaBrokerAlive, Replicas: 12, DiskReplicas: []int32{8, 4},
+			OnlineLogDirs: []string{"/kafka-logs/kafka", "/kafka-logs2/kafka"}},
+	)
+	scaler := NewCruiseControlScalerWithClient(logr.Discard(), fake)
+
+	if _, err := scaler.RemoveDisks(context.TODO(), "1", nil); err == nil {
+		t.Error("expected error without log dirs")
+	}
+	if _, err := scaler.RemoveDisks(context.TODO(), "broker-1", []string{"/kafka-logs2/kafka"}); err == nil {
+		t.Error("expected error for invalid broker id")
+	}
+
+	result, err := scaler.RemoveDisks(context.TODO(), "1", []string{"/kafka-logs2/kafka"})
+	if err != nil || result.State != v1beta1.CruiseControlTaskActive || result.TaskID == "" {
+		t.Fatalf("expected active task, got: %+v, error: %v", result, err)
+	}
+	tasks, err := scaler.GetUserTasks(context.TODO(), result.TaskID)
+	if err != nil || len(tasks) != 1 || tasks[0].State != v1beta1.CruiseControlTaskCompleted {
+		t.Fatalf("expected completed task, got: %+v, error: %v", tasks, err)
+	}
+	if replicas := fake.Brokers[0].DiskReplicas; replicas[0] != 8 || replicas[1] != 0 {
+		t.Errorf("expected only the removed disk to be drained, got: %v", replicas)
+	}
+
+	fake.FailNext(EndpointRemoveDisks, errors.New("not enough capacity on the remaining disks"))
+	result, err = scaler.RemoveDisks(context.TODO(), "1", []string{"/kafka-logs2/kafka"})
+	if err == nil || result.State != v1beta1.CruiseControlTaskCompletedWithError {
+		t.Errorf("expected failed task, got: %+v, error: %v", result, err)
+	}
+}
+
+func TestTaskProgress(t *testing.T) {
+	startedAt := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := startedAt.Add(10 * time.Minute)
+
+	progress := taskProgress(&types.ExecutorState{
+		NumTotalPartitionMovements:    10,
+		NumFinishedPartitionMovements: 5,
+		FinishedDataMovement:          250,
+		TotalDataToMove:               1000,
+	}, startedAt, now)
+	if progress.ReplicaMovementsCompletedPct != 50 {
+		t.Errorf("expected 50%% of the replica movements completed, got: %v", progress.ReplicaMovementsCompletedPct)
+	}
+	// a quarter of the data was moved in 10 minutes
+	if expected := now.Add(30 * time.Minute); !progress.EstimatedCompletion.Equal(expected) {
+		t.Errorf("expected completion at %s, got: %s", expected, progress.EstimatedCompletion)
+	}
+
+	progress = taskProgress(&types.ExecutorState{NumTotalLeadershipMovements: 4, NumFinishedLeadershipMovements: 1}, startedAt, now)
+	if progress.ReplicaMovementsCompletedPct != 25 {
+		t.Errorf("expected the leadership movements to be counted without partition movements, got: %v",
+			progress.ReplicaMovementsCompletedPct)
+	}
+
+	if progress = taskProgress(&types.ExecutorState{}, startedAt, now); !progress.EstimatedCompletion.IsZero() {
+		t.Errorf("expected no estimation without progress, got: %s", progress.EstimatedCompletion)
+	}
+}
@@ -14,27 +14,180 @@
 
 package scale
 
-import "github.com/banzaicloud/koperator/api/v1beta1"
+import (
+	"context"
+	"time"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+// CruiseControlClient is the subset of the Cruise Control API used by the CruiseControlScaler
+type CruiseControlClient interface {
+	State(context.Context, *api.StateRequest) (*api.StateResponse, error)
+	UserTasks(context.Context, *api.UserTasksRequest) (*api.UserTasksResponse, error)
+	AddBroker(context.Context, *api.AddBrokerRequest) (*api.AddBrokerResponse, error)
+	RemoveBroker(context.Context, *api.RemoveBrokerRequest) (*api.RemoveBrokerResponse, error)
+	Rebalance(context.Context, *api.RebalanceRequest) (*api.RebalanceResponse, error)
+	DemoteBroker(context.Context, *api.DemoteBrokerRequest) (*api.DemoteBrokerResponse, error)
+	KafkaClusterLoad(context.Context, *api.KafkaClusterLoadRequest) (*api.KafkaClusterLoadResponse, error)
+	KafkaClusterDiskLoad(context.Context, *api.KafkaClusterLoadRequest) (*KafkaClusterDiskLoadResponse, error)
+	KafkaClusterState(context.Context, *api.KafkaClusterStateRequest) (*api.KafkaClusterStateResponse, error)
+	TopicConfiguration(context.Context, *api.TopicConfigurationRequest) (*api.TopicConfigurationResponse, error)
+	StopProposalExecution(context.Context, *api.StopProposalExecutionRequest) (*api.StopProposalExecutionResponse, error)
+	FixOfflineReplicas(context.Context, *api.FixOfflineReplicasRequest) (*api.FixOfflineReplicasResponse, error)
+	RemoveDisks(context.Context, *RemoveDisksRequest) (*RemoveDisksResponse, error)
+	Proposals(context.Context, *api.ProposalsRequest) (*api.ProposalsResponse, error)
+	PauseSampling(context.Context, *api.PauseSamplingRequest) (*api.PauseSamplingResponse, error)
+	ResumeSampling(context.Context, *api.ResumeSamplingRequest) (*api.ResumeSamplingResponse, error)
+	Admin(context.Context, *api.AdminRequest) (*api.AdminResponse, error)
+	Rightsize(context.Context, *api.RightsizeRequest) (*api.RightsizeResponse, error)
+	Review(context.Context, *api.ReviewRequest) (*ReviewResponse, error)
+	ReviewBoard(context.Context, *api.ReviewBoardRequest) (*ReviewResponse, error)
+}
+
+var _ CruiseControlClient = &httpClient{}
 
 type CruiseControlScaler interface {
-	IsReady() bool
-	Status() CruiseControlStatus
-	GetUserTasks(taskIDs ...string) ([]*Result, error)
-	IsUp() bool
-	AddBrokers(brokerIDs ...string) (*Result, error)
-	RemoveBrokers(brokerIDs ...string) (*Result, error)
-	RebalanceDisks(brokerIDs ...string) (*Result, error)
-	BrokersWithState(states ...KafkaBrokerState) ([]string, error)
-	PartitionReplicasByBroker() (map[string]int32, error)
-	BrokerWithLeastPartitionReplicas() (string, error)
-	LogDirsByBroker() (map[string]map[LogDirState][]string, error)
+	IsReady(ctx context.Context) bool
+	Status(ctx context.Context) CruiseControlStatus
+	GetUserTasks(ctx context.Context, taskIDs ...string) ([]*Result, error)
+	ListUserTasks(ctx context.Context, filter UserTaskFilter) ([]*Result, error)
+	IsUp(ctx context.Context) bool
+	AddBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error)
+	RemoveBrokers(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error)
+	RebalanceDisks(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error)
+	IntraBrokerRebalance(ctx context.Context, opts OperationOptions, brokerIDs ...string) (*Result, error)
+	BrokersWithState(ctx context.Context, states ...KafkaBrokerState) ([]string, error)
+	PartitionReplicasByBroker(ctx context.Context) (map[string]int32, error)
+	BrokerWithLeastPartitionReplicas(ctx context.Context, mode BrokerSelectionMode) (string, error)
+	LogDirsByBroker(ctx context.Context) (map[string]map[LogDirState][]LogDir, error)
+	DiskUsageByBroker(ctx context.Context) (map[string]DiskUsage, error)
+	BrokerLoads(ctx context.Context) (map[string]BrokerLoad, error)
+	GoalViolations(ctx context.Context) ([]GoalViolation, error)
+	RebalanceWithGoals(ctx context.Context, excludedBrokerIDs []string, topics string, goals ...string) (*Result, error)
+	DemoteBrokers(ctx context.Context, brokerIDs ...string) (*Result, error)
+	UpdateTopicReplicationFactor(ctx context.Context, topic string, replicationFactor int32) (*Result, error)
+	StopExecution(ctx context.Context) error
+	FixOfflineReplicas(ctx context.Context) (*Result, error)
+	RemoveDisks(ctx context.Context, brokerID string, logDirs []string) (*Result, error)
+	GetProposals(ctx context.Context, goals ...string) (*ProposalSummary, error)
+	WatchTask(ctx context.Context, taskID string) <-chan TaskUpdate
+	PauseSampling(ctx context.Context, reason string) error
+	ResumeSampling(ctx context.Context, reason string) error
+	UpdateExecutorConcurrency(ctx context.Context, concurrency ExecutorConcurrency) error
+	UpdateSelfHealing(ctx context.Context, enabled, disabled []string) error
+	Rightsize(ctx context.Context, opts RightsizeOptions) (*RightsizeResult, error)
+}
+
+// DefaultUserTasksPageSize is the number of user tasks requested from Cruise Control at once
+const DefaultUserTasksPageSize int32 = 100
+
+// UserTaskFilter selects the user tasks listed by ListUserTasks, the tasks matching all of its non-empty fields are
+// listed.
+type UserTaskFilter struct {
+	TaskIDs []string
+	States  []v1beta1.CruiseControlUserTaskState
+	// Endpoints are the names of the Cruise Control endpoints the tasks were started by, e.g. REMOVE_BROKER
+	Endpoints     []string
+	StartedAfter  time.Time
+	StartedBefore time.Time
+	// PageSize is the number of tasks requested from Cruise Control at once, DefaultUserTasksPageSize is used if it
+	// is 0
+	PageSize int32
+}
+
+// Matches returns true if the task with the given ID, state and start time is selected by the filter, the endpoints
+// are not checked as the Result of a task does not tell its endpoint
+func (f UserTaskFilter) Matches(taskID string, state v1beta1.CruiseControlUserTaskState, startedAt time.Time) bool {
+	if len(f.TaskIDs) > 0 && !util.StringSliceContains(f.TaskIDs, taskID) {
+		return false
+	}
+	if len(f.States) > 0 {
+		found := false
+		for _, s := range f.States {
+			found = found || s == state
+		}
+		if !found {
+			return false
+		}
+	}
+	if !f.StartedAfter.IsZero() && startedAt.Before(f.StartedAfter) {
+		return false
+	}
+	return f.StartedBefore.IsZero() || startedAt.Before(f.StartedBefore)
+}
+
+// OperationOptions configures the operations moving partition replicas via Cruise Control.
+type OperationOptions struct {
+	// Goals are the goals to optimize, the ready default goals of Cruise Control are optimized if it is empty
+	Goals []string
+	// ReplicationThrottle is the upper bound of the bandwidth in bytes per second used to move the replicas, the
+	// default throttle of Cruise Control is used if it is 0
+	ReplicationThrottle int64
+	// Concurrency limits the concurrent movements of the operation
+	Concurrency ExecutorConcurrency
+}
+
+// ExecutorConcurrency defines the upper bounds of the concurrent movements executed by Cruise Control, the defaults
+// of Cruise Control are used for the ones which are 0.
+type ExecutorConcurrency struct {
+	PartitionMovementsPerBroker   int32
+	LeaderMovements               int32
+	IntraBrokerPartitionMovements int32
+}
+
+// IsZero returns true if none of the upper bounds is set
+func (c ExecutorConcurrency) IsZero() bool {
+	return c == ExecutorConcurrency{}
+}
+
+// RightsizeOptions is a request to the provisioner of Cruise Control to rightsize the cluster, at least one of
+// BrokersToAdd and PartitionCount has to be set
+type RightsizeOptions struct {
+	// BrokersToAdd is the number of brokers to add to the cluster
+	BrokersToAdd int32
+	// PartitionCount is the number of partitions to increase the partitions of the topics matching Topic to
+	PartitionCount int32
+	// Topic is the regular expression of the topics whose partitions are rightsized
+	Topic string
+}
+
+// RightsizeResult is the recommendation of the provisioner of Cruise Control and the state of its execution
+type RightsizeResult struct {
+	BrokersToAdd   int32
+	PartitionCount int32
+	Topic          string
+	// ProvisionerState is the state of the provisioner, e.g. COMPLETED or IN_PROGRESS
+	ProvisionerState string
 }
 
 type Result struct {
-	TaskID    string
+	TaskID string
+	// StartedAt is the time the task was started at in RFC3339
 	StartedAt string
-	State     v1beta1.CruiseControlUserTaskState
-	Err       string
+	// FinishedAt is the time the task finished at in RFC3339. It is empty if it is not known, e.g. Cruise Control
+	// does not report when its tasks finish, so the callers record the time they first see the task finished.
+	FinishedAt string
+	State      v1beta1.CruiseControlUserTaskState
+	Err        string
+	// Progress describes the progress of the replica movements of the task while it is in execution
+	Progress *TaskProgress
+}
+
+// TaskProgress describes the progress of the replica movements executed by Cruise Control for a task.
+type TaskProgress struct {
+	// ReplicaMovementsCompletedPct is the percentage of the replica movements of the task which are finished
+	ReplicaMovementsCompletedPct float64
+	// DataMovedMB is the amount of data moved so far in MB
+	DataMovedMB int64
+	// DataToMoveMB is the total amount of data moved by the task in MB
+	DataToMoveMB int64
+	// EstimatedCompletion is the estimated time of the completion of the task extrapolated from the data moved since
+	// the task started, it is zero until there is any progress
+	EstimatedCompletion time.Time
 }
 
 type LogDirState int8
@@ -44,6 +197,115 @@ const (
 	LogDirStateOffline
 )
 
+// LogDir describes a log dir of a Kafka broker as seen by Cruise Control
+type LogDir struct {
+	Path string
+	// Replicas and LeaderReplicas are the numbers of the partition replicas and leaders hosted by the log dir
+	Replicas       int32
+	LeaderReplicas int32
+	// UsedMB and CapacityMB are the used and total disk space of the log dir, they are zero for the offline log dirs.
+	// The capacity is derived from the usage and its percentage, so it is not known either while the log dir is empty.
+	UsedMB     float64
+	CapacityMB float64
+}
+
+// BrokerSelectionMode defines how BrokerWithLeastPartitionReplicas compares the brokers
+type BrokerSelectionMode string
+
+const (
+	// BrokerSelectionByReplicas selects the broker hosting the least partition replicas, it is the default
+	BrokerSelectionByReplicas BrokerSelectionMode = "replicas"
+	// BrokerSelectionByUtilization selects the least utilized alive broker considering the partition replicas hosted by
+	// the broker and its rack and the free capacity of its disks
+	BrokerSelectionByUtilization BrokerSelectionMode = "utilization"
+)
+
+// BrokerUtilization describes the utilization of a Kafka broker compared by BrokerSelectionByUtilization
+type BrokerUtilization struct {
+	ID        string
+	Rack      string
+	Replicas  int32
+	DiskUsage DiskUsage
+}
+
+// DiskUsage describes the disk utilization of a Kafka broker as seen by Cruise Control.
+type DiskUsage struct {
+	UsedMB     float64
+	CapacityMB float64
+}
+
+// BrokerLoad describes the resource utilization of a Kafka broker as seen by Cruise Control.
+type BrokerLoad struct {
+	CPUPct   float64
+	DiskPct  float64
+	Leaders  int32
+	Replicas int32
+	// NetworkInRateKB is the incoming network rate of the broker, as leader and follower, in KB/s
+	NetworkInRateKB float64
+	// NetworkOutRateKB is the outgoing network rate of the broker in KB/s
+	NetworkOutRateKB float64
+}
+
+// GoalViolation describes a goal violation detected by the anomaly detector of Cruise Control.
+type GoalViolation struct {
+	AnomalyID  string
+	DetectedAt time.Time
+	// FixableGoals are the names of the violated goals which can be fixed by a rebalance
+	FixableGoals []string
+}
+
+// Failure describes a broker or disk failure detected by the anomaly detector of Cruise Control.
+type Failure struct {
+	AnomalyID  string
+	DetectedAt time.Time
+	// Status is the status of the anomaly, e.g. FIX_STARTED if it is being fixed by self-healing
+	Status string
+	// FailedAt maps the IDs of the failed brokers, or the failed disks of the brokers as <broker ID>-<log dir>, to the
+	// time of their failure
+	FailedAt map[string]time.Time
+}
+
+// AnomalyDetectorStatus describes the state of the anomaly detector of Cruise Control.
+type AnomalyDetectorStatus struct {
+	// SelfHealingEnabled are the anomaly types, e.g. BROKER_FAILURE, fixed by Cruise Control automatically
+	SelfHealingEnabled []string
+	// SelfHealingDisabled are the anomaly types only reported by Cruise Control
+	SelfHealingDisabled  []string
+	RecentGoalViolations []GoalViolation
+	RecentBrokerFailures []Failure
+	RecentDiskFailures   []Failure
+	// OngoingSelfHealingAnomaly is the type of the anomaly being fixed by Cruise Control, it is empty if there is none
+	OngoingSelfHealingAnomaly string
+	// BalancednessScore is the balancedness score of the cluster between 0 and 100
+	BalancednessScore float64
+}
+
+// SelfHealingEnabledFor returns true if Cruise Control fixes the anomalies of the given type automatically
+func (s AnomalyDetectorStatus) SelfHealingEnabledFor(anomalyType string) bool {
+	return util.StringSliceContains(s.SelfHealingEnabled, anomalyType)
+}
+
+// ProposalSummary describes the impact of the optimization proposals of Cruise Control if they were executed.
+type ProposalSummary struct {
+	NumReplicaMovements            int32
+	DataToMoveMB                   int64
+	NumIntraBrokerReplicaMovements int32
+	IntraBrokerDataToMoveMB        int64
+	NumLeaderMovements             int32
+	// BalancednessScoreBefore and BalancednessScoreAfter are the balancedness scores of the cluster, between 0 and
+	// 100, before and after executing the proposals
+	BalancednessScoreBefore float64
+	BalancednessScoreAfter  float64
+	Goals                   []ProposalGoalSummary
+}
+
+// ProposalGoalSummary describes the outcome of optimizing a goal by the proposals of Cruise Control.
+type ProposalGoalSummary struct {
+	Goal string
+	// Status is one of NO-ACTION, FIXED or VIOLATED
+	Status string
+}
+
 // CruiseControlStatus struct is used to describe internal state of Cruise Control.
 type CruiseControlStatus struct {
 	MonitorReady  bool
@@ -54,6 +316,12 @@ type CruiseControlStatus struct {
 
 	MonitoredWindows   float32
 	MonitoringCoverage float64
+	// SamplingPaused is true if the metrics sampling of the load monitor is paused, SamplingPauseReason is the reason
+	// it was paused with
+	SamplingPaused      bool
+	SamplingPauseReason string
+
+	AnomalyDetector AnomalyDetectorStatus
 }
 
 // IsReady returns true if the Analyzer and Monitor components of Cruise Control are in ready state.
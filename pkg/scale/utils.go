@@ -16,11 +16,17 @@ package scale
 
 import (
 	"fmt"
+	"net/url"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
 )
 
+// cruiseControlAPIPath is the path the Cruise Control REST API is served at
+const cruiseControlAPIPath = "kafkacruisecontrol"
+
 func brokerIDsFromStringSlice(brokerIDs []string) ([]int32, error) {
 	brokers := make([]int32, len(brokerIDs))
 	for idx, id := range brokerIDs {
@@ -37,30 +43,54 @@ func CruiseControlURLFromKafkaCluster(instance *v1beta1.KafkaCluster) string {
 	if instance == nil {
 		return ""
 	}
-	return CruiseControlURL(
+	endpoint := cruiseControlEndpoint(
 		instance.Namespace,
 		instance.Spec.GetKubernetesClusterDomain(),
 		instance.Spec.CruiseControlConfig.CruiseControlEndpoint,
 		instance.Name,
 	)
+	// the endpoints without scheme are reached over https when the client is configured with TLS
+	return cruiseControlURL(endpoint, instance.Spec.CruiseControlConfig.ClientTLS != nil)
 }
 
+// CruiseControlURL returns the base URL of the Cruise Control REST API. The endpoint can be either a host with an
+// optional port and path prefix, e.g. "cruisecontrol:8090", or a full URL with scheme, e.g.
+// "https://gw.example.com/kafka/cc" for Cruise Control fronted by a path-prefixed reverse proxy. The endpoint of the
+// Cruise Control deployed by the operator is used if it is empty.
 func CruiseControlURL(namespace, domain, endpoint, name string) string {
-	var url string
-	if endpoint != "" {
-		url = endpoint
-	} else {
-		url = fmt.Sprintf("%s-cruisecontrol-svc.%s.svc.%s:8090", name, namespace, domain)
+	return cruiseControlURL(cruiseControlEndpoint(namespace, domain, endpoint, name), false)
+}
+
+// cruiseControlEndpoint returns the endpoint of the Cruise Control deployed by the operator if endpoint is empty
+func cruiseControlEndpoint(namespace, domain, endpoint, name string) string {
+	if endpoint == "" {
+		return fmt.Sprintf("%s-cruisecontrol-svc.%s.svc.%s:8090", name, namespace, domain)
 	}
-	return cruiseControlURL(url, false)
+	return endpoint
 }
 
+// cruiseControlURL turns the endpoint into the base URL of the Cruise Control REST API. The API path is appended to
+// the path of the endpoint unless it already ends with it.
 func cruiseControlURL(endpoint string, secure bool) string {
 	scheme := "http"
 	if secure {
 		scheme = "https"
 	}
-	return fmt.Sprintf("%s://%s/%s", scheme, endpoint, "kafkacruisecontrol")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = fmt.Sprintf("%s://%s", scheme, endpoint)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	basePath := strings.TrimSuffix(u.Path, "/")
+	if path.Base(basePath) != cruiseControlAPIPath {
+		basePath += "/" + cruiseControlAPIPath
+	}
+	u.Path = basePath
+	u.RawPath = ""
+	return u.String()
 }
 
 func stringSliceToMap(s []string) map[string]bool {
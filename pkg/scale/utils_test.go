@@ -0,0 +1,69 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestCruiseControlURL(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		expected string
+	}{
+		{
+			endpoint: "",
+			expected: "http://kafka-cruisecontrol-svc.kafka.svc.cluster.local:8090/kafkacruisecontrol",
+		},
+		{
+			endpoint: "localhost:8090",
+			expected: "http://localhost:8090/kafkacruisecontrol",
+		},
+		{
+			endpoint: "gw.example.com:8443/kafka/cc/",
+			expected: "http://gw.example.com:8443/kafka/cc/kafkacruisecontrol",
+		},
+		{
+			endpoint: "https://gw.example.com/kafka/cc",
+			expected: "https://gw.example.com/kafka/cc/kafkacruisecontrol",
+		},
+		{
+			endpoint: "https://gw.example.com:443/cruise-control/kafkacruisecontrol/",
+			expected: "https://gw.example.com:443/cruise-control/kafkacruisecontrol",
+		},
+	}
+
+	for _, test := range testCases {
+		if url := CruiseControlURL("kafka", "cluster.local", test.endpoint, "kafka"); url != test.expected {
+			t.Errorf("endpoint %q: expected URL %q, got %q", test.endpoint, test.expected, url)
+		}
+	}
+}
+
+func TestCruiseControlURLFromKafkaClusterWithClientTLS(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{ClientTLS: &v1beta1.CruiseControlClientTLS{}},
+		},
+	}
+	if url, expected := CruiseControlURLFromKafkaCluster(cluster), "https://kafka-cruisecontrol-svc.kafka.svc.cluster.local:8090/kafkacruisecontrol"; url != expected {
+		t.Errorf("expected URL %q, got %q", expected, url)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const (
+	// taskNotFoundPolls is the number of consecutive polls not finding a task after which its watch gives up
+	taskNotFoundPolls = 3
+)
+
+// ErrTaskNotFound is delivered by the watch of a task which is not known by Cruise Control, e.g. because it was
+// started before Cruise Control restarted
+var ErrTaskNotFound = errors.New("task not found")
+
+// DefaultPollConfig is the PollConfig of the tasks watched by the CruiseControlScaler
+var DefaultPollConfig = PollConfig{
+	MinInterval: 2 * time.Second,
+	MaxInterval: 30 * time.Second,
+}
+
+// PollConfig configures the adaptive polling of a watched task. The task is polled every MinInterval after it
+// changed, the interval is doubled up to MaxInterval while it does not change or it can not be polled.
+type PollConfig struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+// TaskUpdate is a change of a watched task
+type TaskUpdate struct {
+	Result *Result
+	// Err is set if the task can not be watched any longer, it is the last update of the watch
+	Err error
+}
+
+// PollTask watches the task by polling it with the GetUserTasks method of the scaler. An update is delivered on the
+// returned channel when the task is first seen and whenever its state or the progress of its replica movements
+// change. The channel is closed once the task is finished, it is not found or ctx is done. Failed polls are retried
+// until ctx is done.
+func PollTask(ctx context.Context, scaler CruiseControlScaler, taskID string, cfg PollConfig) <-chan TaskUpdate {
+	if cfg.MinInterval <= 0 {
+		cfg.MinInterval = DefaultPollConfig.MinInterval
+	}
+	if cfg.MaxInterval < cfg.MinInterval {
+		cfg.MaxInterval = cfg.MinInterval
+	}
+
+	updates := make(chan TaskUpdate)
+	go func() {
+		defer close(updates)
+
+		send := func(update TaskUpdate) bool {
+			select {
+			case updates <- update:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var last *Result
+		notFound := 0
+		interval := cfg.MinInterval
+		for {
+			results, err := scaler.GetUserTasks(ctx, taskID)
+			result := findResult(results, taskID)
+			switch {
+			case err != nil:
+				interval = nextPollInterval(interval, cfg)
+			case result == nil:
+				if notFound++; notFound >= taskNotFoundPolls {
+					send(TaskUpdate{Err: ErrTaskNotFound})
+					return
+				}
+				interval = nextPollInterval(interval, cfg)
+			case taskChanged(last, result):
+				if !send(TaskUpdate{Result: result}) || isFinished(result.State) {
+					return
+				}
+				last, notFound, interval = result, 0, cfg.MinInterval
+			default:
+				notFound = 0
+				interval = nextPollInterval(interval, cfg)
+			}
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return updates
+}
+
+func findResult(results []*Result, taskID string) *Result {
+	for _, result := range results {
+		if result != nil && result.TaskID == taskID {
+			return result
+		}
+	}
+	return nil
+}
+
+// taskChanged returns true if the state or the progress of the task changed since the last result
+func taskChanged(last, current *Result) bool {
+	if last == nil || last.State != current.State {
+		return true
+	}
+	if (last.Progress == nil) != (current.Progress == nil) {
+		return true
+	}
+	// the estimated completion changes with every poll, so only the movements are compared
+	return current.Progress != nil && (last.Progress.ReplicaMovementsCompletedPct != current.Progress.ReplicaMovementsCompletedPct ||
+		last.Progress.DataMovedMB != current.Progress.DataMovedMB)
+}
+
+func isFinished(state v1beta1.CruiseControlUserTaskState) bool {
+	return state == v1beta1.CruiseControlTaskCompleted || state == v1beta1.CruiseControlTaskCompletedWithError
+}
+
+func nextPollInterval(interval time.Duration, cfg PollConfig) time.Duration {
+	if interval *= 2; interval > cfg.MaxInterval {
+		return cfg.MaxInterval
+	}
+	return interval
+}
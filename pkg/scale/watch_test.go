@@ -0,0 +1,131 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+var testPollConfig = PollConfig{MinInterval: time.Millisecond, MaxInterval: 4 * time.Millisecond}
+
+// scriptedTaskScaler returns the scripted results of GetUserTasks, repeating the last one
+type scriptedTaskScaler struct {
+	mockCruiseControlScaler
+	mu      sync.Mutex
+	results []*Result
+	errs    []error
+	polls   int
+}
+
+func (s *scriptedTaskScaler) GetUserTasks(ctx context.Context, taskIDs ...string) ([]*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.polls
+	if i >= len(s.results) {
+		i = len(s.results) - 1
+	}
+	s.polls++
+	if i < len(s.errs) && s.errs[i] != nil {
+		return nil, s.errs[i]
+	}
+	if s.results[i] == nil {
+		return nil, nil
+	}
+	return []*Result{s.results[i]}, nil
+}
+
+func collectTaskUpdates(t *testing.T, updates <-chan TaskUpdate) []TaskUpdate {
+	t.Helper()
+	var collected []TaskUpdate
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return collected
+			}
+			collected = append(collected, update)
+		case <-timeout:
+			t.Fatalf("the watch was not finished, updates so far: %+v", collected)
+		}
+	}
+}
+
+func TestPollTask(t *testing.T) {
+	active := &Result{TaskID: "task", State: v1beta1.CruiseControlTaskActive}
+	inExecution := &Result{TaskID: "task", State: v1beta1.CruiseControlTaskInExecution,
+		Progress: &TaskProgress{ReplicaMovementsCompletedPct: 10, DataMovedMB: 100}}
+	progressed := &Result{TaskID: "task", State: v1beta1.CruiseControlTaskInExecution,
+		Progress: &TaskProgress{ReplicaMovementsCompletedPct: 60, DataMovedMB: 600}}
+	completed := &Result{TaskID: "task", State: v1beta1.CruiseControlTaskCompleted}
+
+	scaler := &scriptedTaskScaler{
+		results: []*Result{active, active, nil, inExecution, inExecution, inExecution, progressed, completed},
+		errs:    []error{nil, errors.New("connection refused")},
+	}
+	updates := collectTaskUpdates(t, PollTask(context.Background(), scaler, "task", testPollConfig))
+
+	expected := []*Result{active, inExecution, progressed, completed}
+	if len(updates) != len(expected) {
+		t.Fatalf("expected %d updates, got: %+v", len(expected), updates)
+	}
+	for i, update := range updates {
+		if update.Err != nil || update.Result != expected[i] {
+			t.Errorf("expected update %d to be %+v, got: %+v", i, expected[i], update)
+		}
+	}
+}
+
+func TestPollTaskNotFound(t *testing.T) {
+	scaler := &scriptedTaskScaler{results: []*Result{{TaskID: "other", State: v1beta1.CruiseControlTaskActive}}}
+	updates := collectTaskUpdates(t, PollTask(context.Background(), scaler, "task", testPollConfig))
+
+	if len(updates) != 1 || !errors.Is(updates[0].Err, ErrTaskNotFound) {
+		t.Errorf("expected the task not to be found, got: %+v", updates)
+	}
+	if scaler.polls != taskNotFoundPolls {
+		t.Errorf("expected %d polls, got: %d", taskNotFoundPolls, scaler.polls)
+	}
+}
+
+func TestPollTaskCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	scaler := &scriptedTaskScaler{results: []*Result{{TaskID: "task", State: v1beta1.CruiseControlTaskInExecution}}}
+	updates := PollTask(ctx, scaler, "task", testPollConfig)
+
+	if update := <-updates; update.Result == nil || update.Result.State != v1beta1.CruiseControlTaskInExecution {
+		t.Fatalf("expected the task in execution, got: %+v", update)
+	}
+	cancel()
+	if remaining := collectTaskUpdates(t, updates); len(remaining) != 0 {
+		t.Errorf("expected no updates after the cancellation, got: %+v", remaining)
+	}
+}
+
+func TestNextPollInterval(t *testing.T) {
+	cfg := PollConfig{MinInterval: time.Second, MaxInterval: 5 * time.Second}
+	interval := cfg.MinInterval
+	for _, expected := range []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if interval = nextPollInterval(interval, cfg); interval != expected {
+			t.Errorf("expected interval %s, got: %s", expected, interval)
+		}
+	}
+}
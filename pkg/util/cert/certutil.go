@@ -232,6 +232,31 @@ func GenerateJKS(certs []*x509.Certificate, privateKey []byte) (out, passw []byt
 	return outBuf.Bytes(), password, err
 }
 
+// GenerateTrustStoreJKS creates a JKS truststore with a random password containing the given CA certificates
+func GenerateTrustStoreJKS(caCerts []*x509.Certificate) (out, passw []byte, err error) {
+	jksTrustStore := keystore.New()
+	for i, cert := range caCerts {
+		caIn := keystore.TrustedCertificateEntry{
+			CreationTime: time.Now(),
+			Certificate: keystore.Certificate{
+				Type:    "X.509",
+				Content: cert.Raw,
+			},
+		}
+		alias := fmt.Sprintf("trusted_ca_%d", i)
+		if err = jksTrustStore.SetTrustedCertificateEntry(alias, caIn); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	password := GeneratePass(16)
+	var outBuf bytes.Buffer
+	if err = jksTrustStore.Store(&outBuf, password); err != nil {
+		return nil, nil, err
+	}
+	return outBuf.Bytes(), password, err
+}
+
 // GenerateTestCert is used from unit tests for generating certificates
 func GenerateTestCert() (cert, key []byte, expectedDn string, err error) {
 	priv, serialNumber, err := generatePrivateKey()
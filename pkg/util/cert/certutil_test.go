@@ -159,6 +159,29 @@ func TestGenerateJKS(t *testing.T) {
 	}
 }
 
+func TestGenerateTrustStoreJKS(t *testing.T) {
+	cert, _, _, err := GenerateTestCert()
+	if err != nil {
+		t.Error("Failed to generate test certificate")
+	}
+	caCert, err := DecodeCertificate(cert)
+	if err != nil {
+		t.Error("Failed to decode test certificate")
+	}
+
+	trustStoreBytes, password, err := GenerateTrustStoreJKS([]*x509.Certificate{caCert})
+	if err != nil {
+		t.Error("Expected to generate JKS truststore, got error:", err)
+	}
+	jksTrustStore := keystore.New()
+	if err = jksTrustStore.Load(bytes.NewReader(trustStoreBytes), password); err != nil {
+		t.Error("Failed to load truststore from its representation in bytes. Error: ", err)
+	}
+	if _, err = jksTrustStore.GetTrustedCertificateEntry("trusted_ca_0"); err != nil {
+		t.Error("Can't get trusted certificate entry", err)
+	}
+}
+
 func TestEnsureJKSPassoword(t *testing.T) {
 	cert, key, _, err := GenerateTestCert()
 	if err != nil {
@@ -0,0 +1,66 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"5-1 * * * *", "*/0 * * * *", "a * * * *", "@every"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+	for _, spec := range []string{"0 2 * * *", "*/15 * * * *", "0 0 1,15 * 1-5", "0 0 * * 7", "@daily", " @hourly "} {
+		if _, err := Parse(spec); err != nil {
+			t.Errorf("unexpected error for %q: %v", spec, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	from := time.Date(2022, time.March, 14, 10, 30, 15, 0, time.UTC) // Monday
+	testCases := []struct {
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		{spec: "0 2 * * *", from: from, expected: time.Date(2022, time.March, 15, 2, 0, 0, 0, time.UTC)},
+		{spec: "@daily", from: from, expected: time.Date(2022, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", from: from, expected: time.Date(2022, time.March, 14, 10, 45, 0, 0, time.UTC)},
+		{spec: "5/20 * * * *", from: from, expected: time.Date(2022, time.March, 14, 10, 45, 0, 0, time.UTC)},
+		{spec: "30 10 * * *", from: from, expected: time.Date(2022, time.March, 15, 10, 30, 0, 0, time.UTC)},
+		{spec: "0 3 * * 6", from: from, expected: time.Date(2022, time.March, 19, 3, 0, 0, 0, time.UTC)},
+		{spec: "0 3 * * 7", from: from, expected: time.Date(2022, time.March, 20, 3, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 * *", from: from, expected: time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		// either the day of month or the day of week has to match if both are restricted
+		{spec: "0 0 20 * 3", from: from, expected: time.Date(2022, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", from: from, expected: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 30 2 *", from: from, expected: time.Time{}},
+	}
+
+	for _, testCase := range testCases {
+		schedule, err := Parse(testCase.spec)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", testCase.spec, err)
+		}
+		if next := schedule.Next(testCase.from); !next.Equal(testCase.expected) {
+			t.Errorf("%q: expected %s, got %s", testCase.spec, testCase.expected, next)
+		}
+	}
+}
@@ -53,6 +53,29 @@ var PerBrokerConfigs = []string{
 	securityProtocolMapConfigName,
 }
 
+// perListenerPerBrokerConfigSuffixes are the listener scoped (listener.name.<listener>.<config>) configurations which
+// will not trigger rolling upgrade when updated
+var perListenerPerBrokerConfigSuffixes = []string{
+	// SASL/PLAIN credentials are reloaded dynamically
+	".plain.sasl.jaas.config",
+}
+
+// IsPerBrokerConfig returns true if the given configuration can be updated without rolling upgrade, these are
+// applied to the brokers through the Kafka admin API
+func IsPerBrokerConfig(config string) bool {
+	if util.StringSliceContains(PerBrokerConfigs, config) || IsDynamicBrokerConfig(config) {
+		return true
+	}
+	if strings.HasPrefix(config, "listener.name.") {
+		for _, suffix := range perListenerPerBrokerConfigSuffixes {
+			if strings.HasSuffix(config, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // commonACLString is the raw representation of an ACL allowing Describe on a Topic
 var commonACLString = "User:%s,Topic,%s,%s,Describe,Allow,*"
 
@@ -120,8 +143,10 @@ func ShouldRefreshOnlyPerBrokerConfigs(currentConfigs, desiredConfigs *propertie
 		}
 	}
 
-	for _, perBrokerConfig := range PerBrokerConfigs {
-		delete(configDiff, perBrokerConfig)
+	for config := range configDiff {
+		if IsPerBrokerConfig(config) {
+			delete(configDiff, config)
+		}
 	}
 
 	return len(configDiff) == 0
@@ -64,6 +64,38 @@ ssl.client.auth=modified_value_2
 `,
 			DesiredConfigs: `unmodified_config_1=unmodified_value_1
 ssl.client.auth=modified_value_3
+`,
+			Result: true,
+		},
+		{
+			Description: "only dynamic broker configs changed",
+			CurrentConfigs: `unmodified_config_1=unmodified_value_1
+num.io.threads=8
+log.cleaner.threads=1
+`,
+			DesiredConfigs: `unmodified_config_1=unmodified_value_1
+num.io.threads=16
+log.cleaner.threads=2
+`,
+			Result: true,
+		},
+		{
+			Description: "dynamic and static broker configs changed",
+			CurrentConfigs: `num.io.threads=8
+num.partitions=1
+`,
+			DesiredConfigs: `num.io.threads=16
+num.partitions=3
+`,
+			Result: false,
+		},
+		{
+			Description: "only SASL/PLAIN credentials of a listener changed",
+			CurrentConfigs: `unmodified_config_1=unmodified_value_1
+listener.name.legacy.plain.sasl.jaas.config=modified_value_2
+`,
+			DesiredConfigs: `unmodified_config_1=unmodified_value_1
+listener.name.legacy.plain.sasl.jaas.config=modified_value_3
 `,
 			Result: true,
 		},
@@ -0,0 +1,556 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+// ConfigUpdateMode tells how a broker config can be updated as described in the Kafka documentation
+type ConfigUpdateMode string
+
+const (
+	// ConfigUpdateModeReadOnly configs require a broker restart to be updated
+	ConfigUpdateModeReadOnly ConfigUpdateMode = "read-only"
+	// ConfigUpdateModePerBroker configs can be updated dynamically for each broker
+	ConfigUpdateModePerBroker ConfigUpdateMode = "per-broker"
+	// ConfigUpdateModeClusterWide configs can be updated dynamically for each broker and as a cluster-wide default
+	ConfigUpdateModeClusterWide ConfigUpdateMode = "cluster-wide"
+)
+
+// ConfigIssue describes a problem found by the validation of a broker config
+type ConfigIssue struct {
+	Key      string
+	Severity v1beta1.ConfigIssueSeverity
+	Message  string
+}
+
+type configType string
+
+const (
+	configTypeBoolean  configType = "boolean"
+	configTypeShort    configType = "short"
+	configTypeInt      configType = "int"
+	configTypeLong     configType = "long"
+	configTypeDouble   configType = "double"
+	configTypeString   configType = "string"
+	configTypeList     configType = "list"
+	configTypeClass    configType = "class"
+	configTypePassword configType = "password"
+)
+
+type valueRange struct {
+	min, max float64
+}
+
+func atLeast(min float64) *valueRange {
+	return &valueRange{min: min, max: math.Inf(1)}
+}
+
+func between(min, max float64) *valueRange {
+	return &valueRange{min: min, max: max}
+}
+
+// configDef describes a broker config of the Kafka config schema
+type configDef struct {
+	typ        configType
+	updateMode ConfigUpdateMode
+	valueRange *valueRange
+	// validValues restricts the value (or each element of a list) to the given values
+	validValues []string
+	// since is the first Kafka version the config is available in
+	since string
+	// deprecated is the Kafka version the config was deprecated in
+	deprecated string
+	// removed is the Kafka version the config was removed in
+	removed string
+}
+
+var (
+	readOnly    = ConfigUpdateModeReadOnly
+	perBroker   = ConfigUpdateModePerBroker
+	clusterWide = ConfigUpdateModeClusterWide
+
+	securityProtocols = []string{"PLAINTEXT", "SSL", "SASL_PLAINTEXT", "SASL_SSL"}
+
+	listenerPrefixRegex = regexp.MustCompile(`^listener\.name\.[a-zA-Z0-9_-]+\.`)
+	kafkaVersionRegex   = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+)
+
+// brokerConfigSchema holds the broker configs of the supported Kafka versions
+var brokerConfigSchema = map[string]configDef{
+	"advertised.host.name":                                        {typ: configTypeString, updateMode: readOnly, deprecated: "0.10.0", removed: "3.0.0"},
+	"advertised.listeners":                                        {typ: configTypeString, updateMode: perBroker},
+	"advertised.port":                                             {typ: configTypeInt, updateMode: readOnly, deprecated: "0.10.0", removed: "3.0.0"},
+	"alter.config.policy.class.name":                              {typ: configTypeClass, updateMode: readOnly},
+	"alter.log.dirs.replication.quota.window.num":                 {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"alter.log.dirs.replication.quota.window.size.seconds":        {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"authorizer.class.name":                                       {typ: configTypeString, updateMode: readOnly},
+	"auto.create.topics.enable":                                   {typ: configTypeBoolean, updateMode: readOnly},
+	"auto.leader.rebalance.enable":                                {typ: configTypeBoolean, updateMode: readOnly},
+	"background.threads":                                          {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(1)},
+	"broker.heartbeat.interval.ms":                                {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"broker.id":                                                   {typ: configTypeInt, updateMode: readOnly},
+	"broker.id.generation.enable":                                 {typ: configTypeBoolean, updateMode: readOnly},
+	"broker.rack":                                                 {typ: configTypeString, updateMode: readOnly},
+	"broker.session.timeout.ms":                                   {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"client.quota.callback.class":                                 {typ: configTypeClass, updateMode: readOnly},
+	"compression.type":                                            {typ: configTypeString, updateMode: clusterWide, validValues: []string{"uncompressed", "zstd", "lz4", "snappy", "gzip", "producer"}},
+	"connection.failed.authentication.delay.ms":                   {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"connections.max.idle.ms":                                     {typ: configTypeLong, updateMode: readOnly},
+	"connections.max.reauth.ms":                                   {typ: configTypeLong, updateMode: readOnly},
+	"control.plane.listener.name":                                 {typ: configTypeString, updateMode: readOnly},
+	"controlled.shutdown.enable":                                  {typ: configTypeBoolean, updateMode: readOnly},
+	"controlled.shutdown.max.retries":                             {typ: configTypeInt, updateMode: readOnly},
+	"controlled.shutdown.retry.backoff.ms":                        {typ: configTypeLong, updateMode: readOnly},
+	"controller.listener.names":                                   {typ: configTypeString, updateMode: readOnly, since: "2.8.0"},
+	"controller.quorum.append.linger.ms":                          {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"controller.quorum.election.backoff.max.ms":                   {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"controller.quorum.election.timeout.ms":                       {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"controller.quorum.fetch.timeout.ms":                          {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"controller.quorum.request.timeout.ms":                        {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"controller.quorum.retry.backoff.ms":                          {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"controller.quorum.voters":                                    {typ: configTypeList, updateMode: readOnly, since: "2.8.0"},
+	"controller.quota.window.num":                                 {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1), since: "2.7.0"},
+	"controller.quota.window.size.seconds":                        {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1), since: "2.7.0"},
+	"controller.socket.timeout.ms":                                {typ: configTypeInt, updateMode: readOnly},
+	"create.topic.policy.class.name":                              {typ: configTypeClass, updateMode: readOnly},
+	"default.replication.factor":                                  {typ: configTypeInt, updateMode: readOnly},
+	"delegation.token.expiry.check.interval.ms":                   {typ: configTypeLong, updateMode: readOnly, valueRange: atLeast(1)},
+	"delegation.token.expiry.time.ms":                             {typ: configTypeLong, updateMode: readOnly, valueRange: atLeast(1)},
+	"delegation.token.master.key":                                 {typ: configTypePassword, updateMode: readOnly, deprecated: "3.0.0"},
+	"delegation.token.max.lifetime.ms":                            {typ: configTypeLong, updateMode: readOnly, valueRange: atLeast(1)},
+	"delegation.token.secret.key":                                 {typ: configTypePassword, updateMode: readOnly, since: "3.0.0"},
+	"delete.records.purgatory.purge.interval.requests":            {typ: configTypeInt, updateMode: readOnly},
+	"delete.topic.enable":                                         {typ: configTypeBoolean, updateMode: readOnly},
+	"fetch.max.bytes":                                             {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1024)},
+	"fetch.purgatory.purge.interval.requests":                     {typ: configTypeInt, updateMode: readOnly},
+	"follower.replication.throttled.rate":                         {typ: configTypeLong, updateMode: perBroker, valueRange: atLeast(0)},
+	"group.initial.rebalance.delay.ms":                            {typ: configTypeInt, updateMode: readOnly},
+	"group.max.session.timeout.ms":                                {typ: configTypeInt, updateMode: readOnly},
+	"group.max.size":                                              {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"group.min.session.timeout.ms":                                {typ: configTypeInt, updateMode: readOnly},
+	"host.name":                                                   {typ: configTypeString, updateMode: readOnly, deprecated: "0.10.0", removed: "3.0.0"},
+	"initial.broker.registration.timeout.ms":                      {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"inter.broker.listener.name":                                  {typ: configTypeString, updateMode: readOnly},
+	"inter.broker.protocol.version":                               {typ: configTypeString, updateMode: readOnly},
+	"kafka.metrics.polling.interval.secs":                         {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"kafka.metrics.reporters":                                     {typ: configTypeList, updateMode: readOnly},
+	"leader.imbalance.check.interval.seconds":                     {typ: configTypeLong, updateMode: readOnly, valueRange: atLeast(1)},
+	"leader.imbalance.per.broker.percentage":                      {typ: configTypeInt, updateMode: readOnly},
+	"leader.replication.throttled.rate":                           {typ: configTypeLong, updateMode: perBroker, valueRange: atLeast(0)},
+	"listener.security.protocol.map":                              {typ: configTypeString, updateMode: perBroker},
+	"listeners":                                                   {typ: configTypeString, updateMode: perBroker},
+	"log.cleaner.backoff.ms":                                      {typ: configTypeLong, updateMode: clusterWide, valueRange: atLeast(0)},
+	"log.cleaner.dedupe.buffer.size":                              {typ: configTypeLong, updateMode: clusterWide},
+	"log.cleaner.delete.retention.ms":                             {typ: configTypeLong, updateMode: clusterWide, valueRange: atLeast(0)},
+	"log.cleaner.enable":                                          {typ: configTypeBoolean, updateMode: readOnly},
+	"log.cleaner.io.buffer.load.factor":                           {typ: configTypeDouble, updateMode: clusterWide},
+	"log.cleaner.io.buffer.size":                                  {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(0)},
+	"log.cleaner.io.max.bytes.per.second":                         {typ: configTypeDouble, updateMode: clusterWide},
+	"log.cleaner.max.compaction.lag.ms":                           {typ: configTypeLong, updateMode: clusterWide, valueRange: atLeast(1)},
+	"log.cleaner.min.cleanable.ratio":                             {typ: configTypeDouble, updateMode: clusterWide, valueRange: between(0, 1)},
+	"log.cleaner.min.compaction.lag.ms":                           {typ: configTypeLong, updateMode: clusterWide, valueRange: atLeast(0)},
+	"log.cleaner.threads":                                         {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(0)},
+	"log.cleanup.policy":                                          {typ: configTypeList, updateMode: clusterWide, validValues: []string{"compact", "delete"}},
+	"log.dir":                                                     {typ: configTypeString, updateMode: readOnly},
+	"log.dirs":                                                    {typ: configTypeString, updateMode: readOnly},
+	"log.flush.interval.messages":                                 {typ: configTypeLong, updateMode: clusterWide, valueRange: atLeast(1)},
+	"log.flush.interval.ms":                                       {typ: configTypeLong, updateMode: clusterWide},
+	"log.flush.offset.checkpoint.interval.ms":                     {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"log.flush.scheduler.interval.ms":                             {typ: configTypeLong, updateMode: readOnly},
+	"log.flush.start.offset.checkpoint.interval.ms":               {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"log.index.interval.bytes":                                    {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(0)},
+	"log.index.size.max.bytes":                                    {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(4)},
+	"log.message.downconversion.enable":                           {typ: configTypeBoolean, updateMode: clusterWide},
+	"log.message.format.version":                                  {typ: configTypeString, updateMode: clusterWide, deprecated: "3.0.0"},
+	"log.message.timestamp.difference.max.ms":                     {typ: configTypeLong, updateMode: clusterWide, valueRange: atLeast(0)},
+	"log.message.timestamp.type":                                  {typ: configTypeString, updateMode: clusterWide, validValues: []string{"CreateTime", "LogAppendTime"}},
+	"log.preallocate":                                             {typ: configTypeBoolean, updateMode: clusterWide},
+	"log.retention.bytes":                                         {typ: configTypeLong, updateMode: clusterWide},
+	"log.retention.check.interval.ms":                             {typ: configTypeLong, updateMode: readOnly, valueRange: atLeast(1)},
+	"log.retention.hours":                                         {typ: configTypeInt, updateMode: readOnly},
+	"log.retention.minutes":                                       {typ: configTypeInt, updateMode: readOnly},
+	"log.retention.ms":                                            {typ: configTypeLong, updateMode: clusterWide},
+	"log.roll.hours":                                              {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"log.roll.jitter.hours":                                       {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"log.roll.jitter.ms":                                          {typ: configTypeLong, updateMode: clusterWide},
+	"log.roll.ms":                                                 {typ: configTypeLong, updateMode: clusterWide},
+	"log.segment.bytes":                                           {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(14)},
+	"log.segment.delete.delay.ms":                                 {typ: configTypeLong, updateMode: clusterWide, valueRange: atLeast(0)},
+	"max.connection.creation.rate":                                {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(0), since: "2.7.0"},
+	"max.connections":                                             {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(0)},
+	"max.connections.per.ip":                                      {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(0)},
+	"max.connections.per.ip.overrides":                            {typ: configTypeString, updateMode: clusterWide},
+	"max.incremental.fetch.session.cache.slots":                   {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"message.max.bytes":                                           {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(0)},
+	"metadata.log.dir":                                            {typ: configTypeString, updateMode: readOnly, since: "2.8.0"},
+	"metric.reporters":                                            {typ: configTypeList, updateMode: clusterWide},
+	"metrics.num.samples":                                         {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"metrics.recording.level":                                     {typ: configTypeString, updateMode: readOnly, validValues: []string{"INFO", "DEBUG", "TRACE"}},
+	"metrics.sample.window.ms":                                    {typ: configTypeLong, updateMode: readOnly, valueRange: atLeast(1)},
+	"min.insync.replicas":                                         {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(1)},
+	"node.id":                                                     {typ: configTypeInt, updateMode: readOnly, since: "2.8.0"},
+	"num.io.threads":                                              {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(1)},
+	"num.network.threads":                                         {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(1)},
+	"num.partitions":                                              {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"num.recovery.threads.per.data.dir":                           {typ: configTypeInt, updateMode: clusterWide, valueRange: atLeast(1)},
+	"num.replica.alter.log.dirs.threads":                          {typ: configTypeInt, updateMode: readOnly},
+	"num.replica.fetchers":                                        {typ: configTypeInt, updateMode: clusterWide},
+	"offset.metadata.max.bytes":                                   {typ: configTypeInt, updateMode: readOnly},
+	"offsets.commit.required.acks":                                {typ: configTypeShort, updateMode: readOnly},
+	"offsets.commit.timeout.ms":                                   {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"offsets.load.buffer.size":                                    {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"offsets.retention.check.interval.ms":                         {typ: configTypeLong, updateMode: readOnly, valueRange: atLeast(1)},
+	"offsets.retention.minutes":                                   {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"offsets.topic.compression.codec":                             {typ: configTypeInt, updateMode: readOnly},
+	"offsets.topic.num.partitions":                                {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"offsets.topic.replication.factor":                            {typ: configTypeShort, updateMode: readOnly, valueRange: atLeast(1)},
+	"offsets.topic.segment.bytes":                                 {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"password.encoder.cipher.algorithm":                           {typ: configTypeString, updateMode: readOnly},
+	"password.encoder.iterations":                                 {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1024)},
+	"password.encoder.key.length":                                 {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(8)},
+	"password.encoder.keyfactory.algorithm":                       {typ: configTypeString, updateMode: readOnly},
+	"password.encoder.old.secret":                                 {typ: configTypePassword, updateMode: readOnly},
+	"password.encoder.secret":                                     {typ: configTypePassword, updateMode: readOnly},
+	"port":                                                        {typ: configTypeInt, updateMode: readOnly, deprecated: "0.10.0", removed: "3.0.0"},
+	"principal.builder.class":                                     {typ: configTypeClass, updateMode: perBroker},
+	"process.roles":                                               {typ: configTypeList, updateMode: readOnly, validValues: []string{"broker", "controller"}, since: "2.8.0"},
+	"producer.purgatory.purge.interval.requests":                  {typ: configTypeInt, updateMode: readOnly},
+	"queued.max.request.bytes":                                    {typ: configTypeLong, updateMode: readOnly},
+	"queued.max.requests":                                         {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"quota.consumer.default":                                      {typ: configTypeLong, updateMode: readOnly, deprecated: "0.11.0", removed: "3.0.0"},
+	"quota.producer.default":                                      {typ: configTypeLong, updateMode: readOnly, deprecated: "0.11.0", removed: "3.0.0"},
+	"quota.window.num":                                            {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"quota.window.size.seconds":                                   {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"replica.alter.log.dirs.io.max.bytes.per.second":              {typ: configTypeLong, updateMode: perBroker, valueRange: atLeast(0)},
+	"replica.fetch.backoff.ms":                                    {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"replica.fetch.max.bytes":                                     {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"replica.fetch.min.bytes":                                     {typ: configTypeInt, updateMode: readOnly},
+	"replica.fetch.response.max.bytes":                            {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"replica.fetch.wait.max.ms":                                   {typ: configTypeInt, updateMode: readOnly},
+	"replica.high.watermark.checkpoint.interval.ms":               {typ: configTypeLong, updateMode: readOnly},
+	"replica.lag.time.max.ms":                                     {typ: configTypeLong, updateMode: readOnly},
+	"replica.selector.class":                                      {typ: configTypeString, updateMode: readOnly},
+	"replica.socket.receive.buffer.bytes":                         {typ: configTypeInt, updateMode: readOnly},
+	"replica.socket.timeout.ms":                                   {typ: configTypeInt, updateMode: readOnly},
+	"replication.quota.window.num":                                {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"replication.quota.window.size.seconds":                       {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"request.timeout.ms":                                          {typ: configTypeInt, updateMode: readOnly},
+	"reserved.broker.max.id":                                      {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(0)},
+	"sasl.client.callback.handler.class":                          {typ: configTypeClass, updateMode: readOnly},
+	"sasl.enabled.mechanisms":                                     {typ: configTypeList, updateMode: perBroker},
+	"sasl.jaas.config":                                            {typ: configTypePassword, updateMode: perBroker},
+	"sasl.kerberos.kinit.cmd":                                     {typ: configTypeString, updateMode: perBroker},
+	"sasl.kerberos.min.time.before.relogin":                       {typ: configTypeLong, updateMode: perBroker},
+	"sasl.kerberos.principal.to.local.rules":                      {typ: configTypeList, updateMode: perBroker},
+	"sasl.kerberos.service.name":                                  {typ: configTypeString, updateMode: perBroker},
+	"sasl.kerberos.ticket.renew.jitter":                           {typ: configTypeDouble, updateMode: perBroker},
+	"sasl.kerberos.ticket.renew.window.factor":                    {typ: configTypeDouble, updateMode: perBroker},
+	"sasl.login.callback.handler.class":                           {typ: configTypeClass, updateMode: readOnly},
+	"sasl.login.class":                                            {typ: configTypeClass, updateMode: readOnly},
+	"sasl.login.refresh.buffer.seconds":                           {typ: configTypeShort, updateMode: perBroker},
+	"sasl.login.refresh.min.period.seconds":                       {typ: configTypeShort, updateMode: perBroker},
+	"sasl.login.refresh.window.factor":                            {typ: configTypeDouble, updateMode: perBroker},
+	"sasl.login.refresh.window.jitter":                            {typ: configTypeDouble, updateMode: perBroker},
+	"sasl.mechanism.controller.protocol":                          {typ: configTypeString, updateMode: readOnly, since: "2.8.0"},
+	"sasl.mechanism.inter.broker.protocol":                        {typ: configTypeString, updateMode: perBroker},
+	"sasl.server.callback.handler.class":                          {typ: configTypeClass, updateMode: readOnly},
+	"security.inter.broker.protocol":                              {typ: configTypeString, updateMode: readOnly, validValues: securityProtocols},
+	"security.providers":                                          {typ: configTypeString, updateMode: readOnly},
+	"socket.connection.setup.timeout.max.ms":                      {typ: configTypeLong, updateMode: readOnly, since: "2.7.0"},
+	"socket.connection.setup.timeout.ms":                          {typ: configTypeLong, updateMode: readOnly, since: "2.7.0"},
+	"socket.receive.buffer.bytes":                                 {typ: configTypeInt, updateMode: readOnly},
+	"socket.request.max.bytes":                                    {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"socket.send.buffer.bytes":                                    {typ: configTypeInt, updateMode: readOnly},
+	"ssl.cipher.suites":                                           {typ: configTypeList, updateMode: perBroker},
+	"ssl.client.auth":                                             {typ: configTypeString, updateMode: perBroker, validValues: []string{"required", "requested", "none"}},
+	"ssl.enabled.protocols":                                       {typ: configTypeList, updateMode: perBroker},
+	"ssl.endpoint.identification.algorithm":                       {typ: configTypeString, updateMode: perBroker},
+	"ssl.engine.factory.class":                                    {typ: configTypeClass, updateMode: perBroker},
+	"ssl.key.password":                                            {typ: configTypePassword, updateMode: perBroker},
+	"ssl.keymanager.algorithm":                                    {typ: configTypeString, updateMode: perBroker},
+	"ssl.keystore.certificate.chain":                              {typ: configTypePassword, updateMode: perBroker, since: "2.7.0"},
+	"ssl.keystore.key":                                            {typ: configTypePassword, updateMode: perBroker, since: "2.7.0"},
+	"ssl.keystore.location":                                       {typ: configTypeString, updateMode: perBroker},
+	"ssl.keystore.password":                                       {typ: configTypePassword, updateMode: perBroker},
+	"ssl.keystore.type":                                           {typ: configTypeString, updateMode: perBroker},
+	"ssl.principal.mapping.rules":                                 {typ: configTypeString, updateMode: readOnly},
+	"ssl.protocol":                                                {typ: configTypeString, updateMode: perBroker},
+	"ssl.provider":                                                {typ: configTypeString, updateMode: perBroker},
+	"ssl.secure.random.implementation":                            {typ: configTypeString, updateMode: perBroker},
+	"ssl.trustmanager.algorithm":                                  {typ: configTypeString, updateMode: perBroker},
+	"ssl.truststore.certificates":                                 {typ: configTypePassword, updateMode: perBroker, since: "2.7.0"},
+	"ssl.truststore.location":                                     {typ: configTypeString, updateMode: perBroker},
+	"ssl.truststore.password":                                     {typ: configTypePassword, updateMode: perBroker},
+	"ssl.truststore.type":                                         {typ: configTypeString, updateMode: perBroker},
+	"transaction.abort.timed.out.transaction.cleanup.interval.ms": {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"transaction.max.timeout.ms":                                  {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"transaction.remove.expired.transaction.cleanup.interval.ms":  {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"transaction.state.log.load.buffer.size":                      {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"transaction.state.log.min.isr":                               {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"transaction.state.log.num.partitions":                        {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"transaction.state.log.replication.factor":                    {typ: configTypeShort, updateMode: readOnly, valueRange: atLeast(1)},
+	"transaction.state.log.segment.bytes":                         {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"transactional.id.expiration.ms":                              {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"unclean.leader.election.enable":                              {typ: configTypeBoolean, updateMode: clusterWide},
+	"zookeeper.clientCnxnSocket":                                  {typ: configTypeString, updateMode: readOnly},
+	"zookeeper.connect":                                           {typ: configTypeString, updateMode: readOnly},
+	"zookeeper.connection.timeout.ms":                             {typ: configTypeInt, updateMode: readOnly},
+	"zookeeper.max.in.flight.requests":                            {typ: configTypeInt, updateMode: readOnly, valueRange: atLeast(1)},
+	"zookeeper.session.timeout.ms":                                {typ: configTypeInt, updateMode: readOnly},
+	"zookeeper.set.acl":                                           {typ: configTypeBoolean, updateMode: readOnly},
+	"zookeeper.ssl.cipher.suites":                                 {typ: configTypeList, updateMode: readOnly},
+	"zookeeper.ssl.client.enable":                                 {typ: configTypeBoolean, updateMode: readOnly},
+	"zookeeper.ssl.crl.enable":                                    {typ: configTypeBoolean, updateMode: readOnly},
+	"zookeeper.ssl.enabled.protocols":                             {typ: configTypeList, updateMode: readOnly},
+	"zookeeper.ssl.endpoint.identification.algorithm":             {typ: configTypeString, updateMode: readOnly},
+	"zookeeper.ssl.keystore.location":                             {typ: configTypeString, updateMode: readOnly},
+	"zookeeper.ssl.keystore.password":                             {typ: configTypePassword, updateMode: readOnly},
+	"zookeeper.ssl.keystore.type":                                 {typ: configTypeString, updateMode: readOnly},
+	"zookeeper.ssl.ocsp.enable":                                   {typ: configTypeBoolean, updateMode: readOnly},
+	"zookeeper.ssl.protocol":                                      {typ: configTypeString, updateMode: readOnly},
+	"zookeeper.ssl.truststore.location":                           {typ: configTypeString, updateMode: readOnly},
+	"zookeeper.ssl.truststore.password":                           {typ: configTypePassword, updateMode: readOnly},
+	"zookeeper.ssl.truststore.type":                               {typ: configTypeString, updateMode: readOnly},
+}
+
+// pluginConfigPrefixes are the prefixes of configs consumed by plugins which are not part of the Kafka config schema
+var pluginConfigPrefixes = []string{
+	"cruise.control.metrics.reporter.",
+	"remote.log.",
+}
+
+// ParseKafkaVersion returns the Kafka version in major.minor.patch format from a version string or from the tag
+// of a Kafka image (e.g. ghcr.io/banzaicloud/kafka:2.13-3.1.0). It returns an empty string if no version is found
+func ParseKafkaVersion(versionOrImage string) string {
+	version := versionOrImage
+	if i := strings.LastIndex(versionOrImage, ":"); i >= 0 && !strings.Contains(versionOrImage[i:], "/") {
+		version = versionOrImage[i+1:]
+		// strip the scala version from the image tag
+		if j := strings.LastIndex(version, "-"); j >= 0 {
+			version = version[j+1:]
+		}
+	}
+	match := kafkaVersionRegex.FindStringSubmatch(version)
+	if match == nil {
+		return ""
+	}
+	patch := match[3]
+	if patch == "" {
+		patch = "0"
+	}
+	return fmt.Sprintf("%s.%s.%s", match[1], match[2], patch)
+}
+
+// CompareKafkaVersions returns -1, 0 or 1 if a is older, equal or newer than b respectively
+func CompareKafkaVersions(a, b string) int {
+	parse := func(v string) [3]int {
+		var parsed [3]int
+		for i, part := range strings.SplitN(v, ".", 3) {
+			parsed[i], _ = strconv.Atoi(part)
+		}
+		return parsed
+	}
+	pa, pb := parse(a), parse(b)
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// MinorVersion returns the major.minor part of a Kafka version, the format inter.broker.protocol.version is set in
+func MinorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// ValidateBrokerConfig validates the given broker configs against the config schema of the given Kafka version.
+// updateMode is the way the configs are applied: read-only configs are rendered into the broker configuration file
+// while per-broker and cluster-wide configs are updated dynamically. Version specific checks are skipped if
+// kafkaVersion is empty.
+func ValidateBrokerConfig(config *properties.Properties, kafkaVersion string, updateMode ConfigUpdateMode) []ConfigIssue {
+	var issues []ConfigIssue
+	if config == nil {
+		return issues
+	}
+
+	keys := config.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		property, _ := config.Get(key)
+		issues = append(issues, validateBrokerConfigEntry(key, property.Value(), kafkaVersion, updateMode)...)
+	}
+	return issues
+}
+
+func validateBrokerConfigEntry(key, value, kafkaVersion string, updateMode ConfigUpdateMode) []ConfigIssue {
+	for _, prefix := range pluginConfigPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return nil
+		}
+	}
+
+	listenerPrefixed := false
+	schemaKey := key
+	if prefix := listenerPrefixRegex.FindString(key); prefix != "" {
+		listenerPrefixed = true
+		schemaKey = strings.TrimPrefix(key, prefix)
+	}
+
+	def, ok := brokerConfigSchema[schemaKey]
+	if !ok {
+		if listenerPrefixed {
+			// listener and mechanism prefixed configs (e.g. plain.sasl.jaas.config) are passed to plugins as is
+			return nil
+		}
+		return []ConfigIssue{{Key: key, Severity: v1beta1.ConfigIssueSeverityWarning,
+			Message: "unknown config, it is ignored by the brokers unless it is used by a plugin"}}
+	}
+
+	var issues []ConfigIssue
+	if kafkaVersion != "" {
+		switch {
+		case def.removed != "" && CompareKafkaVersions(kafkaVersion, def.removed) >= 0:
+			issues = append(issues, ConfigIssue{Key: key, Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message: fmt.Sprintf("config was removed in Kafka %s and is ignored by the brokers", def.removed)})
+		case def.since != "" && CompareKafkaVersions(kafkaVersion, def.since) < 0:
+			issues = append(issues, ConfigIssue{Key: key, Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message: fmt.Sprintf("config is only available from Kafka %s", def.since)})
+		case def.deprecated != "" && CompareKafkaVersions(kafkaVersion, def.deprecated) >= 0:
+			issues = append(issues, ConfigIssue{Key: key, Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message: fmt.Sprintf("config is deprecated since Kafka %s", def.deprecated)})
+		}
+	}
+
+	if msg := checkUpdateMode(def.updateMode, updateMode, listenerPrefixed); msg != "" {
+		issues = append(issues, ConfigIssue{Key: key, Severity: v1beta1.ConfigIssueSeverityError, Message: msg})
+	}
+	if msg := checkConfigValue(def, value); msg != "" {
+		issues = append(issues, ConfigIssue{Key: key, Severity: v1beta1.ConfigIssueSeverityError, Message: msg})
+	}
+	return issues
+}
+
+func checkUpdateMode(supported, requested ConfigUpdateMode, listenerPrefixed bool) string {
+	switch requested {
+	case ConfigUpdateModePerBroker:
+		if supported == ConfigUpdateModeReadOnly {
+			return "config is read-only, it can not be updated dynamically for a broker, use readOnlyConfig instead"
+		}
+	case ConfigUpdateModeClusterWide:
+		if supported == ConfigUpdateModeReadOnly {
+			return "config is read-only, it can not be updated dynamically, use readOnlyConfig instead"
+		}
+		if supported == ConfigUpdateModePerBroker || listenerPrefixed {
+			return "config can only be updated dynamically for each broker, it can not be set as a cluster-wide default"
+		}
+	}
+	return ""
+}
+
+func checkConfigValue(def configDef, value string) string {
+	value = strings.TrimSpace(value)
+	switch def.typ {
+	case configTypeBoolean:
+		if !strings.EqualFold(value, "true") && !strings.EqualFold(value, "false") {
+			return fmt.Sprintf("invalid value %q, expected true or false", value)
+		}
+	case configTypeShort, configTypeInt, configTypeLong:
+		bitSize := map[configType]int{configTypeShort: 16, configTypeInt: 32, configTypeLong: 64}[def.typ]
+		number, err := strconv.ParseInt(value, 10, bitSize)
+		if err != nil {
+			return fmt.Sprintf("invalid value %q, expected a number of type %s", value, def.typ)
+		}
+		return checkValueRange(def.valueRange, float64(number), value)
+	case configTypeDouble:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid value %q, expected a number of type %s", value, def.typ)
+		}
+		return checkValueRange(def.valueRange, number, value)
+	case configTypeString:
+		return checkValidValues(def.validValues, []string{value})
+	case configTypeList:
+		elements := strings.Split(value, ",")
+		for i := range elements {
+			elements[i] = strings.TrimSpace(elements[i])
+		}
+		return checkValidValues(def.validValues, elements)
+	}
+	return ""
+}
+
+func checkValueRange(valueRange *valueRange, number float64, value string) string {
+	if valueRange == nil || (number >= valueRange.min && number <= valueRange.max) {
+		return ""
+	}
+	if math.IsInf(valueRange.max, 1) {
+		return fmt.Sprintf("value %s is out of range, it must be at least %v", value, valueRange.min)
+	}
+	return fmt.Sprintf("value %s is out of range, it must be between %v and %v", value, valueRange.min, valueRange.max)
+}
+
+func checkValidValues(validValues, values []string) string {
+	if len(validValues) == 0 {
+		return ""
+	}
+	for _, value := range values {
+		if !util.StringSliceContains(validValues, value) {
+			return fmt.Sprintf("invalid value %q, expected one of: %s", value, strings.Join(validValues, ", "))
+		}
+	}
+	return ""
+}
+
+// IsSensitiveConfig returns whether the value of the broker config is a password or another secret which must not be
+// exposed, including the listener and mechanism prefixed ones e.g. listener.name.internal.plain.sasl.jaas.config
+func IsSensitiveConfig(key string) bool {
+	schemaKey := listenerPrefixRegex.ReplaceAllString(key, "")
+	if def, ok := brokerConfigSchema[schemaKey]; ok {
+		return def.typ == configTypePassword
+	}
+	if i := strings.Index(schemaKey, "."); i >= 0 {
+		if def, ok := brokerConfigSchema[schemaKey[i+1:]]; ok {
+			return def.typ == configTypePassword
+		}
+	}
+	return false
+}
+
+// IsDynamicBrokerConfig returns whether the broker config can be updated for a running broker through the Kafka admin
+// API. Passwords are excluded as these can only be stored dynamically if the brokers have a password encoder secret.
+func IsDynamicBrokerConfig(key string) bool {
+	schemaKey := listenerPrefixRegex.ReplaceAllString(key, "")
+	def, ok := brokerConfigSchema[schemaKey]
+	return ok && def.updateMode != ConfigUpdateModeReadOnly && def.typ != configTypePassword
+}
@@ -0,0 +1,234 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+func TestParseKafkaVersion(t *testing.T) {
+	testCases := map[string]string{
+		"ghcr.io/banzaicloud/kafka:2.13-3.1.0":        "3.1.0",
+		"localhost:5000/banzaicloud/kafka:2.13-2.8.1": "2.8.1",
+		"wurstmeister/kafka:2.8.1":                    "2.8.1",
+		"3.2":                                         "3.2.0",
+		"3.1.0":                                       "3.1.0",
+		"ghcr.io/banzaicloud/kafka:latest":            "",
+		"ghcr.io/banzaicloud/kafka":                   "",
+	}
+	for image, expected := range testCases {
+		if version := ParseKafkaVersion(image); version != expected {
+			t.Errorf("%s: expected version %q, got: %q", image, expected, version)
+		}
+	}
+}
+
+func TestMinorVersion(t *testing.T) {
+	testCases := map[string]string{
+		"3.1.0": "3.1",
+		"2.8.1": "2.8",
+		"3.2":   "3.2",
+		"3":     "3",
+	}
+	for version, expected := range testCases {
+		if minor := MinorVersion(version); minor != expected {
+			t.Errorf("%s: expected minor version %q, got: %q", version, expected, minor)
+		}
+	}
+}
+
+func TestValidateBrokerConfig(t *testing.T) {
+	testCases := []struct {
+		Description  string
+		Config       string
+		KafkaVersion string
+		UpdateMode   ConfigUpdateMode
+		Expected     []ConfigIssue
+	}{
+		{
+			Description: "valid read-only config",
+			Config: `auto.create.topics.enable=false
+offsets.topic.replication.factor=3
+log.cleanup.policy=compact, delete
+listener.name.internal.plain.sasl.jaas.config=secret
+cruise.control.metrics.topic.auto.create=true
+cruise.control.metrics.reporter.kubernetes.mode=true`,
+			KafkaVersion: "3.1.0",
+			UpdateMode:   ConfigUpdateModeReadOnly,
+			Expected: []ConfigIssue{{
+				Key:      "cruise.control.metrics.topic.auto.create",
+				Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message:  "unknown config, it is ignored by the brokers unless it is used by a plugin",
+			}},
+		},
+		{
+			Description: "invalid values",
+			Config: `auto.create.topics.enable=yes
+offsets.topic.replication.factor=0
+message.max.bytes=3000000000
+log.cleaner.min.cleanable.ratio=1.5
+compression.type=brotli`,
+			KafkaVersion: "3.1.0",
+			UpdateMode:   ConfigUpdateModeReadOnly,
+			Expected: []ConfigIssue{
+				{
+					Key:      "auto.create.topics.enable",
+					Severity: v1beta1.ConfigIssueSeverityError,
+					Message:  `invalid value "yes", expected true or false`,
+				},
+				{
+					Key:      "compression.type",
+					Severity: v1beta1.ConfigIssueSeverityError,
+					Message:  `invalid value "brotli", expected one of: uncompressed, zstd, lz4, snappy, gzip, producer`,
+				},
+				{
+					Key:      "log.cleaner.min.cleanable.ratio",
+					Severity: v1beta1.ConfigIssueSeverityError,
+					Message:  "value 1.5 is out of range, it must be between 0 and 1",
+				},
+				{
+					Key:      "message.max.bytes",
+					Severity: v1beta1.ConfigIssueSeverityError,
+					Message:  `invalid value "3000000000", expected a number of type int`,
+				},
+				{
+					Key:      "offsets.topic.replication.factor",
+					Severity: v1beta1.ConfigIssueSeverityError,
+					Message:  "value 0 is out of range, it must be at least 1",
+				},
+			},
+		},
+		{
+			Description: "removed and not yet available configs",
+			Config: `port=9092
+process.roles=broker`,
+			KafkaVersion: "2.7.0",
+			UpdateMode:   ConfigUpdateModeReadOnly,
+			Expected: []ConfigIssue{{
+				Key:      "port",
+				Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message:  "config is deprecated since Kafka 0.10.0",
+			}, {
+				Key:      "process.roles",
+				Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message:  "config is only available from Kafka 2.8.0",
+			}},
+		},
+		{
+			Description:  "removed config on newer Kafka version",
+			Config:       `port=9092`,
+			KafkaVersion: "3.1.0",
+			UpdateMode:   ConfigUpdateModeReadOnly,
+			Expected: []ConfigIssue{{
+				Key:      "port",
+				Severity: v1beta1.ConfigIssueSeverityWarning,
+				Message:  "config was removed in Kafka 3.0.0 and is ignored by the brokers",
+			}},
+		},
+		{
+			Description: "version specific checks are skipped without version",
+			Config:      `port=9092`,
+			UpdateMode:  ConfigUpdateModeReadOnly,
+		},
+		{
+			Description: "configs which can not be set cluster-wide",
+			Config: `auto.create.topics.enable=false
+leader.replication.throttled.rate=1000000
+log.retention.ms=86400000`,
+			KafkaVersion: "3.1.0",
+			UpdateMode:   ConfigUpdateModeClusterWide,
+			Expected: []ConfigIssue{
+				{
+					Key:      "auto.create.topics.enable",
+					Severity: v1beta1.ConfigIssueSeverityError,
+					Message:  "config is read-only, it can not be updated dynamically, use readOnlyConfig instead",
+				},
+				{
+					Key:      "leader.replication.throttled.rate",
+					Severity: v1beta1.ConfigIssueSeverityError,
+					Message:  "config can only be updated dynamically for each broker, it can not be set as a cluster-wide default",
+				},
+			},
+		},
+		{
+			Description: "per-broker configs",
+			Config: `leader.replication.throttled.rate=1000000
+log.retention.ms=86400000
+num.partitions=3`,
+			KafkaVersion: "3.1.0",
+			UpdateMode:   ConfigUpdateModePerBroker,
+			Expected: []ConfigIssue{{
+				Key:      "num.partitions",
+				Severity: v1beta1.ConfigIssueSeverityError,
+				Message:  "config is read-only, it can not be updated dynamically for a broker, use readOnlyConfig instead",
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		config, err := properties.NewFromString(test.Config)
+		if err != nil {
+			t.Fatalf("%s: could not parse config: %s", test.Description, err)
+		}
+		issues := ValidateBrokerConfig(config, test.KafkaVersion, test.UpdateMode)
+		if len(issues) == 0 && len(test.Expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(issues, test.Expected) {
+			t.Errorf("%s: expected issues: %+v, got: %+v", test.Description, test.Expected, issues)
+		}
+	}
+}
+
+func TestIsSensitiveConfig(t *testing.T) {
+	testCases := map[string]bool{
+		"ssl.keystore.password":                         true,
+		"listener.name.external.ssl.keystore.password":  true,
+		"listener.name.internal.plain.sasl.jaas.config": true,
+		"sasl.jaas.config":                              true,
+		"ssl.keystore.location":                         false,
+		"listener.name.external.ssl.keystore.location":  false,
+		"num.partitions":                                false,
+		"custom.plugin.config":                          false,
+	}
+	for key, expected := range testCases {
+		if sensitive := IsSensitiveConfig(key); sensitive != expected {
+			t.Errorf("%s: expected sensitive: %v, got: %v", key, expected, sensitive)
+		}
+	}
+}
+
+func TestIsDynamicBrokerConfig(t *testing.T) {
+	testCases := map[string]bool{
+		"num.io.threads":      true,
+		"log.cleaner.threads": true,
+		"ssl.client.auth":     true,
+		"listener.name.external.ssl.keystore.location": true,
+		"ssl.keystore.password":                        false,
+		"listener.name.external.ssl.keystore.password": false,
+		"broker.id":            false,
+		"log.dirs":             false,
+		"custom.plugin.config": false,
+	}
+	for key, expected := range testCases {
+		if dynamic := IsDynamicBrokerConfig(key); dynamic != expected {
+			t.Errorf("%s: expected dynamic: %v, got: %v", key, expected, dynamic)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"sort"
+
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+// ConfigSource tells which part of the KafkaCluster a broker config comes from
+type ConfigSource string
+
+const (
+	// ConfigSourceOperatorDefault configs are defaults set by the operator which can be overridden by the users,
+	// e.g. the replication factors of the internal topics with the dev profile
+	ConfigSourceOperatorDefault ConfigSource = "operatorDefault"
+	// ConfigSourceReadOnlyConfig configs come from spec.readOnlyConfig
+	ConfigSourceReadOnlyConfig ConfigSource = "readOnlyConfig"
+	// ConfigSourceBrokerReadOnlyConfig configs come from spec.brokers[].readOnlyConfig
+	ConfigSourceBrokerReadOnlyConfig ConfigSource = "brokerReadOnlyConfig"
+	// ConfigSourceOperator configs are generated by the operator from the KafkaCluster, e.g. the listeners
+	ConfigSourceOperator ConfigSource = "operator"
+	// ConfigSourceClusterWideConfig configs come from spec.clusterWideConfig
+	ConfigSourceClusterWideConfig ConfigSource = "clusterWideConfig"
+	// ConfigSourceBrokerConfigGroup configs come from spec.brokerConfigGroups[].config
+	ConfigSourceBrokerConfigGroup ConfigSource = "brokerConfigGroup"
+	// ConfigSourceBrokerConfig configs come from spec.brokers[].brokerConfig.config
+	ConfigSourceBrokerConfig ConfigSource = "brokerConfig"
+)
+
+// SourcedConfig is a rendered broker config with the source it comes from
+type SourcedConfig struct {
+	Key    string
+	Value  string
+	Source ConfigSource
+	// Dynamic is true if the config is set through the Kafka admin API instead of server.properties
+	Dynamic bool
+	// RestartRequired is true if the broker has to be restarted to pick up a change of the config
+	RestartRequired bool
+
+	property properties.Property
+}
+
+// SourcedBrokerConfig is the rendered configuration of a broker split into the static configs written into
+// server.properties and the dynamic configs set through the Kafka admin API, each config tagged with its source
+type SourcedBrokerConfig struct {
+	static  map[string]SourcedConfig
+	dynamic map[string]SourcedConfig
+}
+
+// NewSourcedBrokerConfig returns an empty SourcedBrokerConfig
+func NewSourcedBrokerConfig() *SourcedBrokerConfig {
+	return &SourcedBrokerConfig{
+		static:  make(map[string]SourcedConfig),
+		dynamic: make(map[string]SourcedConfig),
+	}
+}
+
+// MergeStatic overrides the static configs with the given ones coming from source
+func (c *SourcedBrokerConfig) MergeStatic(config *properties.Properties, source ConfigSource) {
+	mergeSourcedConfigs(c.static, config, source, false)
+}
+
+// MergeDynamic overrides the dynamic configs with the given ones coming from source
+func (c *SourcedBrokerConfig) MergeDynamic(config *properties.Properties, source ConfigSource) {
+	mergeSourcedConfigs(c.dynamic, config, source, true)
+}
+
+func mergeSourcedConfigs(configs map[string]SourcedConfig, config *properties.Properties, source ConfigSource, dynamic bool) {
+	if config == nil {
+		return
+	}
+	for _, key := range config.Keys() {
+		property, _ := config.Get(key)
+		configs[key] = SourcedConfig{
+			Key:     key,
+			Value:   property.Value(),
+			Source:  source,
+			Dynamic: dynamic,
+			// static configs are reloaded by the operator without restart only if they can be updated per broker
+			RestartRequired: !dynamic && !IsPerBrokerConfig(key),
+			property:        property,
+		}
+	}
+}
+
+// Static returns the configs to be written into server.properties sorted by their keys
+func (c *SourcedBrokerConfig) Static() *properties.Properties {
+	return toProperties(c.static)
+}
+
+// Dynamic returns the configs to be set through the Kafka admin API sorted by their keys
+func (c *SourcedBrokerConfig) Dynamic() *properties.Properties {
+	return toProperties(c.dynamic)
+}
+
+// Effective returns the configs the broker runs with sorted by their keys, the dynamic configs take
+// precedence over the static ones like in the brokers
+func (c *SourcedBrokerConfig) Effective() []SourcedConfig {
+	effective := make(map[string]SourcedConfig, len(c.static)+len(c.dynamic))
+	for key, config := range c.static {
+		effective[key] = config
+	}
+	for key, config := range c.dynamic {
+		effective[key] = config
+	}
+
+	configs := make([]SourcedConfig, 0, len(effective))
+	for _, key := range sortedKeys(effective) {
+		configs = append(configs, effective[key])
+	}
+	return configs
+}
+
+func toProperties(configs map[string]SourcedConfig) *properties.Properties {
+	p := properties.NewProperties()
+	for _, key := range sortedKeys(configs) {
+		p.Put(configs[key].property)
+	}
+	return p
+}
+
+func sortedKeys(configs map[string]SourcedConfig) []string {
+	keys := make([]string, 0, len(configs))
+	for key := range configs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
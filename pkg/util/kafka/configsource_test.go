@@ -0,0 +1,63 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+func TestSourcedBrokerConfig(t *testing.T) {
+	mustProperties := func(s string) *properties.Properties {
+		p, err := properties.NewFromString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	config := NewSourcedBrokerConfig()
+	config.MergeStatic(mustProperties("num.partitions=1\nlog.retention.hours=24"), ConfigSourceOperatorDefault)
+	config.MergeStatic(mustProperties("num.partitions=3"), ConfigSourceReadOnlyConfig)
+	config.MergeStatic(mustProperties("listeners=INTERNAL://:9092"), ConfigSourceOperator)
+	config.MergeStatic(nil, ConfigSourceBrokerReadOnlyConfig)
+	config.MergeDynamic(mustProperties("log.retention.hours=48\nbackground.threads=20"), ConfigSourceClusterWideConfig)
+	config.MergeDynamic(mustProperties("background.threads=30"), ConfigSourceBrokerConfig)
+
+	if static, expected := config.Static().String(), "listeners=INTERNAL://:9092\nlog.retention.hours=24\nnum.partitions=3\n"; static != expected {
+		t.Errorf("expected static configs:\n%s\ngot:\n%s", expected, static)
+	}
+	if dynamic, expected := config.Dynamic().String(), "background.threads=30\nlog.retention.hours=48\n"; dynamic != expected {
+		t.Errorf("expected dynamic configs:\n%s\ngot:\n%s", expected, dynamic)
+	}
+
+	expected := []SourcedConfig{
+		{Key: "background.threads", Value: "30", Source: ConfigSourceBrokerConfig, Dynamic: true},
+		{Key: "listeners", Value: "INTERNAL://:9092", Source: ConfigSourceOperator},
+		{Key: "log.retention.hours", Value: "48", Source: ConfigSourceClusterWideConfig, Dynamic: true},
+		{Key: "num.partitions", Value: "3", Source: ConfigSourceReadOnlyConfig, RestartRequired: true},
+	}
+	effective := config.Effective()
+	if len(effective) != len(expected) {
+		t.Fatalf("expected %d effective configs, got: %v", len(expected), effective)
+	}
+	for i, e := range expected {
+		c := effective[i]
+		if c.Key != e.Key || c.Value != e.Value || c.Source != e.Source || c.Dynamic != e.Dynamic || c.RestartRequired != e.RestartRequired {
+			t.Errorf("expected effective config %+v, got: %+v", e, c)
+		}
+	}
+}
@@ -254,8 +254,9 @@ func GetIngressConfigs(kafkaClusterSpec v1beta1.KafkaClusterSpec,
 	eListenerConfig v1beta1.ExternalListenerConfig) (map[string]v1beta1.IngressConfig, string, error) {
 	var ingressConfigs map[string]v1beta1.IngressConfig
 	var defaultIngressConfigName string
+	ingressController := eListenerConfig.GetIngressController(kafkaClusterSpec.GetIngressController())
 	// Merge specific external listener configuration with the global one if none specified
-	switch kafkaClusterSpec.GetIngressController() {
+	switch ingressController {
 	case envoyutils.IngressControllerName:
 		if eListenerConfig.Config != nil {
 			defaultIngressConfigName = eListenerConfig.Config.DefaultIngressConfig
@@ -313,7 +314,7 @@ func GetIngressConfigs(kafkaClusterSpec v1beta1.KafkaClusterSpec,
 			}
 		}
 	default:
-		return nil, "", errors.NewWithDetails("not supported ingress type", "name", kafkaClusterSpec.GetIngressController())
+		return nil, "", errors.NewWithDetails("not supported ingress type", "name", ingressController)
 	}
 	return ingressConfigs, defaultIngressConfigName, nil
 }
@@ -334,6 +335,43 @@ func GetBrokerMetricsReporterImage(brokerConfig *v1beta1.BrokerConfig, kafkaClus
 	return kafkaClusterSpec.GetClusterMetricsReporterImage()
 }
 
+// AddArchitectureAffinity returns a copy of the given affinity which also requires the nodes to have one of the given
+// CPU architectures. The architecture requirement is added to every required node selector term, as the terms are ORed.
+func AddArchitectureAffinity(affinity *corev1.Affinity, architectures []v1beta1.NodeArchitecture) *corev1.Affinity {
+	if len(architectures) == 0 {
+		return affinity
+	}
+
+	archs := make([]string, 0, len(architectures))
+	for _, arch := range architectures {
+		archs = append(archs, string(arch))
+	}
+	archRequirement := corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelArchStable,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   archs,
+	}
+
+	result := affinity.DeepCopy()
+	if result == nil {
+		result = &corev1.Affinity{}
+	}
+	if result.NodeAffinity == nil {
+		result.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	nodeSelector := result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(nodeSelector.NodeSelectorTerms) == 0 {
+		nodeSelector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range nodeSelector.NodeSelectorTerms {
+		nodeSelector.NodeSelectorTerms[i].MatchExpressions = append(nodeSelector.NodeSelectorTerms[i].MatchExpressions, archRequirement)
+	}
+	return result
+}
+
 // getRandomString returns a random string containing uppercase, lowercase and number characters with the length given
 func GetRandomString(length int) (string, error) {
 	rand.Seed(time.Now().UnixNano())
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util/envoy"
 	"github.com/banzaicloud/koperator/pkg/util/istioingress"
 
 	"gotest.tools/assert"
@@ -335,6 +336,22 @@ func TestGetIngressConfigs(t *testing.T) {
 				IngressConfigGlobalName: {IstioIngressConfig: &defaultKafkaClusterWithIstioIngress.IstioIngressConfig},
 			},
 		},
+		// ExternalListener overrides the global ingress controller
+		{
+			*defaultKafkaClusterWithIstioIngress,
+			v1beta1.ExternalListenerConfig{
+				CommonListenerSpec: v1beta1.CommonListenerSpec{
+					Type:          "plaintext",
+					Name:          "external",
+					ContainerPort: 9094,
+				},
+				ExternalStartingPort: 19090,
+				IngressController:    envoy.IngressControllerName,
+			},
+			map[string]v1beta1.IngressConfig{
+				IngressConfigGlobalName: {EnvoyConfig: &defaultKafkaClusterWithIstioIngress.EnvoyConfig},
+			},
+		},
 		// ExternalListener Specified config is set with EnvoyIngress
 		{
 			*defaultKafkaClusterWithEnvoy,
@@ -607,3 +624,55 @@ func TestIsIngressConfigInUse(t *testing.T) {
 		}
 	}
 }
+
+func TestAddArchitectureAffinity(t *testing.T) {
+	archRequirement := corev1.NodeSelectorRequirement{
+		Key:      "kubernetes.io/arch",
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{"amd64", "arm64"},
+	}
+	zoneRequirement := corev1.NodeSelectorRequirement{
+		Key:      "topology.kubernetes.io/zone",
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{"zone-a"},
+	}
+	architectures := []v1beta1.NodeArchitecture{"amd64", "arm64"}
+
+	if affinity := AddArchitectureAffinity(nil, nil); affinity != nil {
+		t.Errorf("expected no affinity without architectures, got: %+v", affinity)
+	}
+
+	affinity := AddArchitectureAffinity(nil, architectures)
+	expected := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{archRequirement}}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(affinity, expected) {
+		t.Errorf("expected affinity: %+v, got: %+v", expected, affinity)
+	}
+
+	userAffinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{zoneRequirement}},
+					{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node"}}}},
+				},
+			},
+		},
+	}
+	affinity = AddArchitectureAffinity(userAffinity, architectures)
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if !reflect.DeepEqual(terms[0].MatchExpressions, []corev1.NodeSelectorRequirement{zoneRequirement, archRequirement}) {
+		t.Errorf("expected the architecture requirement to be added to the first term, got: %+v", terms[0])
+	}
+	if !reflect.DeepEqual(terms[1].MatchExpressions, []corev1.NodeSelectorRequirement{archRequirement}) {
+		t.Errorf("expected the architecture requirement to be added to the second term, got: %+v", terms[1])
+	}
+	if len(userAffinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions) != 1 {
+		t.Error("expected the given affinity to be left unchanged")
+	}
+}
@@ -14,10 +14,65 @@
 
 package zookeeper
 
-import "strings"
+import (
+	"path"
+	"strings"
+)
 
 // PrepareConnectionAddress prepares the proper address for Kafka and CC
 // The required path for Kafka and CC looks 'example-1:2181,example-2:2181/kafka'
 func PrepareConnectionAddress(zkAddresses []string, zkPath string) string {
 	return strings.Join(zkAddresses, ",") + zkPath
 }
+
+// kafkaZnodes are the top level znodes Kafka keeps its metadata in under its chroot
+var kafkaZnodes = []string{
+	"admin", "brokers", "cluster", "config", "consumers", "controller", "controller_epoch", "delegation_token",
+	"feature", "isr_change_notification", "kafka-acl", "kafka-acl-changes", "kafka-acl-extended",
+	"kafka-acl-extended-changes", "latest_producer_id_block", "log_dir_event_notification",
+}
+
+// SharesEnsemble returns true if the two ZooKeeper connection strings have a common server, the servers without
+// a port are on the default 2181 port
+func SharesEnsemble(zkAddresses, otherZkAddresses []string) bool {
+	servers := make(map[string]bool, len(zkAddresses))
+	for _, address := range zkAddresses {
+		servers[normalizeServer(address)] = true
+	}
+	for _, address := range otherZkAddresses {
+		if servers[normalizeServer(address)] {
+			return true
+		}
+	}
+	return false
+}
+
+// ChrootsCollide returns true if the metadata of the Kafka clusters using the two chroots of the same ZooKeeper
+// ensemble would overlap, i.e. the chroots are the same or one of them is inside a znode of the other cluster
+func ChrootsCollide(chroot, otherChroot string) bool {
+	chroot, otherChroot = path.Clean("/"+chroot), path.Clean("/"+otherChroot)
+	if chroot == otherChroot {
+		return true
+	}
+	if len(otherChroot) < len(chroot) {
+		chroot, otherChroot = otherChroot, chroot
+	}
+	if chroot != "/" && !strings.HasPrefix(otherChroot, chroot+"/") {
+		return false
+	}
+	znode := strings.SplitN(strings.TrimPrefix(strings.TrimPrefix(otherChroot, chroot), "/"), "/", 2)[0]
+	for _, kafkaZnode := range kafkaZnodes {
+		if znode == kafkaZnode {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeServer(address string) string {
+	server := strings.ToLower(strings.TrimSpace(address))
+	if !strings.Contains(server, ":") {
+		server += ":2181"
+	}
+	return server
+}
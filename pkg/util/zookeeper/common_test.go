@@ -0,0 +1,58 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zookeeper
+
+import "testing"
+
+func TestSharesEnsemble(t *testing.T) {
+	testCases := []struct {
+		zkAddresses      []string
+		otherZkAddresses []string
+		expected         bool
+	}{
+		{zkAddresses: []string{"zk-0:2181", "zk-1:2181"}, otherZkAddresses: []string{"zk-1:2181"}, expected: true},
+		{zkAddresses: []string{"ZK-0"}, otherZkAddresses: []string{" zk-0:2181"}, expected: true},
+		{zkAddresses: []string{"zk-0:2181"}, otherZkAddresses: []string{"zk-0:2182"}, expected: false},
+		{zkAddresses: []string{"zk-0:2181"}, otherZkAddresses: []string{"other-zk-0:2181"}, expected: false},
+	}
+	for _, test := range testCases {
+		if shared := SharesEnsemble(test.zkAddresses, test.otherZkAddresses); shared != test.expected {
+			t.Errorf("expected %v for %v sharing the ensemble of %v, got: %v", test.expected, test.zkAddresses, test.otherZkAddresses, shared)
+		}
+	}
+}
+
+func TestChrootsCollide(t *testing.T) {
+	testCases := []struct {
+		chroot      string
+		otherChroot string
+		expected    bool
+	}{
+		{chroot: "/kafka", otherChroot: "/kafka", expected: true},
+		{chroot: "/kafka/", otherChroot: "kafka", expected: true},
+		{chroot: "/", otherChroot: "", expected: true},
+		{chroot: "/kafka", otherChroot: "/kafka-2", expected: false},
+		{chroot: "/", otherChroot: "/kafka", expected: false},
+		{chroot: "/kafka/a", otherChroot: "/kafka/b", expected: false},
+		{chroot: "/kafka", otherChroot: "/kafka/tenant", expected: false},
+		{chroot: "/brokers/kafka", otherChroot: "/", expected: true},
+		{chroot: "/kafka", otherChroot: "/kafka/config/tenant", expected: true},
+	}
+	for _, test := range testCases {
+		if collide := ChrootsCollide(test.chroot, test.otherChroot); collide != test.expected {
+			t.Errorf("expected %v for the chroots %q and %q colliding, got: %v", test.expected, test.chroot, test.otherChroot, collide)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	banzaicloudv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaicloudv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// checkCapacityHeadroom rejects the creation of the topic and the increase of its partition count while the disk
+// utilization of any broker, as last reported by Cruise Control, breaches the capacity headroom of the cluster
+func (s *webhookServer) checkCapacityHeadroom(ctx context.Context, topic *banzaicloudv1alpha1.KafkaTopic,
+	cluster *banzaicloudv1beta1.KafkaCluster) *admissionv1.AdmissionResponse {
+	if cluster.Spec.CapacityHeadroom == nil || k8sutil.IsMarkedForDeletion(topic.ObjectMeta) {
+		return nil
+	}
+
+	brokerIDs := cluster.Status.CruiseControlLoad.GetBrokersAboveDiskUsage(cluster.Spec.CapacityHeadroom.MaxDiskUsagePercent)
+	if len(brokerIDs) == 0 {
+		return nil
+	}
+
+	existing := &banzaicloudv1alpha1.KafkaTopic{}
+	err := s.client.Get(ctx, types.NamespacedName{Name: topic.GetName(), Namespace: topic.GetNamespace()}, existing)
+	switch {
+	case err == nil && topic.Spec.Partitions <= existing.Spec.Partitions:
+		return nil
+	case err != nil && !apierrors.IsNotFound(err):
+		log.Error(err, "API failure while running topic validation")
+		return notAllowed("API failure while validating topic, please try again", metav1.StatusReasonServiceUnavailable)
+	}
+
+	msg := fmt.Sprintf("The disk utilization of the brokers %s of KafkaCluster '%s' is not below %d%%, new partitions are not allowed until the cluster is scaled up",
+		strings.Join(brokerIDs, ", "), cluster.GetName(), cluster.Spec.CapacityHeadroom.MaxDiskUsagePercent)
+	log.Info(msg)
+	return notAllowed(msg, metav1.StatusReasonForbidden)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestCheckCapacityHeadroom(t *testing.T) {
+	server, err := newMockServer()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	ctx := context.Background()
+	cluster := newMockCluster()
+	cluster.Status.CruiseControlLoad = &v1beta1.CruiseControlLoadStatus{
+		Brokers: []v1beta1.BrokerLoadStatus{
+			{BrokerID: "0", DiskPercent: "65.00"},
+			{BrokerID: "1", DiskPercent: "71.50"},
+		},
+	}
+
+	// the headroom is not enforced when it is not set
+	if res := server.checkCapacityHeadroom(ctx, newMockTopic(), cluster); res != nil {
+		t.Error("Expected allowed topic without capacity headroom, got:", res.Result)
+	}
+
+	cluster.Spec.CapacityHeadroom = &v1beta1.CapacityHeadroomConfig{MaxDiskUsagePercent: 80}
+	if res := server.checkCapacityHeadroom(ctx, newMockTopic(), cluster); res != nil {
+		t.Error("Expected allowed topic with brokers below the threshold, got:", res.Result)
+	}
+
+	cluster.Spec.CapacityHeadroom.MaxDiskUsagePercent = 70
+	if res := server.checkCapacityHeadroom(ctx, newMockTopic(), cluster); res == nil || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden topic creation with broker above the threshold, got:", res)
+	}
+
+	// existing topics can be updated without adding partitions
+	topic := newMockTopic()
+	if err := server.client.Create(ctx, topic); err != nil {
+		t.Fatal(err)
+	}
+	if res := server.checkCapacityHeadroom(ctx, topic, cluster); res != nil {
+		t.Error("Expected allowed update of existing topic, got:", res.Result)
+	}
+	topic.Spec.Partitions++
+	if res := server.checkCapacityHeadroom(ctx, topic, cluster); res == nil || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden partition increase with broker above the threshold, got:", res)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// deletionProtectedObject holds the fields of the KafkaClusters, KafkaTopics and KafkaUsers
+// needed to decide whether their deletion is allowed
+type deletionProtectedObject struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		ClusterRef *v1alpha1.ClusterReference `json:"clusterRef,omitempty"`
+	} `json:"spec,omitempty"`
+}
+
+func (s *webhookServer) validateDeletion(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	var obj deletionProtectedObject
+	if err := json.Unmarshal(req.OldObject.Raw, &obj); err != nil {
+		log.Error(err, "Could not unmarshal raw object")
+		return notAllowed(err.Error(), metav1.StatusReasonBadRequest)
+	}
+
+	if !v1beta1.IsDeletionProtected(obj.GetAnnotations()) {
+		return &admissionv1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
+	// Topics and users are deleted by the operator together with their cluster,
+	// the deletion protection of the cluster guards them as well
+	if ref := obj.Spec.ClusterRef; ref != nil && req.Kind.Kind != kafkaCluster {
+		clusterNamespace := ref.Namespace
+		if clusterNamespace == "" {
+			clusterNamespace = req.Namespace
+		}
+		cluster, err := k8sutil.LookupKafkaCluster(context.Background(), s.client, ref.Name, clusterNamespace)
+		switch {
+		case apierrors.IsNotFound(err):
+			log.Info("Referenced kafka cluster does not exist, allowing deletion of protected resource")
+			return &admissionv1.AdmissionResponse{
+				Allowed: true,
+			}
+		case err != nil:
+			log.Error(err, "API failure while running deletion validation")
+			return notAllowed("API failure while validating deletion, please try again", metav1.StatusReasonServiceUnavailable)
+		case k8sutil.IsMarkedForDeletion(cluster.ObjectMeta):
+			log.Info("Cluster is going down for deletion, allowing deletion of protected resource")
+			return &admissionv1.AdmissionResponse{
+				Allowed: true,
+			}
+		}
+	}
+
+	log.Info("Rejecting deletion of protected resource", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name)
+	return notAllowed(fmt.Sprintf("%s '%s' in the namespace '%s' is protected from deletion, remove the '%s' annotation to delete it",
+		req.Kind.Kind, req.Name, req.Namespace, v1beta1.DeletionProtectionAnnotation), metav1.StatusReasonForbidden)
+}
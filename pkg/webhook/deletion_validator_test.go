@@ -0,0 +1,103 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newDeleteAdmissionRequest(kind string, obj interface{}) *admissionv1.AdmissionRequest {
+	raw, _ := json.Marshal(obj)
+	return &admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Kind: kind},
+		Namespace: "test-namespace",
+		Name:      "test-resource",
+		Operation: admissionv1.Delete,
+		OldObject: runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestValidateDeletion(t *testing.T) {
+	server, err := newMockServer()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	protected := map[string]string{v1beta1.DeletionProtectionAnnotation: v1beta1.DeletionProtectionEnabled}
+
+	// unprotected cluster
+	cluster := newMockCluster()
+	if res := server.validateDeletion(newDeleteAdmissionRequest(kafkaCluster, cluster)); !res.Allowed {
+		t.Error("Expected allowed deletion of unprotected cluster, got:", res.Result)
+	}
+
+	// protected cluster
+	cluster.SetAnnotations(protected)
+	res := server.validateDeletion(newDeleteAdmissionRequest(kafkaCluster, cluster))
+	if res.Allowed || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden deletion of protected cluster, got:", res.Result)
+	}
+
+	// protected topic of a non-existent cluster
+	topic := newMockTopic()
+	topic.SetAnnotations(protected)
+	if res := server.validateDeletion(newDeleteAdmissionRequest(kafkaTopic, topic)); !res.Allowed {
+		t.Error("Expected allowed deletion of protected topic of non-existent cluster, got:", res.Result)
+	}
+
+	// protected topic of a running cluster
+	cluster.SetAnnotations(nil)
+	if err := server.client.Create(context.TODO(), cluster); err != nil {
+		t.Fatal(err)
+	}
+	res = server.validateDeletion(newDeleteAdmissionRequest(kafkaTopic, topic))
+	if res.Allowed || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden deletion of protected topic, got:", res.Result)
+	}
+
+	// protected topic of a cluster being deleted
+	cluster.SetFinalizers([]string{"test-finalizer"})
+	if err := server.client.Update(context.TODO(), cluster); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.client.Delete(context.TODO(), cluster); err != nil {
+		t.Fatal(err)
+	}
+	if res := server.validateDeletion(newDeleteAdmissionRequest(kafkaTopic, topic)); !res.Allowed {
+		t.Error("Expected allowed deletion of protected topic of cluster marked for deletion, got:", res.Result)
+	}
+}
+
+func TestValidateDeletionRequest(t *testing.T) {
+	server, err := newMockServer()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	cluster := newMockCluster()
+	cluster.SetAnnotations(map[string]string{v1beta1.DeletionProtectionAnnotation: v1beta1.DeletionProtectionEnabled})
+	ar := &admissionv1.AdmissionReview{Request: newDeleteAdmissionRequest(kafkaCluster, cluster)}
+	if res := server.validate(ar); res.Allowed {
+		t.Error("Expected deletion requests to be validated, got allowed")
+	}
+}
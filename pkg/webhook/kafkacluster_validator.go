@@ -0,0 +1,500 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/util"
+	"github.com/banzaicloud/koperator/pkg/util/cron"
+	envoyutils "github.com/banzaicloud/koperator/pkg/util/envoy"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+	zookeeperutils "github.com/banzaicloud/koperator/pkg/util/zookeeper"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	// reservedBrokerMaxIdConfig is the broker config setting the highest broker ID which can be assigned by the
+	// users, the IDs above it are reserved for the IDs generated by ZooKeeper, there are none in KRaft mode
+	reservedBrokerMaxIdConfig = "reserved.broker.max.id"
+	// defaultReservedBrokerMaxId is the default value of reserved.broker.max.id
+	defaultReservedBrokerMaxId = 1000
+	// zookeeperSetACLConfig is the broker config making the brokers create their znodes with ACLs
+	zookeeperSetACLConfig = "zookeeper.set.acl"
+	// interBrokerProtocolVersionConfig is the broker config setting the version of the protocol the brokers talk
+	interBrokerProtocolVersionConfig = "inter.broker.protocol.version"
+	// minKRaftKafkaVersion is the first Kafka version the operator runs in KRaft mode
+	minKRaftKafkaVersion = "3.0.0"
+)
+
+func (s *webhookServer) validateKafkaCluster(cluster *v1beta1.KafkaCluster) *admissionv1.AdmissionResponse {
+	log.Info(fmt.Sprintf("Doing pre-admission validation of kafka cluster %s", cluster.GetName()))
+
+	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+		// Let this through so that the finalizers can be removed from the
+		// cluster being deleted
+		log.Info("Cluster is going down for deletion, skipping validation")
+		return &admissionv1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
+	errs := validateKafkaClusterSpec(&cluster.Spec, field.NewPath("spec"))
+
+	kafkaClusterList := v1beta1.KafkaClusterList{}
+	if err := s.client.List(context.Background(), &kafkaClusterList); err != nil {
+		log.Error(err, "couldn't list KafkaCluster custom resources")
+		return notAllowed("API failure while retrieving KafkaCluster list, please try again", metav1.StatusReasonServiceUnavailable)
+	}
+	var warnings []string
+	if !cluster.Spec.KRaftMode {
+		var zkErrs field.ErrorList
+		zkErrs, warnings = validateSharedZooKeeper(cluster, kafkaClusterList.Items, field.NewPath("spec"))
+		errs = append(errs, zkErrs...)
+	}
+
+	if len(errs) > 0 {
+		log.Info("Rejecting invalid kafka cluster", "errors", errs.ToAggregate().Error())
+		status := apierrors.NewInvalid(v1beta1.GroupVersion.WithKind(kafkaCluster).GroupKind(), cluster.GetName(), errs).Status()
+		return &admissionv1.AdmissionResponse{
+			Result: &status,
+		}
+	}
+
+	// everything looks a-okay
+	return &admissionv1.AdmissionResponse{
+		Allowed:  true,
+		Warnings: warnings,
+	}
+}
+
+// validateSharedZooKeeper returns the collisions of the chroot of the KafkaCluster with the ones of the other
+// KafkaClusters using the same ZooKeeper ensemble and warns about the metadata not protected by ZooKeeper ACLs
+func validateSharedZooKeeper(cluster *v1beta1.KafkaCluster, clusters []v1beta1.KafkaCluster, specPath *field.Path) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	var sharedWith []string
+	for _, other := range clusters {
+		if other.GetName() == cluster.GetName() && other.GetNamespace() == cluster.GetNamespace() {
+			continue
+		}
+		// filter remote KafkaClusters
+		if util.ObjectManagedByClusterRegistry(&other) {
+			continue
+		}
+		if !zookeeperutils.SharesEnsemble(cluster.Spec.ZKAddresses, other.Spec.ZKAddresses) {
+			continue
+		}
+		name := fmt.Sprintf("%s/%s", other.GetNamespace(), other.GetName())
+		sharedWith = append(sharedWith, name)
+		if zookeeperutils.ChrootsCollide(cluster.Spec.GetZkPath(), other.Spec.GetZkPath()) {
+			errs = append(errs, field.Invalid(specPath.Child("zkPath"), cluster.Spec.GetZkPath(),
+				fmt.Sprintf("the ZooKeeper chroot collides with %s of KafkaCluster %s using the same ZooKeeper ensemble",
+					other.Spec.GetZkPath(), name)))
+		}
+	}
+
+	var warnings []string
+	if len(sharedWith) > 0 && !zookeeperACLEnabled(cluster.Spec.ReadOnlyConfig) {
+		warnings = append(warnings, fmt.Sprintf("%s is not enabled, the KafkaClusters %s using the same ZooKeeper ensemble "+
+			"are able to modify the metadata of this cluster", zookeeperSetACLConfig, strings.Join(sharedWith, ", ")))
+	}
+	return errs, warnings
+}
+
+// zookeeperACLEnabled returns true if the brokers protect their metadata in ZooKeeper with ACLs
+func zookeeperACLEnabled(readOnlyConfig string) bool {
+	config, err := properties.NewFromString(readOnlyConfig)
+	if err != nil {
+		return false
+	}
+	if p, ok := config.Get(zookeeperSetACLConfig); ok {
+		enabled, err := p.Bool()
+		return err == nil && enabled
+	}
+	return false
+}
+
+// validateKafkaClusterSpec returns the misconfigurations of the brokers, the listeners and the tenancy of the KafkaCluster
+// which would otherwise only surface later during the reconciliation
+func validateKafkaClusterSpec(spec *v1beta1.KafkaClusterSpec, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateBrokers(spec, specPath)...)
+	errs = append(errs, validateBrokerVersions(spec, specPath)...)
+	errs = append(errs, validateListeners(&spec.ListenersConfig, specPath.Child("listenersConfig"))...)
+	errs = append(errs, validateEnvoyTLS(spec, specPath)...)
+	errs = append(errs, validateTenancy(spec.Tenancy, specPath.Child("tenancy"))...)
+	errs = append(errs, validateRebalanceSchedule(spec.CruiseControlConfig.RebalanceSchedule,
+		specPath.Child("cruiseControlConfig", "rebalanceSchedule"))...)
+	errs = append(errs, validateBrokerAutoscaling(spec, specPath.Child("cruiseControlConfig", "autoscaling"))...)
+	errs = append(errs, validateBrokerGroupReplicas(spec, specPath.Child("brokerGroupReplicas"))...)
+	errs = append(errs, validateRollingUpgradeConfig(spec, specPath.Child("rollingUpgradeConfig"))...)
+	errs = append(errs, validateKRaft(spec, specPath)...)
+	return errs
+}
+
+// validateKRaft checks that the cluster has a ZooKeeper ensemble to connect to, or in KRaft mode a controller listener,
+// a metadata quorum, brokers to serve the clients and a Kafka version supporting KRaft
+func validateKRaft(spec *v1beta1.KafkaClusterSpec, specPath *field.Path) field.ErrorList {
+	if !spec.KRaftMode {
+		if len(spec.ZKAddresses) == 0 {
+			return field.ErrorList{field.Required(specPath.Child("zkAddresses"), "ZooKeeper is required unless kRaft is set")}
+		}
+		return nil
+	}
+
+	var errs field.ErrorList
+	hasControllerListener := false
+	for _, iListener := range spec.ListenersConfig.InternalListeners {
+		if iListener.UsedForControllerCommunication {
+			hasControllerListener = true
+			break
+		}
+	}
+	if !hasControllerListener {
+		errs = append(errs, field.Required(specPath.Child("listenersConfig", "internalListeners"),
+			"KRaft mode requires an internal listener usedForControllerCommunication as the controller listener"))
+	}
+
+	brokersPath := specPath.Child("brokers")
+	var controllers, brokers int
+	for i, broker := range spec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(*spec)
+		if err != nil {
+			// the missing broker config groups are reported by validateBrokers
+			continue
+		}
+		if brokerConfig == nil {
+			brokerConfig = &v1beta1.BrokerConfig{}
+		}
+		if brokerConfig.IsControllerNode() {
+			controllers++
+		}
+		if brokerConfig.IsBrokerNode() {
+			brokers++
+		}
+		image := util.GetBrokerImage(brokerConfig, spec.GetClusterImage())
+		if version := kafkautils.ParseKafkaVersion(image); version != "" &&
+			kafkautils.CompareKafkaVersions(version, minKRaftKafkaVersion) < 0 {
+			errs = append(errs, field.Invalid(brokersPath.Index(i), image,
+				fmt.Sprintf("KRaft mode requires Kafka %s or newer", minKRaftKafkaVersion)))
+		}
+	}
+	if controllers == 0 {
+		errs = append(errs, field.Required(brokersPath, "KRaft mode requires at least one broker with the controller process role"))
+	}
+	if brokers == 0 {
+		errs = append(errs, field.Required(brokersPath, "KRaft mode requires at least one broker with the broker process role"))
+	}
+	return errs
+}
+
+func validateBrokers(spec *v1beta1.KafkaClusterSpec, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	maxId := int64(defaultReservedBrokerMaxId)
+	if spec.ReadOnlyConfig != "" {
+		config, err := properties.NewFromString(spec.ReadOnlyConfig)
+		if err != nil {
+			return append(errs, field.Invalid(specPath.Child("readOnlyConfig"), spec.ReadOnlyConfig, err.Error()))
+		}
+		if p, ok := config.Get(reservedBrokerMaxIdConfig); ok {
+			if maxId, err = p.Int(); err != nil {
+				errs = append(errs, field.Invalid(specPath.Child("readOnlyConfig"), p.Value(),
+					fmt.Sprintf("%s must be an integer", reservedBrokerMaxIdConfig)))
+				maxId = defaultReservedBrokerMaxId
+			}
+		}
+	}
+
+	brokersPath := specPath.Child("brokers")
+	ids := make(map[int32]int, len(spec.Brokers))
+	for i, broker := range spec.Brokers {
+		idPath := brokersPath.Index(i).Child("id")
+		switch {
+		case broker.Id < 0:
+			errs = append(errs, field.Invalid(idPath, broker.Id, "broker ID must not be negative"))
+		case int64(broker.Id) > maxId && !spec.KRaftMode:
+			errs = append(errs, field.Invalid(idPath, broker.Id,
+				fmt.Sprintf("broker ID must not be greater than %d (%s), the IDs above it are reserved for the IDs generated by ZooKeeper",
+					maxId, reservedBrokerMaxIdConfig)))
+		}
+		if first, ok := ids[broker.Id]; ok {
+			errs = append(errs, field.Duplicate(idPath, fmt.Sprintf("%d (the ID of %s)", broker.Id, brokersPath.Index(first))))
+		} else {
+			ids[broker.Id] = i
+		}
+
+		if broker.BrokerConfigGroup != "" {
+			if _, ok := spec.BrokerConfigGroups[broker.BrokerConfigGroup]; !ok {
+				errs = append(errs, field.NotFound(brokersPath.Index(i).Child("brokerConfigGroup"), broker.BrokerConfigGroup))
+			}
+		}
+	}
+	return errs
+}
+
+// validateBrokerVersions checks that the brokers running different Kafka versions, e.g. the canaries of a broker
+// config group running a newer release, can talk to each other: the inter-broker protocol has to be pinned to the
+// oldest version while the brokers run different minor versions and must never be newer than any of them. The
+// images without a version in their tag are skipped.
+func validateBrokerVersions(spec *v1beta1.KafkaClusterSpec, specPath *field.Path) field.ErrorList {
+	versions := make(map[string]struct{})
+	addVersion := func(image string) {
+		if version := kafkautils.ParseKafkaVersion(image); version != "" {
+			versions[version] = struct{}{}
+		}
+	}
+	addVersion(spec.GetClusterImage())
+	for name := range spec.BrokerConfigGroups {
+		group := spec.BrokerConfigGroups[name]
+		addVersion(util.GetBrokerImage(&group, spec.GetClusterImage()))
+	}
+	for _, broker := range spec.Brokers {
+		if brokerConfig, err := broker.GetBrokerConfig(*spec); err == nil && brokerConfig != nil {
+			addVersion(util.GetBrokerImage(brokerConfig, spec.GetClusterImage()))
+		}
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+
+	sorted := make([]string, 0, len(versions))
+	minorVersions := make(map[string]struct{})
+	for version := range versions {
+		sorted = append(sorted, version)
+		minorVersions[kafkautils.MinorVersion(version)] = struct{}{}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return kafkautils.CompareKafkaVersions(sorted[i], sorted[j]) < 0
+	})
+	oldest := kafkautils.MinorVersion(sorted[0])
+
+	var protocolVersion string
+	if config, err := properties.NewFromString(spec.ReadOnlyConfig); err == nil {
+		if p, ok := config.Get(interBrokerProtocolVersionConfig); ok {
+			protocolVersion = p.Value()
+		}
+	}
+
+	readOnlyConfigPath := specPath.Child("readOnlyConfig")
+	if protocolVersion == "" {
+		if len(minorVersions) > 1 {
+			return field.ErrorList{field.Required(readOnlyConfigPath,
+				fmt.Sprintf("%s must be set to %s or older while the brokers run different Kafka versions (%s)",
+					interBrokerProtocolVersionConfig, oldest, strings.Join(sorted, ", ")))}
+		}
+		return nil
+	}
+	if parsed := kafkautils.ParseKafkaVersion(protocolVersion); parsed != "" &&
+		kafkautils.CompareKafkaVersions(kafkautils.MinorVersion(parsed), oldest) > 0 {
+		return field.ErrorList{field.Invalid(readOnlyConfigPath, protocolVersion,
+			fmt.Sprintf("%s must not be newer than %s, the oldest Kafka version the brokers run",
+				interBrokerProtocolVersionConfig, oldest))}
+	}
+	return nil
+}
+
+func validateListeners(listeners *v1beta1.ListenersConfig, listenersPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	names := make(map[string]*field.Path)
+	ports := make(map[int32]*field.Path)
+	validate := func(listener v1beta1.CommonListenerSpec, listenerPath *field.Path) {
+		if first, ok := names[listener.Name]; ok {
+			errs = append(errs, field.Duplicate(listenerPath.Child("name"), fmt.Sprintf("%s (the name of %s)", listener.Name, first)))
+		} else {
+			names[listener.Name] = listenerPath
+		}
+		if first, ok := ports[listener.ContainerPort]; ok {
+			errs = append(errs, field.Duplicate(listenerPath.Child("containerPort"), fmt.Sprintf("%d (the port of %s)", listener.ContainerPort, first)))
+		} else {
+			ports[listener.ContainerPort] = listenerPath
+		}
+	}
+
+	for i, listener := range listeners.InternalListeners {
+		validate(listener.CommonListenerSpec, listenersPath.Child("internalListeners").Index(i))
+	}
+	for i, listener := range listeners.ExternalListeners {
+		validate(listener.CommonListenerSpec, listenersPath.Child("externalListeners").Index(i))
+	}
+	return errs
+}
+
+// validateEnvoyTLS checks that the Envoy TLS config of the external listeners matches their security protocol, Envoy
+// can only terminate the TLS of the clients in front of plaintext listeners and re-encrypt in front of ssl ones
+func validateEnvoyTLS(spec *v1beta1.KafkaClusterSpec, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	validate := func(listener v1beta1.ExternalListenerConfig, tls *v1beta1.EnvoyTLSConfig, tlsPath *field.Path) {
+		if tls == nil || tls.Mode == v1beta1.EnvoyTLSModePassthrough {
+			return
+		}
+		if tls.SecretName == "" {
+			errs = append(errs, field.Required(tlsPath.Child("secretName"),
+				fmt.Sprintf("the certificate is required in %s mode", tls.Mode)))
+		}
+		sslListener := listener.Type == v1beta1.SecurityProtocolSSL || listener.Type == v1beta1.SecurityProtocolSaslSSL
+		if tls.Mode == v1beta1.EnvoyTLSModeTerminate && sslListener {
+			errs = append(errs, field.Invalid(tlsPath.Child("mode"), tls.Mode,
+				fmt.Sprintf("external listener %s expects TLS from Envoy, use Reencrypt", listener.Name)))
+		}
+		if tls.Mode == v1beta1.EnvoyTLSModeReencrypt && !sslListener {
+			errs = append(errs, field.Invalid(tlsPath.Child("mode"), tls.Mode,
+				fmt.Sprintf("external listener %s does not use TLS, use Terminate", listener.Name)))
+		}
+	}
+
+	for i, listener := range spec.ListenersConfig.ExternalListeners {
+		if listener.GetIngressController(spec.GetIngressController()) != envoyutils.IngressControllerName {
+			continue
+		}
+		if listener.Config == nil {
+			validate(listener, spec.EnvoyConfig.TLS, specPath.Child("envoyConfig", "tls"))
+			continue
+		}
+		ingressConfigNames := make([]string, 0, len(listener.Config.IngressConfig))
+		for name := range listener.Config.IngressConfig {
+			ingressConfigNames = append(ingressConfigNames, name)
+		}
+		sort.Strings(ingressConfigNames)
+		for _, name := range ingressConfigNames {
+			envoyConfig := listener.Config.IngressConfig[name].EnvoyConfig
+			if envoyConfig == nil {
+				continue
+			}
+			// the ingress configs without TLS config inherit the global one
+			if envoyConfig.TLS == nil {
+				validate(listener, spec.EnvoyConfig.TLS, specPath.Child("envoyConfig", "tls"))
+				continue
+			}
+			validate(listener, envoyConfig.TLS, specPath.Child("listenersConfig", "externalListeners").Index(i).
+				Child("config", "ingressConfig").Key(name).Child("envoyConfig", "tls"))
+		}
+	}
+	return errs
+}
+
+// validateRebalanceSchedule checks that the rebalance schedule is a valid cron expression
+func validateRebalanceSchedule(schedule string, schedulePath *field.Path) field.ErrorList {
+	if schedule == "" {
+		return nil
+	}
+	if _, err := cron.Parse(schedule); err != nil {
+		return field.ErrorList{field.Invalid(schedulePath, schedule, err.Error())}
+	}
+	return nil
+}
+
+// validateBrokerAutoscaling checks that the broker autoscaling bounds are ordered, its broker config group exists and
+// at least one load target is set
+func validateBrokerAutoscaling(spec *v1beta1.KafkaClusterSpec, autoscalingPath *field.Path) field.ErrorList {
+	config := spec.CruiseControlConfig.Autoscaling
+	if config == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if config.MaxBrokers < config.MinBrokers {
+		errs = append(errs, field.Invalid(autoscalingPath.Child("maxBrokers"), config.MaxBrokers,
+			fmt.Sprintf("must not be less than minBrokers (%d)", config.MinBrokers)))
+	}
+	if _, ok := spec.BrokerConfigGroups[config.BrokerConfigGroup]; !ok {
+		errs = append(errs, field.NotFound(autoscalingPath.Child("brokerConfigGroup"), config.BrokerConfigGroup))
+	}
+	if config.TargetDiskPercent == 0 && config.TargetCPUPercent == 0 &&
+		config.TargetNetworkInKBps == 0 && config.TargetNetworkOutKBps == 0 {
+		errs = append(errs, field.Required(autoscalingPath,
+			"at least one of targetDiskPercent, targetCpuPercent, targetNetworkInKBps and targetNetworkOutKBps is required"))
+	}
+	return errs
+}
+
+// validateBrokerGroupReplicas checks that the scaled broker config groups exist, are not scaled by the broker
+// autoscaler too and have a non-negative number of brokers
+func validateBrokerGroupReplicas(spec *v1beta1.KafkaClusterSpec, replicasPath *field.Path) field.ErrorList {
+	groups := make([]string, 0, len(spec.BrokerGroupReplicas))
+	for group := range spec.BrokerGroupReplicas {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var errs field.ErrorList
+	autoscaling := spec.CruiseControlConfig.Autoscaling
+	for _, group := range groups {
+		replicas := spec.BrokerGroupReplicas[group]
+		switch _, ok := spec.BrokerConfigGroups[group]; {
+		case !ok:
+			errs = append(errs, field.NotFound(replicasPath.Key(group), group))
+		case autoscaling != nil && autoscaling.BrokerConfigGroup == group:
+			errs = append(errs, field.Forbidden(replicasPath.Key(group),
+				"the brokers of the group are scaled by spec.cruiseControlConfig.autoscaling"))
+		case replicas < 0:
+			errs = append(errs, field.Invalid(replicasPath.Key(group), replicas, "must not be negative"))
+		}
+	}
+	return errs
+}
+
+// validateRollingUpgradeConfig checks that the brokers are placed into racks if they are restarted rack by rack and
+// that the explicit restart order lists each broker at most once
+func validateRollingUpgradeConfig(spec *v1beta1.KafkaClusterSpec, rollingUpgradePath *field.Path) field.ErrorList {
+	config := spec.RollingUpgradeConfig
+
+	var errs field.ErrorList
+	if config.GetConcurrencyPolicy() == v1beta1.RollingUpgradeConcurrencyRack && spec.RackAwareness == nil {
+		errs = append(errs, field.Forbidden(rollingUpgradePath.Child("concurrencyPolicy"),
+			"restarting the brokers rack by rack requires spec.rackAwareness"))
+	}
+	if config.GetOrder() == v1beta1.RollingUpgradeOrderExplicit && len(config.BrokerOrder) == 0 {
+		errs = append(errs, field.Required(rollingUpgradePath.Child("brokerOrder"), "the Explicit order requires the list of broker IDs"))
+	}
+	listed := make(map[int32]struct{}, len(config.BrokerOrder))
+	for i, brokerID := range config.BrokerOrder {
+		if _, ok := listed[brokerID]; ok {
+			errs = append(errs, field.Duplicate(rollingUpgradePath.Child("brokerOrder").Index(i), brokerID))
+		}
+		listed[brokerID] = struct{}{}
+	}
+	return errs
+}
+
+func validateTenancy(tenancy *v1beta1.TenancyConfig, tenancyPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if tenancy == nil {
+		return errs
+	}
+
+	namespacesPath := tenancyPath.Child("namespaces")
+	names := make(map[string]int, len(tenancy.Namespaces))
+	for i, namespace := range tenancy.Namespaces {
+		if first, ok := names[namespace.Name]; ok {
+			errs = append(errs, field.Duplicate(namespacesPath.Index(i).Child("name"), fmt.Sprintf("%s (the name of %s)", namespace.Name, namespacesPath.Index(first))))
+		} else {
+			names[namespace.Name] = i
+		}
+	}
+	return errs
+}
@@ -0,0 +1,446 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newMockValidCluster() *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "test-namespace"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ZKAddresses: []string{"zk-0:2181"},
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfigGroup: "default"},
+				{Id: 1, BrokerConfigGroup: "default"},
+			},
+			BrokerConfigGroups: map[string]v1beta1.BrokerConfig{
+				"default": {},
+			},
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", ContainerPort: 29092}},
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "controller", ContainerPort: 29093}},
+				},
+				ExternalListeners: []v1beta1.ExternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "external", ContainerPort: 9094}},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateKafkaCluster(t *testing.T) {
+	server, err := newMockServer()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	cluster := newMockValidCluster()
+	if res := server.validateKafkaCluster(cluster); !res.Allowed {
+		t.Error("Expected allowed for valid cluster, got:", res.Result)
+	}
+
+	cluster.Spec.Brokers[1].Id = 0
+	res := server.validateKafkaCluster(cluster)
+	switch {
+	case res.Allowed:
+		t.Error("Expected not allowed for duplicate broker IDs, got allowed")
+	case res.Result.Reason != metav1.StatusReasonInvalid:
+		t.Error("Expected invalid cluster, got:", res.Result.Reason)
+	case res.Result.Details == nil || len(res.Result.Details.Causes) != 1 || res.Result.Details.Causes[0].Field != "spec.brokers[1].id":
+		t.Error("Expected the duplicate broker ID to be reported at spec.brokers[1].id, got:", res.Result.Details)
+	}
+
+	// a cluster being deleted is let through
+	now := metav1.Now()
+	cluster.SetDeletionTimestamp(&now)
+	if res := server.validateKafkaCluster(cluster); !res.Allowed {
+		t.Error("Expected allowed for cluster marked for deletion, got:", res.Result)
+	}
+}
+
+func TestValidateKafkaClusterSpec(t *testing.T) {
+	testCases := []struct {
+		testName string
+		modify   func(spec *v1beta1.KafkaClusterSpec)
+		fields   []string
+	}{
+		{
+			testName: "valid",
+			modify:   func(spec *v1beta1.KafkaClusterSpec) {},
+		},
+		{
+			testName: "duplicate broker IDs",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.Brokers = append(spec.Brokers, v1beta1.Broker{Id: 1})
+			},
+			fields: []string{"spec.brokers[2].id"},
+		},
+		{
+			testName: "negative broker ID",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.Brokers[0].Id = -1
+			},
+			fields: []string{"spec.brokers[0].id"},
+		},
+		{
+			testName: "broker ID in the range reserved for ZooKeeper",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.Brokers[1].Id = 1001
+			},
+			fields: []string{"spec.brokers[1].id"},
+		},
+		{
+			testName: "broker ID below custom reserved.broker.max.id",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ReadOnlyConfig = "reserved.broker.max.id=2000"
+				spec.Brokers[1].Id = 1001
+			},
+		},
+		{
+			testName: "broker ID above custom reserved.broker.max.id",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ReadOnlyConfig = "reserved.broker.max.id=100"
+				spec.Brokers[1].Id = 101
+			},
+			fields: []string{"spec.brokers[1].id"},
+		},
+		{
+			testName: "undefined broker config group",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.Brokers[1].BrokerConfigGroup = "missing"
+			},
+			fields: []string{"spec.brokers[1].brokerConfigGroup"},
+		},
+		{
+			testName: "canary broker running a newer patch release",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-3.1.0"
+				spec.Brokers[1].BrokerConfig = &v1beta1.BrokerConfig{Image: "ghcr.io/banzaicloud/kafka:2.13-3.1.2"}
+			},
+		},
+		{
+			testName: "canary broker running a newer minor release without pinned protocol",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-3.1.0"
+				spec.BrokerConfigGroups["canary"] = v1beta1.BrokerConfig{Image: "ghcr.io/banzaicloud/kafka:2.13-3.2.0"}
+				spec.Brokers[1].BrokerConfigGroup = "canary"
+			},
+			fields: []string{"spec.readOnlyConfig"},
+		},
+		{
+			testName: "canary broker running a newer minor release with pinned protocol",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-3.1.0"
+				spec.ReadOnlyConfig = "inter.broker.protocol.version=3.1"
+				spec.BrokerConfigGroups["canary"] = v1beta1.BrokerConfig{Image: "ghcr.io/banzaicloud/kafka:2.13-3.2.0"}
+				spec.Brokers[1].BrokerConfigGroup = "canary"
+			},
+		},
+		{
+			testName: "protocol newer than the oldest broker",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-3.1.0"
+				spec.ReadOnlyConfig = "inter.broker.protocol.version=3.2-IV0"
+				spec.Brokers[1].BrokerConfig = &v1beta1.BrokerConfig{Image: "ghcr.io/banzaicloud/kafka:2.13-3.2.0"}
+			},
+			fields: []string{"spec.readOnlyConfig"},
+		},
+		{
+			testName: "duplicate listener names",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ListenersConfig.ExternalListeners[0].Name = "internal"
+			},
+			fields: []string{"spec.listenersConfig.externalListeners[0].name"},
+		},
+		{
+			testName: "duplicate listener ports",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ListenersConfig.InternalListeners[1].ContainerPort = 29092
+			},
+			fields: []string{"spec.listenersConfig.internalListeners[1].containerPort"},
+		},
+		{
+			testName: "envoy terminating TLS in front of a plaintext listener",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ListenersConfig.ExternalListeners[0].Type = v1beta1.SecurityProtocolPlaintext
+				spec.EnvoyConfig.TLS = &v1beta1.EnvoyTLSConfig{Mode: v1beta1.EnvoyTLSModeTerminate, SecretName: "kafka-public-tls"}
+			},
+		},
+		{
+			testName: "envoy terminating TLS without certificate",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ListenersConfig.ExternalListeners[0].Type = v1beta1.SecurityProtocolPlaintext
+				spec.EnvoyConfig.TLS = &v1beta1.EnvoyTLSConfig{Mode: v1beta1.EnvoyTLSModeTerminate}
+			},
+			fields: []string{"spec.envoyConfig.tls.secretName"},
+		},
+		{
+			testName: "envoy terminating TLS in front of an ssl listener",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ListenersConfig.ExternalListeners[0].Type = v1beta1.SecurityProtocolSSL
+				spec.EnvoyConfig.TLS = &v1beta1.EnvoyTLSConfig{Mode: v1beta1.EnvoyTLSModeTerminate, SecretName: "kafka-public-tls"}
+			},
+			fields: []string{"spec.envoyConfig.tls.mode"},
+		},
+		{
+			testName: "envoy re-encrypting TLS in front of a plaintext listener of an ingress config",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ListenersConfig.ExternalListeners[0].Type = v1beta1.SecurityProtocolSaslPlaintext
+				spec.ListenersConfig.ExternalListeners[0].Config = &v1beta1.Config{
+					DefaultIngressConfig: "public",
+					IngressConfig: map[string]v1beta1.IngressConfig{
+						"public": {EnvoyConfig: &v1beta1.EnvoyConfig{
+							TLS: &v1beta1.EnvoyTLSConfig{Mode: v1beta1.EnvoyTLSModeReencrypt, SecretName: "kafka-public-tls"},
+						}},
+					},
+				}
+			},
+			fields: []string{"spec.listenersConfig.externalListeners[0].config.ingressConfig[public].envoyConfig.tls.mode"},
+		},
+		{
+			testName: "duplicate tenant namespaces",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.Tenancy = &v1beta1.TenancyConfig{
+					Namespaces: []v1beta1.TenantNamespace{{Name: "team-a"}, {Name: "team-b"}, {Name: "team-a"}},
+				}
+			},
+			fields: []string{"spec.tenancy.namespaces[2].name"},
+		},
+		{
+			testName: "invalid rebalance schedule",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.CruiseControlConfig.RebalanceSchedule = "0 25 * * *"
+			},
+			fields: []string{"spec.cruiseControlConfig.rebalanceSchedule"},
+		},
+		{
+			testName: "broker autoscaling",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.CruiseControlConfig.Autoscaling = &v1beta1.BrokerAutoscalingConfig{
+					MinBrokers: 2, MaxBrokers: 6, BrokerConfigGroup: "default", TargetDiskPercent: 70,
+				}
+			},
+		},
+		{
+			testName: "invalid broker autoscaling",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.CruiseControlConfig.Autoscaling = &v1beta1.BrokerAutoscalingConfig{
+					MinBrokers: 3, MaxBrokers: 2, BrokerConfigGroup: "missing",
+				}
+			},
+			fields: []string{
+				"spec.cruiseControlConfig.autoscaling.maxBrokers",
+				"spec.cruiseControlConfig.autoscaling.brokerConfigGroup",
+				"spec.cruiseControlConfig.autoscaling",
+			},
+		},
+		{
+			testName: "broker group replicas",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.BrokerConfigGroups["ssd"] = v1beta1.BrokerConfig{}
+				spec.BrokerGroupReplicas = map[string]int32{"default": 2, "ssd": 0}
+			},
+		},
+		{
+			testName: "invalid broker group replicas",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.BrokerConfigGroups["ssd"] = v1beta1.BrokerConfig{}
+				spec.CruiseControlConfig.Autoscaling = &v1beta1.BrokerAutoscalingConfig{
+					MinBrokers: 2, MaxBrokers: 6, BrokerConfigGroup: "default", TargetDiskPercent: 70,
+				}
+				spec.BrokerGroupReplicas = map[string]int32{"default": 2, "missing": 1, "ssd": -1}
+			},
+			fields: []string{
+				"spec.brokerGroupReplicas[default]",
+				"spec.brokerGroupReplicas[missing]",
+				"spec.brokerGroupReplicas[ssd]",
+			},
+		},
+		{
+			testName: "rolling upgrade concurrency per rack",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.RollingUpgradeConfig.Concurrency = 3
+				spec.RollingUpgradeConfig.ConcurrencyPolicy = v1beta1.RollingUpgradeConcurrencyRack
+				spec.RackAwareness = &v1beta1.RackAwareness{Labels: []string{"topology.kubernetes.io/zone"}}
+			},
+		},
+		{
+			testName: "rolling upgrade concurrency per rack without rack awareness",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.RollingUpgradeConfig.ConcurrencyPolicy = v1beta1.RollingUpgradeConcurrencyRack
+			},
+			fields: []string{"spec.rollingUpgradeConfig.concurrencyPolicy"},
+		},
+		{
+			testName: "explicit rolling upgrade order",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.RollingUpgradeConfig.Order = v1beta1.RollingUpgradeOrderExplicit
+				spec.RollingUpgradeConfig.BrokerOrder = []int32{1, 0}
+			},
+		},
+		{
+			testName: "invalid explicit rolling upgrade order",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.RollingUpgradeConfig.Order = v1beta1.RollingUpgradeOrderExplicit
+				spec.RollingUpgradeConfig.BrokerOrder = []int32{1, 0, 1}
+			},
+			fields: []string{"spec.rollingUpgradeConfig.brokerOrder[2]"},
+		},
+		{
+			testName: "explicit rolling upgrade order without broker IDs",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.RollingUpgradeConfig.Order = v1beta1.RollingUpgradeOrderExplicit
+			},
+			fields: []string{"spec.rollingUpgradeConfig.brokerOrder"},
+		},
+		{
+			testName: "missing ZooKeeper",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.ZKAddresses = nil
+			},
+			fields: []string{"spec.zkAddresses"},
+		},
+		{
+			testName: "KRaft mode",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.KRaftMode = true
+				spec.ZKAddresses = nil
+				spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-3.1.0"
+				spec.ListenersConfig.InternalListeners[1].UsedForControllerCommunication = true
+				spec.BrokerConfigGroups["controller"] = v1beta1.BrokerConfig{
+					ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleController},
+				}
+				spec.Brokers[0].BrokerConfigGroup = "controller"
+				// there are no IDs generated by ZooKeeper in KRaft mode
+				spec.Brokers[1].Id = 1001
+			},
+		},
+		{
+			testName: "KRaft mode without controller listener and controllers",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.KRaftMode = true
+			},
+			fields: []string{"spec.listenersConfig.internalListeners", "spec.brokers"},
+		},
+		{
+			testName: "KRaft mode without brokers serving clients",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.KRaftMode = true
+				spec.ListenersConfig.InternalListeners[1].UsedForControllerCommunication = true
+				spec.BrokerConfigGroups["default"] = v1beta1.BrokerConfig{
+					ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleController},
+				}
+			},
+			fields: []string{"spec.brokers"},
+		},
+		{
+			testName: "KRaft mode on Kafka 2.x",
+			modify: func(spec *v1beta1.KafkaClusterSpec) {
+				spec.KRaftMode = true
+				spec.ListenersConfig.InternalListeners[1].UsedForControllerCommunication = true
+				spec.BrokerConfigGroups["default"] = v1beta1.BrokerConfig{
+					ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleBroker, v1beta1.ProcessRoleController},
+				}
+				spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-2.8.1"
+			},
+			fields: []string{"spec.brokers[0]", "spec.brokers[1]"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			cluster := newMockValidCluster()
+			test.modify(&cluster.Spec)
+
+			errs := validateKafkaClusterSpec(&cluster.Spec, field.NewPath("spec"))
+			if len(errs) != len(test.fields) {
+				t.Fatalf("Expected %d errors, got: %v", len(test.fields), errs)
+			}
+			for i, fieldPath := range test.fields {
+				if errs[i].Field != fieldPath {
+					t.Errorf("Expected error for field %s, got: %s", fieldPath, errs[i].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSharedZooKeeper(t *testing.T) {
+	newCluster := func(namespace, name, zkPath, readOnlyConfig string, zkAddresses ...string) v1beta1.KafkaCluster {
+		return v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1beta1.KafkaClusterSpec{ZKAddresses: zkAddresses, ZKPath: zkPath, ReadOnlyConfig: readOnlyConfig},
+		}
+	}
+	clusters := []v1beta1.KafkaCluster{
+		newCluster("kafka", "kafka", "/kafka", "", "zk-0:2181", "zk-1:2181"),
+		newCluster("team-a", "kafka", "/team-a", "", "zk-1:2181"),
+		newCluster("team-b", "kafka", "/", "", "other-zk:2181"),
+	}
+
+	testCases := []struct {
+		testName string
+		cluster  v1beta1.KafkaCluster
+		errors   int
+		warnings int
+	}{
+		{
+			testName: "updated cluster is not compared to itself",
+			cluster:  newCluster("kafka", "kafka", "/kafka", "zookeeper.set.acl=true", "zk-0:2181"),
+		},
+		{
+			testName: "distinct chroot without ACLs",
+			cluster:  newCluster("team-c", "kafka", "/team-c", "", "zk-0:2181"),
+			warnings: 1,
+		},
+		{
+			testName: "distinct chroot with ACLs",
+			cluster:  newCluster("team-c", "kafka", "/team-c", "zookeeper.set.acl=true", "zk-0:2181"),
+		},
+		{
+			testName: "colliding chroots",
+			cluster:  newCluster("team-c", "kafka", "/team-a", "zookeeper.set.acl=true", "zk-1:2181"),
+			errors:   1,
+		},
+		{
+			testName: "same chroot of another ensemble",
+			cluster:  newCluster("team-c", "kafka", "/", "", "third-zk:2181"),
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			errs, warnings := validateSharedZooKeeper(&test.cluster, clusters, field.NewPath("spec"))
+			if len(errs) != test.errors {
+				t.Errorf("Expected %d errors, got: %v", test.errors, errs)
+			}
+			for _, err := range errs {
+				if err.Field != "spec.zkPath" {
+					t.Errorf("Expected error for field spec.zkPath, got: %s", err.Field)
+				}
+			}
+			if len(warnings) != test.warnings {
+				t.Errorf("Expected %d warnings, got: %v", test.warnings, warnings)
+			}
+		})
+	}
+}
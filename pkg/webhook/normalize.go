@@ -0,0 +1,140 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+// specNormalizers return the spec of a raw object defaulted and canonicalized the way the operator interprets it,
+// for the kinds whose validation depends on nothing else of the object than its spec. The kafka topics are not
+// listed, as their validation depends on their annotations and labels too
+var specNormalizers = map[string]func(raw []byte) (interface{}, error){
+	kafkaUser:    normalizedKafkaUserSpec,
+	kafkaCluster: normalizedKafkaClusterSpec,
+}
+
+// specUnchanged returns true if the object in the update request is of a kind validated by its spec only and its
+// normalized spec is the same as the one of the current object, so the formatting of the raw objects, the order
+// of the unordered lists and the values set explicitly to their defaults are not taken into account
+func specUnchanged(req *admissionv1.AdmissionRequest) bool {
+	normalize, ok := specNormalizers[req.Kind.Kind]
+	if !ok {
+		return false
+	}
+	oldSpec, err := normalize(req.OldObject.Raw)
+	if err != nil {
+		return false
+	}
+	newSpec, err := normalize(req.Object.Raw)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(oldSpec, newSpec)
+}
+
+// normalizedKafkaUserSpec returns the spec of a raw KafkaUser with its cluster reference, certificate creation
+// and topic grant pattern types defaulted, and its DNS names and topic grants sorted
+func normalizedKafkaUserSpec(raw []byte) (interface{}, error) {
+	var user v1alpha1.KafkaUser
+	if err := json.Unmarshal(raw, &user); err != nil {
+		return nil, err
+	}
+	spec := user.Spec
+	if spec.ClusterRef.Namespace == "" {
+		spec.ClusterRef.Namespace = user.GetNamespace()
+	}
+	createCert := spec.GetIfCertShouldBeCreated()
+	spec.CreateCert = &createCert
+	if len(spec.Annotations) == 0 {
+		spec.Annotations = nil
+	}
+
+	spec.DNSNames = append([]string(nil), spec.DNSNames...)
+	sort.Strings(spec.DNSNames)
+
+	spec.TopicGrants = append([]v1alpha1.UserTopicGrant(nil), spec.TopicGrants...)
+	for i := range spec.TopicGrants {
+		if spec.TopicGrants[i].PatternType == "" {
+			spec.TopicGrants[i].PatternType = v1alpha1.KafkaPatternTypeDefault
+		}
+	}
+	sort.Slice(spec.TopicGrants, func(i, j int) bool {
+		a, b := spec.TopicGrants[i], spec.TopicGrants[j]
+		if a.TopicName != b.TopicName {
+			return a.TopicName < b.TopicName
+		}
+		if a.AccessType != b.AccessType {
+			return a.AccessType < b.AccessType
+		}
+		return a.PatternType < b.PatternType
+	})
+	return spec, nil
+}
+
+// normalizedKafkaClusterSpec returns the spec of a raw KafkaCluster with its read-only, cluster wide, broker
+// config group and per-broker configs in the canonical properties format
+func normalizedKafkaClusterSpec(raw []byte) (interface{}, error) {
+	var cluster v1beta1.KafkaCluster
+	if err := json.Unmarshal(raw, &cluster); err != nil {
+		return nil, err
+	}
+	spec := cluster.Spec
+	spec.ReadOnlyConfig = canonicalProperties(spec.ReadOnlyConfig)
+	spec.ClusterWideConfig = canonicalProperties(spec.ClusterWideConfig)
+
+	if spec.BrokerConfigGroups != nil {
+		groups := make(map[string]v1beta1.BrokerConfig, len(spec.BrokerConfigGroups))
+		for name, group := range spec.BrokerConfigGroups {
+			group.Config = canonicalProperties(group.Config)
+			groups[name] = group
+		}
+		spec.BrokerConfigGroups = groups
+	}
+
+	spec.Brokers = append([]v1beta1.Broker(nil), spec.Brokers...)
+	for i := range spec.Brokers {
+		broker := &spec.Brokers[i]
+		broker.ReadOnlyConfig = canonicalProperties(broker.ReadOnlyConfig)
+		if broker.BrokerConfig != nil {
+			brokerConfig := *broker.BrokerConfig
+			brokerConfig.Config = canonicalProperties(brokerConfig.Config)
+			broker.BrokerConfig = &brokerConfig
+		}
+	}
+	return spec, nil
+}
+
+// canonicalProperties returns the properties sorted by their keys with the comments, the blank lines and the
+// leading whitespace left out, the config is returned as it is if it can not be parsed
+func canonicalProperties(config string) string {
+	if config == "" {
+		return config
+	}
+	p, err := properties.NewFromString(config)
+	if err != nil {
+		return config
+	}
+	p.Sort()
+	return p.String()
+}
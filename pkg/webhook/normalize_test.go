@@ -0,0 +1,99 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSpecUnchanged(t *testing.T) {
+	const user = `{"metadata":{"name":"test-user","namespace":"test-namespace"},` +
+		`"spec":{"secretName":"test-secret","clusterRef":{"name":"kafka"},"dnsNames":["a","b"],` +
+		`"topicGrants":[{"topicName":"orders","accessType":"read"},{"topicName":"invoices","accessType":"write"}]}}`
+
+	testCases := []struct {
+		testName  string
+		kind      string
+		oldObject string
+		newObject string
+		expected  bool
+	}{
+		{
+			testName:  "user with defaulted and reordered fields",
+			kind:      kafkaUser,
+			oldObject: user,
+			newObject: `{"metadata":{"name":"test-user","namespace":"test-namespace","labels":{"team":"a"}},` +
+				`"spec":{"clusterRef":{"name":"kafka","namespace":"test-namespace"},"secretName":"test-secret",` +
+				`"createCert":true,"dnsNames":["b","a"],"annotations":{},"topicGrants":[` +
+				`{"topicName":"invoices","accessType":"write","patternType":"literal"},{"topicName":"orders","accessType":"read"}]}}`,
+			expected: true,
+		},
+		{
+			testName:  "user with a changed topic grant",
+			kind:      kafkaUser,
+			oldObject: user,
+			newObject: `{"metadata":{"name":"test-user","namespace":"test-namespace"},` +
+				`"spec":{"secretName":"test-secret","clusterRef":{"name":"kafka"},"dnsNames":["a","b"],` +
+				`"topicGrants":[{"topicName":"orders","accessType":"read","patternType":"prefixed"},` +
+				`{"topicName":"invoices","accessType":"write"}]}}`,
+			expected: false,
+		},
+		{
+			testName:  "user no longer creating its certificate",
+			kind:      kafkaUser,
+			oldObject: user,
+			newObject: `{"metadata":{"name":"test-user","namespace":"test-namespace"},` +
+				`"spec":{"secretName":"test-secret","clusterRef":{"name":"kafka"},"dnsNames":["a","b"],"createCert":false,` +
+				`"topicGrants":[{"topicName":"orders","accessType":"read"},{"topicName":"invoices","accessType":"write"}]}}`,
+			expected: false,
+		},
+		{
+			testName:  "cluster with reformatted broker configs",
+			kind:      kafkaCluster,
+			oldObject: `{"spec":{"brokerConfigGroups":{"default":{"config":"a=1\nb=2"}},"brokers":[{"id":0,"readOnlyConfig":"c=3\nd=4"}]}}`,
+			newObject: `{"spec":{"brokerConfigGroups":{"default":{"config":"b=2\na=1\n"}},"brokers":[{"id":0,"readOnlyConfig":"# broker 0\nd=4\nc=3"}]}}`,
+			expected:  true,
+		},
+		{
+			testName:  "cluster with a changed broker config",
+			kind:      kafkaCluster,
+			oldObject: `{"spec":{"brokers":[{"id":0,"brokerConfig":{"config":"a=1"}}]}}`,
+			newObject: `{"spec":{"brokers":[{"id":0,"brokerConfig":{"config":"a=2"}}]}}`,
+			expected:  false,
+		},
+		{
+			testName:  "topic validated by its annotations too",
+			kind:      kafkaTopic,
+			oldObject: string(newRawTopic()),
+			newObject: string(newRawTopic()),
+			expected:  false,
+		},
+	}
+
+	for _, test := range testCases {
+		req := &admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Kind: test.kind},
+			Operation: admissionv1.Update,
+		}
+		req.OldObject.Raw = []byte(test.oldObject)
+		req.Object.Raw = []byte(test.newObject)
+		if actual := specUnchanged(req); actual != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.testName, test.expected, actual)
+		}
+	}
+}
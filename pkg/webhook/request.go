@@ -28,19 +28,37 @@ import (
 	"github.com/banzaicloud/koperator/pkg/util"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
 )
 
 var (
-	kafkaTopic = reflect.TypeOf(v1alpha1.KafkaTopic{}).Name()
+	kafkaTopic   = reflect.TypeOf(v1alpha1.KafkaTopic{}).Name()
+	kafkaUser    = reflect.TypeOf(v1alpha1.KafkaUser{}).Name()
+	kafkaCluster = reflect.TypeOf(v1beta1.KafkaCluster{}).Name()
 )
 
 func (s *webhookServer) validate(ar *admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 	req := ar.Request
 
 	l := log.WithValues("kind", req.Kind, "namespace", req.Namespace, "name", req.Name, "uid", req.UID,
-		"operation", req.Operation, "user info", req.UserInfo)
+		"operation", req.Operation, "user info", req.UserInfo, "dry run", req.DryRun != nil && *req.DryRun)
 	l.Info("AdmissionReview")
 
+	if req.Operation == admissionv1.Delete {
+		return s.validateDeletion(req)
+	}
+
+	// The validation has no side effects, so dry-run requests are validated the same way. Updates keeping the
+	// normalized spec of the kinds validated by their spec only are let through without validating them again,
+	// so that re-applying an unchanged manifest, e.g. by kubectl diff or a GitOps tool computing its diff, gets
+	// the same answer regardless of the formatting of the manifest or the reachability of the Kafka cluster
+	if req.Operation == admissionv1.Update && specUnchanged(req) {
+		l.V(1).Info("Skip validation as the normalized spec is unchanged")
+		return &admissionv1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
 	switch req.Kind.Kind {
 	case kafkaTopic:
 		var topic v1alpha1.KafkaTopic
@@ -56,6 +74,34 @@ func (s *webhookServer) validate(ar *admissionv1.AdmissionReview) *admissionv1.A
 		}
 		return s.validateKafkaTopic(&topic)
 
+	case kafkaUser:
+		var user v1alpha1.KafkaUser
+		if err := json.Unmarshal(req.Object.Raw, &user); err != nil {
+			l.Error(err, "Could not unmarshal raw object")
+			return notAllowed(err.Error(), metav1.StatusReasonBadRequest)
+		}
+		if ok := util.ObjectManagedByClusterRegistry(user.GetObjectMeta()); ok {
+			l.Info("Skip validation as the resource is managed by Cluster Registry")
+			return &admissionv1.AdmissionResponse{
+				Allowed: true,
+			}
+		}
+		return s.validateKafkaUser(&user)
+
+	case kafkaCluster:
+		var cluster v1beta1.KafkaCluster
+		if err := json.Unmarshal(req.Object.Raw, &cluster); err != nil {
+			l.Error(err, "Could not unmarshal raw object")
+			return notAllowed(err.Error(), metav1.StatusReasonBadRequest)
+		}
+		if ok := util.ObjectManagedByClusterRegistry(cluster.GetObjectMeta()); ok {
+			l.Info("Skip validation as the resource is managed by Cluster Registry")
+			return &admissionv1.AdmissionResponse{
+				Allowed: true,
+			}
+		}
+		return s.validateKafkaCluster(&cluster)
+
 	default:
 		return notAllowed(fmt.Sprintf("Unexpected resource kind: %s", req.Kind.Kind), metav1.StatusReasonBadRequest)
 	}
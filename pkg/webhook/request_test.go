@@ -99,6 +99,49 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateUnchangedSpec(t *testing.T) {
+	server, err := newMockServer()
+	if err != nil {
+		t.Error("Expected no error got:", err)
+	}
+
+	dryRun := true
+	req := newAdmissionReview()
+	req.Request.Kind.Kind = kafkaTopic
+	req.Request.Operation = admissionv1.Update
+	req.Request.DryRun = &dryRun
+	req.Request.OldObject.Raw = newRawTopic()
+	// the same spec with the annotations and the field order changed, the topics are validated by their
+	// annotations too
+	req.Request.Object.Raw = []byte(`{"metadata":{"name":"test-topic","namespace":"test-namespace",` +
+		`"annotations":{"kafka.banzaicloud.io/adopted":"true"}},` +
+		`"spec":{"replicationFactor":0,"partitions":0,"name":"","clusterRef":{"name":""}}}`)
+
+	if res := server.validate(req); res.Allowed {
+		t.Error("Expected not allowed, got allowed")
+	} else if res.Result.Reason != metav1.StatusReasonNotFound {
+		t.Error("Expected not found for no cluster, got:", res.Result.Reason)
+	}
+
+	req.Request.Kind.Kind = kafkaCluster
+	req.Request.OldObject.Raw = []byte(`{"metadata":{"name":"kafka","namespace":"test-namespace"},` +
+		`"spec":{"readOnlyConfig":"auto.create.topics.enable=false\nlog.retention.hours=24"}}`)
+	// the same spec with the labels, the order of the properties, the comments and the whitespace changed
+	req.Request.Object.Raw = []byte(`{"metadata":{"name":"kafka","namespace":"test-namespace","labels":{"team":"a"}},` +
+		`"spec":{"readOnlyConfig":"# retention\n  log.retention.hours=24\n\nauto.create.topics.enable=false\n"}}`)
+
+	if res := server.validate(req); !res.Allowed {
+		t.Error("Expected allowed update with unchanged normalized spec, got:", res.Result.Message)
+	}
+
+	req.Request.Object.Raw = []byte(`{"metadata":{"name":"kafka","namespace":"test-namespace"},` +
+		`"spec":{"readOnlyConfig":"auto.create.topics.enable=true\nlog.retention.hours=24"}}`)
+
+	if res := server.validate(req); res.Allowed {
+		t.Error("Expected the changed spec to be validated and not allowed, got allowed")
+	}
+}
+
 func TestServe(t *testing.T) {
 	server, err := newMockServer()
 	if err != nil {
@@ -0,0 +1,181 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	banzaicloudv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaicloudv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// tenantNamespace returns the budget of the namespace sharing the cluster, and false if the namespace
+// is not allowed to create resources referencing the cluster. The budget is nil if the namespace is not limited.
+func tenantNamespace(cluster *banzaicloudv1beta1.KafkaCluster, namespace string) (*banzaicloudv1beta1.TenantNamespace, bool) {
+	if cluster.Spec.Tenancy == nil {
+		return nil, true
+	}
+	if tenant := cluster.Spec.Tenancy.GetNamespace(namespace); tenant != nil {
+		return tenant, true
+	}
+	return nil, namespace == cluster.GetNamespace()
+}
+
+func namespaceNotAllowed(kind, namespace string, cluster *banzaicloudv1beta1.KafkaCluster) *admissionv1.AdmissionResponse {
+	msg := fmt.Sprintf("Namespace '%s' is not allowed to create %s resources referencing KafkaCluster '%s' in the namespace '%s'",
+		namespace, kind, cluster.GetName(), cluster.GetNamespace())
+	log.Info(msg)
+	return notAllowed(msg, metav1.StatusReasonForbidden)
+}
+
+// checkTenancy checks whether the namespace of the topic is allowed to create topics in the cluster, and
+// whether the topic fits the naming prefix and the budget of the namespace. Updates of existing topics not
+// increasing the usage of the namespace are let through so that the topics created before the budget was
+// lowered can still be managed.
+func (s *webhookServer) checkTenancy(ctx context.Context, topic *banzaicloudv1alpha1.KafkaTopic,
+	cluster *banzaicloudv1beta1.KafkaCluster) *admissionv1.AdmissionResponse {
+	if k8sutil.IsMarkedForDeletion(topic.ObjectMeta) {
+		return nil
+	}
+
+	tenant, allowed := tenantNamespace(cluster, topic.GetNamespace())
+	if !allowed {
+		return namespaceNotAllowed(kafkaTopic, topic.GetNamespace(), cluster)
+	}
+	if tenant == nil {
+		return nil
+	}
+
+	kafkaTopicList := banzaicloudv1alpha1.KafkaTopicList{}
+	if err := s.client.List(ctx, &kafkaTopicList, client.InNamespace(topic.GetNamespace())); err != nil {
+		log.Error(err, "couldn't list KafkaTopic custom resources")
+		return notAllowed("API failure while retrieving KafkaTopic list, please try again", metav1.StatusReasonServiceUnavailable)
+	}
+
+	var existing *banzaicloudv1alpha1.KafkaTopic
+	others := make([]banzaicloudv1alpha1.KafkaTopic, 0, len(kafkaTopicList.Items))
+	for i, t := range kafkaTopicList.Items {
+		if t.GetName() == topic.GetName() {
+			existing = &kafkaTopicList.Items[i]
+			continue
+		}
+		others = append(others, t)
+	}
+
+	if tenant.TopicNamePrefix != "" && !strings.HasPrefix(topic.Spec.Name, tenant.TopicNamePrefix) &&
+		(existing == nil || existing.Spec.Name != topic.Spec.Name) {
+		msg := fmt.Sprintf("The name of the topics of the namespace '%s' has to start with '%s'", topic.GetNamespace(), tenant.TopicNamePrefix)
+		log.Info(msg)
+		return notAllowed(msg, metav1.StatusReasonInvalid)
+	}
+
+	if existing != nil && topic.Spec.Partitions <= existing.Spec.Partitions {
+		return nil
+	}
+
+	usage := k8sutil.TenantUsageOfKafkaTopics(others, cluster)[topic.GetNamespace()]
+	usage.Topics++
+	usage.Partitions += topic.Spec.Partitions
+
+	if tenant.MaxTopics != nil && usage.Topics > *tenant.MaxTopics {
+		msg := fmt.Sprintf("The namespace '%s' would exceed its budget of %d topics in KafkaCluster '%s'",
+			topic.GetNamespace(), *tenant.MaxTopics, cluster.GetName())
+		log.Info(msg)
+		return notAllowed(msg, metav1.StatusReasonForbidden)
+	}
+	if tenant.MaxPartitions != nil && usage.Partitions > *tenant.MaxPartitions {
+		msg := fmt.Sprintf("The namespace '%s' would exceed its budget of %d partitions in KafkaCluster '%s'",
+			topic.GetNamespace(), *tenant.MaxPartitions, cluster.GetName())
+		log.Info(msg)
+		return notAllowed(msg, metav1.StatusReasonForbidden)
+	}
+	return nil
+}
+
+func (s *webhookServer) validateKafkaUser(user *banzaicloudv1alpha1.KafkaUser) *admissionv1.AdmissionResponse {
+	ctx := context.Background()
+	log.Info(fmt.Sprintf("Doing pre-admission validation of kafka user %s", user.GetName()))
+
+	if k8sutil.IsMarkedForDeletion(user.ObjectMeta) {
+		return &admissionv1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
+	clusterNamespace := user.Spec.ClusterRef.Namespace
+	if clusterNamespace == "" {
+		clusterNamespace = user.GetNamespace()
+	}
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, s.client, user.Spec.ClusterRef.Name, clusterNamespace)
+	switch {
+	case apierrors.IsNotFound(err):
+		// the user controller waits for the cluster to be created
+		return &admissionv1.AdmissionResponse{
+			Allowed: true,
+		}
+	case err != nil:
+		log.Error(err, "API failure while running user validation")
+		return notAllowed("API failure while validating user, please try again", metav1.StatusReasonServiceUnavailable)
+	case k8sutil.IsMarkedForDeletion(cluster.ObjectMeta):
+		return &admissionv1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
+	tenant, allowed := tenantNamespace(cluster, user.GetNamespace())
+	if !allowed {
+		return namespaceNotAllowed(kafkaUser, user.GetNamespace(), cluster)
+	}
+
+	if tenant != nil && tenant.TopicNamePrefix != "" {
+		if errs := validateTopicGrants(user.Spec.TopicGrants, tenant.TopicNamePrefix, field.NewPath("spec").Child("topicGrants")); len(errs) > 0 {
+			log.Info("Rejecting kafka user granted access to topics of other namespaces", "errors", errs.ToAggregate().Error())
+			status := apierrors.NewInvalid(banzaicloudv1alpha1.GroupVersion.WithKind(kafkaUser).GroupKind(), user.GetName(), errs).Status()
+			return &admissionv1.AdmissionResponse{
+				Result: &status,
+			}
+		}
+	}
+
+	return &admissionv1.AdmissionResponse{
+		Allowed: true,
+	}
+}
+
+// validateTopicGrants returns the topic grants giving access to topics not starting with the prefix
+func validateTopicGrants(grants []banzaicloudv1alpha1.UserTopicGrant, prefix string, grantsPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, grant := range grants {
+		if grant.PatternType == banzaicloudv1alpha1.KafkaPatternTypeAny {
+			errs = append(errs, field.Forbidden(grantsPath.Index(i).Child("patternType"),
+				fmt.Sprintf("granting access to any topic is not allowed, the topic names have to start with '%s'", prefix)))
+			continue
+		}
+		if !strings.HasPrefix(grant.TopicName, prefix) {
+			errs = append(errs, field.Invalid(grantsPath.Index(i).Child("topicName"), grant.TopicName,
+				fmt.Sprintf("the topic name has to start with '%s'", prefix)))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,176 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func newMockTenantCluster() *v1beta1.KafkaCluster {
+	cluster := newMockCluster()
+	cluster.Spec.Tenancy = &v1beta1.TenancyConfig{
+		Namespaces: []v1beta1.TenantNamespace{
+			{
+				Name:            "team-a",
+				MaxTopics:       util.Int32Pointer(2),
+				MaxPartitions:   util.Int32Pointer(5),
+				TopicNamePrefix: "team-a.",
+			},
+		},
+	}
+	return cluster
+}
+
+func newMockTenantTopic(name string, partitions int32) *v1alpha1.KafkaTopic {
+	return &v1alpha1.KafkaTopic{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"},
+		Spec: v1alpha1.KafkaTopicSpec{
+			Name:              "team-a." + name,
+			Partitions:        partitions,
+			ReplicationFactor: 1,
+			ClusterRef: v1alpha1.ClusterReference{
+				Name:      "test-cluster",
+				Namespace: "test-namespace",
+			},
+		},
+	}
+}
+
+func TestCheckTenancy(t *testing.T) {
+	server, err := newMockServer()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	ctx := context.Background()
+	cluster := newMockTenantCluster()
+
+	// topics of the namespace of the cluster are not limited
+	if res := server.checkTenancy(ctx, newMockTopic(), cluster); res != nil {
+		t.Error("Expected allowed topic in the namespace of the cluster, got:", res.Result)
+	}
+
+	// topics of namespaces not listed are rejected
+	topic := newMockTenantTopic("orders", 2)
+	topic.Namespace = "team-b"
+	if res := server.checkTenancy(ctx, topic, cluster); res == nil || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden topic in namespace not listed, got:", res)
+	}
+
+	// topic names have to start with the prefix of the namespace
+	topic = newMockTenantTopic("orders", 2)
+	topic.Spec.Name = "orders"
+	if res := server.checkTenancy(ctx, topic, cluster); res == nil || res.Result.Reason != metav1.StatusReasonInvalid {
+		t.Error("Expected invalid topic name without prefix, got:", res)
+	}
+
+	topic = newMockTenantTopic("orders", 2)
+	if res := server.checkTenancy(ctx, topic, cluster); res != nil {
+		t.Error("Expected allowed topic within budget, got:", res.Result)
+	}
+	if err := server.client.Create(ctx, topic); err != nil {
+		t.Fatal(err)
+	}
+
+	// partition budget
+	if res := server.checkTenancy(ctx, newMockTenantTopic("payments", 4), cluster); res == nil || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden topic exceeding the partition budget, got:", res)
+	}
+	topic.Spec.Partitions = 6
+	if res := server.checkTenancy(ctx, topic, cluster); res == nil || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden partition increase exceeding the partition budget, got:", res)
+	}
+
+	// topic budget
+	if err := server.client.Create(ctx, newMockTenantTopic("payments", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if res := server.checkTenancy(ctx, newMockTenantTopic("invoices", 1), cluster); res == nil || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden topic exceeding the topic budget, got:", res)
+	}
+
+	// existing topics can still be updated after the budget is lowered
+	cluster.Spec.Tenancy.Namespaces[0].MaxTopics = util.Int32Pointer(1)
+	topic.Spec.Partitions = 2
+	if res := server.checkTenancy(ctx, topic, cluster); res != nil {
+		t.Error("Expected allowed update of existing topic, got:", res.Result)
+	}
+
+	// clusters without tenancy are not limited
+	topic = newMockTenantTopic("invoices", 100)
+	topic.Spec.Name = "invoices"
+	if res := server.checkTenancy(ctx, topic, newMockCluster()); res != nil {
+		t.Error("Expected allowed topic of cluster without tenancy, got:", res.Result)
+	}
+}
+
+func TestValidateKafkaUser(t *testing.T) {
+	server, err := newMockServer()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	user := &v1alpha1.KafkaUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-user", Namespace: "team-b"},
+		Spec: v1alpha1.KafkaUserSpec{
+			SecretName: "test-secret",
+			ClusterRef: v1alpha1.ClusterReference{Name: "test-cluster", Namespace: "test-namespace"},
+			TopicGrants: []v1alpha1.UserTopicGrant{
+				{TopicName: "team-a.orders", AccessType: v1alpha1.KafkaAccessTypeRead},
+			},
+		},
+	}
+
+	// non-existent cluster
+	if res := server.validateKafkaUser(user); !res.Allowed {
+		t.Error("Expected allowed user of non-existent cluster, got:", res.Result)
+	}
+
+	if err := server.client.Create(context.TODO(), newMockTenantCluster()); err != nil {
+		t.Fatal(err)
+	}
+
+	// namespace not listed
+	if res := server.validateKafkaUser(user); res.Allowed || res.Result.Reason != metav1.StatusReasonForbidden {
+		t.Error("Expected forbidden user in namespace not listed, got:", res.Result)
+	}
+
+	user.Namespace = "team-a"
+	if res := server.validateKafkaUser(user); !res.Allowed {
+		t.Error("Expected allowed user granted access to the topics of its namespace, got:", res.Result)
+	}
+
+	user.Spec.TopicGrants = append(user.Spec.TopicGrants,
+		v1alpha1.UserTopicGrant{TopicName: "team-b.orders", AccessType: v1alpha1.KafkaAccessTypeWrite},
+		v1alpha1.UserTopicGrant{TopicName: "team-a.", AccessType: v1alpha1.KafkaAccessTypeRead, PatternType: v1alpha1.KafkaPatternTypeAny},
+	)
+	res := server.validateKafkaUser(user)
+	switch {
+	case res.Allowed:
+		t.Error("Expected not allowed user granted access to topics of other namespaces, got allowed")
+	case res.Result.Reason != metav1.StatusReasonInvalid:
+		t.Error("Expected invalid user, got:", res.Result.Reason)
+	case res.Result.Details == nil || len(res.Result.Details.Causes) != 2 ||
+		res.Result.Details.Causes[0].Field != "spec.topicGrants[1].topicName" ||
+		res.Result.Details.Causes[1].Field != "spec.topicGrants[2].patternType":
+		t.Error("Expected the grants of other topics to be reported, got:", res.Result.Details)
+	}
+}
@@ -81,7 +81,22 @@ func (s *webhookServer) validateKafkaTopic(topic *banzaicloudv1alpha1.KafkaTopic
 		)
 	}
 
-	res := s.checkExistingKafkaTopicCRs(ctx, clusterNamespace, topic)
+	res := s.checkTenancy(ctx, topic, cluster)
+	if res != nil {
+		return res
+	}
+
+	res = s.checkCapacityHeadroom(ctx, topic, cluster)
+	if res != nil {
+		return res
+	}
+
+	res = checkTopicPlacement(topic, cluster)
+	if res != nil {
+		return res
+	}
+
+	res = s.checkExistingKafkaTopicCRs(ctx, clusterNamespace, topic)
 	if res != nil {
 		return res
 	}
@@ -121,13 +136,18 @@ func (s *webhookServer) checkKafka(ctx context.Context, topic *banzaicloudv1alph
 		// Check if this is the correct CR for this topic
 		topicCR := &banzaicloudv1alpha1.KafkaTopic{}
 		if err := s.client.Get(ctx, types.NamespacedName{Name: topic.Name, Namespace: topic.Namespace}, topicCR); err != nil {
-			if apierrors.IsNotFound(err) {
+			switch {
+			case apierrors.IsNotFound(err) && isAdoptedTopic(topic, cluster):
+				// the topics of an adopted cluster are imported by the operator
+				log.Info("Topic of adopted kafka cluster is being imported")
+			case apierrors.IsNotFound(err):
 				// User is trying to overwrite an existing topic - bad user
 				log.Info("User attempted to create topic with name that already exists in the kafka cluster")
 				return notAllowed(fmt.Sprintf("Topic '%s' already exists on kafka cluster '%s'", topic.Spec.Name, topic.Spec.ClusterRef.Name), metav1.StatusReasonAlreadyExists)
+			default:
+				log.Error(err, "API failure while running topic validation")
+				return notAllowed("API failure while validating topic, please try again", metav1.StatusReasonServiceUnavailable)
 			}
-			log.Error(err, "API failure while running topic validation")
-			return notAllowed("API failure while validating topic, please try again", metav1.StatusReasonServiceUnavailable)
 		}
 
 		// make sure the user isn't trying to decrease partition count
@@ -150,6 +170,29 @@ func (s *webhookServer) checkKafka(ctx context.Context, topic *banzaicloudv1alph
 	return nil
 }
 
+// checkTopicPlacement checks whether the replica placement constraints of the topic can be satisfied
+func checkTopicPlacement(topic *banzaicloudv1alpha1.KafkaTopic, cluster *banzaicloudv1beta1.KafkaCluster) *admissionv1.AdmissionResponse {
+	placement := topic.Spec.Placement
+	if placement == nil {
+		return nil
+	}
+	if placement.MinRacks > topic.Spec.ReplicationFactor {
+		log.Info(fmt.Sprintf("Spec is requesting replicas to span %v racks with a replication factor of %v, rejecting", placement.MinRacks, topic.Spec.ReplicationFactor))
+		return notAllowed(fmt.Sprintf("the replicas of a topic with a replication factor of %d can not span %d racks", topic.Spec.ReplicationFactor, placement.MinRacks), metav1.StatusReasonInvalid)
+	}
+	if group := placement.PreferredBrokerConfigGroup; group != "" {
+		if _, ok := cluster.Spec.BrokerConfigGroups[group]; !ok {
+			return notAllowed(fmt.Sprintf("broker config group '%s' does not exist in kafka cluster '%s'", group, topic.Spec.ClusterRef.Name), metav1.StatusReasonInvalid)
+		}
+	}
+	return nil
+}
+
+// isAdoptedTopic returns true if the topic is imported from the adopted cluster
+func isAdoptedTopic(topic *banzaicloudv1alpha1.KafkaTopic, cluster *banzaicloudv1beta1.KafkaCluster) bool {
+	return cluster.Spec.Adoption != nil && topic.GetAnnotations()[banzaicloudv1beta1.AdoptedAnnotation] == "true"
+}
+
 // checkExistingKafkaTopicCRs checks whether there's any other duplicate KafkaTopic CR exists
 // that refers to the same KafkaCluster's same topic
 func (s *webhookServer) checkExistingKafkaTopicCRs(ctx context.Context,
@@ -151,3 +151,62 @@ func TestValidateTopic(t *testing.T) {
 		t.Error("Expected invalid status reason, got:", res.Result)
 	}
 }
+
+func TestValidateAdoptedTopic(t *testing.T) {
+	cluster := newMockCluster()
+	cluster.Spec.Adoption = &v1beta1.AdoptionConfig{ImportTopics: true}
+	server, broker, err := newMockServerForTopicValidator(cluster)
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if err := server.client.Create(context.TODO(), cluster); err != nil {
+		t.Fatal(err)
+	}
+	err = broker.CreateTopic(&kafkaclient.CreateTopicOptions{Name: "test-topic", ReplicationFactor: 1, Partitions: 2})
+	if err != nil {
+		t.Error("creation of topic should have been successful")
+	}
+
+	topic := newMockTopic()
+	if res := server.validateKafkaTopic(topic); res.Allowed || res.Result.Reason != metav1.StatusReasonAlreadyExists {
+		t.Error("Expected not allowed due to existing topic without adopted annotation, got:", res.Result)
+	}
+
+	topic.SetAnnotations(map[string]string{v1beta1.AdoptedAnnotation: "true"})
+	if res := server.validateKafkaTopic(topic); !res.Allowed {
+		t.Error("Expected allowed import of topic of adopted cluster, got:", res.Result)
+	}
+
+	// the imported topic still has to match the existing one
+	topic.Spec.Partitions = 1
+	if res := server.validateKafkaTopic(topic); res.Allowed || res.Result.Reason != metav1.StatusReasonInvalid {
+		t.Error("Expected not allowed due to partition decrease, got:", res.Result)
+	}
+}
+
+func TestCheckTopicPlacement(t *testing.T) {
+	cluster := newMockCluster()
+	cluster.Spec.BrokerConfigGroups = map[string]v1beta1.BrokerConfig{"fast-disks": {}}
+
+	topic := newMockTopic()
+	if res := checkTopicPlacement(topic, cluster); res != nil {
+		t.Error("Expected allowed topic without placement, got:", res.Result)
+	}
+
+	topic.Spec.ReplicationFactor = 3
+	topic.Spec.Placement = &v1alpha1.TopicPlacement{MinRacks: 3, PreferredBrokerConfigGroup: "fast-disks", ExcludedBrokers: []int32{2}}
+	if res := checkTopicPlacement(topic, cluster); res != nil {
+		t.Error("Expected allowed topic with satisfiable placement, got:", res.Result)
+	}
+
+	topic.Spec.Placement.MinRacks = 4
+	if res := checkTopicPlacement(topic, cluster); res == nil || res.Result.Reason != metav1.StatusReasonInvalid {
+		t.Error("Expected not allowed due to more racks than replicas, got:", res)
+	}
+
+	topic.Spec.Placement.MinRacks = 2
+	topic.Spec.Placement.PreferredBrokerConfigGroup = "not-exists"
+	if res := checkTopicPlacement(topic, cluster); res == nil || res.Result.Reason != metav1.StatusReasonInvalid {
+		t.Error("Expected not allowed due to missing broker config group, got:", res)
+	}
+}